@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mholzen/workflowy/pkg/patch"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+func getApplyCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "apply",
+		Usage:     "Apply a patch of precomputed edits",
+		UsageText: "workflowy apply <patch.json> [options]",
+		Description: `Apply a list of {id, field, old, new} edits from a JSON file.
+
+Before writing, each edit is verified: the node's current field value must still
+equal "old", otherwise the edit is skipped; --no-verify skips this check.
+Edits to #locked nodes are refused unless the global --force is given. The JSON output of replace and transform
+dry-runs is also accepted, so changes can be reviewed before being applied.
+
+Examples:
+  workflowy replace --dry-run --format=json "v1" "v2" > plan.json
+  workflowy apply plan.json
+  workflowy apply --dry-run plan.json
+  workflowy apply --write-undo=undo.json plan.json`,
+		Arguments: []cli.Argument{
+			&cli.StringArg{
+				Name:      "patch",
+				UsageText: "<patch.json>",
+			},
+		},
+		Flags: []cli.Flag{
+			getAPIKeyFlag(),
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Verify edits without applying them",
+			},
+			&cli.BoolFlag{
+				Name:  "no-verify",
+				Usage: "Apply edits without verifying current values",
+			},
+			&cli.StringFlag{
+				Name:  "write-undo",
+				Usage: "Write a reverse patch of the applied edits to this file",
+			},
+		},
+		Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+
+			patchFile := cmd.StringArg("patch")
+			if patchFile == "" {
+				return fmt.Errorf("patch file is required")
+			}
+
+			guard, err := NewWriteGuard(ctx, client, getWriteRootID(cmd))
+			if err != nil {
+				return err
+			}
+
+			opts := patch.Options{
+				DryRun: cmd.Bool("dry-run"),
+				Force:  cmd.Bool("no-verify"),
+			}
+			return applyPatchFile(ctx, client, guard, patchFile, cmd.String("write-undo"), format, opts)
+		}),
+	}
+}
+
+// applyPatchFile applies the edits in patchFile, optionally writing the reverse
+// of the applied edits to undoFile.
+func applyPatchFile(ctx context.Context, client workflowy.Client, guard *WriteGuard, patchFile, undoFile, format string, opts patch.Options) error {
+	edits, err := patch.ReadFile(patchFile)
+	if err != nil {
+		return err
+	}
+
+	for _, edit := range edits {
+		if err := guard.ValidateTarget(edit.ID, "apply"); err != nil {
+			return err
+		}
+	}
+
+	results := patch.Apply(ctx, client, edits, opts)
+
+	if undoFile != "" && !opts.DryRun {
+		if err := patch.WriteFile(undoFile, patch.Reverse(patch.Applied(results))); err != nil {
+			return err
+		}
+	}
+
+	failure := failedResults(results, func(r patch.Result) bool { return r.Failed }, "edits")
+
+	if format == "json" {
+		printJSON(results)
+		return failure
+	}
+
+	appliedCount := 0
+	skippedCount := 0
+	for _, result := range results {
+		fmt.Println(result.String())
+		if result.Applied {
+			appliedCount++
+		}
+		if result.Skipped {
+			skippedCount++
+		}
+	}
+	if opts.DryRun {
+		printInfo("\nDry run: %d edit(s) would be applied", len(results)-skippedCount)
+	} else {
+		printInfo("\nApplied %d edit(s)", appliedCount)
+	}
+	if skippedCount > 0 {
+		printInfo(", skipped %d", skippedCount)
+	}
+	printInfo("\n")
+	if undoFile != "" && !opts.DryRun {
+		printInfo("Undo patch written to %s\n", undoFile)
+	}
+	return failure
+}
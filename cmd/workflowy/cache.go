@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/cache"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+// exportCacheStatus describes the export cache, for `cache status`.
+type exportCacheStatus struct {
+	Path       string `json:"path"`
+	Exists     bool   `json:"exists"`
+	Bytes      int64  `json:"bytes,omitempty"`
+	FetchedAt  string `json:"fetched_at,omitempty"`
+	AgeSeconds int64  `json:"age_seconds,omitempty"`
+	TTLSeconds int64  `json:"ttl_seconds"`
+	Valid      bool   `json:"valid"` // reused by the next export instead of calling the API
+}
+
+func getExportCacheStatus() (exportCacheStatus, error) {
+	path, err := cache.GetCachePath()
+	if err != nil {
+		return exportCacheStatus{}, err
+	}
+	status := exportCacheStatus{Path: path, TTLSeconds: int64(cache.CacheExpiryDuration.Seconds())}
+	data, err := cache.ReadExportCache()
+	if err != nil {
+		return status, err
+	}
+	if data == nil {
+		return status, nil
+	}
+	status.Exists = true
+	status.Bytes = fileSize(path)
+	status.FetchedAt = time.Unix(data.Timestamp, 0).Format(time.RFC3339)
+	status.AgeSeconds = int64(cache.GetCacheAge(data).Seconds())
+	status.Valid = cache.IsCacheValid(data)
+	return status, nil
+}
+
+// treeCacheStatus describes the tree cache, for `cache status` and `cache refresh`.
+type treeCacheStatus struct {
+	Path        string         `json:"path"`
+	Bytes       int64          `json:"bytes,omitempty"`
+	Nodes       int            `json:"nodes"`
+	RefreshedAt string         `json:"refreshed_at,omitempty"`
+	AgeSeconds  int64          `json:"age_seconds,omitempty"`
+	Refreshes   int            `json:"refreshes"`
+	LastDiff    cache.TreeDiff `json:"last_diff"`
+	// Subtrees are the hot subtrees refreshed since the last export, with when
+	Subtrees map[string]string `json:"subtrees,omitempty"`
+}
+
+func newTreeCacheStatus(tc *cache.TreeCache) treeCacheStatus {
+	status := treeCacheStatus{
+		Path:      tc.Path(),
+		Bytes:     fileSize(tc.Path()),
+		Nodes:     len(tc.Nodes),
+		Refreshes: tc.Refreshes,
+		LastDiff:  tc.LastDiff,
+	}
+	if !tc.IsEmpty() {
+		status.RefreshedAt = time.Unix(tc.RefreshedAt, 0).Format(time.RFC3339)
+		status.AgeSeconds = int64(tc.Age().Seconds())
+	}
+	for id, refreshedAt := range tc.Subtrees {
+		if status.Subtrees == nil {
+			status.Subtrees = make(map[string]string)
+		}
+		status.Subtrees[id] = time.Unix(refreshedAt, 0).Format(time.RFC3339)
+	}
+	return status
+}
+
+// fileSize returns the size of the file at path, or 0 if it cannot be read.
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// formatBytes returns n as a number of bytes, KB or MB.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
+
+func formatTreeDiff(diff cache.TreeDiff) string {
+	return fmt.Sprintf("%d added, %d changed, %d removed", diff.Added, diff.Changed, diff.Removed)
+}
+
+// refreshTreeCache exports the outline and applies it to the tree cache,
+// returning the cache and the differences applied.
+func refreshTreeCache(ctx context.Context, client workflowy.Client, force bool) (*cache.TreeCache, cache.TreeDiff, error) {
+	before, err := cache.LoadTreeCache()
+	if err != nil {
+		return nil, cache.TreeDiff{}, err
+	}
+	response, err := client.ExportNodesWithCache(ctx, force)
+	if err != nil {
+		return nil, cache.TreeDiff{}, fmt.Errorf("cannot export nodes: %w", err)
+	}
+	if response.Partial {
+		return nil, cache.TreeDiff{}, fmt.Errorf("cannot refresh tree cache: the export is incomplete")
+	}
+
+	tc, err := cache.LoadTreeCache()
+	if err != nil {
+		return nil, cache.TreeDiff{}, err
+	}
+	// a fresh export updates the tree cache itself; an export cache newer
+	// than the tree cache is applied here
+	diff := tc.LastDiff
+	if tc.Refreshes == before.Refreshes {
+		diff = cache.TreeDiff{}
+		if tc.IsEmpty() || response.FetchedAt.Unix() > tc.RefreshedAt {
+			if diff, err = workflowy.RefreshTreeCache(tc, response); err != nil {
+				return nil, cache.TreeDiff{}, err
+			}
+		}
+	}
+	return tc, diff, nil
+}
+
+func getCacheCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "cache",
+		Usage: "Manage the export cache and the tree cache",
+		Description: `The export cache, ~/.workflowy/export-cache.json, holds the last export for
+--cache-ttl (1 minute by default), during which exports reuse it instead of
+calling the API.
+
+The tree cache keeps a copy of the whole outline in ~/.workflowy/tree-cache.json.
+Every export fetched from the API updates it with the nodes that were added,
+changed or removed. It does not expire: commands read it with --method=cache
+instantly and offline, and exports fall back to it when the API cannot be
+reached.`,
+		Commands: []*cli.Command{
+			{
+				Name:      "refresh",
+				Usage:     "Update the tree cache from an export",
+				UsageText: "workflowy cache refresh [options]",
+				Description: `Export the outline and apply the differences to the tree cache. An export
+fetched less than a minute ago is reused, unless --force-refresh is given.
+
+Examples:
+  workflowy cache refresh
+  workflowy cache refresh --force-refresh
+  workflowy search --method=cache "meeting"`,
+				Flags: []cli.Flag{
+					getAPIKeyFlag(),
+					&cli.BoolFlag{
+						Name:  "force-refresh",
+						Usage: "Bypass the export cache",
+					},
+				},
+				Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+					format := cmd.String("format")
+					if err := validateFormat(format); err != nil {
+						return err
+					}
+					tc, diff, err := refreshTreeCache(ctx, client, cmd.Bool("force-refresh"))
+					if err != nil {
+						return err
+					}
+
+					if format == "json" {
+						printJSON(newTreeCacheStatus(tc))
+					} else {
+						printInfo("Tree cache refreshed: %d nodes as of %s (%s)\n",
+							len(tc.Nodes), formatRunTime(tc.RefreshedAt), formatTreeDiff(diff))
+					}
+					return nil
+				}),
+			},
+			getCacheWatchCommand(),
+			{
+				Name:      "status",
+				Usage:     "Show the path, size and age of the export cache and the tree cache",
+				UsageText: "workflowy cache status",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					format := cmd.String("format")
+					if err := validateFormat(format); err != nil {
+						return err
+					}
+					export, err := getExportCacheStatus()
+					if err != nil {
+						return err
+					}
+					tc, err := cache.LoadTreeCache()
+					if err != nil {
+						return err
+					}
+					tree := newTreeCacheStatus(tc)
+					if format == "json" {
+						printJSON(map[string]any{"export": export, "tree": tree})
+						return nil
+					}
+
+					fmt.Printf("Export cache: %s\n", export.Path)
+					if !export.Exists {
+						fmt.Println("  Empty")
+					} else {
+						state := "expired"
+						if export.Valid {
+							state = "reused by the next export"
+						}
+						fmt.Printf("  Size: %s\n", formatBytes(export.Bytes))
+						fmt.Printf("  Fetched: %s (%s ago, %s; TTL %s)\n", formatRunTime(time.Now().Unix()-export.AgeSeconds),
+							time.Duration(export.AgeSeconds)*time.Second, state, cache.CacheExpiryDuration)
+					}
+
+					fmt.Printf("Tree cache: %s\n", tree.Path)
+					if tc.IsEmpty() {
+						fmt.Println("  Empty (run `workflowy cache refresh`)")
+						return nil
+					}
+					fmt.Printf("  Size: %s\n", formatBytes(tree.Bytes))
+					fmt.Printf("  Nodes: %d\n", tree.Nodes)
+					fmt.Printf("  Refreshed: %s (%s ago)\n", formatRunTime(tc.RefreshedAt), tc.Age().Round(time.Second))
+					fmt.Printf("  Refreshes: %d\n", tree.Refreshes)
+					fmt.Printf("  Last refresh: %s\n", formatTreeDiff(tree.LastDiff))
+					ids := slices.Sorted(maps.Keys(tc.Subtrees))
+					for _, id := range ids {
+						fmt.Printf("  Subtree %s refreshed since: %s\n", id, formatRunTime(tc.Subtrees[id]))
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "path",
+				Usage:     "Print the path of the export cache, or of the tree cache",
+				UsageText: "workflowy cache path [--tree]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "tree",
+						Usage: "Print the path of the tree cache",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					getPath := cache.GetCachePath
+					if cmd.Bool("tree") {
+						getPath = cache.GetTreeCachePath
+					}
+					path, err := getPath()
+					if err != nil {
+						return err
+					}
+					fmt.Println(path)
+					return nil
+				},
+			},
+			{
+				Name:      "clear",
+				Usage:     "Delete the tree cache and the export cache",
+				UsageText: "workflowy cache clear",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					if err := cache.ClearTreeCache(); err != nil {
+						return err
+					}
+					if err := cache.ClearExportCache(); err != nil {
+						return err
+					}
+					printInfo("Tree cache and export cache deleted\n")
+					return nil
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/cache"
+	"github.com/mholzen/workflowy/pkg/config"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+// hotSubtreeDepth is how many levels of a hot subtree are fetched with the
+// GET API, one request per node; deeper nodes are refreshed by the exports.
+const hotSubtreeDepth = 10
+
+const defaultExportInterval = 10 * time.Minute
+
+func getCacheWatchCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "watch",
+		Usage:     "Keep the tree cache fresh: export the outline periodically, and hot subtrees more often",
+		UsageText: "workflowy cache watch [options]",
+		Description: `Refreshes the tree cache from an export every --export-interval, and in
+between, refreshes each hot subtree with the GET API at its own, shorter
+interval, so that the nodes edited most stay fresh without exporting more
+often than the API allows.
+
+Hot subtrees are set with --hot-subtrees, WORKFLOWY_HOT_SUBTREES or the
+hot_subtrees setting: IDs or target keys, each with an optional =interval
+(default 1m, at least 10s), separated by commas. The GET API is called once
+for each node of a hot subtree, down to 10 levels, so keep them small.
+
+The command runs until interrupted. A refresh that fails is reported, and
+tried again when next due.
+
+Examples:
+  workflowy config set hot_subtrees "projects=30s,3495d784"
+  workflowy cache watch
+  workflowy cache watch --export-interval=30m --hot-subtrees=inbox=15s`,
+		Flags: []cli.Flag{
+			getAPIKeyFlag(),
+			&cli.DurationFlag{
+				Name:  "export-interval",
+				Value: defaultExportInterval,
+				Usage: "How often to refresh the whole tree from an export",
+			},
+			&cli.StringFlag{
+				Name:    "hot-subtrees",
+				Value:   userConfig.HotSubtrees,
+				Usage:   "Subtrees to refresh with the GET API between exports: IDs or target keys, each with an optional =interval, separated by commas",
+				Sources: cli.EnvVars("WORKFLOWY_HOT_SUBTREES"),
+			},
+		},
+		Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			exportInterval := cmd.Duration("export-interval")
+			if exportInterval < cache.CacheExpiryDuration {
+				return fmt.Errorf("--export-interval must be at least the export cache TTL (%s)", cache.CacheExpiryDuration)
+			}
+			subtrees, err := config.ParseHotSubtrees(cmd.String("hot-subtrees"))
+			if err != nil {
+				return fmt.Errorf("invalid --hot-subtrees: %w", err)
+			}
+			for i := range subtrees {
+				if subtrees[i].ID, err = workflowy.ResolveNodeIDToUUID(ctx, client, subtrees[i].ID); err != nil {
+					return fmt.Errorf("cannot resolve hot subtree: %w", err)
+				}
+			}
+
+			schedule := newRefreshSchedule(exportInterval, subtrees, time.Now())
+			for {
+				export, due := schedule.due(time.Now())
+				if export {
+					tc, diff, err := refreshTreeCache(ctx, client, false)
+					if err != nil {
+						slog.Warn("cannot refresh tree cache", "error", err)
+					} else {
+						printInfo("%s tree cache refreshed: %d nodes (%s)\n", time.Now().Format(time.TimeOnly), len(tc.Nodes), formatTreeDiff(diff))
+					}
+				}
+				for _, subtree := range due {
+					if err := refreshHotSubtree(ctx, client, subtree.ID); err != nil {
+						slog.Warn("cannot refresh hot subtree", "id", subtree.ID, "error", err)
+					}
+				}
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(schedule.wait(time.Now())):
+				}
+			}
+		}),
+	}
+}
+
+// refreshHotSubtree refreshes the subtree of id in the tree cache with the
+// GET API.
+func refreshHotSubtree(ctx context.Context, client workflowy.Client, id string) error {
+	tc, err := cache.LoadTreeCache()
+	if err != nil {
+		return err
+	}
+	diff, err := workflowy.RefreshTreeCacheSubtree(ctx, client, tc, id, hotSubtreeDepth)
+	if err != nil {
+		return err
+	}
+	printInfo("%s subtree %s refreshed (%s)\n", time.Now().Format(time.TimeOnly), id, formatTreeDiff(diff))
+	return nil
+}
+
+// refreshSchedule tells when the whole tree and each hot subtree are due for
+// a refresh.
+type refreshSchedule struct {
+	exportInterval time.Duration
+	nextExport     time.Time
+	subtrees       []config.HotSubtree
+	next           []time.Time // of each subtree
+}
+
+// newRefreshSchedule returns a schedule with everything due at now.
+func newRefreshSchedule(exportInterval time.Duration, subtrees []config.HotSubtree, now time.Time) *refreshSchedule {
+	next := make([]time.Time, len(subtrees))
+	for i := range next {
+		next[i] = now
+	}
+	return &refreshSchedule{exportInterval: exportInterval, nextExport: now, subtrees: subtrees, next: next}
+}
+
+// due returns whether the export is due at now, and the subtrees due, and
+// schedules their next refreshes. An export refreshes every subtree, so
+// none is due along with it.
+func (s *refreshSchedule) due(now time.Time) (bool, []config.HotSubtree) {
+	export := !now.Before(s.nextExport)
+	if export {
+		s.nextExport = now.Add(s.exportInterval)
+	}
+	var due []config.HotSubtree
+	for i, subtree := range s.subtrees {
+		if now.Before(s.next[i]) {
+			continue
+		}
+		s.next[i] = now.Add(subtree.Interval)
+		if !export {
+			due = append(due, subtree)
+		}
+	}
+	return export, due
+}
+
+// wait returns how long until the next refresh is due after now.
+func (s *refreshSchedule) wait(now time.Time) time.Duration {
+	next := s.nextExport
+	for _, t := range s.next {
+		if t.Before(next) {
+			next = t
+		}
+	}
+	return max(next.Sub(now), 0)
+}
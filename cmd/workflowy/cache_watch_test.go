@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshSchedule(t *testing.T) {
+	start := time.Unix(1000, 0)
+	schedule := newRefreshSchedule(10*time.Minute, []config.HotSubtree{
+		{ID: "projects", Interval: time.Minute},
+		{ID: "inbox", Interval: 30 * time.Second},
+	}, start)
+
+	export, due := schedule.due(start)
+	assert.True(t, export)
+	assert.Empty(t, due, "the export refreshes the hot subtrees too")
+	assert.Equal(t, 30*time.Second, schedule.wait(start))
+
+	export, due = schedule.due(start.Add(30 * time.Second))
+	assert.False(t, export)
+	assert.Equal(t, []config.HotSubtree{{ID: "inbox", Interval: 30 * time.Second}}, due)
+
+	export, due = schedule.due(start.Add(time.Minute))
+	assert.False(t, export)
+	assert.Len(t, due, 2)
+	assert.Equal(t, 30*time.Second, schedule.wait(start.Add(time.Minute)))
+
+	export, _ = schedule.due(start.Add(10 * time.Minute))
+	assert.True(t, export)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/escape"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+func getCaptureCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "capture",
+		Usage:     "Capture text from arguments or stdin as a node of the inbox",
+		UsageText: "workflowy capture [options] [<text>...]",
+		Description: `Creates a node under the inbox target, or --parent-id, from the arguments,
+joined with spaces, or from stdin when there are none (or "-"). The first
+line of the text is the name of the node; the lines after it, its note.
+
+The text is plain: <, > and & are kept as typed, unless --markdown converts
+markdown formatting. --tag appends #tags to the name, and --url a link.
+
+Examples:
+  workflowy capture Call the plumber --tag=home
+  pbpaste | workflowy capture --url="$(pbpaste -pboard find)"
+  git log -1 --format='%s%n%n%b' | workflowy capture --tag=commit
+  alias wfc='workflowy capture --quiet'`,
+		Arguments: []cli.Argument{
+			&cli.StringArgs{
+				Name:      "text",
+				Min:       0,
+				Max:       -1,
+				UsageText: "<text>... (default: stdin)",
+			},
+		},
+		Flags: []cli.Flag{
+			getAPIKeyFlag(),
+			&cli.StringFlag{
+				Name:  "parent-id",
+				Value: "inbox",
+				Usage: "Parent ID: UUID or target key",
+			},
+			&cli.StringFlag{
+				Name:  "position",
+				Usage: "Position: \"top\" or \"bottom\" (omit for API default)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "tag",
+				Usage: "Append this #tag to the name (repeatable)",
+			},
+			&cli.StringFlag{
+				Name:  "url",
+				Usage: "Append a link to this URL to the name",
+			},
+			&cli.BoolFlag{
+				Name:  "markdown",
+				Usage: "Convert markdown (**bold**, _italic_, ~~strike~~, `code`, [link](url)) in the text to Workflowy formatting",
+			},
+		},
+		Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+
+			text, err := readTextArgs(cmd.StringArgs("text"))
+			if err != nil {
+				return err
+			}
+			convert := escape.HTML
+			if cmd.Bool("markdown") {
+				convert = escape.FromMarkdown
+			}
+			name, note, err := captureNode(text, cmd.StringSlice("tag"), cmd.String("url"), convert)
+			if err != nil {
+				return err
+			}
+
+			guard, err := NewWriteGuard(ctx, client, getWriteRootID(cmd))
+			if err != nil {
+				return err
+			}
+			parentID, err := workflowy.ResolveNodeID(ctx, client, guard.DefaultParent(getParentID(cmd)))
+			if err != nil {
+				return fmt.Errorf("cannot resolve parent ID: %w", err)
+			}
+			if err := guard.ValidateParent(parentID, "capture"); err != nil {
+				return err
+			}
+
+			req := &workflowy.CreateNodeRequest{ParentID: parentID, Name: name}
+			if err := req.SetPosition(cmd.String("position")); err != nil {
+				return err
+			}
+			if note != "" {
+				req.Note = &note
+			}
+			slog.Debug("capturing node", "parent_id", parentID, "name", name)
+			response, err := client.CreateNode(ctx, req)
+			if err != nil {
+				return fmt.Errorf("cannot create node: %w", err)
+			}
+
+			if format == "json" {
+				printJSON(response)
+				return nil
+			}
+			if quiet {
+				fmt.Println(response.ItemID)
+			} else {
+				fmt.Printf("%s captured\n", response.ItemID)
+			}
+			printOversizeInfo(response.ContinuationIDs, response.Truncated)
+			return nil
+		}),
+	}
+}
+
+// readTextArgs returns args joined with spaces, or stdin when there are none
+// and it is not a terminal, or args is "-".
+func readTextArgs(args []string) (string, error) {
+	text := strings.Join(args, " ")
+	if text == "-" || (text == "" && !isTerminal(os.Stdin)) {
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("cannot read stdin: %w", err)
+		}
+		text = string(input)
+	}
+	return text, nil
+}
+
+// captureNode returns the name and note of a node capturing text: its first
+// line, followed by tags and a link to url, and the lines after it, each
+// converted to Workflowy formatting with convert.
+func captureNode(text string, tags []string, url string, convert func(string) string) (name, note string, err error) {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	first, rest, _ := strings.Cut(text, "\n")
+	name = convert(strings.TrimSpace(first))
+	note = strings.TrimSpace(rest)
+	if note != "" {
+		note = convert(note)
+	}
+
+	if url = strings.TrimSpace(url); url != "" {
+		link := fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), escape.HTML(url))
+		name = strings.TrimSpace(name + " " + link)
+	}
+	for _, tag := range tags {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		if tag == "" || strings.ContainsAny(tag, " \t#") {
+			return "", "", fmt.Errorf("invalid tag %q: tags are single words", tag)
+		}
+		name = strings.TrimSpace(name + " #" + tag)
+	}
+	if name == "" {
+		return "", "", fmt.Errorf("nothing to capture: give the text as arguments or on stdin")
+	}
+	return name, note, nil
+}
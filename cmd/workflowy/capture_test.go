@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/escape"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureNode(t *testing.T) {
+	name, note, err := captureNode("Fix a < b\r\n\r\nin the parser\nsee #12\n", []string{"#work", "bug"}, "", escape.HTML)
+	require.NoError(t, err)
+	assert.Equal(t, "Fix a &lt; b #work #bug", name)
+	assert.Equal(t, "in the parser\nsee #12", note)
+
+	name, note, err = captureNode("", nil, "https://example.com/?a=1&b=2", escape.HTML)
+	require.NoError(t, err)
+	assert.Equal(t, `<a href="https://example.com/?a=1&amp;b=2">https://example.com/?a=1&amp;b=2</a>`, name)
+	assert.Empty(t, note)
+
+	name, _, err = captureNode("**Read** later", nil, "", escape.FromMarkdown)
+	require.NoError(t, err)
+	assert.Equal(t, "<b>Read</b> later", name)
+
+	_, _, err = captureNode("Call", []string{"two words"}, "", escape.HTML)
+	assert.ErrorContains(t, err, "invalid tag")
+	_, _, err = captureNode(" \n ", nil, "", escape.HTML)
+	assert.ErrorContains(t, err, "nothing to capture")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+// cascadeResult is the outcome of completing or uncompleting a node and its descendants.
+type cascadeResult struct {
+	ID          string   `json:"id"`
+	Descendants int      `json:"descendants"`
+	Changed     int      `json:"changed"`   // descendants completed or uncompleted
+	Unchanged   int      `json:"unchanged"` // descendants already in the requested state
+	Failed      int      `json:"failed"`
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// cascadeTargets returns the IDs of the descendants of item that are not
+// already completed (or uncompleted, when complete is false), in tree order,
+// and the number of descendants left as they are.
+func cascadeTargets(item *workflowy.Item, complete bool) (ids []string, unchanged int) {
+	for _, child := range item.Children {
+		if completed := child.IsCompleted(); completed != complete {
+			ids = append(ids, child.ID)
+		} else {
+			unchanged++
+		}
+		childIDs, childUnchanged := cascadeTargets(child, complete)
+		ids = append(ids, childIDs...)
+		unchanged += childUnchanged
+	}
+	return ids, unchanged
+}
+
+// completeCascade completes or uncompletes itemID, then each of its
+// descendants that is not already in that state.
+func completeCascade(ctx context.Context, cmd *cli.Command, client workflowy.Client, itemID, commandName string) error {
+	format := cmd.String("format")
+	complete := commandName == "complete"
+	setCompletion := client.UncompleteNode
+	if complete {
+		setCompletion = client.CompleteNode
+	}
+
+	snapshot, err := loadSnapshot(ctx, cmd, client, workflowy.DefaultBackupProvider)
+	if err != nil {
+		return err
+	}
+	root, err := snapshot.Root(itemID)
+	if err != nil {
+		return err
+	}
+	ids, unchanged := cascadeTargets(root, complete)
+
+	if _, err := setCompletion(ctx, itemID); err != nil {
+		return fmt.Errorf("cannot %s node: %w", commandName, err)
+	}
+
+	result := &cascadeResult{ID: itemID, Unchanged: unchanged, Descendants: len(ids) + unchanged}
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			result.Failed++
+			continue
+		}
+		if _, err := setCompletion(ctx, id); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("cannot %s %s: %v", commandName, id, err))
+			continue
+		}
+		result.Changed++
+		slog.Debug(commandName+"d descendant", "item_id", id)
+	}
+
+	var failure error
+	if result.Failed > 0 {
+		failure = partialFailure(result.Failed, len(ids), "descendants")
+	}
+	if format == "json" {
+		printJSON(result)
+		return failure
+	}
+	for _, msg := range result.Errors {
+		printInfo("%s\n", msg)
+	}
+	printInfo("%s %sd, with %d of %d descendant(s) (%d already %sd)\n",
+		itemID, commandName, result.Changed, result.Descendants, result.Unchanged, commandName)
+	return failure
+}
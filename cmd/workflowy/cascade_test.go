@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCascadeTargets(t *testing.T) {
+	done := int64(1700000000)
+	project := &workflowy.Item{ID: "p", Children: []*workflowy.Item{
+		{ID: "a", Children: []*workflowy.Item{
+			{ID: "a1", CompletedAt: &done},
+			{ID: "a2"},
+		}},
+		{ID: "b", CompletedAt: &done},
+	}}
+
+	ids, unchanged := cascadeTargets(project, true)
+	assert.Equal(t, []string{"a", "a2"}, ids)
+	assert.Equal(t, 2, unchanged)
+
+	ids, unchanged = cascadeTargets(project, false)
+	assert.Equal(t, []string{"a1", "b"}, ids)
+	assert.Equal(t, 2, unchanged)
+
+	ids, unchanged = cascadeTargets(&workflowy.Item{ID: "leaf"}, true)
+	assert.Empty(t, ids)
+	assert.Zero(t, unchanged)
+}
@@ -8,10 +8,21 @@ import (
 	"os"
 	"regexp"
 	"strings"
-
+	"text/template"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/client"
+	"github.com/mholzen/workflowy/pkg/escape"
+	"github.com/mholzen/workflowy/pkg/filters"
+	"github.com/mholzen/workflowy/pkg/formatter"
+	"github.com/mholzen/workflowy/pkg/journal"
 	"github.com/mholzen/workflowy/pkg/mcp"
 	"github.com/mholzen/workflowy/pkg/mirror"
+	"github.com/mholzen/workflowy/pkg/patch"
+	"github.com/mholzen/workflowy/pkg/replace"
 	"github.com/mholzen/workflowy/pkg/reports"
+	"github.com/mholzen/workflowy/pkg/search"
+	"github.com/mholzen/workflowy/pkg/secrets"
 	"github.com/mholzen/workflowy/pkg/workflowy"
 	"github.com/urfave/cli/v3"
 )
@@ -21,18 +32,41 @@ func getCommands() []*cli.Command {
 		getGetCommand(),
 		getListCommand(),
 		getCreateCommand(),
+		getEnsureCommand(),
+		getCaptureCommand(),
 		getUpdateCommand(),
+		getNoteCommand(),
 		getMoveCommand(),
 		getDeleteCommand(),
 		getCompleteCommand(),
 		getUncompleteCommand(),
 		getTargetsCommand(),
+		getLimitsCommand(),
+		getPermissionsCommand(),
 		getReportCommand(),
+		getMirrorCommand(),
+		getValidateCommand(),
 		getSearchCommand(),
 		getReplaceCommand(),
 		getTransformCommand(),
+		getApplyCommand(),
+		getUndoCommand(),
+		getRecipesCommand(),
+		getImportCommand(),
 		getIDCommand(),
+		getInfoCommand(),
+		getHashCommand(),
+		getSyncCommand(),
+		getMirrorSyncCommand(),
+		getOpenCommand(),
+		getPickCommand(),
+		getTuiCommand(),
 		getMcpCommand(),
+		getTrashCommand(),
+		getCacheCommand(),
+		getStatsCommand(),
+		getSelftestCommand(),
+		getConfigCommand(),
 		getVersionCommand(),
 	}
 }
@@ -42,13 +76,49 @@ func getGetCommand() *cli.Command {
 		Name:      "get",
 		Usage:     "Get node and descendants",
 		UsageText: "workflowy get [<id>] [options]",
+		Description: `Gets a node and its descendants as a tree.
+
+With --format=dot or --format=mermaid, the tree is rendered as a Graphviz
+digraph or a Mermaid flowchart, to visualize the structure of a project.
+Labels are truncated to --label-length characters; each node's tooltip holds
+its full name and it links to its Workflowy URL.
+
+Examples:
+  workflowy get inbox --depth 3
+  workflowy get <project-id> --all --format=dot | dot -Tsvg > project.svg
+  workflowy get <project-id> --depth 2 --format=mermaid`,
 		Arguments: getFetchArguments(),
-		Flags:     getFetchFlags(),
+		Flags: append(getFetchFlags(),
+			&cli.BoolFlag{
+				Name:  "anchors",
+				Usage: "With --format=markdown, emit an anchor derived from the node ID before each header",
+			},
+			&cli.IntFlag{
+				Name:  "label-length",
+				Value: formatter.DefaultLabelLength,
+				Usage: "With --format=dot or mermaid, truncate node labels to this many characters (0 for no limit)",
+			},
+			getTemplateFileFlag(),
+			getBreadcrumbFlag(),
+		),
 		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
-			params, err := getAndValidateFetchParams(cmd)
+			format := cmd.String("format")
+			if err := validateFormat(format, "dot", "mermaid", "template"); err != nil {
+				return err
+			}
+			if err := validateTemplateFile(cmd, format); err != nil {
+				return err
+			}
+			params, err := getFetchParams(cmd, format)
 			if err != nil {
 				return err
 			}
+			var tmpl *template.Template
+			if format == "template" {
+				if tmpl, err = loadOutputTemplate(cmd); err != nil {
+					return err
+				}
+			}
 
 			readGuard, err := NewReadGuard(ctx, client, getReadRootID(cmd))
 			if err != nil {
@@ -64,12 +134,31 @@ func getGetCommand() *cli.Command {
 				return err
 			}
 
-			result, err := fetchItems(cmd, ctx, client, itemID, params.depth)
+			result, source, err := fetchOrResolveMirrors(cmd, ctx, client, itemID, params.depth)
 			if err != nil {
 				return err
 			}
+			result = workflowy.FilterCompletedTree(result, params.completed)
+			result = params.pruning.ApplyTree(result)
+			if item, ok := result.(*workflowy.Item); ok && cmd.Bool("breadcrumb") {
+				tree, err := loadBreadcrumbTree(ctx, cmd, client)
+				if err != nil {
+					return err
+				}
+				workflowy.AddItemBreadcrumbs([]*workflowy.Item{item}, tree)
+			}
+			if summarizeOutput(cmd, result, params.format) {
+				return nil
+			}
 
-			printOutput(result, params.format, cmd.Bool("include-empty-names"))
+			printOutputWithOptions(result, params.format, outputOptions{
+				showEmptyNames: cmd.Bool("include-empty-names"),
+				anchors:        cmd.Bool("anchors"),
+				notes:          cmd.Bool("include-notes"),
+				labelLength:    cmd.Int("label-length"),
+				template:       tmpl,
+				source:         source,
+			})
 			return nil
 		}),
 	}
@@ -79,11 +168,52 @@ func getListCommand() *cli.Command {
 	return &cli.Command{
 		Name:      "list",
 		Usage:     "List descendants as flat list",
-		UsageText: "workflowy list [<id>] [options]",
-		Arguments: getFetchArguments(),
-		Flags:     getFetchFlags(),
+		UsageText: "workflowy list [<id>...] [options]",
+		Description: `Lists a node and its descendants as a flat list.
+
+With several ids, the tree is loaded once (export API or backup) and each
+node is listed as its own group, in the order given.
+
+With --format=csv or --format=tsv, each node is a row with its id, name,
+note, parent_id, depth (0 for the listed node), created and modified times
+(RFC 3339, UTC) and completed status, for analysis in a spreadsheet. Fields
+are quoted as needed, so names and notes may hold separators and newlines.
+
+With --format=jsonl, each node is printed as a line of JSON, for piping
+large lists into jq.
+
+Examples:
+  workflowy list inbox
+  workflowy list 3495d784 a1b2c3d4 --all --format=json
+  workflowy list --all --format=jsonl | jq .name
+  workflowy list inbox --all --format=csv > inbox.csv
+  workflowy list inbox --all --format=tsv --columns=name,depth,completed`,
+		Arguments: []cli.Argument{
+			&cli.StringArgs{
+				Name:      "id",
+				Min:       0,
+				Max:       -1,
+				UsageText: "<id>... (default: root)",
+			},
+		},
+		Flags: append(getFetchFlags(),
+			&cli.IntFlag{
+				Name:  "offset",
+				Usage: "Skip this many items of the flattened list, e.g. the next_offset of the previous page",
+			},
+			&cli.IntFlag{
+				Name:  "limit",
+				Usage: "Return at most this many items (default: all)",
+			},
+			getTemplateFileFlag(),
+			&cli.StringFlag{
+				Name:  "columns",
+				Usage: "Comma-separated columns of --format=csv or tsv: id, name, note, parent_id, depth, created, modified, completed (default: all)",
+			},
+			getBreadcrumbFlag(),
+		),
 		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
-			params, err := getAndValidateFetchParams(cmd)
+			params, err := getAndValidateListParams(cmd)
 			if err != nil {
 				return err
 			}
@@ -93,6 +223,15 @@ func getListCommand() *cli.Command {
 				return err
 			}
 
+			ids := cmd.StringArgs("id")
+			if len(ids) > 1 {
+				return listRoots(ctx, cmd, client, readGuard, ids, params)
+			}
+			params.itemID = "None"
+			if len(ids) == 1 {
+				params.itemID = ids[0]
+			}
+
 			itemID, err := workflowy.ResolveNodeID(ctx, client, readGuard.DefaultID(params.itemID))
 			if err != nil {
 				return fmt.Errorf("cannot resolve ID: %w", err)
@@ -102,14 +241,57 @@ func getListCommand() *cli.Command {
 				return err
 			}
 
-			treeResult, err := fetchItems(cmd, ctx, client, itemID, params.depth)
+			treeResult, source, err := fetchOrResolveMirrors(cmd, ctx, client, itemID, params.depth)
 			if err != nil {
 				return err
 			}
+			treeResult = workflowy.FilterCompletedTree(treeResult, params.completed)
+			treeResult = params.pruning.ApplyTree(treeResult)
+
+			offset, limit := cmd.Int("offset"), cmd.Int("limit")
+			if offset < 0 || limit < 0 {
+				return fmt.Errorf("offset and limit must be non-negative")
+			}
+			if isRowFormat(params.format) {
+				return printListRows(cmd, treeResult, params, offset, limit)
+			}
+			// an explicit page is never summarized
+			if offset == 0 && limit == 0 && summarizeOutput(cmd, treeResult, params.format) {
+				return nil
+			}
 
 			flatList := flattenTree(treeResult)
+			if params.completed == workflowy.CompletedOnly {
+				flatList.Items = workflowy.CompletedItems(flatList.Items)
+			}
+			if cmd.Bool("breadcrumb") {
+				tree, err := loadBreadcrumbTree(ctx, cmd, client)
+				if err != nil {
+					return err
+				}
+				workflowy.AddItemBreadcrumbs(flatList.Items, tree)
+			}
+			if offset == 0 && limit == 0 {
+				printOutputWithOptions(flatList, params.format, outputOptions{
+					showEmptyNames: cmd.Bool("include-empty-names"),
+					notes:          cmd.Bool("include-notes"),
+					source:         source,
+				})
+				return nil
+			}
 
-			printOutput(flatList, params.format, cmd.Bool("include-empty-names"))
+			// filter and sort the whole list so pages match the unpaginated output
+			if !cmd.Bool("include-empty-names") {
+				flatList.Items = filterEmptyNames(flatList.Items)
+			}
+			sortItemsByPriority(flatList.Items)
+			page := workflowy.Paginate(flatList, offset, limit)
+			if params.format == "json" {
+				printJSON(withDataSource(page, source))
+				return nil
+			}
+			printOutputWithOptions(&workflowy.ListChildrenResponse{Items: page.Items}, params.format, outputOptions{showEmptyNames: true, notes: cmd.Bool("include-notes")})
+			printPageInfo(page)
 			return nil
 		}),
 	}
@@ -144,6 +326,7 @@ func getCreateCommand() *cli.Command {
 				Name:  "read-file",
 				Usage: "Read node name from file instead of argument",
 			},
+			getUniqueChildrenFlag("Do not create the node if the parent has a child of the same name; print its ID instead"),
 		),
 		Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
 			format := cmd.String("format")
@@ -226,7 +409,7 @@ func getCreateCommand() *cli.Command {
 
 			req := &workflowy.CreateNodeRequest{
 				ParentID: parentID,
-				Name:     name,
+				Name:     fromMarkdown(cmd, name),
 			}
 			if err := req.SetPosition(cmd.String("position")); err != nil {
 				return err
@@ -235,9 +418,28 @@ func getCreateCommand() *cli.Command {
 				req.LayoutMode = &layoutMode
 			}
 			if note := cmd.String("note"); note != "" {
+				note = fromMarkdown(cmd, note)
 				req.Note = &note
 			}
 
+			if cmd.Bool("unique-children") {
+				existing, err := workflowy.FindChild(ctx, client, parentID, req.Name)
+				if err != nil {
+					return fmt.Errorf("cannot check names: %w", err)
+				}
+				if existing != nil {
+					slog.Debug("node exists", "parent_id", parentID, "id", existing.ID)
+					if format == "json" {
+						printJSON(map[string]any{"item_id": existing.ID, "existing": true})
+					} else if quiet {
+						fmt.Println(existing.ID)
+					} else {
+						fmt.Printf("%s exists\n", existing.ID)
+					}
+					return nil
+				}
+			}
+
 			slog.Debug("creating node", "parent_id", req.ParentID, "name", name)
 			response, err := client.CreateNode(ctx, req)
 			if err != nil {
@@ -247,7 +449,12 @@ func getCreateCommand() *cli.Command {
 			if format == "json" {
 				printJSON(response)
 			} else {
-				fmt.Printf("%s created\n", response.ItemID)
+				if quiet {
+					fmt.Println(response.ItemID)
+				} else {
+					fmt.Printf("%s created\n", response.ItemID)
+				}
+				printOversizeInfo(response.ContinuationIDs, response.Truncated)
 			}
 			return nil
 		}),
@@ -309,12 +516,15 @@ func getUpdateCommand() *cli.Command {
 			}
 
 			if content != "" {
+				content = fromMarkdown(cmd, content)
 				req.Name = &content
 			} else if nameFlag != "" {
+				nameFlag = fromMarkdown(cmd, nameFlag)
 				req.Name = &nameFlag
 			}
 
 			if noteFlag != "" {
+				noteFlag = fromMarkdown(cmd, noteFlag)
 				req.Note = &noteFlag
 			}
 
@@ -335,18 +545,39 @@ func getUpdateCommand() *cli.Command {
 			if format == "json" {
 				printJSON(response)
 			} else {
-				fmt.Printf("%s updated\n", itemID)
+				printInfo("%s updated\n", itemID)
+				printOversizeInfo(response.ContinuationIDs, response.Truncated)
 			}
 			return nil
 		}),
 	}
 }
 
+// fromMarkdown converts s from markdown to Workflowy formatting when --markdown is set.
+func fromMarkdown(cmd *cli.Command, s string) string {
+	if !cmd.Bool("markdown") {
+		return s
+	}
+	return escape.FromMarkdown(s)
+}
+
 func getMoveCommand() *cli.Command {
 	return &cli.Command{
 		Name:      "move",
-		Usage:     "Move a node to a new parent",
-		UsageText: "workflowy move <id> <parent-id> [options]",
+		Usage:     "Move a node, or every node matching a pattern, to a new parent",
+		UsageText: "workflowy move <id> <parent-id> [options]\n   workflowy move --pattern <text> --to <parent-id> [options]",
+		Description: `Moves the node <id> under <parent-id>.
+
+With --pattern, moves every node whose name contains the text (or matches
+the regular expression, with --regexp) under --to instead, within
+--parent-id. The descendants of a node moved go with it, and the nodes moved
+keep their order. Preview with --dry-run, or confirm each move with
+--interactive.
+
+Examples:
+  workflowy move 3495d784 inbox --position=bottom
+  workflowy move --pattern="#archive" --to=a8e4b2c1 --dry-run
+  workflowy move -E --pattern="^Done:" --to=a8e4b2c1 --parent-id=projects --interactive`,
 		Arguments: []cli.Argument{
 			&cli.StringArg{
 				Name:      "id",
@@ -363,6 +594,27 @@ func getMoveCommand() *cli.Command {
 				Name:  "position",
 				Usage: "Position in new parent: top or bottom (default: top)",
 			},
+			&cli.StringFlag{
+				Name:  "pattern",
+				Usage: "Instead of <id>, move every node whose name contains this text",
+			},
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "Parent to move the nodes matching --pattern to: UUID or target key",
+			},
+			getRegexpFlag(),
+			getIgnoreCaseFlag(),
+			getParentIdFlag("Parent ID to limit --pattern to: UUID or target key (default: root)"),
+			getDepthFlag(-1, "Maximum depth below --parent-id to traverse with --pattern (-1 for unlimited)"),
+			getBreadcrumbFlag(),
+			&cli.BoolFlag{
+				Name:  "interactive",
+				Usage: "Prompt for confirmation before each move of --pattern",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show the nodes --pattern selects without moving them",
+			},
 		},
 		Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
 			format := cmd.String("format")
@@ -377,6 +629,12 @@ func getMoveCommand() *cli.Command {
 			}
 
 			rawItemID := cmd.StringArg("id")
+			if cmd.IsSet("pattern") {
+				if rawItemID != "" {
+					return fmt.Errorf("--pattern cannot be combined with <id>: give the destination with --to")
+				}
+				return moveMatching(ctx, cmd, client, guard)
+			}
 			if rawItemID == "" {
 				return fmt.Errorf("id is required")
 			}
@@ -420,7 +678,7 @@ func getMoveCommand() *cli.Command {
 			if format == "json" {
 				printJSON(response)
 			} else {
-				fmt.Printf("%s moved to %s\n", itemID, parentID)
+				printInfo("%s moved to %s\n", itemID, parentID)
 			}
 			return nil
 		}),
@@ -430,7 +688,7 @@ func getMoveCommand() *cli.Command {
 func getDeleteCommand() *cli.Command {
 	return &cli.Command{
 		Name:      "delete",
-		Usage:     "Permanently delete a node",
+		Usage:     "Permanently delete a node, or move it to the trash with --soft",
 		UsageText: "workflowy delete <id> [options]",
 		Arguments: []cli.Argument{
 			&cli.StringArg{
@@ -438,7 +696,13 @@ func getDeleteCommand() *cli.Command {
 				UsageText: "<id>",
 			},
 		},
-		Flags: getMethodFlags(),
+		Flags: append(getMethodFlags(),
+			&cli.BoolFlag{
+				Name:  "soft",
+				Usage: "Move the node to the trash instead of deleting it (see trash empty)",
+			},
+			getTrashIDFlag(),
+		),
 		Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
 			format := cmd.String("format")
 			if err := validateFormat(format); err != nil {
@@ -461,11 +725,15 @@ func getDeleteCommand() *cli.Command {
 				return fmt.Errorf("cannot resolve ID: %w", err)
 			}
 
-			// Validate target is within write-root scope
-			if err := guard.ValidateTarget(itemID, "delete"); err != nil {
+			// Validate target is within write-root scope and holds no locked node
+			if err := guard.ValidateSubtree(itemID, "delete"); err != nil {
 				return err
 			}
 
+			if cmd.Bool("soft") {
+				return softDelete(ctx, cmd, client, guard, itemID)
+			}
+
 			slog.Debug("deleting node", "item_id", itemID)
 
 			response, err := client.DeleteNode(ctx, itemID)
@@ -476,7 +744,7 @@ func getDeleteCommand() *cli.Command {
 			if format == "json" {
 				printJSON(response)
 			} else {
-				fmt.Printf("%s deleted\n", itemID)
+				printInfo("%s deleted\n", itemID)
 			}
 			return nil
 		}),
@@ -503,13 +771,86 @@ func getTargetsCommand() *cli.Command {
 				return err
 			}
 
+			writeGuard, err := newWriteRootGuard(ctx, client, getWriteRootID(cmd))
+			if err != nil {
+				return err
+			}
+			readGuard, err := NewReadGuard(ctx, client, getReadRootID(cmd))
+			if err != nil {
+				return err
+			}
+
 			slog.Debug("listing targets")
 			response, err := client.ListTargets(ctx)
 			if err != nil {
 				return fmt.Errorf("cannot list targets: %w", err)
 			}
 
+			if !writeGuard.IsRestricted() && !readGuard.IsRestricted() {
+				printOutput(response.Targets, format, false)
+				return nil
+			}
+
+			// restricted: report the roots as the MCP targets tool does
+			var writeRoot, readRoot *restrictionRoot
+			if writeGuard.IsRestricted() {
+				writeRoot = &restrictionRoot{ID: writeGuard.WriteRootID(), Name: writeGuard.WriteRootName()}
+			}
+			if readGuard.IsRestricted() {
+				readRoot = &restrictionRoot{ID: readGuard.ReadRootID(), Name: readGuard.ReadRootName()}
+			}
+			if format == "json" {
+				result := map[string]any{"targets": response.Targets}
+				if writeRoot != nil {
+					result["write_root"] = writeRoot
+				}
+				if readRoot != nil {
+					result["read_root"] = readRoot
+				}
+				printJSON(result)
+				return nil
+			}
 			printOutput(response.Targets, format, false)
+			fmt.Println()
+			if writeRoot != nil {
+				fmt.Printf("Writes restricted to %s\n", writeRoot)
+			}
+			if readRoot != nil {
+				fmt.Printf("Reads restricted to %s\n", readRoot)
+			}
+			return nil
+		}),
+	}
+}
+
+func getLimitsCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "limits",
+		Usage:     "Show the API rate limit: requests remaining and when the quota resets",
+		UsageText: "workflowy limits [options]",
+		Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+
+			limit, known, err := workflowy.RateLimit(ctx, client)
+			if err != nil {
+				return fmt.Errorf("cannot get rate limit: %w", err)
+			}
+			if format == "json" {
+				if !known {
+					printJSON(map[string]any{"reported": false})
+					return nil
+				}
+				printJSON(limit)
+				return nil
+			}
+			if !known {
+				fmt.Println("The API did not report a rate limit")
+				return nil
+			}
+			fmt.Println(limit.String())
 			return nil
 		}),
 	}
@@ -519,14 +860,35 @@ func getCompletionCommand(commandName, usage, action string) *cli.Command {
 	return &cli.Command{
 		Name:      commandName,
 		Usage:     usage,
-		UsageText: "workflowy " + commandName + " <id> [options]",
+		UsageText: "workflowy " + commandName + " <id> [options]\n   workflowy " + commandName + " --pattern <regex> --older-than <age> [options]",
 		Arguments: []cli.Argument{
 			&cli.StringArg{
 				Name:      "id",
 				UsageText: "<id>",
 			},
 		},
-		Flags: getMethodFlags(),
+		Flags: append(getMethodFlags(),
+			&cli.BoolFlag{
+				Name:  "cascade",
+				Usage: "Also " + commandName + " all descendants",
+			},
+			&cli.StringFlag{
+				Name:  "pattern",
+				Usage: "Instead of <id>, " + commandName + " every node whose name matches this regular expression",
+			},
+			&cli.StringFlag{
+				Name:  "older-than",
+				Usage: "Instead of <id>, " + commandName + " every node not modified for this long, e.g. 90d, 2w or 36h (with --pattern, nodes must match both)",
+			},
+			getIgnoreCaseFlag(),
+			getParentIdFlag("Parent ID to limit --pattern and --older-than to: UUID or target key (default: root)"),
+			getDepthFlag(-1, "Maximum depth to traverse with --pattern and --older-than (-1 for unlimited)"),
+			getBreadcrumbFlag(),
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show the nodes --pattern and --older-than select without changing them",
+			},
+		),
 		Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
 			format := cmd.String("format")
 			if err := validateFormat(format); err != nil {
@@ -540,6 +902,12 @@ func getCompletionCommand(commandName, usage, action string) *cli.Command {
 			}
 
 			rawItemID := cmd.StringArg("id")
+			if cmd.IsSet("pattern") || cmd.IsSet("older-than") {
+				if rawItemID != "" || cmd.Bool("cascade") {
+					return fmt.Errorf("--pattern and --older-than cannot be combined with <id> or --cascade")
+				}
+				return completeMatching(ctx, cmd, client, guard, commandName)
+			}
 			if rawItemID == "" {
 				return fmt.Errorf("id is required")
 			}
@@ -549,12 +917,19 @@ func getCompletionCommand(commandName, usage, action string) *cli.Command {
 				return fmt.Errorf("cannot resolve ID: %w", err)
 			}
 
-			// Validate target is within write-root scope
-			if err := guard.ValidateTarget(itemID, commandName); err != nil {
+			// Validate target is within write-root scope, and its subtree unlocked when cascading
+			validate := guard.ValidateTarget
+			if cmd.Bool("cascade") {
+				validate = guard.ValidateSubtree
+			}
+			if err := validate(itemID, commandName); err != nil {
 				return err
 			}
 
-			slog.Debug(action+" node", "item_id", itemID)
+			slog.Debug(action+" node", "item_id", itemID, "cascade", cmd.Bool("cascade"))
+			if cmd.Bool("cascade") {
+				return completeCascade(ctx, cmd, client, itemID, commandName)
+			}
 
 			var response *workflowy.UpdateNodeResponse
 
@@ -571,7 +946,7 @@ func getCompletionCommand(commandName, usage, action string) *cli.Command {
 			if format == "json" {
 				printJSON(response)
 			} else {
-				fmt.Printf("%s %sd\n", itemID, commandName)
+				printInfo("%s %sd\n", itemID, commandName)
 			}
 			return nil
 		}),
@@ -588,6 +963,11 @@ func getReportCommand() *cli.Command {
 			getChildrenReportCommand(),
 			getCreatedReportCommand(),
 			getModifiedReportCommand(),
+			getTagsReportCommand(),
+			getCompletedReportCommand(),
+			getStaleReportCommand(),
+			getExposureReportCommand(),
+			getDepthReportCommand(),
 			getMirrorReportCommand(),
 		},
 	}
@@ -608,6 +988,10 @@ func getCountReportCommandWithDeps(deps ReportDeps, clientProvider ClientProvide
 				Value: 0.01,
 				Usage: "Minimum ratio threshold for filtering (0.0 to 1.0)",
 			},
+			&cli.StringFlag{
+				Name:  "compare",
+				Usage: "Backup file of an earlier snapshot to compare counts with",
+			},
 		),
 		Action: clientProvider(countReportAction(deps)),
 	}
@@ -620,7 +1004,7 @@ func getChildrenReportCommand() *cli.Command {
 		UsageText: "workflowy report children [options]",
 		Flags:     getRankingReportFlags(),
 		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
-			descendants, err := loadAndCountDescendants(ctx, cmd, client)
+			descendants, snapshot, err := loadAndCountDescendants(ctx, cmd, client)
 			if err != nil {
 				return err
 			}
@@ -631,11 +1015,12 @@ func getChildrenReportCommand() *cli.Command {
 			ranked := workflowy.RankByChildrenCount(nodesWithTimestamps, topN)
 
 			report := &reports.ChildrenCountReportOutput{
-				Ranked: ranked,
-				TopN:   topN,
+				Ranked:   ranked,
+				TopN:     topN,
+				Snapshot: snapshot.Meta(),
 			}
 
-			return outputReport(ctx, cmd, client, report, os.Stdout)
+			return outputReport(ctx, cmd, client, report, snapshot.Meta(), os.Stdout)
 		}),
 	}
 }
@@ -647,7 +1032,7 @@ func getCreatedReportCommand() *cli.Command {
 		UsageText: "workflowy report created [options]",
 		Flags:     getRankingReportFlags(),
 		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
-			descendants, err := loadAndCountDescendants(ctx, cmd, client)
+			descendants, snapshot, err := loadAndCountDescendants(ctx, cmd, client)
 			if err != nil {
 				return err
 			}
@@ -658,11 +1043,12 @@ func getCreatedReportCommand() *cli.Command {
 			ranked := workflowy.RankByCreated(nodesWithTimestamps, topN)
 
 			report := &reports.CreatedReportOutput{
-				Ranked: ranked,
-				TopN:   topN,
+				Ranked:   ranked,
+				TopN:     topN,
+				Snapshot: snapshot.Meta(),
 			}
 
-			return outputReport(ctx, cmd, client, report, os.Stdout)
+			return outputReport(ctx, cmd, client, report, snapshot.Meta(), os.Stdout)
 		}),
 	}
 }
@@ -674,7 +1060,7 @@ func getModifiedReportCommand() *cli.Command {
 		UsageText: "workflowy report modified [options]",
 		Flags:     getRankingReportFlags(),
 		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
-			descendants, err := loadAndCountDescendants(ctx, cmd, client)
+			descendants, snapshot, err := loadAndCountDescendants(ctx, cmd, client)
 			if err != nil {
 				return err
 			}
@@ -685,11 +1071,195 @@ func getModifiedReportCommand() *cli.Command {
 			ranked := workflowy.RankByModified(nodesWithTimestamps, topN)
 
 			report := &reports.ModifiedReportOutput{
-				Ranked: ranked,
-				TopN:   topN,
+				Ranked:   ranked,
+				TopN:     topN,
+				Snapshot: snapshot.Meta(),
+			}
+
+			return outputReport(ctx, cmd, client, report, snapshot.Meta(), os.Stdout)
+		}),
+	}
+}
+
+func getTagsReportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "tags",
+		Usage:     "Rank #tags and @mentions by usage, with the nodes using each most",
+		UsageText: "workflowy report tags [options]",
+		Flags: append(getRankingReportFlags(),
+			&cli.IntFlag{
+				Name:  "nodes-per-tag",
+				Value: 5,
+				Usage: "Number of nodes listed under each tag (0 for all)",
+			},
+		),
+		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			snapshot, rootItem, err := loadReportRoot(ctx, cmd, client, workflowy.DefaultBackupProvider, "report")
+			if err != nil {
+				return err
+			}
+
+			topN := cmd.Int("top-n")
+			usages := workflowy.CountTags(rootItem, cmd.Int("nodes-per-tag"))
+			if topN > 0 && len(usages) > topN {
+				usages = usages[:topN]
+			}
+
+			report := &reports.TagsReportOutput{
+				Tags:     usages,
+				TopN:     topN,
+				Snapshot: snapshot.Meta(),
+			}
+
+			return outputReport(ctx, cmd, client, report, snapshot.Meta(), os.Stdout)
+		}),
+	}
+}
+
+func getCompletedReportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "completed",
+		Usage:     "Count completed nodes per day, week or month",
+		UsageText: "workflowy report completed [options]",
+		Flags: getReportFlags(
+			&cli.StringFlag{
+				Name:  "period",
+				Value: workflowy.PeriodWeek,
+				Usage: "Period to group completions by: day, week or month",
+			},
+		),
+		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			period := cmd.String("period")
+			if err := workflowy.ValidatePeriod(period); err != nil {
+				return err
+			}
+
+			snapshot, rootItem, err := loadReportRoot(ctx, cmd, client, workflowy.DefaultBackupProvider, "report")
+			if err != nil {
+				return err
+			}
+
+			completions, err := workflowy.CountCompletions(rootItem, period, time.Local)
+			if err != nil {
+				return err
+			}
+
+			report := &reports.CompletedReportOutput{
+				Completions: completions,
+				Snapshot:    snapshot.Meta(),
+			}
+
+			return outputReport(ctx, cmd, client, report, snapshot.Meta(), os.Stdout)
+		}),
+	}
+}
+
+func getStaleReportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "stale",
+		Usage:     "Find the largest subtrees in which nothing was modified for a number of days",
+		UsageText: "workflowy report stale [options]",
+		Flags: append(getRankingReportFlags(),
+			&cli.IntFlag{
+				Name:  "days",
+				Value: 180,
+				Usage: "Report subtrees in which no node was modified in this many days",
+			},
+		),
+		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			days := cmd.Int("days")
+			if days <= 0 {
+				return fmt.Errorf("days must be positive")
+			}
+
+			snapshot, rootItem, err := loadReportRoot(ctx, cmd, client, workflowy.DefaultBackupProvider, "report")
+			if err != nil {
+				return err
+			}
+
+			topN := cmd.Int("top-n")
+			subtrees := workflowy.FindStaleSubtrees(rootItem, time.Now().AddDate(0, 0, -days))
+			if topN > 0 && len(subtrees) > topN {
+				subtrees = subtrees[:topN]
+			}
+
+			report := &reports.StaleReportOutput{
+				Subtrees: subtrees,
+				Days:     days,
+				TopN:     topN,
+				Snapshot: snapshot.Meta(),
+			}
+
+			return outputReport(ctx, cmd, client, report, snapshot.Meta(), os.Stdout)
+		}),
+	}
+}
+
+func getExposureReportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "exposure",
+		Usage:     "Find nodes holding likely secrets: API keys, passwords, card numbers",
+		UsageText: "workflowy report exposure [options]",
+		Flags: getReportFlags(
+			&cli.StringFlag{
+				Name:  "patterns-file",
+				Usage: "YAML file mapping detector names to regular expressions, added to the built-in detectors",
+			},
+		),
+		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			detectors := secrets.DefaultDetectors()
+			if path := cmd.String("patterns-file"); path != "" {
+				custom, err := secrets.LoadDetectors(path)
+				if err != nil {
+					return err
+				}
+				detectors = secrets.Merge(detectors, custom)
+			}
+
+			snapshot, rootItem, err := loadReportRoot(ctx, cmd, client, workflowy.DefaultBackupProvider, "report")
+			if err != nil {
+				return err
+			}
+
+			report := &reports.ExposureReportOutput{
+				Exposures: secrets.Scan(rootItem, detectors),
+				Snapshot:  snapshot.Meta(),
+			}
+
+			return outputReport(ctx, cmd, client, report, snapshot.Meta(), os.Stdout)
+		}),
+	}
+}
+
+func getDepthReportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "depth",
+		Usage:     "Show tree depth and breadth: max depth, branching factor, nodes per level and deepest paths",
+		UsageText: "workflowy report depth [options]",
+		Flags: getReportFlags(
+			&cli.IntFlag{
+				Name:  "paths",
+				Value: 5,
+				Usage: "Number of deepest paths to show (0 for all leaves)",
+			},
+		),
+		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			paths := cmd.Int("paths")
+			if paths < 0 {
+				return fmt.Errorf("paths must not be negative")
 			}
 
-			return outputReport(ctx, cmd, client, report, os.Stdout)
+			snapshot, rootItem, err := loadReportRoot(ctx, cmd, client, workflowy.DefaultBackupProvider, "report")
+			if err != nil {
+				return err
+			}
+
+			report := &reports.DepthReportOutput{
+				Depth:    workflowy.MeasureDepth(rootItem, paths),
+				Snapshot: snapshot.Meta(),
+			}
+
+			return outputReport(ctx, cmd, client, report, snapshot.Meta(), os.Stdout)
 		}),
 	}
 }
@@ -701,26 +1271,146 @@ func getMirrorReportCommand() *cli.Command {
 		UsageText: "workflowy report mirrors [options]",
 		Flags:     getMirrorReportFlags(),
 		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
-			method := cmd.String("method")
-			if method != "" && method != "backup" {
-				return fmt.Errorf("mirror report requires --method=backup (mirror data is only available in backup files)")
+			if err := checkMirrorMethod(cmd.String("method"), "mirror report"); err != nil {
+				return err
 			}
 
-			items, err := loadTree(ctx, cmd, client)
+			snapshot, err := loadSnapshot(ctx, cmd, client, workflowy.DefaultBackupProvider)
 			if err != nil {
 				return err
 			}
+			items := snapshot.Items()
 
 			infos := mirror.CollectMirrorInfos(items)
 			topN := cmd.Int("top-n")
 			ranked := mirror.RankByMirrorCount(infos, topN)
 
 			report := &reports.MirrorCountReportOutput{
-				Ranked: ranked,
-				TopN:   topN,
+				Ranked:   ranked,
+				TopN:     topN,
+				Problems: mirror.ValidateChains(items, mirror.DefaultMaxChainLength),
+				Snapshot: snapshot.Meta(),
+			}
+
+			return outputReport(ctx, cmd, client, report, snapshot.Meta(), os.Stdout)
+		}),
+	}
+}
+
+func getMirrorCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "mirror",
+		Usage:     "Inspect mirrored nodes",
+		UsageText: "workflowy mirror <subcommand> [options]",
+		Description: `Mirror data is only available in backup files. The API cannot create mirrors,
+so mirrors must be created in the Workflowy app.`,
+		Commands: []*cli.Command{
+			getMirrorResolveCommand(),
+		},
+	}
+}
+
+func getMirrorResolveCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "resolve",
+		Usage:     "Map a mirror copy to its original",
+		UsageText: "workflowy mirror resolve <id> [options]",
+		Arguments: []cli.Argument{
+			&cli.StringArg{
+				Name:      "id",
+				UsageText: "<id>",
+			},
+		},
+		Flags: getMirrorResolveFlags(),
+		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+			if err := checkMirrorMethod(cmd.String("method"), "mirror resolve"); err != nil {
+				return err
+			}
+
+			rawID := cmd.StringArg("id")
+			if rawID == "" {
+				return fmt.Errorf("id is required")
+			}
+			id, err := workflowy.ResolveNodeID(ctx, client, rawID)
+			if err != nil {
+				return fmt.Errorf("cannot resolve ID: %w", err)
+			}
+
+			items, _, err := loadTree(ctx, cmd, client)
+			if err != nil {
+				return err
+			}
+
+			res, err := mirror.Resolve(items, id)
+			if err != nil {
+				return err
+			}
+			if format == "json" {
+				printJSON(res)
+				return nil
+			}
+			if !res.IsMirror {
+				fmt.Printf("%s is not a mirror copy\n", res.ID)
+			} else {
+				fmt.Printf("%s mirrors %s\n", res.ID, res.OriginalID)
+			}
+			fmt.Printf("original: %s %s\n", res.OriginalID, res.OriginalName)
+			if res.OriginalParentID != "" {
+				fmt.Printf("parent: %s %s\n", res.OriginalParentID, res.OriginalParentName)
 			}
+			for _, mirrorID := range res.MirrorIDs {
+				fmt.Printf("mirrored at: %s\n", mirrorID)
+			}
+			return nil
+		}),
+	}
+}
 
-			return outputReport(ctx, cmd, client, report, os.Stdout)
+func getValidateCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "validate",
+		Usage:     "Check mirrors for cycles, long chains and missing originals",
+		UsageText: "workflowy validate [options]",
+		Description: `Follows the chain from each mirror copy to its original and reports copies
+whose chain loops back on itself, is longer than --max-chain-length, or points
+at a node that no longer exists. Exits with code 1 when problems are found.
+Mirror data is only available in backup files.`,
+		Flags: append(getMirrorResolveFlags(), &cli.IntFlag{
+			Name:  "max-chain-length",
+			Value: mirror.DefaultMaxChainLength,
+			Usage: "Longest accepted chain of mirrors of mirrors",
+		}),
+		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+			if err := checkMirrorMethod(cmd.String("method"), "validate"); err != nil {
+				return err
+			}
+
+			items, _, err := loadTree(ctx, cmd, client)
+			if err != nil {
+				return err
+			}
+
+			problems := mirror.ValidateChains(items, cmd.Int("max-chain-length"))
+			if format == "json" {
+				printJSON(map[string]any{"mirror_chains": problems})
+			} else {
+				for _, p := range problems {
+					fmt.Printf("%s (%s): %s via %s\n", p.NodeID, p.NodeName, p.Kind, strings.Join(p.Chain, " -> "))
+				}
+			}
+			if len(problems) > 0 {
+				return fmt.Errorf("found %d mirror chain problems", len(problems))
+			}
+			printInfo("no problems found\n")
+			return nil
 		}),
 	}
 }
@@ -728,8 +1418,16 @@ func getMirrorReportCommand() *cli.Command {
 func getSearchCommand() *cli.Command {
 	return &cli.Command{
 		Name:      "search",
-		Usage:     "Search for nodes by name",
-		UsageText: "workflowy search <pattern> [options]",
+		Usage:     "Search for nodes by name or note",
+		UsageText: "workflowy search [<pattern> | --saved <name>] [options]",
+		Description: `Searches node names, and with --fields notes, for a pattern.
+
+With several --id, the tree is loaded once and each node is searched as its
+own group, in the order given.
+
+Examples:
+  workflowy search "TODO" --id inbox
+  workflowy search -E "^Notes$" --id <project-a> --id <project-b> --breadcrumb`,
 		Arguments: []cli.Argument{
 			&cli.StringArg{
 				Name:      "pattern",
@@ -739,14 +1437,38 @@ func getSearchCommand() *cli.Command {
 		Flags: append(getSearchFlags(), getMethodFlags()...),
 		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
 			format := cmd.String("format")
-			if err := validateFormat(format); err != nil {
+			if err := validateFormat(format, "jsonl"); err != nil {
 				return err
 			}
 
 			pattern := cmd.StringArg("pattern")
-			if pattern == "" {
+			fields, err := search.ParseFields(cmd.String("fields"))
+			if err != nil {
+				return fmt.Errorf("invalid --fields: %w", err)
+			}
+			filter := filters.Filter{
+				Pattern:    pattern,
+				Regexp:     cmd.Bool("regexp"),
+				IgnoreCase: cmd.Bool("ignore-case"),
+				Fields:     fields,
+				Completed:  cmd.String("completed"),
+			}
+			if name := cmd.String("saved"); name != "" {
+				if pattern != "" {
+					return fmt.Errorf("a pattern cannot be given with --saved")
+				}
+				if filter, err = loadSavedSearch(name); err != nil {
+					return err
+				}
+				if cmd.IsSet("completed") {
+					filter.Completed = cmd.String("completed")
+				}
+			} else if pattern == "" {
 				return fmt.Errorf("search pattern is required")
 			}
+			if err := workflowy.ValidateCompletedMode(filter.Completed); err != nil {
+				return err
+			}
 
 			method := cmd.String("method")
 			if method == "get" {
@@ -758,38 +1480,32 @@ func getSearchCommand() *cli.Command {
 				return err
 			}
 
-			items, err := loadTree(ctx, cmd, client)
+			items, source, err := loadTree(ctx, cmd, client)
 			if err != nil {
 				return err
 			}
 
-			rawID := readGuard.DefaultID(getID(cmd))
-			itemID, err := workflowy.ResolveNodeID(ctx, client, rawID)
-			if err != nil {
-				return fmt.Errorf("cannot resolve ID: %w", err)
+			ids := cmd.StringSlice("id")
+			if len(ids) == 0 {
+				ids = []string{"None"}
+				if filter.RootID != "" {
+					ids = []string{filter.RootID}
+				}
 			}
-
-			if err := readGuard.ValidateTarget(itemID, "search"); err != nil {
-				return err
+			if len(ids) > 1 {
+				return searchRoots(ctx, cmd, client, readGuard, items, source, filter, ids)
 			}
 
-			rootItem := findRootItem(items, itemID)
-			if rootItem == nil && itemID != "None" {
-				return fmt.Errorf("item not found: %s", itemID)
+			group, err := searchWithin(ctx, client, readGuard, items, filter, ids[0])
+			if err != nil {
+				return err
 			}
+			results := group.Results
 
-			searchRoot := items
-			if rootItem != nil {
-				searchRoot = []*workflowy.Item{rootItem}
+			if cmd.Bool("breadcrumb") {
+				workflowy.AddBreadcrumbs(results, items)
 			}
 
-			results := searchItems(
-				searchRoot,
-				pattern,
-				cmd.Bool("regexp"),
-				cmd.Bool("ignore-case"),
-			)
-
 			printOutput(results, format, false)
 			return nil
 		}),
@@ -828,7 +1544,14 @@ Examples:
   workflowy replace --interactive "pattern" "replacement"
 
   # Limit to a specific subtree
-  workflowy replace --parent-id=1a2b3c --depth=3 "pattern" "replacement"`,
+  workflowy replace --parent-id=1a2b3c --depth=3 "pattern" "replacement"
+
+  # Move matched text from the name to the note (or --move-to=name for the reverse)
+  workflowy replace --move-to=note " *#meeting-\d+" ""
+
+  # Save an undo patch, then revert the changes
+  workflowy replace --write-undo=undo.json "pattern" "replacement"
+  workflowy replace --apply-patch=undo.json`,
 		Arguments: []cli.Argument{
 			&cli.StringArg{
 				Name:      "pattern",
@@ -852,6 +1575,11 @@ Examples:
 				return err
 			}
 
+			if patchFile := cmd.String("apply-patch"); patchFile != "" {
+				opts := patch.Options{DryRun: cmd.Bool("dry-run")}
+				return applyPatchFile(ctx, client, guard, patchFile, cmd.String("write-undo"), format, opts)
+			}
+
 			pattern := cmd.StringArg("pattern")
 			if pattern == "" {
 				return fmt.Errorf("pattern is required")
@@ -859,6 +1587,11 @@ Examples:
 
 			substitution := cmd.StringArg("substitution")
 
+			moveTo := cmd.String("move-to")
+			if err := replace.ValidateMoveTo(moveTo); err != nil {
+				return err
+			}
+
 			if cmd.Bool("ignore-case") {
 				pattern = "(?i)" + pattern
 			}
@@ -868,7 +1601,7 @@ Examples:
 				return fmt.Errorf("invalid regular expression: %w", err)
 			}
 
-			items, err := loadTree(ctx, cmd, client)
+			items, _, err := loadTree(ctx, cmd, client)
 			if err != nil {
 				return err
 			}
@@ -898,16 +1631,27 @@ Examples:
 				Interactive: cmd.Bool("interactive"),
 				DryRun:      cmd.Bool("dry-run"),
 				Depth:       int(cmd.Int("depth")),
+				MoveTo:      moveTo,
 			}
 
 			var results []ReplaceResult
 			collectReplacements(searchRoot, opts, 0, &results)
+			for i := range results {
+				if err := guard.ValidateTarget(results[i].ID, "replace"); err != nil {
+					results[i].Skipped = true
+					results[i].SkipReason = err.Error()
+				}
+			}
+
+			if cmd.Bool("breadcrumb") {
+				workflowy.AddBreadcrumbs(results, items)
+			}
 
 			if len(results) == 0 {
 				if format == "json" {
 					fmt.Println("[]")
 				} else {
-					fmt.Println("No matches found")
+					printInfo("No matches found\n")
 				}
 				return nil
 			}
@@ -918,9 +1662,19 @@ Examples:
 			for i := range results {
 				result := &results[i]
 
+				if result.Skipped {
+					skippedCount++
+					continue
+				}
 				if opts.DryRun {
 					continue
 				}
+				if ctx.Err() != nil {
+					result.Skipped = true
+					result.SkipReason = "cancelled"
+					skippedCount++
+					continue
+				}
 
 				shouldApply := true
 				if opts.Interactive {
@@ -945,12 +1699,11 @@ Examples:
 				}
 
 				if shouldApply {
-					req := &workflowy.UpdateNodeRequest{
-						Name: &result.NewName,
-					}
+					req := replace.BuildUpdateRequest(result)
 					_, err := client.UpdateNode(ctx, result.ID, req)
 					if err != nil {
 						result.Skipped = true
+						result.Failed = true
 						result.SkipReason = fmt.Sprintf("update failed: %v", err)
 						skippedCount++
 						continue
@@ -960,6 +1713,13 @@ Examples:
 				}
 			}
 
+			undoFile := cmd.String("write-undo")
+			if undoFile != "" && !opts.DryRun {
+				if err := patch.WriteFile(undoFile, replace.UndoPatch(results)); err != nil {
+					return err
+				}
+			}
+
 			if format == "json" {
 				printJSON(results)
 			} else {
@@ -967,32 +1727,44 @@ Examples:
 					fmt.Println(result.String())
 				}
 				if opts.DryRun {
-					fmt.Printf("\nDry run: %d node(s) would be updated\n", len(results))
+					printInfo("\nDry run: %d node(s) would be updated\n", len(results))
 				} else {
-					fmt.Printf("\nUpdated %d node(s)", appliedCount)
+					printInfo("\nUpdated %d node(s)", appliedCount)
 					if skippedCount > 0 {
-						fmt.Printf(", skipped %d", skippedCount)
+						printInfo(", skipped %d", skippedCount)
+					}
+					printInfo("\n")
+					if undoFile != "" {
+						printInfo("Undo patch written to %s\n", undoFile)
 					}
-					fmt.Println()
 				}
 			}
 
-			return nil
+			return failedResults(results, func(r ReplaceResult) bool { return r.Failed }, "updates")
 		}),
 	}
 }
 
 func getMcpCommand() *cli.Command {
+	// Every mcp option can also be set from the environment, so the server can
+	// run in a container without flags.
+	apiKeyFlag := getAPIKeyFlag()
+	writeRootFlag := getWriteRootIdFlag().(*cli.StringFlag)
+	writeRootFlag.Sources = cli.EnvVars("WORKFLOWY_WRITE_ROOT_ID")
+	readRootFlag := getReadRootIdFlag().(*cli.StringFlag)
+	readRootFlag.Sources = cli.EnvVars("WORKFLOWY_READ_ROOT_ID")
+
 	return &cli.Command{
 		Name:      "mcp",
-		Usage:     "Run as MCP server (stdio transport)",
+		Usage:     "Run as MCP server (stdio or HTTP transport)",
 		UsageText: "workflowy mcp [options]",
 		Description: `Start the Workflowy MCP server for integration with AI assistants like Claude.
 
-The server communicates via stdio using the Model Context Protocol (MCP).
+The server communicates via stdio using the Model Context Protocol (MCP), or
+over streamable HTTP with --transport=http.
 
 Tool groups:
-  read   Get, List, Search, Targets, and Report tools (default)
+  read   Get, List, Search, Targets, Permissions, Limits, Export, and Report tools (default)
   write  Create, Update, Delete, Complete, Uncomplete, Replace, Transform tools
   all    All available tools
 
@@ -1000,18 +1772,114 @@ Examples:
   workflowy mcp                      # Read-only tools (safe)
   workflowy mcp --expose=all         # All tools including write operations
   workflowy mcp --expose=read,write  # Explicit groups
-  workflowy mcp --expose=get,list    # Specific tools only`,
+  workflowy mcp --expose=get,list    # Specific tools only
+  workflowy mcp --method=backup      # Read tools use the latest local backup
+  workflowy mcp --transport=http --addr=:8080 --auth-token-file=/run/secrets/mcp_token
+  workflowy mcp --state=memory       # Write nothing to disk, e.g. on a read-only filesystem`,
 		Flags: []cli.Flag{
-			getAPIKeyFlag(),
+			apiKeyFlag,
+			&cli.StringFlag{
+				Name:    "expose",
+				Value:   "read",
+				Usage:   "Tools to expose: read, write, all, or comma-separated tool names",
+				Sources: cli.EnvVars("WORKFLOWY_MCP_EXPOSE"),
+			},
+			writeRootFlag,
+			readRootFlag,
+			getTrashIDFlag(),
+			&cli.StringFlag{
+				Name:    "transport",
+				Value:   mcp.TransportStdio,
+				Usage:   "Transport: stdio or http",
+				Sources: cli.EnvVars("WORKFLOWY_MCP_TRANSPORT"),
+			},
+			&cli.StringFlag{
+				Name:    "addr",
+				Value:   mcp.DefaultAddr,
+				Usage:   "Listen address for --transport=http",
+				Sources: cli.EnvVars("WORKFLOWY_MCP_ADDR"),
+			},
 			&cli.StringFlag{
-				Name:  "expose",
-				Value: "read",
-				Usage: "Tools to expose: read, write, all, or comma-separated tool names",
+				Name:    "auth-token",
+				Usage:   "Bearer token required by the HTTP server",
+				Sources: cli.EnvVars("WORKFLOWY_MCP_AUTH_TOKEN"),
+			},
+			&cli.StringFlag{
+				Name:    "auth-token-file",
+				Usage:   "File containing the bearer token required by the HTTP server",
+				Sources: cli.EnvVars("WORKFLOWY_MCP_AUTH_TOKEN_FILE"),
+			},
+			&cli.StringFlag{
+				Name:    "oauth-issuer",
+				Usage:   "OpenID Connect issuer whose access tokens the HTTP server accepts",
+				Sources: cli.EnvVars("WORKFLOWY_MCP_OAUTH_ISSUER"),
+			},
+			&cli.StringFlag{
+				Name:    "oauth-audience",
+				Usage:   "Audience the issuer's access tokens (JWTs) must be issued for",
+				Sources: cli.EnvVars("WORKFLOWY_MCP_OAUTH_AUDIENCE"),
+			},
+			&cli.StringSliceFlag{
+				Name:    "oauth-allowed-users",
+				Usage:   "OAuth users accepted, by their --tenant-claim value (comma-separated)",
+				Sources: cli.EnvVars("WORKFLOWY_MCP_OAUTH_ALLOWED_USERS"),
+			},
+			&cli.BoolFlag{
+				Name:    "insecure-no-auth",
+				Usage:   "Serve HTTP requests without authentication, e.g. behind an authenticating proxy",
+				Sources: cli.EnvVars("WORKFLOWY_MCP_INSECURE_NO_AUTH"),
+			},
+			&cli.StringFlag{
+				Name:    "tenants-file",
+				Usage:   "JSON file mapping OAuth users to their sandbox root, e.g. {\"alice@example.com\": {\"root_id\": \"abc123\"}}",
+				Sources: cli.EnvVars("WORKFLOWY_MCP_TENANTS_FILE"),
+			},
+			&cli.StringFlag{
+				Name:    "tenant-claim",
+				Value:   mcp.DefaultTenantClaim,
+				Usage:   "Userinfo claim identifying OAuth users, e.g. sub or email",
+				Sources: cli.EnvVars("WORKFLOWY_MCP_TENANT_CLAIM"),
+			},
+			&cli.StringFlag{
+				Name:    "tenant-parent-id",
+				Usage:   "Node under which a sandbox named after each OAuth user is created on first use",
+				Sources: cli.EnvVars("WORKFLOWY_MCP_TENANT_PARENT_ID"),
+			},
+			&cli.StringFlag{
+				Name:    "method",
+				Usage:   "Access method for read tools: get, export, backup or cache\n\tDefaults to 'get' for depth 1-3 and 'export' for depth 4+",
+				Sources: cli.EnvVars("WORKFLOWY_MCP_METHOD"),
+			},
+			&cli.StringFlag{
+				Name:    "backup-file",
+				Usage:   "Path to backup file read by --method=backup (default: latest in Dropbox/Apps/Workflowy/Data)",
+				Sources: cli.EnvVars("WORKFLOWY_BACKUP_FILE"),
+			},
+			&cli.StringFlag{
+				Name:    "state",
+				Value:   mcp.StateDisk,
+				Usage:   "Where caches, the undo journal and usage statistics are kept: disk (the configured storage) or memory (nothing written to disk)",
+				Sources: cli.EnvVars("WORKFLOWY_MCP_STATE"),
 			},
-			getWriteRootIdFlag(),
-			getReadRootIdFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
+			authToken := cmd.String("auth-token")
+			if tokenFile := cmd.String("auth-token-file"); tokenFile != "" {
+				data, err := os.ReadFile(workflowy.ExpandTilde(tokenFile))
+				if err != nil {
+					return fmt.Errorf("cannot read auth token file: %w", err)
+				}
+				authToken = strings.TrimSpace(string(data))
+			}
+
+			var tenants map[string]mcp.Tenant
+			if tenantsFile := cmd.String("tenants-file"); tenantsFile != "" {
+				var err error
+				if tenants, err = mcp.ReadTenantsFile(tenantsFile); err != nil {
+					return err
+				}
+			}
+
 			serverConfig := mcp.Config{
 				APIKeyFile:        cmd.String("api-key-file"),
 				DefaultAPIKeyFile: defaultAPIKeyFile,
@@ -1019,6 +1887,23 @@ Examples:
 				Version:           version,
 				WriteRootID:       cmd.String("write-root-id"),
 				ReadRootID:        cmd.String("read-root-id"),
+				Transport:         cmd.String("transport"),
+				Addr:              cmd.String("addr"),
+				AuthToken:         authToken,
+				OAuthIssuer:       cmd.String("oauth-issuer"),
+				OAuthAudience:     cmd.String("oauth-audience"),
+				OAuthAllowedUsers: cmd.StringSlice("oauth-allowed-users"),
+				InsecureNoAuth:    cmd.Bool("insecure-no-auth"),
+				Tenants:           tenants,
+				TenantClaim:       cmd.String("tenant-claim"),
+				TenantParentID:    cmd.String("tenant-parent-id"),
+				Method:            cmd.String("method"),
+				BackupFile:        cmd.String("backup-file"),
+				MaxBackupAge:      cmd.Duration("max-backup-age"),
+				Force:             cmd.Bool("force"),
+				UsageStats:        cmd.Bool("usage-stats"),
+				TrashID:           cmd.String("trash-id"),
+				State:             cmd.String("state"),
 			}
 			return mcp.RunServer(ctx, serverConfig)
 		},
@@ -1056,12 +1941,89 @@ func getIDCommand() *cli.Command {
 	}
 }
 
+func getHashCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "hash",
+		Usage:     "Print a content hash of a node and its descendants",
+		UsageText: "workflowy hash [<id>] [options]",
+		Description: `Prints a SHA-256 of the names, notes, completion and order of a node and all
+its descendants. The hash changes whenever anything under the node does, and
+is the same whether read from the export API or a backup, so sync tools and
+caches can check a subtree for changes with a single comparison.`,
+		Arguments: getFetchArguments(),
+		Flags:     getMethodFlags(),
+		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+
+			readGuard, err := NewReadGuard(ctx, client, getReadRootID(cmd))
+			if err != nil {
+				return err
+			}
+
+			itemID, err := workflowy.ResolveNodeID(ctx, client, readGuard.DefaultID(cmd.StringArg("id")))
+			if err != nil {
+				return fmt.Errorf("cannot resolve ID: %w", err)
+			}
+
+			if err := readGuard.ValidateTarget(itemID, "hash"); err != nil {
+				return err
+			}
+
+			snapshot, err := loadSnapshot(ctx, cmd, client, workflowy.DefaultBackupProvider)
+			if err != nil {
+				return err
+			}
+			item, err := snapshot.Root(itemID)
+			if err != nil {
+				return err
+			}
+
+			hash := workflowy.SubtreeHash(item)
+			if format == "json" {
+				printJSON(map[string]any{"id": item.ID, "hash": hash, "snapshot": snapshot.Meta()})
+				return nil
+			}
+			fmt.Println(hash)
+			return nil
+		}),
+	}
+}
+
+// versionInfo describes the binary to tools that adapt to its capabilities.
+// Fields may be added but are never renamed or removed.
+type versionInfo struct {
+	Version  string   `json:"version"`
+	Commit   string   `json:"commit"`
+	Date     string   `json:"date"`
+	Formats  []string `json:"formats"`
+	Methods  []string `json:"methods"`
+	MCPTools []string `json:"mcp_tools"`
+}
+
 func getVersionCommand() *cli.Command {
 	return &cli.Command{
 		Name:      "version",
 		Usage:     "Show version information",
-		UsageText: "workflowy version",
+		UsageText: "workflowy version [--format=json]",
 		Action: func(ctx context.Context, cmd *cli.Command) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+			if format == "json" {
+				printJSON(versionInfo{
+					Version:  version,
+					Commit:   commit,
+					Date:     date,
+					Formats:  []string{"list", "json", "markdown"},
+					Methods:  []string{"get", "export", "backup", "session", "cache"},
+					MCPTools: mcp.ToolNames(),
+				})
+				return nil
+			}
 			fmt.Printf("workflowy version %s\n", version)
 			fmt.Printf("commit: %s\n", commit)
 			fmt.Printf("built: %s\n", date)
@@ -1070,12 +2032,20 @@ func getVersionCommand() *cli.Command {
 	}
 }
 
-func createClient(apiKeyFile string) (*workflowy.WorkflowyClient, error) {
-	option, err := workflowy.ResolveAPIKey(apiKeyFile, defaultAPIKeyFile)
+func createClient(cmd *cli.Command) (*workflowy.WorkflowyClient, error) {
+	policy, err := workflowy.ParseOversizePolicy(cmd.String("oversize"))
+	if err != nil {
+		return nil, err
+	}
+	option, err := workflowy.ResolveAPIKey(cmd.String("api-key-file"), defaultAPIKeyFile)
 	if err != nil {
 		return nil, err
 	}
-	return workflowy.NewWorkflowyClient(option), nil
+	client := workflowy.NewWorkflowyClient(option, client.WithRetry(cmd.Int("retries"), client.DefaultRetryDelay))
+	limits := workflowy.DefaultLengthLimits
+	limits.Policy = policy
+	client.SetLengthLimits(limits)
+	return client, nil
 }
 
 type ClientActionFunc func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error
@@ -1084,21 +2054,37 @@ type ClientProvider func(ClientActionFunc) cli.ActionFunc
 
 func withClient(fn ClientActionFunc) cli.ActionFunc {
 	return func(ctx context.Context, cmd *cli.Command) error {
-		client, err := createClient(cmd.String("api-key-file"))
+		client, err := createClient(cmd)
 		if err != nil {
 			return err
 		}
-		return fn(ctx, cmd, client)
+		return fn(ctx, cmd, journaled(cmd, client))
 	}
 }
 
 func withOptionalClient(fn ClientActionFunc) cli.ActionFunc {
 	return func(ctx context.Context, cmd *cli.Command) error {
-		client, err := createClient(cmd.String("api-key-file"))
+		client, err := createClient(cmd)
 		if err != nil {
 			slog.Warn("cannot create API client -- using backup method", "error", err)
 			return fn(ctx, cmd, nil)
 		}
-		return fn(ctx, cmd, client)
+		return fn(ctx, cmd, journaled(cmd, client))
 	}
 }
+
+// journaled records the writes made through client in the journal, so that
+// `workflowy undo` can revert them.
+func journaled(cmd *cli.Command, client workflowy.Client) workflowy.Client {
+	j, err := journal.OpenDefault()
+	if err != nil {
+		slog.Warn("cannot open journal -- writes will not be undoable", "error", err)
+		return client
+	}
+	return journal.NewClient(client, j, commandName(cmd))
+}
+
+// commandName returns the name of cmd without the program name, e.g. "report count".
+func commandName(cmd *cli.Command) string {
+	return strings.TrimPrefix(cmd.FullName(), cmd.Root().Name+" ")
+}
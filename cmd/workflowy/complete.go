@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/complete"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+// completeMatching completes or uncompletes every node selected by --pattern
+// and --older-than, as replace does with its matches.
+func completeMatching(ctx context.Context, cmd *cli.Command, client workflowy.Client, guard *WriteGuard, commandName string) error {
+	format := cmd.String("format")
+	opts := complete.Options{
+		Complete: commandName == "complete",
+		Now:      time.Now(),
+		Depth:    int(cmd.Int("depth")),
+	}
+
+	if pattern := cmd.String("pattern"); pattern != "" {
+		if cmd.Bool("ignore-case") {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid regular expression: %w", err)
+		}
+		opts.Pattern = re
+	}
+	if age := cmd.String("older-than"); age != "" {
+		olderThan, err := complete.ParseAge(age)
+		if err != nil {
+			return err
+		}
+		opts.OlderThan = olderThan
+	}
+	if opts.Pattern == nil && opts.OlderThan == 0 {
+		return fmt.Errorf("--pattern or --older-than must select nodes")
+	}
+
+	items, _, err := loadTree(ctx, cmd, client)
+	if err != nil {
+		return err
+	}
+
+	parentID, err := workflowy.ResolveNodeID(ctx, client, getParentID(cmd))
+	if err != nil {
+		return fmt.Errorf("cannot resolve parent ID: %w", err)
+	}
+	if err := guard.ValidateParent(parentID, commandName); err != nil {
+		return err
+	}
+
+	searchRoot := items
+	if parentID != "None" {
+		rootItem := findItemByID(items, parentID)
+		if rootItem == nil {
+			return fmt.Errorf("parent item not found: %s", parentID)
+		}
+		searchRoot = []*workflowy.Item{rootItem}
+	}
+
+	var results []complete.Result
+	complete.Collect(searchRoot, opts, 0, &results)
+	for i := range results {
+		if err := guard.ValidateTarget(results[i].ID, commandName); err != nil {
+			results[i].Skipped = true
+			results[i].SkipReason = err.Error()
+		}
+	}
+
+	if cmd.Bool("breadcrumb") {
+		workflowy.AddBreadcrumbs(results, items)
+	}
+
+	if len(results) == 0 {
+		if format == "json" {
+			fmt.Println("[]")
+		} else {
+			printInfo("No matching nodes found\n")
+		}
+		return nil
+	}
+
+	dryRun := cmd.Bool("dry-run")
+	applied := 0
+	if !dryRun {
+		applied = complete.Apply(ctx, client, results, opts.Complete)
+	}
+
+	if format == "json" {
+		printJSON(results)
+	} else {
+		for _, result := range results {
+			fmt.Println(result.String())
+		}
+		if dryRun {
+			printInfo("\nDry run: %d node(s) would be %sd\n", len(results), commandName)
+		} else {
+			printInfo("\n%s %d node(s)", strings.ToUpper(commandName[:1])+commandName[1:]+"d", applied)
+			if skipped := len(results) - applied; skipped > 0 {
+				printInfo(", skipped %d", skipped)
+			}
+			printInfo("\n")
+		}
+	}
+
+	return failedResults(results, func(r complete.Result) bool { return r.Failed }, commandName+"s")
+}
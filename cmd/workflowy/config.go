@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mholzen/workflowy/pkg/config"
+	"github.com/mholzen/workflowy/pkg/paths"
+	"github.com/urfave/cli/v3"
+)
+
+// configValue is the effective value of a setting, for `config list`.
+type configValue struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"` // env, file or default
+	Env    string `json:"env"`
+}
+
+// defaultConfigValue returns the value of key when neither its environment
+// variable nor the configuration file sets it.
+func defaultConfigValue(key string) (string, error) {
+	switch key {
+	case "backup_dir":
+		dirs, err := paths.BackupDirs()
+		return strings.Join(dirs, string(os.PathListSeparator)), err
+	case "cache_dir":
+		return paths.CacheDir()
+	case "storage":
+		return paths.Storage()
+	case "api_key_file":
+		return paths.APIKeyFile()
+	case "format":
+		return "list", nil
+	case "depth":
+		return "2", nil
+	case "fallback":
+		return defaultFallback, nil
+	}
+	return "", nil
+}
+
+func getConfigValues(c *config.Config) ([]configValue, error) {
+	values := make([]configValue, 0, len(config.Settings))
+	for _, setting := range config.Settings {
+		value := configValue{Key: setting.Key, Env: setting.Env}
+		if env := os.Getenv(setting.Env); env != "" {
+			value.Value, value.Source = env, "env"
+		} else if v, _ := c.Get(setting.Key); v != "" {
+			value.Value, value.Source = v, "file"
+		} else {
+			v, err := defaultConfigValue(setting.Key)
+			if err != nil {
+				return nil, err
+			}
+			value.Value, value.Source = v, "default"
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func formatConfigValues(values []configValue) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, value := range values {
+		source := value.Source
+		if source == "env" {
+			source = value.Env
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", value.Key, value.Value, source)
+	}
+	w.Flush()
+	return sb.String()
+}
+
+func listConfig(ctx context.Context, cmd *cli.Command) error {
+	format := cmd.String("format")
+	if err := validateFormat(format); err != nil {
+		return err
+	}
+	c, err := config.LoadDefault()
+	if err != nil {
+		return err
+	}
+	values, err := getConfigValues(c)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		printJSON(values)
+		return nil
+	}
+	fmt.Print(formatConfigValues(values))
+	return nil
+}
+
+// updateConfig sets key to value in the configuration file; an empty value unsets it.
+func updateConfig(key, value string) error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	c, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	if err := c.Set(key, value); err != nil {
+		return err
+	}
+	return c.Save(path)
+}
+
+func getConfigCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "config",
+		Usage:     "View and change the settings of ~/.workflowy/config.yaml",
+		UsageText: "workflowy config [list|get|set|unset|path]",
+		Description: `The configuration file sets defaults read at startup:
+
+  backup_dir     Directories searched for backups
+  cache_dir      Directory holding caches and state files
+  storage        Where caches and state files are kept, if not in cache_dir:
+                 a directory, or memory to write nothing
+  api_key_file   File containing the API key
+  format         Default --format: list, json or markdown
+  depth          Default --depth of get and list
+
+Each setting has an environment variable that takes precedence over the file
+(WORKFLOWY_BACKUP_DIR, WORKFLOWY_CACHE_DIR, WORKFLOWY_STORAGE,
+WORKFLOWY_API_KEY_FILE, WORKFLOWY_FORMAT, WORKFLOWY_DEPTH), and command line
+flags take precedence over both.
+
+Examples:
+  workflowy config
+  workflowy config set format markdown
+  workflowy config set backup_dir ~/Dropbox/Apps/Workflowy/Data
+  workflowy config unset depth`,
+		Action: listConfig,
+		Commands: []*cli.Command{
+			{
+				Name:      "list",
+				Usage:     "Show the effective value of each setting and where it comes from",
+				UsageText: "workflowy config list",
+				Action:    listConfig,
+			},
+			{
+				Name:      "get",
+				Usage:     "Print the value of a setting in the configuration file",
+				UsageText: "workflowy config get <key>",
+				Arguments: []cli.Argument{
+					&cli.StringArg{Name: "key"},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					key := cmd.StringArg("key")
+					if key == "" {
+						return fmt.Errorf("key is required")
+					}
+					c, err := config.LoadDefault()
+					if err != nil {
+						return err
+					}
+					value, err := c.Get(key)
+					if err != nil {
+						return err
+					}
+					fmt.Println(value)
+					return nil
+				},
+			},
+			{
+				Name:      "set",
+				Usage:     "Set a setting in the configuration file",
+				UsageText: "workflowy config set <key> <value>",
+				Arguments: []cli.Argument{
+					&cli.StringArg{Name: "key"},
+					&cli.StringArg{Name: "value"},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					key, value := cmd.StringArg("key"), cmd.StringArg("value")
+					if key == "" || value == "" {
+						return fmt.Errorf("key and value are required (use `workflowy config unset` to remove a setting)")
+					}
+					if err := updateConfig(key, value); err != nil {
+						return err
+					}
+					printInfo("Set %s to %s\n", key, value)
+					return nil
+				},
+			},
+			{
+				Name:      "unset",
+				Usage:     "Remove a setting from the configuration file",
+				UsageText: "workflowy config unset <key>",
+				Arguments: []cli.Argument{
+					&cli.StringArg{Name: "key"},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					key := cmd.StringArg("key")
+					if key == "" {
+						return fmt.Errorf("key is required")
+					}
+					if err := updateConfig(key, ""); err != nil {
+						return err
+					}
+					printInfo("Unset %s\n", key)
+					return nil
+				},
+			},
+			{
+				Name:      "path",
+				Usage:     "Print the path of the configuration file",
+				UsageText: "workflowy config path",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					path, err := config.Path()
+					if err != nil {
+						return err
+					}
+					fmt.Println(path)
+					return nil
+				},
+			},
+		},
+	}
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/mholzen/workflowy/pkg/workflowy"
 	"github.com/stretchr/testify/assert"
@@ -252,3 +254,79 @@ func TestCountReportCommand_PreserveHTMLTags(t *testing.T) {
 	assert.Contains(t, outputStr, "<b>Bold Parent</b>", "HTML tags should be preserved with --preserve-tags")
 	assert.Contains(t, outputStr, "<i>Italic</i>", "HTML tags should be preserved with --preserve-tags")
 }
+
+func TestCountReportCommand_TitleAndNoteCarrySnapshotTime(t *testing.T) {
+	testItems := []*workflowy.Item{
+		{ID: "abc123", Name: "Parent Item", ModifiedAt: 1700000000},
+	}
+
+	var output bytes.Buffer
+	deps := ReportDeps{
+		BackupProvider: &MockBackupProvider{Items: testItems},
+		Output:         &output,
+	}
+
+	cmd := getCountReportCommandWithDeps(deps, withOptionalClient)
+	err := cmd.Run(context.Background(), []string{"count", "--method=backup", "--threshold=0"})
+	assert.NoError(t, err)
+
+	takenAt := time.Unix(1700000000, 0).Format("2006-01-02 15:04:05")
+	assert.Contains(t, output.String(), "(threshold: 0.00%) - "+takenAt)
+	assert.Contains(t, output.String(), "Snapshot: "+takenAt+" (backup)")
+}
+
+func TestCountReportCommand_JSONCarriesDataSource(t *testing.T) {
+	var output bytes.Buffer
+	deps := ReportDeps{
+		BackupProvider: &MockBackupProvider{Items: []*workflowy.Item{{ID: "abc123", Name: "Parent Item", ModifiedAt: 1700000000}}},
+		Output:         &output,
+	}
+
+	root := &cli.Command{
+		Name:     "workflowy",
+		Flags:    []cli.Flag{&cli.StringFlag{Name: "format", Value: "list"}},
+		Commands: []*cli.Command{getCountReportCommandWithDeps(deps, withOptionalClient)},
+	}
+	err := root.Run(context.Background(), []string{"workflowy", "--format=json", "count", "--method=backup", "--threshold=0"})
+	assert.NoError(t, err)
+	assert.Contains(t, output.String(), `"data_source": "backup"`)
+}
+
+type filesBackupProvider map[string][]*workflowy.Item
+
+func (p filesBackupProvider) ReadBackupFile(filename string) ([]*workflowy.Item, error) {
+	return p[filename], nil
+}
+
+func (p filesBackupProvider) ReadLatestBackup() ([]*workflowy.Item, error) {
+	return nil, fmt.Errorf("no latest backup")
+}
+
+func TestCountReportCommand_CompareShowsDeltas(t *testing.T) {
+	provider := filesBackupProvider{
+		"old.backup": {
+			{ID: "inbox", Name: "Inbox", ModifiedAt: 1700000000, Children: []*workflowy.Item{
+				{ID: "a", Name: "A"}, {ID: "b", Name: "B"}, {ID: "c", Name: "C"},
+			}},
+		},
+		"new.backup": {
+			{ID: "inbox", Name: "Inbox", ModifiedAt: 1700086400, Children: []*workflowy.Item{
+				{ID: "a", Name: "A"},
+			}},
+			{ID: "later", Name: "Later"},
+		},
+	}
+
+	var output bytes.Buffer
+	deps := ReportDeps{BackupProvider: provider, Output: &output}
+
+	cmd := getCountReportCommandWithDeps(deps, withOptionalClient)
+	err := cmd.Run(context.Background(), []string{"count", "--method=backup", "--backup-file=new.backup", "--compare=old.backup", "--threshold=0"})
+	assert.NoError(t, err)
+
+	outputStr := output.String()
+	assert.Contains(t, outputStr, "Descendant Count Comparison")
+	assert.Contains(t, outputStr, "[Root](https://workflowy.com/#/root) (5 → 4 descendants, -1)")
+	assert.Contains(t, outputStr, "[Inbox](https://workflowy.com/#/inbox) (4 → 2 descendants, -2)")
+	assert.Contains(t, outputStr, "[Later](https://workflowy.com/#/later) (1 descendants, new)")
+}
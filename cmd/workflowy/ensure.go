@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+func getEnsureCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "ensure",
+		Usage:     "Find or create a path of nodes and print the ID of the last one",
+		UsageText: "workflowy ensure <path> [options]",
+		Description: `Walks a path of names separated by "/" down from --parent-id, creating the
+nodes missing at the bottom of their parent, and prints the ID of the last
+one. Existing nodes are found by name, ignoring surrounding spaces, so running
+the command again creates nothing. Write "\/" for a slash within a name.
+
+Examples:
+  workflowy ensure "Journal/2026/February/10"
+  workflowy create --parent-id=$(workflowy ensure Projects/Home) "Fix the gate"
+  workflowy ensure "Reading/Books\/Articles" --parent-id=inbox`,
+		Arguments: []cli.Argument{
+			&cli.StringArg{
+				Name:      "path",
+				UsageText: "Names separated by /",
+			},
+		},
+		Flags: []cli.Flag{
+			getAPIKeyFlag(),
+			&cli.StringFlag{
+				Name:  "parent-id",
+				Value: "None",
+				Usage: "Node the path starts from: UUID or target key (default: root)",
+			},
+		},
+		Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+			names, err := workflowy.SplitPath(cmd.StringArg("path"))
+			if err != nil {
+				return err
+			}
+
+			guard, err := NewWriteGuard(ctx, client, getWriteRootID(cmd))
+			if err != nil {
+				return err
+			}
+			parentID, err := workflowy.ResolveNodeID(ctx, client, guard.DefaultParent(getParentID(cmd)))
+			if err != nil {
+				return fmt.Errorf("cannot resolve parent ID: %w", err)
+			}
+			if err := guard.ValidateParent(parentID, "ensure"); err != nil {
+				return err
+			}
+
+			slog.Debug("ensuring path", "parent_id", parentID, "names", names)
+			id, created, err := workflowy.FindOrCreatePath(ctx, client, parentID, names)
+			if err != nil {
+				if len(created) > 0 {
+					printInfo("created %d node(s) before failing: %v\n", len(created), created)
+				}
+				return err
+			}
+
+			if format == "json" {
+				if created == nil {
+					created = []string{}
+				}
+				printJSON(map[string]any{"id": id, "created": created})
+				return nil
+			}
+			fmt.Println(id)
+			if len(created) > 0 {
+				printInfo("created %d node(s)\n", len(created))
+			}
+			return nil
+		}),
+	}
+}
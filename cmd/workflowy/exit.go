@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mholzen/workflowy/pkg/client"
+)
+
+// Process exit codes
+const (
+	exitFailure        = 1   // the command failed
+	exitPartialFailure = 2   // the command ran but some nodes could not be updated
+	exitInterrupted    = 130 // the command was stopped by SIGINT or SIGTERM
+)
+
+// exitError attaches a process exit code to an error.
+type exitError struct {
+	err  error
+	code int
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// partialFailure reports that failed of total operations failed.
+func partialFailure(failed, total int, operation string) error {
+	return &exitError{
+		err:  fmt.Errorf("%d of %d %s failed", failed, total, operation),
+		code: exitPartialFailure,
+	}
+}
+
+// failedResults returns a partialFailure for the results that failed, nil
+// if none did.
+func failedResults[R any](results []R, hasFailed func(R) bool, operation string) error {
+	failed := 0
+	for _, result := range results {
+		if hasFailed(result) {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return partialFailure(failed, len(results), operation)
+}
+
+// exitCode returns the process exit code for err.
+func exitCode(err error) int {
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return exitFailure
+}
+
+// errorResponse is the JSON form of a command failure.
+type errorResponse struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Message  string `json:"message"`
+	ExitCode int    `json:"exit_code"`
+	Status   int    `json:"status,omitempty"` // HTTP status of a failed API call
+}
+
+// printError writes err as a JSON error object.
+func printError(w io.Writer, err error) {
+	detail := errorDetail{Message: err.Error(), ExitCode: exitCode(err)}
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		detail.Status = apiErr.Status
+	}
+	printJSONToWriter(w, errorResponse{Error: detail})
+}
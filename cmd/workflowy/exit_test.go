@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, exitFailure, exitCode(errors.New("boom")))
+	assert.Equal(t, exitPartialFailure, exitCode(partialFailure(1, 3, "updates")))
+	assert.Equal(t, exitPartialFailure, exitCode(fmt.Errorf("wrapped: %w", partialFailure(1, 3, "updates"))))
+}
+
+func TestFailedResults(t *testing.T) {
+	failed := func(ok bool) bool { return !ok }
+	assert.NoError(t, failedResults([]bool{true, true}, failed, "updates"))
+	err := failedResults([]bool{true, false, false}, failed, "updates")
+	assert.EqualError(t, err, "2 of 3 updates failed")
+	assert.Equal(t, exitPartialFailure, exitCode(err))
+}
+
+func TestPrintError_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	printError(&buf, fmt.Errorf("cannot get node: %w", &client.APIError{Status: 404, Body: "not found"}))
+
+	var resp errorResponse
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
+	assert.Equal(t, "cannot get node: api 404: not found", resp.Error.Message)
+	assert.Equal(t, exitFailure, resp.Error.ExitCode)
+	assert.Equal(t, 404, resp.Error.Status)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/config"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+// defaultFallback is the --fallback of reads when neither the configuration
+// nor WORKFLOWY_FALLBACK sets it.
+const defaultFallback = "backup"
+
+func getFallbackFlag() *cli.StringFlag {
+	value := defaultFallback
+	if userConfig.Fallback != "" {
+		value = userConfig.Fallback
+	}
+	return &cli.StringFlag{
+		Name:    "fallback",
+		Value:   value,
+		Usage:   "Sources tried in order when the access method fails: get, export, cache or backup, separated by commas, or none\n\tWith --method, reads do not fall back unless this is set",
+		Sources: cli.EnvVars("WORKFLOWY_FALLBACK"),
+	}
+}
+
+// readSources returns primary followed by the sources of --fallback, without
+// those that usable rejects, with the reason why. An explicit --method only
+// falls back when --fallback is given or configured.
+func readSources(cmd *cli.Command, primary string, usable func(source string) error) ([]string, error) {
+	sources := []string{primary}
+	value := cmd.String("fallback")
+	if value == "" || (cmd.String("method") != "" && !cmd.IsSet("fallback") && userConfig.Fallback == "") {
+		return sources, nil
+	}
+	fallbacks, err := config.ParseFallback(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --fallback: %w", err)
+	}
+	for _, source := range fallbacks {
+		if slices.Contains(sources, source) {
+			continue
+		}
+		if err := usable(source); err != nil {
+			slog.Debug("skipping fallback source", "source", source, "reason", err)
+			continue
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// readWithFallback reads from each of sources in turn until one succeeds, and
+// logs which one served the data. A node that is not found is not looked for
+// in the next sources.
+func readWithFallback[T any](sources []string, read func(source string) (T, error)) (T, error) {
+	for i, source := range sources {
+		result, err := read(source)
+		if err == nil {
+			if i > 0 {
+				slog.Info("read from fallback source", "source", source)
+			} else {
+				slog.Debug("read from source", "source", source)
+			}
+			return result, nil
+		}
+		if i == len(sources)-1 || errors.Is(err, workflowy.ErrNotFound) {
+			return result, err
+		}
+		slog.Warn(fmt.Sprintf("%s failed, falling back to %s", source, sources[i+1]), "error", err)
+	}
+	var zero T
+	return zero, fmt.Errorf("no source to read from")
+}
+
+// sourcedJSON is data followed by the source it was read from, as data_source,
+// and how old that was when printed, as data_age in seconds.
+type sourcedJSON struct {
+	data   interface{}
+	source workflowy.SnapshotMeta
+}
+
+func withDataSource(data interface{}, source workflowy.SnapshotMeta) interface{} {
+	if source.Source == "" {
+		return data
+	}
+	return sourcedJSON{data: data, source: source}
+}
+
+func (s sourcedJSON) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(s.data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2 || data[0] != '{' {
+		return data, nil
+	}
+	fields, err := json.Marshal(struct {
+		Source string `json:"data_source"`
+		Age    int64  `json:"data_age"`
+	}{s.source.Source, max(int64(time.Since(s.source.TakenAt).Seconds()), 0)})
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "{}" {
+		return fields, nil
+	}
+	return append(append(data[:len(data)-1], ','), fields[1:]...), nil
+}
@@ -4,17 +4,25 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
+	"github.com/mholzen/workflowy/pkg/mirror"
 	"github.com/mholzen/workflowy/pkg/workflowy"
 	"github.com/urfave/cli/v3"
 )
 
 func fetchItems(cmd *cli.Command, apiCtx context.Context, client workflowy.Client, itemID string, depth int) (interface{}, error) {
+	result, _, err := fetchItemsWithSource(cmd, apiCtx, client, itemID, depth)
+	return result, err
+}
+
+// fetchItemsWithSource fetches like fetchItems, and returns which source
+// served the data and when it was taken.
+func fetchItemsWithSource(cmd *cli.Command, apiCtx context.Context, client workflowy.Client, itemID string, depth int) (interface{}, workflowy.SnapshotMeta, error) {
 	method := cmd.String("method")
-	backupFile := cmd.String("backup-file")
 
-	if method != "" && method != "get" && method != "export" && method != "backup" {
-		return nil, fmt.Errorf("method must be 'get', 'export', or 'backup'")
+	if method != "" && method != "get" && method != "export" && method != "backup" && method != "cache" {
+		return nil, workflowy.SnapshotMeta{}, fmt.Errorf("method must be 'get', 'export', 'backup' or 'cache'")
 	}
 
 	var useMethod string
@@ -31,27 +39,58 @@ func fetchItems(cmd *cli.Command, apiCtx context.Context, client workflowy.Clien
 	}
 
 	if client == nil && (useMethod == "get" || useMethod == "export") {
-		return nil, fmt.Errorf("cannot use method '%s' without using the API", useMethod)
+		return nil, workflowy.SnapshotMeta{}, fmt.Errorf("cannot use method '%s' without using the API", useMethod)
 	}
 
 	slog.Debug("access method determined", "method", useMethod, "depth", depth)
 
-	var result interface{}
+	sources, err := readSources(cmd, useMethod, func(source string) error {
+		if client == nil && (source == "get" || source == "export") {
+			return fmt.Errorf("no API client")
+		}
+		if source == "get" && depth < 0 {
+			return fmt.Errorf("the GET API needs a depth")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, workflowy.SnapshotMeta{}, err
+	}
+
+	type sourced struct {
+		result interface{}
+		meta   workflowy.SnapshotMeta
+	}
+	fetched, err := readWithFallback(sources, func(source string) (sourced, error) {
+		result, meta, err := fetchFrom(cmd, apiCtx, client, source, itemID, depth)
+		return sourced{result, meta}, err
+	})
+	return fetched.result, fetched.meta, err
+}
+
+// fetchFrom fetches itemID down to depth from a single source.
+func fetchFrom(cmd *cli.Command, apiCtx context.Context, client workflowy.Client, source string, itemID string, depth int) (interface{}, workflowy.SnapshotMeta, error) {
+	meta := workflowy.SnapshotMeta{Source: source, TakenAt: time.Now()}
 
-	switch useMethod {
+	switch source {
 	case "backup":
-		return fetchFromBackup(backupFile, itemID, depth)
+		return fetchFromBackup(cmd.String("backup-file"), itemID, depth)
 
-	case "export":
-		slog.Debug("using export API", "depth", depth)
-		forceRefresh := cmd.Bool("force-refresh")
-		response, err := client.ExportNodesWithCache(apiCtx, forceRefresh)
+	case "export", "cache":
+		var response *workflowy.ExportNodesResponse
+		var err error
+		if source == "cache" {
+			slog.Debug("using tree cache", "depth", depth)
+			response, err = workflowy.ReadTreeCache()
+		} else {
+			slog.Debug("using export API", "depth", depth)
+			response, err = client.ExportNodesWithCache(apiCtx, cmd.Bool("force-refresh"))
+		}
 		if err != nil {
-			if method == "" {
-				slog.Warn("export failed, falling back to backup", "error", err)
-				return fetchFromBackup(backupFile, itemID, depth)
-			}
-			return nil, fmt.Errorf("cannot export nodes: %w", err)
+			return nil, meta, fmt.Errorf("cannot export nodes: %w", err)
+		}
+		if !response.FetchedAt.IsZero() {
+			meta.TakenAt = response.FetchedAt
 		}
 
 		slog.Debug("reconstructing tree from export data")
@@ -60,84 +99,113 @@ func fetchItems(cmd *cli.Command, apiCtx context.Context, client workflowy.Clien
 		if itemID != "None" {
 			found := workflowy.FindItemInTree(root.Children, itemID, depth)
 			if found == nil {
-				return nil, fmt.Errorf("item %s not found", itemID)
+				return nil, meta, workflowy.Errorf(workflowy.ErrNotFound, "item %s not found", itemID)
 			}
-			result = found
-		} else {
-			if depth >= 0 {
-				slog.Debug("limiting depth for export results", "depth", depth, "item_count", len(root.Children))
-				workflowy.LimitItemsDepth(root.Children, depth)
-			}
-			result = &workflowy.ListChildrenResponse{Items: root.Children}
+			return found, meta, nil
+		}
+		if depth >= 0 {
+			slog.Debug("limiting depth for export results", "depth", depth, "item_count", len(root.Children))
+			workflowy.LimitItemsDepth(root.Children, depth)
 		}
+		return &workflowy.ListChildrenResponse{Items: root.Children}, meta, nil
 
 	case "get":
 		slog.Debug("using GET API", "depth", depth)
 		if depth < 0 {
-			return nil, fmt.Errorf("depth must be non-negative when using GET API (use --method=export for depth=-1)")
+			return nil, meta, fmt.Errorf("depth must be non-negative when using GET API (use --method=export for depth=-1)")
 		}
 
-		var err error
 		if itemID == "None" {
 			slog.Debug("fetching root items", "depth", depth)
-			result, err = client.ListChildrenRecursiveWithDepth(apiCtx, itemID, depth)
+			result, err := client.ListChildrenRecursiveWithDepth(apiCtx, itemID, depth)
 			if err != nil {
-				if method == "" {
-					slog.Warn("get API failed, falling back to backup", "error", err)
-					return fetchFromBackup(backupFile, itemID, depth)
-				}
-				return nil, fmt.Errorf("cannot fetch root items: %w", err)
+				return nil, meta, fmt.Errorf("cannot fetch root items: %w", err)
 			}
-		} else {
-			slog.Debug("fetching item", "item_id", itemID, "depth", depth)
-			item, err := client.GetItem(apiCtx, itemID)
+			return result, meta, nil
+		}
+
+		slog.Debug("fetching item", "item_id", itemID, "depth", depth)
+		item, err := client.GetItem(apiCtx, itemID)
+		if err != nil {
+			return nil, meta, fmt.Errorf("cannot get item: %w", err)
+		}
+		if depth > 0 {
+			childrenResp, err := client.ListChildrenRecursiveWithDepth(apiCtx, itemID, depth)
 			if err != nil {
-				if method == "" {
-					slog.Warn("get API failed, falling back to backup", "error", err)
-					return fetchFromBackup(backupFile, itemID, depth)
-				}
-				return nil, fmt.Errorf("cannot get item: %w", err)
+				return nil, meta, fmt.Errorf("cannot fetch children: %w", err)
 			}
+			item.Children = childrenResp.Items
+		}
+		return item, meta, nil
+	}
+	return nil, meta, fmt.Errorf("unknown access method: %s", source)
+}
 
-			if depth > 0 {
-				childrenResp, err := client.ListChildrenRecursiveWithDepth(apiCtx, itemID, depth)
-				if err != nil {
-					if method == "" {
-						slog.Warn("get API failed fetching children, falling back to backup", "error", err)
-						return fetchFromBackup(backupFile, itemID, depth)
-					}
-					return nil, fmt.Errorf("cannot fetch children: %w", err)
-				}
-				item.Children = childrenResp.Items
-			}
-			result = item
+// fetchFromBackup reads itemID down to depth from the backup, dated by its
+// most recent modification.
+func fetchFromBackup(backupFile string, itemID string, depth int) (interface{}, workflowy.SnapshotMeta, error) {
+	items, err := loadFromBackupProvider(backupFile, workflowy.DefaultBackupProvider)
+	if err != nil {
+		return nil, workflowy.SnapshotMeta{}, err
+	}
+	meta := workflowy.NewBackupSnapshot(items).Meta()
+
+	if itemID != "None" {
+		found := workflowy.FindItemInTree(items, itemID, depth)
+		if found == nil {
+			return nil, meta, workflowy.Errorf(workflowy.ErrNotFound, "item %s not found in backup", itemID)
 		}
+		return found, meta, nil
+	}
 
-	default:
-		return nil, fmt.Errorf("unknown access method: %s", useMethod)
+	if depth >= 0 {
+		workflowy.LimitItemsDepth(items, depth)
 	}
+	return &workflowy.ListChildrenResponse{Items: items}, meta, nil
+}
 
-	return result, nil
+// fetchOrResolveMirrors fetches like fetchItems, or from the backup with
+// mirrors inlined when --resolve-mirrors is set.
+func fetchOrResolveMirrors(cmd *cli.Command, apiCtx context.Context, client workflowy.Client, itemID string, depth int) (interface{}, workflowy.SnapshotMeta, error) {
+	if cmd.Bool("resolve-mirrors") {
+		return fetchWithMirrors(cmd, itemID, depth)
+	}
+	return fetchItemsWithSource(cmd, apiCtx, client, itemID, depth)
 }
 
-func fetchFromBackup(backupFile string, itemID string, depth int) (interface{}, error) {
-	items, err := loadFromBackupProvider(backupFile, workflowy.DefaultBackupProvider)
+// fetchWithMirrors reads itemID from the backup with mirror copies replaced by
+// their originals' content (--resolve-mirrors). Mirror data is only available
+// in backup files.
+func fetchWithMirrors(cmd *cli.Command, itemID string, depth int) (interface{}, workflowy.SnapshotMeta, error) {
+	if method := cmd.String("method"); method != "" && method != "backup" {
+		return nil, workflowy.SnapshotMeta{}, fmt.Errorf("--resolve-mirrors requires --method=backup (mirror data is only available in backup files)")
+	}
+
+	items, err := loadFromBackupProvider(cmd.String("backup-file"), workflowy.DefaultBackupProvider)
 	if err != nil {
-		return nil, err
+		return nil, workflowy.SnapshotMeta{}, err
 	}
+	meta := workflowy.NewBackupSnapshot(items).Meta()
 
 	if itemID != "None" {
-		found := workflowy.FindItemInTree(items, itemID, depth)
+		found := workflowy.FindItemByID(items, itemID)
 		if found == nil {
-			return nil, fmt.Errorf("item %s not found in backup", itemID)
+			return nil, meta, workflowy.Errorf(workflowy.ErrNotFound, "item %s not found in backup", itemID)
+		}
+		count := mirror.InlineMirrors([]*workflowy.Item{found}, items)
+		slog.Debug("inlined mirrors", "count", count)
+		if depth >= 0 {
+			workflowy.LimitItemDepth(found, depth)
 		}
-		return found, nil
+		return found, meta, nil
 	}
 
+	count := mirror.InlineMirrors(items, items)
+	slog.Debug("inlined mirrors", "count", count)
 	if depth >= 0 {
 		workflowy.LimitItemsDepth(items, depth)
 	}
-	return &workflowy.ListChildrenResponse{Items: items}, nil
+	return &workflowy.ListChildrenResponse{Items: items}, meta, nil
 }
 
 func flattenTree(data interface{}) *workflowy.ListChildrenResponse {
@@ -2,8 +2,13 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/mholzen/workflowy/pkg/workflowy"
 	"github.com/stretchr/testify/assert"
 	"github.com/urfave/cli/v3"
 )
@@ -63,3 +68,98 @@ func TestFetchItems_NilClient_AutoMethod_AttemptsBackup(t *testing.T) {
 	err := cmd.Run(context.Background(), []string{"test", "--backup-file=/nonexistent/backup.json"})
 	assert.NoError(t, err)
 }
+
+func TestFetchItemsWithSource_FallsBackToBackup(t *testing.T) {
+	t.Setenv("WORKFLOWY_STORAGE", t.TempDir())
+	backup := filepath.Join(t.TempDir(), "test.workflowy.backup")
+	err := os.WriteFile(backup, []byte(`[{"id": "inbox", "nm": "Inbox", "lm": 1700000000}]`), 0644)
+	assert.NoError(t, err)
+
+	run := func(args ...string) (interface{}, workflowy.SnapshotMeta, error) {
+		var result interface{}
+		var meta workflowy.SnapshotMeta
+		var fetchErr error
+		cmd := &cli.Command{
+			Flags: getMethodFlags(),
+			Action: func(ctx context.Context, c *cli.Command) error {
+				result, meta, fetchErr = fetchItemsWithSource(c, ctx, nil, "None", 2)
+				return nil
+			},
+		}
+		assert.NoError(t, cmd.Run(context.Background(), append([]string{"test", "--method=cache", "--backup-file=" + backup}, args...)))
+		return result, meta, fetchErr
+	}
+
+	_, _, err = run()
+	assert.Error(t, err, "an explicit method does not fall back by default")
+
+	result, meta, err := run("--fallback=export,backup")
+	assert.NoError(t, err)
+	assert.Equal(t, "backup", meta.Source)
+	assert.Equal(t, int64(1700000000), meta.TakenAt.Unix())
+	assert.Len(t, result.(*workflowy.ListChildrenResponse).Items, 1)
+
+	_, _, err = run("--fallback=tape")
+	assert.ErrorContains(t, err, "invalid --fallback")
+}
+
+func TestWithDataSource(t *testing.T) {
+	source := workflowy.SnapshotMeta{Source: "export", TakenAt: time.Now().Add(-90 * time.Second)}
+	data, err := json.Marshal(withDataSource(&workflowy.ListChildrenResponse{Items: []*workflowy.Item{}}, source))
+	assert.NoError(t, err)
+	assert.Regexp(t, `^\{"nodes":\[\],"data_source":"export","data_age":9[01]\}$`, string(data))
+
+	data, err = json.Marshal(withDataSource(map[string]any{}, source))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"data_source":"export"`)
+
+	assert.Equal(t, "x", withDataSource("x", workflowy.SnapshotMeta{}))
+}
+
+func TestFetchOrResolveMirrors_RequiresBackupMethod(t *testing.T) {
+	cmd := &cli.Command{
+		Flags: getFetchFlags(),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			_, _, err := fetchOrResolveMirrors(c, ctx, nil, "None", 2)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "--resolve-mirrors requires --method=backup")
+			return nil
+		},
+	}
+	err := cmd.Run(context.Background(), []string{"test", "--resolve-mirrors", "--method=export"})
+	assert.NoError(t, err)
+}
+
+func TestFetchOrResolveMirrors_InlinesOriginal(t *testing.T) {
+	backup := filepath.Join(t.TempDir(), "test.workflowy.backup")
+	err := os.WriteFile(backup, []byte(`[
+		{"id": "projects", "nm": "Projects", "ch": [
+			{"id": "original", "nm": "Template", "ch": [{"id": "step", "nm": "Step 1"}],
+			 "metadata": {"mirror": {"mirrorRootIds": {"copy": true}}}}
+		]},
+		{"id": "today", "nm": "Today", "ch": [
+			{"id": "copy", "nm": "", "metadata": {"mirror": {"originalId": "original", "isMirrorRoot": true}}}
+		]}
+	]`), 0644)
+	assert.NoError(t, err)
+
+	cmd := &cli.Command{
+		Flags: getFetchFlags(),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			result, _, err := fetchOrResolveMirrors(c, ctx, nil, "today", 2)
+			assert.NoError(t, err)
+			today := result.(*workflowy.Item)
+			if assert.Len(t, today.Children, 1) {
+				mirrored := today.Children[0]
+				assert.Equal(t, "Template", mirrored.Name)
+				assert.Equal(t, "original", mirrored.MirrorOf)
+				if assert.Len(t, mirrored.Children, 1) {
+					assert.Equal(t, "Step 1", mirrored.Children[0].Name)
+				}
+			}
+			return nil
+		},
+	}
+	err = cmd.Run(context.Background(), []string{"test", "--resolve-mirrors", "--backup-file=" + backup})
+	assert.NoError(t, err)
+}
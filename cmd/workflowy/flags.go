@@ -4,38 +4,53 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/mholzen/workflowy/pkg/config"
+	"github.com/mholzen/workflowy/pkg/paths"
+	"github.com/mholzen/workflowy/pkg/search"
+	"github.com/mholzen/workflowy/pkg/workflowy"
 	"github.com/urfave/cli/v3"
 )
 
 type FetchParameters struct {
-	format string
-	depth  int
-	itemID string
+	format    string
+	depth     int
+	itemID    string
+	completed string
+	pruning   workflowy.Pruning
 }
 
 func getMethodFlags() []cli.Flag {
 	return []cli.Flag{
 		&cli.StringFlag{
 			Name:  "method",
-			Usage: "Access method: get, export or backup\n\tDefaults to 'get' for depth 1-3, 'export' for depth 4+, 'backup' if no api key provided",
+			Usage: "Access method: get, export, backup or cache\n\tDefaults to 'get' for depth 1-3, 'export' for depth 4+, 'backup' if no api key provided",
 		},
 		getAPIKeyFlag(),
 		&cli.StringFlag{
 			Name:  "backup-file",
-			Usage: "Path to backup file (default: latest in ~/Dropbox/Apps/Workflowy/Data)",
+			Usage: "Path to backup file (default: latest in Dropbox/Apps/Workflowy/Data)",
 		},
 		&cli.BoolFlag{
 			Name:  "force-refresh",
 			Usage: "Force refresh from API when using export (bypassing cache)",
 		},
+		getFallbackFlag(),
 	}
 }
 
 func getFetchFlags() []cli.Flag {
+	depth := 2
+	if userConfig.Depth != nil {
+		depth = *userConfig.Depth
+	}
+	depthFlag := getDepthFlag(depth, "Recursion depth for get/list operations (positive integer)")
+	depthFlag.Sources = cli.EnvVars("WORKFLOWY_DEPTH")
+
 	flags := []cli.Flag{
-		getDepthFlag(2, "Recursion depth for get/list operations (positive integer)"),
+		depthFlag,
 		&cli.BoolFlag{
 			Name:  "all",
 			Usage: "Get/list all descendants (equivalent to --depth=-1)",
@@ -45,11 +60,56 @@ func getFetchFlags() []cli.Flag {
 			Value: false,
 			Usage: "Include items with empty names",
 		},
+		&cli.BoolFlag{
+			Name:  "include-notes",
+			Usage: "Render notes: after each name in list output, as quotes or indented blocks in markdown",
+		},
+		&cli.BoolFlag{
+			Name:  "resolve-mirrors",
+			Usage: "Show the content of the original in place of each mirror copy, marked with mirror_of (reads the backup)",
+		},
+		&cli.IntFlag{
+			Name:  "summarize-over",
+			Usage: "When the result has more than this many nodes, print each top-level branch's size and first items instead (0 to disable)",
+		},
+		&cli.IntFlag{
+			Name:  "summary-items",
+			Value: 5,
+			Usage: "Items shown per branch by --summarize-over",
+		},
+		getCompletedFlag(),
+		&cli.StringSliceFlag{
+			Name:  "prune-tag",
+			Usage: "Leave out nodes tagged with this #tag or @mention, and their descendants (repeatable)",
+		},
+		&cli.BoolFlag{
+			Name:  "prune-completed",
+			Usage: "Leave out completed nodes and their descendants (same as --completed=exclude)",
+		},
+		&cli.IntFlag{
+			Name:  "max-children-per-node",
+			Usage: "Show at most this many children of each node, the first by position (0 for all)",
+		},
 	}
 	flags = append(flags, getMethodFlags()...)
 	return flags
 }
 
+func getCompletedFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:  "completed",
+		Value: workflowy.CompletedInclude,
+		Usage: "Completed nodes: include, exclude (with their descendants) or only (with the ancestors that lead to them)",
+	}
+}
+
+func getUniqueChildrenFlag(usage string) *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "unique-children",
+		Usage: usage,
+	}
+}
+
 func getWriteFlags(commandFlags ...cli.Flag) []cli.Flag {
 	flags := []cli.Flag{
 		getAPIKeyFlag(),
@@ -65,6 +125,10 @@ func getWriteFlags(commandFlags ...cli.Flag) []cli.Flag {
 			Name:  "layout-mode",
 			Usage: "Display mode: bullets, todo, h1, h2, h3",
 		},
+		&cli.BoolFlag{
+			Name:  "markdown",
+			Usage: "Convert markdown (**bold**, _italic_, ~~strike~~, `code`, [link](url)) in the name and note to Workflowy formatting",
+		},
 	}
 	flags = append(flags, commandFlags...)
 	return flags
@@ -93,6 +157,7 @@ func getReportFlags(commandFlags ...cli.Flag) []cli.Flag {
 	flags = append(flags, getMethodFlags()...)
 	flags = append(flags, commandFlags...)
 	flags = append(flags, getIdFlag("ID to start from (default: root)"))
+	flags = append(flags, getCompletedFlag())
 	flags = append(flags, getReportOutputFlags()...)
 	return flags
 }
@@ -109,16 +174,31 @@ func getRankingReportFlags() []cli.Flag {
 	return reportFlags
 }
 
+func getMirrorResolveFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "method",
+			Value: "backup",
+			Usage: "Access method: backup, or session for the private API (mirror data is not in the public API)",
+		},
+		&cli.StringFlag{
+			Name:  "backup-file",
+			Usage: "Path to backup file (default: latest in Dropbox/Apps/Workflowy/Data)",
+		},
+		getAPIKeyFlag(),
+	}
+}
+
 func getMirrorReportFlags() []cli.Flag {
 	flags := []cli.Flag{
 		&cli.StringFlag{
 			Name:  "method",
 			Value: "backup",
-			Usage: "Access method (only 'backup' is supported for mirror report)",
+			Usage: "Access method: backup, or session for the private API (mirror data is not in the public API)",
 		},
 		&cli.StringFlag{
 			Name:  "backup-file",
-			Usage: "Path to backup file (default: latest in ~/Dropbox/Apps/Workflowy/Data)",
+			Usage: "Path to backup file (default: latest in Dropbox/Apps/Workflowy/Data)",
 		},
 		getAPIKeyFlag(),
 	}
@@ -142,25 +222,37 @@ func getFetchArguments() []cli.Argument {
 	}
 }
 
-func getAndValidateFetchParams(cmd *cli.Command) (FetchParameters, error) {
-	format := cmd.String("format")
-	if err := validateFormat(format); err != nil {
-		return FetchParameters{}, err
-	}
-
+// getFetchParams reads the fetch flags of cmd, for a format already validated.
+func getFetchParams(cmd *cli.Command, format string) (FetchParameters, error) {
 	depth := cmd.Int("depth")
 	if cmd.Bool("all") {
 		depth = -1
 	}
+	completed := cmd.String("completed")
+	if err := workflowy.ValidateCompletedMode(completed); err != nil {
+		return FetchParameters{}, err
+	}
+	if cmd.Int("max-children-per-node") < 0 {
+		return FetchParameters{}, fmt.Errorf("max-children-per-node must be non-negative")
+	}
+	pruning := workflowy.Pruning{
+		Completed:   cmd.Bool("prune-completed"),
+		Tags:        cmd.StringSlice("prune-tag"),
+		MaxChildren: cmd.Int("max-children-per-node"),
+	}
 	itemID := cmd.StringArg("id")
-	return FetchParameters{format: format, depth: depth, itemID: itemID}, nil
+	return FetchParameters{format: format, depth: depth, itemID: itemID, completed: completed, pruning: pruning}, nil
 }
 
-func validateFormat(format string) error {
-	if format != "list" && format != "json" && format != "markdown" {
-		return fmt.Errorf("format must be 'list', 'json', or 'markdown'")
+// validateFormat accepts the formats of all commands, and the extra formats
+// of the calling command, such as jsonl.
+func validateFormat(format string, extra ...string) error {
+	formats := append([]string{"list", "json", "markdown"}, extra...)
+	if slices.Contains(formats, format) {
+		return nil
 	}
-	return nil
+	last := len(formats) - 1
+	return fmt.Errorf("format must be '%s', or '%s'", strings.Join(formats[:last], "', '"), formats[last])
 }
 
 func getIgnoreCaseFlag() cli.Flag {
@@ -195,7 +287,7 @@ func getIdFlag(usage string) cli.Flag {
 	}
 }
 
-func getDepthFlag(defaultValue int, usage string) cli.Flag {
+func getDepthFlag(defaultValue int, usage string) *cli.IntFlag {
 	return &cli.IntFlag{
 		Name:    "depth",
 		Aliases: []string{"d"},
@@ -208,7 +300,28 @@ func getSearchFlags() []cli.Flag {
 	return []cli.Flag{
 		getIgnoreCaseFlag(),
 		getRegexpFlag(),
-		getIdFlag("ID to search within (default: root)"),
+		&cli.StringSliceFlag{
+			Name:  "id",
+			Usage: "ID to search within, repeatable to search several nodes from one load of the tree (default: root)",
+		},
+		getBreadcrumbFlag(),
+		&cli.StringFlag{
+			Name:  "fields",
+			Value: search.FieldName,
+			Usage: "Comma-separated fields to search: name, note",
+		},
+		&cli.StringFlag{
+			Name:  "saved",
+			Usage: "Run the saved search of this name from filters.yaml in the config directory",
+		},
+		getCompletedFlag(),
+	}
+}
+
+func getBreadcrumbFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "breadcrumb",
+		Usage: "Include the names of the two top ancestors with each result",
 	}
 }
 
@@ -217,6 +330,7 @@ func getReplaceFlags() []cli.Flag {
 		getIgnoreCaseFlag(),
 		getParentIdFlag("Parent ID to limit replacement scope: UUID or target key (default: root)"),
 		getDepthFlag(-1, "Maximum depth to traverse (-1 for unlimited)"),
+		getBreadcrumbFlag(),
 		&cli.BoolFlag{
 			Name:  "interactive",
 			Usage: "Prompt for confirmation before each replacement",
@@ -225,6 +339,18 @@ func getReplaceFlags() []cli.Flag {
 			Name:  "dry-run",
 			Usage: "Show what would be replaced without making changes",
 		},
+		&cli.StringFlag{
+			Name:  "move-to",
+			Usage: "Move matched text to another field instead of replacing: note (from name) or name (from note)",
+		},
+		&cli.StringFlag{
+			Name:  "write-undo",
+			Usage: "Write a reverse patch of the applied changes to this file",
+		},
+		&cli.StringFlag{
+			Name:  "apply-patch",
+			Usage: "Apply a patch file (e.g. one written by --write-undo) instead of a pattern",
+		},
 	}
 	flags = append(flags, getMethodFlags()...)
 	return flags
@@ -232,19 +358,55 @@ func getReplaceFlags() []cli.Flag {
 
 var defaultAPIKeyFile string
 
+// userConfig is the configuration file, read at startup; configErr, if it
+// cannot be read, is reported when a command runs.
+var (
+	userConfig = &config.Config{}
+	configErr  error
+)
+
 func init() {
-	homeDir, err := os.UserHomeDir()
+	if c, err := config.LoadDefault(); err != nil {
+		configErr = err
+	} else {
+		userConfig = c
+		paths.Configure(c.Locations())
+	}
+
+	if userConfig.APIKeyFile != "" {
+		defaultAPIKeyFile = workflowy.ExpandTilde(userConfig.APIKeyFile)
+		return
+	}
+	path, err := paths.APIKeyFile()
 	if err != nil {
-		log.Fatalf("cannot get home directory: %v", err)
+		log.Fatalf("cannot locate configuration directory: %v", err)
+	}
+	defaultAPIKeyFile = path
+}
+
+// userAliases returns the aliases setting: $WORKFLOWY_ALIASES, or the
+// configuration file.
+func userAliases() string {
+	if env := os.Getenv("WORKFLOWY_ALIASES"); env != "" {
+		return env
+	}
+	return userConfig.Aliases
+}
+
+// defaultFormat is the default --format: list, unless configured.
+func defaultFormat() string {
+	if userConfig.Format != "" {
+		return userConfig.Format
 	}
-	defaultAPIKeyFile = filepath.Join(homeDir, ".workflowy", "api.key")
+	return "list"
 }
 
 func getAPIKeyFlag() *cli.StringFlag {
 	return &cli.StringFlag{
-		Name:  "api-key-file",
-		Value: defaultAPIKeyFile,
-		Usage: "Path to API key file (overrides WORKFLOWY_API_KEY env var)",
+		Name:    "api-key-file",
+		Value:   defaultAPIKeyFile,
+		Usage:   "Path to API key file, read when the " + paths.APIKeyEnv + " env var is not set",
+		Sources: cli.EnvVars("WORKFLOWY_API_KEY_FILE"),
 	}
 }
 
@@ -256,6 +418,14 @@ func getID(cmd *cli.Command) string {
 	return cmd.String("id")
 }
 
+func getTrashIDFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "trash-id",
+		Usage:   "Node that soft-deleted nodes are moved to (default: a top-level \"" + workflowy.TrashName + "\" node, created if missing)",
+		Sources: cli.EnvVars("WORKFLOWY_TRASH_ID"),
+	}
+}
+
 func getWriteRootIdFlag() cli.Flag {
 	return &cli.StringFlag{
 		Name:  "write-root-id",
@@ -58,3 +58,10 @@ func TestGetMethodFlags_IncludesAPIKeyFile(t *testing.T) {
 	}
 	assert.True(t, found, "getMethodFlags should include api-key-file flag")
 }
+
+func TestValidateFormat(t *testing.T) {
+	assert.NoError(t, validateFormat("markdown"))
+	assert.EqualError(t, validateFormat("jsonl"), "format must be 'list', 'json', or 'markdown'")
+	assert.NoError(t, validateFormat("jsonl", "jsonl"))
+	assert.EqualError(t, validateFormat("xml", "csv", "jsonl"), "format must be 'list', 'json', 'markdown', 'csv', or 'jsonl'")
+}
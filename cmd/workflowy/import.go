@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/escape"
+	"github.com/mholzen/workflowy/pkg/markdown"
+	"github.com/mholzen/workflowy/pkg/opml"
+	"github.com/mholzen/workflowy/pkg/outline"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+func getImportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "import",
+		Usage:     "Import outlines from other outliners",
+		UsageText: "workflowy import <subcommand> [options]",
+		Commands: []*cli.Command{
+			getImportOPMLCommand(),
+			getImportMarkdownCommand(),
+		},
+	}
+}
+
+func getImportOPMLCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "opml",
+		Usage:     "Create nodes from an OPML file",
+		UsageText: "workflowy import opml <file> [options]",
+		Description: `Creates the outline of an OPML file, as exported by OmniOutliner, Dynalist and
+most outliners, under --parent-id. Each outline's text becomes a node name, its
+_note attribute the note, and _complete="true" completes the node.
+
+A node that cannot be created is skipped with its children and the import
+continues; the command then exits with code 2.
+
+Examples:
+  workflowy import opml outline.opml --dry-run
+  workflowy import opml outline.opml --parent-id=inbox --markdown`,
+		Arguments: getImportArguments(),
+		Flags: getImportFlags(&cli.BoolFlag{
+			Name:  "markdown",
+			Usage: "Convert markdown (**bold**, _italic_, ~~strike~~, `code`, [link](url)) in names and notes to Workflowy formatting",
+		}),
+		Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			return importFile(ctx, cmd, client, func(f io.Reader) ([]*outline.Node, string, error) {
+				doc, err := opml.Parse(f)
+				if err != nil {
+					return nil, "", err
+				}
+				return doc.Nodes, doc.Title, nil
+			})
+		}),
+	}
+}
+
+func getImportMarkdownCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "markdown",
+		Usage:     "Create nodes from a markdown document",
+		UsageText: "workflowy import markdown <file> [options]",
+		Description: `Creates the outline of a markdown document under --parent-id, the inverse of
+get --format=markdown:
+
+  # Header, ## and ###   h1, h2 and h3 nodes holding the content that follows
+  paragraphs            "p" nodes
+  - and 1. list items   nodes nested by indentation, under the paragraph they
+                        directly follow; "- [x]" items are completed
+  > quotes, code blocks "quote" and "code" nodes
+  ---                   divider nodes
+
+Markdown formatting (**bold**, _italic_, ~~strike~~, ` + "`code`" + `, [link](url)) is
+converted to Workflowy formatting.
+
+A node that cannot be created is skipped with its children and the import
+continues; the command then exits with code 2.
+
+Examples:
+  workflowy import markdown notes.md --dry-run
+  workflowy import markdown notes.md --parent-id=inbox`,
+		Arguments: getImportArguments(),
+		Flags:     getImportFlags(),
+		Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			return importFile(ctx, cmd, client, func(f io.Reader) ([]*outline.Node, string, error) {
+				nodes, err := markdown.Parse(f)
+				return nodes, "", err
+			})
+		}),
+	}
+}
+
+func getImportArguments() []cli.Argument {
+	return []cli.Argument{
+		&cli.StringArg{
+			Name:      "file",
+			UsageText: "<file>",
+		},
+	}
+}
+
+func getImportFlags(commandFlags ...cli.Flag) []cli.Flag {
+	flags := []cli.Flag{
+		getAPIKeyFlag(),
+		&cli.StringFlag{
+			Name:  "parent-id",
+			Value: "None",
+			Usage: "Parent ID: UUID or target key (default: root)",
+		},
+		&cli.StringFlag{
+			Name:  "position",
+			Usage: "Position of the top-level nodes: \"top\" or \"bottom\" (omit for API default)",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Print the outline without creating nodes",
+		},
+		&cli.StringFlag{
+			Name:  "on-collision",
+			Value: workflowy.CollisionAllow,
+			Usage: "For nodes named like an existing sibling: allow (create anyway), skip, rename (add a \" (2)\" suffix) or merge (import their children into the existing node)",
+		},
+		getUniqueChildrenFlag("Never create a node named like a sibling: merge into it, as with --on-collision=merge, so that importing again only adds what is new"),
+	}
+	return append(flags, commandFlags...)
+}
+
+// importFile parses the file argument with parse and creates its outline
+// under --parent-id, or prints it with --dry-run.
+func importFile(ctx context.Context, cmd *cli.Command, client workflowy.Client, parse func(io.Reader) ([]*outline.Node, string, error)) error {
+	format := cmd.String("format")
+	if err := validateFormat(format); err != nil {
+		return err
+	}
+
+	file := cmd.StringArg("file")
+	if file == "" {
+		return fmt.Errorf("file is required")
+	}
+	position := cmd.String("position")
+	if position != "" && position != "top" && position != "bottom" {
+		return fmt.Errorf("position must be \"top\" or \"bottom\"")
+	}
+	collision := cmd.String("on-collision")
+	if err := workflowy.ValidateCollisionPolicy(collision); err != nil {
+		return err
+	}
+	if cmd.Bool("unique-children") {
+		var err error
+		if collision, err = uniqueCollisionPolicy(collision, cmd.IsSet("on-collision")); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("cannot open file: %w", err)
+	}
+	defer f.Close()
+	nodes, title, err := parse(f)
+	if err != nil {
+		return err
+	}
+	total := outline.Count(nodes)
+
+	if cmd.Bool("dry-run") {
+		if format == "json" {
+			output := map[string]any{"nodes": nodes, "count": total}
+			if title != "" {
+				output["title"] = title
+			}
+			printJSON(output)
+			return nil
+		}
+		printOutlineNodes(nodes, 0)
+		printInfo("\nDry run: %d node(s) would be imported\n", total)
+		return nil
+	}
+
+	guard, err := NewWriteGuard(ctx, client, getWriteRootID(cmd))
+	if err != nil {
+		return err
+	}
+	parentID, err := workflowy.ResolveNodeID(ctx, client, guard.DefaultParent(getParentID(cmd)))
+	if err != nil {
+		return fmt.Errorf("cannot resolve parent ID: %w", err)
+	}
+	if err := guard.ValidateParent(parentID, "import"); err != nil {
+		return err
+	}
+
+	opts := outline.Options{ParentID: parentID, Position: position, Collision: collision}
+	if cmd.Bool("markdown") {
+		opts.Convert = escape.FromMarkdown
+	}
+	if collision != workflowy.CollisionAllow {
+		opts.Children = func(ctx context.Context, parentID string) ([]*workflowy.Item, error) {
+			return existingChildren(ctx, client, parentID)
+		}
+	}
+	result := outline.Import(ctx, client, nodes, opts)
+
+	var failure error
+	if result.Failed > 0 {
+		failure = partialFailure(result.Failed, total, "nodes")
+	}
+	if format == "json" {
+		printJSON(result)
+		return failure
+	}
+	for _, msg := range result.Errors {
+		printInfo("%s\n", msg)
+	}
+	printInfo("imported %d of %d node(s)", result.Created, total)
+	if result.Skipped > 0 {
+		printInfo(", skipped %d", result.Skipped)
+	}
+	if result.Merged > 0 {
+		printInfo(", merged %d into existing nodes", result.Merged)
+	}
+	if result.Renamed > 0 {
+		printInfo(", renamed %d", result.Renamed)
+	}
+	printInfo("\n")
+	return failure
+}
+
+// uniqueCollisionPolicy returns the collision policy for --unique-children:
+// merge, unless skip was set, as both leave the existing node alone.
+func uniqueCollisionPolicy(collision string, set bool) (string, error) {
+	if !set {
+		return workflowy.CollisionMerge, nil
+	}
+	if collision != workflowy.CollisionSkip && collision != workflowy.CollisionMerge {
+		return "", fmt.Errorf("cannot use --unique-children with --on-collision=%s", collision)
+	}
+	return collision, nil
+}
+
+// existingChildren returns the children of parentID from the API, not a
+// cached export that may miss the nodes created since, to check the names of
+// imported nodes against.
+func existingChildren(ctx context.Context, client workflowy.Client, parentID string) ([]*workflowy.Item, error) {
+	resp, err := client.ListChildren(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// printOutlineNodes prints an outline as an indented list.
+func printOutlineNodes(nodes []*outline.Node, depth int) {
+	for _, node := range nodes {
+		prefix := ""
+		if node.Completed {
+			prefix = "[x] "
+		}
+		if node.LayoutMode != "" {
+			prefix += "(" + node.LayoutMode + ") "
+		}
+		fmt.Printf("%s- %s%s\n", strings.Repeat("  ", depth), prefix, node.Name)
+		printOutlineNodes(node.Children, depth+1)
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniqueCollisionPolicy(t *testing.T) {
+	policy, err := uniqueCollisionPolicy(workflowy.CollisionAllow, false)
+	assert.NoError(t, err)
+	assert.Equal(t, workflowy.CollisionMerge, policy, "the default policy becomes merge")
+
+	policy, err = uniqueCollisionPolicy(workflowy.CollisionSkip, true)
+	assert.NoError(t, err)
+	assert.Equal(t, workflowy.CollisionSkip, policy)
+
+	_, err = uniqueCollisionPolicy(workflowy.CollisionRename, true)
+	assert.Error(t, err)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/mirror"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+// nodeInfo is the metadata of a single node printed by info.
+type nodeInfo struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	NoteLength  int                    `json:"note_length"`
+	LayoutMode  string                 `json:"layout_mode,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	ModifiedAt  time.Time              `json:"modified_at"`
+	Completed   bool                   `json:"completed"`
+	CompletedAt *time.Time             `json:"completed_at,omitempty"` // unknown in exports, which only flag completion
+	Children    int                    `json:"children"`
+	Descendants int                    `json:"descendants"`
+	Depth       int                    `json:"depth"` // 1 for top-level nodes
+	Path        []string               `json:"path"`  // names of the ancestors, outermost first
+	Tags        []string               `json:"tags"`
+	MirrorOf    string                 `json:"mirror_of,omitempty"`
+	Mirrors     []string               `json:"mirrors,omitempty"` // IDs of the mirror copies of the node or its original
+	URL         string                 `json:"url"`
+	Snapshot    workflowy.SnapshotMeta `json:"snapshot"`
+}
+
+func getInfoCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "info",
+		Usage:     "Show the metadata of a node",
+		UsageText: "workflowy info <id> [options]",
+		Description: `Prints a node's name, note length, layout, timestamps, child and descendant
+counts, depth and path from the root, tags, mirrors and web URL.
+
+The whole tree is loaded with the export API (or --method=backup) to count
+descendants and find the path. Mirror data is only available in backups.
+
+Examples:
+  workflowy info 3495d784
+  workflowy info inbox --format=json`,
+		Arguments: []cli.Argument{
+			&cli.StringArg{
+				Name:      "id",
+				UsageText: "<id>",
+			},
+		},
+		Flags: getMethodFlags(),
+		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+			rawID := cmd.StringArg("id")
+			if rawID == "" {
+				return fmt.Errorf("id is required")
+			}
+
+			readGuard, err := NewReadGuard(ctx, client, getReadRootID(cmd))
+			if err != nil {
+				return err
+			}
+			itemID, err := workflowy.ResolveNodeIDToUUID(ctx, client, rawID)
+			if err != nil {
+				return fmt.Errorf("cannot resolve ID: %w", err)
+			}
+			if err := readGuard.ValidateTarget(itemID, "info"); err != nil {
+				return err
+			}
+
+			snapshot, err := loadSnapshot(ctx, cmd, client, workflowy.DefaultBackupProvider)
+			if err != nil {
+				return err
+			}
+			info, err := buildNodeInfo(snapshot, itemID)
+			if err != nil {
+				return err
+			}
+
+			if format == "json" {
+				printJSON(info)
+				return nil
+			}
+			printNodeInfo(info)
+			return nil
+		}),
+	}
+}
+
+// buildNodeInfo gathers the metadata of the node id from the snapshot.
+func buildNodeInfo(snapshot *workflowy.Snapshot, id string) (*nodeInfo, error) {
+	path := workflowy.FindPath(snapshot.Items(), id)
+	if path == nil {
+		return nil, fmt.Errorf("item with ID %s not found", id)
+	}
+	item := path[len(path)-1]
+
+	note := ""
+	if item.Note != nil {
+		note = *item.Note
+	}
+	info := &nodeInfo{
+		ID:          item.ID,
+		Name:        item.Name,
+		NoteLength:  len([]rune(note)),
+		CreatedAt:   time.Unix(item.CreatedAt, 0),
+		ModifiedAt:  time.Unix(item.ModifiedAt, 0),
+		Children:    len(item.Children),
+		Descendants: workflowy.CountItems(item.Children),
+		Depth:       len(path),
+		Path:        []string{},
+		Tags:        workflowy.Tags(item.Name, note),
+		MirrorOf:    mirror.OriginalID(item),
+		URL:         nodeURL(item.ID, true),
+		Snapshot:    snapshot.Meta(),
+	}
+	if info.Tags == nil {
+		info.Tags = []string{}
+	}
+	if mode, ok := item.Data["layoutMode"].(string); ok {
+		info.LayoutMode = mode
+	}
+	info.Completed = item.IsCompleted()
+	if item.CompletedAt != nil {
+		completedAt := time.Unix(*item.CompletedAt, 0)
+		info.CompletedAt = &completedAt
+	}
+	for _, ancestor := range path[:len(path)-1] {
+		info.Path = append(info.Path, ancestor.Name)
+	}
+
+	resolution, err := mirror.Resolve(snapshot.Items(), item.ID)
+	if err != nil {
+		slog.Debug("cannot resolve mirrors", "id", item.ID, "error", err)
+	} else {
+		info.Mirrors = resolution.MirrorIDs
+	}
+	return info, nil
+}
+
+func printNodeInfo(info *nodeInfo) {
+	const timeFormat = "2006-01-02 15:04:05"
+	fmt.Printf("name:        %s\n", info.Name)
+	fmt.Printf("id:          %s\n", info.ID)
+	fmt.Printf("url:         %s\n", info.URL)
+	fmt.Printf("path:        %s\n", strings.Join(info.Path, workflowy.BreadcrumbSeparator))
+	fmt.Printf("depth:       %d\n", info.Depth)
+	if info.LayoutMode != "" {
+		fmt.Printf("layout:      %s\n", info.LayoutMode)
+	}
+	fmt.Printf("note:        %d characters\n", info.NoteLength)
+	fmt.Printf("children:    %d\n", info.Children)
+	fmt.Printf("descendants: %d\n", info.Descendants)
+	fmt.Printf("created:     %s\n", info.CreatedAt.Format(timeFormat))
+	fmt.Printf("modified:    %s\n", info.ModifiedAt.Format(timeFormat))
+	if info.CompletedAt != nil {
+		fmt.Printf("completed:   %s\n", info.CompletedAt.Format(timeFormat))
+	} else if info.Completed {
+		fmt.Printf("completed:   yes\n")
+	}
+	if len(info.Tags) > 0 {
+		fmt.Printf("tags:        %s\n", strings.Join(info.Tags, " "))
+	}
+	if info.MirrorOf != "" {
+		fmt.Printf("mirror of:   %s\n", info.MirrorOf)
+	}
+	if len(info.Mirrors) > 0 {
+		fmt.Printf("mirrors:     %s\n", strings.Join(info.Mirrors, ", "))
+	}
+	printInfo("snapshot: %s\n", info.Snapshot)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildNodeInfo(t *testing.T) {
+	note := "call @alice"
+	completedAt := int64(1700000300)
+	task := &workflowy.Item{
+		ID:          "task",
+		Name:        "Ship #urgent",
+		Note:        &note,
+		Data:        map[string]interface{}{"layoutMode": "todo"},
+		CreatedAt:   1700000000,
+		ModifiedAt:  1700000200,
+		CompletedAt: &completedAt,
+		Children:    []*workflowy.Item{{ID: "a", Children: []*workflowy.Item{{ID: "b"}}}},
+	}
+	items := []*workflowy.Item{{ID: "projects", Name: "Projects", Children: []*workflowy.Item{
+		{ID: "alpha", Name: "Alpha", Children: []*workflowy.Item{task}},
+	}}}
+	snapshot := workflowy.NewSnapshot(items, "backup", time.Unix(1700000400, 0))
+
+	info, err := buildNodeInfo(snapshot, "task")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Ship #urgent", info.Name)
+	assert.Equal(t, 11, info.NoteLength)
+	assert.Equal(t, "todo", info.LayoutMode)
+	assert.Equal(t, time.Unix(1700000000, 0), info.CreatedAt)
+	assert.True(t, info.Completed)
+	assert.Equal(t, time.Unix(1700000300, 0), *info.CompletedAt)
+	assert.Equal(t, 1, info.Children)
+	assert.Equal(t, 2, info.Descendants)
+	assert.Equal(t, 3, info.Depth)
+	assert.Equal(t, []string{"Projects", "Alpha"}, info.Path)
+	assert.Equal(t, []string{"#urgent", "@alice"}, info.Tags)
+	assert.Equal(t, "https://workflowy.com/#/task", info.URL)
+	assert.Equal(t, "backup", info.Snapshot.Source)
+
+	// exports flag completion without its time
+	task.CompletedAt = nil
+	task.Completed = true
+	info, err = buildNodeInfo(snapshot, "task")
+	require.NoError(t, err)
+	assert.True(t, info.Completed)
+	assert.Nil(t, info.CompletedAt)
+
+	_, err = buildNodeInfo(snapshot, "missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/mholzen/workflowy/pkg/formatter"
+	"github.com/mholzen/workflowy/pkg/mirror"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+// listGroup is the flattened list of one of the roots given to list.
+type listGroup struct {
+	ID    string            `json:"id"`
+	Name  string            `json:"name"`
+	Items []*workflowy.Item `json:"nodes"`
+}
+
+// listRoots lists several roots from a single snapshot of the tree, so each
+// additional root costs no further load, and prints one group per root.
+func listRoots(ctx context.Context, cmd *cli.Command, client workflowy.Client, readGuard *ReadGuard, rawIDs []string, params FetchParameters) error {
+	if cmd.Int("offset") != 0 || cmd.Int("limit") != 0 {
+		return fmt.Errorf("--offset and --limit apply to a single id")
+	}
+	if cmd.String("method") == "get" {
+		return fmt.Errorf("listing several ids requires --method=export or --method=backup")
+	}
+	if isRowFormat(params.format) || params.format == "jsonl" {
+		return fmt.Errorf("--format=%s applies to a single id", params.format)
+	}
+
+	ids := make([]string, 0, len(rawIDs))
+	for _, rawID := range rawIDs {
+		id, err := workflowy.ResolveNodeID(ctx, client, readGuard.DefaultID(rawID))
+		if err != nil {
+			return fmt.Errorf("cannot resolve ID %s: %w", rawID, err)
+		}
+		if err := readGuard.ValidateTarget(id, "list"); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	snapshot, err := loadListSnapshot(ctx, cmd, client)
+	if err != nil {
+		return err
+	}
+	groups, err := groupListRoots(snapshot, ids, params.depth, params.completed, params.pruning)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Bool("breadcrumb") {
+		var listed []*workflowy.Item
+		for _, group := range groups {
+			listed = append(listed, group.Items...)
+		}
+		workflowy.AddItemBreadcrumbs(listed, snapshot.Items())
+	}
+
+	showEmptyNames := cmd.Bool("include-empty-names")
+	if params.format == "json" {
+		for _, group := range groups {
+			if !showEmptyNames {
+				group.Items = filterEmptyNames(group.Items)
+			}
+			sortItemsByPriority(group.Items)
+		}
+		printJSON(withDataSource(map[string]any{"roots": groups, "snapshot": snapshot.Meta()}, snapshot.Meta()))
+		return nil
+	}
+	for i, group := range groups {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("## %s (%s)\n\n", group.Name, group.ID)
+		printOutputWithOptions(&workflowy.ListChildrenResponse{Items: group.Items}, params.format, outputOptions{showEmptyNames: showEmptyNames, notes: cmd.Bool("include-notes")})
+	}
+	return nil
+}
+
+// loadListSnapshot loads the tree once for listRoots, with mirrors inlined
+// when --resolve-mirrors is set.
+func loadListSnapshot(ctx context.Context, cmd *cli.Command, client workflowy.Client) (*workflowy.Snapshot, error) {
+	if !cmd.Bool("resolve-mirrors") {
+		return loadSnapshot(ctx, cmd, client, workflowy.DefaultBackupProvider)
+	}
+	if method := cmd.String("method"); method != "" && method != "backup" {
+		return nil, fmt.Errorf("--resolve-mirrors requires --method=backup (mirror data is only available in backup files)")
+	}
+	items, err := loadFromBackupProvider(cmd.String("backup-file"), workflowy.DefaultBackupProvider)
+	if err != nil {
+		return nil, err
+	}
+	count := mirror.InlineMirrors(items, items)
+	slog.Debug("inlined mirrors", "count", count)
+	return workflowy.NewBackupSnapshot(items), nil
+}
+
+// groupListRoots flattens each root of the snapshot down to depth, keeping
+// completed nodes as the completed mode says and dropping those pruning drops.
+// The root ("None") is listed from its top-level items, as list does for a
+// single root.
+func groupListRoots(snapshot *workflowy.Snapshot, ids []string, depth int, completed string, pruning workflowy.Pruning) ([]*listGroup, error) {
+	groups := make([]*listGroup, 0, len(ids))
+	for _, id := range ids {
+		root, err := snapshot.Root(id)
+		if err != nil {
+			return nil, err
+		}
+		root = workflowy.FilterCompletedItem(root, completed)
+		root = pruning.ApplyTree(root).(*workflowy.Item)
+		group := &listGroup{ID: root.ID, Name: root.Name}
+		if id == "None" {
+			childDepth := -1
+			if depth >= 0 {
+				childDepth = max(depth-1, 0)
+			}
+			for _, item := range root.Children {
+				group.Items = append(group.Items, workflowy.FlattenCopy(item, childDepth)...)
+			}
+		} else {
+			group.Items = workflowy.FlattenCopy(root, depth)
+		}
+		if completed == workflowy.CompletedOnly {
+			group.Items = workflowy.CompletedItems(group.Items)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// isTableFormat reports whether format is one of the tabular formats of list.
+func isTableFormat(format string) bool {
+	return format == "csv" || format == "tsv"
+}
+
+// isRowFormat reports whether list prints format from the rows of the tree,
+// which know the parent and depth of each node.
+func isRowFormat(format string) bool {
+	return isTableFormat(format) || format == "template"
+}
+
+// getAndValidateListParams reads the fetch flags of list, which also accepts
+// the csv, tsv, jsonl and template formats.
+func getAndValidateListParams(cmd *cli.Command) (FetchParameters, error) {
+	format := cmd.String("format")
+	if err := validateFormat(format, "csv", "tsv", "jsonl", "template"); err != nil {
+		return FetchParameters{}, err
+	}
+	if isTableFormat(format) {
+		if _, err := formatter.ParseColumns(cmd.String("columns")); err != nil {
+			return FetchParameters{}, err
+		}
+	} else if cmd.IsSet("columns") {
+		return FetchParameters{}, fmt.Errorf("--columns applies to --format=csv or --format=tsv")
+	}
+	if err := validateTemplateFile(cmd, format); err != nil {
+		return FetchParameters{}, err
+	}
+	return getFetchParams(cmd, format)
+}
+
+// rowWriter returns the function writing rows in format: csv, tsv or template.
+func rowWriter(cmd *cli.Command, format string) (func(w io.Writer, rows []formatter.Row) error, error) {
+	if format == "template" {
+		tmpl, err := loadOutputTemplate(cmd)
+		if err != nil {
+			return nil, err
+		}
+		return func(w io.Writer, rows []formatter.Row) error {
+			return formatter.WriteTemplate(w, tmpl, rows)
+		}, nil
+	}
+	columns, err := formatter.ParseColumns(cmd.String("columns"))
+	if err != nil {
+		return nil, err
+	}
+	separator, err := formatter.TableSeparator(format)
+	if err != nil {
+		return nil, err
+	}
+	return func(w io.Writer, rows []formatter.Row) error {
+		return formatter.WriteTable(w, rows, columns, separator)
+	}, nil
+}
+
+// printListRows prints the nodes of tree as csv or tsv rows, or through a
+// template, in outline order and paginated by offset and limit like the flat
+// list.
+func printListRows(cmd *cli.Command, tree interface{}, params FetchParameters, offset, limit int) error {
+	writeRows, err := rowWriter(cmd, params.format)
+	if err != nil {
+		return err
+	}
+
+	var items []*workflowy.Item
+	switch v := tree.(type) {
+	case *workflowy.Item:
+		items = []*workflowy.Item{v}
+	case *workflowy.ListChildrenResponse:
+		items = v.Items
+	}
+	if !cmd.Bool("include-empty-names") {
+		items = filterEmptyNames(items)
+	}
+	sortItemsByPriority(items)
+	rows := formatter.Rows(items)
+	if params.completed == workflowy.CompletedOnly {
+		completed := rows[:0]
+		for _, row := range rows {
+			if row.Item.IsCompleted() {
+				completed = append(completed, row)
+			}
+		}
+		rows = completed
+	}
+
+	if offset == 0 && limit == 0 {
+		return writeRows(os.Stdout, rows)
+	}
+	list := &workflowy.ListChildrenResponse{Items: make([]*workflowy.Item, len(rows))}
+	for i, row := range rows {
+		list.Items[i] = row.Item
+	}
+	page := workflowy.Paginate(list, offset, limit)
+	if err := writeRows(os.Stdout, rows[page.Offset:page.Offset+len(page.Items)]); err != nil {
+		return err
+	}
+	printPageInfo(page)
+	return nil
+}
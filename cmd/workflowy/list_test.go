@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/filters"
+	"github.com/mholzen/workflowy/pkg/search"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupListRoots(t *testing.T) {
+	alpha := &workflowy.Item{ID: "alpha", Name: "Alpha", Children: []*workflowy.Item{{ID: "task", Name: "Task"}}}
+	projects := &workflowy.Item{ID: "projects", Name: "Projects", Children: []*workflowy.Item{alpha}}
+	today := &workflowy.Item{ID: "today", Name: "Today", Children: []*workflowy.Item{{ID: "call", Name: "Call"}}}
+	snapshot := workflowy.NewSnapshot([]*workflowy.Item{projects, today}, "backup", time.Now())
+
+	ids := func(items []*workflowy.Item) []string {
+		var ids []string
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+
+	groups, err := groupListRoots(snapshot, []string{"today", "projects"}, -1, workflowy.CompletedInclude, workflowy.Pruning{})
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "Today", groups[0].Name)
+	assert.Equal(t, []string{"today", "call"}, ids(groups[0].Items))
+	assert.Equal(t, []string{"projects", "alpha", "task"}, ids(groups[1].Items))
+
+	groups, err = groupListRoots(snapshot, []string{"projects", "None"}, 1, "", workflowy.Pruning{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"projects", "alpha"}, ids(groups[0].Items))
+	assert.Equal(t, "root", groups[1].ID)
+	assert.Equal(t, []string{"projects", "today"}, ids(groups[1].Items))
+	assert.Len(t, alpha.Children, 1, "the snapshot is unchanged")
+
+	_, err = groupListRoots(snapshot, []string{"projects", "missing"}, -1, "", workflowy.Pruning{})
+	assert.Error(t, err)
+}
+
+func TestGroupListRootsCompleted(t *testing.T) {
+	done := int64(1700000000)
+	alpha := &workflowy.Item{ID: "alpha", Name: "Alpha", Children: []*workflowy.Item{
+		{ID: "done", Name: "Done", CompletedAt: &done, Children: []*workflowy.Item{{ID: "sub", Name: "Sub"}}},
+		{ID: "open", Name: "Open"},
+	}}
+	snapshot := workflowy.NewSnapshot([]*workflowy.Item{alpha}, "backup", time.Now())
+
+	groups, err := groupListRoots(snapshot, []string{"alpha"}, -1, workflowy.CompletedExclude, workflowy.Pruning{})
+	require.NoError(t, err)
+	require.Len(t, groups[0].Items, 2)
+	assert.Equal(t, "open", groups[0].Items[1].ID)
+
+	groups, err = groupListRoots(snapshot, []string{"alpha"}, -1, workflowy.CompletedOnly, workflowy.Pruning{})
+	require.NoError(t, err)
+	require.Len(t, groups[0].Items, 1)
+	assert.Equal(t, "done", groups[0].Items[0].ID)
+	assert.Len(t, alpha.Children, 2, "the snapshot is unchanged")
+}
+
+func TestPrintJSONLines(t *testing.T) {
+	var sb strings.Builder
+	printJSONLinesToWriter(&sb, &workflowy.ListChildrenResponse{Items: []*workflowy.Item{
+		{ID: "a", Name: "first\nline"},
+		{ID: "b", Name: "second"},
+	}})
+
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	var item workflowy.Item
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &item))
+	assert.Equal(t, "first\nline", item.Name)
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &item))
+	assert.Equal(t, "b", item.ID)
+
+	sb.Reset()
+	printJSONLinesToWriter(&sb, []SearchResult{{ID: "a", Name: "match"}})
+	assert.Equal(t, 1, strings.Count(sb.String(), "\n"))
+	assert.Contains(t, sb.String(), `"id":"a"`)
+}
+
+func TestItemToMarkdownListNotes(t *testing.T) {
+	note := "line one\nline two"
+	item := &workflowy.Item{Name: "Project", Note: &note, Children: []*workflowy.Item{{Name: "Task"}}}
+	assert.Equal(t, "- Project\n  - Task\n", itemToMarkdownList(item, 0, false))
+	assert.Equal(t, "- Project\tline one\\nline two\n  - Task\n", itemToMarkdownList(item, 0, true))
+}
+
+func TestItemToMarkdownListBreadcrumb(t *testing.T) {
+	item := &workflowy.Item{Name: "Notes", Breadcrumb: "Work > Projects"}
+	assert.Equal(t, "- Notes (in Work > Projects)\n", itemToMarkdownList(item, 0, false))
+}
+
+func TestSearchWithin_SearchesEachRoot(t *testing.T) {
+	items := []*workflowy.Item{
+		{ID: "aaaa", Name: "Project A", Children: []*workflowy.Item{{ID: "a1", Name: "Notes"}}},
+		{ID: "bbbb", Name: "Project B", Children: []*workflowy.Item{{ID: "b1", Name: "Notes"}, {ID: "b2", Name: "Tasks"}}},
+	}
+	guard, err := NewReadGuard(context.Background(), nil, "")
+	require.NoError(t, err)
+	filter := filters.Filter{Pattern: "Notes", Fields: []string{search.FieldName}, Completed: workflowy.CompletedInclude}
+
+	group, err := searchWithin(context.Background(), nil, guard, items, filter, "bbbb")
+	require.NoError(t, err)
+	assert.Equal(t, "Project B", group.Name)
+	require.Len(t, group.Results, 1)
+	assert.Equal(t, "b1", group.Results[0].ID)
+
+	group, err = searchWithin(context.Background(), nil, guard, items, filter, "None")
+	require.NoError(t, err)
+	assert.Equal(t, "root", group.ID)
+	assert.Len(t, group.Results, 2)
+
+	_, err = searchWithin(context.Background(), nil, guard, items, filter, "cccc")
+	assert.ErrorContains(t, err, "item not found")
+}
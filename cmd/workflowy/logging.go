@@ -8,17 +8,28 @@ import (
 	"os"
 	"strings"
 
+	"github.com/mholzen/workflowy/pkg/logging"
 	"github.com/mholzen/workflowy/pkg/workflowy"
 )
 
 type simpleHandler struct {
 	level  slog.Level
 	writer io.Writer
+	attrs  []slog.Attr
 }
 
-func setupLogging(level string, logFile string) {
+// logConfig describes where and how log records are written
+type logConfig struct {
+	Level      string
+	File       string
+	Format     string // text or json
+	MaxSizeMB  int    // rotate the log file at this size (0 = never)
+	MaxBackups int    // rotated log files kept
+}
+
+func setupLogging(cfg logConfig) {
 	var logLevel slog.Level
-	switch level {
+	switch cfg.Level {
 	case "debug":
 		logLevel = slog.LevelDebug
 	case "info":
@@ -33,9 +44,9 @@ func setupLogging(level string, logFile string) {
 	}
 
 	writer := io.Writer(os.Stderr)
-	if logFile != "" {
-		logFile = workflowy.ExpandTilde(logFile)
-		f, err := os.OpenFile(logFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if cfg.File != "" {
+		logFile := workflowy.ExpandTilde(cfg.File)
+		f, err := logging.OpenRotatingFile(logFile, int64(cfg.MaxSizeMB)<<20, cfg.MaxBackups)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: cannot open log file %s: %v\n", logFile, err)
 			os.Exit(1)
@@ -43,11 +54,20 @@ func setupLogging(level string, logFile string) {
 		writer = f
 	}
 
-	handler := &simpleHandler{
-		level:  logLevel,
-		writer: writer,
+	var handler slog.Handler
+	switch cfg.Format {
+	case "", "text":
+		handler = &simpleHandler{
+			level:  logLevel,
+			writer: writer,
+		}
+	case "json":
+		handler = slog.NewJSONHandler(writer, &slog.HandlerOptions{Level: logLevel})
+	default:
+		fmt.Fprintf(os.Stderr, "Error: log format must be one of: text, json\n")
+		os.Exit(1)
 	}
-	slog.SetDefault(slog.New(handler))
+	slog.SetDefault(slog.New(logging.NewContextHandler(handler)))
 }
 
 func (h *simpleHandler) Enabled(_ context.Context, level slog.Level) bool {
@@ -58,6 +78,9 @@ func (h *simpleHandler) Handle(_ context.Context, r slog.Record) error {
 	level := r.Level.String()
 	msg := r.Message
 	var attrs []string
+	for _, a := range h.attrs {
+		attrs = append(attrs, fmt.Sprintf("%s='%v'", a.Key, a.Value))
+	}
 	r.Attrs(func(a slog.Attr) bool {
 		attrs = append(attrs, fmt.Sprintf("%s='%v'", a.Key, a.Value))
 		return true
@@ -71,7 +94,11 @@ func (h *simpleHandler) Handle(_ context.Context, r slog.Record) error {
 }
 
 func (h *simpleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return h
+	return &simpleHandler{
+		level:  h.level,
+		writer: h.writer,
+		attrs:  append(append([]slog.Attr{}, h.attrs...), attrs...),
+	}
 }
 
 func (h *simpleHandler) WithGroup(name string) slog.Handler {
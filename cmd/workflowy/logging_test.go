@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/mholzen/workflowy/pkg/logging"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,10 +19,48 @@ func TestSetupLogging_WritesToFile(t *testing.T) {
 	dir := t.TempDir()
 	logPath := filepath.Join(dir, "workflowy.log")
 
-	setupLogging("info", logPath)
+	setupLogging(logConfig{Level: "info", File: logPath})
 	slog.Info("hello world")
 
 	data, err := os.ReadFile(logPath)
 	assert.NoError(t, err)
 	assert.Contains(t, string(data), "INFO: hello world")
 }
+
+func TestSetupLogging_IncludesRequestID(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	logPath := filepath.Join(t.TempDir(), "workflowy.log")
+	setupLogging(logConfig{Level: "debug", File: logPath})
+
+	ctx := logging.WithRequestID(context.Background(), "abc123")
+	slog.DebugContext(ctx, "from context")
+	logging.Logger(ctx).Debug("from logger", "id", "x")
+
+	data, err := os.ReadFile(logPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "DEBUG: from context (request_id='abc123')")
+	assert.Contains(t, string(data), "DEBUG: from logger (request_id='abc123' id='x')")
+}
+
+func TestSetupLogging_JSONFormat(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	logPath := filepath.Join(t.TempDir(), "workflowy.log")
+	setupLogging(logConfig{Level: "info", File: logPath, Format: "json"})
+
+	ctx := logging.WithRequestID(context.Background(), "abc123")
+	slog.InfoContext(ctx, "hello json", "tool", "get")
+
+	data, err := os.ReadFile(logPath)
+	assert.NoError(t, err)
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(data, &record))
+	assert.Equal(t, "hello json", record["msg"])
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "get", record["tool"])
+	assert.Equal(t, "abc123", record["request_id"])
+}
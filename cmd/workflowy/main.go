@@ -2,9 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/mholzen/workflowy/pkg/cache"
+	"github.com/mholzen/workflowy/pkg/client"
+	"github.com/mholzen/workflowy/pkg/config"
+	"github.com/mholzen/workflowy/pkg/logging"
+	"github.com/mholzen/workflowy/pkg/workflowy"
 	"github.com/urfave/cli/v3"
 )
 
@@ -14,6 +24,9 @@ var (
 	date    = "unknown"
 )
 
+// cancelTimeout releases the --timeout deadline once the command returns
+var cancelTimeout context.CancelFunc = func() {}
+
 func main() {
 	cmd := &cli.Command{
 		Name:  "workflowy",
@@ -24,11 +37,13 @@ Specify how to access the data using the --method flag:
   --method=get      Use GET API (default for depth 1-3)
   --method=export   Use Export API (default for depth 4+, --all)
   --method=backup   Use local backup file (fastest, offline)
+  --method=cache    Use the tree cache of the last export (instant, offline)
 
 Further customize the access method with the following flags:
-  --api-key-file    Path to API key file (default: ~/.workflowy/api.key)
+  --api-key-file    Path to API key file (default: ~/.workflowy/api.key),
+                    read when WORKFLOWY_API_KEY is not set
   --force-refresh   Bypass export cache (use with --method=export)
-  --backup-file     Path to backup file (default: latest in ~/Dropbox/Apps/Workflowy/Data)
+  --backup-file     Path to backup file (default: latest in Dropbox/Apps/Workflowy/Data)
 
 Examples:
   workflowy get --method=backup
@@ -38,8 +53,9 @@ Examples:
 			&cli.StringFlag{
 				Name:    "format",
 				Aliases: []string{"f"},
-				Value:   "list",
-				Usage:   "Output format: list, json, or markdown",
+				Value:   defaultFormat(),
+				Usage:   "Output format: list, json, or markdown (list also accepts csv, tsv and jsonl; search jsonl; get dot and mermaid; get and list template)",
+				Sources: cli.EnvVars("WORKFLOWY_FORMAT"),
 			},
 			&cli.StringFlag{
 				Name:  "log",
@@ -50,19 +66,146 @@ Examples:
 				Name:  "log-file",
 				Usage: "Write logs to file instead of stderr",
 			},
+			&cli.StringFlag{
+				Name:  "log-format",
+				Value: "text",
+				Usage: "Log format: text or json",
+			},
+			&cli.IntFlag{
+				Name:  "log-max-size",
+				Value: 10,
+				Usage: "Rotate the log file when it reaches this size in MB (0 to disable)",
+			},
+			&cli.IntFlag{
+				Name:  "log-max-backups",
+				Value: 3,
+				Usage: "Number of rotated log files to keep",
+			},
+			&cli.BoolFlag{
+				Name:    "quiet",
+				Aliases: []string{"q"},
+				Usage:   "Suppress informational messages; only the primary output is written to stdout",
+			},
+			&cli.BoolFlag{
+				Name:  "no-pager",
+				Usage: "Do not send the output of get, list, search and report to $PAGER on a terminal",
+			},
+			&cli.StringFlag{
+				Name:  "oversize",
+				Value: string(workflowy.OversizeSplit),
+				Usage: "Names and notes over the API length limits: split (into continuation children), truncate, or error",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "Fail the command if it does not complete within this duration, e.g. 30s (0 for no limit; ignored by mcp)",
+			},
+			&cli.IntFlag{
+				Name:  "retries",
+				Value: client.DefaultRetries,
+				Usage: "Retry API requests failing with 429, or 5xx except for creates and updates, up to this many times, with exponential backoff (0 to disable)",
+			},
+			&cli.DurationFlag{
+				Name:    "max-backup-age",
+				Usage:   "Refuse to read a backup older than this, e.g. 24h (0 for no limit; older than 24h only warns)",
+				Sources: cli.EnvVars("WORKFLOWY_MAX_BACKUP_AGE"),
+			},
+			&cli.DurationFlag{
+				Name:    "cache-ttl",
+				Value:   cache.DefaultCacheExpiryDuration,
+				Usage:   "How long an export is reused from the export cache before fetching a new one (0 to always fetch)",
+				Sources: cli.EnvVars("WORKFLOWY_CACHE_TTL"),
+			},
+			&cli.BoolFlag{
+				Name:    "usage-stats",
+				Usage:   "Record the commands and MCP tools used, with their durations, in ~/.workflowy/usage.json (local only; see stats usage)",
+				Sources: cli.EnvVars("WORKFLOWY_USAGE_STATS"),
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Write to nodes locked with " + workflowy.LockTag + " (or within one)",
+			},
 			getAPIKeyFlag(),
 			getWriteRootIdFlag(),
 			getReadRootIdFlag(),
 		},
 		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
-			setupLogging(cmd.String("log"), cmd.String("log-file"))
+			quiet = cmd.Bool("quiet")
+			// the config command can still show where the file is
+			if configErr != nil && cmd.Args().First() != "config" {
+				return ctx, configErr
+			}
+			maxBackupAge = cmd.Duration("max-backup-age")
+			forceWrites = cmd.Bool("force")
+			level := cmd.String("log")
+			if quiet && !cmd.IsSet("log") {
+				level = "warn"
+			}
+			setupLogging(logConfig{
+				Level:      level,
+				File:       cmd.String("log-file"),
+				Format:     cmd.String("log-format"),
+				MaxSizeMB:  cmd.Int("log-max-size"),
+				MaxBackups: cmd.Int("log-max-backups"),
+			})
+			if level == "debug" {
+				// tag debug records so interleaved runs sharing a log file can be told apart
+				ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+			}
+			if _, err := workflowy.ParseOversizePolicy(cmd.String("oversize")); err != nil {
+				return ctx, err
+			}
+			if cmd.Duration("cache-ttl") < 0 {
+				return ctx, fmt.Errorf("cache-ttl cannot be negative")
+			}
+			cache.CacheExpiryDuration = cmd.Duration("cache-ttl")
+			aliases, err := config.ParseAliases(userAliases())
+			if err != nil {
+				return ctx, fmt.Errorf("invalid aliases: %w", err)
+			}
+			workflowy.SetAliases(aliases)
+			if timeout := cmd.Duration("timeout"); timeout > 0 && cmd.Args().First() != "mcp" {
+				ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+			}
+			if !cmd.Bool("no-pager") && shouldPage(cmd.Args().First(), cmd.String("format"), isTerminal(os.Stdout)) {
+				startPager()
+			}
 			return ctx, nil
 		},
 		Commands: getCommands(),
 	}
 
-	if err := cmd.Run(context.Background(), os.Args); err != nil {
-		slog.Error("cannot run command", "error", err)
-		os.Exit(1)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		// restore default handling so a second signal terminates immediately
+		stop()
+		slog.Warn("interrupted, stopping after the current operation (repeat to abort)")
+	}()
+
+	start := time.Now()
+	err := cmd.Run(ctx, os.Args)
+	stopPager()
+	cancelTimeout()
+	if cmd.Bool("usage-stats") {
+		recordUsage(cmd, start, err)
+	}
+	// a signal is the normal way to stop the MCP server
+	if ctx.Err() != nil && !(err == nil && cmd.Args().First() == "mcp") {
+		interrupted := errors.New("interrupted")
+		if err != nil {
+			interrupted = fmt.Errorf("interrupted: %w", err)
+		}
+		err = &exitError{err: interrupted, code: exitInterrupted}
+	}
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && cmd.Duration("timeout") > 0 {
+			err = fmt.Errorf("timed out after %s: %w", cmd.Duration("timeout"), err)
+		}
+		if cmd.String("format") == "json" {
+			printError(os.Stderr, err)
+		} else {
+			slog.Error("cannot run command", "error", err)
+		}
+		os.Exit(exitCode(err))
 	}
 }
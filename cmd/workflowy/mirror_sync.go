@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mholzen/workflowy/pkg/outline"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+func getMirrorSyncCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "mirror-sync",
+		Usage:     "Make a subtree a copy of another one",
+		UsageText: "workflowy mirror-sync --src=<id> --dst=<id> [options]",
+		Description: `Compares the children of --dst with those of --src, and creates, updates
+and deletes nodes under --dst until they match: to keep a public copy of a
+private working area, for example. Nothing under --src is changed.
+
+Names, notes and completion are compared. At each level, nodes are matched
+by name, then in order, so a node renamed under --src is updated under --dst
+rather than deleted and recreated, and nodes of --dst keep their IDs, and
+their links and mirrors. Nodes created under --dst go at the bottom of their
+parent: existing nodes are not reordered.
+
+Unlike mirrors made in the Workflowy app, the copy is only as fresh as the
+last mirror-sync; run it again, e.g. from cron, to keep it up to date.
+
+Examples:
+  workflowy mirror-sync --src=3495d784 --dst=a8e4b2c1 --dry-run
+  workflowy mirror-sync --src=projects --dst=public --one-way`,
+		Flags: []cli.Flag{
+			getAPIKeyFlag(),
+			&cli.StringFlag{
+				Name:  "src",
+				Usage: "ID of the node whose children are copied: UUID, short ID or target key",
+			},
+			&cli.StringFlag{
+				Name:  "dst",
+				Usage: "ID of the node whose children are made to match: UUID, short ID or target key",
+			},
+			&cli.BoolFlag{
+				Name:  "one-way",
+				Value: true,
+				Usage: "Only change --dst to match --src (two-way sync is not supported)",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Print the changes without applying them",
+			},
+			&cli.BoolFlag{
+				Name:  "force-refresh",
+				Usage: "Bypass the export cache",
+			},
+		},
+		Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+			if !cmd.Bool("one-way") {
+				return fmt.Errorf("two-way sync between subtrees is not supported: use --one-way")
+			}
+			if cmd.String("src") == "" || cmd.String("dst") == "" {
+				return fmt.Errorf("--src and --dst are required")
+			}
+
+			readGuard, err := NewReadGuard(ctx, client, getReadRootID(cmd))
+			if err != nil {
+				return err
+			}
+			guard, err := NewWriteGuard(ctx, client, getWriteRootID(cmd))
+			if err != nil {
+				return err
+			}
+			srcID, err := workflowy.ResolveNodeID(ctx, client, cmd.String("src"))
+			if err != nil {
+				return fmt.Errorf("cannot resolve --src: %w", err)
+			}
+			dstID, err := workflowy.ResolveNodeID(ctx, client, cmd.String("dst"))
+			if err != nil {
+				return fmt.Errorf("cannot resolve --dst: %w", err)
+			}
+			if err := readGuard.ValidateTarget(srcID, "mirror-sync"); err != nil {
+				return err
+			}
+
+			response, err := client.ExportNodesWithCache(ctx, cmd.Bool("force-refresh"))
+			if err != nil {
+				return fmt.Errorf("cannot export nodes: %w", err)
+			}
+			snapshot := workflowy.NewExportSnapshot(response)
+			src, err := snapshot.Root(srcID)
+			if err != nil {
+				return err
+			}
+			dst, err := snapshot.Root(dstID)
+			if err != nil {
+				return err
+			}
+			if err := checkDisjoint(src, dst); err != nil {
+				return err
+			}
+
+			changes := outline.DiffWithNotes(outline.FromItems(src.Children), dst.Children, dst.ID)
+			if changes == nil {
+				changes = []outline.Change{}
+			}
+			output := map[string]any{"src": src.ID, "dst": dst.ID, "changes": changes}
+
+			if len(changes) == 0 || cmd.Bool("dry-run") {
+				if format == "json" {
+					printJSON(output)
+					return nil
+				}
+				printMirrorSyncChanges(changes, src, dst)
+				if len(changes) > 0 {
+					printInfo("\nDry run: %d change(s) would be applied\n", len(changes))
+				}
+				return nil
+			}
+
+			if err := guard.ValidateSubtree(dst.ID, "mirror-sync"); err != nil {
+				return err
+			}
+			result := outline.Apply(ctx, client, changes)
+			var failure error
+			if result.Failed > 0 {
+				failure = partialFailure(result.Failed, len(changes), "changes")
+			}
+			if format == "json" {
+				output["result"] = result
+				printJSON(output)
+				return failure
+			}
+			printMirrorSyncChanges(changes, src, dst)
+			for _, msg := range result.Errors {
+				printInfo("%s\n", msg)
+			}
+			printInfo("applied %d of %d change(s)\n", result.Applied, len(changes))
+			return failure
+		}),
+	}
+}
+
+// checkDisjoint returns an error when src and dst are the same node or one
+// contains the other: the copy would then change what it copies.
+func checkDisjoint(src, dst *workflowy.Item) error {
+	if workflowy.FindItemByID([]*workflowy.Item{src}, dst.ID) != nil {
+		return fmt.Errorf("--dst cannot be --src or one of its descendants")
+	}
+	if workflowy.FindItemByID([]*workflowy.Item{dst}, src.ID) != nil {
+		return fmt.Errorf("--src cannot be a descendant of --dst")
+	}
+	return nil
+}
+
+func printMirrorSyncChanges(changes []outline.Change, src, dst *workflowy.Item) {
+	if len(changes) == 0 {
+		printInfo("%s is in sync with %s\n", dst.ID, src.ID)
+		return
+	}
+	printInfo("%d change(s) to %s\n", len(changes), dst.ID)
+	for _, change := range changes {
+		fmt.Println(change.String())
+	}
+}
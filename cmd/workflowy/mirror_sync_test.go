@@ -0,0 +1,18 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDisjoint(t *testing.T) {
+	private := &workflowy.Item{ID: "private", Children: []*workflowy.Item{{ID: "draft"}}}
+	public := &workflowy.Item{ID: "public"}
+
+	assert.NoError(t, checkDisjoint(private, public))
+	assert.Error(t, checkDisjoint(private, private))
+	assert.Error(t, checkDisjoint(private, private.Children[0]), "the copy cannot be inside its source")
+	assert.Error(t, checkDisjoint(private.Children[0], private), "the source cannot be inside its copy")
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mholzen/workflowy/pkg/move"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+// moveMatching moves every node whose name matches --pattern under --to, as
+// replace does with its matches. With --interactive, each move is confirmed
+// before any is made, so that the nodes keep their order in the destination.
+func moveMatching(ctx context.Context, cmd *cli.Command, client workflowy.Client, guard *WriteGuard) error {
+	format := cmd.String("format")
+
+	destination, err := workflowy.ResolveNodeID(ctx, client, cmd.String("to"))
+	if err != nil {
+		return fmt.Errorf("cannot resolve destination: %w", err)
+	}
+	opts := move.Options{
+		Pattern:     cmd.String("pattern"),
+		Regexp:      cmd.Bool("regexp"),
+		IgnoreCase:  cmd.Bool("ignore-case"),
+		Destination: destination,
+		Depth:       int(cmd.Int("depth")),
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	if err := guard.ValidateParent(destination, "move destination"); err != nil {
+		return err
+	}
+
+	items, _, err := loadTree(ctx, cmd, client)
+	if err != nil {
+		return err
+	}
+
+	parentID, err := workflowy.ResolveNodeID(ctx, client, getParentID(cmd))
+	if err != nil {
+		return fmt.Errorf("cannot resolve parent ID: %w", err)
+	}
+	if err := guard.ValidateParent(parentID, "move"); err != nil {
+		return err
+	}
+
+	searchRoot := items
+	if parentID != "None" {
+		rootItem := findItemByID(items, parentID)
+		if rootItem == nil {
+			return fmt.Errorf("parent item not found: %s", parentID)
+		}
+		searchRoot = rootItem.Children
+	}
+
+	var results []move.Result
+	move.Collect(searchRoot, parentID, opts, 0, &results)
+	for i := range results {
+		if results[i].Skipped {
+			continue
+		}
+		if err := guard.ValidateTarget(results[i].ID, "move"); err != nil {
+			results[i].Skipped = true
+			results[i].SkipReason = err.Error()
+		}
+	}
+
+	if cmd.Bool("breadcrumb") {
+		workflowy.AddBreadcrumbs(results, items)
+	}
+
+	if len(results) == 0 {
+		if format == "json" {
+			fmt.Println("[]")
+		} else {
+			printInfo("No matching nodes found\n")
+		}
+		return nil
+	}
+
+	dryRun := cmd.Bool("dry-run")
+	if !dryRun && cmd.Bool("interactive") {
+		confirmMoves(results)
+	}
+	applied := 0
+	if !dryRun {
+		if applied, err = move.Apply(ctx, client, results, destination, cmd.String("position")); err != nil {
+			return err
+		}
+	}
+
+	if format == "json" {
+		printJSON(results)
+	} else {
+		for _, result := range results {
+			fmt.Println(result.String())
+		}
+		if dryRun {
+			printInfo("\nDry run: %d node(s) would be moved to %s\n", len(results), destination)
+		} else {
+			printInfo("\nMoved %d node(s) to %s", applied, destination)
+			if skipped := len(results) - applied; skipped > 0 {
+				printInfo(", skipped %d", skipped)
+			}
+			printInfo("\n")
+		}
+	}
+
+	return failedResults(results, func(r move.Result) bool { return r.Failed }, "moves")
+}
+
+// confirmMoves asks whether to move each result not skipped, and skips those
+// declined, and all after a quit.
+func confirmMoves(results []move.Result) {
+	for i := range results {
+		result := &results[i]
+		if result.Skipped {
+			continue
+		}
+		confirm, quit := promptYesNoQuit(fmt.Sprintf("Move \"%s\"?", result.Name))
+		if quit {
+			for j := i; j < len(results); j++ {
+				if !results[j].Skipped {
+					results[j].Skipped = true
+					results[j].SkipReason = "user quit"
+				}
+			}
+			return
+		}
+		if !confirm {
+			result.Skipped = true
+			result.SkipReason = "user declined"
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+func getNoteCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "note",
+		Usage:     "Add to the note of a node",
+		UsageText: "workflowy note <subcommand> <id> [<text>...] [options]",
+		Description: `Adds text to the end or the start of a note, on a line of its own, without
+overwriting it: the current note is read first, and written back with the
+text. To replace a note, use update --note.`,
+		Commands: []*cli.Command{
+			getNoteAddCommand("append", "Add text to the end of a note", workflowy.AppendNote),
+			getNoteAddCommand("prepend", "Add text to the start of a note", workflowy.PrependNote),
+		},
+	}
+}
+
+type noteAdder func(ctx context.Context, client workflowy.Client, itemID, text string) (*workflowy.UpdateNodeResponse, error)
+
+func getNoteAddCommand(name, usage string, add noteAdder) *cli.Command {
+	return &cli.Command{
+		Name:      name,
+		Usage:     usage,
+		UsageText: fmt.Sprintf("workflowy note %s <id> [<text>...] [options]", name),
+		Description: fmt.Sprintf(`The text is the arguments after <id>, joined with spaces, or stdin when
+there are none (or "-").
+
+Examples:
+  workflowy note %[1]s 3495d784 "Called back, left a message"
+  date | workflowy note %[1]s inbox`, name),
+		Arguments: []cli.Argument{
+			&cli.StringArg{
+				Name:      "id",
+				UsageText: "<id>",
+			},
+			&cli.StringArgs{
+				Name:      "text",
+				Min:       0,
+				Max:       -1,
+				UsageText: "<text>... (default: stdin)",
+			},
+		},
+		Flags: []cli.Flag{
+			getAPIKeyFlag(),
+			&cli.BoolFlag{
+				Name:  "markdown",
+				Usage: "Convert markdown (**bold**, _italic_, ~~strike~~, `code`, [link](url)) in the text to Workflowy formatting",
+			},
+		},
+		Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+
+			guard, err := NewWriteGuard(ctx, client, getWriteRootID(cmd))
+			if err != nil {
+				return err
+			}
+			rawItemID := cmd.StringArg("id")
+			if rawItemID == "" {
+				return fmt.Errorf("id is required")
+			}
+			itemID, err := workflowy.ResolveNodeID(ctx, client, rawItemID)
+			if err != nil {
+				return fmt.Errorf("cannot resolve ID: %w", err)
+			}
+			if err := guard.ValidateTarget(itemID, "note "+name); err != nil {
+				return err
+			}
+
+			text, err := readTextArgs(cmd.StringArgs("text"))
+			if err != nil {
+				return err
+			}
+			text = strings.TrimSpace(text)
+			if text == "" {
+				return fmt.Errorf("nothing to %s: give the text as arguments or on stdin", name)
+			}
+			text = fromMarkdown(cmd, text)
+
+			slog.Debug("adding to note", "item_id", itemID, "position", name)
+			response, err := add(ctx, client, itemID, text)
+			if err != nil {
+				return fmt.Errorf("cannot update note: %w", err)
+			}
+
+			if format == "json" {
+				printJSON(response)
+			} else {
+				printInfo("%s updated\n", itemID)
+				printOversizeInfo(response.ContinuationIDs, response.Truncated)
+			}
+			return nil
+		}),
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"runtime"
+
+	"github.com/mholzen/workflowy/pkg/filters"
+	"github.com/mholzen/workflowy/pkg/search"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+func getOpenCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "open",
+		Usage:     "Open a node in the Workflowy app or browser",
+		UsageText: "workflowy open <id> [options]\n   workflowy open --search <pattern> [options]",
+		Description: `Opens workflowy://#/<id> in the desktop app, or https://workflowy.com/#/<id>
+in the browser with --web, using the platform opener (open, xdg-open or the
+Windows URL handler).
+
+With --search, the argument is a search pattern, as with search, and the
+first node whose name matches is opened.
+
+Examples:
+  workflowy open inbox
+  workflowy open 3495d784 --web
+  workflowy open 3495d784 --print
+  workflowy open --search -i "weekly review"`,
+		Arguments: []cli.Argument{
+			&cli.StringArg{
+				Name:      "id",
+				UsageText: "<id> or, with --search, <pattern>",
+			},
+		},
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "search",
+				Usage: "Open the first node whose name matches the argument, as a search pattern",
+			},
+			getIgnoreCaseFlag(),
+			getRegexpFlag(),
+			getCompletedFlag(),
+			&cli.BoolFlag{
+				Name:  "web",
+				Usage: "Open the web URL in the browser instead of the desktop app",
+			},
+			&cli.BoolFlag{
+				Name:  "print",
+				Usage: "Print the URL instead of opening it",
+			},
+		}, getMethodFlags()...),
+		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			rawID := cmd.StringArg("id")
+			if rawID == "" {
+				if cmd.Bool("search") {
+					return fmt.Errorf("search pattern is required")
+				}
+				return fmt.Errorf("id is required")
+			}
+
+			var id string
+			var err error
+			if cmd.Bool("search") {
+				id, err = searchFirstMatch(ctx, cmd, client, rawID)
+			} else {
+				id, err = workflowy.ResolveNodeID(ctx, client, rawID)
+				if err != nil {
+					err = fmt.Errorf("cannot resolve ID: %w", err)
+				}
+			}
+			if err != nil {
+				return err
+			}
+
+			url := nodeURL(id, cmd.Bool("web"))
+			if cmd.Bool("print") {
+				fmt.Println(url)
+				return nil
+			}
+			printInfo("opening %s\n", url)
+			return openURL(url)
+		}),
+	}
+}
+
+// searchFirstMatch returns the ID of the first node, in outline order, whose
+// name matches pattern, within the read root.
+func searchFirstMatch(ctx context.Context, cmd *cli.Command, client workflowy.Client, pattern string) (string, error) {
+	if cmd.String("method") == "get" {
+		return "", fmt.Errorf("cannot search using the GET method")
+	}
+	filter := filters.Filter{
+		Pattern:    pattern,
+		Regexp:     cmd.Bool("regexp"),
+		IgnoreCase: cmd.Bool("ignore-case"),
+		Fields:     []string{search.FieldName},
+		Completed:  cmd.String("completed"),
+	}
+	if err := workflowy.ValidateCompletedMode(filter.Completed); err != nil {
+		return "", err
+	}
+
+	readGuard, err := NewReadGuard(ctx, client, getReadRootID(cmd))
+	if err != nil {
+		return "", err
+	}
+	items, _, err := loadTree(ctx, cmd, client)
+	if err != nil {
+		return "", err
+	}
+	if rootID := readGuard.DefaultID("None"); rootID != "None" {
+		root := findRootItem(items, rootID)
+		if root == nil {
+			return "", workflowy.Errorf(workflowy.ErrNotFound, "item not found: %s", rootID)
+		}
+		items = []*workflowy.Item{root}
+	}
+
+	results := filter.Search(items)
+	if len(results) == 0 {
+		return "", workflowy.Errorf(workflowy.ErrNotFound, "no node matches %q", pattern)
+	}
+	if len(results) > 1 {
+		printInfo("%d nodes match, opening the first: %s\n", len(results), results[0].Name)
+	}
+	return results[0].ID, nil
+}
+
+// nodeURL returns the desktop app URL of a node, or its web URL when web is set.
+func nodeURL(id string, web bool) string {
+	if id == "None" {
+		id = ""
+	}
+	if web {
+		return "https://workflowy.com/#/" + id
+	}
+	return "workflowy://#/" + id
+}
+
+// openerCommand returns the command that opens url with the default handler on goos.
+func openerCommand(goos, url string) (string, []string) {
+	switch goos {
+	case "darwin":
+		return "open", []string{url}
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", url}
+	default:
+		return "xdg-open", []string{url}
+	}
+}
+
+func openURL(url string) error {
+	name, args := openerCommand(runtime.GOOS, url)
+	slog.Debug("opening url", "command", name, "url", url)
+	opener := exec.Command(name, args...)
+	if err := opener.Start(); err != nil {
+		return fmt.Errorf("cannot open %s: %w", url, err)
+	}
+	// the opener hands the URL to the app and exits on its own
+	return opener.Process.Release()
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+func TestNodeURL(t *testing.T) {
+	assert.Equal(t, "workflowy://#/abc", nodeURL("abc", false))
+	assert.Equal(t, "https://workflowy.com/#/abc", nodeURL("abc", true))
+	assert.Equal(t, "https://workflowy.com/#/", nodeURL("None", true), "the root has no ID")
+}
+
+func TestOpenerCommand(t *testing.T) {
+	name, args := openerCommand("darwin", "workflowy://#/abc")
+	assert.Equal(t, "open", name)
+	assert.Equal(t, []string{"workflowy://#/abc"}, args)
+
+	name, args = openerCommand("windows", "workflowy://#/abc")
+	assert.Equal(t, "rundll32", name)
+	assert.Equal(t, []string{"url.dll,FileProtocolHandler", "workflowy://#/abc"}, args)
+
+	name, _ = openerCommand("linux", "workflowy://#/abc")
+	assert.Equal(t, "xdg-open", name)
+}
+
+func TestSearchFirstMatch(t *testing.T) {
+	backup := filepath.Join(t.TempDir(), "test.workflowy.backup")
+	require.NoError(t, os.WriteFile(backup, []byte(`[
+		{"id": "projects", "nm": "Projects", "ch": [
+			{"id": "review", "nm": "Weekly review", "cp": 1700000000},
+			{"id": "plan", "nm": "Weekly plan"}
+		]},
+		{"id": "notes", "nm": "Review notes"}
+	]`), 0644))
+
+	run := func(args ...string) (string, error) {
+		var id string
+		var searchErr error
+		cmd := &cli.Command{
+			Flags: getOpenCommand().Flags,
+			Arguments: []cli.Argument{
+				&cli.StringArg{Name: "id"},
+			},
+			Action: func(ctx context.Context, c *cli.Command) error {
+				id, searchErr = searchFirstMatch(ctx, c, nil, c.StringArg("id"))
+				return nil
+			},
+		}
+		require.NoError(t, cmd.Run(context.Background(), append([]string{"open", "--method=backup", "--backup-file=" + backup}, args...)))
+		return id, searchErr
+	}
+
+	id, err := run("weekly")
+	assert.ErrorIs(t, err, workflowy.ErrNotFound)
+	assert.Empty(t, id)
+
+	id, err = run("-i", "weekly")
+	require.NoError(t, err)
+	assert.Equal(t, "review", id, "the first match in outline order")
+
+	id, err = run("-i", "--completed=exclude", "weekly")
+	require.NoError(t, err)
+	assert.Equal(t, "plan", id)
+
+	id, err = run("-E", "^Review")
+	require.NoError(t, err)
+	assert.Equal(t, "notes", id)
+}
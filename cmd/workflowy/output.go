@@ -1,18 +1,56 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
+	"text/template"
 
 	"github.com/mholzen/workflowy/pkg/formatter"
 	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
 )
 
+// quiet is set by the global --quiet flag
+var quiet bool
+
+// printInfo writes an informational message (summaries, confirmations) to stderr,
+// keeping stdout for the primary payload. Nothing is written with --quiet.
+func printInfo(format string, args ...any) {
+	if quiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+// printOversizeInfo reports how a name or note over the API length limits was handled.
+func printOversizeInfo(continuationIDs []string, truncated bool) {
+	if len(continuationIDs) > 0 {
+		printInfo("content over the length limit continued in %d child nodes: %s\n", len(continuationIDs), strings.Join(continuationIDs, ", "))
+	}
+	if truncated {
+		printInfo("content over the length limit was truncated\n")
+	}
+}
+
+// printPageInfo reports which part of a paginated list was printed.
+func printPageInfo(page *workflowy.Page) {
+	if len(page.Items) == 0 {
+		printInfo("no items from offset %d of %d\n", page.Offset, page.Total)
+		return
+	}
+	printInfo("items %d-%d of %d\n", page.Offset+1, page.Offset+len(page.Items), page.Total)
+	if page.NextOffset > 0 {
+		printInfo("next page: --offset=%d\n", page.NextOffset)
+	}
+}
+
 func printJSON(response interface{}) {
 	printJSONToWriter(os.Stdout, response)
 }
@@ -25,6 +63,104 @@ func printJSONToWriter(w io.Writer, response interface{}) {
 	fmt.Fprintf(w, "%s\n", prettyJSON)
 }
 
+// printJSONLines writes each item or search result of data as a line of JSON,
+// as it goes, so that large lists stream into tools like jq.
+func printJSONLines(data interface{}) {
+	printJSONLinesToWriter(os.Stdout, data)
+}
+
+func printJSONLinesToWriter(w io.Writer, data interface{}) {
+	buffered := bufio.NewWriter(w)
+	defer buffered.Flush()
+	encoder := json.NewEncoder(buffered)
+	encode := func(v interface{}) {
+		if err := encoder.Encode(v); err != nil {
+			log.Fatalf("cannot format JSON: %v", err)
+		}
+	}
+
+	switch v := data.(type) {
+	case *workflowy.ListChildrenResponse:
+		for _, item := range v.Items {
+			encode(item)
+		}
+	case []SearchResult:
+		for _, result := range v {
+			encode(result)
+		}
+	default:
+		encode(data)
+	}
+}
+
+// printGraph prints a fetched *Item with its descendants, or the items of a
+// *ListChildrenResponse, as a Graphviz (dot) or Mermaid graph.
+func printGraph(data interface{}, format string, labelLength int) {
+	var root *workflowy.Item
+	switch v := data.(type) {
+	case *workflowy.Item:
+		root = v
+	case *workflowy.ListChildrenResponse:
+		root = formatter.GraphRoot(v.Items)
+	default:
+		printJSON(data)
+		return
+	}
+	if format == "dot" {
+		fmt.Print(formatter.FormatDOT(root, labelLength))
+	} else {
+		fmt.Print(formatter.FormatMermaid(root, labelLength))
+	}
+}
+
+// printTemplate executes tmpl for a fetched *Item and each of its
+// descendants, or each item of a *ListChildrenResponse and its descendants.
+func printTemplate(data interface{}, tmpl *template.Template) {
+	var items []*workflowy.Item
+	switch v := data.(type) {
+	case *workflowy.Item:
+		items = []*workflowy.Item{v}
+	case *workflowy.ListChildrenResponse:
+		items = v.Items
+	default:
+		printJSON(data)
+		return
+	}
+	if err := formatter.WriteTemplate(os.Stdout, tmpl, formatter.Rows(items)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// getTemplateFileFlag returns the --template-file flag of --format=template.
+func getTemplateFileFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "template-file",
+		Usage: "Go text/template executed for each node with --format=template; fields: ID, Name, Note, URL, ParentID, Depth, Completed, Created, Modified",
+	}
+}
+
+// validateTemplateFile checks that --template-file is given with
+// --format=template, and only then.
+func validateTemplateFile(cmd *cli.Command, format string) error {
+	if format == "template" && cmd.String("template-file") == "" {
+		return fmt.Errorf("--format=template requires --template-file")
+	}
+	if format != "template" && cmd.String("template-file") != "" {
+		return fmt.Errorf("--template-file applies to --format=template")
+	}
+	return nil
+}
+
+// loadOutputTemplate reads and parses --template-file.
+func loadOutputTemplate(cmd *cli.Command) (*template.Template, error) {
+	path := workflowy.ExpandTilde(cmd.String("template-file"))
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read template: %w", err)
+	}
+	return formatter.ParseTemplate(filepath.Base(path), string(text))
+}
+
 func sortItemsByPriority(items []*workflowy.Item) {
 	sort.Slice(items, func(i, j int) bool {
 		return items[i].Priority < items[j].Priority
@@ -37,42 +173,61 @@ func sortItemsByPriority(items []*workflowy.Item) {
 	}
 }
 
-func itemToMarkdownList(item *workflowy.Item, depth int) string {
+// itemToMarkdownList renders item and its descendants as a bulleted list. With
+// notes, each note follows its name on the same line, after a tab.
+func itemToMarkdownList(item *workflowy.Item, depth int, notes bool) string {
 	indent := strings.Repeat("  ", depth)
-	result := fmt.Sprintf("%s- %s\n", indent, item.Name)
+	result := fmt.Sprintf("%s- %s", indent, item.Name)
+	if item.Breadcrumb != "" {
+		result += fmt.Sprintf(" (in %s)", item.Breadcrumb)
+	}
+	if note := formatter.NoteColumn(item); notes && note != "" {
+		result += "\t" + note
+	}
+	result += "\n"
 
 	for _, child := range item.Children {
-		result += itemToMarkdownList(child, depth+1)
+		result += itemToMarkdownList(child, depth+1, notes)
 	}
 
 	return result
 }
 
-func responseToMarkdownList(response *workflowy.ListChildrenResponse) string {
+func responseToMarkdownList(response *workflowy.ListChildrenResponse, notes bool) string {
 	var result strings.Builder
 
 	for _, item := range response.Items {
-		result.WriteString(itemToMarkdownList(item, 0))
+		result.WriteString(itemToMarkdownList(item, 0, notes))
 	}
 
 	return result.String()
 }
 
 func filterEmptyNames(items []*workflowy.Item) []*workflowy.Item {
-	filtered := make([]*workflowy.Item, 0, len(items))
-	for _, item := range items {
-		if strings.TrimSpace(item.Name) != "" {
-			if len(item.Children) > 0 {
-				item.Children = filterEmptyNames(item.Children)
-			}
-			filtered = append(filtered, item)
-		}
-	}
-	return filtered
+	return workflowy.Prune(items, workflowy.EmptyName)
+}
+
+// outputOptions controls how printOutput renders data.
+type outputOptions struct {
+	showEmptyNames bool
+	anchors        bool                   // emit node-ID anchors before markdown headers
+	notes          bool                   // render notes: a column of list output, blocks of markdown
+	labelLength    int                    // truncate the labels of dot and mermaid graphs (0 for no limit)
+	template       *template.Template     // renders each node with --format=template
+	source         workflowy.SnapshotMeta // where the data was read from, as data_source and data_age of JSON output
 }
 
 func printOutput(data interface{}, format string, showEmptyNames bool) {
-	if !showEmptyNames {
+	printOutputWithOptions(data, format, outputOptions{showEmptyNames: showEmptyNames})
+}
+
+func printOutputWithOptions(data interface{}, format string, opts outputOptions) {
+	markdownConfig := formatter.DefaultMarkdownConfig()
+	markdownConfig.Anchors = opts.anchors
+	markdownConfig.Notes = opts.notes
+	formatMarkdown := formatter.NewMarkdownFormatterWithConfig(markdownConfig).FormatTree
+
+	if !opts.showEmptyNames {
 		switch v := data.(type) {
 		case *workflowy.Item:
 			if len(v.Children) > 0 {
@@ -98,9 +253,9 @@ func printOutput(data interface{}, format string, showEmptyNames bool) {
 	case "list":
 		switch v := data.(type) {
 		case *workflowy.Item:
-			fmt.Print(itemToMarkdownList(v, 0))
+			fmt.Print(itemToMarkdownList(v, 0, opts.notes))
 		case *workflowy.ListChildrenResponse:
-			fmt.Print(responseToMarkdownList(v))
+			fmt.Print(responseToMarkdownList(v, opts.notes))
 		case []SearchResult:
 			for _, result := range v {
 				fmt.Println(result.String())
@@ -119,13 +274,13 @@ func printOutput(data interface{}, format string, showEmptyNames bool) {
 	case "markdown":
 		switch v := data.(type) {
 		case *workflowy.Item:
-			output, err := formatter.FormatItemsAsMarkdown(v.Children)
+			output, err := formatMarkdown(v.Children)
 			if err != nil {
 				log.Fatalf("cannot format markdown: %v", err)
 			}
 			fmt.Print(output)
 		case *workflowy.ListChildrenResponse:
-			output, err := formatter.FormatItemsAsMarkdown(v.Items)
+			output, err := formatMarkdown(v.Items)
 			if err != nil {
 				log.Fatalf("cannot format markdown: %v", err)
 			}
@@ -145,7 +300,58 @@ func printOutput(data interface{}, format string, showEmptyNames bool) {
 		default:
 			printJSON(data)
 		}
+	case "jsonl":
+		printJSONLines(data)
+	case "dot", "mermaid":
+		printGraph(data, format, opts.labelLength)
+	case "template":
+		printTemplate(data, opts.template)
 	default:
-		printJSON(data)
+		printJSON(withDataSource(data, opts.source))
+	}
+}
+
+// summarizeOutput prints a summary of each branch of data in place of data
+// when it holds more nodes than --summarize-over, and reports whether it did.
+func summarizeOutput(cmd *cli.Command, data interface{}, format string) bool {
+	threshold := cmd.Int("summarize-over")
+	// a stream of items, a graph or a template is never replaced by a summary
+	if threshold <= 0 || format == "jsonl" || format == "dot" || format == "mermaid" || format == "template" {
+		return false
+	}
+	var branches []*workflowy.Item
+	switch v := data.(type) {
+	case *workflowy.Item:
+		branches = v.Children
+	case *workflowy.ListChildrenResponse:
+		branches = v.Items
+	default:
+		return false
+	}
+	if !cmd.Bool("include-empty-names") {
+		branches = filterEmptyNames(branches)
+	}
+	total := workflowy.CountItems(branches)
+	if total <= threshold {
+		return false
+	}
+	sortItemsByPriority(branches)
+	perBranch := cmd.Int("summary-items")
+	summaries := workflowy.Summarize(branches, perBranch)
+
+	if format == "json" {
+		printJSON(map[string]any{"summarized": true, "total": total, "branches": summaries})
+		return true
+	}
+	printInfo("%d nodes, over --summarize-over=%d: showing the first %d of each branch\n", total, threshold, perBranch)
+	for _, summary := range summaries {
+		fmt.Printf("- %s (%d descendants)\n", summary.Name, summary.Descendants)
+		for _, item := range summary.Items {
+			fmt.Printf("  - %s\n", item.Name)
+		}
+		if summary.More > 0 {
+			fmt.Printf("  - … %d more\n", summary.More)
+		}
 	}
+	return true
 }
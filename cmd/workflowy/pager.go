@@ -0,0 +1,84 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// pagedCommands are the commands whose output goes through the pager
+var pagedCommands = []string{"get", "list", "search", "report"}
+
+// stopPager waits for the pager started by startPager, if any
+var stopPager = func() {}
+
+// shouldPage reports whether the output of command in format goes through the
+// pager: only text output of browsing commands, written to a terminal.
+func shouldPage(command, format string, terminal bool) bool {
+	return terminal && slices.Contains(pagedCommands, command) && (format == "list" || format == "markdown")
+}
+
+// pagerCommand returns the pager to run, as git chooses it: $WORKFLOWY_PAGER,
+// then $PAGER, then less. An empty or "cat" pager disables paging.
+func pagerCommand(lookupEnv func(string) (string, bool)) string {
+	for _, env := range []string{"WORKFLOWY_PAGER", "PAGER"} {
+		if pager, ok := lookupEnv(env); ok {
+			pager = strings.TrimSpace(pager)
+			if pager == "cat" {
+				return ""
+			}
+			return pager
+		}
+	}
+	return "less"
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// startPager sends stdout through the pager until stopPager is called. Like
+// git, it sets LESS=FRX unless LESS is set, so that less exits at once when
+// the output fits on the screen. Without a pager, stdout is left unchanged.
+func startPager() {
+	pager := pagerCommand(os.LookupEnv)
+	if pager == "" || runtime.GOOS == "windows" {
+		return
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		slog.Debug("cannot start pager", "error", err)
+		return
+	}
+	cmd := exec.Command("sh", "-c", pager)
+	cmd.Stdin = r
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	if err := cmd.Start(); err != nil {
+		slog.Debug("cannot start pager", "pager", pager, "error", err)
+		r.Close()
+		w.Close()
+		return
+	}
+	r.Close()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	stopPager = func() {
+		os.Stdout = stdout
+		w.Close()
+		if err := cmd.Wait(); err != nil {
+			slog.Debug("pager exited", "pager", pager, "error", err)
+		}
+		stopPager = func() {}
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldPage(t *testing.T) {
+	assert.True(t, shouldPage("list", "list", true))
+	assert.True(t, shouldPage("get", "markdown", true))
+	assert.False(t, shouldPage("get", "markdown", false), "not a terminal")
+	assert.False(t, shouldPage("get", "json", true), "json is for programs")
+	assert.False(t, shouldPage("create", "list", true))
+}
+
+func TestPagerCommand(t *testing.T) {
+	env := func(vars map[string]string) func(string) (string, bool) {
+		return func(key string) (string, bool) {
+			value, ok := vars[key]
+			return value, ok
+		}
+	}
+	assert.Equal(t, "less", pagerCommand(env(nil)))
+	assert.Equal(t, "more", pagerCommand(env(map[string]string{"PAGER": "more"})))
+	assert.Equal(t, "bat -p", pagerCommand(env(map[string]string{"PAGER": "more", "WORKFLOWY_PAGER": "bat -p"})))
+	assert.Equal(t, "", pagerCommand(env(map[string]string{"PAGER": "cat"})))
+	assert.Equal(t, "", pagerCommand(env(map[string]string{"PAGER": ""})))
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/mcp"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+func getPermissionsCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "permissions",
+		Usage:     "Show the tools, roots and API budget available to the MCP server",
+		UsageText: "workflowy permissions [options]",
+		Description: `Report what "workflowy mcp" run with the same options would allow: the
+exposed tools, whether it is read-only, which tools preview their edits by
+default, the write and read roots, and the API requests left.`,
+		Flags: append(getMethodFlags(),
+			&cli.StringFlag{
+				Name:    "expose",
+				Value:   "read",
+				Usage:   "Tools to expose: read, write, all, or comma-separated tool names",
+				Sources: cli.EnvVars("WORKFLOWY_MCP_EXPOSE"),
+			},
+		),
+		Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+
+			tools, err := mcp.ParseExposeList(cmd.String("expose"))
+			if err != nil {
+				return err
+			}
+			writeGuard, err := newWriteRootGuard(ctx, client, getWriteRootID(cmd))
+			if err != nil {
+				return err
+			}
+			readGuard, err := NewReadGuard(ctx, client, getReadRootID(cmd))
+			if err != nil {
+				return err
+			}
+
+			permissions := mcp.ExposedPermissions(tools)
+			permissions.Method = cmd.String("method")
+			permissions.BackupFile = cmd.String("backup-file")
+			if writeGuard.IsRestricted() {
+				permissions.WriteRoot = &mcp.Root{ID: writeGuard.WriteRootID(), Name: writeGuard.WriteRootName()}
+			}
+			if readGuard.IsRestricted() {
+				permissions.ReadRoot = &mcp.Root{ID: readGuard.ReadRootID(), Name: readGuard.ReadRootName()}
+			}
+			if permissions.Method != "backup" {
+				if limit, known, err := workflowy.RateLimit(ctx, client); err != nil {
+					slog.Debug("cannot get rate limit", "error", err)
+				} else if known {
+					permissions.RateLimit = &limit
+				}
+			}
+
+			if format == "json" {
+				printJSON(permissions)
+				return nil
+			}
+			fmt.Print(formatPermissions(permissions))
+			return nil
+		}),
+	}
+}
+
+// formatPermissions describes permissions as text, one aspect per line.
+func formatPermissions(permissions mcp.Permissions) string {
+	var sb strings.Builder
+	mode := "read and write"
+	if permissions.ReadOnly {
+		mode = "read-only"
+	}
+	fmt.Fprintf(&sb, "Tools (%s): %s\n", mode, strings.Join(permissions.Tools, ", "))
+	if len(permissions.DryRun) > 0 {
+		fmt.Fprintf(&sb, "Dry-run by default: %s\n", strings.Join(permissions.DryRun, ", "))
+	}
+
+	writes, reads := "anywhere", "anywhere"
+	if permissions.ReadOnly {
+		writes = "none"
+	} else if permissions.WriteRoot != nil {
+		writes = "restricted to " + restrictionRoot(*permissions.WriteRoot).String()
+	}
+	if permissions.ReadRoot != nil {
+		reads = "restricted to " + restrictionRoot(*permissions.ReadRoot).String()
+	}
+	fmt.Fprintf(&sb, "Writes: %s\n", writes)
+	fmt.Fprintf(&sb, "Reads: %s\n", reads)
+
+	if permissions.Method != "" {
+		fmt.Fprintf(&sb, "Access method: %s\n", permissions.Method)
+	}
+	if permissions.RateLimit != nil {
+		fmt.Fprintf(&sb, "Rate limit: %s\n", permissions.RateLimit)
+	}
+	return sb.String()
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatPermissions(t *testing.T) {
+	tools, err := mcp.ParseExposeList("read")
+	require.NoError(t, err)
+	permissions := mcp.ExposedPermissions(tools)
+	assert.True(t, permissions.ReadOnly)
+	assert.Empty(t, permissions.DryRun)
+	text := formatPermissions(permissions)
+	assert.Contains(t, text, "Tools (read-only): workflowy_get, ")
+	assert.Contains(t, text, "Writes: none\n")
+	assert.Contains(t, text, "Reads: anywhere\n")
+
+	tools, err = mcp.ParseExposeList("get,replace")
+	require.NoError(t, err)
+	permissions = mcp.ExposedPermissions(tools)
+	permissions.WriteRoot = &mcp.Root{ID: "abc", Name: "Inbox"}
+	assert.False(t, permissions.ReadOnly)
+	assert.Equal(t, []string{"workflowy_replace"}, permissions.DryRun)
+	assert.Equal(t, "Tools (read and write): workflowy_get, workflowy_replace\n"+
+		"Dry-run by default: workflowy_replace\n"+
+		"Writes: restricted to Inbox (abc)\n"+
+		"Reads: anywhere\n", formatPermissions(permissions))
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/escape"
+	"github.com/mholzen/workflowy/pkg/picker"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+// pickContextLevels is how many ancestors are shown, and matched, after the
+// name of each node in the picker.
+const pickContextLevels = 3
+
+func getPickCommand() *cli.Command {
+	completed := getCompletedFlag()
+	completed.Value = workflowy.CompletedExclude
+	return &cli.Command{
+		Name:      "pick",
+		Usage:     "Pick a node interactively with a fuzzy finder and print its ID",
+		UsageText: "workflowy pick [options]",
+		Description: `Loads the tree (export API or backup) and lets you pick a node in the
+terminal: type to narrow down the nodes by name and path, move with the arrow
+keys (or ctrl-n and ctrl-p) and press enter to pick, or escape to cancel. The
+ID of the node picked is printed, so that it can be given to other commands.
+
+The picker draws on the terminal, not the standard output, so it can be used
+within $(...). Cancelling exits with status 130.
+
+Examples:
+  workflowy pick
+  workflowy move $(workflowy pick) inbox
+  workflowy get $(workflowy pick --id=projects --query=review)`,
+		Flags: append([]cli.Flag{
+			getIdFlag("ID to pick within (default: root)"),
+			&cli.StringFlag{
+				Name:  "query",
+				Usage: "Start with this query typed",
+			},
+			completed,
+		}, getMethodFlags()...),
+		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+			if err := workflowy.ValidateCompletedMode(cmd.String("completed")); err != nil {
+				return err
+			}
+			if cmd.String("method") == "get" {
+				return fmt.Errorf("cannot pick using the GET method")
+			}
+
+			tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+			if err != nil {
+				return fmt.Errorf("pick needs a terminal: %w", err)
+			}
+			defer tty.Close()
+
+			items, err := loadChildrenOfID(ctx, cmd, client, "pick")
+			if err != nil {
+				return err
+			}
+			items = workflowy.FilterCompleted(items, cmd.String("completed"))
+
+			candidates := pickCandidates(items)
+			if len(candidates) == 0 {
+				return fmt.Errorf("no node to pick")
+			}
+			picked, err := picker.Run(tty, candidates, cmd.String("query"))
+			if errors.Is(err, picker.ErrCancelled) {
+				return &exitError{err: errors.New("no node picked"), code: exitInterrupted}
+			}
+			if err != nil {
+				return err
+			}
+
+			if format == "json" {
+				printJSON(map[string]string{"id": picked.ID, "name": picked.Text, "path": picked.Context})
+				return nil
+			}
+			fmt.Println(picked.ID)
+			return nil
+		}),
+	}
+}
+
+// loadChildrenOfID loads the tree and returns the children of --id, or the
+// top-level nodes without it, within the read root.
+func loadChildrenOfID(ctx context.Context, cmd *cli.Command, client workflowy.Client, action string) ([]*workflowy.Item, error) {
+	readGuard, err := NewReadGuard(ctx, client, getReadRootID(cmd))
+	if err != nil {
+		return nil, err
+	}
+	itemID, err := workflowy.ResolveNodeID(ctx, client, readGuard.DefaultID(getID(cmd)))
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve ID: %w", err)
+	}
+	if err := readGuard.ValidateTarget(itemID, action); err != nil {
+		return nil, err
+	}
+
+	items, _, err := loadTree(ctx, cmd, client)
+	if err != nil {
+		return nil, err
+	}
+	if itemID == "None" {
+		return items, nil
+	}
+	root := findRootItem(items, itemID)
+	if root == nil {
+		return nil, workflowy.Errorf(workflowy.ErrNotFound, "item not found: %s", itemID)
+	}
+	return root.Children, nil
+}
+
+// pickCandidates returns the named nodes of items and their descendants, in
+// outline order, each with the names of its nearest named ancestors as
+// context.
+func pickCandidates(items []*workflowy.Item) []picker.Candidate {
+	var candidates []picker.Candidate
+	var walk func(items []*workflowy.Item, ancestors []string)
+	walk = func(items []*workflowy.Item, ancestors []string) {
+		// the items of a snapshot are shared: sort a copy
+		items = slices.SortedStableFunc(slices.Values(items), func(a, b *workflowy.Item) int {
+			return a.Priority - b.Priority
+		})
+		for _, item := range items {
+			name := strings.Join(strings.Fields(escape.Text(item.Name)), " ")
+			if name != "" {
+				nearest := ancestors[max(len(ancestors)-pickContextLevels, 0):]
+				candidates = append(candidates, picker.Candidate{
+					ID:      item.ID,
+					Text:    name,
+					Context: strings.Join(nearest, workflowy.BreadcrumbSeparator),
+				})
+			}
+			if len(item.Children) > 0 {
+				path := ancestors
+				if name != "" {
+					path = append(ancestors[:len(ancestors):len(ancestors)], name)
+				}
+				walk(item.Children, path)
+			}
+		}
+	}
+	walk(items, nil)
+	return candidates
+}
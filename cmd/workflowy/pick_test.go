@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/picker"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickCandidates(t *testing.T) {
+	items := []*workflowy.Item{
+		{ID: "b", Name: "Inbox", Priority: 2},
+		{ID: "a", Name: "<b>Projects</b>", Priority: 1, Children: []*workflowy.Item{
+			{ID: "a2", Name: "Launch", Priority: 2},
+			{ID: "a1", Name: "", Priority: 1, Children: []*workflowy.Item{
+				{ID: "a11", Name: "Untitled child"},
+			}},
+		}},
+	}
+	assert.Equal(t, []picker.Candidate{
+		{ID: "a", Text: "Projects"},
+		{ID: "a11", Text: "Untitled child", Context: "Projects"},
+		{ID: "a2", Text: "Launch", Context: "Projects"},
+		{ID: "b", Text: "Inbox"},
+	}, pickCandidates(items))
+	assert.Equal(t, "a2", items[1].Children[0].ID, "the items given are not reordered")
+}
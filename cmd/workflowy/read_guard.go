@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
+	"github.com/mholzen/workflowy/pkg/logging"
 	"github.com/mholzen/workflowy/pkg/workflowy"
 )
 
@@ -12,6 +14,7 @@ type ReadGuard struct {
 	client     workflowy.Client
 	readRootID string
 	tree       []*workflowy.Item
+	logger     *slog.Logger
 }
 
 // NewReadGuard creates a guard that restricts all operations to descendants of readRootID.
@@ -20,6 +23,7 @@ func NewReadGuard(ctx context.Context, client workflowy.Client, readRootID strin
 	guard := &ReadGuard{
 		client:     client,
 		readRootID: readRootID,
+		logger:     logging.Logger(ctx),
 	}
 
 	if !workflowy.IsRestricted(readRootID) {
@@ -56,7 +60,9 @@ func (g *ReadGuard) ValidateTarget(targetID, operation string) error {
 	if !g.IsRestricted() {
 		return nil
 	}
-	return workflowy.ValidateReadAccess(g.tree, g.readRootID, targetID, operation)
+	err := workflowy.ValidateReadAccess(g.tree, g.readRootID, targetID, operation)
+	g.logger.Debug("read guard check", "target_id", targetID, "operation", operation, "allowed", err == nil)
+	return err
 }
 
 // DefaultID returns the readRootID when itemID is "None" and restrictions are in effect,
@@ -78,3 +84,14 @@ func (g *ReadGuard) ReadRootID() string {
 	}
 	return g.readRootID
 }
+
+// ReadRootName returns the name of the read root, or empty string if not restricted.
+func (g *ReadGuard) ReadRootName() string {
+	if !g.IsRestricted() {
+		return ""
+	}
+	if item := workflowy.FindItemByID(g.tree, g.readRootID); item != nil {
+		return item.Name
+	}
+	return ""
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/recipes"
+	"github.com/urfave/cli/v3"
+)
+
+func getRecipesCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "recipes",
+		Usage: "List and run recipes, parameterized sequences of commands",
+		Description: `A recipe runs workflowy commands one after the other, with variables filled
+in from --var, so that a multi-step flow can be reused without scripting.
+Recipes ship with workflowy, and more can be defined in recipes.yaml in the
+configuration directory (~/.workflowy/recipes.yaml):
+
+  archive-project:
+    description: Complete a project and move it to the archive
+    vars:
+      - name: project
+        required: true
+      - name: archive
+        default: <archive-id>
+    steps:
+      - name: complete
+        run: [complete, "{{.project}}", --cascade]
+      - run: [move, "{{.project}}", "{{.archive}}"]
+
+Examples:
+  workflowy recipes list
+  workflowy recipes show archive-completed-project
+  workflowy recipes run archive-completed-project --var project=<id> --var archive=<id>`,
+		Commands: []*cli.Command{
+			{
+				Name:      "list",
+				Usage:     "List the available recipes",
+				UsageText: "workflowy recipes list",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					format := cmd.String("format")
+					if err := validateFormat(format); err != nil {
+						return err
+					}
+					available, err := recipes.LoadDefault()
+					if err != nil {
+						return err
+					}
+					if format == "json" {
+						printJSON(available)
+						return nil
+					}
+					for _, recipe := range available {
+						source := ""
+						if recipe.Builtin {
+							source = " (built-in)"
+						}
+						fmt.Printf("- %s%s: %s\n", recipe.Name, source, recipe.Description)
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "show",
+				Usage:     "Show the variables and steps of a recipe",
+				UsageText: "workflowy recipes show <name>",
+				Arguments: []cli.Argument{
+					&cli.StringArg{
+						Name:      "name",
+						UsageText: "<name>",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					format := cmd.String("format")
+					if err := validateFormat(format); err != nil {
+						return err
+					}
+					recipe, err := loadRecipe(cmd.StringArg("name"))
+					if err != nil {
+						return err
+					}
+					if format == "json" {
+						printJSON(recipe)
+						return nil
+					}
+					printRecipe(recipe)
+					return nil
+				},
+			},
+			{
+				Name:      "run",
+				Usage:     "Run the steps of a recipe",
+				UsageText: "workflowy recipes run <name> [--var name=value]... [options]",
+				Description: `Run the steps of a recipe in order, each as its own workflowy command with the
+global options given here (such as --format or --write-root-id). The run stops
+at the first step that fails, unless the step sets continue_on_error. Each
+step is recorded in the journal as a separate command, to revert with undo.
+
+Examples:
+  workflowy recipes run archive-completed-project --var project=<id> --var archive=<id> --dry-run
+  workflowy recipes run weekly-report-upload --var project=<id>`,
+				Arguments: []cli.Argument{
+					&cli.StringArg{
+						Name:      "name",
+						UsageText: "<name>",
+					},
+				},
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "var",
+						Usage: "Set a variable of the recipe, as name=value (repeatable)",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Print the commands without running them",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					recipe, err := loadRecipe(cmd.StringArg("name"))
+					if err != nil {
+						return err
+					}
+					values, err := recipes.ParseVars(cmd.StringSlice("var"))
+					if err != nil {
+						return err
+					}
+					commands, err := recipe.Commands(values)
+					if err != nil {
+						return err
+					}
+					globals := forwardedFlags(cmd.Root())
+					if cmd.Bool("dry-run") {
+						if cmd.String("format") == "json" {
+							printJSON(commands)
+							return nil
+						}
+						for i, command := range commands {
+							fmt.Printf("%d. %s: workflowy %s\n", i+1, command.Label(), shellJoin(append(globals, command.Args...)))
+						}
+						return nil
+					}
+					return runRecipe(ctx, recipe, commands, globals)
+				},
+			},
+		},
+	}
+}
+
+// loadRecipe returns the recipe called name, among the built-in recipes and
+// those of recipes.yaml.
+func loadRecipe(name string) (recipes.Recipe, error) {
+	if name == "" {
+		return recipes.Recipe{}, fmt.Errorf("recipe name is required")
+	}
+	available, err := recipes.LoadDefault()
+	if err != nil {
+		return recipes.Recipe{}, err
+	}
+	return recipes.Find(available, name)
+}
+
+func printRecipe(recipe recipes.Recipe) {
+	fmt.Println(recipe.Name)
+	if recipe.Description != "" {
+		fmt.Printf("  %s\n", recipe.Description)
+	}
+	if len(recipe.Vars) > 0 {
+		fmt.Println("\nVariables:")
+		for _, v := range recipe.Vars {
+			detail := ""
+			switch {
+			case v.Required:
+				detail = " (required)"
+			case v.Default != "":
+				detail = fmt.Sprintf(" (default: %s)", v.Default)
+			}
+			fmt.Printf("  %s%s: %s\n", v.Name, detail, v.Description)
+		}
+	}
+	fmt.Println("\nSteps:")
+	for i, step := range recipe.Steps {
+		fmt.Printf("  %d. %s\n", i+1, step.Label())
+		if step.Name != "" {
+			fmt.Printf("     workflowy %s\n", shellJoin(step.Run))
+		}
+	}
+}
+
+// runRecipe runs commands one after the other as workflowy processes, with the
+// global flags given.
+func runRecipe(ctx context.Context, recipe recipes.Recipe, commands []recipes.Command, globals []string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot locate the workflowy executable: %w", err)
+	}
+	failed := 0
+	for i, command := range commands {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		printInfo("==> %d/%d %s\n", i+1, len(commands), command.Label())
+		step := exec.CommandContext(ctx, executable, append(globals, command.Args...)...)
+		step.Stdin = os.Stdin
+		step.Stdout = os.Stdout
+		step.Stderr = os.Stderr
+		if err := step.Run(); err != nil {
+			err = fmt.Errorf("step %d (%s) of recipe %s failed: %w", i+1, command.Label(), recipe.Name, err)
+			if !command.ContinueOnError {
+				var exitErr *exec.ExitError
+				if errors.As(err, &exitErr) {
+					return &exitError{err: err, code: exitErr.ExitCode()}
+				}
+				return err
+			}
+			printInfo("%v, continuing\n", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return partialFailure(failed, len(commands), "steps")
+	}
+	return nil
+}
+
+// forwardedFlags returns the global flags set on root, as arguments for the
+// commands of a recipe.
+func forwardedFlags(root *cli.Command) []string {
+	var args []string
+	for _, flag := range root.Flags {
+		name := flag.Names()[0]
+		if root.IsSet(name) {
+			args = append(args, fmt.Sprintf("--%s=%v", name, root.Value(name)))
+		}
+	}
+	return args
+}
+
+// shellJoin quotes the arguments that a shell would split or expand.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"\\$`|&;<>()*?#~") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+func TestForwardedFlags(t *testing.T) {
+	var forwarded []string
+	root := &cli.Command{
+		Name: "workflowy",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "format", Value: "list"},
+			&cli.BoolFlag{Name: "force"},
+			&cli.StringFlag{Name: "write-root-id"},
+		},
+		Commands: []*cli.Command{{
+			Name: "recipes",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				forwarded = forwardedFlags(cmd.Root())
+				return nil
+			},
+		}},
+	}
+	require.NoError(t, root.Run(context.Background(), []string{"workflowy", "--format=json", "--force", "recipes"}))
+	assert.Equal(t, []string{"--format=json", "--force=true"}, forwarded)
+}
+
+func TestShellJoin(t *testing.T) {
+	assert.Equal(t, `move 'Q3 plan' abc`, shellJoin([]string{"move", "Q3 plan", "abc"}))
+	assert.Equal(t, `create 'it'\''s' ''`, shellJoin([]string{"create", "it's", ""}))
+}
@@ -18,8 +18,17 @@ func collectReplacements(items []*workflowy.Item, opts ReplaceOptions, currentDe
 }
 
 func promptConfirmation(result ReplaceResult) (confirm bool, quit bool) {
+	if result.MovedTo != "" {
+		return promptYesNoQuit(fmt.Sprintf("Replace \"%s\" → \"%s\" (note: \"%s\" → \"%s\")?", result.OldName, result.NewName, result.OldNote, result.NewNote))
+	}
+	return promptYesNoQuit(fmt.Sprintf("Replace \"%s\" → \"%s\"?", result.OldName, result.NewName))
+}
+
+// promptYesNoQuit asks question on stderr and reads the answer from stdin:
+// whether it was yes, or quit.
+func promptYesNoQuit(question string) (confirm bool, quit bool) {
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("Replace \"%s\" → \"%s\"? [y/N/q] ", result.OldName, result.NewName)
+	fmt.Fprintf(os.Stderr, "%s [y/N/q] ", question)
 
 	response, err := reader.ReadString('\n')
 	if err != nil {
@@ -4,6 +4,8 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/mholzen/workflowy/pkg/patch"
+	"github.com/mholzen/workflowy/pkg/replace"
 	"github.com/mholzen/workflowy/pkg/workflowy"
 	"github.com/stretchr/testify/assert"
 )
@@ -293,3 +295,91 @@ func TestCollectReplacements_URL(t *testing.T) {
 	assert.Len(t, results, 1)
 	assert.Equal(t, "https://workflowy.com/#/abc-123-def", results[0].URL)
 }
+
+func TestCollectReplacements_MoveToNote(t *testing.T) {
+	note := "existing note"
+	items := []*workflowy.Item{
+		{ID: "1", Name: "call bob https://example.com/a", Note: &note},
+		{ID: "2", Name: "no links here"},
+		{ID: "3", Name: "read https://example.com/b"},
+	}
+
+	opts := ReplaceOptions{
+		Pattern: regexp.MustCompile(`https?://\S+`),
+		Depth:   -1,
+		MoveTo:  "note",
+	}
+
+	var results []ReplaceResult
+	collectReplacements(items, opts, 0, &results)
+
+	assert.Len(t, results, 2)
+	assert.Equal(t, "call bob", results[0].NewName)
+	assert.Equal(t, "existing note", results[0].OldNote)
+	assert.Equal(t, "existing note\nhttps://example.com/a", results[0].NewNote)
+	assert.Equal(t, "note", results[0].MovedTo)
+	assert.Equal(t, "read", results[1].NewName)
+	assert.Equal(t, "https://example.com/b", results[1].NewNote)
+}
+
+func TestCollectReplacements_MoveToName(t *testing.T) {
+	note := "due: 2024-05-01\nsome details"
+	items := []*workflowy.Item{
+		{ID: "1", Name: "file taxes", Note: &note},
+		{ID: "2", Name: "no note"},
+	}
+
+	opts := ReplaceOptions{
+		Pattern: regexp.MustCompile(`#\w+|due: \S+`),
+		Depth:   -1,
+		MoveTo:  "name",
+	}
+
+	var results []ReplaceResult
+	collectReplacements(items, opts, 0, &results)
+
+	assert.Len(t, results, 1)
+	assert.Equal(t, "file taxes due: 2024-05-01", results[0].NewName)
+	assert.Equal(t, "some details", results[0].NewNote)
+	assert.Equal(t, "name", results[0].MovedTo)
+}
+
+func TestReplaceResult_String_Moved(t *testing.T) {
+	result := ReplaceResult{
+		ID:      "abc123",
+		OldName: "read https://x.y",
+		NewName: "read",
+		NewNote: "https://x.y",
+		MovedTo: "note",
+		Applied: true,
+	}
+
+	assert.Equal(t, `abc123: "read https://x.y" → "read" (note: "" → "https://x.y")`, result.String())
+}
+
+func TestUndoPatch_OnlyAppliedResults(t *testing.T) {
+	results := []ReplaceResult{
+		{ID: "1", OldName: "hello", NewName: "hi", Applied: true},
+		{ID: "2", OldName: "hello there", NewName: "hi there", Skipped: true},
+		{ID: "3", OldName: "read https://x.y", NewName: "read", NewNote: "https://x.y", MovedTo: "note", Applied: true},
+	}
+
+	undo := replace.UndoPatch(results)
+
+	assert.Equal(t, []patch.Edit{
+		{ID: "3", Field: "note", Old: "https://x.y", New: ""},
+		{ID: "3", Field: "name", Old: "read", New: "read https://x.y"},
+		{ID: "1", Field: "name", Old: "hi", New: "hello"},
+	}, undo)
+}
+
+func TestReplaceResult_String_Breadcrumb(t *testing.T) {
+	result := ReplaceResult{
+		ID:         "abc123",
+		OldName:    "Notes",
+		NewName:    "Meeting notes",
+		Breadcrumb: "Work > Projects",
+	}
+
+	assert.Equal(t, `abc123: "Notes" → (dry-run) "Meeting notes" (in Work > Projects)`, result.String())
+}
@@ -7,7 +7,9 @@ import (
 	"log/slog"
 	"os"
 	"regexp"
+	"time"
 
+	"github.com/mholzen/workflowy/pkg/privateapi"
 	"github.com/mholzen/workflowy/pkg/reports"
 	"github.com/mholzen/workflowy/pkg/workflowy"
 	"github.com/urfave/cli/v3"
@@ -35,12 +37,17 @@ func uploadReport(ctx context.Context, cmd *cli.Command, client workflowy.Client
 		return err
 	}
 
-	fmt.Printf("Report uploaded successfully!\n")
-	fmt.Printf("URL: https://workflowy.com/#/%s\n", nodeID)
+	url := "https://workflowy.com/#/" + nodeID
+	if quiet {
+		fmt.Println(url)
+		return nil
+	}
+	printInfo("Report uploaded successfully!\n")
+	fmt.Printf("URL: %s\n", url)
 	return nil
 }
 
-func outputReport(ctx context.Context, cmd *cli.Command, client workflowy.Client, report reports.ReportOutput, output io.Writer) error {
+func outputReport(ctx context.Context, cmd *cli.Command, client workflowy.Client, report reports.ReportOutput, source workflowy.SnapshotMeta, output io.Writer) error {
 	if cmd.Bool("upload") {
 		return uploadReport(ctx, cmd, client, report)
 	}
@@ -51,7 +58,7 @@ func outputReport(ctx context.Context, cmd *cli.Command, client workflowy.Client
 		if err != nil {
 			return err
 		}
-		printJSONToWriter(output, item)
+		printJSONToWriter(output, withDataSource(item, source))
 	} else {
 		preserveTags := cmd.Bool("preserve-tags")
 		return printReportToWriter(output, report, preserveTags)
@@ -60,20 +67,24 @@ func outputReport(ctx context.Context, cmd *cli.Command, client workflowy.Client
 	return nil
 }
 
-func loadTree(ctx context.Context, cmd *cli.Command, client workflowy.Client) ([]*workflowy.Item, error) {
-	return loadTreeWithBackupProvider(ctx, cmd, client, workflowy.DefaultBackupProvider)
+// loadTree loads the whole tree, and returns the source that served it.
+func loadTree(ctx context.Context, cmd *cli.Command, client workflowy.Client) ([]*workflowy.Item, workflowy.SnapshotMeta, error) {
+	snapshot, err := loadSnapshot(ctx, cmd, client, workflowy.DefaultBackupProvider)
+	if err != nil {
+		return nil, workflowy.SnapshotMeta{}, err
+	}
+	return snapshot.Items(), snapshot.Meta(), nil
 }
 
-func loadTreeWithBackupProvider(ctx context.Context, cmd *cli.Command, client workflowy.Client, backupProvider workflowy.BackupProvider) ([]*workflowy.Item, error) {
-	var items []*workflowy.Item
-
+// loadSnapshot loads the whole tree once, from the export API or a backup, so
+// that everything computed from it reflects the same point in time.
+func loadSnapshot(ctx context.Context, cmd *cli.Command, client workflowy.Client, backupProvider workflowy.BackupProvider) (*workflowy.Snapshot, error) {
 	method := cmd.String("method")
 	backupFile := cmd.String("backup-file")
 
-	if method != "" && method != "export" && method != "backup" {
-		return nil, fmt.Errorf("method must be 'export' or 'backup'")
+	if method != "" && method != "export" && method != "backup" && method != "session" && method != "cache" {
+		return nil, fmt.Errorf("method must be 'export', 'backup', 'session' or 'cache'")
 	}
-
 	useMethod := method
 	if useMethod == "" {
 		if client == nil {
@@ -87,29 +98,72 @@ func loadTreeWithBackupProvider(ctx context.Context, cmd *cli.Command, client wo
 		return nil, fmt.Errorf("cannot use 'export' without an API client")
 	}
 
-	if useMethod == "backup" {
-		return loadFromBackupProvider(backupFile, backupProvider)
+	sources, err := readSources(cmd, useMethod, func(source string) error {
+		if source == "get" {
+			return fmt.Errorf("the GET API cannot load the whole tree")
+		}
+		if client == nil && source == "export" {
+			return fmt.Errorf("no API client")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return readWithFallback(sources, func(source string) (*workflowy.Snapshot, error) {
+		switch source {
+		case "session":
+			return loadSessionSnapshot(ctx)
+		case "cache":
+			return workflowy.ReadTreeCacheSnapshot()
+		case "backup":
+			return loadBackupSnapshot(backupFile, backupProvider)
+		}
+		forceRefresh := cmd.Bool("force-refresh")
+		slog.Debug("using export API", "force_refresh", forceRefresh)
+		response, err := client.ExportNodesWithCache(ctx, forceRefresh)
+		if err != nil {
+			return nil, fmt.Errorf("cannot export nodes: %w", err)
+		}
+		slog.Debug("reconstructing tree from export data")
+		return workflowy.NewExportSnapshot(response), nil
+	})
+}
 
-	forceRefresh := cmd.Bool("force-refresh")
-
-	slog.Debug("using export API", "force_refresh", forceRefresh)
-	response, err := client.ExportNodesWithCache(ctx, forceRefresh)
+// loadSessionSnapshot loads the tree through the private API (--method=session),
+// which has the mirror data of backups without their delay.
+func loadSessionSnapshot(ctx context.Context) (*workflowy.Snapshot, error) {
+	cookie, err := privateapi.LoadSessionCookie()
 	if err != nil {
-		if method == "" {
-			slog.Warn("export failed, falling back to backup", "error", err)
-			return loadFromBackupProvider(backupFile, backupProvider)
-		}
-		return nil, fmt.Errorf("cannot export nodes: %w", err)
+		return nil, err
 	}
+	items, err := privateapi.NewClient(cookie).Tree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return workflowy.NewSnapshot(items, "session", time.Now()), nil
+}
 
-	slog.Debug("reconstructing tree from export data")
-	root := workflowy.BuildTreeFromExport(response.Nodes)
-	items = root.Children
+// checkMirrorMethod refuses access methods without mirror data, which only
+// backups and the private API have.
+func checkMirrorMethod(method, command string) error {
+	if method != "" && method != "backup" && method != "session" {
+		return fmt.Errorf("%s requires --method=backup or --method=session (mirror data is only available in backup files and the private API)", command)
+	}
+	return nil
+}
 
-	return items, nil
+func loadBackupSnapshot(backupFile string, provider workflowy.BackupProvider) (*workflowy.Snapshot, error) {
+	items, err := loadFromBackupProvider(backupFile, provider)
+	if err != nil {
+		return nil, err
+	}
+	return workflowy.NewBackupSnapshot(items), nil
 }
 
+// maxBackupAge is the --max-backup-age past which backups are refused (0 for no limit)
+var maxBackupAge time.Duration
+
 func loadFromBackupProvider(backupFile string, provider workflowy.BackupProvider) ([]*workflowy.Item, error) {
 	if backupFile != "" {
 		slog.Debug("using backup file", "file", backupFile)
@@ -117,60 +171,62 @@ func loadFromBackupProvider(backupFile string, provider workflowy.BackupProvider
 		slog.Debug("using latest backup file")
 	}
 
-	var items []*workflowy.Item
-	var err error
-	if backupFile != "" {
-		items, err = provider.ReadBackupFile(backupFile)
-	} else {
-		items, err = provider.ReadLatestBackup()
-	}
+	items, err := workflowy.ReadCheckedBackup(provider, backupFile, maxBackupAge, time.Now())
 	if err != nil {
 		return nil, fmt.Errorf("cannot read backup file: %w", err)
 	}
 	return items, nil
 }
 
-func loadAndCountDescendants(ctx context.Context, cmd *cli.Command, client workflowy.Client) (workflowy.Descendants, error) {
+// loadAndCountDescendants counts the descendants of the report root in a
+// snapshot of the tree, returning the snapshot with the counts.
+func loadAndCountDescendants(ctx context.Context, cmd *cli.Command, client workflowy.Client) (workflowy.Descendants, *workflowy.Snapshot, error) {
 	return loadAndCountDescendantsWithBackupProvider(ctx, cmd, client, workflowy.DefaultBackupProvider)
 }
 
-func loadAndCountDescendantsWithBackupProvider(ctx context.Context, cmd *cli.Command, client workflowy.Client, backupProvider workflowy.BackupProvider) (workflowy.Descendants, error) {
+func loadAndCountDescendantsWithBackupProvider(ctx context.Context, cmd *cli.Command, client workflowy.Client, backupProvider workflowy.BackupProvider) (workflowy.Descendants, *workflowy.Snapshot, error) {
+	snapshot, rootItem, err := loadReportRoot(ctx, cmd, client, backupProvider, "report")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	threshold := cmd.Float64("threshold")
+	return workflowy.CountDescendants(rootItem, threshold), snapshot, nil
+}
+
+// loadReportRoot loads a snapshot and returns it with the report root: the
+// node given by --id, or the whole tree, with its descendants filtered by --completed.
+func loadReportRoot(ctx context.Context, cmd *cli.Command, client workflowy.Client, backupProvider workflowy.BackupProvider, operation string) (*workflowy.Snapshot, *workflowy.Item, error) {
+	completed := cmd.String("completed")
+	if err := workflowy.ValidateCompletedMode(completed); err != nil {
+		return nil, nil, err
+	}
+
 	readGuard, err := NewReadGuard(ctx, client, getReadRootID(cmd))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	items, err := loadTreeWithBackupProvider(ctx, cmd, client, backupProvider)
+	snapshot, err := loadSnapshot(ctx, cmd, client, backupProvider)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	rawID := readGuard.DefaultID(getID(cmd))
 	itemID, err := workflowy.ResolveNodeID(ctx, client, rawID)
 	if err != nil {
-		return nil, fmt.Errorf("cannot resolve item ID: %w", err)
+		return nil, nil, fmt.Errorf("cannot resolve item ID: %w", err)
 	}
 
-	if err := readGuard.ValidateTarget(itemID, "report"); err != nil {
-		return nil, err
+	if err := readGuard.ValidateTarget(itemID, operation); err != nil {
+		return nil, nil, err
 	}
 
-	var rootItem *workflowy.Item
-	if itemID == "None" && len(items) > 0 {
-		rootItem = &workflowy.Item{
-			ID:       "root",
-			Name:     "Root",
-			Children: items,
-		}
-	} else {
-		rootItem = findItemByID(items, itemID)
-		if rootItem == nil {
-			return nil, fmt.Errorf("item with ID %s not found", itemID)
-		}
+	rootItem, err := snapshot.Root(itemID)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	threshold := cmd.Float64("threshold")
-	return workflowy.CountDescendants(rootItem, threshold), nil
+	return snapshot, workflowy.FilterCompletedItem(rootItem, completed), nil
 }
 
 func findItemByID(items []*workflowy.Item, id string) *workflowy.Item {
@@ -192,6 +248,9 @@ func printReportToWriter(w io.Writer, report reports.ReportOutput, preserveTags
 		title = stripHTMLTags(title)
 	}
 	fmt.Fprintf(w, "# %s\n\n", title)
+	if item.Note != nil && *item.Note != "" {
+		fmt.Fprintf(w, "%s\n\n", *item.Note)
+	}
 
 	for _, child := range item.Children {
 		printReportItem(w, child, 0, preserveTags)
@@ -233,50 +292,49 @@ func DefaultReportDeps() ReportDeps {
 
 func countReportAction(deps ReportDeps) func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
 	return func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
-		readGuard, err := NewReadGuard(ctx, client, getReadRootID(cmd))
-		if err != nil {
-			return err
-		}
-
-		items, err := loadTreeWithBackupProvider(ctx, cmd, client, deps.BackupProvider)
+		snapshot, rootItem, err := loadReportRoot(ctx, cmd, client, deps.BackupProvider, "report count")
 		if err != nil {
 			return err
 		}
 
-		rawID := readGuard.DefaultID(getID(cmd))
-		itemID, err := workflowy.ResolveNodeID(ctx, client, rawID)
-		if err != nil {
-			return fmt.Errorf("cannot resolve item ID: %w", err)
-		}
-
-		if err := readGuard.ValidateTarget(itemID, "report count"); err != nil {
-			return err
-		}
+		threshold := cmd.Float64("threshold")
+		slog.Debug("counting descendants", "threshold", threshold)
+		descendants := workflowy.CountDescendants(rootItem, threshold)
 
-		var rootItem *workflowy.Item
-		if itemID == "None" && len(items) > 0 {
-			rootItem = &workflowy.Item{
-				ID:       "root",
-				Name:     "Root",
-				Children: items,
+		if compareFile := cmd.String("compare"); compareFile != "" {
+			// the earlier backup is expected to be old: it is not checked against --max-backup-age
+			previousItems, err := deps.BackupProvider.ReadBackupFile(compareFile)
+			if err != nil {
+				return fmt.Errorf("cannot load snapshot to compare: %w", err)
 			}
-		} else {
-			rootItem = findItemByID(items, itemID)
-			if rootItem == nil {
-				return fmt.Errorf("item with ID %s not found", itemID)
+			previous := workflowy.NewBackupSnapshot(previousItems)
+			previousID := rootItem.ID
+			if previousID == "root" {
+				previousID = "None"
+			}
+			previousRoot, err := previous.Root(previousID)
+			if err != nil {
+				return fmt.Errorf("cannot find report root in the snapshot to compare: %w", err)
 			}
-		}
 
-		threshold := cmd.Float64("threshold")
-		slog.Debug("counting descendants", "threshold", threshold)
-		descendants := workflowy.CountDescendants(rootItem, threshold)
+			report := &reports.CountComparisonReportOutput{
+				RootItem:         rootItem,
+				Descendants:      descendants,
+				Previous:         workflowy.DescendantCounts(workflowy.CountDescendants(workflowy.FilterCompletedItem(previousRoot, cmd.String("completed")), 0)),
+				Threshold:        threshold,
+				Snapshot:         snapshot.Meta(),
+				PreviousSnapshot: previous.Meta(),
+			}
+			return outputReport(ctx, cmd, client, report, snapshot.Meta(), deps.Output)
+		}
 
 		report := &reports.CountReportOutput{
 			RootItem:    rootItem,
 			Descendants: descendants,
 			Threshold:   threshold,
+			Snapshot:    snapshot.Meta(),
 		}
 
-		return outputReport(ctx, cmd, client, report, deps.Output)
+		return outputReport(ctx, cmd, client, report, snapshot.Meta(), deps.Output)
 	}
 }
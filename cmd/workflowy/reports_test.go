@@ -13,7 +13,7 @@ func TestLoadTree_NilClient_AutoFallbackToBackup(t *testing.T) {
 	cmd := &cli.Command{
 		Flags: getMethodFlags(),
 		Action: func(ctx context.Context, c *cli.Command) error {
-			_, err := loadTree(ctx, c, nil)
+			_, _, err := loadTree(ctx, c, nil)
 			assert.Error(t, err)
 			assert.Contains(t, err.Error(), "cannot read backup file")
 			return nil
@@ -27,7 +27,7 @@ func TestLoadTree_NilClient_ExplicitExportMethod_ReturnsError(t *testing.T) {
 	cmd := &cli.Command{
 		Flags: getMethodFlags(),
 		Action: func(ctx context.Context, c *cli.Command) error {
-			_, err := loadTree(ctx, c, nil)
+			_, _, err := loadTree(ctx, c, nil)
 			assert.Error(t, err)
 			assert.Contains(t, err.Error(), "cannot use 'export' without an API client")
 			return nil
@@ -41,7 +41,7 @@ func TestLoadTree_NilClient_ExplicitBackupMethod_AttemptsBackup(t *testing.T) {
 	cmd := &cli.Command{
 		Flags: getMethodFlags(),
 		Action: func(ctx context.Context, c *cli.Command) error {
-			_, err := loadTree(ctx, c, nil)
+			_, _, err := loadTree(ctx, c, nil)
 			assert.Error(t, err)
 			assert.Contains(t, err.Error(), "cannot read backup file")
 			return nil
@@ -51,6 +51,22 @@ func TestLoadTree_NilClient_ExplicitBackupMethod_AttemptsBackup(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestLoadTree_SessionMethod_RequiresCookie(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("WORKFLOWY_SESSION_COOKIE", "")
+	cmd := &cli.Command{
+		Flags: getMethodFlags(),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			_, _, err := loadTree(ctx, c, nil)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "no session cookie")
+			return nil
+		},
+	}
+	err := cmd.Run(context.Background(), []string{"test", "--method=session"})
+	assert.NoError(t, err)
+}
+
 func TestUploadReport_NilClient_ReturnsError(t *testing.T) {
 	cmd := &cli.Command{
 		Flags: getReportFlags(),
@@ -1,17 +1,102 @@
 package main
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/mholzen/workflowy/pkg/filters"
 	"github.com/mholzen/workflowy/pkg/search"
 	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
 )
 
 type SearchResult = search.Result
 type MatchPosition = search.MatchPosition
 
+// loadBreadcrumbTree loads the whole tree for --breadcrumb on commands that
+// fetch a single subtree, which does not hold the ancestors of its root.
+func loadBreadcrumbTree(ctx context.Context, cmd *cli.Command, client workflowy.Client) ([]*workflowy.Item, error) {
+	if cmd.String("method") == "get" {
+		return nil, fmt.Errorf("--breadcrumb needs the whole tree: use --method=export or --method=backup")
+	}
+	items, _, err := loadTree(ctx, cmd, client)
+	return items, err
+}
+
 func findRootItem(items []*workflowy.Item, itemID string) *workflowy.Item {
 	return workflowy.FindRootItem(items, itemID)
 }
 
-func searchItems(items []*workflowy.Item, pattern string, useRegexp, ignoreCase bool) []SearchResult {
-	return search.SearchItems(items, pattern, useRegexp, ignoreCase)
+// loadSavedSearch returns the saved search called name from the configuration directory.
+func loadSavedSearch(name string) (filters.Filter, error) {
+	saved, err := filters.LoadDefault()
+	if err != nil {
+		return filters.Filter{}, err
+	}
+	return filters.Find(saved, name)
+}
+
+// searchGroup is the results of search under one of several roots.
+type searchGroup struct {
+	ID      string         `json:"id"`
+	Name    string         `json:"name"`
+	Results []SearchResult `json:"results"`
+}
+
+// searchWithin resolves rawID and searches the items under it, or all of
+// items for the root.
+func searchWithin(ctx context.Context, client workflowy.Client, readGuard *ReadGuard, items []*workflowy.Item, filter filters.Filter, rawID string) (*searchGroup, error) {
+	itemID, err := workflowy.ResolveNodeID(ctx, client, readGuard.DefaultID(rawID))
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve ID %s: %w", rawID, err)
+	}
+	if err := readGuard.ValidateTarget(itemID, "search"); err != nil {
+		return nil, err
+	}
+
+	rootItem := findRootItem(items, itemID)
+	if rootItem == nil {
+		if itemID != "None" {
+			return nil, fmt.Errorf("item not found: %s", itemID)
+		}
+		return &searchGroup{ID: "root", Name: "Root", Results: filter.Search(items)}, nil
+	}
+	return &searchGroup{ID: rootItem.ID, Name: rootItem.Name, Results: filter.Search([]*workflowy.Item{rootItem})}, nil
+}
+
+// searchRoots searches several roots of one load of the tree, as list does
+// for several ids, and prints one group per root.
+func searchRoots(ctx context.Context, cmd *cli.Command, client workflowy.Client, readGuard *ReadGuard, items []*workflowy.Item, source workflowy.SnapshotMeta, filter filters.Filter, rawIDs []string) error {
+	format := cmd.String("format")
+	if format == "jsonl" {
+		return fmt.Errorf("--format=jsonl applies to a single id")
+	}
+
+	groups := make([]*searchGroup, 0, len(rawIDs))
+	for _, rawID := range rawIDs {
+		group, err := searchWithin(ctx, client, readGuard, items, filter, rawID)
+		if err != nil {
+			return err
+		}
+		if group.Results == nil {
+			group.Results = []SearchResult{}
+		}
+		if cmd.Bool("breadcrumb") {
+			workflowy.AddBreadcrumbs(group.Results, items)
+		}
+		groups = append(groups, group)
+	}
+
+	if format == "json" {
+		printJSON(withDataSource(map[string]any{"roots": groups}, source))
+		return nil
+	}
+	for i, group := range groups {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("## %s (%s)\n\n", group.Name, group.ID)
+		printOutput(group.Results, format, false)
+	}
+	return nil
 }
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mholzen/workflowy/pkg/selftest"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+func getSelftestCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "selftest",
+		Usage:     "Check the API key, permissions and connection with a scripted write cycle",
+		UsageText: "workflowy selftest --parent-id=<id> [options]",
+		Description: `Create a scratch node at the bottom of the parent node and, inside it, create,
+update, move, complete, uncomplete and delete nodes, reading each change back
+through the API. The scratch node is deleted at the end, whether the checks
+pass or not. Exits non-zero if any check fails.
+
+The self-test writes to your outline: point --parent-id at a node you do not
+mind being written to. Its writes are not recorded for undo.
+
+Examples:
+  workflowy selftest --parent-id=inbox
+  workflowy selftest --parent-id=3495d784 --format=json`,
+		Flags: []cli.Flag{
+			getAPIKeyFlag(),
+			&cli.StringFlag{
+				Name:  "parent-id",
+				Usage: "Node to create the scratch node in: UUID, short ID or target key (default: --write-root-id)",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+			if cmd.String("parent-id") == "" && !workflowy.IsWriteRestricted(getWriteRootID(cmd)) {
+				return fmt.Errorf("parent-id is required")
+			}
+			// the self-test cleans up after itself, so its writes are not journaled
+			client, err := createClient(cmd)
+			if err != nil {
+				return err
+			}
+			guard, err := NewWriteGuard(ctx, client, getWriteRootID(cmd))
+			if err != nil {
+				return err
+			}
+			parentID, err := workflowy.ResolveNodeIDToUUID(ctx, client, guard.DefaultParent(cmd.String("parent-id")))
+			if err != nil {
+				return fmt.Errorf("cannot resolve parent ID: %w", err)
+			}
+			if err := guard.ValidateParent(parentID, "selftest"); err != nil {
+				return err
+			}
+
+			report := selftest.Run(ctx, client, parentID)
+			if format == "json" {
+				printJSON(report)
+			} else {
+				for _, step := range report.Steps {
+					fmt.Println(step.String())
+				}
+			}
+			if !report.Passed {
+				return fmt.Errorf("%d of %d checks failed", report.Failed(), len(report.Steps))
+			}
+			if format != "json" {
+				printInfo("\nAll %d checks passed\n", len(report.Steps))
+			}
+			return nil
+		},
+	}
+}
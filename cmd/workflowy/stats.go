@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/usage"
+	"github.com/urfave/cli/v3"
+)
+
+func getStatsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "stats",
+		Usage: "Show local statistics",
+		Commands: []*cli.Command{
+			{
+				Name:      "usage",
+				Usage:     "Show how often each command and MCP tool was used",
+				UsageText: "workflowy stats usage [options]",
+				Description: `Show the commands and MCP tools used, most used first, with how many times
+they failed and how long they took.
+
+Statistics are only recorded with the global --usage-stats flag or
+WORKFLOWY_USAGE_STATS=true, in ~/.workflowy/usage.json. They never leave your
+machine.
+
+Examples:
+  export WORKFLOWY_USAGE_STATS=true
+  workflowy stats usage
+  workflowy stats usage --reset`,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "reset",
+						Usage: "Delete the statistics",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					format := cmd.String("format")
+					if err := validateFormat(format); err != nil {
+						return err
+					}
+					file, err := usage.OpenDefault()
+					if err != nil {
+						return err
+					}
+					if cmd.Bool("reset") {
+						if err := file.Reset(); err != nil {
+							return err
+						}
+						printInfo("Usage statistics deleted\n")
+						return nil
+					}
+
+					stats, err := file.Read()
+					if err != nil {
+						return err
+					}
+					rows := stats.Rows()
+					if format == "json" {
+						if rows == nil {
+							rows = []usage.Row{}
+						}
+						printJSON(rows)
+						return nil
+					}
+					if len(rows) == 0 {
+						printInfo("No usage recorded; enable it with --usage-stats or WORKFLOWY_USAGE_STATS=true\n")
+						return nil
+					}
+					fmt.Print(formatUsage(rows))
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// formatUsage lays out rows as a table.
+func formatUsage(rows []usage.Row) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tKIND\tUSES\tFAILED\tAVERAGE\tMAX\tLAST USED")
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\t%s\n",
+			row.Name, row.Kind, row.Count, row.Failures,
+			formatMS(row.AverageMS), formatMS(row.MaxMS),
+			time.Unix(row.Last, 0).Format("2006-01-02 15:04"))
+	}
+	w.Flush()
+	return sb.String()
+}
+
+func formatMS(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+// recordUsage adds the command run by root to the usage statistics.
+func recordUsage(root *cli.Command, start time.Time, err error) {
+	var names []string
+	for c := root; ; {
+		sub := c.Command(c.Args().First())
+		if sub == nil {
+			break
+		}
+		names = append(names, sub.Name)
+		c = sub
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	file, openErr := usage.OpenDefault()
+	if openErr == nil {
+		openErr = file.Record(usage.KindCommand, strings.Join(names, " "), start, time.Since(start), err != nil)
+	}
+	if openErr != nil {
+		slog.Warn("cannot record usage statistics", "error", openErr)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/markdown"
+	"github.com/mholzen/workflowy/pkg/outline"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+func getSyncCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "sync",
+		Usage:     "Synchronize a markdown file with a subtree",
+		UsageText: "workflowy sync <file.md> --id=<id> [options]",
+		Description: `Compares a markdown file with the children of --id and makes one side match
+the other:
+
+  push   update Workflowy from the file
+  pull   rewrite the file from Workflowy, as a nested list
+  both   whichever side changed last wins: the file's modification time is
+         compared to the most recent modification in the subtree (default)
+
+Names and completion ("- [x]" items) are compared; notes and layouts in
+Workflowy are left as they are. At each level, nodes are matched by name, then
+in order, so a renamed item is updated rather than deleted and recreated.
+Nodes created by push go at the bottom of their parent.
+
+Examples:
+  workflowy sync notes.md --id=3495d784 --dry-run
+  workflowy sync notes.md --id=3495d784 --direction=pull`,
+		Arguments: []cli.Argument{
+			&cli.StringArg{
+				Name:      "file",
+				UsageText: "<file.md>",
+			},
+		},
+		Flags: []cli.Flag{
+			getAPIKeyFlag(),
+			&cli.StringFlag{
+				Name:  "id",
+				Usage: "ID of the node whose children are synchronized: UUID, short ID or target key",
+			},
+			&cli.StringFlag{
+				Name:  "direction",
+				Value: "both",
+				Usage: "push (file to Workflowy), pull (Workflowy to file) or both (the newer side wins)",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Print the changes without applying them",
+			},
+			&cli.BoolFlag{
+				Name:  "force-refresh",
+				Usage: "Bypass the export cache",
+			},
+		},
+		Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+			direction := cmd.String("direction")
+			if direction != "push" && direction != "pull" && direction != "both" {
+				return fmt.Errorf("direction must be 'push', 'pull' or 'both'")
+			}
+			file := cmd.StringArg("file")
+			if file == "" {
+				return fmt.Errorf("file is required")
+			}
+
+			readGuard, err := NewReadGuard(ctx, client, getReadRootID(cmd))
+			if err != nil {
+				return err
+			}
+			guard, err := NewWriteGuard(ctx, client, getWriteRootID(cmd))
+			if err != nil {
+				return err
+			}
+			// without --id, sync the write or read root if one is set
+			rawID := readGuard.DefaultID(guard.DefaultParent(cmd.String("id")))
+			if rawID == "" || rawID == "None" {
+				return fmt.Errorf("--id is required: sync does not apply to the whole account")
+			}
+			rootID, err := workflowy.ResolveNodeID(ctx, client, rawID)
+			if err != nil {
+				return fmt.Errorf("cannot resolve ID: %w", err)
+			}
+			if err := readGuard.ValidateTarget(rootID, "sync"); err != nil {
+				return err
+			}
+
+			response, err := client.ExportNodesWithCache(ctx, cmd.Bool("force-refresh"))
+			if err != nil {
+				return fmt.Errorf("cannot export nodes: %w", err)
+			}
+			root, err := workflowy.NewExportSnapshot(response).Root(rootID)
+			if err != nil {
+				return err
+			}
+
+			nodes, modTime, err := readSyncFile(file)
+			if err != nil {
+				return err
+			}
+			if direction == "push" && modTime.IsZero() {
+				return fmt.Errorf("cannot push %s: file does not exist", file)
+			}
+			if direction == "both" {
+				direction = "pull"
+				if modTime.After(latestModification(root)) {
+					direction = "push"
+				}
+			}
+
+			changes := outline.Diff(nodes, root.Children, root.ID)
+			if changes == nil {
+				changes = []outline.Change{}
+			}
+			if direction == "pull" {
+				for i, change := range changes {
+					changes[i] = change.Reverse()
+				}
+			}
+			output := map[string]any{"direction": direction, "changes": changes}
+
+			if len(changes) == 0 || cmd.Bool("dry-run") {
+				if format == "json" {
+					printJSON(output)
+					return nil
+				}
+				printSyncChanges(changes, direction, file)
+				if len(changes) > 0 {
+					printInfo("\nDry run: %d change(s) would be applied\n", len(changes))
+				}
+				return nil
+			}
+
+			if direction == "pull" {
+				if err := os.WriteFile(file, []byte(markdown.FormatOutline(outline.FromItems(root.Children))), 0644); err != nil {
+					return fmt.Errorf("cannot write file: %w", err)
+				}
+				if format == "json" {
+					printJSON(output)
+					return nil
+				}
+				printSyncChanges(changes, direction, file)
+				return nil
+			}
+
+			if err := guard.ValidateSubtree(rootID, "sync"); err != nil {
+				return err
+			}
+			result := outline.Apply(ctx, client, changes)
+			var failure error
+			if result.Failed > 0 {
+				failure = partialFailure(result.Failed, len(changes), "changes")
+			}
+			if format == "json" {
+				output["result"] = result
+				printJSON(output)
+				return failure
+			}
+			printSyncChanges(changes, direction, file)
+			for _, msg := range result.Errors {
+				printInfo("%s\n", msg)
+			}
+			printInfo("applied %d of %d change(s)\n", result.Applied, len(changes))
+			return failure
+		}),
+	}
+}
+
+// readSyncFile parses the markdown file and returns its modification time.
+// A missing file is an empty outline that has never been modified.
+func readSyncFile(file string) ([]*outline.Node, time.Time, error) {
+	f, err := os.Open(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("cannot open file: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("cannot read file: %w", err)
+	}
+	nodes, err := markdown.Parse(f)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return nodes, info.ModTime(), nil
+}
+
+// latestModification returns the most recent modification of item or its descendants.
+func latestModification(item *workflowy.Item) time.Time {
+	latest := time.Unix(item.ModifiedAt, 0)
+	for _, child := range item.Children {
+		if modified := latestModification(child); modified.After(latest) {
+			latest = modified
+		}
+	}
+	return latest
+}
+
+func printSyncChanges(changes []outline.Change, direction, file string) {
+	if len(changes) == 0 {
+		printInfo("%s is in sync\n", file)
+		return
+	}
+	target := "Workflowy"
+	if direction == "pull" {
+		target = file
+	}
+	printInfo("%s: %d change(s) to %s\n", direction, len(changes), target)
+	for _, change := range changes {
+		fmt.Println(change.String())
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSyncFile(t *testing.T) {
+	dir := t.TempDir()
+
+	nodes, modTime, err := readSyncFile(filepath.Join(dir, "missing.md"))
+	require.NoError(t, err)
+	assert.Nil(t, nodes)
+	assert.True(t, modTime.IsZero(), "a missing file has never been modified")
+
+	file := filepath.Join(dir, "notes.md")
+	require.NoError(t, os.WriteFile(file, []byte("- Website\n  - [x] Write copy\n"), 0644))
+	nodes, modTime, err = readSyncFile(file)
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	assert.True(t, nodes[0].Children[0].Completed)
+	assert.False(t, modTime.IsZero())
+}
+
+func TestLatestModification(t *testing.T) {
+	root := &workflowy.Item{ModifiedAt: 100, Children: []*workflowy.Item{
+		{ModifiedAt: 50, Children: []*workflowy.Item{{ModifiedAt: 300}}},
+		{ModifiedAt: 200},
+	}}
+
+	assert.Equal(t, time.Unix(300, 0), latestModification(root))
+}
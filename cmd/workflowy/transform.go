@@ -7,6 +7,8 @@ import (
 	"os"
 	"strings"
 
+	"github.com/mholzen/workflowy/pkg/patch"
+	"github.com/mholzen/workflowy/pkg/secrets"
 	"github.com/mholzen/workflowy/pkg/transform"
 	"github.com/mholzen/workflowy/pkg/workflowy"
 	"github.com/urfave/cli/v3"
@@ -32,7 +34,9 @@ Examples:
   workflowy transform 1a2b3c split -s "\n"         # Split by newline
   workflowy transform 1a2b3c -x 'echo {} | tr a-z A-Z'
   workflowy transform 1a2b3c uppercase --as-child  # Insert as child, keep original
-  workflowy transform 1a2b3c uppercase --dry-run --depth 2`,
+  workflowy transform 1a2b3c capitalize --lang=tr  # "istanbul" → "İstanbul"
+  workflowy transform 1a2b3c uppercase --dry-run --depth 2
+  workflowy transform 1a2b3c scrub --note --all -i --write-undo=undo.json`,
 		Arguments: []cli.Argument{
 			&cli.StringArg{
 				Name:      "id",
@@ -90,6 +94,19 @@ func getTransformFlags() []cli.Flag {
 			Name:  "as-child",
 			Usage: "Insert result as child of source node instead of replacing",
 		},
+		getBreadcrumbFlag(),
+		&cli.StringFlag{
+			Name:  "lang",
+			Usage: "Language for case transforms as a BCP 47 tag, e.g. tr, nl, de (default: language-neutral)",
+		},
+		&cli.StringFlag{
+			Name:  "patterns-file",
+			Usage: "YAML file of custom secret detectors for the scrub transform (see report exposure)",
+		},
+		&cli.StringFlag{
+			Name:  "write-undo",
+			Usage: "Write a reverse patch of the applied changes to this file (apply it with 'workflowy apply')",
+		},
 	)
 	return flags
 }
@@ -110,16 +127,12 @@ func runTransform(ctx context.Context, cmd *cli.Command, client workflowy.Client
 		return fmt.Errorf("cannot resolve ID: %w", err)
 	}
 
-	// Validate write-root scope only if restriction is in effect
-	writeRootID := getWriteRootID(cmd)
-	if workflowy.IsWriteRestricted(writeRootID) {
-		guard, err := NewWriteGuard(ctx, client, writeRootID)
-		if err != nil {
-			return err
-		}
-		if err := guard.ValidateTarget(itemID, "transform"); err != nil {
-			return err
-		}
+	guard, err := NewWriteGuard(ctx, client, getWriteRootID(cmd))
+	if err != nil {
+		return err
+	}
+	if err := guard.ValidateTarget(itemID, "transform"); err != nil {
+		return err
 	}
 
 	// Calculate depth (--all overrides --depth)
@@ -147,11 +160,15 @@ func runTransform(ctx context.Context, cmd *cli.Command, client workflowy.Client
 
 	transformName := cmd.StringArg("transform_name")
 	execCmd := cmd.String("exec")
+	undoFile := cmd.String("write-undo")
 
 	// Handle split transform
 	if transformName == "split" {
+		if undoFile != "" {
+			return fmt.Errorf("--write-undo cannot be used with split")
+		}
 		separator := cmd.String("separator")
-		return runSplitTransform(ctx, cmd, client, searchRoot, separator, format)
+		return runSplitTransform(ctx, cmd, client, guard, searchRoot, separator, format)
 	}
 
 	// Handle exec (no transform_name required)
@@ -163,10 +180,23 @@ func runTransform(ctx context.Context, cmd *cli.Command, client workflowy.Client
 		return fmt.Errorf("transform name required (use a built-in, 'split', or --exec)")
 	}
 
-	t, err := transform.ResolveTransformer(transformName, execCmd)
+	t, err := transform.ResolveTransformerLang(transformName, execCmd, cmd.String("lang"))
 	if err != nil {
 		return err
 	}
+	if patternsFile := cmd.String("patterns-file"); patternsFile != "" {
+		if transformName != "scrub" {
+			return fmt.Errorf("--patterns-file only applies to the scrub transform")
+		}
+		custom, err := secrets.LoadDetectors(patternsFile)
+		if err != nil {
+			return err
+		}
+		t = transform.NewScrubber(secrets.Merge(secrets.DefaultDetectors(), custom))
+	}
+	if undoFile != "" && cmd.Bool("as-child") {
+		return fmt.Errorf("--write-undo cannot be used with --as-child")
+	}
 
 	opts := transform.Options{
 		Transformer: t,
@@ -179,12 +209,26 @@ func runTransform(ctx context.Context, cmd *cli.Command, client workflowy.Client
 
 	var results []transform.Result
 	transform.CollectTransformations(searchRoot, opts, 0, &results)
+	for i := range results {
+		if err := guard.ValidateTarget(results[i].ID, "transform"); err != nil {
+			results[i].Skipped = true
+			results[i].SkipReason = err.Error()
+		}
+	}
+
+	if cmd.Bool("breadcrumb") {
+		items, err := loadBreadcrumbTree(ctx, cmd, client)
+		if err != nil {
+			return err
+		}
+		workflowy.AddBreadcrumbs(results, items)
+	}
 
 	if len(results) == 0 {
 		if format == "json" {
 			fmt.Println("[]")
 		} else {
-			fmt.Println("No transformations to apply")
+			printInfo("No transformations to apply\n")
 		}
 		return nil
 	}
@@ -195,12 +239,21 @@ func runTransform(ctx context.Context, cmd *cli.Command, client workflowy.Client
 		} else {
 			transform.ApplyResultsWithOptions(ctx, client, results, opts.AsChild)
 		}
+		if undoFile != "" {
+			if err := patch.WriteFile(undoFile, transform.UndoPatch(results)); err != nil {
+				return err
+			}
+		}
 	}
 
-	return printTransformResults(results, format, opts.DryRun)
+	err = printTransformResults(results, format, opts.DryRun)
+	if undoFile != "" && !opts.DryRun && format != "json" {
+		printInfo("Undo patch written to %s\n", undoFile)
+	}
+	return err
 }
 
-func runSplitTransform(ctx context.Context, cmd *cli.Command, client workflowy.Client, searchRoot []*workflowy.Item, separator, format string) error {
+func runSplitTransform(ctx context.Context, cmd *cli.Command, client workflowy.Client, guard *WriteGuard, searchRoot []*workflowy.Item, separator, format string) error {
 	separator = transform.UnescapeSeparator(separator)
 
 	fields := transform.DetermineFields(cmd.Bool("name"), cmd.Bool("note"))
@@ -209,12 +262,18 @@ func runSplitTransform(ctx context.Context, cmd *cli.Command, client workflowy.C
 	var results []transform.SplitResult
 	// fetchItems already limited depth, process all fetched nodes
 	transform.CollectSplits(searchRoot, separator, fields, true, 0, -1, &results)
+	for i := range results {
+		if err := guard.ValidateTarget(results[i].ParentID, "split"); err != nil {
+			results[i].Skipped = true
+			results[i].SkipReason = err.Error()
+		}
+	}
 
 	if len(results) == 0 {
 		if format == "json" {
 			fmt.Println("[]")
 		} else {
-			fmt.Println("No nodes to split")
+			printInfo("No nodes to split\n")
 		}
 		return nil
 	}
@@ -227,9 +286,11 @@ func runSplitTransform(ctx context.Context, cmd *cli.Command, client workflowy.C
 }
 
 func printSplitResults(results []transform.SplitResult, format string, dryRun bool) error {
+	failure := failedResults(results, func(r transform.SplitResult) bool { return r.Failed }, "splits")
+
 	if format == "json" {
 		printJSON(results)
-		return nil
+		return failure
 	}
 
 	appliedCount := 0
@@ -247,16 +308,16 @@ func printSplitResults(results []transform.SplitResult, format string, dryRun bo
 	}
 
 	if dryRun {
-		fmt.Printf("\nDry run: %d node(s) would be split into %d children\n", len(results), totalChildren)
+		printInfo("\nDry run: %d node(s) would be split into %d children\n", len(results), totalChildren)
 		return nil
 	}
 
-	fmt.Printf("\nSplit %d node(s) into %d children", appliedCount, totalChildren)
+	printInfo("\nSplit %d node(s) into %d children", appliedCount, totalChildren)
 	if skippedCount > 0 {
-		fmt.Printf(", skipped %d", skippedCount)
+		printInfo(", skipped %d", skippedCount)
 	}
-	fmt.Println()
-	return nil
+	printInfo("\n")
+	return failure
 }
 
 func applyResultsInteractively(ctx context.Context, client workflowy.Client, results []transform.Result, asChild bool) {
@@ -272,8 +333,13 @@ func applyResultsInteractively(ctx context.Context, client workflowy.Client, res
 		if result.Skipped {
 			continue
 		}
+		if ctx.Err() != nil {
+			result.Skipped = true
+			result.SkipReason = "cancelled"
+			continue
+		}
 
-		fmt.Printf("%s %s (%s): \"%s\" → \"%s\"? [y/N/q] ",
+		fmt.Fprintf(os.Stderr, "%s %s (%s): \"%s\" → \"%s\"? [y/N/q] ",
 			action, result.ID, result.Field, result.Original, result.New)
 		response, err := reader.ReadString('\n')
 		if err != nil {
@@ -313,6 +379,7 @@ func applyResultsInteractively(ctx context.Context, client workflowy.Client, res
 			resp, err := client.CreateNode(ctx, req)
 			if err != nil {
 				result.Skipped = true
+				result.Failed = true
 				result.SkipReason = fmt.Sprintf("create child failed: %v", err)
 				continue
 			}
@@ -322,6 +389,7 @@ func applyResultsInteractively(ctx context.Context, client workflowy.Client, res
 			req := transform.BuildUpdateRequest(result)
 			if _, err := client.UpdateNode(ctx, result.ID, req); err != nil {
 				result.Skipped = true
+				result.Failed = true
 				result.SkipReason = fmt.Sprintf("update failed: %v", err)
 				continue
 			}
@@ -331,9 +399,11 @@ func applyResultsInteractively(ctx context.Context, client workflowy.Client, res
 }
 
 func printTransformResults(results []transform.Result, format string, dryRun bool) error {
+	failure := failedResults(results, func(r transform.Result) bool { return r.Failed }, "transformations")
+
 	if format == "json" {
 		printJSON(results)
-		return nil
+		return failure
 	}
 
 	appliedCount := 0
@@ -349,13 +419,13 @@ func printTransformResults(results []transform.Result, format string, dryRun boo
 	}
 
 	if dryRun {
-		fmt.Printf("\nDry run: %d transformation(s) would be applied\n", len(results))
+		printInfo("\nDry run: %d transformation(s) would be applied\n", len(results))
 	} else {
-		fmt.Printf("\nApplied %d transformation(s)", appliedCount)
+		printInfo("\nApplied %d transformation(s)", appliedCount)
 		if skippedCount > 0 {
-			fmt.Printf(", skipped %d", skippedCount)
+			printInfo(", skipped %d", skippedCount)
 		}
-		fmt.Println()
+		printInfo("\n")
 	}
-	return nil
+	return failure
 }
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+// softDelete moves itemID to the trash, creating the default trash if needed.
+func softDelete(ctx context.Context, cmd *cli.Command, client workflowy.Client, guard *WriteGuard, itemID string) error {
+	format := cmd.String("format")
+	trashID, created, err := workflowy.ResolveTrash(ctx, client, cmd.String("trash-id"), guard.DefaultParent("None"))
+	if err != nil {
+		return err
+	}
+	// a trash just created lies in the default parent, where writes are allowed
+	if !created {
+		if err := guard.ValidateParent(trashID, "delete"); err != nil {
+			return err
+		}
+	}
+
+	slog.Debug("moving node to trash", "item_id", itemID, "trash_id", trashID)
+	response, err := workflowy.MoveToTrash(ctx, client, itemID, trashID)
+	if err != nil {
+		return fmt.Errorf("cannot move node to trash: %w", err)
+	}
+
+	if format == "json" {
+		printJSON(map[string]string{"id": itemID, "trash_id": trashID, "status": response.Status})
+	} else {
+		printInfo("%s moved to trash %s\n", itemID, trashID)
+	}
+	return nil
+}
+
+// trashResult is a node of the trash, permanently deleted or not.
+type trashResult struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Deleted    bool   `json:"deleted"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	Failed     bool   `json:"failed,omitempty"`
+	SkipReason string `json:"skip_reason,omitempty"`
+}
+
+func (r trashResult) String() string {
+	switch {
+	case r.Skipped:
+		return fmt.Sprintf("%s: \"%s\" (skipped: %s)", r.ID, r.Name, r.SkipReason)
+	case r.Deleted:
+		return fmt.Sprintf("%s: \"%s\"", r.ID, r.Name)
+	}
+	return fmt.Sprintf("%s: \"%s\" (dry-run)", r.ID, r.Name)
+}
+
+func getTrashCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "trash",
+		Usage: "Manage the nodes soft-deleted with delete --soft",
+		Commands: []*cli.Command{
+			{
+				Name:      "empty",
+				Usage:     "Permanently delete the nodes in the trash",
+				UsageText: "workflowy trash empty [options]",
+				Description: `Permanently delete every child of the trash node, with its descendants. The
+trash node itself is kept. Nodes can be restored from the trash, until it is
+emptied, by moving them out of it.
+
+Examples:
+  workflowy trash empty --dry-run
+  workflowy trash empty
+  workflowy trash empty --trash-id=3495d784`,
+				Flags: []cli.Flag{
+					getAPIKeyFlag(),
+					getTrashIDFlag(),
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "List the nodes that would be deleted",
+					},
+				},
+				Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+					format := cmd.String("format")
+					if err := validateFormat(format); err != nil {
+						return err
+					}
+
+					guard, err := NewWriteGuard(ctx, client, getWriteRootID(cmd))
+					if err != nil {
+						return err
+					}
+					trashID, err := workflowy.FindTrash(ctx, client, cmd.String("trash-id"), guard.DefaultParent("None"))
+					if err != nil {
+						return err
+					}
+					if trashID == "" {
+						printInfo("No trash node found\n")
+						return nil
+					}
+					children, err := client.ListChildren(ctx, trashID)
+					if err != nil {
+						return fmt.Errorf("cannot list trash: %w", err)
+					}
+
+					results := make([]trashResult, 0, len(children.Items))
+					for _, child := range children.Items {
+						result := trashResult{ID: child.ID, Name: child.Name}
+						if err := guard.ValidateSubtree(child.ID, "empty trash"); err != nil {
+							result.Skipped = true
+							result.SkipReason = err.Error()
+						}
+						results = append(results, result)
+					}
+
+					dryRun := cmd.Bool("dry-run")
+					deleted, failed := 0, 0
+					for i := range results {
+						result := &results[i]
+						if dryRun || result.Skipped {
+							continue
+						}
+						if ctx.Err() != nil {
+							result.Skipped = true
+							result.SkipReason = "cancelled"
+							continue
+						}
+						if _, err := client.DeleteNode(ctx, result.ID); err != nil {
+							result.Skipped = true
+							result.Failed = true
+							result.SkipReason = fmt.Sprintf("delete failed: %v", err)
+							failed++
+							continue
+						}
+						result.Deleted = true
+						deleted++
+					}
+
+					if format == "json" {
+						printJSON(results)
+					} else {
+						for _, result := range results {
+							fmt.Println(result.String())
+						}
+						if len(results) == 0 {
+							printInfo("Trash is empty\n")
+						} else if dryRun {
+							printInfo("\nDry run: %d node(s) would be deleted\n", len(results))
+						} else {
+							printInfo("\nDeleted %d node(s)", deleted)
+							if skipped := len(results) - deleted; skipped > 0 {
+								printInfo(", skipped %d", skipped)
+							}
+							printInfo("\n")
+						}
+					}
+					if failed > 0 {
+						return partialFailure(failed, len(results), "deletes")
+					}
+					return nil
+				}),
+			},
+		},
+	}
+}
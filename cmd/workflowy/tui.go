@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mholzen/workflowy/pkg/tui"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+func getTuiCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "tui",
+		Usage:     "Browse the outline in the terminal",
+		UsageText: "workflowy tui [options]",
+		Description: `Loads the tree (export API, cache or backup) and shows it in the terminal,
+to browse without a network connection when read from a backup or the cache.
+
+Keys:
+  up, down, j, k          move; page up, page down, home (g) and end (G) too
+  right, l                expand the node, or move to its first child
+  left, h                 collapse the node, or move to its parent
+  space, tab              expand or collapse the node
+  /                       search names; n and N find the next and previous match
+  .                       show or hide completed nodes
+  enter                   quit and print the ID of the node
+  q, escape               quit
+
+The browser draws on the terminal, not the standard output, so it can be used
+within $(...). It is read-only.
+
+Examples:
+  workflowy tui
+  workflowy tui --method=backup
+  workflowy get $(workflowy tui --id=projects)`,
+		Flags: append([]cli.Flag{
+			getIdFlag("ID to browse (default: root)"),
+		}, getMethodFlags()...),
+		Action: withOptionalClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+			if cmd.String("method") == "get" {
+				return fmt.Errorf("cannot browse using the GET method")
+			}
+
+			tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+			if err != nil {
+				return fmt.Errorf("tui needs a terminal: %w", err)
+			}
+			defer tty.Close()
+
+			items, err := loadChildrenOfID(ctx, cmd, client, "browse")
+			if err != nil {
+				return err
+			}
+			item, err := tui.Run(tty, items)
+			if err != nil || item == nil {
+				return err
+			}
+
+			if format == "json" {
+				printJSON(map[string]string{"id": item.ID, "name": tui.Name(item)})
+				return nil
+			}
+			fmt.Println(item.ID)
+			return nil
+		}),
+	}
+}
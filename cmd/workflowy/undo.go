@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/journal"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/urfave/cli/v3"
+)
+
+func getUndoCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "undo",
+		Usage:     "Revert the writes of the last commands",
+		UsageText: "workflowy undo [options]",
+		Description: `Revert the writes made by the last command, or the last N with --last.
+
+Every command and MCP tool call that writes records its changes, with the
+values they replaced, in the journal (~/.workflowy/journal.jsonl). Undo applies
+the inverse operations through the API, newest first: created nodes are deleted,
+updated nodes get their previous name and note back, moved nodes return to their
+previous parent, completions are reversed and deleted nodes are recreated.
+
+Recreated nodes get new IDs. The API only places nodes first or last among their
+siblings, so moved and deleted nodes go back first if they were, last otherwise.
+Undone commands are not undone again; undo itself cannot be undone. Changes to
+nodes outside --write-root-id, or locked, are refused unless --force is given.
+
+Examples:
+  workflowy undo --dry-run
+  workflowy undo
+  workflowy undo --last 3`,
+		Flags: []cli.Flag{
+			getAPIKeyFlag(),
+			&cli.IntFlag{
+				Name:  "last",
+				Usage: "Number of commands to undo",
+				Value: 1,
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "List the changes that would be reverted",
+			},
+		},
+		Action: withClient(func(ctx context.Context, cmd *cli.Command, client workflowy.Client) error {
+			format := cmd.String("format")
+			if err := validateFormat(format); err != nil {
+				return err
+			}
+			last := int(cmd.Int("last"))
+			if last < 1 {
+				return fmt.Errorf("--last must be at least 1")
+			}
+
+			journaledClient, ok := client.(*journal.Client)
+			if !ok {
+				return fmt.Errorf("cannot undo: journal unavailable")
+			}
+			j, err := journal.OpenDefault()
+			if err != nil {
+				return err
+			}
+			changes, err := j.Read()
+			if err != nil {
+				return err
+			}
+			runs := journal.Runs(changes)
+			if len(runs) == 0 {
+				printInfo("Nothing to undo\n")
+				return nil
+			}
+			runs = runs[:min(last, len(runs))]
+
+			if cmd.Bool("dry-run") {
+				if format == "json" {
+					printJSON(runs)
+					return nil
+				}
+				for _, run := range runs {
+					fmt.Printf("%s (%s): %d change(s)\n", run.Command, formatRunTime(run.Time), len(run.Changes))
+					for i := len(run.Changes) - 1; i >= 0; i-- {
+						fmt.Printf("  %s\n", run.Changes[i])
+					}
+				}
+				printInfo("\nDry run: %d command(s) would be undone\n", len(runs))
+				return nil
+			}
+
+			guard, err := NewWriteGuard(ctx, client, getWriteRootID(cmd))
+			if err != nil {
+				return err
+			}
+
+			var results []*journal.UndoResult
+			failed, total := 0, 0
+			for _, run := range runs {
+				if ctx.Err() != nil {
+					break
+				}
+				result := journal.Undo(ctx, journaledClient, run, guard)
+				results = append(results, result)
+				failed += len(result.Errors)
+				total += result.Changes
+			}
+
+			if format == "json" {
+				printJSON(results)
+			} else {
+				for i, result := range results {
+					printInfo("Undid %s (%s): reverted %d of %d change(s)\n", result.Command, formatRunTime(runs[i].Time), result.Reverted, result.Changes)
+					for _, e := range result.Errors {
+						printInfo("  %s\n", e)
+					}
+				}
+			}
+			if failed > 0 {
+				return partialFailure(failed, total, "reverts")
+			}
+			return nil
+		}),
+	}
+}
+
+func formatRunTime(unix int64) string {
+	return time.Unix(unix, 0).Format("2006-01-02 15:04:05")
+}
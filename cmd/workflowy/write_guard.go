@@ -3,23 +3,58 @@ package main
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
+	"github.com/mholzen/workflowy/pkg/logging"
 	"github.com/mholzen/workflowy/pkg/workflowy"
 )
 
-// WriteGuard validates write operations against a root restriction
+// forceWrites is set by --force: writes ignore #locked nodes.
+var forceWrites bool
+
+// WriteGuard validates write operations against a root restriction and
+// refuses to modify nodes locked with #locked, unless forced.
 type WriteGuard struct {
 	client      workflowy.Client
 	writeRootID string
 	tree        []*workflowy.Item
+	locks       workflowy.Locks
+	force       bool
+	logger      *slog.Logger
 }
 
 // NewWriteGuard creates a guard that restricts writes to descendants of writeRootID.
-// If writeRootID is empty or "None", no restrictions are applied.
+// If writeRootID is empty or "None", no root restriction is applied. Unless
+// --force is set, locked nodes are found in the tree loaded for the write
+// root, or else in the cached export, so that writes do not export the whole
+// tree: nodes locked since the cache was written are missed.
 func NewWriteGuard(ctx context.Context, client workflowy.Client, writeRootID string) (*WriteGuard, error) {
+	guard, err := newWriteRootGuard(ctx, client, writeRootID)
+	if err != nil || guard.force {
+		return guard, err
+	}
+
+	if guard.tree != nil {
+		guard.locks = workflowy.FindLocks(guard.tree)
+		return guard, nil
+	}
+	resp, err := workflowy.ReadCachedExport()
+	if err != nil {
+		guard.logger.Debug("no cached export to find locked nodes", "error", err)
+		return guard, nil
+	}
+	guard.locks = workflowy.FindLocks(workflowy.BuildTreeFromExport(resp.Nodes).Children)
+	return guard, nil
+}
+
+// newWriteRootGuard creates a guard checking the write-root restriction only,
+// for commands that report it without writing.
+func newWriteRootGuard(ctx context.Context, client workflowy.Client, writeRootID string) (*WriteGuard, error) {
 	guard := &WriteGuard{
 		client:      client,
 		writeRootID: writeRootID,
+		force:       forceWrites,
+		logger:      logging.Logger(ctx),
 	}
 
 	if !workflowy.IsWriteRestricted(writeRootID) {
@@ -54,24 +89,54 @@ func (g *WriteGuard) IsRestricted() bool {
 	return workflowy.IsWriteRestricted(g.writeRootID)
 }
 
-// ValidateTarget checks if targetID is within the write-root scope
+// ValidateTarget checks if targetID is within the write-root scope and not locked
 func (g *WriteGuard) ValidateTarget(targetID, operation string) error {
-	if !g.IsRestricted() {
-		return nil
+	if g.IsRestricted() {
+		err := workflowy.ValidateWriteAccess(g.tree, g.writeRootID, targetID, operation)
+		g.logger.Debug("write guard check", "target_id", targetID, "operation", operation, "allowed", err == nil)
+		if err != nil {
+			return err
+		}
 	}
-	return workflowy.ValidateWriteAccess(g.tree, g.writeRootID, targetID, operation)
+	return g.checkLock(g.locks.Check(targetID, operation))
 }
 
-// ValidateParent checks if parentID is within the write-root scope (for create/move)
+// ValidateSubtree checks, in addition to ValidateTarget, that targetID has no
+// locked descendant, for writes to the whole subtree such as delete.
+func (g *WriteGuard) ValidateSubtree(targetID, operation string) error {
+	if err := g.ValidateTarget(targetID, operation); err != nil {
+		return err
+	}
+	return g.checkLock(g.locks.CheckSubtree(targetID, operation))
+}
+
+// ValidateParent checks if parentID is within the write-root scope and not locked (for create/move)
 func (g *WriteGuard) ValidateParent(parentID, operation string) error {
 	if !g.IsRestricted() {
-		return nil
+		return g.checkLock(g.locks.Check(parentID, operation))
 	}
 	// For "None" parent (root level), deny if we have restrictions
 	if parentID == "None" || parentID == "" {
 		return fmt.Errorf("%s denied: cannot use root as parent when write-root-id is set to %s", operation, g.writeRootID)
 	}
-	return workflowy.ValidateWriteAccess(g.tree, g.writeRootID, parentID, operation)
+	err := workflowy.ValidateWriteAccess(g.tree, g.writeRootID, parentID, operation)
+	g.logger.Debug("write guard check", "parent_id", parentID, "operation", operation, "allowed", err == nil)
+	if err != nil {
+		return err
+	}
+	return g.checkLock(g.locks.Check(parentID, operation))
+}
+
+// checkLock returns err, a lock violation, unless writes are forced.
+func (g *WriteGuard) checkLock(err error) error {
+	if err == nil {
+		return nil
+	}
+	if g.force {
+		g.logger.Warn("writing to a locked node (--force)", "error", err)
+		return nil
+	}
+	return fmt.Errorf("%w (use --force to override)", err)
 }
 
 // DefaultParent returns the write-root-id if parentID is "None" and restrictions are in effect,
@@ -93,3 +158,27 @@ func (g *WriteGuard) WriteRootID() string {
 	}
 	return g.writeRootID
 }
+
+// WriteRootName returns the name of the write root, or empty string if not restricted.
+func (g *WriteGuard) WriteRootName() string {
+	if !g.IsRestricted() {
+		return ""
+	}
+	if item := workflowy.FindItemByID(g.tree, g.writeRootID); item != nil {
+		return item.Name
+	}
+	return ""
+}
+
+// restrictionRoot is a write or read root, as reported by targets.
+type restrictionRoot struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+func (r restrictionRoot) String() string {
+	if r.Name == "" {
+		return r.ID
+	}
+	return fmt.Sprintf("%s (%s)", r.Name, r.ID)
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteRootName(t *testing.T) {
+	tree := []*workflowy.Item{{ID: "inbox-id", Name: "Inbox"}}
+
+	guard := &WriteGuard{writeRootID: "inbox-id", tree: tree}
+	assert.Equal(t, "Inbox", guard.WriteRootName())
+	assert.Equal(t, "Inbox (inbox-id)", restrictionRoot{ID: guard.WriteRootID(), Name: guard.WriteRootName()}.String())
+
+	unrestricted := &WriteGuard{writeRootID: "None", tree: tree}
+	assert.Empty(t, unrestricted.WriteRootName())
+	assert.Equal(t, "inbox-id", restrictionRoot{ID: "inbox-id"}.String())
+}
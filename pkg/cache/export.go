@@ -2,20 +2,24 @@ package cache
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
-	"os"
-	"path/filepath"
 	"time"
+
+	"github.com/mholzen/workflowy/pkg/storage"
 )
 
 const (
-	// CacheExpiryDuration is how long the cache is valid (1 minute for rate limiting)
-	CacheExpiryDuration = 1 * time.Minute
-	// DefaultCacheFile is the default location for the export cache
-	DefaultCacheFile = ".workflowy/export-cache.json"
+	// DefaultCacheExpiryDuration is how long the cache is valid by default (1 minute for rate limiting)
+	DefaultCacheExpiryDuration = 1 * time.Minute
+	// DefaultCacheFile is the name of the export cache in the cache directory
+	DefaultCacheFile = "export-cache.json"
 )
 
+// CacheExpiryDuration is how long the cache is valid, set with --cache-ttl
+var CacheExpiryDuration = DefaultCacheExpiryDuration
+
 // ExportCache represents the cached export data with timestamp
 // Data is stored as raw JSON to avoid circular dependencies
 type ExportCache struct {
@@ -23,25 +27,26 @@ type ExportCache struct {
 	Data      json.RawMessage `json:"data"`
 }
 
-// GetCachePath returns the full path to the cache file
+// GetCachePath returns the location of the cache file in the storage
 func GetCachePath() (string, error) {
-	homeDir, err := os.UserHomeDir()
+	s, err := storage.Default()
 	if err != nil {
-		return "", fmt.Errorf("could not get home directory: %w", err)
+		return "", err
 	}
-	return filepath.Join(homeDir, DefaultCacheFile), nil
+	return s.Location("", DefaultCacheFile), nil
 }
 
 // ReadExportCache reads the cached export data if it exists and is valid
 func ReadExportCache() (*ExportCache, error) {
-	cachePath, err := GetCachePath()
+	s, err := storage.Default()
 	if err != nil {
 		return nil, err
 	}
+	cachePath := s.Location("", DefaultCacheFile)
 
-	data, err := os.ReadFile(cachePath)
+	data, err := s.Get("", DefaultCacheFile)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, storage.ErrNotFound) {
 			slog.Debug("cache file does not exist", "path", cachePath)
 			return nil, nil // No cache exists, not an error
 		}
@@ -60,16 +65,11 @@ func ReadExportCache() (*ExportCache, error) {
 // WriteExportCache writes the export data to cache with current timestamp
 // data should be any type that can be marshaled to JSON
 func WriteExportCache(data interface{}) error {
-	cachePath, err := GetCachePath()
+	s, err := storage.Default()
 	if err != nil {
 		return err
 	}
-
-	// Ensure cache directory exists
-	cacheDir := filepath.Dir(cachePath)
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		return fmt.Errorf("cannot create cache directory: %w", err)
-	}
+	cachePath := s.Location("", DefaultCacheFile)
 
 	// Marshal the data to JSON
 	dataJSON, err := json.Marshal(data)
@@ -87,7 +87,7 @@ func WriteExportCache(data interface{}) error {
 		return fmt.Errorf("cannot encode cache data: %w", err)
 	}
 
-	if err := os.WriteFile(cachePath, cacheData, 0644); err != nil {
+	if err := s.Put("", DefaultCacheFile, cacheData); err != nil {
 		return fmt.Errorf("cannot write cache file: %w", err)
 	}
 
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/storage"
+)
+
+const (
+	// TitleCacheExpiryDuration is how long a fetched page title is reused
+	TitleCacheExpiryDuration = 30 * 24 * time.Hour
+	// DefaultTitleCacheFile is the name of the page title cache in the cache directory
+	DefaultTitleCacheFile = "title-cache.json"
+)
+
+// TitleEntry is a cached page title with the time it was fetched
+type TitleEntry struct {
+	Title     string `json:"title"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// TitleCache stores page titles by URL, persisted as JSON
+type TitleCache struct {
+	mu      sync.Mutex
+	store   storage.Storage // nil if the cache is kept in memory only
+	key     string
+	entries map[string]TitleEntry
+}
+
+// NewTitleCache creates an empty cache persisted at path (or in memory only if path is empty)
+func NewTitleCache(path string) *TitleCache {
+	c := &TitleCache{entries: make(map[string]TitleEntry)}
+	if path != "" {
+		c.store, c.key = storage.NewFileStorage(filepath.Dir(path)), filepath.Base(path)
+	}
+	return c
+}
+
+// LoadTitleCache reads the title cache from the storage, or returns an empty one if it does not exist
+func LoadTitleCache() (*TitleCache, error) {
+	s, err := storage.Default()
+	if err != nil {
+		return nil, err
+	}
+	c := &TitleCache{store: s, key: DefaultTitleCacheFile, entries: make(map[string]TitleEntry)}
+
+	data, err := s.Get("", c.key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			slog.Debug("title cache file does not exist", "path", s.Location("", c.key))
+			return c, nil
+		}
+		return nil, fmt.Errorf("cannot read title cache file: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("cannot parse title cache file: %w", err)
+	}
+	return c, nil
+}
+
+// Get returns the cached title for url if present and not expired
+func (c *TitleCache) Get(url string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[url]
+	if !ok || time.Since(time.Unix(entry.Timestamp, 0)) >= TitleCacheExpiryDuration {
+		return "", false
+	}
+	return entry.Title, true
+}
+
+// Put stores the title for url and saves the cache file, with the titles
+// other processes saved since it was loaded
+func (c *TitleCache) Put(url, title string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[url] = TitleEntry{Title: title, Timestamp: time.Now().Unix()}
+	if c.store == nil {
+		return nil
+	}
+
+	err := storage.Update(c.store, "", c.key, func(data []byte) ([]byte, error) {
+		if data != nil {
+			var saved map[string]TitleEntry
+			if err := json.Unmarshal(data, &saved); err != nil {
+				return nil, fmt.Errorf("cannot parse title cache file: %w", err)
+			}
+			for savedURL, entry := range saved {
+				if entry.Timestamp > c.entries[savedURL].Timestamp {
+					c.entries[savedURL] = entry
+				}
+			}
+		}
+		data, err := json.MarshalIndent(c.entries, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode title cache: %w", err)
+		}
+		return data, nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot write title cache file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,364 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/storage"
+)
+
+// DefaultTreeCacheFile is the name of the full-tree cache in the cache directory
+const DefaultTreeCacheFile = "tree-cache.json"
+
+// TreeDiff counts the nodes a refresh of the tree cache added, changed and removed
+type TreeDiff struct {
+	Added   int `json:"added"`
+	Changed int `json:"changed"`
+	Removed int `json:"removed"`
+}
+
+// Empty reports whether the refresh found no change
+func (d TreeDiff) Empty() bool {
+	return d.Added == 0 && d.Changed == 0 && d.Removed == 0
+}
+
+// TreeCache is a persistent copy of every node of the outline, kept up to date
+// from exports. Unlike the export cache, it does not expire: it is read without
+// the API, offline, and refreshed by applying the differences with each new
+// export. Nodes are stored as raw JSON, by ID, to avoid circular dependencies.
+type TreeCache struct {
+	store       storage.Storage
+	key         string
+	RefreshedAt int64                      `json:"refreshed_at"`
+	Refreshes   int                        `json:"refreshes"`
+	LastDiff    TreeDiff                   `json:"last_diff"`
+	Nodes       map[string]json.RawMessage `json:"nodes"`
+	// Subtrees are the subtrees refreshed on their own since the last
+	// export, by root ID, with when they were refreshed, in Unix seconds
+	Subtrees map[string]int64 `json:"subtrees,omitempty"`
+}
+
+// NewTreeCache creates an empty tree cache persisted at path
+func NewTreeCache(path string) *TreeCache {
+	return newTreeCache(storage.NewFileStorage(filepath.Dir(path)), filepath.Base(path))
+}
+
+func newTreeCache(s storage.Storage, key string) *TreeCache {
+	return &TreeCache{store: s, key: key, Nodes: make(map[string]json.RawMessage)}
+}
+
+// GetTreeCachePath returns the location of the tree cache file in the storage
+func GetTreeCachePath() (string, error) {
+	s, err := storage.Default()
+	if err != nil {
+		return "", err
+	}
+	return s.Location("", DefaultTreeCacheFile), nil
+}
+
+// LoadTreeCache reads the tree cache from the storage, or returns an empty
+// one, never refreshed, if it does not exist
+func LoadTreeCache() (*TreeCache, error) {
+	s, err := storage.Default()
+	if err != nil {
+		return nil, err
+	}
+	return loadTreeCache(newTreeCache(s, DefaultTreeCacheFile))
+}
+
+// ReadTreeCache reads the tree cache at path, or returns an empty one if it does not exist
+func ReadTreeCache(path string) (*TreeCache, error) {
+	return loadTreeCache(NewTreeCache(path))
+}
+
+func loadTreeCache(c *TreeCache) (*TreeCache, error) {
+	data, err := c.store.Get("", c.key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			slog.Debug("tree cache file does not exist", "path", c.Path())
+			return c, nil
+		}
+		return nil, fmt.Errorf("cannot read tree cache file: %w", err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("cannot parse tree cache file: %w", err)
+	}
+	if c.Nodes == nil {
+		c.Nodes = make(map[string]json.RawMessage)
+	}
+	return c, nil
+}
+
+// Path returns the file the tree cache is persisted in
+func (c *TreeCache) Path() string {
+	return c.store.Location("", c.key)
+}
+
+// IsEmpty reports whether the tree cache was never refreshed
+func (c *TreeCache) IsEmpty() bool {
+	return c.RefreshedAt == 0
+}
+
+// Age returns how long ago the tree cache was refreshed
+func (c *TreeCache) Age() time.Duration {
+	if c.IsEmpty() {
+		return 0
+	}
+	return time.Since(time.Unix(c.RefreshedAt, 0))
+}
+
+// Refresh makes the cache hold exactly nodes, as of at, replacing only the
+// nodes that changed, and saves it. It returns the differences applied. The
+// cache is locked and read again first, so that the differences are those
+// with the latest refresh, whichever process made it; a cache refreshed by
+// another process with a more recent export is left as it is.
+func (c *TreeCache) Refresh(nodes map[string]json.RawMessage, at time.Time) (TreeDiff, error) {
+	var diff TreeDiff
+	err := c.update(func() (bool, error) {
+		if c.RefreshedAt > at.Unix() {
+			slog.Debug("tree cache refreshed since by a more recent export", "refreshed_at", c.RefreshedAt)
+			return false, nil
+		}
+		diff = c.apply(nodes, at)
+		return true, nil
+	})
+	if err != nil {
+		return TreeDiff{}, err
+	}
+	return diff, nil
+}
+
+// RefreshSubtree makes the descendants of rootID, down to depth levels, be
+// exactly nodes, as of at, and saves the cache. It returns the differences
+// applied. Cached nodes below depth are kept, unless their ancestor was
+// removed. Like Refresh, it reads the latest cache under a lock first; a
+// subtree refreshed since by another process is left as it is.
+func (c *TreeCache) RefreshSubtree(rootID string, nodes map[string]json.RawMessage, depth int, at time.Time) (TreeDiff, error) {
+	var diff TreeDiff
+	err := c.update(func() (bool, error) {
+		if c.IsEmpty() {
+			return false, fmt.Errorf("cannot refresh a subtree of an empty tree cache (run `workflowy cache refresh`)")
+		}
+		if c.Subtrees[rootID] > at.Unix() {
+			slog.Debug("subtree refreshed since by another process", "root_id", rootID, "refreshed_at", c.Subtrees[rootID])
+			return false, nil
+		}
+		diff = c.applySubtree(rootID, nodes, depth, at)
+		return true, nil
+	})
+	if err != nil {
+		return TreeDiff{}, err
+	}
+	return diff, nil
+}
+
+// update reads the latest cache into c, under the lock of the storage, and
+// saves it if change reports that it changed it.
+func (c *TreeCache) update(change func() (bool, error)) error {
+	var changeErr error
+	err := storage.Update(c.store, "", c.key, func(data []byte) ([]byte, error) {
+		latest := newTreeCache(c.store, c.key)
+		if data != nil {
+			if err := json.Unmarshal(data, latest); err != nil {
+				return nil, fmt.Errorf("cannot parse tree cache file: %w", err)
+			}
+			if latest.Nodes == nil {
+				latest.Nodes = make(map[string]json.RawMessage)
+			}
+		}
+		*c = *latest
+		changed, err := change()
+		if err != nil {
+			changeErr = err
+			return nil, err
+		}
+		if !changed {
+			return data, nil
+		}
+		encoded, err := json.Marshal(c)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode tree cache: %w", err)
+		}
+		return encoded, nil
+	})
+	if changeErr != nil {
+		return changeErr
+	}
+	if err != nil {
+		return fmt.Errorf("cannot write tree cache file: %w", err)
+	}
+	return nil
+}
+
+// apply replaces the nodes of the cache by nodes, as of at, and returns the
+// differences. The descendants of subtrees refreshed after at are kept as
+// they are, since nodes are older.
+func (c *TreeCache) apply(nodes map[string]json.RawMessage, at time.Time) TreeDiff {
+	newer := make(map[string]bool)
+	for id, refreshedAt := range c.Subtrees {
+		if refreshedAt > at.Unix() {
+			newer[id] = true
+		}
+	}
+	cached := descendants(c.Nodes, newer)
+	exported := descendants(nodes, newer)
+
+	var diff TreeDiff
+	for id, node := range nodes {
+		if cached[id] || exported[id] {
+			continue
+		}
+		previous, ok := c.Nodes[id]
+		switch {
+		case !ok:
+			diff.Added++
+		case !bytes.Equal(previous, node):
+			diff.Changed++
+		default:
+			continue
+		}
+		c.Nodes[id] = node
+	}
+	for id := range c.Nodes {
+		if _, ok := nodes[id]; !ok && !cached[id] {
+			delete(c.Nodes, id)
+			diff.Removed++
+		}
+	}
+
+	c.RefreshedAt = at.Unix()
+	c.Refreshes++
+	c.LastDiff = diff
+	for id, refreshedAt := range c.Subtrees {
+		if refreshedAt <= c.RefreshedAt {
+			delete(c.Subtrees, id)
+		}
+	}
+	slog.Debug("tree cache refreshed", "added", diff.Added, "changed", diff.Changed, "removed", diff.Removed)
+	return diff
+}
+
+// applySubtree replaces the descendants of rootID, down to depth levels, by
+// nodes, as of at, and returns the differences.
+func (c *TreeCache) applySubtree(rootID string, nodes map[string]json.RawMessage, depth int, at time.Time) TreeDiff {
+	var diff TreeDiff
+	children := make(map[string][]string)
+	for id, node := range c.Nodes {
+		parentID := parentOf(node)
+		children[parentID] = append(children[parentID], id)
+	}
+	var remove func(id string)
+	remove = func(id string) {
+		delete(c.Nodes, id)
+		diff.Removed++
+		for _, child := range children[id] {
+			remove(child)
+		}
+	}
+	var prune func(parentID string, level int)
+	prune = func(parentID string, level int) {
+		for _, id := range children[parentID] {
+			if _, ok := nodes[id]; !ok {
+				remove(id)
+			} else if level < depth {
+				prune(id, level+1)
+			}
+		}
+	}
+	prune(rootID, 1)
+
+	for id, node := range nodes {
+		previous, ok := c.Nodes[id]
+		switch {
+		case !ok:
+			diff.Added++
+		case !bytes.Equal(previous, node):
+			diff.Changed++
+		default:
+			continue
+		}
+		c.Nodes[id] = node
+	}
+
+	if c.Subtrees == nil {
+		c.Subtrees = make(map[string]int64)
+	}
+	c.Subtrees[rootID] = at.Unix()
+	slog.Debug("tree cache subtree refreshed", "root_id", rootID, "added", diff.Added, "changed", diff.Changed, "removed", diff.Removed)
+	return diff
+}
+
+// descendants returns the IDs of nodes with an ancestor among roots.
+func descendants(nodes map[string]json.RawMessage, roots map[string]bool) map[string]bool {
+	found := make(map[string]bool)
+	if len(roots) == 0 {
+		return found
+	}
+	checked := make(map[string]bool)
+	var under func(id string) bool
+	under = func(id string) bool {
+		if checked[id] {
+			return found[id]
+		}
+		checked[id] = true
+		node, ok := nodes[id]
+		if !ok {
+			return false
+		}
+		parentID := parentOf(node)
+		if roots[parentID] || under(parentID) {
+			found[id] = true
+		}
+		return found[id]
+	}
+	for id := range nodes {
+		under(id)
+	}
+	return found
+}
+
+// parentOf returns the parent ID of a cached node, "None" for a top-level one.
+func parentOf(node json.RawMessage) string {
+	var parent struct {
+		ParentID *string `json:"parent_id"`
+	}
+	if json.Unmarshal(node, &parent) != nil || parent.ParentID == nil || *parent.ParentID == "" {
+		return "None"
+	}
+	return *parent.ParentID
+}
+
+// Clear deletes the tree cache file and empties the cache
+func (c *TreeCache) Clear() error {
+	if err := c.store.Delete("", c.key); err != nil {
+		return fmt.Errorf("cannot delete tree cache file: %w", err)
+	}
+	*c = *newTreeCache(c.store, c.key)
+	return nil
+}
+
+// ClearTreeCache deletes the tree cache file of the storage
+func ClearTreeCache() error {
+	s, err := storage.Default()
+	if err != nil {
+		return err
+	}
+	return newTreeCache(s, DefaultTreeCacheFile).Clear()
+}
+
+// ClearExportCache deletes the export cache file, so that the next export is fetched from the API
+func ClearExportCache() error {
+	s, err := storage.Default()
+	if err != nil {
+		return err
+	}
+	if err := s.Delete("", DefaultCacheFile); err != nil {
+		return fmt.Errorf("cannot delete cache file: %w", err)
+	}
+	return nil
+}
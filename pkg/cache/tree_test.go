@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTreeCacheRefresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultTreeCacheFile)
+	c, err := ReadTreeCache(path)
+	require.NoError(t, err)
+	assert.True(t, c.IsEmpty())
+
+	diff, err := c.Refresh(map[string]json.RawMessage{
+		"a": json.RawMessage(`{"id":"a","name":"A"}`),
+		"b": json.RawMessage(`{"id":"b","name":"B"}`),
+	}, time.Unix(100, 0))
+	require.NoError(t, err)
+	assert.Equal(t, TreeDiff{Added: 2}, diff)
+
+	diff, err = c.Refresh(map[string]json.RawMessage{
+		"a": json.RawMessage(`{"id":"a","name":"A"}`),
+		"b": json.RawMessage(`{"id":"b","name":"B2"}`),
+		"c": json.RawMessage(`{"id":"c","name":"C"}`),
+	}, time.Unix(200, 0))
+	require.NoError(t, err)
+	assert.Equal(t, TreeDiff{Added: 1, Changed: 1}, diff)
+
+	diff, err = c.Refresh(map[string]json.RawMessage{
+		"b": json.RawMessage(`{"id":"b","name":"B2"}`),
+		"c": json.RawMessage(`{"id":"c","name":"C"}`),
+	}, time.Unix(300, 0))
+	require.NoError(t, err)
+	assert.Equal(t, TreeDiff{Removed: 1}, diff)
+
+	read, err := ReadTreeCache(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), read.RefreshedAt)
+	assert.Equal(t, 3, read.Refreshes)
+	assert.Equal(t, TreeDiff{Removed: 1}, read.LastDiff)
+	assert.Len(t, read.Nodes, 2)
+	assert.JSONEq(t, `{"id":"b","name":"B2"}`, string(read.Nodes["b"]))
+
+	require.NoError(t, read.Clear())
+	assert.True(t, read.IsEmpty())
+	read, err = ReadTreeCache(path)
+	require.NoError(t, err)
+	assert.True(t, read.IsEmpty())
+}
+
+func TestTreeCacheRefresh_ByAnotherProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultTreeCacheFile)
+	first, err := ReadTreeCache(path)
+	require.NoError(t, err)
+	second, err := ReadTreeCache(path)
+	require.NoError(t, err)
+
+	_, err = first.Refresh(map[string]json.RawMessage{"a": json.RawMessage(`{"id":"a"}`)}, time.Unix(200, 0))
+	require.NoError(t, err)
+
+	// an older export does not replace the newer one
+	diff, err := second.Refresh(map[string]json.RawMessage{"b": json.RawMessage(`{"id":"b"}`)}, time.Unix(100, 0))
+	require.NoError(t, err)
+	assert.True(t, diff.Empty())
+	assert.Contains(t, second.Nodes, "a")
+
+	// a newer one is compared with the latest refresh
+	diff, err = second.Refresh(map[string]json.RawMessage{"a": json.RawMessage(`{"id":"a"}`)}, time.Unix(300, 0))
+	require.NoError(t, err)
+	assert.True(t, diff.Empty())
+	assert.Equal(t, 2, second.Refreshes)
+}
+
+func TestTreeCacheRefreshSubtree(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultTreeCacheFile)
+	c, err := ReadTreeCache(path)
+	require.NoError(t, err)
+	_, err = c.RefreshSubtree("p", nil, 2, time.Unix(100, 0))
+	assert.ErrorContains(t, err, "empty tree cache")
+
+	_, err = c.Refresh(map[string]json.RawMessage{
+		"p":  json.RawMessage(`{"id":"p","parent_id":null}`),
+		"a":  json.RawMessage(`{"id":"a","parent_id":"p","name":"A"}`),
+		"a1": json.RawMessage(`{"id":"a1","parent_id":"a"}`),
+		"a2": json.RawMessage(`{"id":"a2","parent_id":"a1"}`),
+		"b":  json.RawMessage(`{"id":"b","parent_id":"p"}`),
+		"b1": json.RawMessage(`{"id":"b1","parent_id":"b"}`),
+		"o":  json.RawMessage(`{"id":"o","parent_id":null}`),
+	}, time.Unix(100, 0))
+	require.NoError(t, err)
+
+	diff, err := c.RefreshSubtree("p", map[string]json.RawMessage{
+		"a":  json.RawMessage(`{"id":"a","parent_id":"p","name":"A2"}`),
+		"a1": json.RawMessage(`{"id":"a1","parent_id":"a"}`),
+		"c":  json.RawMessage(`{"id":"c","parent_id":"p"}`),
+	}, 2, time.Unix(200, 0))
+	require.NoError(t, err)
+	assert.Equal(t, TreeDiff{Added: 1, Changed: 1, Removed: 2}, diff, "b is removed with its child")
+	assert.Contains(t, c.Nodes, "a2", "nodes below the depth fetched are kept")
+	assert.Contains(t, c.Nodes, "o", "nodes outside the subtree are kept")
+	assert.Equal(t, int64(100), c.RefreshedAt, "the export date is kept")
+
+	read, err := ReadTreeCache(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"p": 200}, read.Subtrees)
+	assert.JSONEq(t, `{"id":"a","parent_id":"p","name":"A2"}`, string(read.Nodes["a"]))
+
+	// an older refresh of the subtree is left out
+	diff, err = read.RefreshSubtree("p", nil, 2, time.Unix(150, 0))
+	require.NoError(t, err)
+	assert.True(t, diff.Empty())
+	assert.Contains(t, read.Nodes, "c")
+
+	// a newer export replaces the subtree too
+	_, err = read.Refresh(map[string]json.RawMessage{"o": json.RawMessage(`{"id":"o"}`)}, time.Unix(300, 0))
+	require.NoError(t, err)
+	assert.Empty(t, read.Subtrees)
+}
+
+func TestTreeCacheRefresh_OlderThanSubtree(t *testing.T) {
+	c, err := ReadTreeCache(filepath.Join(t.TempDir(), DefaultTreeCacheFile))
+	require.NoError(t, err)
+	_, err = c.Refresh(map[string]json.RawMessage{
+		"p": json.RawMessage(`{"id":"p","parent_id":null}`),
+		"a": json.RawMessage(`{"id":"a","parent_id":"p","name":"A"}`),
+		"b": json.RawMessage(`{"id":"b","parent_id":"p"}`),
+		"o": json.RawMessage(`{"id":"o","parent_id":null}`),
+	}, time.Unix(100, 0))
+	require.NoError(t, err)
+	_, err = c.RefreshSubtree("p", map[string]json.RawMessage{
+		"a": json.RawMessage(`{"id":"a","parent_id":"p","name":"A2"}`),
+		"c": json.RawMessage(`{"id":"c","parent_id":"p"}`),
+	}, 1, time.Unix(300, 0))
+	require.NoError(t, err)
+
+	// an export taken between the two keeps the newer subtree
+	diff, err := c.Refresh(map[string]json.RawMessage{
+		"p":  json.RawMessage(`{"id":"p","parent_id":null}`),
+		"a":  json.RawMessage(`{"id":"a","parent_id":"p","name":"A"}`),
+		"b":  json.RawMessage(`{"id":"b","parent_id":"p"}`),
+		"o":  json.RawMessage(`{"id":"o","parent_id":null,"name":"O"}`),
+		"o1": json.RawMessage(`{"id":"o1","parent_id":"o"}`),
+	}, time.Unix(200, 0))
+	require.NoError(t, err)
+	assert.Equal(t, TreeDiff{Added: 1, Changed: 1}, diff)
+	assert.JSONEq(t, `{"id":"a","parent_id":"p","name":"A2"}`, string(c.Nodes["a"]))
+	assert.Contains(t, c.Nodes, "c")
+	assert.NotContains(t, c.Nodes, "b", "nodes removed by the subtree refresh stay removed")
+	assert.Equal(t, map[string]int64{"p": 300}, c.Subtrees)
+}
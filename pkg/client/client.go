@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -15,6 +18,10 @@ type Client struct {
 	baseURL string
 	http    *http.Client
 	auth    func(r *http.Request) // injects auth headers
+
+	responses *responseCache // conditional GET cache; nil when disabled
+	limits    *rateLimits
+	retry     retryPolicy
 }
 
 // SetAuth allows setting the auth function after client creation
@@ -27,8 +34,11 @@ type Option func(*Client)
 func New(base string, opts ...Option) *Client {
 	c := &Client{
 		baseURL: strings.TrimRight(base, "/"),
-		http:    &http.Client{Timeout: 10 * time.Second}, // always set timeouts
+		http:    &http.Client{Timeout: DefaultTimeout, Transport: sharedTransport}, // always set timeouts
 		auth:    func(*http.Request) {},
+
+		responses: newResponseCache(),
+		limits:    &rateLimits{},
 	}
 	for _, opt := range opts {
 		opt(c)
@@ -37,50 +47,225 @@ func New(base string, opts ...Option) *Client {
 }
 
 func (c *Client) Do(ctx context.Context, method, path string, in any, out any) error {
-	u := c.baseURL + path
-
-	var body io.ReadWriter
 	// For GET requests, encode input as query parameters; otherwise use JSON body
 	if method == "GET" && in != nil {
 		// Query parameters will be handled by caller building the path
 		// So we just set in to nil to avoid JSON encoding
 		in = nil
 	}
+	var body []byte
 	if in != nil {
 		buf := new(bytes.Buffer)
 		if err := json.NewEncoder(buf).Encode(in); err != nil {
 			return fmt.Errorf("encode: %w", err)
 		}
-		body = buf
+		body = buf.Bytes()
+	}
+	return c.retry.do(ctx, method, path, func() error {
+		return c.do(ctx, method, path, body, out)
+	})
+}
+
+// do makes a single attempt at a request with an encoded JSON body, if any.
+func (c *Client) do(ctx context.Context, method, path string, body []byte, out any) error {
+	return c.send(ctx, method, path, body, out, method == "GET" && out != nil && c.responses != nil)
+}
+
+// send makes a request, revalidating the cached response of path if conditional.
+func (c *Client) send(ctx context.Context, method, path string, body []byte, out any, conditional bool) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
 	}
-	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Accept", "application/json")
-	if in != nil {
+	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
 	c.auth(req)
 
+	if conditional {
+		c.responses.setValidators(path, req)
+	}
+
+	if err := c.limits.wait(ctx); err != nil {
+		return err
+	}
+	start := time.Now()
 	resp, err := c.http.Do(req)
 	if err != nil {
+		slog.DebugContext(ctx, "api request failed", "method", method, "path", path, "error", err)
 		return err
 	}
-	defer resp.Body.Close()
+	defer closeBody(resp.Body)
+	c.limits.record(resp.Header)
+	slog.DebugContext(ctx, "api request", "method", method, "path", path, "status", resp.StatusCode, "duration_ms", time.Since(start).Milliseconds())
+
+	if conditional && resp.StatusCode == http.StatusNotModified {
+		if cached, ok := c.responses.get(path); ok {
+			slog.DebugContext(ctx, "response not modified, using cached body", "path", path)
+			return json.Unmarshal(cached, out)
+		}
+		// a write dropped the cached body while the request was in flight
+		slog.DebugContext(ctx, "response not modified but no longer cached, fetching again", "path", path)
+		closeBody(resp.Body)
+		return c.send(ctx, method, path, body, out, false)
+	}
 
 	if resp.StatusCode >= 400 {
 		b, _ := io.ReadAll(io.LimitReader(resp.Body, 8<<10))
 		// include Retry-After for backoff decisions
 		return &APIError{Status: resp.StatusCode, Body: string(b), RetryAfter: resp.Header.Get("Retry-After")}
 	}
+	// only a successful write can have changed the cached payloads
+	if method != "GET" && c.responses != nil {
+		c.responses.invalidate()
+	}
+	if conditional {
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, out); err != nil {
+			return err
+		}
+		c.responses.store(path, resp.Header, b)
+		return nil
+	}
 	if out != nil {
 		return json.NewDecoder(resp.Body).Decode(out)
 	}
 	return nil
 }
 
+// maxDrainBytes bounds how much of an unread body is discarded to keep its connection reusable
+const maxDrainBytes = 64 << 10
+
+// closeBody drains what is left of body so the connection returns to the idle pool.
+func closeBody(body io.ReadCloser) {
+	io.Copy(io.Discard, io.LimitReader(body, maxDrainBytes))
+	body.Close()
+}
+
+// ErrIncompleteBody is returned when a response body ends before its declared length
+var ErrIncompleteBody = errors.New("incomplete response body")
+
+// maxResumeAttempts limits how many times a truncated download is resumed
+const maxResumeAttempts = 3
+
+// Get fetches path and returns the raw response body. The body is checked against
+// its declared length; a download cut short is resumed with Range requests when
+// the server accepts them. If the body still cannot be completed, the bytes
+// received so far are returned along with the error. Requests failing with a
+// transient status are retried as configured by WithRetry.
+func (c *Client) Get(ctx context.Context, path string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	for attempt := 0; attempt <= maxResumeAttempts; attempt++ {
+		var resumable bool
+		err = c.retry.do(ctx, "GET", path, func() error {
+			var rangeErr error
+			resumable, rangeErr = c.getRange(ctx, path, &buf)
+			return rangeErr
+		})
+		if err == nil {
+			return buf.Bytes(), nil
+		}
+		if !resumable || ctx.Err() != nil {
+			break
+		}
+		slog.WarnContext(ctx, "response body incomplete, resuming download", "path", path, "received", buf.Len(), "error", err)
+	}
+	return buf.Bytes(), err
+}
+
+// getRange requests path from the end of buf and appends the body to buf. It
+// reports whether a failed download can be resumed.
+func (c *Client) getRange(ctx context.Context, path string, buf *bytes.Buffer) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	offset := int64(buf.Len())
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	c.auth(req)
+
+	if err := c.limits.wait(ctx); err != nil {
+		return false, err
+	}
+	start := time.Now()
+	resp, err := c.http.Do(req)
+	if err != nil {
+		slog.DebugContext(ctx, "api request failed", "method", "GET", "path", path, "error", err)
+		// a resumed request that fails to connect may still succeed on retry
+		return offset > 0, err
+	}
+	defer closeBody(resp.Body)
+	c.limits.record(resp.Header)
+	slog.DebugContext(ctx, "api request", "method", "GET", "path", path, "status", resp.StatusCode, "offset", offset, "duration_ms", time.Since(start).Milliseconds())
+
+	if resp.StatusCode >= 400 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 8<<10))
+		return false, &APIError{Status: resp.StatusCode, Body: string(b), RetryAfter: resp.Header.Get("Retry-After")}
+	}
+
+	total := resp.ContentLength
+	if resp.StatusCode == http.StatusPartialContent {
+		start, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return false, fmt.Errorf("unexpected content range %q for offset %d", resp.Header.Get("Content-Range"), offset)
+		}
+		total = size
+	} else if offset > 0 {
+		// the server ignored the range and sent the whole body again
+		buf.Reset()
+	}
+	resumable := resp.StatusCode == http.StatusPartialContent || resp.Header.Get("Accept-Ranges") == "bytes"
+
+	if _, err := io.Copy(buf, resp.Body); err != nil {
+		return resumable, fmt.Errorf("%w after %d bytes: %v", ErrIncompleteBody, buf.Len(), err)
+	}
+	if total >= 0 && int64(buf.Len()) != total {
+		return resumable, fmt.Errorf("%w: received %d of %d bytes", ErrIncompleteBody, buf.Len(), total)
+	}
+	return false, nil
+}
+
+// parseContentRange parses a "bytes start-end/size" header. size is -1 when unknown.
+func parseContentRange(header string) (start, size int64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rng, sizeStr, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, false
+	}
+	startStr, _, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if sizeStr == "*" {
+		return start, -1, true
+	}
+	size, err = strconv.ParseInt(sizeStr, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, size, true
+}
+
 type APIError struct {
 	Status     int
 	Body       string
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"net/http"
+	"sync"
+)
+
+// maxCachedResponses bounds the conditional response cache; it is cleared when full
+const maxCachedResponses = 1024
+
+// cachedResponse is a GET response body with the validators needed to revalidate it
+type cachedResponse struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+// responseCache stores GET responses by path so unchanged payloads can be
+// revalidated with If-None-Match / If-Modified-Since instead of transferred again.
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+}
+
+func newResponseCache() *responseCache {
+	return &responseCache{entries: make(map[string]cachedResponse)}
+}
+
+// setValidators adds conditional headers for path to req, reporting whether a cached body exists.
+func (rc *responseCache) setValidators(path string, req *http.Request) bool {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	entry, ok := rc.entries[path]
+	if !ok {
+		return false
+	}
+	if entry.etag != "" {
+		req.Header.Set("If-None-Match", entry.etag)
+	}
+	if entry.lastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.lastModified)
+	}
+	return true
+}
+
+func (rc *responseCache) get(path string) ([]byte, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	entry, ok := rc.entries[path]
+	return entry.body, ok
+}
+
+// store records body for path if the response carries a validator.
+func (rc *responseCache) store(path string, header http.Header, body []byte) {
+	entry := cachedResponse{
+		etag:         header.Get("ETag"),
+		lastModified: header.Get("Last-Modified"),
+		body:         body,
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if entry.etag == "" && entry.lastModified == "" {
+		delete(rc.entries, path)
+		return
+	}
+	if len(rc.entries) >= maxCachedResponses {
+		rc.entries = make(map[string]cachedResponse)
+	}
+	rc.entries[path] = entry
+}
+
+// invalidate drops every cached response. Called after successful writes,
+// since one edit can change the payload of many paths.
+func (rc *responseCache) invalidate() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	clear(rc.entries)
+}
+
+// WithoutConditionalCache disables conditional GET revalidation.
+func WithoutConditionalCache() Option {
+	return func(c *Client) {
+		c.responses = nil
+	}
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit is the request quota reported by the API in its last response.
+type RateLimit struct {
+	Limit      int       `json:"limit"`
+	Remaining  int       `json:"remaining"`
+	Reset      time.Time `json:"reset,omitzero"` // when the quota is replenished
+	ObservedAt time.Time `json:"observed_at"`
+}
+
+func (r RateLimit) String() string {
+	s := fmt.Sprintf("%d of %d requests remaining", r.Remaining, r.Limit)
+	if !r.Reset.IsZero() {
+		s += fmt.Sprintf(", resets in %s", time.Until(r.Reset).Round(time.Second))
+	}
+	return s
+}
+
+const (
+	// rateLimitReserve is the number of remaining requests at which new requests
+	// wait for the quota to reset rather than risk being rejected
+	rateLimitReserve = 1
+	// maxRateLimitWait bounds the preemptive wait; requests are sent anyway past it
+	maxRateLimitWait = time.Minute
+)
+
+// ParseRateLimit reads the rate-limit headers of a response, accepting both the
+// X-RateLimit-* convention (reset as a Unix time) and the IETF RateLimit-*
+// fields (reset in seconds). It returns false when the headers are absent.
+func ParseRateLimit(header http.Header, now time.Time) (RateLimit, bool) {
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		limit, errLimit := strconv.Atoi(header.Get(prefix + "Limit"))
+		remaining, errRemaining := strconv.Atoi(header.Get(prefix + "Remaining"))
+		if errLimit != nil || errRemaining != nil {
+			continue
+		}
+		r := RateLimit{Limit: limit, Remaining: remaining, ObservedAt: now}
+		if reset, err := strconv.ParseInt(header.Get(prefix+"Reset"), 10, 64); err == nil {
+			// values larger than a year of seconds are Unix times, smaller ones are deltas
+			if reset > 365*24*60*60 {
+				r.Reset = time.Unix(reset, 0)
+			} else {
+				r.Reset = now.Add(time.Duration(reset) * time.Second)
+			}
+		}
+		return r, true
+	}
+	return RateLimit{}, false
+}
+
+// rateLimits tracks the quota reported by the API across requests.
+type rateLimits struct {
+	mu    sync.Mutex
+	last  RateLimit
+	known bool
+}
+
+func (l *rateLimits) record(header http.Header) {
+	r, ok := ParseRateLimit(header, time.Now())
+	if !ok {
+		return
+	}
+	l.mu.Lock()
+	l.last, l.known = r, true
+	l.mu.Unlock()
+}
+
+// wait delays the caller until the quota resets when it is nearly exhausted.
+func (l *rateLimits) wait(ctx context.Context) error {
+	l.mu.Lock()
+	r, known := l.last, l.known
+	if known {
+		// count this request against the quota so concurrent callers see it
+		l.last.Remaining--
+	}
+	l.mu.Unlock()
+	if !known || r.Remaining > rateLimitReserve || r.Reset.IsZero() {
+		return nil
+	}
+	delay := time.Until(r.Reset)
+	if delay <= 0 || delay > maxRateLimitWait {
+		return nil
+	}
+	slog.WarnContext(ctx, "rate limit nearly exhausted, waiting for reset", "remaining", r.Remaining, "limit", r.Limit, "wait", delay.Round(time.Millisecond))
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RateLimit returns the quota reported by the last response, and false if the
+// API has not reported one yet.
+func (c *Client) RateLimit() (RateLimit, bool) {
+	c.limits.mu.Lock()
+	defer c.limits.mu.Unlock()
+	return c.limits.last, c.limits.known
+}
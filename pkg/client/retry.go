@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultRetries is the number of retries used by the CLI and MCP server
+	DefaultRetries = 3
+	// DefaultRetryDelay is the delay before the first retry; it doubles with each retry
+	DefaultRetryDelay = 500 * time.Millisecond
+	// maxRetryDelay caps the backoff delay between two attempts
+	maxRetryDelay = 30 * time.Second
+	// maxRetryAfter bounds how long a Retry-After header is honored; a request
+	// asked to wait longer fails instead
+	maxRetryAfter = time.Minute
+)
+
+// retryPolicy retries requests that fail with a transient status.
+// The zero value makes a single attempt.
+type retryPolicy struct {
+	max       int
+	baseDelay time.Duration
+}
+
+// WithRetry retries requests answered with 429 Too Many Requests, or a 5xx
+// status for idempotent methods, up to maxRetries times. A POST answered with
+// a 5xx is not retried: the API may have applied it, e.g. created the node,
+// before failing. Retries are delayed by an exponential backoff
+// from baseDelay with jitter, or by the Retry-After header when the API sends one.
+func WithRetry(maxRetries int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		c.retry = retryPolicy{max: max(maxRetries, 0), baseDelay: baseDelay}
+	}
+}
+
+// Retryable reports whether err is an API error worth retrying.
+func Retryable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Status == http.StatusTooManyRequests || apiErr.Status >= 500
+}
+
+// retryableFor returns the API error of err when it is worth retrying a
+// request with method: a 5xx only when the method is idempotent.
+func retryableFor(method string, err error) (*APIError, bool) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !Retryable(apiErr) {
+		return nil, false
+	}
+	if apiErr.Status != http.StatusTooManyRequests && !idempotent(method) {
+		return nil, false
+	}
+	return apiErr, true
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// do calls attempt until it succeeds, fails with an error that is not
+// retryable, or the retries are exhausted.
+func (p retryPolicy) do(ctx context.Context, method, path string, attempt func() error) error {
+	for retry := 0; ; retry++ {
+		err := attempt()
+		if err == nil || retry >= p.max {
+			return err
+		}
+		apiErr, ok := retryableFor(method, err)
+		if !ok {
+			return err
+		}
+		delay, ok := p.delay(retry, apiErr.RetryAfter, time.Now())
+		if !ok {
+			return err
+		}
+		slog.WarnContext(ctx, "api request failed, retrying", "method", method, "path", path, "error", err, "retry", retry+1, "wait", delay.Round(time.Millisecond))
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// delay returns how long to wait before the given retry (0 for the first).
+// It returns false when Retry-After asks for a longer wait than is honored.
+func (p retryPolicy) delay(retry int, retryAfter string, now time.Time) (time.Duration, bool) {
+	if wait, ok := ParseRetryAfter(retryAfter, now); ok {
+		return wait, wait <= maxRetryAfter
+	}
+	backoff := min(p.baseDelay<<retry, maxRetryDelay)
+	if backoff <= 0 {
+		// the shift overflowed
+		backoff = maxRetryDelay
+	}
+	// jitter between half and the full backoff, so concurrent clients spread out
+	return backoff/2 + rand.N(backoff/2+1), true
+}
+
+// ParseRetryAfter reads a Retry-After header, given either in seconds or as an
+// HTTP date. It returns false when the header is absent or malformed.
+func ParseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
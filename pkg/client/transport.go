@@ -0,0 +1,81 @@
+package client
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultTimeout bounds each request made by a Client
+const DefaultTimeout = 10 * time.Second
+
+// TransportConfig tunes connection reuse for API requests.
+type TransportConfig struct {
+	MaxIdleConns        int           // idle connections kept across all hosts
+	MaxIdleConnsPerHost int           // idle connections kept per host
+	MaxConnsPerHost     int           // concurrent connections per host (0 = unlimited)
+	IdleConnTimeout     time.Duration // how long an idle connection is kept
+	TLSSessionCacheSize int           // TLS sessions cached for resumption (0 = disabled)
+}
+
+// DefaultTransportConfig keeps enough idle connections for recursive fetches
+// against a single API host.
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		MaxIdleConns:        32,
+		MaxIdleConnsPerHost: 16,
+		MaxConnsPerHost:     16,
+		IdleConnTimeout:     90 * time.Second,
+		TLSSessionCacheSize: 64,
+	}
+}
+
+// NewTransport builds an HTTP/2-capable transport from cfg.
+func NewTransport(cfg TransportConfig) *http.Transport {
+	t := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if cfg.TLSSessionCacheSize > 0 {
+		t.TLSClientConfig = &tls.Config{
+			ClientSessionCache: tls.NewLRUClientSessionCache(cfg.TLSSessionCacheSize),
+		}
+	}
+	return t
+}
+
+// sharedTransport is used by every Client that does not configure its own, so
+// connections are reused across clients.
+var sharedTransport = NewTransport(DefaultTransportConfig())
+
+// WithTransportConfig gives the client its own transport built from cfg.
+func WithTransportConfig(cfg TransportConfig) Option {
+	return func(c *Client) {
+		c.http.Transport = NewTransport(cfg)
+	}
+}
+
+// WithHTTPClient replaces the underlying http.Client.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.http = hc
+	}
+}
+
+// WithTimeout sets the per-request timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.http.Timeout = timeout
+	}
+}
@@ -0,0 +1,156 @@
+// Package complete completes or uncompletes, in bulk, the nodes whose name
+// matches a pattern or that were not modified for some time.
+package complete
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// Result is a node to complete or uncomplete.
+type Result struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	ModifiedAt int64  `json:"modified_at,omitempty"`
+	URL        string `json:"url"`
+	Applied    bool   `json:"applied"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	Failed     bool   `json:"failed,omitempty"`
+	SkipReason string `json:"skip_reason,omitempty"`
+	Breadcrumb string `json:"breadcrumb,omitempty"`
+}
+
+func (r Result) String() string {
+	var s string
+	if r.Skipped {
+		s = fmt.Sprintf("%s: \"%s\" (skipped: %s)", r.ID, r.Name, r.SkipReason)
+	} else if r.Applied {
+		s = fmt.Sprintf("%s: \"%s\"", r.ID, r.Name)
+	} else {
+		s = fmt.Sprintf("%s: \"%s\" (dry-run)", r.ID, r.Name)
+	}
+	if r.Breadcrumb != "" {
+		s += fmt.Sprintf(" (in %s)", r.Breadcrumb)
+	}
+	return s
+}
+
+// Options selects the nodes to complete or uncomplete. A node must match
+// every criterion set.
+type Options struct {
+	Complete  bool           // complete the nodes, or uncomplete them
+	Pattern   *regexp.Regexp // matched against the name
+	OlderThan time.Duration  // minimum time since the last modification
+	Now       time.Time
+	Depth     int // -1 for unlimited
+}
+
+// ParseAge parses an age such as 90d, 2w or 36h: a number of days, weeks, or
+// any duration time.ParseDuration accepts.
+func ParseAge(age string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, ok := strings.CutSuffix(age, suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age %q: expected e.g. 90d, 2w or 36h", age)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(age)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q: expected e.g. 90d, 2w or 36h", age)
+	}
+	return d, nil
+}
+
+// Collect appends to results the nodes of items and their descendants,
+// down to opts.Depth, that match opts and are not already in the requested
+// state. Nodes without a modification time are not older than any age.
+func Collect(items []*workflowy.Item, opts Options, currentDepth int, results *[]Result) {
+	if opts.Depth >= 0 && currentDepth > opts.Depth {
+		return
+	}
+
+	for _, item := range items {
+		if matches(item, opts) {
+			*results = append(*results, Result{
+				ID:         item.ID,
+				Name:       item.Name,
+				ModifiedAt: item.ModifiedAt,
+				URL:        fmt.Sprintf("https://workflowy.com/#/%s", item.ID),
+			})
+		}
+		if len(item.Children) > 0 {
+			Collect(item.Children, opts, currentDepth+1, results)
+		}
+	}
+}
+
+func matches(item *workflowy.Item, opts Options) bool {
+	if item.IsCompleted() == opts.Complete {
+		return false
+	}
+	if opts.Pattern != nil && !opts.Pattern.MatchString(item.Name) {
+		return false
+	}
+	if opts.OlderThan > 0 {
+		if item.ModifiedAt == 0 || opts.Now.Sub(time.Unix(item.ModifiedAt, 0)) < opts.OlderThan {
+			return false
+		}
+	}
+	return true
+}
+
+// NodeID returns the ID of the node of the result.
+func (r *Result) NodeID() string {
+	return r.ID
+}
+
+// SetBreadcrumb sets the ancestors shown with the result (see workflowy.AddBreadcrumbs).
+func (r *Result) SetBreadcrumb(breadcrumb string) {
+	r.Breadcrumb = breadcrumb
+}
+
+// Completer is the subset of workflowy.Client needed to apply results.
+type Completer interface {
+	CompleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error)
+	UncompleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error)
+}
+
+// Apply completes or uncompletes the results not already skipped, in order,
+// and returns the number applied. A failed result is skipped and the others
+// are still applied; a cancelled context skips the remaining ones.
+func Apply(ctx context.Context, client Completer, results []Result, complete bool) int {
+	setCompletion := client.UncompleteNode
+	if complete {
+		setCompletion = client.CompleteNode
+	}
+	applied := 0
+	for i := range results {
+		result := &results[i]
+		if result.Skipped {
+			continue
+		}
+		if ctx.Err() != nil {
+			result.Skipped = true
+			result.SkipReason = "cancelled"
+			continue
+		}
+		if _, err := setCompletion(ctx, result.ID); err != nil {
+			result.Skipped = true
+			result.Failed = true
+			result.SkipReason = fmt.Sprintf("update failed: %v", err)
+			continue
+		}
+		result.Applied = true
+		applied++
+	}
+	return applied
+}
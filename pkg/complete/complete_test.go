@@ -0,0 +1,93 @@
+package complete
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAge(t *testing.T) {
+	for age, expected := range map[string]time.Duration{
+		"90d": 90 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+		"0d":  0,
+	} {
+		d, err := ParseAge(age)
+		require.NoError(t, err, age)
+		assert.Equal(t, expected, d, age)
+	}
+	for _, age := range []string{"", "d", "ninety", "-3d", "1.5d"} {
+		_, err := ParseAge(age)
+		assert.Error(t, err, age)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -100).Unix()
+	recent := now.AddDate(0, 0, -10).Unix()
+	completedAt := old
+	items := []*workflowy.Item{
+		{ID: "a", Name: "TODO plan", ModifiedAt: old, Children: []*workflowy.Item{
+			{ID: "a1", Name: "TODO book", ModifiedAt: recent},
+			{ID: "a2", Name: "TODO done", ModifiedAt: old, CompletedAt: &completedAt},
+			{ID: "a3", Name: "TODO undated"},
+		}},
+		{ID: "b", Name: "notes", ModifiedAt: old},
+	}
+
+	collect := func(opts Options) []string {
+		opts.Now = now
+		var results []Result
+		Collect(items, opts, 0, &results)
+		var ids []string
+		for _, result := range results {
+			ids = append(ids, result.ID)
+		}
+		return ids
+	}
+
+	pattern := regexp.MustCompile("^TODO")
+	assert.Equal(t, []string{"a", "a1", "a3"}, collect(Options{Complete: true, Pattern: pattern, Depth: -1}))
+	assert.Equal(t, []string{"a", "b"}, collect(Options{Complete: true, OlderThan: 90 * 24 * time.Hour, Depth: -1}))
+	assert.Equal(t, []string{"a"}, collect(Options{Complete: true, Pattern: pattern, OlderThan: 90 * 24 * time.Hour, Depth: -1}))
+	assert.Equal(t, []string{"a", "b"}, collect(Options{Complete: true, Depth: 0}))
+	assert.Equal(t, []string{"a2"}, collect(Options{Complete: false, Pattern: pattern, Depth: -1}))
+}
+
+type fakeCompleter struct {
+	completed []string
+	fail      string
+}
+
+func (f *fakeCompleter) CompleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error) {
+	if itemID == f.fail {
+		return nil, errors.New("boom")
+	}
+	f.completed = append(f.completed, itemID)
+	return &workflowy.UpdateNodeResponse{}, nil
+}
+
+func (f *fakeCompleter) UncompleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error) {
+	return nil, errors.New("unexpected uncomplete")
+}
+
+func TestApply(t *testing.T) {
+	client := &fakeCompleter{fail: "b"}
+	results := []Result{{ID: "a"}, {ID: "b"}, {ID: "c", Skipped: true, SkipReason: "locked"}, {ID: "d"}}
+
+	applied := Apply(context.Background(), client, results, true)
+
+	assert.Equal(t, 2, applied)
+	assert.Equal(t, []string{"a", "d"}, client.completed)
+	assert.True(t, results[1].Failed)
+	assert.Equal(t, "update failed: boom", results[1].SkipReason)
+	assert.False(t, results[2].Applied)
+}
@@ -0,0 +1,329 @@
+// Package config reads and writes the user settings in config.yaml in the
+// configuration directory:
+//
+//	backup_dir: ~/Dropbox/Apps/Workflowy/Data
+//	cache_dir: ~/.cache/workflowy
+//	storage: /data/workflowy
+//	api_key_file: ~/secrets/workflowy.key
+//	format: markdown
+//	depth: 3
+//	fallback: export,backup
+//	hot_subtrees: projects=30s,3495d784
+//	aliases: work=3495d784,site=/Projects/Website
+//
+// Each setting has an environment variable that takes precedence over it, and
+// command line flags take precedence over both.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/paths"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the configuration file in the configuration directory
+const FileName = "config.yaml"
+
+// Formats are the values of the format setting
+var Formats = []string{"list", "json", "markdown"}
+
+// FallbackSources are the sources of the fallback setting, tried in the order
+// given when the access method of a read fails
+var FallbackSources = []string{"get", "export", "cache", "backup"}
+
+// Intervals of the hot subtrees: the default, and the shortest allowed to
+// stay within the rate limits of the API
+const (
+	DefaultHotInterval = time.Minute
+	MinHotInterval     = 10 * time.Second
+)
+
+// Setting describes a key of the configuration file.
+type Setting struct {
+	Key         string `json:"key"`
+	Env         string `json:"env"` // environment variable overriding the file
+	Description string `json:"description"`
+}
+
+// Settings are the keys of the configuration file.
+var Settings = []Setting{
+	{Key: "backup_dir", Env: paths.BackupDirEnv, Description: "Directories searched for backups, separated by " + string(os.PathListSeparator)},
+	{Key: "cache_dir", Env: paths.CacheDirEnv, Description: "Directory holding caches and state files"},
+	{Key: "storage", Env: paths.StorageEnv, Description: "Where caches and state files are kept: a directory, or memory"},
+	{Key: "api_key_file", Env: "WORKFLOWY_API_KEY_FILE", Description: "File containing the API key"},
+	{Key: "format", Env: "WORKFLOWY_FORMAT", Description: "Default output format: list, json or markdown"},
+	{Key: "depth", Env: "WORKFLOWY_DEPTH", Description: "Default --depth of get and list"},
+	{Key: "fallback", Env: "WORKFLOWY_FALLBACK", Description: "Sources tried in order when a read fails: get, export, cache or backup, separated by commas, or none"},
+	{Key: "hot_subtrees", Env: "WORKFLOWY_HOT_SUBTREES", Description: "Subtrees `cache watch` refreshes with the GET API between exports: IDs or target keys, each with an optional =interval (default 1m), separated by commas"},
+	{Key: "aliases", Env: "WORKFLOWY_ALIASES", Description: "Names accepted wherever an ID is: name=ID pairs, the ID in any form a command accepts, separated by commas"},
+}
+
+// Find returns the setting of key.
+func Find(key string) (Setting, error) {
+	for _, setting := range Settings {
+		if setting.Key == key {
+			return setting, nil
+		}
+	}
+	keys := make([]string, len(Settings))
+	for i, setting := range Settings {
+		keys[i] = setting.Key
+	}
+	return Setting{}, fmt.Errorf("unknown setting %q (settings: %v)", key, keys)
+}
+
+// Config is the content of the configuration file. Empty values are unset.
+type Config struct {
+	BackupDir   string `yaml:"backup_dir,omitempty"`
+	CacheDir    string `yaml:"cache_dir,omitempty"`
+	Storage     string `yaml:"storage,omitempty"`
+	APIKeyFile  string `yaml:"api_key_file,omitempty"`
+	Format      string `yaml:"format,omitempty"`
+	Depth       *int   `yaml:"depth,omitempty"`
+	Fallback    string `yaml:"fallback,omitempty"`
+	HotSubtrees string `yaml:"hot_subtrees,omitempty"`
+	Aliases     string `yaml:"aliases,omitempty"`
+}
+
+// Path returns the path of the configuration file.
+func Path() (string, error) {
+	return paths.ConfigFile(FileName)
+}
+
+// Load reads the configuration at path. A missing file has no settings.
+func Load(path string) (*Config, error) {
+	c := &Config{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("cannot read configuration: %w", err)
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("cannot parse configuration %s: %w", path, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// LoadDefault reads the configuration in the configuration directory (~/.workflowy/config.yaml).
+func LoadDefault() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return Load(path)
+}
+
+// Save writes the configuration to path.
+func (c *Config) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("cannot encode configuration: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("cannot create configuration directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("cannot write configuration: %w", err)
+	}
+	return nil
+}
+
+func (c *Config) validate() error {
+	if c.Format != "" && !slices.Contains(Formats, c.Format) {
+		return fmt.Errorf("format must be one of %v, not %q", Formats, c.Format)
+	}
+	if c.Depth != nil && *c.Depth < -1 {
+		return fmt.Errorf("depth must be -1 (unlimited) or more, not %d", *c.Depth)
+	}
+	if c.Fallback != "" {
+		if _, err := ParseFallback(c.Fallback); err != nil {
+			return err
+		}
+	}
+	if _, err := ParseHotSubtrees(c.HotSubtrees); err != nil {
+		return err
+	}
+	if _, err := ParseAliases(c.Aliases); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseFallback returns the sources of a fallback setting such as
+// "export,backup", or none for "none".
+func ParseFallback(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "none" {
+		return nil, nil
+	}
+	var sources []string
+	for _, source := range strings.Split(value, ",") {
+		source = strings.TrimSpace(source)
+		if !slices.Contains(FallbackSources, source) {
+			return nil, fmt.Errorf("fallback sources must be among %v or none, not %q", FallbackSources, source)
+		}
+		if !slices.Contains(sources, source) {
+			sources = append(sources, source)
+		}
+	}
+	return sources, nil
+}
+
+// HotSubtree is a subtree refreshed with the GET API every Interval, so
+// that it stays fresh between the exports of the whole tree.
+type HotSubtree struct {
+	ID       string        `json:"id"` // UUID, short ID or target key
+	Interval time.Duration `json:"interval"`
+}
+
+// ParseHotSubtrees returns the subtrees of a hot_subtrees setting such as
+// "projects=30s,3495d784", those without an interval refreshed every
+// DefaultHotInterval.
+func ParseHotSubtrees(value string) ([]HotSubtree, error) {
+	var subtrees []HotSubtree
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, interval, found := strings.Cut(entry, "=")
+		subtree := HotSubtree{ID: strings.TrimSpace(id), Interval: DefaultHotInterval}
+		if subtree.ID == "" {
+			return nil, fmt.Errorf("hot subtree %q has no ID", entry)
+		}
+		if found {
+			d, err := time.ParseDuration(strings.TrimSpace(interval))
+			if err != nil {
+				return nil, fmt.Errorf("hot subtree %q: interval must be a duration such as 30s or 5m", entry)
+			}
+			if d < MinHotInterval {
+				return nil, fmt.Errorf("hot subtree %q: interval must be at least %s", entry, MinHotInterval)
+			}
+			subtree.Interval = d
+		}
+		subtrees = append(subtrees, subtree)
+	}
+	return subtrees, nil
+}
+
+// ParseAliases returns the IDs of the names of an aliases setting such as
+// "work=3495d784,site=/Projects/Website".
+func ParseAliases(value string) (map[string]string, error) {
+	aliases := map[string]string{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, id, found := strings.Cut(entry, "=")
+		name, id = strings.TrimSpace(name), strings.TrimSpace(id)
+		if !found || name == "" || id == "" {
+			return nil, fmt.Errorf("alias %q must be a name=ID pair", entry)
+		}
+		if _, ok := aliases[name]; ok {
+			return nil, fmt.Errorf("alias %q is defined twice", name)
+		}
+		aliases[name] = id
+	}
+	return aliases, nil
+}
+
+// Get returns the value of key, or "" if it is not set.
+func (c *Config) Get(key string) (string, error) {
+	if _, err := Find(key); err != nil {
+		return "", err
+	}
+	switch key {
+	case "backup_dir":
+		return c.BackupDir, nil
+	case "cache_dir":
+		return c.CacheDir, nil
+	case "storage":
+		return c.Storage, nil
+	case "api_key_file":
+		return c.APIKeyFile, nil
+	case "format":
+		return c.Format, nil
+	case "depth":
+		if c.Depth == nil {
+			return "", nil
+		}
+		return strconv.Itoa(*c.Depth), nil
+	case "fallback":
+		return c.Fallback, nil
+	case "hot_subtrees":
+		return c.HotSubtrees, nil
+	case "aliases":
+		return c.Aliases, nil
+	}
+	return "", nil
+}
+
+// Set sets key to value; an empty value unsets it. An invalid value leaves
+// the configuration unchanged.
+func (c *Config) Set(key, value string) error {
+	if _, err := Find(key); err != nil {
+		return err
+	}
+	updated := *c
+	if err := updated.set(key, value); err != nil {
+		return err
+	}
+	if err := updated.validate(); err != nil {
+		return err
+	}
+	*c = updated
+	return nil
+}
+
+func (c *Config) set(key, value string) error {
+	switch key {
+	case "backup_dir":
+		c.BackupDir = value
+	case "cache_dir":
+		c.CacheDir = value
+	case "storage":
+		c.Storage = value
+	case "api_key_file":
+		c.APIKeyFile = value
+	case "format":
+		c.Format = value
+	case "depth":
+		c.Depth = nil
+		if value != "" {
+			depth, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("depth must be an integer, not %q", value)
+			}
+			c.Depth = &depth
+		}
+	case "fallback":
+		c.Fallback = value
+	case "hot_subtrees":
+		c.HotSubtrees = value
+	case "aliases":
+		c.Aliases = value
+	}
+	return nil
+}
+
+// Locations returns the backup and cache directories and the storage of the
+// configuration, for paths.Configure.
+func (c *Config) Locations() paths.Configured {
+	locations := paths.Configured{CacheDir: c.CacheDir, Storage: c.Storage}
+	if c.BackupDir != "" {
+		locations.BackupDirs = filepath.SplitList(c.BackupDir)
+	}
+	return locations
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetGetSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	c, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, &Config{}, c)
+
+	require.NoError(t, c.Set("format", "markdown"))
+	require.NoError(t, c.Set("depth", "3"))
+	require.NoError(t, c.Set("backup_dir", "~/a"+string(os.PathListSeparator)+"/b"))
+	require.NoError(t, c.Set("storage", "/data/workflowy"))
+	assert.Error(t, c.Set("format", "xml"))
+	assert.Error(t, c.Set("depth", "deep"))
+	assert.Error(t, c.Set("depth", "-2"))
+	assert.Error(t, c.Set("color", "blue"))
+	require.NoError(t, c.Save(path))
+
+	read, err := Load(path)
+	require.NoError(t, err)
+	format, err := read.Get("format")
+	require.NoError(t, err)
+	assert.Equal(t, "markdown", format)
+	depth, err := read.Get("depth")
+	require.NoError(t, err)
+	assert.Equal(t, "3", depth)
+	assert.Equal(t, []string{"~/a", "/b"}, read.Locations().BackupDirs)
+	assert.Equal(t, "/data/workflowy", read.Locations().Storage)
+
+	require.NoError(t, read.Set("depth", ""))
+	assert.Nil(t, read.Depth)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte("format: xml\n"), 0644))
+	_, err := Load(path)
+	assert.ErrorContains(t, err, "format must be one of")
+}
+
+func TestParseFallback(t *testing.T) {
+	sources, err := ParseFallback("export, backup,export")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"export", "backup"}, sources)
+
+	sources, err = ParseFallback("none")
+	require.NoError(t, err)
+	assert.Empty(t, sources)
+
+	_, err = ParseFallback("export,session")
+	assert.ErrorContains(t, err, "fallback sources must be among")
+
+	c := &Config{}
+	require.NoError(t, c.Set("fallback", "cache,backup"))
+	assert.Error(t, c.Set("fallback", "dropbox"))
+	assert.Equal(t, "cache,backup", c.Fallback)
+}
+
+func TestParseHotSubtrees(t *testing.T) {
+	subtrees, err := ParseHotSubtrees("projects=30s, 3495d784 ,")
+	require.NoError(t, err)
+	assert.Equal(t, []HotSubtree{
+		{ID: "projects", Interval: 30 * time.Second},
+		{ID: "3495d784", Interval: DefaultHotInterval},
+	}, subtrees)
+
+	_, err = ParseHotSubtrees("projects=1s")
+	assert.ErrorContains(t, err, "at least")
+	_, err = ParseHotSubtrees("projects=often")
+	assert.Error(t, err)
+	_, err = ParseHotSubtrees("=1m")
+	assert.Error(t, err)
+
+	c := &Config{}
+	require.NoError(t, c.Set("hot_subtrees", "inbox=2m"))
+	assert.Error(t, c.Set("hot_subtrees", "inbox=2"))
+	assert.Equal(t, "inbox=2m", c.HotSubtrees)
+}
+
+func TestParseAliases(t *testing.T) {
+	aliases, err := ParseAliases("work=3495d784, site = /Projects/Website ,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"work": "3495d784", "site": "/Projects/Website"}, aliases)
+
+	_, err = ParseAliases("work")
+	assert.ErrorContains(t, err, "name=ID")
+	_, err = ParseAliases("work=a,work=b")
+	assert.ErrorContains(t, err, "twice")
+
+	c := &Config{}
+	require.NoError(t, c.Set("aliases", "work=inbox"))
+	assert.Error(t, c.Set("aliases", "=inbox"))
+	assert.Equal(t, "work=inbox", c.Aliases)
+}
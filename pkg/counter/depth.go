@@ -0,0 +1,61 @@
+package counter
+
+import "slices"
+
+// DepthStats describes the shape of a tree: how deep and how wide it is.
+type DepthStats[T any] struct {
+	MaxDepth        int     // depth of the deepest node, the root being at depth 0
+	NodeCount       int     // nodes in the tree, including the root
+	LevelCounts     []int   // number of nodes at each depth
+	BranchingFactor float64 // average number of children of the nodes having some
+	DeepestPaths    [][]T   // paths from the root to the deepest leaves, deepest first
+}
+
+// CountDepth measures the tree under root, keeping the paths to its maxPaths
+// deepest leaves (0 for all). Leaves of equal depth keep their tree order.
+func CountDepth[T TreeProvider[T]](root T, maxPaths int) DepthStats[T] {
+	stats := DepthStats[T]{}
+	parents, children := 0, 0
+
+	var visit func(node T, path []T)
+	visit = func(node T, path []T) {
+		path = append(path, node)
+		depth := len(path) - 1
+		stats.NodeCount++
+		if depth == len(stats.LevelCounts) {
+			stats.LevelCounts = append(stats.LevelCounts, 0)
+		}
+		stats.LevelCounts[depth]++
+		stats.MaxDepth = max(stats.MaxDepth, depth)
+
+		count := 0
+		for child := range node.Children() {
+			count++
+			visit(child.Node(), path)
+		}
+		if count > 0 {
+			parents++
+			children += count
+			return
+		}
+
+		// insert the leaf after the paths at least as deep
+		i := len(stats.DeepestPaths)
+		for i > 0 && len(stats.DeepestPaths[i-1]) < len(path) {
+			i--
+		}
+		if maxPaths > 0 && i >= maxPaths {
+			return
+		}
+		stats.DeepestPaths = slices.Insert(stats.DeepestPaths, i, slices.Clone(path))
+		if maxPaths > 0 && len(stats.DeepestPaths) > maxPaths {
+			stats.DeepestPaths = stats.DeepestPaths[:maxPaths]
+		}
+	}
+	visit(root, nil)
+
+	if parents > 0 {
+		stats.BranchingFactor = float64(children) / float64(parents)
+	}
+	return stats
+}
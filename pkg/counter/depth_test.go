@@ -0,0 +1,51 @@
+package counter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CountDepth(t *testing.T) {
+	root := &testTreeNode{val: 1, children: []*testTreeNode{
+		{val: 2, children: []*testTreeNode{
+			{val: 4},
+			{val: 5, children: []*testTreeNode{
+				{val: 7},
+			}},
+		}},
+		{val: 3, children: []*testTreeNode{
+			{val: 6, children: []*testTreeNode{
+				{val: 8},
+			}},
+		}},
+	}}
+
+	values := func(paths [][]*testTreeNode) [][]int {
+		result := [][]int{}
+		for _, path := range paths {
+			vals := []int{}
+			for _, node := range path {
+				vals = append(vals, node.val)
+			}
+			result = append(result, vals)
+		}
+		return result
+	}
+
+	stats := CountDepth(root, 2)
+	assert.Equal(t, 3, stats.MaxDepth)
+	assert.Equal(t, 8, stats.NodeCount)
+	assert.Equal(t, []int{1, 2, 3, 2}, stats.LevelCounts)
+	assert.InDelta(t, 7.0/5.0, stats.BranchingFactor, 1e-9)
+	assert.Equal(t, [][]int{{1, 2, 5, 7}, {1, 3, 6, 8}}, values(stats.DeepestPaths))
+
+	all := CountDepth(root, 0)
+	assert.Equal(t, [][]int{{1, 2, 5, 7}, {1, 3, 6, 8}, {1, 2, 4}}, values(all.DeepestPaths))
+
+	leaf := CountDepth(&testTreeNode{val: 1}, 5)
+	assert.Equal(t, 0, leaf.MaxDepth)
+	assert.Equal(t, []int{1}, leaf.LevelCounts)
+	assert.Zero(t, leaf.BranchingFactor)
+	assert.Equal(t, [][]int{{1}}, values(leaf.DeepestPaths))
+}
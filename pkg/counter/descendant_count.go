@@ -127,12 +127,12 @@ func CountDescendantTree[T TreeProvider[T]](root T) *DescendantTreeCount[*T] {
 }
 
 type TreeTraversePostTracker[F any, T any] struct {
-	Parent   *F
-	Children []*T
+	Parent     *F
+	Children   []*T
+	Eliminated int // children left out of Children
 }
 
 func FilterDescendantTree[T any](descendantTreeCount *DescendantTreeCount[*T], threshold float64) *DescendantTreeCount[*T] {
-	eliminated := []*DescendantTreeCount[*T]{}
 	frames := collections.Stack[TreeTraversePostTracker[*DescendantTreeCount[*T], DescendantTreeCount[*T]]]{}
 	pop := false
 
@@ -160,11 +160,10 @@ func FilterDescendantTree[T any](descendantTreeCount *DescendantTreeCount[*T], t
 			for _, child := range frame.Children {
 				belowThresholdCount += child.BelowThresholdCount
 			}
-			belowThresholdCount += len(eliminated)
+			belowThresholdCount += frame.Eliminated
 			newNode.BelowThresholdCount = belowThresholdCount
 
 			newNode.SetChildren(frame.Children)
-			eliminated = eliminated[:0]
 		}
 		newNode.Count = node.Count
 		newNode.ChildrenCount = node.ChildrenCount
@@ -176,7 +175,7 @@ func FilterDescendantTree[T any](descendantTreeCount *DescendantTreeCount[*T], t
 		frame := frames.Top()
 
 		if isEliminated {
-			eliminated = append(eliminated, node)
+			frame.Eliminated++
 		} else {
 			frame.Children = append(frame.Children, newNode)
 		}
@@ -82,3 +82,82 @@ func Test_SortDescendantTree(t *testing.T) {
 	require.True(t, sorted.children[0].Count >= sorted.children[1].Count)
 	require.True(t, sorted.children[1].Count >= sorted.children[2].Count)
 }
+
+// treeFromBytes decodes fuzz input into a tree: each byte adds a node under
+// one of the nodes before it. It returns the root and the number of nodes.
+func treeFromBytes(data []byte) (*testTreeNode, int) {
+	nodes := []*testTreeNode{{val: 0}}
+	for i, b := range data[:min(len(data), 300)] {
+		parent := nodes[int(b)%len(nodes)]
+		node := &testTreeNode{val: i + 1}
+		parent.children = append(parent.children, node)
+		nodes = append(nodes, node)
+	}
+	return nodes[0], len(nodes)
+}
+
+type testCount = DescendantTreeCount[**testTreeNode]
+
+func FuzzFilterDescendantTree(f *testing.F) {
+	f.Add(uint8(30), []byte{0, 0, 0, 3})
+	f.Add(uint8(0), []byte{})
+	f.Add(uint8(100), []byte{0, 1, 2, 3, 4})
+	f.Add(uint8(10), []byte{0, 0, 1, 1, 2, 2, 0, 7, 7, 7, 9, 0})
+	f.Add(uint8(25), []byte{0, 1, 0, 3, 3, 3, 0})
+
+	f.Fuzz(func(t *testing.T, percent uint8, data []byte) {
+		root, total := treeFromBytes(data)
+		threshold := float64(percent%101) / 100
+
+		counted := CountDescendantTree(root)
+		var checkCounts func(node *testCount)
+		checkCounts = func(node *testCount) {
+			sum := 1
+			for _, child := range node.children {
+				sum += child.Count
+				checkCounts(child)
+			}
+			require.Equal(t, sum, node.Count)
+		}
+		checkCounts(counted)
+		require.Equal(t, total, counted.Count)
+
+		// nodes at or above the threshold are kept; the others are counted as
+		// below the threshold by their closest kept ancestor
+		CalculateRatioToRoot(counted)
+		var kept, below int
+		var expect func(node *testCount)
+		expect = func(node *testCount) {
+			kept++
+			for _, child := range node.children {
+				if child.RatioToRoot < threshold {
+					below++
+					continue
+				}
+				expect(child)
+			}
+		}
+		expect(counted)
+
+		filtered := FilterDescendantTree(counted, threshold)
+		require.Equal(t, total, filtered.Count)
+
+		var filteredKept int
+		var walk func(node *testCount, isRoot bool)
+		walk = func(node *testCount, isRoot bool) {
+			filteredKept++
+			if !isRoot {
+				require.GreaterOrEqual(t, node.RatioToRoot, threshold)
+			}
+			childrenBelow := 0
+			for _, child := range node.children {
+				childrenBelow += child.BelowThresholdCount
+				walk(child, false)
+			}
+			require.GreaterOrEqual(t, node.BelowThresholdCount, childrenBelow)
+		}
+		walk(filtered, true)
+		require.Equal(t, kept, filteredKept)
+		require.Equal(t, below, filtered.BelowThresholdCount)
+	})
+}
@@ -0,0 +1,341 @@
+package escape
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// ToMarkdown converts a Workflowy name or note to markdown. Bold, italic,
+// strikethrough, code and links become markdown syntax, other formatting tags
+// (underline, colors) are kept as inline HTML, and text is escaped so it
+// renders literally.
+func ToMarkdown(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		tag, length := formattingTag(s[i:])
+		if tag == "" {
+			next := nextTag(s, i+1)
+			b.WriteString(Markdown(html.UnescapeString(s[i:next])))
+			i = next
+			continue
+		}
+
+		open := s[i : i+length]
+		end, closeLen := matchingClose(s, i+length, tag)
+		if strings.HasPrefix(open, "</") || end < 0 {
+			// unmatched tags are kept as inline HTML
+			b.WriteString(open)
+			i += length
+			continue
+		}
+		inner := s[i+length : end]
+		after := end + closeLen
+
+		switch tag {
+		case "b":
+			b.WriteString(wrap(ToMarkdown(inner), "**"))
+		case "i":
+			// underscores do not emphasize inside words, so use asterisks there
+			delim := "_"
+			if isWordByte(b.String(), b.Len()-1) || isWordByte(s, after) {
+				delim = "*"
+			}
+			b.WriteString(wrap(ToMarkdown(inner), delim))
+		case "s":
+			b.WriteString(wrap(ToMarkdown(inner), "~~"))
+		case "code":
+			b.WriteString(codeSpan(Text(inner)))
+		case "a":
+			b.WriteString("[" + ToMarkdown(inner) + "](" + linkDestination(href(open)) + ")")
+		default:
+			b.WriteString(open + ToMarkdown(inner) + s[end:after])
+		}
+		i = after
+	}
+	return b.String()
+}
+
+// nextTag returns the offset of the next formatting tag in s at or after from, or len(s).
+func nextTag(s string, from int) int {
+	for i := from; i < len(s); i++ {
+		if s[i] != '<' {
+			continue
+		}
+		if tag, _ := formattingTag(s[i:]); tag != "" {
+			return i
+		}
+	}
+	return len(s)
+}
+
+// matchingClose returns the offset and length of the tag closing a tag named
+// name whose content starts at from, or -1 if it is not closed.
+func matchingClose(s string, from int, name string) (int, int) {
+	depth := 0
+	for i := nextTag(s, from); i < len(s); i = nextTag(s, i+1) {
+		tag, length := formattingTag(s[i:])
+		if tag != name {
+			continue
+		}
+		if !strings.HasPrefix(s[i:], "</") {
+			depth++
+			continue
+		}
+		if depth == 0 {
+			return i, length
+		}
+		depth--
+	}
+	return -1, 0
+}
+
+// wrap surrounds inner with delim, keeping leading and trailing spaces outside
+// as markdown requires.
+func wrap(inner, delim string) string {
+	trimmed := strings.TrimLeft(inner, " ")
+	lead := inner[:len(inner)-len(trimmed)]
+	content := strings.TrimRight(trimmed, " ")
+	trail := trimmed[len(content):]
+	if content == "" {
+		return inner
+	}
+	return lead + delim + content + delim + trail
+}
+
+// codeSpan returns text as a markdown code span, using a backtick fence longer
+// than any backtick run in text.
+func codeSpan(text string) string {
+	fence := "`"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+	return fence + text + fence
+}
+
+var hrefPattern = regexp.MustCompile(`(?i)\bhref\s*=\s*("[^"]*"|'[^']*'|[^\s>]+)`)
+
+// href returns the decoded href attribute of an anchor tag.
+func href(tag string) string {
+	m := hrefPattern.FindStringSubmatch(tag)
+	if m == nil {
+		return ""
+	}
+	return html.UnescapeString(strings.Trim(m[1], `"'`))
+}
+
+// linkDestination writes url as a markdown link destination, in angle
+// brackets when it contains spaces or parentheses.
+func linkDestination(url string) string {
+	if strings.ContainsAny(url, " ()<>") {
+		return "<" + strings.NewReplacer("<", `\<`, ">", `\>`).Replace(url) + ">"
+	}
+	return url
+}
+
+// FromMarkdown converts markdown-ish text to a Workflowy name or note:
+// **bold**, _italic_ or *italic*, ~~strikethrough~~, `code` and [links](url)
+// become formatting tags, backslash escapes are resolved, Workflowy
+// formatting tags pass through, and other text is HTML-escaped.
+func FromMarkdown(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			b.WriteString(HTML(s[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '`':
+			if code, n := parseCodeSpan(s[i:]); n > 0 {
+				b.WriteString("<code>" + HTML(code) + "</code>")
+				i += n
+				continue
+			}
+			run := backtickRun(s[i:])
+			b.WriteString(s[i : i+run])
+			i += run
+			continue
+
+		case c == '*' || c == '_' || c == '~':
+			if out, n := parseEmphasis(s, i); n > 0 {
+				b.WriteString(out)
+				i += n
+				continue
+			}
+
+		case c == '[':
+			if text, url, n := parseLink(s[i:]); n > 0 {
+				b.WriteString(`<a href="` + attrEscaper.Replace(url) + `">` + FromMarkdown(text) + "</a>")
+				i += n
+				continue
+			}
+
+		case c == '<':
+			if tag, n := formattingTag(s[i:]); tag != "" {
+				b.WriteString(s[i : i+n])
+				i += n
+				continue
+			}
+
+		case c == '&':
+			if m := entityPattern.FindString(s[i:]); m != "" {
+				b.WriteString(m)
+				i += len(m)
+				continue
+			}
+		}
+		b.WriteString(HTML(s[i : i+1]))
+		i++
+	}
+	return b.String()
+}
+
+var attrEscaper = strings.NewReplacer("&", "&amp;", `"`, "&quot;", "<", "&lt;", ">", "&gt;")
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func backtickRun(s string) int {
+	n := 0
+	for n < len(s) && s[n] == '`' {
+		n++
+	}
+	return n
+}
+
+// parseCodeSpan parses a code span at the start of s, returning its content
+// and length, or 0 if the backticks are not closed by a run of the same length.
+func parseCodeSpan(s string) (string, int) {
+	run := backtickRun(s)
+	for i := run; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		closing := backtickRun(s[i:])
+		if closing == run {
+			code := s[run:i]
+			if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+				code = code[1 : len(code)-1]
+			}
+			return code, i + closing
+		}
+		i += closing
+	}
+	return "", 0
+}
+
+// emphasisTags maps emphasis delimiters to the tag they produce.
+var emphasisTags = map[string]string{"**": "b", "__": "b", "*": "i", "_": "i", "~~": "s"}
+
+// parseEmphasis parses emphasis opening at s[i], returning the HTML and the
+// length consumed, or 0 if the delimiter does not open a closed emphasis.
+func parseEmphasis(s string, i int) (string, int) {
+	for _, delim := range []string{"**", "__", "~~", "*", "_"} {
+		if !strings.HasPrefix(s[i:], delim) {
+			continue
+		}
+		start := i + len(delim)
+		if start >= len(s) || s[start] == ' ' || delim[0] == '_' && isWordByte(s, i-1) {
+			return "", 0
+		}
+		if len(delim) == 1 && s[start] == delim[0] {
+			// an unclosed double delimiter is text
+			return "", 0
+		}
+		end := closingDelimiter(s, start, delim)
+		if end <= start {
+			continue
+		}
+		tag := emphasisTags[delim]
+		return "<" + tag + ">" + FromMarkdown(s[start:end]) + "</" + tag + ">", end + len(delim) - i
+	}
+	return "", 0
+}
+
+// closingDelimiter finds the delimiter closing an emphasis whose content starts
+// at from, skipping escapes, code spans and nested emphasis, or returns -1.
+func closingDelimiter(s string, from int, delim string) int {
+	for i := from; i < len(s); {
+		switch {
+		case s[i] == '\\':
+			i += 2
+			continue
+		case s[i] == '`':
+			if _, n := parseCodeSpan(s[i:]); n > 0 {
+				i += n
+				continue
+			}
+		case strings.HasPrefix(s[i:], delim) && s[i-1] != ' ':
+			after := i + len(delim)
+			// a single delimiter does not close on part of a double one
+			double := len(delim) == 1 && after < len(s) && s[after] == delim[0]
+			if !double && !(delim[0] == '_' && isWordByte(s, after)) {
+				return i
+			}
+			if double {
+				// skip nested strong emphasis as a unit
+				if end := closingDelimiter(s, after+1, delim+delim); end > 0 {
+					i = end + 2
+					continue
+				}
+				i += 2
+				continue
+			}
+		}
+		i++
+	}
+	return -1
+}
+
+// parseLink parses [text](url) at the start of s.
+func parseLink(s string) (text, url string, n int) {
+	depth := 0
+	closeBracket := -1
+	for i := 0; i < len(s) && closeBracket < 0; i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closeBracket = i
+			}
+		}
+	}
+	if closeBracket < 0 || closeBracket+1 >= len(s) || s[closeBracket+1] != '(' {
+		return "", "", 0
+	}
+	text = s[1:closeBracket]
+	rest := s[closeBracket+2:]
+
+	if strings.HasPrefix(rest, "<") {
+		for i := 1; i < len(rest); i++ {
+			switch rest[i] {
+			case '\\':
+				i++
+			case '>':
+				if i+1 < len(rest) && rest[i+1] == ')' {
+					url = strings.NewReplacer(`\<`, "<", `\>`, ">").Replace(rest[1:i])
+					return text, url, closeBracket + 2 + i + 2
+				}
+				return "", "", 0
+			}
+		}
+		return "", "", 0
+	}
+
+	end := strings.IndexAny(rest, ") ")
+	if end < 0 || rest[end] != ')' {
+		return "", "", 0
+	}
+	return text, rest[:end], closeBracket + 2 + end + 1
+}
@@ -0,0 +1,110 @@
+// Package escape converts text between Workflowy's HTML-formatted names and
+// notes, plain text and markdown, so content survives a round trip: markdown
+// produced by ToMarkdown converts back to the same HTML with FromMarkdown.
+package escape
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// HTML escapes plain text for use as a Workflowy name or note.
+func HTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Text returns the plain text of a Workflowy name or note: formatting tags
+// are removed and entities decoded.
+func Text(s string) string {
+	return html.UnescapeString(tagPattern.ReplaceAllString(s, ""))
+}
+
+// tagPattern matches an HTML tag.
+var tagPattern = regexp.MustCompile(`</?[a-zA-Z][^<>]*>`)
+
+// formattingTags are the inline tags Workflowy uses in names and notes. They
+// are the only tags passed through as inline HTML; other tags are text.
+var formattingTags = map[string]bool{"b": true, "i": true, "u": true, "s": true, "code": true, "a": true, "span": true}
+
+// formattingTag returns the name of the formatting tag at the start of s and
+// its length, or "" if s does not start with one.
+func formattingTag(s string) (name string, length int) {
+	loc := tagPattern.FindStringIndex(s)
+	if loc == nil || loc[0] != 0 {
+		return "", 0
+	}
+	tag := strings.TrimPrefix(s[1:loc[1]-1], "/")
+	if end := strings.IndexAny(tag, " \t\n/"); end >= 0 {
+		tag = tag[:end]
+	}
+	tag = strings.ToLower(tag)
+	if !formattingTags[tag] {
+		return "", 0
+	}
+	return tag, loc[1]
+}
+
+// Markdown escapes plain text so markdown renders it literally.
+func Markdown(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = escapeLineStart(escapeInline(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// entityPattern matches text that markdown would decode as an HTML entity.
+var entityPattern = regexp.MustCompile(`^&(#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z][a-zA-Z0-9]*);`)
+
+func escapeInline(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case strings.IndexByte("\\`*[]", c) >= 0:
+			b.WriteByte('\\')
+		case c == '_' && !(isWordByte(s, i-1) && isWordByte(s, i+1)):
+			// intraword underscores (snake_case) never start emphasis
+			b.WriteByte('\\')
+		case c == '~' && (i > 0 && s[i-1] == '~' || i+1 < len(s) && s[i+1] == '~'):
+			b.WriteByte('\\')
+		case c == '<' && i+1 < len(s) && (isLetter(s[i+1]) || strings.IndexByte("/!?", s[i+1]) >= 0):
+			b.WriteByte('\\')
+		case c == '&' && entityPattern.MatchString(s[i:]):
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// lineStartPattern matches a line start that markdown would read as a block:
+// a heading, quote, list item or ordered list item. "* " is already escaped
+// by escapeInline.
+var lineStartPattern = regexp.MustCompile(`^( {0,3})(#{1,6}(?: |$)|>|[-+] |[0-9]{1,9}[.)] )`)
+
+func escapeLineStart(line string) string {
+	m := lineStartPattern.FindStringSubmatchIndex(line)
+	if m == nil {
+		return line
+	}
+	marker := line[m[4]:m[5]]
+	// escape the punctuation of the marker, not the digits of an ordered list item
+	at := m[4] + strings.IndexFunc(marker, func(r rune) bool { return r < '0' || r > '9' })
+	return line[:at] + `\` + line[at:]
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isWordByte(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return isLetter(c) || c >= '0' && c <= '9' || c >= 0x80
+}
@@ -0,0 +1,61 @@
+package escape
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// golden converts each line of testdata/input and compares the result with testdata/input.golden.
+func golden(t *testing.T, input string, convert func(string) string) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", input))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+
+	var out strings.Builder
+	for _, line := range lines {
+		out.WriteString(convert(line) + "\n")
+	}
+
+	path := filepath.Join("testdata", input+".golden")
+	if *update {
+		require.NoError(t, os.WriteFile(path, []byte(out.String()), 0644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), out.String())
+	return lines
+}
+
+func TestToMarkdown_Golden(t *testing.T) {
+	lines := golden(t, "names.html", ToMarkdown)
+
+	// markdown produced from a name converts back to the same name
+	for _, line := range lines {
+		assert.Equal(t, line, FromMarkdown(ToMarkdown(line)), "round trip of %q", line)
+	}
+}
+
+func TestFromMarkdown_Golden(t *testing.T) {
+	golden(t, "import.md", FromMarkdown)
+}
+
+func TestToMarkdown_MovesSpacesOutsideEmphasis(t *testing.T) {
+	assert.Equal(t, "a **bold** b", ToMarkdown("a<b> bold </b>b"))
+}
+
+func TestText(t *testing.T) {
+	assert.Equal(t, "Fish & chips <now>", Text("<b>Fish</b> &amp; chips &lt;now&gt;"))
+}
+
+func TestMarkdown_Multiline(t *testing.T) {
+	assert.Equal(t, "first\n\\# second\n3\\. third", Markdown("first\n# second\n3. third"))
+}
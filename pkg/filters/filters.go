@@ -0,0 +1,168 @@
+// Package filters reads saved searches, named filters defined in filters.yaml
+// in the configuration directory.
+package filters
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/paths"
+	"github.com/mholzen/workflowy/pkg/search"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is the name of the saved searches file in the configuration directory
+const DefaultFile = "filters.yaml"
+
+// Filter is a saved search. The file maps each name to its filter:
+//
+//	inbox-triage:
+//	  pattern: "todo|later"
+//	  regexp: true
+//	  ignore_case: true
+//	  fields: [name, note]
+//	  root_id: inbox
+//	  tags: ["#waiting"]
+//	  completed: exclude
+type Filter struct {
+	Name        string   `yaml:"-" json:"name"`
+	Description string   `yaml:"description" json:"description,omitempty"`
+	Pattern     string   `yaml:"pattern" json:"pattern"`
+	Regexp      bool     `yaml:"regexp" json:"regexp"`
+	IgnoreCase  bool     `yaml:"ignore_case" json:"ignore_case"`
+	Fields      []string `yaml:"fields" json:"fields"`                 // default: name
+	RootID      string   `yaml:"root_id" json:"root_id,omitempty"`     // UUID, short ID or target key; default: root
+	Tags        []string `yaml:"tags" json:"tags,omitempty"`           // results must carry all of these tags
+	Completed   string   `yaml:"completed" json:"completed,omitempty"` // include (default), exclude or only
+}
+
+// Load reads the filters at path, sorted by name. A missing file has no filters.
+func Load(path string) ([]Filter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot read saved searches: %w", err)
+	}
+	var byName map[string]Filter
+	if err := yaml.Unmarshal(data, &byName); err != nil {
+		return nil, fmt.Errorf("cannot parse saved searches %s: %w", path, err)
+	}
+
+	filters := make([]Filter, 0, len(byName))
+	for name, filter := range byName {
+		filter.Name = name
+		if err := filter.normalize(); err != nil {
+			return nil, fmt.Errorf("saved search %q in %s: %w", name, path, err)
+		}
+		filters = append(filters, filter)
+	}
+	sort.Slice(filters, func(i, j int) bool { return filters[i].Name < filters[j].Name })
+	return filters, nil
+}
+
+// LoadDefault reads the filters in the configuration directory (~/.workflowy/filters.yaml).
+func LoadDefault() ([]Filter, error) {
+	path, err := paths.ConfigFile(DefaultFile)
+	if err != nil {
+		return nil, err
+	}
+	return Load(path)
+}
+
+// Find returns the filter called name.
+func Find(filters []Filter, name string) (Filter, error) {
+	for _, filter := range filters {
+		if filter.Name == name {
+			return filter, nil
+		}
+	}
+	if len(filters) == 0 {
+		return Filter{}, fmt.Errorf("unknown saved search %q: no saved searches are defined", name)
+	}
+	names := make([]string, len(filters))
+	for i, filter := range filters {
+		names[i] = filter.Name
+	}
+	return Filter{}, fmt.Errorf("unknown saved search %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// normalize validates the filter and fills in its defaults.
+func (f *Filter) normalize() error {
+	if f.Pattern == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	if f.Regexp {
+		if _, err := search.CompileRegexp(f.Pattern, f.IgnoreCase); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	fields := search.FieldName
+	if len(f.Fields) > 0 {
+		fields = strings.Join(f.Fields, ",")
+	}
+	parsed, err := search.ParseFields(fields)
+	if err != nil {
+		return err
+	}
+	f.Fields = parsed
+	if err := workflowy.ValidateCompletedMode(f.Completed); err != nil {
+		return err
+	}
+	for i, tag := range f.Tags {
+		if !strings.HasPrefix(tag, "#") && !strings.HasPrefix(tag, "@") {
+			f.Tags[i] = "#" + tag
+		}
+	}
+	return nil
+}
+
+// Search runs the filter on items and their descendants. Tags are matched
+// without regard to case, in names and notes. With completed set to only, the
+// completed nodes that match are returned, not their ancestors.
+func (f Filter) Search(items []*workflowy.Item) []search.Result {
+	items = workflowy.FilterCompleted(items, f.Completed)
+	results := search.SearchFields(items, f.Pattern, f.Regexp, f.IgnoreCase, f.Fields)
+	if len(f.Tags) == 0 && f.Completed != workflowy.CompletedOnly {
+		return results
+	}
+
+	selected := make(map[string]bool)
+	var visit func(items []*workflowy.Item)
+	visit = func(items []*workflowy.Item) {
+		for _, item := range items {
+			if f.hasTags(item) && (f.Completed != workflowy.CompletedOnly || item.IsCompleted()) {
+				selected[item.ID] = true
+			}
+			visit(item.Children)
+		}
+	}
+	visit(items)
+
+	var kept []search.Result
+	for _, result := range results {
+		if selected[result.ID] {
+			kept = append(kept, result)
+		}
+	}
+	return kept
+}
+
+func (f Filter) hasTags(item *workflowy.Item) bool {
+	note := ""
+	if item.Note != nil {
+		note = *item.Note
+	}
+	tags := workflowy.Tags(item.Name, note)
+	for _, want := range f.Tags {
+		if !slices.ContainsFunc(tags, func(tag string) bool { return strings.EqualFold(tag, want) }) {
+			return false
+		}
+	}
+	return true
+}
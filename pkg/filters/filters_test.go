@@ -0,0 +1,111 @@
+package filters
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/search"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFilters(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeFilters(t, `
+triage:
+  pattern: "todo|later"
+  regexp: true
+  fields: [note, name]
+  root_id: inbox
+  tags: [waiting, "@alice"]
+calls:
+  pattern: call
+`)
+	filters, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, filters, 2)
+
+	assert.Equal(t, "calls", filters[0].Name)
+	assert.Equal(t, []string{search.FieldName}, filters[0].Fields)
+	assert.Equal(t, "triage", filters[1].Name)
+	assert.Equal(t, []string{search.FieldNote, search.FieldName}, filters[1].Fields)
+	assert.Equal(t, "inbox", filters[1].RootID)
+	assert.Equal(t, []string{"#waiting", "@alice"}, filters[1].Tags)
+
+	filter, err := Find(filters, "triage")
+	require.NoError(t, err)
+	assert.Equal(t, "todo|later", filter.Pattern)
+	_, err = Find(filters, "missing")
+	assert.ErrorContains(t, err, "available: calls, triage")
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	filters, err := Load(filepath.Join(t.TempDir(), DefaultFile))
+	require.NoError(t, err)
+	assert.Empty(t, filters)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	_, err := Load(writeFilters(t, "nopattern:\n  fields: [name]\n"))
+	assert.ErrorContains(t, err, "pattern is required")
+	_, err = Load(writeFilters(t, "badfield:\n  pattern: x\n  fields: [body]\n"))
+	assert.ErrorContains(t, err, "unknown field")
+	_, err = Load(writeFilters(t, "badregexp:\n  pattern: \"(\"\n  regexp: true\n"))
+	assert.ErrorContains(t, err, "invalid pattern")
+}
+
+func TestSearchTags(t *testing.T) {
+	note := "blocked, #Waiting on vendor"
+	items := []*workflowy.Item{
+		{ID: "a", Name: "call plumber", Note: &note},
+		{ID: "b", Name: "call mom", Children: []*workflowy.Item{
+			{ID: "c", Name: "call back #waiting"},
+		}},
+	}
+	filter := Filter{Pattern: "call", Fields: []string{search.FieldName}, Tags: []string{"#waiting"}}
+
+	results := filter.Search(items)
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	assert.Equal(t, []string{"a", "c"}, ids)
+
+	filter.Tags = nil
+	assert.Len(t, filter.Search(items), 3)
+}
+
+func TestSearchCompleted(t *testing.T) {
+	done := int64(1700000000)
+	items := []*workflowy.Item{
+		{ID: "a", Name: "call plumber", CompletedAt: &done, Children: []*workflowy.Item{
+			{ID: "b", Name: "call back"},
+		}},
+		{ID: "c", Name: "call mom", Children: []*workflowy.Item{
+			{ID: "d", Name: "call again", CompletedAt: &done},
+		}},
+	}
+	ids := func(results []search.Result) []string {
+		var ids []string
+		for _, result := range results {
+			ids = append(ids, result.ID)
+		}
+		return ids
+	}
+
+	filter := Filter{Pattern: "call", Fields: []string{search.FieldName}, Completed: workflowy.CompletedExclude}
+	assert.Equal(t, []string{"c"}, ids(filter.Search(items)))
+	filter.Completed = workflowy.CompletedOnly
+	assert.Equal(t, []string{"a", "d"}, ids(filter.Search(items)))
+
+	_, err := Load(writeFilters(t, "bad:\n  pattern: x\n  completed: hide\n"))
+	assert.ErrorContains(t, err, "completed must be")
+}
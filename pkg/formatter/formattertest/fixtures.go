@@ -0,0 +1,223 @@
+// Package formattertest provides sample trees and golden-file helpers to
+// regression-test formatters and custom MarkdownConfig settings:
+//
+//	func TestMyConfig(t *testing.T) {
+//		f := formatter.NewMarkdownFormatterWithConfig(myConfig)
+//		formattertest.GoldenFixtures(t, "testdata/markdown", f.FormatTree)
+//	}
+//
+// Run the tests with WORKFLOWY_UPDATE_GOLDEN=1, or set Update, to write the
+// golden files from the current output, then review and commit them.
+package formattertest
+
+import "github.com/mholzen/workflowy/pkg/workflowy"
+
+// Fixture is a named sample tree.
+type Fixture struct {
+	Name  string
+	Items []*workflowy.Item
+}
+
+// Fixtures returns the sample trees, each exercising a part of the markdown
+// conversion. They are built anew on each call, so they can be modified.
+func Fixtures() []Fixture {
+	return []Fixture{
+		{Name: "paragraphs", Items: paragraphs()},
+		{Name: "subheaders", Items: subheaders()},
+		{Name: "lists", Items: lists()},
+		{Name: "tags", Items: tags()},
+		{Name: "layout-modes", Items: layoutModes()},
+		{Name: "escaping", Items: escaping()},
+	}
+}
+
+// FixtureNamed returns the sample tree named name, or nil if there is none.
+func FixtureNamed(name string) []*workflowy.Item {
+	for _, fixture := range Fixtures() {
+		if fixture.Name == name {
+			return fixture.Items
+		}
+	}
+	return nil
+}
+
+func layout(mode string) map[string]interface{} {
+	return map[string]interface{}{"layoutMode": mode}
+}
+
+// paragraphs are headers whose children are sentences, separated by empty nodes.
+func paragraphs() []*workflowy.Item {
+	return []*workflowy.Item{
+		{
+			ID:   "3f2a9b1c-0d4e-4f5a-8b6c-000000000001",
+			Name: "Item A",
+			Children: []*workflowy.Item{
+				{ID: "3f2a9b1c-0d4e-4f5a-8b6c-000000000002", Name: "Item A1"},
+				{ID: "3f2a9b1c-0d4e-4f5a-8b6c-000000000003", Name: "Item A2"},
+			},
+		},
+		{
+			ID:   "3f2a9b1c-0d4e-4f5a-8b6c-000000000004",
+			Name: "Item B",
+			Children: []*workflowy.Item{
+				{ID: "3f2a9b1c-0d4e-4f5a-8b6c-000000000005", Name: "First paragraph"},
+				{ID: "3f2a9b1c-0d4e-4f5a-8b6c-000000000006", Name: ""},
+				{ID: "3f2a9b1c-0d4e-4f5a-8b6c-000000000007", Name: ""},
+				{ID: "3f2a9b1c-0d4e-4f5a-8b6c-000000000008", Name: "Second paragraph."},
+			},
+		},
+	}
+}
+
+// subheaders are nodes whose children all have children of their own.
+func subheaders() []*workflowy.Item {
+	return []*workflowy.Item{
+		{
+			ID:   "4a1b2c3d-0000-4000-8000-000000000001",
+			Name: "Item C",
+			Children: []*workflowy.Item{
+				{
+					ID:   "4a1b2c3d-0000-4000-8000-000000000002",
+					Name: "Item C1",
+					Children: []*workflowy.Item{
+						{ID: "4a1b2c3d-0000-4000-8000-000000000003", Name: "Item C11"},
+						{ID: "4a1b2c3d-0000-4000-8000-000000000004", Name: "Item C12"},
+					},
+				},
+				{
+					ID:   "4a1b2c3d-0000-4000-8000-000000000005",
+					Name: "Item C2",
+					Children: []*workflowy.Item{
+						{ID: "4a1b2c3d-0000-4000-8000-000000000006", Name: "Item C21"},
+						{ID: "4a1b2c3d-0000-4000-8000-000000000007", Name: "Item C22"},
+					},
+				},
+			},
+		},
+	}
+}
+
+// lists are introductions ending with a colon followed by short items.
+func lists() []*workflowy.Item {
+	return []*workflowy.Item{
+		{
+			ID:   "5b2c3d4e-0000-4000-8000-000000000001",
+			Name: "Item A",
+			Children: []*workflowy.Item{
+				{ID: "5b2c3d4e-0000-4000-8000-000000000002", Name: "This is a rather lengthy paragraph"},
+				{
+					ID:   "5b2c3d4e-0000-4000-8000-000000000003",
+					Name: "This looks like the beginning of a list:",
+					Children: []*workflowy.Item{
+						{ID: "5b2c3d4e-0000-4000-8000-000000000004", Name: "item 1"},
+						{ID: "5b2c3d4e-0000-4000-8000-000000000005", Name: "item 2"},
+						{ID: "5b2c3d4e-0000-4000-8000-000000000006", Name: "item 3"},
+					},
+				},
+				{ID: "5b2c3d4e-0000-4000-8000-000000000007", Name: "This looks like another lengthy paragraph."},
+			},
+		},
+	}
+}
+
+// tags are the layout tags of the default MarkdownConfig and its exclude tag.
+func tags() []*workflowy.Item {
+	return []*workflowy.Item{
+		{
+			ID:   "6c3d4e5f-0000-4000-8000-000000000001",
+			Name: "Title #h1",
+			Children: []*workflowy.Item{
+				{
+					ID:   "6c3d4e5f-0000-4000-8000-000000000002",
+					Name: "Section #h2",
+					Children: []*workflowy.Item{
+						{
+							ID:   "6c3d4e5f-0000-4000-8000-000000000003",
+							Name: "Subsection #h3",
+							Children: []*workflowy.Item{
+								{ID: "6c3d4e5f-0000-4000-8000-000000000004", Name: "Some text"},
+							},
+						},
+						{
+							ID:   "6c3d4e5f-0000-4000-8000-000000000005",
+							Name: "A paragraph #p",
+							Children: []*workflowy.Item{
+								{ID: "6c3d4e5f-0000-4000-8000-000000000006", Name: "with a child"},
+							},
+						},
+						{
+							ID:   "6c3d4e5f-0000-4000-8000-000000000007",
+							Name: "Steps #list",
+							Children: []*workflowy.Item{
+								{ID: "6c3d4e5f-0000-4000-8000-000000000008", Name: "first"},
+								{ID: "6c3d4e5f-0000-4000-8000-000000000009", Name: "second"},
+							},
+						},
+						{ID: "6c3d4e5f-0000-4000-8000-000000000010", Name: "Private notes #exclude"},
+					},
+				},
+			},
+		},
+	}
+}
+
+// layoutModes are the layout modes set in Workflowy.
+func layoutModes() []*workflowy.Item {
+	return []*workflowy.Item{
+		{
+			ID:   "7d4e5f60-0000-4000-8000-000000000001",
+			Name: "Quote content",
+			Data: layout("quote"),
+			Children: []*workflowy.Item{
+				{ID: "7d4e5f60-0000-4000-8000-000000000002", Name: "Line 1"},
+				{ID: "7d4e5f60-0000-4000-8000-000000000003", Name: "Line 2"},
+			},
+		},
+		{ID: "7d4e5f60-0000-4000-8000-000000000004", Data: layout("divider")},
+		{
+			ID:   "7d4e5f60-0000-4000-8000-000000000005",
+			Name: "function example()",
+			Data: layout("code"),
+			Children: []*workflowy.Item{
+				{ID: "7d4e5f60-0000-4000-8000-000000000006", Name: "  return true"},
+			},
+		},
+		{
+			ID:   "7d4e5f60-0000-4000-8000-000000000007",
+			Name: "Numbered steps",
+			Data: layout("ol"),
+			Children: []*workflowy.Item{
+				{ID: "7d4e5f60-0000-4000-8000-000000000008", Name: "Open the file"},
+				{ID: "7d4e5f60-0000-4000-8000-000000000009", Name: "Edit it"},
+			},
+		},
+		{
+			ID:   "7d4e5f60-0000-4000-8000-000000000010",
+			Name: "This is a paragraph",
+			Data: layout("p"),
+			Children: []*workflowy.Item{
+				{ID: "7d4e5f60-0000-4000-8000-000000000011", Name: "Child item 1"},
+				{ID: "7d4e5f60-0000-4000-8000-000000000012", Name: "Child item 2"},
+			},
+		},
+	}
+}
+
+// escaping are names with Workflowy formatting and markdown special characters.
+func escaping() []*workflowy.Item {
+	return []*workflowy.Item{
+		{
+			ID:   "8e5f6071-0000-4000-8000-000000000001",
+			Name: "<b>Fish</b> &amp; chips",
+			Children: []*workflowy.Item{
+				{ID: "8e5f6071-0000-4000-8000-000000000002", Name: "2 * 3 = 6 [approx]"},
+				{ID: "8e5f6071-0000-4000-8000-000000000003", Name: `See <a href="https://example.com">the <i>site</i></a>`},
+			},
+		},
+		{
+			ID:   "8e5f6071-0000-4000-8000-000000000004",
+			Name: "x &lt; y",
+			Data: layout("code"),
+		},
+	}
+}
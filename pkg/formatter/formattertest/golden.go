@@ -0,0 +1,54 @@
+package formattertest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// UpdateEnv is the environment variable that makes the golden helpers write
+// the golden files instead of comparing with them, when set to a non-empty value.
+const UpdateEnv = "WORKFLOWY_UPDATE_GOLDEN"
+
+// Update makes the golden helpers write the golden files instead of comparing
+// with them, e.g. from a test's own -update flag.
+var Update = os.Getenv(UpdateEnv) != ""
+
+// FormatFunc formats a tree, like the FormatTree method of the formatters.
+type FormatFunc func(items []*workflowy.Item) (string, error)
+
+// Golden compares got with the content of the golden file at path, or writes
+// got to it when updating.
+func Golden(t testing.TB, path, got string) {
+	t.Helper()
+	if Update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(got), 0644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "missing golden file: run with %s=1 to create it", UpdateEnv)
+	assert.Equal(t, string(want), got, "output differs from %s", path)
+}
+
+// GoldenFormat formats items and compares the output with the golden file at path.
+func GoldenFormat(t testing.TB, path string, items []*workflowy.Item, format FormatFunc) {
+	t.Helper()
+	got, err := format(items)
+	require.NoError(t, err)
+	Golden(t, path, got)
+}
+
+// GoldenFixtures formats each fixture, in a subtest named after it, and
+// compares the output with dir/<name>.golden.
+func GoldenFixtures(t *testing.T, dir string, format FormatFunc) {
+	t.Helper()
+	for _, fixture := range Fixtures() {
+		t.Run(fixture.Name, func(t *testing.T) {
+			GoldenFormat(t, filepath.Join(dir, fixture.Name+".golden"), fixture.Items, format)
+		})
+	}
+}
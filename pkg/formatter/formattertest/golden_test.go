@@ -0,0 +1,33 @@
+package formattertest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/formatter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoldenFixtures_DefaultMarkdown(t *testing.T) {
+	GoldenFixtures(t, filepath.Join("testdata", "markdown"), formatter.NewMarkdownFormatter().FormatTree)
+}
+
+func TestGoldenFixtures_Anchors(t *testing.T) {
+	config := formatter.DefaultMarkdownConfig()
+	config.Anchors = true
+	GoldenFixtures(t, filepath.Join("testdata", "markdown-anchors"), formatter.NewMarkdownFormatterWithConfig(config).FormatTree)
+}
+
+func TestFixtures(t *testing.T) {
+	names := make(map[string]bool)
+	for _, fixture := range Fixtures() {
+		assert.False(t, names[fixture.Name], "duplicate fixture %s", fixture.Name)
+		names[fixture.Name] = true
+		assert.NotEmpty(t, fixture.Items)
+	}
+
+	// each call returns new trees
+	FixtureNamed("paragraphs")[0].Name = "changed"
+	assert.Equal(t, "Item A", FixtureNamed("paragraphs")[0].Name)
+	assert.Nil(t, FixtureNamed("missing"))
+}
@@ -0,0 +1,134 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// DefaultLabelLength is the length at which graph labels are truncated.
+const DefaultLabelLength = 40
+
+// graphRootName labels the node standing for the whole outline, when the
+// graph starts from the top-level items.
+const graphRootName = "Workflowy"
+
+// graphNode is a node of a graph, numbered in outline order so that
+// definitions do not depend on the syntax of Workflowy IDs.
+type graphNode struct {
+	key    string // n0, n1, ...
+	item   *workflowy.Item
+	parent string // key of the parent, empty for the root
+}
+
+// graphNodes numbers root and its descendants, each following its parent.
+func graphNodes(root *workflowy.Item) []graphNode {
+	var nodes []graphNode
+	var walk func(item *workflowy.Item, parent string)
+	walk = func(item *workflowy.Item, parent string) {
+		key := fmt.Sprintf("n%d", len(nodes))
+		nodes = append(nodes, graphNode{key: key, item: item, parent: parent})
+		for _, child := range item.Children {
+			walk(child, key)
+		}
+	}
+	walk(root, "")
+	return nodes
+}
+
+// GraphRoot returns the root of the graph of items: the single item itself,
+// or a node standing for the outline above several top-level items.
+func GraphRoot(items []*workflowy.Item) *workflowy.Item {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return &workflowy.Item{Name: graphRootName, Children: items}
+}
+
+// TruncateLabel shortens name to at most length characters, ending with an
+// ellipsis when it is cut, and joins its lines. A length of zero or less
+// keeps the whole name.
+func TruncateLabel(name string, length int) string {
+	name = strings.Join(strings.Fields(name), " ")
+	runes := []rune(name)
+	if length <= 0 || len(runes) <= length {
+		return name
+	}
+	if length == 1 {
+		return "…"
+	}
+	return strings.TrimSpace(string(runes[:length-1])) + "…"
+}
+
+func itemURL(item *workflowy.Item) string {
+	if item.ID == "" {
+		return "https://workflowy.com/"
+	}
+	return "https://workflowy.com/#/" + item.ID
+}
+
+// FormatDOT renders root and its descendants as a Graphviz digraph. Labels
+// are truncated to labelLength characters; the tooltip of each node holds its
+// full name and its Workflowy URL, which it also links to.
+func FormatDOT(root *workflowy.Item, labelLength int) string {
+	var sb strings.Builder
+	sb.WriteString("digraph workflowy {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box, style=rounded];\n")
+	nodes := graphNodes(root)
+	for _, node := range nodes {
+		fmt.Fprintf(&sb, "  %s [label=%s, tooltip=%s, URL=%s];\n", node.key,
+			dotQuote(TruncateLabel(node.item.Name, labelLength)),
+			dotQuote(node.item.Name+"\n"+itemURL(node.item)),
+			dotQuote(itemURL(node.item)))
+	}
+	for _, node := range nodes {
+		if node.parent != "" {
+			fmt.Fprintf(&sb, "  %s -> %s;\n", node.parent, node.key)
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// dotQuote quotes s as a DOT string.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\r", "")
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// FormatMermaid renders root and its descendants as a Mermaid flowchart.
+// Labels are truncated to labelLength characters; clicking a node opens its
+// Workflowy URL, and its tooltip holds its full name.
+func FormatMermaid(root *workflowy.Item, labelLength int) string {
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	nodes := graphNodes(root)
+	for _, node := range nodes {
+		label := mermaidEscape(TruncateLabel(node.item.Name, labelLength))
+		if node.parent == "" {
+			fmt.Fprintf(&sb, "  %s[\"%s\"]\n", node.key, label)
+		} else {
+			fmt.Fprintf(&sb, "  %s --> %s[\"%s\"]\n", node.parent, node.key, label)
+		}
+	}
+	for _, node := range nodes {
+		tooltip := mermaidEscape(TruncateLabel(node.item.Name, 0))
+		fmt.Fprintf(&sb, "  click %s href \"%s\" \"%s\" _blank\n", node.key, itemURL(node.item), tooltip)
+	}
+	return sb.String()
+}
+
+// mermaidEscape replaces the characters that end a quoted Mermaid string or
+// that Mermaid reads as markup with entity codes.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(
+		`"`, "#quot;",
+		"<", "#lt;",
+		">", "#gt;",
+	).Replace(s)
+}
@@ -0,0 +1,66 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+)
+
+func graphFixture() *workflowy.Item {
+	return &workflowy.Item{
+		ID:   "root-id",
+		Name: `Project "Apollo" with a rather long name`,
+		Children: []*workflowy.Item{
+			{ID: "a", Name: "Design <b>review</b>"},
+			{ID: "b", Name: `Build\nphase`, Children: []*workflowy.Item{
+				{ID: "b1", Name: "line one\nline two"},
+			}},
+		},
+	}
+}
+
+func TestTruncateLabel(t *testing.T) {
+	assert.Equal(t, "short", TruncateLabel("short", 10))
+	assert.Equal(t, "a rather…", TruncateLabel("a rather long name", 10))
+	assert.Equal(t, "héllo wo…", TruncateLabel("héllo world", 9), "counts characters, not bytes")
+	assert.Equal(t, "two lines", TruncateLabel("two\nlines", 0))
+}
+
+func TestFormatDOT(t *testing.T) {
+	assert.Equal(t, `digraph workflowy {
+  rankdir=LR;
+  node [shape=box, style=rounded];
+  n0 [label="Project \"Apollo\" with a…", tooltip="Project \"Apollo\" with a rather long name\nhttps://workflowy.com/#/root-id", URL="https://workflowy.com/#/root-id"];
+  n1 [label="Design <b>review</b>", tooltip="Design <b>review</b>\nhttps://workflowy.com/#/a", URL="https://workflowy.com/#/a"];
+  n2 [label="Build\\nphase", tooltip="Build\\nphase\nhttps://workflowy.com/#/b", URL="https://workflowy.com/#/b"];
+  n3 [label="line one line two", tooltip="line one\nline two\nhttps://workflowy.com/#/b1", URL="https://workflowy.com/#/b1"];
+  n0 -> n1;
+  n0 -> n2;
+  n2 -> n3;
+}
+`, FormatDOT(graphFixture(), 25))
+}
+
+func TestFormatMermaid(t *testing.T) {
+	assert.Equal(t, `flowchart LR
+  n0["Project #quot;Apollo#quot; with a…"]
+  n0 --> n1["Design #lt;b#gt;review#lt;/b#gt;"]
+  n0 --> n2["Build\nphase"]
+  n2 --> n3["line one line two"]
+  click n0 href "https://workflowy.com/#/root-id" "Project #quot;Apollo#quot; with a rather long name" _blank
+  click n1 href "https://workflowy.com/#/a" "Design #lt;b#gt;review#lt;/b#gt;" _blank
+  click n2 href "https://workflowy.com/#/b" "Build\nphase" _blank
+  click n3 href "https://workflowy.com/#/b1" "line one line two" _blank
+`, FormatMermaid(graphFixture(), 25))
+}
+
+func TestGraphRoot(t *testing.T) {
+	single := &workflowy.Item{ID: "a"}
+	assert.Same(t, single, GraphRoot([]*workflowy.Item{single}))
+
+	root := GraphRoot([]*workflowy.Item{single, {ID: "b"}})
+	assert.Equal(t, "Workflowy", root.Name)
+	assert.Len(t, root.Children, 2)
+	assert.Contains(t, FormatDOT(root, 0), `URL="https://workflowy.com/"`)
+}
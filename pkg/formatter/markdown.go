@@ -3,6 +3,7 @@ package formatter
 import (
 	"strings"
 
+	"github.com/mholzen/workflowy/pkg/escape"
 	"github.com/mholzen/workflowy/pkg/workflowy"
 )
 
@@ -17,6 +18,14 @@ type MarkdownConfig struct {
 	H3Tag      string
 	PTag       string
 	ListTag    string
+
+	// Anchors emits an HTML anchor derived from the node ID before each header,
+	// so exported documents can be deep-linked.
+	Anchors bool
+
+	// Notes renders the note of each node: as a quote under headers and
+	// paragraphs, and as an indented block under list items.
+	Notes bool
 }
 
 func DefaultMarkdownConfig() *MarkdownConfig {
@@ -126,9 +135,11 @@ func (f *MarkdownFormatter) childrenAreAllSubheaders(children []*workflowy.Item)
 
 func (f *MarkdownFormatter) formatAsHeader(item *workflowy.Item, name string, level int) string {
 	var result strings.Builder
+	f.writeAnchor(&result, item)
 	result.WriteString(HeaderPrefix(level))
 	result.WriteString(Capitalize(name))
 	result.WriteString("\n\n")
+	result.WriteString(f.quoteNote(item))
 
 	for _, child := range item.Children {
 		childOutput := f.formatNode(child, level+1)
@@ -141,9 +152,11 @@ func (f *MarkdownFormatter) formatAsHeader(item *workflowy.Item, name string, le
 func (f *MarkdownFormatter) formatAsHeaderWithParagraph(item *workflowy.Item, name string, headerLevel int) string {
 	var result strings.Builder
 
+	f.writeAnchor(&result, item)
 	result.WriteString(HeaderPrefix(headerLevel))
 	result.WriteString(Capitalize(name))
 	result.WriteString("\n")
+	result.WriteString(f.quoteNote(item))
 
 	paragraphs := f.collectParagraphs(item.Children)
 	for _, para := range paragraphs {
@@ -196,6 +209,13 @@ func (f *MarkdownFormatter) collectParagraphs(children []*workflowy.Item) []stri
 		}
 
 		currentSentences = append(currentSentences, FormatAsSentence(childName))
+
+		// a note ends the paragraph of its node, as a quote of its own
+		if note := f.quoteNote(child); note != "" {
+			paragraphs = append(paragraphs, strings.Join(currentSentences, " "), strings.TrimRight(note, "\n"))
+			currentSentences = nil
+			needsBlankBefore = true
+		}
 	}
 
 	if len(currentSentences) > 0 {
@@ -226,6 +246,7 @@ func (f *MarkdownFormatter) formatInlineListWithIntro(item *workflowy.Item, intr
 			result.WriteString("- ")
 			result.WriteString(childName)
 			result.WriteString("\n")
+			result.WriteString(f.listNote(child, "  "))
 		}
 	}
 
@@ -235,9 +256,11 @@ func (f *MarkdownFormatter) formatInlineListWithIntro(item *workflowy.Item, intr
 func (f *MarkdownFormatter) formatAsHeaderWithSubheaders(item *workflowy.Item, name string, headerLevel int) string {
 	var result strings.Builder
 
+	f.writeAnchor(&result, item)
 	result.WriteString(HeaderPrefix(headerLevel))
 	result.WriteString(Capitalize(name))
 	result.WriteString("\n\n")
+	result.WriteString(f.quoteNote(item))
 
 	for _, child := range item.Children {
 		if f.shouldExclude(child) {
@@ -252,6 +275,7 @@ func (f *MarkdownFormatter) formatAsHeaderWithSubheaders(item *workflowy.Item, n
 		} else if !IsEmpty(childName) {
 			result.WriteString(FormatAsSentence(childName))
 			result.WriteString("\n\n")
+			result.WriteString(f.quoteNote(child))
 		}
 	}
 
@@ -261,9 +285,11 @@ func (f *MarkdownFormatter) formatAsHeaderWithSubheaders(item *workflowy.Item, n
 func (f *MarkdownFormatter) formatWithListChildren(item *workflowy.Item, name string, headerLevel int) string {
 	var result strings.Builder
 
+	f.writeAnchor(&result, item)
 	result.WriteString(HeaderPrefix(headerLevel))
 	result.WriteString(Capitalize(name))
 	result.WriteString("\n")
+	result.WriteString(f.quoteNote(item))
 
 	for _, child := range item.Children {
 		if f.shouldExclude(child) {
@@ -274,6 +300,7 @@ func (f *MarkdownFormatter) formatWithListChildren(item *workflowy.Item, name st
 			result.WriteString("- ")
 			result.WriteString(childName)
 			result.WriteString("\n")
+			result.WriteString(f.listNote(child, "  "))
 		}
 	}
 	result.WriteString("\n")
@@ -290,6 +317,7 @@ func (f *MarkdownFormatter) formatAsParagraphWithChildren(item *workflowy.Item,
 
 	result.WriteString(f.formatAsParagraph(name))
 	result.WriteString("\n\n")
+	result.WriteString(f.quoteNote(item))
 
 	if len(item.Children) > 0 {
 		for _, child := range item.Children {
@@ -301,6 +329,7 @@ func (f *MarkdownFormatter) formatAsParagraphWithChildren(item *workflowy.Item,
 				result.WriteString("- ")
 				result.WriteString(childName)
 				result.WriteString("\n")
+				result.WriteString(f.listNote(child, "  "))
 			}
 		}
 		result.WriteString("\n")
@@ -327,6 +356,7 @@ func (f *MarkdownFormatter) formatAsOrderedList(item *workflowy.Item, name strin
 			result.WriteString(string(rune('1'+i)) + ". ")
 			result.WriteString(childName)
 			result.WriteString("\n")
+			result.WriteString(f.listNote(child, "   "))
 		}
 	}
 	result.WriteString("\n")
@@ -359,9 +389,11 @@ func (f *MarkdownFormatter) formatAsQuote(item *workflowy.Item, name string) str
 	return result.String()
 }
 
-func (f *MarkdownFormatter) formatAsCode(item *workflowy.Item, name string) string {
+func (f *MarkdownFormatter) formatAsCode(item *workflowy.Item, _ string) string {
 	var result strings.Builder
 
+	// code blocks show plain text, without markdown escapes
+	name := escape.Text(f.stripLayoutTags(item.Name))
 	result.WriteString("```\n")
 	if name != "" {
 		result.WriteString(name)
@@ -372,7 +404,7 @@ func (f *MarkdownFormatter) formatAsCode(item *workflowy.Item, name string) stri
 		if f.shouldExclude(child) {
 			continue
 		}
-		childName := f.stripAllTags(child.Name)
+		childName := escape.Text(f.stripLayoutTags(child.Name))
 		result.WriteString(childName)
 		result.WriteString("\n")
 	}
@@ -411,7 +443,13 @@ func (f *MarkdownFormatter) getLayoutMode(item *workflowy.Item) string {
 	return "bullets"
 }
 
+// stripAllTags removes the layout tags from a name and converts it to markdown.
 func (f *MarkdownFormatter) stripAllTags(text string) string {
+	return escape.ToMarkdown(f.stripLayoutTags(text))
+}
+
+// stripLayoutTags removes the layout tags from a name.
+func (f *MarkdownFormatter) stripLayoutTags(text string) string {
 	text = StripTag(text, f.config.ExcludeTag)
 	text = StripTag(text, f.config.H1Tag)
 	text = StripTag(text, f.config.H2Tag)
@@ -421,8 +459,57 @@ func (f *MarkdownFormatter) stripAllTags(text string) string {
 	return strings.TrimSpace(text)
 }
 
+// quoteNote returns the note of item as a quote followed by a blank line, or ""
+// when notes are left out.
+func (f *MarkdownFormatter) quoteNote(item *workflowy.Item) string {
+	if !f.config.Notes {
+		return ""
+	}
+	if note := NoteBlock(item, "> "); note != "" {
+		return note + "\n"
+	}
+	return ""
+}
+
+// listNote returns the note of a list item indented to continue it, or "" when
+// notes are left out.
+func (f *MarkdownFormatter) listNote(item *workflowy.Item, indent string) string {
+	if !f.config.Notes {
+		return ""
+	}
+	return NoteBlock(item, indent)
+}
+
+// writeAnchor writes the anchor line for item when anchors are enabled.
+func (f *MarkdownFormatter) writeAnchor(result *strings.Builder, item *workflowy.Item) {
+	if !f.config.Anchors || item.ID == "" {
+		return
+	}
+	result.WriteString(`<a id="`)
+	result.WriteString(Anchor(item.ID))
+	result.WriteString(`"></a>`)
+	result.WriteString("\n")
+}
+
+// Anchor returns a stable anchor name for a node ID, based on its short ID.
+func Anchor(id string) string {
+	hex := strings.ReplaceAll(id, "-", "")
+	if len(hex) > 12 {
+		hex = hex[len(hex)-12:]
+	}
+	return "wf-" + strings.ToLower(hex)
+}
+
 func FormatItemsAsMarkdown(items []*workflowy.Item) (string, error) {
 	formatter := NewMarkdownFormatter()
 	return formatter.FormatTree(items)
 }
 
+// FormatItemsAsMarkdownWithAnchors formats items like FormatItemsAsMarkdown and
+// precedes each header with an anchor derived from the node ID.
+func FormatItemsAsMarkdownWithAnchors(items []*workflowy.Item) (string, error) {
+	config := DefaultMarkdownConfig()
+	config.Anchors = true
+	return NewMarkdownFormatterWithConfig(config).FormatTree(items)
+}
+
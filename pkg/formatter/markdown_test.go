@@ -349,6 +349,29 @@ func TestCodeLayoutMode(t *testing.T) {
 	assert.Contains(t, result, "return true")
 }
 
+func TestFormattingAndEscaping(t *testing.T) {
+	items := []*workflowy.Item{
+		{
+			Name: "<b>Fish</b> &amp; chips",
+			Children: []*workflowy.Item{
+				{Name: "2 * 3 = 6 [approx]"},
+			},
+		},
+		{
+			Name: "x &lt; y",
+			Data: map[string]interface{}{"layoutMode": "code"},
+		},
+	}
+
+	formatter := NewMarkdownFormatter()
+	result, err := formatter.FormatTree(items)
+
+	assert.NoError(t, err)
+	assert.Contains(t, result, "# **Fish** & chips\n")
+	assert.Contains(t, result, `2 \* 3 = 6 \[approx\]`)
+	assert.Contains(t, result, "```\nx < y\n```")
+}
+
 func TestWordCount(t *testing.T) {
 	assert.Equal(t, 0, WordCount(""))
 	assert.Equal(t, 0, WordCount("   "))
@@ -414,3 +437,32 @@ Now this would be a list.
 	assert.Equal(t, expected, result)
 }
 
+
+func TestAnchors(t *testing.T) {
+	items := []*workflowy.Item{
+		{
+			ID:   "3f2a9b1c-0d4e-4f5a-8b6c-7d8e9f0a1b2c",
+			Name: "Item A",
+			Children: []*workflowy.Item{
+				{ID: "11111111-2222-3333-4444-555555555555", Name: "Item A1"},
+			},
+		},
+	}
+
+	expected := `<a id="wf-7d8e9f0a1b2c"></a>
+# Item A
+Item A1.
+`
+	result, err := FormatItemsAsMarkdownWithAnchors(items)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	result, err = FormatItemsAsMarkdown(items)
+	assert.NoError(t, err)
+	assert.Equal(t, "# Item A\nItem A1.\n", result)
+}
+
+func TestAnchor(t *testing.T) {
+	assert.Equal(t, "wf-7d8e9f0a1b2c", Anchor("3f2a9b1c-0d4e-4f5a-8b6c-7d8e9f0a1b2c"))
+	assert.Equal(t, "wf-abc", Anchor("ABC"))
+}
@@ -0,0 +1,46 @@
+package formatter
+
+import (
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/escape"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// NoteText returns the text of the note of item, without formatting tags and
+// surrounding blank lines, or "" if it has none. Markdown typed in a note is
+// kept as it is, so that it renders as markdown.
+func NoteText(item *workflowy.Item) string {
+	if item.Note == nil {
+		return ""
+	}
+	note := strings.ReplaceAll(escape.Text(*item.Note), "\r\n", "\n")
+	return strings.Trim(strings.TrimRight(note, " \t\n"), "\n")
+}
+
+// NoteColumn returns the note of item on a single line, to follow its name as
+// a column: backslashes, newlines and tabs are written as \\, \n and \t.
+func NoteColumn(item *workflowy.Item) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\t", `\t`, "\r", "").Replace(NoteText(item))
+}
+
+// NoteBlock returns the note of item as lines starting with prefix, such as
+// "> " for a quote or spaces to continue a list item, or "" if it has none.
+// Blank lines of the note get the prefix without trailing spaces.
+func NoteBlock(item *workflowy.Item, prefix string) string {
+	note := NoteText(item)
+	if note == "" {
+		return ""
+	}
+	var result strings.Builder
+	for _, line := range strings.Split(note, "\n") {
+		if strings.TrimSpace(line) == "" {
+			result.WriteString(strings.TrimRight(prefix, " "))
+		} else {
+			result.WriteString(prefix)
+			result.WriteString(line)
+		}
+		result.WriteString("\n")
+	}
+	return result.String()
+}
@@ -0,0 +1,74 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noted(name, note string, children ...*workflowy.Item) *workflowy.Item {
+	return &workflowy.Item{Name: name, Note: &note, Children: children}
+}
+
+func TestNoteText(t *testing.T) {
+	assert.Equal(t, "", NoteText(&workflowy.Item{Name: "no note"}))
+	assert.Equal(t, "## Agenda\n\n- *first* & second", NoteText(noted("x", "\n## Agenda\r\n\n- *first* &amp; <b>second</b>\n\n")))
+}
+
+func TestNoteColumn(t *testing.T) {
+	assert.Equal(t, `line one\nline\ttwo \\ three`, NoteColumn(noted("x", "line one\nline\ttwo \\ three")))
+}
+
+func TestNoteBlock(t *testing.T) {
+	item := noted("x", "first\n\n```\ncode\n```")
+	assert.Equal(t, "> first\n>\n> ```\n> code\n> ```\n", NoteBlock(item, "> "))
+	assert.Equal(t, "  first\n\n  ```\n  code\n  ```\n", NoteBlock(item, "  "))
+	assert.Equal(t, "", NoteBlock(noted("x", "  \n"), "> "))
+}
+
+func TestMarkdownNotes(t *testing.T) {
+	items := []*workflowy.Item{
+		noted("Meeting", "Attendees:\n- Ann\n- Bob",
+			noted("Decisions:", "",
+				noted("Ship it", "by Friday\nafter review"),
+				&workflowy.Item{Name: "Hire Ann"},
+			),
+		),
+	}
+
+	config := DefaultMarkdownConfig()
+	config.Notes = true
+	output, err := NewMarkdownFormatterWithConfig(config).FormatTree(items)
+	require.NoError(t, err)
+	assert.Equal(t, `# Meeting
+> Attendees:
+> - Ann
+> - Bob
+
+Decisions:
+- Ship it
+  by Friday
+  after review
+- Hire Ann
+`, output)
+
+	output, err = FormatItemsAsMarkdown(items)
+	require.NoError(t, err)
+	assert.NotContains(t, output, "Attendees")
+}
+
+func TestMarkdownNotesInParagraphs(t *testing.T) {
+	items := []*workflowy.Item{
+		{Name: "Intro", Children: []*workflowy.Item{
+			noted("First point", "see the\nappendix"),
+			{Name: "Second point"},
+		}},
+	}
+	config := DefaultMarkdownConfig()
+	config.Notes = true
+	output, err := NewMarkdownFormatterWithConfig(config).FormatTree(items)
+	require.NoError(t, err)
+	assert.Equal(t, "# Intro\nFirst point.\n> see the\n> appendix\n\nSecond point.\n", output)
+}
@@ -0,0 +1,122 @@
+package formatter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// TableColumns are the columns of the csv and tsv formats, in their default order.
+var TableColumns = []string{"id", "name", "note", "parent_id", "depth", "created", "modified", "completed"}
+
+// Row is a node of a flattened tree, with its position in the tree.
+type Row struct {
+	Item     *workflowy.Item
+	ParentID string // empty for the top-level nodes
+	Depth    int    // 0 for the top-level nodes
+}
+
+// Rows flattens items and their descendants in outline order, each node
+// following its parent. Items are expected to be sorted.
+func Rows(items []*workflowy.Item) []Row {
+	var rows []Row
+	var walk func(items []*workflowy.Item, parentID string, depth int)
+	walk = func(items []*workflowy.Item, parentID string, depth int) {
+		for _, item := range items {
+			rows = append(rows, Row{Item: item, ParentID: parentID, Depth: depth})
+			walk(item.Children, item.ID, depth+1)
+		}
+	}
+	walk(items, "", 0)
+	return rows
+}
+
+// ParseColumns parses a comma-separated list of columns; an empty list
+// selects all of them.
+func ParseColumns(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return TableColumns, nil
+	}
+	var columns []string
+	for _, column := range strings.Split(s, ",") {
+		column = strings.TrimSpace(column)
+		if !slices.Contains(TableColumns, column) {
+			return nil, fmt.Errorf("unknown column %q (columns: %s)", column, strings.Join(TableColumns, ","))
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// TableSeparator returns the field separator of the csv or tsv format.
+func TableSeparator(format string) (rune, error) {
+	switch format {
+	case "csv":
+		return ',', nil
+	case "tsv":
+		return '\t', nil
+	}
+	return 0, fmt.Errorf("format must be 'csv' or 'tsv'")
+}
+
+// WriteTable writes a header and one record per row, with fields quoted as
+// RFC 4180 requires, so names and notes may hold separators, quotes and newlines.
+func WriteTable(w io.Writer, rows []Row, columns []string, separator rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = separator
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, column := range columns {
+			record[i] = row.field(column)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (row Row) field(column string) string {
+	item := row.Item
+	switch column {
+	case "id":
+		return item.ID
+	case "name":
+		return item.Name
+	case "note":
+		if item.Note == nil {
+			return ""
+		}
+		return *item.Note
+	case "parent_id":
+		return row.ParentID
+	case "depth":
+		return strconv.Itoa(row.Depth)
+	case "created":
+		return formatTimestamp(item.CreatedAt)
+	case "modified":
+		return formatTimestamp(item.ModifiedAt)
+	case "completed":
+		return strconv.FormatBool(item.IsCompleted())
+	}
+	return ""
+}
+
+// formatTimestamp formats Unix seconds as RFC 3339 in UTC, which spreadsheets
+// parse as dates; zero is left empty.
+func formatTimestamp(seconds int64) string {
+	if seconds == 0 {
+		return ""
+	}
+	return time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+}
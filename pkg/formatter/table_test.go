@@ -0,0 +1,101 @@
+package formatter
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tableFixture() []*workflowy.Item {
+	note := "first line\nsecond, with \"quotes\""
+	completedAt := int64(1700000200)
+	return []*workflowy.Item{
+		{
+			ID:         "a",
+			Name:       "Project, one",
+			Note:       &note,
+			CreatedAt:  1700000000,
+			ModifiedAt: 1700000100,
+			Children: []*workflowy.Item{
+				{ID: "a1", Name: "task\twith tab", CompletedAt: &completedAt},
+				{ID: "a2", Name: "task", Children: []*workflowy.Item{
+					{ID: "a21", Name: "subtask", Completed: true},
+				}},
+			},
+		},
+		{ID: "b", Name: "Other"},
+	}
+}
+
+func TestRows(t *testing.T) {
+	rows := Rows(tableFixture())
+
+	var got []string
+	for _, row := range rows {
+		got = append(got, row.Item.ID+"<"+row.ParentID)
+	}
+	assert.Equal(t, []string{"a<", "a1<a", "a2<a", "a21<a2", "b<"}, got)
+	assert.Equal(t, 2, rows[3].Depth)
+	assert.Equal(t, 0, rows[4].Depth)
+}
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns("")
+	require.NoError(t, err)
+	assert.Equal(t, TableColumns, columns)
+
+	columns, err = ParseColumns("name, depth,completed")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "depth", "completed"}, columns)
+
+	_, err = ParseColumns("name,size")
+	assert.ErrorContains(t, err, `unknown column "size"`)
+}
+
+func TestWriteTableCSV(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteTable(&sb, Rows(tableFixture()), TableColumns, ','))
+
+	records, err := csv.NewReader(strings.NewReader(sb.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+	assert.Equal(t, TableColumns, records[0])
+	assert.Equal(t, []string{
+		"a", "Project, one", "first line\nsecond, with \"quotes\"", "", "0",
+		"2023-11-14T22:13:20Z", "2023-11-14T22:15:00Z", "false",
+	}, records[1])
+	assert.Equal(t, []string{"a1", "task\twith tab", "", "a", "1", "", "", "true"}, records[2])
+	assert.Equal(t, "true", records[4][7], "completed from the export API's flag")
+}
+
+func TestWriteTableTSV(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteTable(&sb, Rows(tableFixture()), []string{"name", "depth"}, '\t'))
+
+	reader := csv.NewReader(strings.NewReader(sb.String()))
+	reader.Comma = '\t'
+	records, err := reader.ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"name", "depth"},
+		{"Project, one", "0"},
+		{"task\twith tab", "1"},
+		{"task", "1"},
+		{"subtask", "2"},
+		{"Other", "0"},
+	}, records)
+	assert.Contains(t, sb.String(), "\"task\twith tab\"\t1\n")
+}
+
+func TestTableSeparator(t *testing.T) {
+	separator, err := TableSeparator("tsv")
+	require.NoError(t, err)
+	assert.Equal(t, '\t', separator)
+
+	_, err = TableSeparator("markdown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateItem is the node given to output templates, once per node in
+// outline order:
+//
+//	{{indent .Depth}}- [{{.Name}}]({{.URL}}){{if .Completed}} ✓{{end}}
+type TemplateItem struct {
+	ID        string
+	Name      string
+	Note      string
+	URL       string
+	ParentID  string // empty for the top-level nodes
+	Depth     int    // 0 for the top-level nodes
+	Completed bool
+	Created   time.Time // zero if unknown
+	Modified  time.Time // zero if unknown
+}
+
+// templateFuncs are the functions available to output templates, besides the
+// builtins of text/template.
+var templateFuncs = template.FuncMap{
+	// indent returns two spaces per level of depth
+	"indent": func(depth int) string { return strings.Repeat("  ", max(depth, 0)) },
+	// repeat returns s count times
+	"repeat": func(count int, s string) string { return strings.Repeat(s, max(count, 0)) },
+	// truncate shortens s to length characters, as graph labels are
+	"truncate": func(length int, s string) string { return TruncateLabel(s, length) },
+	// json encodes v as JSON, for example to quote a name
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+// ParseTemplate parses an output template, with the functions indent, repeat,
+// truncate, json, upper, lower and trim.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// NewTemplateItem returns the template data of row.
+func NewTemplateItem(row Row) TemplateItem {
+	item := row.Item
+	data := TemplateItem{
+		ID:        item.ID,
+		Name:      item.Name,
+		URL:       "https://workflowy.com/#/" + item.ID,
+		ParentID:  row.ParentID,
+		Depth:     row.Depth,
+		Completed: item.IsCompleted(),
+	}
+	if item.Note != nil {
+		data.Note = *item.Note
+	}
+	if item.CreatedAt != 0 {
+		data.Created = time.Unix(item.CreatedAt, 0).UTC()
+	}
+	if item.ModifiedAt != 0 {
+		data.Modified = time.Unix(item.ModifiedAt, 0).UTC()
+	}
+	return data
+}
+
+// WriteTemplate executes tmpl once for each row.
+func WriteTemplate(w io.Writer, tmpl *template.Template, rows []Row) error {
+	for _, row := range rows {
+		if err := tmpl.Execute(w, NewTemplateItem(row)); err != nil {
+			return fmt.Errorf("cannot execute template: %w", err)
+		}
+	}
+	return nil
+}
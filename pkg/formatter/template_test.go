@@ -0,0 +1,50 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("outline", `{{indent .Depth}}- [{{.Name}}]({{.URL}}){{if .Completed}} ✓{{end}}{{with .Note}} {{json .}}{{end}}
+`)
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	require.NoError(t, WriteTemplate(&sb, tmpl, Rows(tableFixture())))
+	assert.Equal(t, `- [Project, one](https://workflowy.com/#/a) "first line\nsecond, with \"quotes\""
+  - [task	with tab](https://workflowy.com/#/a1) ✓
+  - [task](https://workflowy.com/#/a2)
+    - [subtask](https://workflowy.com/#/a21) ✓
+- [Other](https://workflowy.com/#/b)
+`, sb.String())
+}
+
+func TestTemplateItem(t *testing.T) {
+	rows := Rows(tableFixture())
+	item := NewTemplateItem(rows[0])
+	assert.Equal(t, "2023-11-14T22:13:20Z", item.Created.Format("2006-01-02T15:04:05Z07:00"))
+	assert.True(t, NewTemplateItem(rows[4]).Created.IsZero())
+	assert.Equal(t, "a2", NewTemplateItem(rows[3]).ParentID)
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	tmpl, err := ParseTemplate("funcs", `{{repeat .Depth "#"}} {{upper (truncate 6 .Name)}}|{{lower .ID}}|{{trim "  x "}}`)
+	require.NoError(t, err)
+	var sb strings.Builder
+	require.NoError(t, WriteTemplate(&sb, tmpl, Rows(tableFixture())[3:4]))
+	assert.Equal(t, "## SUBTA…|a21|x", sb.String())
+}
+
+func TestParseTemplateError(t *testing.T) {
+	_, err := ParseTemplate("broken", "{{.Name")
+	assert.ErrorContains(t, err, "cannot parse template")
+
+	tmpl, err := ParseTemplate("unknown", "{{.Size}}")
+	require.NoError(t, err)
+	err = WriteTemplate(&strings.Builder{}, tmpl, Rows(tableFixture()))
+	assert.ErrorContains(t, err, "cannot execute template")
+}
@@ -0,0 +1,174 @@
+// Package ingest creates nodes from external sources (feeds, issues, email) exactly once.
+package ingest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// Entry is an item produced by a source.
+type Entry struct {
+	// Key identifies the entry within its source (feed GUID, issue URL, message ID).
+	// When empty, the entry is identified by its content.
+	Key  string
+	Name string
+	Note string
+}
+
+// Hash returns the identity of the entry used for deduplication.
+func (e Entry) Hash() string {
+	h := sha256.New()
+	if e.Key != "" {
+		h.Write([]byte("key\x00" + e.Key))
+	} else {
+		h.Write([]byte("content\x00" + e.Name + "\x00" + e.Note))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Source lists the entries available for ingestion.
+type Source interface {
+	// Name identifies the source in the seen-state store, e.g. "rss:https://example.com/feed".
+	Name() string
+	// Entries returns the current entries, in the order they should be created.
+	Entries(ctx context.Context) ([]Entry, error)
+}
+
+// Creator is the subset of workflowy.Client needed to ingest entries.
+type Creator interface {
+	CreateNode(ctx context.Context, req *workflowy.CreateNodeRequest) (*workflowy.CreateNodeResponse, error)
+}
+
+// Options controls where and how entries are created.
+type Options struct {
+	ParentID string
+	Position string // "top" or "bottom" (default: API default)
+	DryRun   bool
+	// Collision is the policy for entries named like a child of ParentID (see
+	// workflowy.CollisionSkip); merging records the entry as ingested into
+	// the existing node.
+	Collision string
+	Existing  []*workflowy.Item // children of ParentID, checked for collisions
+}
+
+// Result records what happened to an entry.
+type Result struct {
+	Hash       string `json:"hash"`
+	Name       string `json:"name"`
+	ID         string `json:"id,omitempty"`
+	Created    bool   `json:"created"`
+	Merged     bool   `json:"merged,omitempty"` // recorded as ingested into the existing node of the same name
+	Skipped    bool   `json:"skipped,omitempty"`
+	SkipReason string `json:"skip_reason,omitempty"`
+}
+
+func (r Result) String() string {
+	switch {
+	case r.Created:
+		return fmt.Sprintf("created %s: %s", r.ID, r.Name)
+	case r.Merged:
+		return fmt.Sprintf("merged into %s: %s", r.ID, r.Name)
+	case r.Skipped:
+		return fmt.Sprintf("skipped: %s (%s)", r.Name, r.SkipReason)
+	default:
+		return fmt.Sprintf("would create: %s", r.Name)
+	}
+}
+
+// Run creates a node for each entry of src that is not yet recorded in store,
+// recording each created node. Entries already seen are skipped, so Run can be
+// repeated safely. Creation errors are reported per entry and do not stop the run.
+func Run(ctx context.Context, client Creator, src Source, store Store, opts Options) ([]Result, error) {
+	entries, err := src.Entries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list entries from %s: %w", src.Name(), err)
+	}
+
+	results := make([]Result, 0, len(entries))
+	batch := make(map[string]bool)
+	siblings := workflowy.NewSiblings(opts.Existing)
+	for _, entry := range entries {
+		result := Result{Hash: entry.Hash(), Name: entry.Name}
+		if ctx.Err() != nil {
+			result.Skipped = true
+			result.SkipReason = "cancelled"
+			results = append(results, result)
+			continue
+		}
+
+		seen, err := store.Seen(src.Name(), result.Hash)
+		if err != nil {
+			return results, fmt.Errorf("cannot read seen state: %w", err)
+		}
+		if seen || batch[result.Hash] {
+			result.Skipped = true
+			result.SkipReason = "already ingested"
+			results = append(results, result)
+			continue
+		}
+		batch[result.Hash] = true
+
+		name, existing := siblings.Resolve(entry.Name, opts.Collision)
+		if existing != nil {
+			if opts.Collision == workflowy.CollisionSkip {
+				result.Skipped = true
+				result.SkipReason = fmt.Sprintf("name taken by %s", existing.ID)
+				results = append(results, result)
+				continue
+			}
+			result.ID = existing.ID
+			result.Merged = true
+			if !opts.DryRun {
+				if err := store.Mark(src.Name(), result.Hash, existing.ID); err != nil {
+					results = append(results, result)
+					return results, fmt.Errorf("cannot record ingested entry: %w", err)
+				}
+			}
+			results = append(results, result)
+			continue
+		}
+		result.Name = name
+
+		if opts.DryRun {
+			results = append(results, result)
+			continue
+		}
+
+		req := &workflowy.CreateNodeRequest{
+			ParentID: opts.ParentID,
+			Name:     name,
+		}
+		if entry.Note != "" {
+			note := entry.Note
+			req.Note = &note
+		}
+		if opts.Position != "" {
+			position := opts.Position
+			req.Position = &position
+		}
+
+		resp, err := client.CreateNode(ctx, req)
+		if err != nil {
+			result.Skipped = true
+			result.SkipReason = fmt.Sprintf("create failed: %v", err)
+			results = append(results, result)
+			continue
+		}
+		result.ID = resp.ItemID
+		result.Created = true
+		siblings.Add(&workflowy.Item{ID: resp.ItemID, Name: name})
+
+		if err := store.Mark(src.Name(), result.Hash, resp.ItemID); err != nil {
+			results = append(results, result)
+			return results, fmt.Errorf("cannot record ingested entry: %w", err)
+		}
+		slog.Debug("ingested entry", "source", src.Name(), "id", resp.ItemID, "name", entry.Name)
+		results = append(results, result)
+	}
+	return results, nil
+}
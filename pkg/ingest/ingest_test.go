@@ -0,0 +1,144 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticSource struct {
+	entries []Entry
+}
+
+func (s staticSource) Name() string { return "static" }
+
+func (s staticSource) Entries(ctx context.Context) ([]Entry, error) {
+	return s.entries, nil
+}
+
+type fakeCreator struct {
+	created []*workflowy.CreateNodeRequest
+	failOn  string
+}
+
+func (c *fakeCreator) CreateNode(ctx context.Context, req *workflowy.CreateNodeRequest) (*workflowy.CreateNodeResponse, error) {
+	if req.Name == c.failOn {
+		return nil, fmt.Errorf("boom")
+	}
+	c.created = append(c.created, req)
+	return &workflowy.CreateNodeResponse{ItemID: fmt.Sprintf("node-%d", len(c.created))}, nil
+}
+
+func TestEntryHash(t *testing.T) {
+	assert.Equal(t, Entry{Key: "guid-1", Name: "a"}.Hash(), Entry{Key: "guid-1", Name: "b"}.Hash())
+	assert.NotEqual(t, Entry{Key: "guid-1"}.Hash(), Entry{Key: "guid-2"}.Hash())
+	assert.Equal(t, Entry{Name: "a", Note: "n"}.Hash(), Entry{Name: "a", Note: "n"}.Hash())
+	assert.NotEqual(t, Entry{Name: "a"}.Hash(), Entry{Name: "a", Note: "n"}.Hash())
+}
+
+func TestRun_Idempotent(t *testing.T) {
+	src := staticSource{entries: []Entry{
+		{Key: "1", Name: "first", Note: "body"},
+		{Key: "2", Name: "second"},
+		{Key: "1", Name: "first (duplicate)"},
+	}}
+	store := NewMemoryStore()
+	client := &fakeCreator{}
+	opts := Options{ParentID: "parent", Position: "top"}
+
+	results, err := Run(context.Background(), client, src, store, opts)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Created)
+	assert.True(t, results[1].Created)
+	assert.True(t, results[2].Skipped)
+	require.Len(t, client.created, 2)
+	assert.Equal(t, "parent", client.created[0].ParentID)
+	assert.Equal(t, "body", *client.created[0].Note)
+	assert.Equal(t, "top", *client.created[0].Position)
+
+	results, err = Run(context.Background(), client, src, store, opts)
+	require.NoError(t, err)
+	for _, result := range results {
+		assert.True(t, result.Skipped)
+	}
+	assert.Len(t, client.created, 2)
+}
+
+func TestRun_DryRunAndFailures(t *testing.T) {
+	src := staticSource{entries: []Entry{{Name: "ok"}, {Name: "bad"}}}
+	store := NewMemoryStore()
+	client := &fakeCreator{failOn: "bad"}
+
+	results, err := Run(context.Background(), client, src, store, Options{DryRun: true})
+	require.NoError(t, err)
+	assert.False(t, results[0].Created)
+	assert.False(t, results[0].Skipped)
+	assert.Empty(t, client.created)
+
+	results, err = Run(context.Background(), client, src, store, Options{})
+	require.NoError(t, err)
+	assert.True(t, results[0].Created)
+	assert.True(t, results[1].Skipped)
+	assert.Contains(t, results[1].SkipReason, "boom")
+
+	// failed entries are retried on the next run
+	client.failOn = ""
+	results, err = Run(context.Background(), client, src, store, Options{})
+	require.NoError(t, err)
+	assert.True(t, results[0].Skipped)
+	assert.True(t, results[1].Created)
+}
+
+func TestRun_Collision(t *testing.T) {
+	src := staticSource{entries: []Entry{{Key: "1", Name: "Weekly digest"}, {Key: "2", Name: "News"}}}
+	existing := []*workflowy.Item{{ID: "old", Name: "Weekly digest"}}
+
+	client := &fakeCreator{}
+	results, err := Run(context.Background(), client, src, NewMemoryStore(), Options{Collision: workflowy.CollisionSkip, Existing: existing})
+	require.NoError(t, err)
+	assert.True(t, results[0].Skipped)
+	assert.Equal(t, "name taken by old", results[0].SkipReason)
+	assert.True(t, results[1].Created)
+
+	client = &fakeCreator{}
+	_, err = Run(context.Background(), client, src, NewMemoryStore(), Options{Collision: workflowy.CollisionRename, Existing: existing})
+	require.NoError(t, err)
+	assert.Equal(t, "Weekly digest (2)", client.created[0].Name)
+
+	client = &fakeCreator{}
+	store := NewMemoryStore()
+	results, err = Run(context.Background(), client, src, store, Options{Collision: workflowy.CollisionMerge, Existing: existing})
+	require.NoError(t, err)
+	assert.True(t, results[0].Merged)
+	assert.Equal(t, "old", results[0].ID)
+	assert.Len(t, client.created, 1)
+	seen, err := store.Seen(src.Name(), results[0].Hash)
+	require.NoError(t, err)
+	assert.True(t, seen, "merged entries are not ingested again")
+}
+
+func TestFileStore_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	store, err := OpenFileStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Mark("rss", "abc", "node-1"))
+
+	reopened, err := OpenFileStore(path)
+	require.NoError(t, err)
+	seen, err := reopened.Seen("rss", "abc")
+	require.NoError(t, err)
+	assert.True(t, seen)
+	record, ok := reopened.Lookup("rss", "abc")
+	assert.True(t, ok)
+	assert.Equal(t, "node-1", record.NodeID)
+
+	seen, _ = reopened.Seen("github", "abc")
+	assert.False(t, seen)
+}
@@ -0,0 +1,132 @@
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/storage"
+)
+
+// DefaultStateFile is the name of the seen-state store in the cache directory
+const DefaultStateFile = "ingest-state.json"
+
+// Store records which entries have been ingested, per source.
+type Store interface {
+	Seen(source, hash string) (bool, error)
+	Mark(source, hash, nodeID string) error
+}
+
+// Record describes an ingested entry.
+type Record struct {
+	NodeID    string `json:"node_id"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// FileStore is a Store persisted as a JSON file of a storage. A store without
+// a storage keeps the state in memory.
+type FileStore struct {
+	mu      sync.Mutex
+	store   storage.Storage
+	key     string
+	sources map[string]map[string]Record
+}
+
+// NewMemoryStore returns a Store that is not persisted.
+func NewMemoryStore() *FileStore {
+	return &FileStore{sources: make(map[string]map[string]Record)}
+}
+
+// OpenFileStore loads the store at path, or starts an empty one if the file does not exist.
+func OpenFileStore(path string) (*FileStore, error) {
+	return openStore(storage.NewFileStorage(filepath.Dir(path)), filepath.Base(path))
+}
+
+// OpenDefaultFileStore opens the store in the storage (~/.workflowy/ingest-state.json by default).
+func OpenDefaultFileStore() (*FileStore, error) {
+	s, err := storage.Default()
+	if err != nil {
+		return nil, err
+	}
+	return openStore(s, DefaultStateFile)
+}
+
+func openStore(st storage.Storage, key string) (*FileStore, error) {
+	s := &FileStore{store: st, key: key, sources: make(map[string]map[string]Record)}
+	data, err := st.Get("", key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("cannot read ingest state: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.sources); err != nil {
+		return nil, fmt.Errorf("cannot parse ingest state: %w", err)
+	}
+	return s, nil
+}
+
+// Seen reports whether hash has been recorded for source.
+func (s *FileStore) Seen(source, hash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.sources[source][hash]
+	return ok, nil
+}
+
+// Lookup returns the record of an ingested entry.
+func (s *FileStore) Lookup(source, hash string) (Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.sources[source][hash]
+	return record, ok
+}
+
+// Mark records hash as ingested for source and saves the store.
+func (s *FileStore) Mark(source, hash, nodeID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sources[source] == nil {
+		s.sources[source] = make(map[string]Record)
+	}
+	s.sources[source][hash] = Record{NodeID: nodeID, Timestamp: time.Now().Unix()}
+	return s.save()
+}
+
+// save writes the store, with the entries other processes recorded since it
+// was loaded, so that concurrent ingestions do not lose each other's.
+func (s *FileStore) save() error {
+	if s.store == nil {
+		return nil
+	}
+	err := storage.Update(s.store, "", s.key, func(data []byte) ([]byte, error) {
+		if data != nil {
+			var saved map[string]map[string]Record
+			if err := json.Unmarshal(data, &saved); err != nil {
+				return nil, fmt.Errorf("cannot parse ingest state: %w", err)
+			}
+			for source, records := range saved {
+				if s.sources[source] == nil {
+					s.sources[source] = make(map[string]Record)
+				}
+				for hash, record := range records {
+					if _, ok := s.sources[source][hash]; !ok {
+						s.sources[source][hash] = record
+					}
+				}
+			}
+		}
+		data, err := json.MarshalIndent(s.sources, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode ingest state: %w", err)
+		}
+		return data, nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot write ingest state: %w", err)
+	}
+	return nil
+}
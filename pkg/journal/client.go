@@ -0,0 +1,281 @@
+package journal
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/client"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// Client is a workflowy.Client that records the writes it makes in a journal.
+// The previous values of a node are read from the API before it is written.
+// Where moved and deleted nodes were is looked up in the outline last exported
+// (the export or tree cache, never fetched for this) as kept up to date by the
+// writes of the run, and checked against the children of that parent, so that
+// an out-of-date cache leaves a location unknown rather than wrong.
+type Client struct {
+	workflowy.Client
+	journal *Journal
+	run     *run // the run of the writes whose context has none
+	now     func() time.Time
+	cached  func() (*workflowy.ExportNodesResponse, error) // reads the outline last exported
+
+	mu            sync.Mutex
+	parents       map[string]string // node ID -> parent ID, "None" for the top level
+	parentsRun    string            // the run the parents were loaded for
+	parentsLoaded bool              // whether loading them was attempted
+}
+
+// NewClient records the writes made through client in journal, as a run of command.
+func NewClient(client workflowy.Client, journal *Journal, command string) *Client {
+	return &Client{
+		Client:  client,
+		journal: journal,
+		run:     &run{id: NewRunID(), command: command},
+		now:     time.Now,
+		cached:  workflowy.ReadCachedExport,
+	}
+}
+
+// RateLimit reports the API quota of the wrapped client, when it reports one.
+func (c *Client) RateLimit() (client.RateLimit, bool) {
+	if reporter, ok := c.Client.(interface {
+		RateLimit() (client.RateLimit, bool)
+	}); ok {
+		return reporter.RateLimit()
+	}
+	return client.RateLimit{}, false
+}
+
+// CreateNode creates a node and records it, with its continuations.
+func (c *Client) CreateNode(ctx context.Context, req *workflowy.CreateNodeRequest) (*workflowy.CreateNodeResponse, error) {
+	resp, err := c.Client.CreateNode(ctx, req)
+	if resp == nil || resp.ItemID == "" {
+		return resp, err
+	}
+	c.setParent(ctx, resp.ItemID, req.ParentID)
+	changes := []Change{{Op: OpCreate, ID: resp.ItemID}}
+	for _, id := range resp.ContinuationIDs {
+		c.setParent(ctx, id, resp.ItemID)
+		changes = append(changes, Change{Op: OpCreate, ID: id})
+	}
+	c.record(ctx, changes...)
+	return resp, err
+}
+
+// UpdateNode updates a node and records the values it replaced.
+func (c *Client) UpdateNode(ctx context.Context, itemID string, req *workflowy.UpdateNodeRequest) (*workflowy.UpdateNodeResponse, error) {
+	previous, lookupErr := c.Client.GetItem(ctx, itemID)
+	resp, err := c.Client.UpdateNode(ctx, itemID, req)
+	if resp == nil {
+		return resp, err
+	}
+	if lookupErr != nil {
+		slog.WarnContext(ctx, "cannot journal update: previous values unknown", "id", itemID, "error", lookupErr)
+		return resp, err
+	}
+
+	change := Change{Op: OpUpdate, ID: itemID}
+	if req.Name != nil {
+		change.Name = &previous.Name
+	}
+	if req.Note != nil {
+		note := ""
+		if previous.Note != nil {
+			note = *previous.Note
+		}
+		change.Note = &note
+	}
+	if req.LayoutMode != nil {
+		mode, _ := previous.Data["layoutMode"].(string)
+		change.LayoutMode = &mode
+	}
+	changes := []Change{change}
+	for _, id := range resp.ContinuationIDs {
+		c.setParent(ctx, id, itemID)
+		changes = append(changes, Change{Op: OpCreate, ID: id})
+	}
+	c.record(ctx, changes...)
+	return resp, err
+}
+
+// MoveNode moves a node and records where it was.
+func (c *Client) MoveNode(ctx context.Context, itemID string, req *workflowy.MoveNodeRequest) (*workflowy.MoveNodeResponse, error) {
+	parentID, position := c.location(ctx, itemID)
+	resp, err := c.Client.MoveNode(ctx, itemID, req)
+	if err != nil {
+		return resp, err
+	}
+	c.setParent(ctx, itemID, req.ParentID)
+	if parentID == "" {
+		slog.WarnContext(ctx, "journaled move cannot be undone: previous parent unknown", "id", itemID)
+	}
+	c.record(ctx, Change{Op: OpMove, ID: itemID, ParentID: parentID, Position: position})
+	return resp, nil
+}
+
+// CompleteNode completes a node and records it, unless it was completed already.
+func (c *Client) CompleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error) {
+	return c.setCompletion(ctx, itemID, true)
+}
+
+// UncompleteNode uncompletes a node and records it, unless it was not completed.
+func (c *Client) UncompleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error) {
+	return c.setCompletion(ctx, itemID, false)
+}
+
+func (c *Client) setCompletion(ctx context.Context, itemID string, completed bool) (*workflowy.UpdateNodeResponse, error) {
+	// when the previous state is unknown, the change is recorded: undoing it is harmless
+	previous, lookupErr := c.Client.GetItem(ctx, itemID)
+
+	setCompletion, op := c.Client.UncompleteNode, OpUncomplete
+	if completed {
+		setCompletion, op = c.Client.CompleteNode, OpComplete
+	}
+	resp, err := setCompletion(ctx, itemID)
+	if err != nil {
+		return resp, err
+	}
+	if lookupErr == nil && previous.IsCompleted() == completed {
+		return resp, nil
+	}
+	c.record(ctx, Change{Op: op, ID: itemID})
+	return resp, nil
+}
+
+// DeleteNode deletes a node and records it, with its descendants and where it was.
+func (c *Client) DeleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error) {
+	parentID, position := c.location(ctx, itemID)
+	item := c.fetchSubtree(ctx, itemID)
+
+	resp, err := c.Client.DeleteNode(ctx, itemID)
+	if err != nil {
+		return resp, err
+	}
+	c.setParent(ctx, itemID, "")
+	if item == nil {
+		slog.WarnContext(ctx, "cannot journal deletion: content unknown", "id", itemID)
+		return resp, nil
+	}
+	c.record(ctx, Change{
+		Op:       OpDelete,
+		ID:       itemID,
+		ParentID: parentID,
+		Position: position,
+		Node:     newNode(item),
+	})
+	return resp, nil
+}
+
+func (c *Client) fetchSubtree(ctx context.Context, itemID string) *workflowy.Item {
+	item, err := c.Client.GetItem(ctx, itemID)
+	if err != nil {
+		return nil
+	}
+	children, err := c.Client.ListChildrenRecursive(ctx, itemID)
+	if err != nil {
+		return nil
+	}
+	item.Children = children.Items
+	return item
+}
+
+// record appends changes to the journal, failing to do so being only logged:
+// the writes were made.
+func (c *Client) record(ctx context.Context, changes ...Change) {
+	r := c.runOf(ctx)
+	now := c.now().Unix()
+	for i := range changes {
+		changes[i].Run = r.id
+		changes[i].Command = r.command
+		changes[i].Time = now
+		changes[i].Undoes = r.undoes
+	}
+	if err := c.journal.Append(changes...); err != nil {
+		slog.WarnContext(ctx, "cannot record changes in journal", "error", err)
+	}
+}
+
+func (c *Client) runOf(ctx context.Context) *run {
+	if r := runFrom(ctx); r != nil {
+		return r
+	}
+	return c.run
+}
+
+// location returns the parent of a node and "top" if it is its first child,
+// else "bottom"; an empty parent ID when the node is unknown. The parent comes
+// from the outline of the run and is checked against the API, which lists the
+// children of the parent in order.
+func (c *Client) location(ctx context.Context, id string) (string, string) {
+	parentID := c.parentOf(ctx, id)
+	if parentID == "" {
+		return "", ""
+	}
+	children, err := c.Client.ListChildren(ctx, parentID)
+	if err != nil {
+		slog.WarnContext(ctx, "cannot check where a journaled node is", "id", id, "parent_id", parentID, "error", err)
+		return "", ""
+	}
+	siblings := slices.Clone(children.Items)
+	slices.SortStableFunc(siblings, func(a, b *workflowy.Item) int { return a.Priority - b.Priority })
+	for i, sibling := range siblings {
+		if sibling.ID == id {
+			if i == 0 {
+				return parentID, "top"
+			}
+			return parentID, "bottom"
+		}
+	}
+	slog.DebugContext(ctx, "cached parent of a journaled node is out of date", "id", id, "parent_id", parentID)
+	return "", ""
+}
+
+// parentOf returns the parent of a node in the outline of the run of ctx, or
+// "" if it is unknown.
+func (c *Client) parentOf(ctx context.Context, id string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.runParents(ctx)[id]
+}
+
+// setParent records that a node is now under parentID, or gone when it is empty.
+func (c *Client) setParent(ctx context.Context, id, parentID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	parents := c.runParents(ctx)
+	if parentID == "" {
+		delete(parents, id)
+		return
+	}
+	parents[id] = parentID
+}
+
+// runParents returns the parents of the nodes as of the start of the run of
+// ctx, updated with its writes, reading the cached outline on first use.
+func (c *Client) runParents(ctx context.Context) map[string]string {
+	r := c.runOf(ctx)
+	if c.parentsRun != r.id {
+		c.parents, c.parentsRun, c.parentsLoaded = make(map[string]string), r.id, false
+	}
+	if c.parentsLoaded {
+		return c.parents
+	}
+	c.parentsLoaded = true
+	resp, err := c.cached()
+	if err != nil || resp == nil {
+		slog.DebugContext(ctx, "no cached outline to journal changes", "error", err)
+		return c.parents
+	}
+	for _, node := range resp.Nodes {
+		c.parents[node.ID] = "None"
+		if node.ParentID != nil {
+			c.parents[node.ID] = *node.ParentID
+		}
+	}
+	return c.parents
+}
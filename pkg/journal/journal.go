@@ -0,0 +1,221 @@
+// Package journal records the writes made to Workflowy, with the values they
+// replaced, so that the commands making them can be undone.
+package journal
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/storage"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// FileName is the name of the journal in the cache directory
+const FileName = "journal.jsonl"
+
+// Kinds of Change
+const (
+	OpCreate     = "create"
+	OpUpdate     = "update"
+	OpMove       = "move"
+	OpComplete   = "complete"
+	OpUncomplete = "uncomplete"
+	OpDelete     = "delete"
+)
+
+// Change is a write applied to a node, recorded with what is needed to
+// revert it.
+type Change struct {
+	Run     string `json:"run"`     // the command invocation or tool call that made the change
+	Command string `json:"command"` // its name
+	Time    int64  `json:"time"`
+	Op      string `json:"op"`
+	ID      string `json:"id"` // the node created, updated, moved, (un)completed or deleted
+
+	// where a moved or deleted node was: its parent ("None" for the top
+	// level) and "top" if it was the first child, else "bottom"
+	ParentID string `json:"parent_id,omitempty"`
+	Position string `json:"position,omitempty"`
+
+	// previous values of the fields set by an update
+	Name       *string `json:"name,omitempty"`
+	Note       *string `json:"note,omitempty"`
+	LayoutMode *string `json:"layout_mode,omitempty"`
+
+	Node *Node `json:"node,omitempty"` // a deleted node, with its descendants
+
+	Undoes string `json:"undoes,omitempty"` // the run a change made by undo reverts
+}
+
+// Node is a deleted node. Its ID and those of its descendants let the changes
+// made to them before the deletion be undone once it is.
+type Node struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Note       string  `json:"note,omitempty"`
+	LayoutMode string  `json:"layout_mode,omitempty"`
+	Completed  bool    `json:"completed,omitempty"`
+	Children   []*Node `json:"children,omitempty"`
+}
+
+func newNode(item *workflowy.Item) *Node {
+	node := &Node{ID: item.ID, Name: item.Name, Completed: item.IsCompleted()}
+	if item.Note != nil {
+		node.Note = *item.Note
+	}
+	if mode, ok := item.Data["layoutMode"].(string); ok {
+		node.LayoutMode = mode
+	}
+	for _, child := range item.Children {
+		node.Children = append(node.Children, newNode(child))
+	}
+	return node
+}
+
+// Journal is an append-only file of changes, one JSON object per line.
+type Journal struct {
+	mu    sync.Mutex
+	store storage.Storage
+	key   string
+}
+
+// Open returns the journal at path, created on the first change.
+func Open(path string) *Journal {
+	return &Journal{store: storage.NewFileStorage(filepath.Dir(path)), key: filepath.Base(path)}
+}
+
+// OpenDefault returns the journal in the storage (~/.workflowy/journal.jsonl by default).
+func OpenDefault() (*Journal, error) {
+	s, err := storage.Default()
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{store: s, key: FileName}, nil
+}
+
+// Path returns the file of the journal.
+func (j *Journal) Path() string {
+	return j.store.Location("", j.key)
+}
+
+// Append adds changes at the end of the journal.
+func (j *Journal) Append(changes ...Change) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, change := range changes {
+		if err := encoder.Encode(change); err != nil {
+			return fmt.Errorf("cannot write journal: %w", err)
+		}
+	}
+	// deleted content ends up in the journal: storages keep it private
+	if err := storage.Append(j.store, "", j.key, buf.Bytes()); err != nil {
+		return fmt.Errorf("cannot write journal: %w", err)
+	}
+	return nil
+}
+
+// Read returns the changes in the journal, oldest first. A missing journal
+// has no changes.
+func (j *Journal) Read() ([]Change, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	data, err := j.store.Get("", j.key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot open journal: %w", err)
+	}
+
+	var changes []Change
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var change Change
+		err := decoder.Decode(&change)
+		if errors.Is(err, io.EOF) {
+			return changes, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse journal %s: %w", j.Path(), err)
+		}
+		changes = append(changes, change)
+	}
+}
+
+// Run is the changes made by one command invocation or tool call, oldest first.
+type Run struct {
+	ID      string   `json:"run"`
+	Command string   `json:"command"`
+	Time    int64    `json:"time"`
+	Changes []Change `json:"changes"`
+}
+
+// Runs groups changes by run, newest run first, leaving out the runs that
+// were undone and those made by undo.
+func Runs(changes []Change) []Run {
+	undone := make(map[string]bool)
+	for _, change := range changes {
+		if change.Undoes != "" {
+			undone[change.Run] = true
+			undone[change.Undoes] = true
+		}
+	}
+
+	var runs []Run
+	index := make(map[string]int)
+	for _, change := range changes {
+		if undone[change.Run] {
+			continue
+		}
+		i, ok := index[change.Run]
+		if !ok {
+			i = len(runs)
+			index[change.Run] = i
+			runs = append(runs, Run{ID: change.Run, Command: change.Command, Time: change.Time})
+		}
+		runs[i].Changes = append(runs[i].Changes, change)
+	}
+	for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+		runs[i], runs[j] = runs[j], runs[i]
+	}
+	return runs
+}
+
+// NewRunID returns a random identifier for a run.
+func NewRunID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+type runKey struct{}
+
+type run struct {
+	id      string
+	command string
+	undoes  string
+}
+
+// WithRun makes the changes recorded with ctx a new run of command, for
+// clients shared by several commands, such as the MCP server's.
+func WithRun(ctx context.Context, command string) context.Context {
+	return context.WithValue(ctx, runKey{}, &run{id: NewRunID(), command: command})
+}
+
+func runFrom(ctx context.Context) *run {
+	r, _ := ctx.Value(runKey{}).(*run)
+	return r
+}
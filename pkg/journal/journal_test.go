@@ -0,0 +1,362 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOutline is an outline kept in memory by the writes made to it.
+type fakeOutline struct {
+	workflowy.Client
+	items    map[string]*workflowy.Item
+	parents  map[string]string
+	children map[string][]string
+	created  int
+}
+
+func newFakeOutline() *fakeOutline {
+	return &fakeOutline{
+		items:    make(map[string]*workflowy.Item),
+		parents:  make(map[string]string),
+		children: make(map[string][]string),
+	}
+}
+
+func (f *fakeOutline) add(id, parentID, name string) {
+	f.items[id] = &workflowy.Item{ID: id, Name: name}
+	f.parents[id] = parentID
+	f.children[parentID] = append(f.children[parentID], id)
+}
+
+func (f *fakeOutline) place(id, parentID string, position *string) {
+	if position != nil && *position == "top" {
+		f.children[parentID] = append([]string{id}, f.children[parentID]...)
+	} else {
+		f.children[parentID] = append(f.children[parentID], id)
+	}
+	f.parents[id] = parentID
+}
+
+func (f *fakeOutline) unplace(id string) {
+	parentID := f.parents[id]
+	f.children[parentID] = slices.DeleteFunc(f.children[parentID], func(child string) bool { return child == id })
+	delete(f.parents, id)
+}
+
+// render lists the outline, one indented line per node.
+func (f *fakeOutline) render() string {
+	var b strings.Builder
+	var visit func(parentID string, depth int)
+	visit = func(parentID string, depth int) {
+		for _, id := range f.children[parentID] {
+			item := f.items[id]
+			fmt.Fprintf(&b, "%s%s", strings.Repeat("  ", depth), item.Name)
+			if item.Note != nil && *item.Note != "" {
+				fmt.Fprintf(&b, " (%s)", *item.Note)
+			}
+			if item.Completed {
+				b.WriteString(" [x]")
+			}
+			b.WriteString("\n")
+			visit(id, depth+1)
+		}
+	}
+	visit("None", 0)
+	return b.String()
+}
+
+func (f *fakeOutline) GetItem(ctx context.Context, itemID string) (*workflowy.Item, error) {
+	item, ok := f.items[itemID]
+	if _, placed := f.parents[itemID]; !ok || !placed {
+		return nil, fmt.Errorf("item %s not found", itemID)
+	}
+	copied := *item
+	return &copied, nil
+}
+
+func (f *fakeOutline) ListChildren(ctx context.Context, itemID string) (*workflowy.ListChildrenResponse, error) {
+	resp := &workflowy.ListChildrenResponse{}
+	for i, id := range f.children[itemID] {
+		item := *f.items[id]
+		item.Priority = i
+		resp.Items = append(resp.Items, &item)
+	}
+	return resp, nil
+}
+
+func (f *fakeOutline) ListChildrenRecursive(ctx context.Context, itemID string) (*workflowy.ListChildrenResponse, error) {
+	resp, _ := f.ListChildren(ctx, itemID)
+	for _, item := range resp.Items {
+		children, _ := f.ListChildrenRecursive(ctx, item.ID)
+		item.Children = children.Items
+	}
+	return resp, nil
+}
+
+// export is the outline as the export API returns it, for the cached outline.
+// The wrapped client is never asked to export.
+func (f *fakeOutline) export() (*workflowy.ExportNodesResponse, error) {
+	resp := &workflowy.ExportNodesResponse{}
+	for id, parentID := range f.parents {
+		item := f.items[id]
+		node := workflowy.ExportNode{ID: id, Name: item.Name, Note: item.Note, Completed: item.Completed,
+			Priority: slices.Index(f.children[parentID], id)}
+		if parentID != "None" {
+			node.ParentID = &parentID
+		}
+		resp.Nodes = append(resp.Nodes, node)
+	}
+	return resp, nil
+}
+
+func (f *fakeOutline) CreateNode(ctx context.Context, req *workflowy.CreateNodeRequest) (*workflowy.CreateNodeResponse, error) {
+	f.created++
+	id := fmt.Sprintf("new%d", f.created)
+	f.items[id] = &workflowy.Item{ID: id, Name: req.Name, Note: req.Note}
+	f.place(id, req.ParentID, req.Position)
+	return &workflowy.CreateNodeResponse{ItemID: id}, nil
+}
+
+func (f *fakeOutline) UpdateNode(ctx context.Context, itemID string, req *workflowy.UpdateNodeRequest) (*workflowy.UpdateNodeResponse, error) {
+	if _, err := f.GetItem(ctx, itemID); err != nil {
+		return nil, err
+	}
+	item := f.items[itemID]
+	if req.Name != nil {
+		item.Name = *req.Name
+	}
+	if req.Note != nil {
+		note := *req.Note
+		item.Note = &note
+	}
+	return &workflowy.UpdateNodeResponse{}, nil
+}
+
+func (f *fakeOutline) MoveNode(ctx context.Context, itemID string, req *workflowy.MoveNodeRequest) (*workflowy.MoveNodeResponse, error) {
+	f.unplace(itemID)
+	position := "top"
+	if req.Position != nil {
+		position = *req.Position
+	}
+	f.place(itemID, req.ParentID, &position)
+	return &workflowy.MoveNodeResponse{}, nil
+}
+
+func (f *fakeOutline) CompleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error) {
+	if _, err := f.GetItem(ctx, itemID); err != nil {
+		return nil, err
+	}
+	f.items[itemID].Completed = true
+	return &workflowy.UpdateNodeResponse{}, nil
+}
+
+func (f *fakeOutline) UncompleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error) {
+	if _, err := f.GetItem(ctx, itemID); err != nil {
+		return nil, err
+	}
+	f.items[itemID].Completed = false
+	return &workflowy.UpdateNodeResponse{}, nil
+}
+
+func (f *fakeOutline) DeleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error) {
+	if _, ok := f.parents[itemID]; !ok {
+		return nil, fmt.Errorf("item %s not found", itemID)
+	}
+	var forget func(id string)
+	forget = func(id string) {
+		for _, child := range f.children[id] {
+			forget(child)
+			delete(f.parents, child)
+		}
+	}
+	forget(itemID)
+	f.unplace(itemID)
+	return &workflowy.UpdateNodeResponse{}, nil
+}
+
+func sampleOutline() *fakeOutline {
+	f := newFakeOutline()
+	f.add("p", "None", "Projects")
+	f.add("a", "p", "Alpha")
+	f.add("a1", "a", "Plan")
+	f.add("a2", "a", "Build")
+	f.add("b", "p", "Beta")
+	f.add("i", "None", "Inbox")
+	return f
+}
+
+// newTestClient journals the writes to fake, with the current outline cached.
+func newTestClient(fake *fakeOutline, journal *Journal, command string) *Client {
+	client := NewClient(fake, journal, command)
+	client.cached = fake.export
+	return client
+}
+
+func TestClient_UndoRestoresOutline(t *testing.T) {
+	ctx := context.Background()
+	fake := sampleOutline()
+	before := fake.render()
+	journal := Open(filepath.Join(t.TempDir(), FileName))
+
+	client := newTestClient(fake, journal, "test")
+	name, note := "Alpha v2", "renamed"
+	_, err := client.UpdateNode(ctx, "a", &workflowy.UpdateNodeRequest{Name: &name, Note: &note})
+	require.NoError(t, err)
+	_, err = client.CreateNode(ctx, &workflowy.CreateNodeRequest{ParentID: "b", Name: "Launch"})
+	require.NoError(t, err)
+	_, err = client.MoveNode(ctx, "b", &workflowy.MoveNodeRequest{ParentID: "i"})
+	require.NoError(t, err)
+	_, err = client.CompleteNode(ctx, "a2")
+	require.NoError(t, err)
+	_, err = client.UpdateNode(ctx, "a1", &workflowy.UpdateNodeRequest{Name: &name})
+	require.NoError(t, err)
+	_, err = client.DeleteNode(ctx, "a")
+	require.NoError(t, err)
+	require.NotEqual(t, before, fake.render())
+
+	changes, err := journal.Read()
+	require.NoError(t, err)
+	runs := Runs(changes)
+	require.Len(t, runs, 1)
+	assert.Len(t, runs[0].Changes, 6)
+
+	result := Undo(ctx, newTestClient(fake, journal, "undo"), runs[0], nil)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, 6, result.Reverted)
+	assert.Equal(t, before, fake.render())
+
+	// the run is undone, and undoing is not itself undone
+	changes, err = journal.Read()
+	require.NoError(t, err)
+	assert.Empty(t, Runs(changes))
+}
+
+// refusingGuard refuses writes to, and under, the nodes it holds.
+type refusingGuard map[string]bool
+
+func (g refusingGuard) refuse(id, operation string) error {
+	if g[id] {
+		return fmt.Errorf("%s denied: %s is locked", operation, id)
+	}
+	return nil
+}
+
+func (g refusingGuard) ValidateTarget(id, operation string) error  { return g.refuse(id, operation) }
+func (g refusingGuard) ValidateSubtree(id, operation string) error { return g.refuse(id, operation) }
+func (g refusingGuard) ValidateParent(id, operation string) error  { return g.refuse(id, operation) }
+
+func TestUndo_SkipsChangesTheGuardRefuses(t *testing.T) {
+	ctx := context.Background()
+	fake := sampleOutline()
+	journal := Open(filepath.Join(t.TempDir(), FileName))
+
+	client := newTestClient(fake, journal, "test")
+	name := "Beta v2"
+	_, err := client.UpdateNode(ctx, "b", &workflowy.UpdateNodeRequest{Name: &name})
+	require.NoError(t, err)
+	_, err = client.MoveNode(ctx, "a2", &workflowy.MoveNodeRequest{ParentID: "i"})
+	require.NoError(t, err)
+	changes, err := journal.Read()
+	require.NoError(t, err)
+
+	result := Undo(ctx, newTestClient(fake, journal, "undo"), Runs(changes)[0], refusingGuard{"a": true})
+	assert.Equal(t, 1, result.Reverted)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0], "refused to revert move a2")
+	assert.Equal(t, "Beta", fake.items["b"].Name)
+	assert.Equal(t, "i", fake.parents["a2"])
+}
+
+func TestClient_SkipsCompletionWithoutEffect(t *testing.T) {
+	ctx := context.Background()
+	fake := sampleOutline()
+	fake.items["a1"].Completed = true
+	journal := Open(filepath.Join(t.TempDir(), FileName))
+
+	client := newTestClient(fake, journal, "complete")
+	_, err := client.CompleteNode(ctx, "a1")
+	require.NoError(t, err)
+	_, err = client.CompleteNode(ctx, "a2")
+	require.NoError(t, err)
+
+	changes, err := journal.Read()
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, Change{Run: changes[0].Run, Command: "complete", Time: changes[0].Time, Op: OpComplete, ID: "a2"}, changes[0])
+}
+
+func TestClient_DoesNotTrustAnOutOfDateCache(t *testing.T) {
+	ctx := context.Background()
+	fake := sampleOutline()
+	journal := Open(filepath.Join(t.TempDir(), FileName))
+	client := newTestClient(fake, journal, "move")
+	cached, err := fake.export()
+	require.NoError(t, err)
+	client.cached = func() (*workflowy.ExportNodesResponse, error) { return cached, nil }
+
+	// moved by someone else since the outline was cached
+	fake.unplace("b")
+	fake.place("b", "i", nil)
+	_, err = client.MoveNode(ctx, "b", &workflowy.MoveNodeRequest{ParentID: "a"})
+	require.NoError(t, err)
+	// moved by the run, which knows where it put it
+	_, err = client.MoveNode(ctx, "b", &workflowy.MoveNodeRequest{ParentID: "p"})
+	require.NoError(t, err)
+
+	changes, err := journal.Read()
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Empty(t, changes[0].ParentID, "the cache places b under p")
+	assert.Equal(t, "a", changes[1].ParentID)
+	assert.Equal(t, "top", changes[1].Position)
+}
+
+func TestClient_CompletionFromTheAPI(t *testing.T) {
+	ctx := context.Background()
+	fake := sampleOutline()
+	journal := Open(filepath.Join(t.TempDir(), FileName))
+	client := newTestClient(fake, journal, "uncomplete")
+	client.cached = func() (*workflowy.ExportNodesResponse, error) { return &workflowy.ExportNodesResponse{}, nil }
+
+	// completed since the outline was cached
+	fake.items["a1"].Completed = true
+	_, err := client.UncompleteNode(ctx, "a1")
+	require.NoError(t, err)
+
+	changes, err := journal.Read()
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, OpUncomplete, changes[0].Op)
+}
+
+func TestRuns(t *testing.T) {
+	changes := []Change{
+		{Run: "1", Command: "delete", Op: OpDelete, ID: "a"},
+		{Run: "2", Command: "replace", Op: OpUpdate, ID: "b"},
+		{Run: "2", Command: "replace", Op: OpUpdate, ID: "c"},
+		{Run: "3", Command: "undo", Op: OpCreate, ID: "a", Undoes: "1"},
+		{Run: "4", Command: "move", Op: OpMove, ID: "d"},
+	}
+
+	runs := Runs(changes)
+
+	require.Len(t, runs, 2)
+	assert.Equal(t, "4", runs[0].ID)
+	assert.Equal(t, "2", runs[1].ID)
+	assert.Equal(t, "replace", runs[1].Command)
+	assert.Len(t, runs[1].Changes, 2)
+}
+
+func TestJournal_ReadMissing(t *testing.T) {
+	changes, err := Open(filepath.Join(t.TempDir(), FileName)).Read()
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
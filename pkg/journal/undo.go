@@ -0,0 +1,165 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+func (c Change) String() string {
+	switch {
+	case c.Op == OpDelete && c.Node != nil:
+		return fmt.Sprintf("%s %s \"%s\"", c.Op, c.ID, c.Node.Name)
+	case c.Op == OpUpdate && c.Name != nil:
+		return fmt.Sprintf("%s %s (was \"%s\")", c.Op, c.ID, *c.Name)
+	}
+	return fmt.Sprintf("%s %s", c.Op, c.ID)
+}
+
+// UndoResult reports the reverting of a run.
+type UndoResult struct {
+	Run      string   `json:"run"`
+	Command  string   `json:"command"`
+	Changes  int      `json:"changes"`
+	Reverted int      `json:"reverted"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// Guard decides whether the nodes a revert writes to may be written, such as
+// the write root and lock checks of the CLI.
+type Guard interface {
+	ValidateTarget(targetID, operation string) error
+	ValidateSubtree(targetID, operation string) error
+	ValidateParent(parentID, operation string) error
+}
+
+// Undo reverts the changes of a run, newest first, through client, which
+// records the writes as undoing the run. A change that guard refuses, or that
+// cannot be reverted, is reported and the others are still reverted; a nil
+// guard allows every change. Deleted nodes are recreated, with new IDs; the
+// API only places nodes first or last among their siblings, so moved and
+// deleted nodes go back first if they were, last otherwise.
+func Undo(ctx context.Context, client *Client, undone Run, guard Guard) *UndoResult {
+	ctx = context.WithValue(ctx, runKey{}, &run{id: NewRunID(), command: "undo", undoes: undone.ID})
+	result := &UndoResult{Run: undone.ID, Command: undone.Command, Changes: len(undone.Changes)}
+	recreated := make(map[string]string) // ID of a deleted node -> ID of its copy
+	for i := len(undone.Changes) - 1; i >= 0; i-- {
+		change := undone.Changes[i]
+		if guard != nil {
+			if err := check(guard, change, recreated); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("refused to revert %s: %v", change, err))
+				continue
+			}
+		}
+		if id, ok := recreated[change.ID]; ok {
+			change.ID = id
+		}
+		if id, ok := recreated[change.ParentID]; ok {
+			change.ParentID = id
+		}
+		if err := revert(ctx, client, change, recreated); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("cannot revert %s: %v", change, err))
+			continue
+		}
+		result.Reverted++
+	}
+	return result
+}
+
+// check asks guard whether the revert of change may write to its nodes. Nodes
+// recreated by the undo are inside a parent guard allowed, and unknown to it.
+func check(guard Guard, change Change, recreated map[string]string) error {
+	_, targetRecreated := recreated[change.ID]
+	_, parentRecreated := recreated[change.ParentID]
+	switch change.Op {
+	case OpCreate:
+		if !targetRecreated {
+			return guard.ValidateSubtree(change.ID, "delete")
+		}
+	case OpUpdate, OpComplete, OpUncomplete:
+		if !targetRecreated {
+			return guard.ValidateTarget(change.ID, change.Op)
+		}
+	case OpMove:
+		if !targetRecreated {
+			if err := guard.ValidateTarget(change.ID, "move"); err != nil {
+				return err
+			}
+		}
+		if !parentRecreated && change.ParentID != "" {
+			return guard.ValidateParent(change.ParentID, "move destination")
+		}
+	case OpDelete:
+		if !parentRecreated && change.ParentID != "" {
+			return guard.ValidateParent(change.ParentID, "create")
+		}
+	}
+	return nil
+}
+
+func revert(ctx context.Context, client workflowy.Client, change Change, recreated map[string]string) error {
+	var err error
+	switch change.Op {
+	case OpCreate:
+		_, err = client.DeleteNode(ctx, change.ID)
+	case OpUpdate:
+		_, err = client.UpdateNode(ctx, change.ID, &workflowy.UpdateNodeRequest{
+			Name:       change.Name,
+			Note:       change.Note,
+			LayoutMode: change.LayoutMode,
+		})
+	case OpMove:
+		if change.ParentID == "" {
+			return fmt.Errorf("previous parent unknown")
+		}
+		req := &workflowy.MoveNodeRequest{ParentID: change.ParentID}
+		if err := req.SetPosition(change.Position); err != nil {
+			return err
+		}
+		_, err = client.MoveNode(ctx, change.ID, req)
+	case OpComplete:
+		_, err = client.UncompleteNode(ctx, change.ID)
+	case OpUncomplete:
+		_, err = client.CompleteNode(ctx, change.ID)
+	case OpDelete:
+		if change.Node == nil || change.ParentID == "" {
+			return fmt.Errorf("previous content or parent unknown")
+		}
+		return restore(ctx, client, change.Node, change.ParentID, change.Position, recreated)
+	default:
+		return fmt.Errorf("unknown operation %q", change.Op)
+	}
+	return err
+}
+
+// restore creates node and its descendants under parentID, recording the IDs
+// of the copies in recreated.
+func restore(ctx context.Context, client workflowy.Client, node *Node, parentID, position string, recreated map[string]string) error {
+	req := &workflowy.CreateNodeRequest{ParentID: parentID, Name: node.Name}
+	if node.Note != "" {
+		req.Note = &node.Note
+	}
+	if node.LayoutMode != "" {
+		req.LayoutMode = &node.LayoutMode
+	}
+	if err := req.SetPosition(position); err != nil {
+		return err
+	}
+	resp, err := client.CreateNode(ctx, req)
+	if err != nil {
+		return fmt.Errorf("cannot recreate %q: %w", node.Name, err)
+	}
+	recreated[node.ID] = resp.ItemID
+	if node.Completed {
+		if _, err := client.CompleteNode(ctx, resp.ItemID); err != nil {
+			return fmt.Errorf("cannot complete %q: %w", node.Name, err)
+		}
+	}
+	for _, child := range node.Children {
+		if err := restore(ctx, client, child, resp.ItemID, "bottom", recreated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
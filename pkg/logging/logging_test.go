@@ -0,0 +1,40 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextHandler_AddsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewContextHandler(slog.NewTextHandler(&buf, nil)))
+
+	ctx := WithRequestID(context.Background(), "abc123")
+	logger.InfoContext(ctx, "with id")
+	assert.Contains(t, buf.String(), "request_id=abc123")
+
+	buf.Reset()
+	logger.InfoContext(context.Background(), "without id")
+	assert.NotContains(t, buf.String(), "request_id")
+}
+
+func TestLogger_TagsRequestID(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	Logger(WithRequestID(context.Background(), "def456")).Info("tagged")
+	assert.Contains(t, buf.String(), "request_id=def456")
+}
+
+func TestNewRequestID(t *testing.T) {
+	id := NewRequestID()
+	assert.Len(t, id, 8)
+	assert.NotEqual(t, id, NewRequestID())
+}
@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile is an append-only log file that is renamed to path.1 once it
+// reaches a maximum size, shifting older files to path.2, path.3 and so on.
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxBytes   int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// OpenRotatingFile opens path for appending. A maxBytes of 0 disables rotation;
+// maxBackups is the number of rotated files kept.
+func OpenRotatingFile(path string, maxBytes int64, maxBackups int) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file = f
+	r.size = info.Size()
+	return nil
+}
+
+// Write appends p, rotating first if p would take the file past its maximum size.
+// When rotation fails, p is still appended to the current file, and the
+// rotation is tried again on the next write.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var rotateErr error
+	if r.maxBytes > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			rotateErr = fmt.Errorf("cannot rotate log file: %w", err)
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, errors.Join(rotateErr, err)
+}
+
+// rotate shifts the backups and starts a new file. Whether or not the current
+// file could be moved aside, a file is reopened at path so writes go on.
+func (r *RotatingFile) rotate() error {
+	err := r.file.Close()
+	if err == nil {
+		err = r.shift()
+	}
+	if openErr := r.open(); openErr != nil {
+		return errors.Join(err, openErr)
+	}
+	return err
+}
+
+// shift moves the current file to path.1 and older backups one number up,
+// or removes it when no backups are kept.
+func (r *RotatingFile) shift() error {
+	if r.maxBackups == 0 {
+		return os.Remove(r.path)
+	}
+	for i := r.maxBackups - 1; i >= 1; i-- {
+		os.Rename(r.backupPath(i), r.backupPath(i+1))
+	}
+	return os.Rename(r.path, r.backupPath(1))
+}
+
+func (r *RotatingFile) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", r.path, i)
+}
+
+// Close closes the current file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile_RotatesAtMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.log")
+	r, err := OpenRotatingFile(path, 10, 2)
+	require.NoError(t, err)
+	defer r.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := r.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "fourth\n", string(current))
+
+	backup1, err := os.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "third\n", string(backup1))
+
+	backup2, err := os.ReadFile(path + ".2")
+	require.NoError(t, err)
+	assert.Equal(t, "second\n", string(backup2))
+
+	assert.NoFileExists(t, path+".3")
+}
+
+func TestRotatingFile_NoRotationWhenUnlimited(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.log")
+	r, err := OpenRotatingFile(path, 0, 2)
+	require.NoError(t, err)
+	defer r.Close()
+
+	r.Write([]byte("first\n"))
+	r.Write([]byte("second\n"))
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(current))
+	assert.NoFileExists(t, path+".1")
+}
+
+func TestRotatingFile_AppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.log")
+	require.NoError(t, os.WriteFile(path, []byte("previous run\n"), 0o644))
+
+	r, err := OpenRotatingFile(path, 16, 1)
+	require.NoError(t, err)
+	defer r.Close()
+	r.Write([]byte("new run\n"))
+
+	backup, err := os.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "previous run\n", string(backup))
+}
+
+func TestRotatingFile_KeepsWritingWhenRotationFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.log")
+	// a non-empty directory in the way of the first backup makes the rename fail
+	require.NoError(t, os.MkdirAll(filepath.Join(path+".1", "blocked"), 0o755))
+
+	r, err := OpenRotatingFile(path, 10, 1)
+	require.NoError(t, err)
+	defer r.Close()
+
+	_, err = r.Write([]byte("first\n"))
+	require.NoError(t, err)
+	n, err := r.Write([]byte("second\n"))
+	assert.ErrorContains(t, err, "cannot rotate log file")
+	assert.Equal(t, len("second\n"), n)
+
+	require.NoError(t, os.RemoveAll(path+".1"))
+	_, err = r.Write([]byte("third\n"))
+	require.NoError(t, err)
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "third\n", string(current))
+	backup, err := os.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(backup))
+}
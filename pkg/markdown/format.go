@@ -0,0 +1,31 @@
+package markdown
+
+import (
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/escape"
+	"github.com/mholzen/workflowy/pkg/outline"
+)
+
+// FormatOutline writes an outline as a nested list, one item per node, with
+// "[x]" marking completed nodes. Unlike the markdown formatter, it keeps the
+// exact structure of the outline, so Parse reads back the same names,
+// completion and nesting.
+func FormatOutline(nodes []*outline.Node) string {
+	var b strings.Builder
+	writeOutline(&b, nodes, 0)
+	return b.String()
+}
+
+func writeOutline(b *strings.Builder, nodes []*outline.Node, depth int) {
+	for _, node := range nodes {
+		b.WriteString(strings.Repeat("  ", depth))
+		b.WriteString("- ")
+		if node.Completed {
+			b.WriteString("[x] ")
+		}
+		b.WriteString(escape.ToMarkdown(node.Name))
+		b.WriteString("\n")
+		writeOutline(b, node.Children, depth+1)
+	}
+}
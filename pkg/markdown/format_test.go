@@ -0,0 +1,30 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/outline"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatOutline_RoundTrips(t *testing.T) {
+	nodes := []*outline.Node{
+		{Name: "Website", Children: []*outline.Node{
+			{Name: "Write <b>copy</b>", Completed: true},
+			{Name: "Pick a theme", Children: []*outline.Node{
+				{Name: "# not a header"},
+			}},
+		}},
+		{Name: "Garden"},
+	}
+
+	doc := FormatOutline(nodes)
+
+	assert.Equal(t, `- Website
+  - [x] Write **copy**
+  - Pick a theme
+    - \# not a header
+- Garden
+`, doc)
+	assert.Equal(t, nodes, parse(t, doc))
+}
@@ -0,0 +1,25 @@
+package markdown
+
+import (
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/outline"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// ParseTree converts a markdown document to a tree of Workflowy items, the
+// inverse of the markdown formatter, as described by Parse. The items have no
+// IDs: they are meant to be built in memory, formatted or created.
+func ParseTree(s string) []*workflowy.Item {
+	return ParseTreeWithOptions(s, ParseOptions{})
+}
+
+// ParseTreeWithOptions converts a markdown document to a tree of Workflowy
+// items, like ParseTree.
+func ParseTreeWithOptions(s string, opts ParseOptions) []*workflowy.Item {
+	p := &parser{opts: opts}
+	for _, line := range strings.Split(s, "\n") {
+		p.line(strings.TrimRight(line, "\r"))
+	}
+	return outline.ToItems(p.end())
+}
@@ -0,0 +1,27 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTree(t *testing.T) {
+	items := ParseTree("# Project\r\n\nShip it:\n- [x] build\n- test\n")
+
+	assert.Equal(t, []*workflowy.Item{
+		{Name: "Project", Data: map[string]interface{}{"layoutMode": "h1"}, Children: []*workflowy.Item{
+			{Name: "Ship it:", Data: map[string]interface{}{"layoutMode": "p"}, Children: []*workflowy.Item{
+				{Name: "build", Completed: true},
+				{Name: "test"},
+			}},
+		}},
+	}, items)
+	assert.True(t, items[0].Children[0].Children[0].IsCompleted())
+}
+
+func TestParseTree_Empty(t *testing.T) {
+	assert.Nil(t, ParseTree(""))
+	assert.Nil(t, ParseTreeWithOptions("\n\n", ParseOptions{FlatHeaders: true}))
+}
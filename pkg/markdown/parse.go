@@ -0,0 +1,286 @@
+package markdown
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/escape"
+	"github.com/mholzen/workflowy/pkg/outline"
+)
+
+var (
+	headerPattern   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	listItemPattern = regexp.MustCompile(`^([ \t]*)(?:[-*+]|[0-9]{1,9}[.)])[ \t]+(.*)$`)
+	checkboxPattern = regexp.MustCompile(`^\[([ xX])\][ \t]+`)
+	fencePattern    = regexp.MustCompile("^ {0,3}(```+|~~~+)")
+	quotePattern    = regexp.MustCompile(`^ {0,3}>[ \t]?(.*)$`)
+	dividerPattern  = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	anchorPattern   = regexp.MustCompile(`^<a id="[^"]*"></a>$`)
+)
+
+// Parse reads a markdown document into an outline, the inverse of the
+// markdown formatter:
+//   - headers become h1-h3 nodes holding the content up to the next header of
+//     the same or a higher level
+//   - paragraphs become "p" nodes
+//   - list items become nodes nested by indentation, under the paragraph they
+//     directly follow if any; "[x]" items are completed
+//   - quotes and fenced code blocks become "quote" and "code" nodes whose first
+//     line is the name and other lines the children
+//
+// Text is converted to Workflowy formatting; code is kept literally.
+func Parse(r io.Reader) ([]*outline.Node, error) {
+	return ParseWithOptions(r, ParseOptions{})
+}
+
+// ParseOptions change how Parse interprets headers and lists. The zero value
+// is the behavior of Parse.
+type ParseOptions struct {
+	// FlatHeaders makes headers siblings of the content following them
+	// instead of its parents.
+	FlatHeaders bool
+	// DetachLists makes lists siblings of the paragraph they follow instead
+	// of its children.
+	DetachLists bool
+	// NoLayouts leaves the layout of every node unset, so headers,
+	// paragraphs, quotes and code blocks become plain bullets and dividers
+	// empty ones.
+	NoLayouts bool
+}
+
+// ParseWithOptions reads a markdown document into an outline, like Parse.
+func ParseWithOptions(r io.Reader, opts ParseOptions) ([]*outline.Node, error) {
+	p := &parser{opts: opts}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		p.line(strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read markdown: %w", err)
+	}
+	return p.end(), nil
+}
+
+// end closes the blocks still open and returns the outline.
+func (p *parser) end() []*outline.Node {
+	p.endBlock()
+	if p.fence != "" {
+		p.endCode()
+	}
+	if p.opts.NoLayouts {
+		clearLayouts(p.nodes)
+	}
+	return p.nodes
+}
+
+func clearLayouts(nodes []*outline.Node) {
+	for _, node := range nodes {
+		node.LayoutMode = ""
+		clearLayouts(node.Children)
+	}
+}
+
+type header struct {
+	level int
+	node  *outline.Node
+}
+
+type listItem struct {
+	indent int
+	node   *outline.Node
+}
+
+type parser struct {
+	opts      ParseOptions
+	nodes     []*outline.Node
+	headers   []header      // open headers, outermost first
+	list      []listItem    // open list items, outermost first
+	listOwner *outline.Node // the paragraph the open list belongs to, if any
+	paragraph []string
+	quote     []string
+	fence     string // the opening fence of the current code block
+	code      []string
+}
+
+// add appends node to the innermost open header, or to the top level.
+func (p *parser) add(node *outline.Node) {
+	if len(p.headers) == 0 {
+		p.nodes = append(p.nodes, node)
+		return
+	}
+	parent := p.headers[len(p.headers)-1].node
+	parent.Children = append(parent.Children, node)
+}
+
+func (p *parser) line(line string) {
+	if p.fence != "" {
+		if strings.HasPrefix(strings.TrimSpace(line), p.fence) {
+			p.endCode()
+			return
+		}
+		p.code = append(p.code, line)
+		return
+	}
+
+	if strings.TrimSpace(line) == "" {
+		// lists continue across blank lines, until a line that is not part of them
+		p.endParagraph()
+		p.endQuote()
+		return
+	}
+
+	if m := fencePattern.FindStringSubmatch(line); m != nil {
+		p.endBlock()
+		p.fence = m[1]
+		return
+	}
+
+	if m := listItemPattern.FindStringSubmatch(line); m != nil && !dividerPattern.MatchString(line) {
+		p.endQuote()
+		if owner := p.endParagraph(); owner != nil && !p.opts.DetachLists {
+			p.listOwner = owner
+		}
+		p.listItem(indentWidth(m[1]), m[2])
+		return
+	}
+
+	if len(p.list) > 0 && indentWidth(line) > p.list[len(p.list)-1].indent {
+		// a continuation line of the last list item
+		item := p.list[len(p.list)-1].node
+		item.Name += " " + escape.FromMarkdown(strings.TrimSpace(line))
+		return
+	}
+
+	if m := headerPattern.FindStringSubmatch(line); m != nil {
+		p.endBlock()
+		p.header(len(m[1]), m[2])
+		return
+	}
+
+	if dividerPattern.MatchString(line) {
+		p.endBlock()
+		p.add(&outline.Node{LayoutMode: "divider"})
+		return
+	}
+
+	if anchorPattern.MatchString(strings.TrimSpace(line)) {
+		return
+	}
+
+	if m := quotePattern.FindStringSubmatch(line); m != nil {
+		p.endParagraph()
+		p.endList()
+		p.quote = append(p.quote, m[1])
+		return
+	}
+
+	p.endQuote()
+	p.endList()
+	p.paragraph = append(p.paragraph, strings.TrimSpace(line))
+}
+
+func (p *parser) header(level int, text string) {
+	for len(p.headers) > 0 && p.headers[len(p.headers)-1].level >= level {
+		p.headers = p.headers[:len(p.headers)-1]
+	}
+	node := &outline.Node{Name: escape.FromMarkdown(text)}
+	if level <= 3 {
+		node.LayoutMode = fmt.Sprintf("h%d", level)
+	}
+	p.add(node)
+	if !p.opts.FlatHeaders {
+		p.headers = append(p.headers, header{level: level, node: node})
+	}
+}
+
+func (p *parser) listItem(indent int, text string) {
+	node := &outline.Node{}
+	if m := checkboxPattern.FindStringSubmatch(text); m != nil {
+		node.Completed = m[1] != " "
+		text = text[len(m[0]):]
+	}
+	node.Name = escape.FromMarkdown(text)
+
+	for len(p.list) > 0 && p.list[len(p.list)-1].indent >= indent {
+		p.list = p.list[:len(p.list)-1]
+	}
+	switch {
+	case len(p.list) == 0 && p.listOwner != nil:
+		p.listOwner.Children = append(p.listOwner.Children, node)
+	case len(p.list) == 0:
+		p.add(node)
+	default:
+		parent := p.list[len(p.list)-1].node
+		parent.Children = append(parent.Children, node)
+	}
+	p.list = append(p.list, listItem{indent: indent, node: node})
+}
+
+// endBlock closes the current paragraph, quote and list.
+func (p *parser) endBlock() {
+	p.endParagraph()
+	p.endQuote()
+	p.endList()
+}
+
+func (p *parser) endList() {
+	p.list = nil
+	p.listOwner = nil
+}
+
+// endParagraph closes the current paragraph, returning its node or nil if no
+// paragraph was open.
+func (p *parser) endParagraph() *outline.Node {
+	if len(p.paragraph) == 0 {
+		return nil
+	}
+	node := &outline.Node{Name: escape.FromMarkdown(strings.Join(p.paragraph, " ")), LayoutMode: "p"}
+	p.add(node)
+	p.paragraph = nil
+	return node
+}
+
+func (p *parser) endQuote() {
+	if len(p.quote) == 0 {
+		return
+	}
+	node := &outline.Node{Name: escape.FromMarkdown(p.quote[0]), LayoutMode: "quote"}
+	for _, line := range p.quote[1:] {
+		node.Children = append(node.Children, &outline.Node{Name: escape.FromMarkdown(line)})
+	}
+	p.add(node)
+	p.quote = nil
+}
+
+func (p *parser) endCode() {
+	node := &outline.Node{LayoutMode: "code"}
+	if len(p.code) > 0 {
+		node.Name = escape.HTML(p.code[0])
+		for _, line := range p.code[1:] {
+			node.Children = append(node.Children, &outline.Node{Name: escape.HTML(line)})
+		}
+	}
+	p.add(node)
+	p.fence = ""
+	p.code = nil
+}
+
+// indentWidth returns the width of the leading whitespace of s, counting tabs as 4 spaces.
+func indentWidth(s string) int {
+	width := 0
+	for _, c := range s {
+		switch c {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
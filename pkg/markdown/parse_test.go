@@ -0,0 +1,92 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/outline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, doc string) []*outline.Node {
+	nodes, err := Parse(strings.NewReader(doc))
+	require.NoError(t, err)
+	return nodes
+}
+
+func TestParse_HeadersNestByLevel(t *testing.T) {
+	nodes := parse(t, `# Project
+<a id="wf-0123456789ab"></a>
+## Goals
+
+Ship the **first** version.
+It should be fast.
+
+## Risks
+# Archive
+`)
+
+	assert.Equal(t, []*outline.Node{
+		{Name: "Project", LayoutMode: "h1", Children: []*outline.Node{
+			{Name: "Goals", LayoutMode: "h2", Children: []*outline.Node{
+				{Name: "Ship the <b>first</b> version. It should be fast.", LayoutMode: "p"},
+			}},
+			{Name: "Risks", LayoutMode: "h2"},
+		}},
+		{Name: "Archive", LayoutMode: "h1"},
+	}, nodes)
+}
+
+func TestParse_Lists(t *testing.T) {
+	nodes := parse(t, `Groceries:
+- [x] milk
+- eggs
+  - brown
+    wrapped
+
+1. first
+2. second
+`)
+
+	assert.Equal(t, []*outline.Node{
+		{Name: "Groceries:", LayoutMode: "p", Children: []*outline.Node{
+			{Name: "milk", Completed: true},
+			{Name: "eggs", Children: []*outline.Node{
+				{Name: "brown wrapped"},
+			}},
+			{Name: "first"},
+			{Name: "second"},
+		}},
+	}, nodes)
+}
+
+func TestParse_CodeQuoteAndDivider(t *testing.T) {
+	nodes := parse(t, "> quoted\n> more\n\n---\n\n```go\nif a < b {\n\n}\n```\n")
+
+	assert.Equal(t, []*outline.Node{
+		{Name: "quoted", LayoutMode: "quote", Children: []*outline.Node{{Name: "more"}}},
+		{LayoutMode: "divider"},
+		{Name: "if a &lt; b {", LayoutMode: "code", Children: []*outline.Node{{Name: ""}, {Name: "}"}}},
+	}, nodes)
+}
+
+func TestParseWithOptions(t *testing.T) {
+	doc := "# Project\n\nGoals:\n- ship\n"
+
+	nodes, err := ParseWithOptions(strings.NewReader(doc), ParseOptions{FlatHeaders: true, DetachLists: true})
+	require.NoError(t, err)
+	assert.Equal(t, []*outline.Node{
+		{Name: "Project", LayoutMode: "h1"},
+		{Name: "Goals:", LayoutMode: "p"},
+		{Name: "ship"},
+	}, nodes)
+
+	nodes, err = ParseWithOptions(strings.NewReader(doc), ParseOptions{NoLayouts: true})
+	require.NoError(t, err)
+	assert.Equal(t, []*outline.Node{
+		{Name: "Project", Children: []*outline.Node{
+			{Name: "Goals:", Children: []*outline.Node{{Name: "ship"}}},
+		}},
+	}, nodes)
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mholzen/workflowy/pkg/cache"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// CapabilitiesMetaKey is the _meta key of the initialize response holding the
+// server's Capabilities.
+const CapabilitiesMetaKey = "workflowy/capabilities"
+
+// Capabilities is what the server tells clients when they connect, so they
+// can show its restrictions and warn users when its data is stale.
+type Capabilities struct {
+	Version string `json:"version"`
+	Permissions
+	Tenants   bool      `json:"tenants,omitempty"` // roots depend on the user; see workflowy_permissions
+	Freshness Freshness `json:"freshness"`
+}
+
+// Freshness tells how old the data read tools may serve is.
+type Freshness struct {
+	CachedAt         time.Time `json:"cached_at,omitzero"` // when the export cache was last written
+	CacheAgeSeconds  int64     `json:"cache_age_seconds,omitempty"`
+	BackupFile       string    `json:"backup_file,omitempty"` // backup read by the backup method
+	BackupAt         time.Time `json:"backup_at,omitzero"`    // when that backup was written
+	BackupAgeSeconds int64     `json:"backup_age_seconds,omitempty"`
+}
+
+// capabilities describes the server exposing tools. It may load the whole
+// tree to name the roots, so it is computed once, at startup; freshness is
+// then updated with withFreshness. With tenants, the roots are those of each
+// user, and are left out.
+func (b ToolBuilder) capabilities(ctx context.Context, version string, tools []string, tenants bool) Capabilities {
+	b.tools = tools
+	permissions := b.exposedPermissions()
+	if !tenants {
+		permissions = b.permissions(ctx)
+	}
+	return Capabilities{
+		Version:     version,
+		Permissions: permissions,
+		Tenants:     tenants,
+		Freshness:   dataFreshness(b.backupFile, time.Now()),
+	}
+}
+
+// withFreshness returns the capabilities with the freshness of the data as of now.
+func (c Capabilities) withFreshness(backupFile string, now time.Time) Capabilities {
+	c.Freshness = dataFreshness(backupFile, now)
+	return c
+}
+
+// dataFreshness dates the export cache and the backup file, leaving out the
+// ones that do not exist.
+func dataFreshness(backupFile string, now time.Time) Freshness {
+	var freshness Freshness
+	if path, err := cache.GetCachePath(); err == nil {
+		if info, err := os.Stat(path); err == nil {
+			freshness.CachedAt = info.ModTime()
+			freshness.CacheAgeSeconds = int64(now.Sub(info.ModTime()).Seconds())
+		}
+	}
+
+	if backupFile == "" {
+		latest, err := workflowy.LatestBackupFile()
+		if err != nil {
+			slog.Debug("no backup file", "error", err)
+			return freshness
+		}
+		backupFile = latest
+	}
+	if info, err := os.Stat(workflowy.ExpandTilde(backupFile)); err == nil {
+		freshness.BackupFile = backupFile
+		freshness.BackupAt = info.ModTime()
+		freshness.BackupAgeSeconds = int64(now.Sub(info.ModTime()).Seconds())
+	}
+	return freshness
+}
+
+// capabilitiesMeta returns the _meta of an initialize response carrying capabilities.
+func capabilitiesMeta(capabilities Capabilities) *mcptypes.Meta {
+	return &mcptypes.Meta{AdditionalFields: map[string]any{CapabilitiesMetaKey: capabilities}}
+}
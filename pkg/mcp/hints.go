@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mholzen/workflowy/pkg/client"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// ErrorHint is the structured content of a failed tool call whose error is
+// understood: how an agent can recover from it without asking the user.
+type ErrorHint struct {
+	Error             string `json:"error"`
+	Hint              string `json:"hint"`
+	SuggestedTool     string `json:"suggested_tool,omitempty"`
+	RetryAfterSeconds int    `json:"retry_after_seconds,omitempty"`
+}
+
+// hintFor returns how to recover from err, or nil if its kind is not known.
+func hintFor(err error, now time.Time) *ErrorHint {
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		return apiHint(apiErr, now)
+	}
+	switch {
+	case errors.Is(err, workflowy.ErrNotFound):
+		return &ErrorHint{Hint: "item not found — call " + ToolSearch + " with the name first, then use the id it returns", SuggestedTool: ToolSearch}
+	case errors.Is(err, workflowy.ErrAmbiguousID):
+		return &ErrorHint{Hint: "the short ID matches several items — call " + ToolSearch + " and use the full id of the one meant", SuggestedTool: ToolSearch}
+	case errors.Is(err, workflowy.ErrAccessDenied):
+		return &ErrorHint{Hint: "the item is outside the part of the outline this server may access — call " + ToolPermissions + " to see it", SuggestedTool: ToolPermissions}
+	case errors.Is(err, workflowy.ErrLocked):
+		return &ErrorHint{Hint: "the item is tagged #locked and cannot be written to — choose another item, or ask the user to remove the tag"}
+	case errors.Is(err, errPlanNotFound):
+		return &ErrorHint{Hint: "plans can be applied once, within an hour — preview the changes again with " + ToolReplace + " or " + ToolTransform + " and dry_run, then apply the new plan_id"}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &ErrorHint{Hint: "timed out — retry with a smaller depth, or on a node lower in the outline"}
+	}
+	return nil
+}
+
+// apiHint returns how to recover from a failed call to the Workflowy API.
+func apiHint(err *client.APIError, now time.Time) *ErrorHint {
+	switch {
+	case err.Status == http.StatusTooManyRequests:
+		hint := &ErrorHint{Hint: "rate limited — retry in a minute", SuggestedTool: ToolLimits}
+		if wait, ok := client.ParseRetryAfter(err.RetryAfter, now); ok {
+			hint.RetryAfterSeconds = int(math.Ceil(wait.Seconds()))
+			hint.Hint = fmt.Sprintf("rate limited — retry after %ds", hint.RetryAfterSeconds)
+		}
+		return hint
+	case err.Status == http.StatusNotFound:
+		return &ErrorHint{Hint: "item not found — call " + ToolSearch + " with the name first, then use the id it returns", SuggestedTool: ToolSearch}
+	case err.Status == http.StatusUnauthorized || err.Status == http.StatusForbidden:
+		return &ErrorHint{Hint: "the Workflowy API key was rejected — ask the user to check it; retrying will not help"}
+	case err.Status >= 500:
+		return &ErrorHint{Hint: "the Workflowy API failed — retry in a moment"}
+	}
+	return nil
+}
+
+// errorResult returns the result of a tool call that failed with err, with a
+// hint when the kind of err is known.
+func errorResult(err error) *mcptypes.CallToolResult {
+	return withHint(mcptypes.NewToolResultError(err.Error()), err)
+}
+
+// errorResultFromErr is errorResult with the message given before err.
+func errorResultFromErr(message string, err error) *mcptypes.CallToolResult {
+	return withHint(mcptypes.NewToolResultErrorFromErr(message, err), err)
+}
+
+// withHint adds the hint for err to result, both as structured content and
+// as text for clients that only show text.
+func withHint(result *mcptypes.CallToolResult, err error) *mcptypes.CallToolResult {
+	hint := hintFor(err, time.Now())
+	if hint == nil {
+		return result
+	}
+	if len(result.Content) > 0 {
+		if text, ok := result.Content[0].(mcptypes.TextContent); ok {
+			hint.Error = text.Text
+		}
+	}
+	result.StructuredContent = hint
+	result.Content = append(result.Content, mcptypes.NewTextContent("hint: "+hint.Hint))
+	return result
+}
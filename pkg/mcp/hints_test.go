@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mholzen/workflowy/pkg/client"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHintFor(t *testing.T) {
+	now := time.Now()
+
+	hint := hintFor(fmt.Errorf("cannot resolve ID: %w", workflowy.Errorf(workflowy.ErrNotFound, "no node found with short ID: abc")), now)
+	require.NotNil(t, hint)
+	assert.Equal(t, ToolSearch, hint.SuggestedTool)
+
+	hint = hintFor(&client.APIError{Status: 429, RetryAfter: "42"}, now)
+	require.NotNil(t, hint)
+	assert.Equal(t, "rate limited — retry after 42s", hint.Hint)
+	assert.Equal(t, 42, hint.RetryAfterSeconds)
+
+	hint = hintFor(fmt.Errorf("cannot get item: %w", &client.APIError{Status: 404}), now)
+	require.NotNil(t, hint)
+	assert.Equal(t, ToolSearch, hint.SuggestedTool)
+
+	assert.Equal(t, ToolPermissions, hintFor(workflowy.Errorf(workflowy.ErrAccessDenied, "denied"), now).SuggestedTool)
+	assert.NotNil(t, hintFor(fmt.Errorf("%w: 1234", errPlanNotFound), now))
+	assert.Nil(t, hintFor(fmt.Errorf("pattern is required"), now))
+}
+
+func TestErrorResult(t *testing.T) {
+	err := workflowy.Errorf(workflowy.ErrLocked, "update denied: abc is tagged #locked")
+	result := errorResultFromErr("cannot update", err)
+	assert.True(t, result.IsError)
+	require.Len(t, result.Content, 2)
+	assert.Equal(t, "cannot update: update denied: abc is tagged #locked", result.Content[0].(mcptypes.TextContent).Text)
+	hint, ok := result.StructuredContent.(*ErrorHint)
+	require.True(t, ok)
+	assert.Equal(t, "cannot update: update denied: abc is tagged #locked", hint.Error)
+	assert.Contains(t, result.Content[1].(mcptypes.TextContent).Text, "hint: ")
+
+	result = errorResult(fmt.Errorf("pattern is required"))
+	assert.Len(t, result.Content, 1)
+	assert.Nil(t, result.StructuredContent)
+}
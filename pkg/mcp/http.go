@@ -0,0 +1,424 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+const (
+	// DefaultAddr is the listen address of the HTTP transport
+	DefaultAddr = ":8080"
+	// mcpEndpoint serves the streamable HTTP transport
+	mcpEndpoint = "/mcp"
+	// tokenCacheDuration is how long an access token validated by the OAuth issuer is trusted
+	tokenCacheDuration = 5 * time.Minute
+)
+
+// serveHTTP serves the MCP server over streamable HTTP until ctx is cancelled.
+//
+// Routes:
+//
+//	/mcp                                    MCP endpoint (authenticated)
+//	/config                                 effective configuration, secrets redacted (authenticated)
+//	/healthz                                liveness probe
+//	/.well-known/oauth-protected-resource   OAuth metadata, when an issuer is configured
+func serveHTTP(ctx context.Context, server *mcpserver.MCPServer, cfg Config, tools []string) error {
+	addr := cfg.Addr
+	if addr == "" {
+		addr = DefaultAddr
+	}
+
+	auth := newAuthenticator(cfg)
+	if auth == nil {
+		// only with InsecureNoAuth, checked by RunServer
+		slog.Warn("HTTP MCP server accepts anonymous requests; /config is disabled", "addr", addr)
+	}
+
+	streamable := mcpserver.NewStreamableHTTPServer(server,
+		mcpserver.WithEndpointPath(mcpEndpoint),
+		mcpserver.WithHTTPContextFunc(func(reqCtx context.Context, _ *http.Request) context.Context {
+			return reqCtx
+		}),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle(mcpEndpoint, requireAuth(auth, streamable, true))
+	mux.Handle("/config", requireAuth(auth, configHandler(cfg, tools), false))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	if cfg.OAuthIssuer != "" {
+		mux.HandleFunc("/.well-known/oauth-protected-resource", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, map[string]any{
+				"resource":              baseURL(r) + mcpEndpoint,
+				"authorization_servers": []string{cfg.OAuthIssuer},
+			})
+		})
+	}
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		slog.Info("serving MCP over HTTP", "addr", addr, "endpoint", mcpEndpoint)
+		errs <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("cannot serve HTTP: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("cannot shut down HTTP server: %w", err)
+		}
+		return nil
+	}
+}
+
+// configHandler reports the effective configuration without secrets.
+func configHandler(cfg Config, tools []string) http.Handler {
+	apiKeySource := workflowy.ResolveAPIKeySource(cfg.APIKeyFile, cfg.DefaultAPIKeyFile).String()
+	addr := cfg.Addr
+	if addr == "" {
+		addr = DefaultAddr
+	}
+	state := cfg.State
+	if state == "" {
+		state = StateDisk
+	}
+	tenantClaim := cfg.TenantClaim
+	if tenantClaim == "" {
+		tenantClaim = DefaultTenantClaim
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{
+			"version":        cfg.Version,
+			"transport":      TransportHTTP,
+			"addr":           addr,
+			"tools":          tools,
+			"write_root_id":  cfg.WriteRootID,
+			"read_root_id":   cfg.ReadRootID,
+			"api_key_source": apiKeySource,
+			"method":         cfg.Method,
+			"backup_file":    cfg.BackupFile,
+			"state":          state,
+			"auth": map[string]any{
+				"token":               cfg.AuthToken != "",
+				"oauth_issuer":        cfg.OAuthIssuer,
+				"oauth_audience":      cfg.OAuthAudience,
+				"oauth_allowed_users": len(cfg.OAuthAllowedUsers),
+				"insecure_no_auth":    cfg.InsecureNoAuth,
+			},
+			"tenants": map[string]any{
+				"enabled":   cfg.multiTenant(),
+				"count":     len(cfg.Tenants),
+				"claim":     tenantClaim,
+				"parent_id": cfg.TenantParentID,
+			},
+		})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// baseURL returns the URL clients used to reach the server, honouring TLS-terminating proxies.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// authenticator checks the bearer token of a request.
+type authenticator struct {
+	token  string
+	issuer *oidcValidator
+
+	// Accepting any token of the issuer would let any of its accounts in:
+	// OAuth users must also have a token for audience, or be allowed users.
+	audience     string          // required "aud" of the access token, a JWT, when set
+	userClaim    string          // userinfo claim matched against allowedUsers
+	allowedUsers map[string]bool // nil to allow any user with the audience
+}
+
+// newAuthenticator returns nil when no authentication is configured.
+func newAuthenticator(cfg Config) *authenticator {
+	if cfg.AuthToken == "" && cfg.OAuthIssuer == "" {
+		return nil
+	}
+	auth := &authenticator{token: cfg.AuthToken}
+	if cfg.OAuthIssuer != "" {
+		auth.issuer = newOIDCValidator(cfg.OAuthIssuer)
+		auth.audience = cfg.OAuthAudience
+		auth.userClaim = cfg.TenantClaim
+		if auth.userClaim == "" {
+			auth.userClaim = DefaultTenantClaim
+		}
+		if len(cfg.OAuthAllowedUsers) > 0 {
+			auth.allowedUsers = make(map[string]bool, len(cfg.OAuthAllowedUsers))
+			for _, user := range cfg.OAuthAllowedUsers {
+				auth.allowedUsers[user] = true
+			}
+		}
+	}
+	return auth
+}
+
+// authenticate checks the bearer token of r. It returns the identity of OAuth
+// users, and nil for the static token, which acts as the server operator.
+func (a *authenticator) authenticate(r *http.Request) (*identity, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil, errors.New("missing bearer token")
+	}
+	if a.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) == 1 {
+		return nil, nil
+	}
+	if a.issuer != nil {
+		claims, err := a.issuer.validate(r.Context(), token)
+		if err != nil {
+			return nil, err
+		}
+		if err := a.authorize(token, claims); err != nil {
+			return nil, err
+		}
+		return &identity{claims: claims}, nil
+	}
+	return nil, errors.New("invalid bearer token")
+}
+
+// authorize checks the audience and the user of an access token the issuer
+// accepted, so that its signature need not be checked here.
+func (a *authenticator) authorize(token string, claims map[string]any) error {
+	if a.audience != "" {
+		audiences, err := tokenAudiences(token)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(audiences, a.audience) {
+			return fmt.Errorf("access token is not for audience %s", a.audience)
+		}
+	}
+	if a.allowedUsers != nil {
+		user, _ := claims[a.userClaim].(string)
+		if !a.allowedUsers[user] {
+			return fmt.Errorf("user %q is not allowed", user)
+		}
+	}
+	return nil
+}
+
+// tokenAudiences returns the "aud" claim of a JWT access token, a string or
+// a list of strings.
+func tokenAudiences(token string) ([]string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("access token is not a JWT: cannot check its audience")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode access token: %w", err)
+	}
+	var claims struct {
+		Audience any `json:"aud"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("cannot parse access token: %w", err)
+	}
+	switch aud := claims.Audience.(type) {
+	case string:
+		return []string{aud}, nil
+	case []any:
+		audiences := make([]string, 0, len(aud))
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				audiences = append(audiences, s)
+			}
+		}
+		return audiences, nil
+	}
+	return nil, errors.New("access token has no audience")
+}
+
+// requireAuth rejects unauthenticated requests and records the caller's
+// identity in the request context. When auth is nil, requests are allowed
+// through if allowAnonymous is set and refused otherwise.
+func requireAuth(auth *authenticator, next http.Handler, allowAnonymous bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth == nil {
+			if allowAnonymous {
+				next.ServeHTTP(w, r)
+				return
+			}
+			http.NotFound(w, r)
+			return
+		}
+		id, err := auth.authenticate(r)
+		if err != nil {
+			slog.Debug("rejected HTTP request", "path", r.URL.Path, "error", err)
+			challenge := `Bearer realm="workflowy"`
+			if auth.issuer != nil {
+				challenge += fmt.Sprintf(`, resource_metadata="%s/.well-known/oauth-protected-resource"`, baseURL(r))
+			}
+			w.Header().Set("WWW-Authenticate", challenge)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if id != nil {
+			r = r.WithContext(withIdentity(r.Context(), id))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// identity is an OAuth user, described by the claims of the issuer's userinfo response.
+type identity struct {
+	claims map[string]any
+}
+
+// claim returns the string value of a userinfo claim, or "" if absent.
+func (id *identity) claim(name string) string {
+	value, _ := id.claims[name].(string)
+	return value
+}
+
+type identityKey struct{}
+
+func withIdentity(ctx context.Context, id *identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// identityFrom returns the OAuth user of the request, or nil for the operator and anonymous requests.
+func identityFrom(ctx context.Context) *identity {
+	id, _ := ctx.Value(identityKey{}).(*identity)
+	return id
+}
+
+// oidcValidator accepts access tokens that the issuer's userinfo endpoint accepts.
+type oidcValidator struct {
+	issuer string
+	client *http.Client
+
+	mu       sync.Mutex
+	userinfo string
+	valid    map[string]validation // token -> cached validation
+}
+
+type validation struct {
+	claims map[string]any
+	expiry time.Time
+}
+
+func newOIDCValidator(issuer string) *oidcValidator {
+	return &oidcValidator{
+		issuer: strings.TrimRight(issuer, "/"),
+		client: &http.Client{Timeout: 10 * time.Second},
+		valid:  make(map[string]validation),
+	}
+}
+
+// validate returns the userinfo claims of the user the token was issued to.
+func (v *oidcValidator) validate(ctx context.Context, token string) (map[string]any, error) {
+	v.mu.Lock()
+	cached, ok := v.valid[token]
+	v.mu.Unlock()
+	if ok && time.Now().Before(cached.expiry) {
+		return cached.claims, nil
+	}
+
+	endpoint, err := v.userinfoEndpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach OAuth issuer: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token rejected by OAuth issuer: %s", resp.Status)
+	}
+	claims := map[string]any{}
+	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return nil, fmt.Errorf("cannot parse userinfo response: %w", err)
+	}
+
+	v.mu.Lock()
+	now := time.Now()
+	for t, c := range v.valid {
+		if now.After(c.expiry) {
+			delete(v.valid, t)
+		}
+	}
+	v.valid[token] = validation{claims: claims, expiry: now.Add(tokenCacheDuration)}
+	v.mu.Unlock()
+	return claims, nil
+}
+
+// userinfoEndpoint discovers the userinfo endpoint from the issuer's OpenID
+// configuration. The lock is not held during discovery, so that a slow
+// issuer does not block the validation of cached tokens; concurrent first
+// requests may each discover it.
+func (v *oidcValidator) userinfoEndpoint(ctx context.Context) (string, error) {
+	v.mu.Lock()
+	userinfo := v.userinfo
+	v.mu.Unlock()
+	if userinfo != "" {
+		return userinfo, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", v.issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("cannot reach OAuth issuer: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("cannot read OpenID configuration: %s", resp.Status)
+	}
+	var discovery struct {
+		UserinfoEndpoint string `json:"userinfo_endpoint"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return "", fmt.Errorf("cannot parse OpenID configuration: %w", err)
+	}
+	if discovery.UserinfoEndpoint == "" {
+		return "", errors.New("OAuth issuer does not publish a userinfo endpoint")
+	}
+	v.mu.Lock()
+	v.userinfo = discovery.UserinfoEndpoint
+	v.mu.Unlock()
+	return discovery.UserinfoEndpoint, nil
+}
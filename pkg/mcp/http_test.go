@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIssuer accepts any bearer token and returns claims for sub.
+func fakeIssuer(t *testing.T, sub string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			writeJSON(w, map[string]any{"userinfo_endpoint": server.URL + "/userinfo"})
+		case "/userinfo":
+			writeJSON(w, map[string]any{"sub": sub})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func jwtWithAudience(t *testing.T, aud any) string {
+	payload, err := json.Marshal(map[string]any{"aud": aud})
+	require.NoError(t, err)
+	return "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+func authRequest(token string) *http.Request {
+	r := httptest.NewRequest("GET", "/mcp", nil).WithContext(context.Background())
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+func TestAuthenticatorAudience(t *testing.T) {
+	issuer := fakeIssuer(t, "alice")
+	auth := newAuthenticator(Config{OAuthIssuer: issuer.URL, OAuthAudience: "workflowy-mcp"})
+
+	id, err := auth.authenticate(authRequest(jwtWithAudience(t, []string{"other", "workflowy-mcp"})))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", id.claim("sub"))
+
+	_, err = auth.authenticate(authRequest(jwtWithAudience(t, "other")))
+	assert.Error(t, err)
+
+	_, err = auth.authenticate(authRequest("opaque-token"))
+	assert.Error(t, err)
+}
+
+func TestAuthenticatorAllowedUsers(t *testing.T) {
+	auth := newAuthenticator(Config{OAuthIssuer: fakeIssuer(t, "alice").URL, OAuthAllowedUsers: []string{"alice"}})
+	_, err := auth.authenticate(authRequest("opaque-token"))
+	assert.NoError(t, err)
+
+	auth = newAuthenticator(Config{OAuthIssuer: fakeIssuer(t, "mallory").URL, OAuthAllowedUsers: []string{"alice"}})
+	_, err = auth.authenticate(authRequest("opaque-token"))
+	assert.Error(t, err)
+}
+
+func TestRunServerRequiresAuth(t *testing.T) {
+	err := RunServer(context.Background(), Config{Transport: TransportHTTP})
+	assert.ErrorContains(t, err, "--insecure-no-auth")
+
+	err = RunServer(context.Background(), Config{Transport: TransportHTTP, OAuthIssuer: "https://auth.example.com"})
+	assert.ErrorContains(t, err, "--oauth-audience")
+}
@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/mholzen/workflowy/pkg/client"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// dryRunTools preview their edits unless called with dry_run=false.
+var dryRunTools = []string{ToolReplace, ToolTransform}
+
+// Root is a node that reads or writes are confined to.
+type Root struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// Permissions describes what a server lets its clients do, so an agent can
+// plan its calls instead of discovering restrictions through failures.
+type Permissions struct {
+	Tools      []string          `json:"tools"`                        // exposed tools
+	ReadOnly   bool              `json:"read_only"`                    // no write tool is exposed
+	DryRun     []string          `json:"dry_run_by_default,omitempty"` // exposed tools that only preview by default
+	WriteRoot  *Root             `json:"write_root,omitempty"`
+	ReadRoot   *Root             `json:"read_root,omitempty"`
+	Method     string            `json:"method,omitempty"`      // access method of read tools
+	BackupFile string            `json:"backup_file,omitempty"` // backup read by the backup method
+	RateLimit  *client.RateLimit `json:"rate_limit,omitempty"`  // API requests left, when reported
+}
+
+// ExposedPermissions returns the permissions granted by exposing tools.
+func ExposedPermissions(tools []string) Permissions {
+	permissions := Permissions{Tools: tools, ReadOnly: true}
+	for _, tool := range tools {
+		if slices.Contains(writeTools, tool) {
+			permissions.ReadOnly = false
+		}
+		if slices.Contains(dryRunTools, tool) {
+			permissions.DryRun = append(permissions.DryRun, tool)
+		}
+	}
+	return permissions
+}
+
+func (b ToolBuilder) buildPermissionsTool() mcpserver.ServerTool {
+	return mcpserver.ServerTool{
+		Tool: mcptypes.NewTool(
+			ToolPermissions,
+			mcptypes.WithDescription("Show what this server allows: the exposed tools, whether it is read-only, the read and write roots, the access method and the API requests left. Call it before planning edits"),
+		),
+		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
+			permissions := b.permissions(ctx)
+
+			// The budget is informative: backup-only servers may not reach the API
+			if b.method != "backup" && b.method != "cache" {
+				if limit, known, err := workflowy.RateLimit(ctx, b.client); err != nil {
+					slog.DebugContext(ctx, "cannot get rate limit", "error", err)
+				} else if known {
+					permissions.RateLimit = &limit
+				}
+			}
+
+			return mcptypes.NewToolResultJSON(permissions)
+		},
+	}
+}
+
+// exposedPermissions returns the permissions of the tools being built, without
+// the roots and the rate limit.
+func (b ToolBuilder) exposedPermissions() Permissions {
+	permissions := ExposedPermissions(b.tools)
+	permissions.Method = b.method
+	permissions.BackupFile = b.backupFile
+	return permissions
+}
+
+// permissions returns the permissions of the tools being built, without the
+// rate limit. Root names are left out when the tree cannot be loaded.
+func (b ToolBuilder) permissions(ctx context.Context) Permissions {
+	permissions := b.exposedPermissions()
+	if b.isRestricted() || b.isReadRestricted() {
+		items, err := b.loadTree(ctx)
+		root := func(id string) *Root {
+			root := &Root{ID: id}
+			if err == nil {
+				if item := workflowy.FindItemByID(items, id); item != nil {
+					root.Name = item.Name
+				}
+			}
+			return root
+		}
+		if b.isRestricted() {
+			permissions.WriteRoot = root(b.writeRootID)
+		}
+		if b.isReadRestricted() {
+			permissions.ReadRoot = root(b.readRootID)
+		}
+	}
+	return permissions
+}
@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/patch"
+	"github.com/mholzen/workflowy/pkg/replace"
+	"github.com/mholzen/workflowy/pkg/transform"
+)
+
+// errPlanNotFound reports a plan that was never made, already applied or expired.
+var errPlanNotFound = errors.New("plan not found or expired")
+
+// planExpiry is how long a dry-run plan can be applied after it was created.
+const planExpiry = 1 * time.Hour
+
+type plan struct {
+	edits   []patch.Edit
+	created time.Time
+}
+
+// PlanStore keeps the edits previewed by dry-runs so they can be applied later.
+type PlanStore struct {
+	mu    sync.Mutex
+	plans map[string]plan
+	now   func() time.Time
+}
+
+// NewPlanStore creates an empty plan store.
+func NewPlanStore() *PlanStore {
+	return &PlanStore{plans: make(map[string]plan), now: time.Now}
+}
+
+// Save stores edits and returns the plan ID to apply them with.
+func (s *PlanStore) Save(edits []patch.Edit) (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("cannot generate plan id: %w", err)
+	}
+	id := hex.EncodeToString(buf)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purgeExpired()
+	s.plans[id] = plan{edits: edits, created: s.now()}
+	return id, nil
+}
+
+// Get returns the edits of a plan, leaving it to be applied.
+func (s *PlanStore) Get(id string) ([]patch.Edit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purgeExpired()
+	p, ok := s.plans[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errPlanNotFound, id)
+	}
+	return p.edits, nil
+}
+
+// Take removes and returns the edits of a plan. A plan can only be applied once.
+func (s *PlanStore) Take(id string) ([]patch.Edit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purgeExpired()
+	p, ok := s.plans[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errPlanNotFound, id)
+	}
+	delete(s.plans, id)
+	return p.edits, nil
+}
+
+func (s *PlanStore) purgeExpired() {
+	for id, p := range s.plans {
+		if s.now().Sub(p.created) > planExpiry {
+			delete(s.plans, id)
+		}
+	}
+}
+
+func replaceEdits(results []replace.Result) []patch.Edit {
+	var edits []patch.Edit
+	for _, result := range results {
+		if !result.Skipped {
+			edits = append(edits, result.Edits()...)
+		}
+	}
+	return edits
+}
+
+func transformEdits(results []transform.Result) []patch.Edit {
+	var edits []patch.Edit
+	for _, result := range results {
+		if !result.Skipped {
+			edits = append(edits, patch.Edit{ID: result.ID, Field: result.Field, Old: result.Original, New: result.New})
+		}
+	}
+	return edits
+}
@@ -5,13 +5,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
+	"time"
 
 	mcptypes "github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/mholzen/workflowy/pkg/client"
+	"github.com/mholzen/workflowy/pkg/journal"
+	"github.com/mholzen/workflowy/pkg/logging"
+	"github.com/mholzen/workflowy/pkg/storage"
+	"github.com/mholzen/workflowy/pkg/usage"
 	"github.com/mholzen/workflowy/pkg/workflowy"
 )
 
+// Transports supported by RunServer
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http"
+)
+
+// States supported by RunServer: where caches, the undo journal and usage
+// statistics are kept
+const (
+	StateDisk   = "disk"   // the configured storage (default)
+	StateMemory = "memory" // memory only, lost on exit, for read-only filesystems
+)
+
 // Config controls MCP server startup.
 type Config struct {
 	APIKeyFile        string
@@ -20,9 +40,41 @@ type Config struct {
 	Version           string
 	WriteRootID       string
 	ReadRootID        string
+
+	// Read access settings, as the CLI's --method and --backup-file
+	Method     string // get, export, backup or cache; empty chooses by depth
+	BackupFile string // backup file read by the backup method (default: latest)
+
+	MaxBackupAge time.Duration // backups older than this are refused (0 for no limit)
+	Force        bool          // write tools modify #locked nodes, as the CLI's --force
+	UsageStats   bool          // record tool calls in the local usage statistics, as the CLI's --usage-stats
+	TrashID      string        // node workflowy_delete moves nodes to with soft (default: a "Trash" node)
+	State        string        // disk (default) or memory
+
+	// HTTP transport settings
+	Transport   string // stdio (default) or http
+	Addr        string // listen address, e.g. ":8080"
+	AuthToken   string // static bearer token accepted by the HTTP server
+	OAuthIssuer string // OpenID Connect issuer whose access tokens are accepted
+
+	// Restrictions on the OAuth users accepted, at least one required with an issuer
+	OAuthAudience     string   // audience the access tokens (JWTs) must be issued for
+	OAuthAllowedUsers []string // values of TenantClaim accepted
+
+	InsecureNoAuth bool // serve HTTP requests without authentication
+
+	// Multi-tenant settings: each OAuth user is confined to their own sandbox
+	Tenants        map[string]Tenant // sandbox of each user, keyed by TenantClaim
+	TenantClaim    string            // userinfo claim identifying users (default "sub")
+	TenantParentID string            // parent of sandboxes created for users missing from Tenants
+}
+
+// multiTenant reports whether OAuth users are confined to per-user sandboxes.
+func (cfg Config) multiTenant() bool {
+	return len(cfg.Tenants) > 0 || cfg.TenantParentID != ""
 }
 
-// RunServer starts the MCP stdio server with the requested tool set.
+// RunServer starts the MCP server with the requested tool set, on stdio or HTTP.
 func RunServer(ctx context.Context, cfg Config) error {
 	expose := strings.TrimSpace(cfg.Expose)
 	if expose == "" {
@@ -34,12 +86,32 @@ func RunServer(ctx context.Context, cfg Config) error {
 		return err
 	}
 
+	if cfg.multiTenant() && (cfg.Transport != TransportHTTP || cfg.OAuthIssuer == "") {
+		return fmt.Errorf("tenants require --transport=http and an OAuth issuer to identify users")
+	}
+	if cfg.OAuthIssuer != "" && cfg.OAuthAudience == "" && len(cfg.OAuthAllowedUsers) == 0 {
+		return fmt.Errorf("an OAuth issuer requires --oauth-audience or --oauth-allowed-users: otherwise any account of the issuer is accepted")
+	}
+	if cfg.Transport == TransportHTTP && cfg.AuthToken == "" && cfg.OAuthIssuer == "" && !cfg.InsecureNoAuth {
+		return fmt.Errorf("--transport=http requires --auth-token or --oauth-issuer, or --insecure-no-auth to serve anonymous requests")
+	}
+
+	switch cfg.State {
+	case "", StateDisk:
+	case StateMemory:
+		// nothing is written to disk: caches, journal and usage live in memory
+		storage.SetDefault(storage.NewMemoryStorage())
+		slog.Info("state kept in memory")
+	default:
+		return fmt.Errorf("unknown state %q (expected %s or %s)", cfg.State, StateDisk, StateMemory)
+	}
+
 	option, err := workflowy.ResolveAPIKey(cfg.APIKeyFile, cfg.DefaultAPIKeyFile)
 	if err != nil {
 		return fmt.Errorf("cannot load API key: %w", err)
 	}
 
-	client := workflowy.NewWorkflowyClient(option)
+	client := workflowy.NewWorkflowyClient(option, client.WithRetry(client.DefaultRetries, client.DefaultRetryDelay))
 
 	// Resolve write-root-id if provided (supports short IDs, target keys)
 	writeRootID := cfg.WriteRootID
@@ -63,12 +135,49 @@ func RunServer(ctx context.Context, cfg Config) error {
 		slog.Info("read restrictions enabled", "read_root_id", readRootID)
 	}
 
-	builder := NewToolBuilder(client, writeRootID, readRootID)
+	// record the writes of each tool call, for `workflowy undo`
+	var toolClient workflowy.Client = client
+	if j, err := journal.OpenDefault(); err != nil {
+		slog.Warn("cannot open journal -- writes will not be undoable", "error", err)
+	} else {
+		toolClient = journal.NewClient(client, j, "mcp")
+	}
+
+	builder, err := NewToolBuilder(toolClient, writeRootID, readRootID).WithAccessMethod(cfg.Method, cfg.BackupFile)
+	if err != nil {
+		return err
+	}
+	builder = builder.WithMaxBackupAge(cfg.MaxBackupAge).WithForce(cfg.Force).WithTrash(cfg.TrashID)
+	if cfg.Method != "" {
+		slog.Info("access method configured", "method", cfg.Method, "backup_file", cfg.BackupFile)
+	}
 	serverTools, err := builder.BuildTools(toolsToEnable)
 	if err != nil {
 		return err
 	}
 
+	middlewares := []mcpserver.ServerOption{
+		mcpserver.WithToolHandlerMiddleware(withRequestID),
+		mcpserver.WithToolHandlerMiddleware(withJournalRun),
+	}
+	if cfg.UsageStats {
+		if file, err := usage.OpenDefault(); err != nil {
+			slog.Warn("cannot open usage statistics", "error", err)
+		} else {
+			middlewares = append(middlewares, mcpserver.WithToolHandlerMiddleware(withUsage(file)))
+		}
+	}
+	if cfg.multiTenant() {
+		if cfg.TenantParentID != "" {
+			if cfg.TenantParentID, err = workflowy.ResolveNodeIDToUUID(ctx, client, cfg.TenantParentID); err != nil {
+				return fmt.Errorf("cannot resolve tenant parent: %w", err)
+			}
+		}
+		tenants := newTenantRouter(builder, toolsToEnable, cfg)
+		middlewares = append(middlewares, mcpserver.WithToolHandlerMiddleware(tenants.middleware))
+		slog.Info("multi-tenant sandboxes enabled", "tenants", len(cfg.Tenants), "tenant_parent_id", cfg.TenantParentID, "claim", tenants.claim)
+	}
+
 	hooks := &mcpserver.Hooks{}
 	hooks.AddBeforeAny(func(ctx context.Context, id any, method mcptypes.MCPMethod, message any) {
 		msgJSON, _ := json.Marshal(message)
@@ -81,22 +190,72 @@ func RunServer(ctx context.Context, cfg Config) error {
 	hooks.AddOnError(func(ctx context.Context, id any, method mcptypes.MCPMethod, message any, err error) {
 		slog.Debug("mcp error", "id", id, "method", method, "error", err)
 	})
+	capabilities := builder.capabilities(ctx, cfg.Version, toolsToEnable, cfg.multiTenant())
+	hooks.AddAfterInitialize(func(ctx context.Context, id any, message *mcptypes.InitializeRequest, result *mcptypes.InitializeResult) {
+		result.Meta = capabilitiesMeta(capabilities.withFreshness(builder.backupFile, time.Now()))
+	})
 
-	server := mcpserver.NewMCPServer(
-		"workflowy",
-		cfg.Version,
+	options := append([]mcpserver.ServerOption{
 		mcpserver.WithToolCapabilities(true),
 		mcpserver.WithLogging(),
 		mcpserver.WithHooks(hooks),
-	)
+	}, middlewares...)
+	server := mcpserver.NewMCPServer("workflowy", cfg.Version, options...)
 
 	for _, tool := range serverTools {
 		server.AddTool(tool.Tool, tool.Handler)
 	}
 
-	return mcpserver.ServeStdio(server, mcpserver.WithStdioContextFunc(func(_ context.Context) context.Context {
-		return ctx
-	}))
+	switch cfg.Transport {
+	case "", TransportStdio:
+		return mcpserver.ServeStdio(server, mcpserver.WithStdioContextFunc(func(_ context.Context) context.Context {
+			return ctx
+		}))
+	case TransportHTTP:
+		return serveHTTP(ctx, server, cfg, toolsToEnable)
+	default:
+		return fmt.Errorf("unknown transport %q (expected %s or %s)", cfg.Transport, TransportStdio, TransportHTTP)
+	}
+}
+
+// withRequestID tags each tool call with a request ID so its log records can be correlated.
+func withRequestID(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, request mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
+		ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+		start := time.Now()
+		slog.DebugContext(ctx, "tool call started", "tool", request.Params.Name)
+		result, err := next(ctx, request)
+		slog.DebugContext(ctx, "tool call finished", "tool", request.Params.Name, "duration_ms", time.Since(start).Milliseconds(), "error", err)
+		return result, err
+	}
+}
+
+// withJournalRun records the writes of each tool call as a run of its own, to
+// be undone together.
+func withJournalRun(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, request mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
+		return next(journal.WithRun(ctx, request.Params.Name), request)
+	}
+}
+
+// withUsage adds each tool call to the usage statistics in file.
+func withUsage(file *usage.File) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
+			start := time.Now()
+			result, err := next(ctx, request)
+			failed := err != nil || (result != nil && result.IsError)
+			if recordErr := file.Record(usage.KindTool, request.Params.Name, start, time.Since(start), failed); recordErr != nil {
+				slog.WarnContext(ctx, "cannot record usage statistics", "error", recordErr)
+			}
+			return result, err
+		}
+	}
+}
+
+// ToolNames returns the names of all the tools the server can expose.
+func ToolNames() []string {
+	return slices.Clone(allTools)
 }
 
 // ParseExposeList converts the --expose flag into a deduplicated, ordered tool list.
@@ -159,8 +318,12 @@ var (
 		ToolGet,
 		ToolList,
 		ToolSearch,
+		ToolSavedSearches,
 		ToolTargets,
+		ToolPermissions,
 		ToolID,
+		ToolLimits,
+		ToolExport,
 		ToolCreate,
 		ToolUpdate,
 		ToolMove,
@@ -171,22 +334,39 @@ var (
 		ToolReportChildren,
 		ToolReportCreated,
 		ToolReportModified,
+		ToolReportTags,
+		ToolReportCompleted,
+		ToolReportStale,
+		ToolReportExposure,
+		ToolReportDepth,
 		ToolReportMirrors,
 		ToolReplace,
 		ToolTransform,
+		ToolApplyPlan,
+		ToolSimulate,
 	}
 
 	readTools = []string{
 		ToolGet,
 		ToolList,
 		ToolSearch,
+		ToolSavedSearches,
 		ToolTargets,
+		ToolPermissions,
 		ToolID,
+		ToolLimits,
+		ToolExport,
 		ToolReportCount,
 		ToolReportChildren,
 		ToolReportCreated,
 		ToolReportModified,
+		ToolReportTags,
+		ToolReportCompleted,
+		ToolReportStale,
+		ToolReportExposure,
+		ToolReportDepth,
 		ToolReportMirrors,
+		ToolSimulate,
 	}
 
 	writeTools = []string{
@@ -198,6 +378,7 @@ var (
 		ToolUncomplete,
 		ToolReplace,
 		ToolTransform,
+		ToolApplyPlan,
 	}
 
 	groupMap = map[string][]string{
@@ -207,24 +388,35 @@ var (
 	}
 
 	aliasMap = map[string]string{
-		"get":             ToolGet,
-		"list":            ToolList,
-		"search":          ToolSearch,
-		"targets":         ToolTargets,
-		"id":              ToolID,
-		"create":          ToolCreate,
-		"update":          ToolUpdate,
-		"move":            ToolMove,
-		"delete":          ToolDelete,
-		"complete":        ToolComplete,
-		"uncomplete":      ToolUncomplete,
-		"report_count":    ToolReportCount,
-		"report_children": ToolReportChildren,
-		"report_created":  ToolReportCreated,
-		"report_modified": ToolReportModified,
-		"report_mirrors":  ToolReportMirrors,
-		"replace":         ToolReplace,
-		"transform":       ToolTransform,
+		"get":              ToolGet,
+		"list":             ToolList,
+		"search":           ToolSearch,
+		"saved_searches":   ToolSavedSearches,
+		"targets":          ToolTargets,
+		"permissions":      ToolPermissions,
+		"id":               ToolID,
+		"limits":           ToolLimits,
+		"export":           ToolExport,
+		"create":           ToolCreate,
+		"update":           ToolUpdate,
+		"move":             ToolMove,
+		"delete":           ToolDelete,
+		"complete":         ToolComplete,
+		"uncomplete":       ToolUncomplete,
+		"report_count":     ToolReportCount,
+		"report_children":  ToolReportChildren,
+		"report_created":   ToolReportCreated,
+		"report_modified":  ToolReportModified,
+		"report_tags":      ToolReportTags,
+		"report_completed": ToolReportCompleted,
+		"report_stale":     ToolReportStale,
+		"report_exposure":  ToolReportExposure,
+		"report_depth":     ToolReportDepth,
+		"report_mirrors":   ToolReportMirrors,
+		"replace":          ToolReplace,
+		"transform":        ToolTransform,
+		"apply_plan":       ToolApplyPlan,
+		"simulate":         ToolSimulate,
 	}
 
 	aliasMapFull = func() map[string]string {
@@ -0,0 +1,219 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultTenantClaim is the userinfo claim that identifies a user to the tenant mapping.
+const DefaultTenantClaim = "sub"
+
+// Tenant is the sandbox of one user of a shared HTTP server. RootID sets
+// both roots; ReadRootID and WriteRootID override it individually.
+type Tenant struct {
+	RootID      string `json:"root_id,omitempty"`
+	ReadRootID  string `json:"read_root_id,omitempty"`
+	WriteRootID string `json:"write_root_id,omitempty"`
+}
+
+func (t Tenant) roots() (writeRootID, readRootID string) {
+	writeRootID, readRootID = t.RootID, t.RootID
+	if t.WriteRootID != "" {
+		writeRootID = t.WriteRootID
+	}
+	if t.ReadRootID != "" {
+		readRootID = t.ReadRootID
+	}
+	return writeRootID, readRootID
+}
+
+// ReadTenantsFile loads a JSON object mapping user identifiers (the value of
+// the tenant claim) to their sandbox, e.g. {"alice@example.com": {"root_id": "abc123"}}.
+func ReadTenantsFile(path string) (map[string]Tenant, error) {
+	data, err := os.ReadFile(workflowy.ExpandTilde(path))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read tenants file: %w", err)
+	}
+	var tenants map[string]Tenant
+	if err := json.Unmarshal(data, &tenants); err != nil {
+		return nil, fmt.Errorf("cannot parse tenants file: %w", err)
+	}
+	for user, tenant := range tenants {
+		if w, r := tenant.roots(); w == "" || r == "" {
+			return nil, fmt.Errorf("tenant %s: root_id, or both read_root_id and write_root_id, are required", user)
+		}
+	}
+	return tenants, nil
+}
+
+// tenantRouter runs the tool calls of each OAuth user against a ToolBuilder
+// restricted to that user's sandbox. A user's sandbox comes from the tenants
+// file or, failing that, is a child of parentID named after the user, created
+// on first use. Users without a sandbox are refused.
+type tenantRouter struct {
+	builder  ToolBuilder
+	tools    []string
+	claim    string
+	tenants  map[string]Tenant
+	parentID string
+
+	mu        sync.Mutex
+	handlers  map[string]map[string]mcpserver.ToolHandlerFunc // user -> tool name -> handler
+	sandboxes singleflight.Group                              // builds of the handlers, by user
+}
+
+func newTenantRouter(builder ToolBuilder, tools []string, cfg Config) *tenantRouter {
+	claim := cfg.TenantClaim
+	if claim == "" {
+		claim = DefaultTenantClaim
+	}
+	return &tenantRouter{
+		builder:  builder,
+		tools:    tools,
+		claim:    claim,
+		tenants:  cfg.Tenants,
+		parentID: cfg.TenantParentID,
+		handlers: make(map[string]map[string]mcpserver.ToolHandlerFunc),
+	}
+}
+
+// middleware dispatches calls from OAuth users to their sandboxed tools.
+// Calls without an identity (static token, stdio) use the server's own restrictions.
+func (t *tenantRouter) middleware(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, request mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
+		id := identityFrom(ctx)
+		if id == nil {
+			return next(ctx, request)
+		}
+		user := id.claim(t.claim)
+		if user == "" {
+			return mcptypes.NewToolResultError(fmt.Sprintf("access denied: token has no %q claim", t.claim)), nil
+		}
+		handlers, err := t.handlersFor(ctx, user)
+		if err != nil {
+			slog.WarnContext(ctx, "cannot resolve tenant", "user", user, "error", err)
+			return mcptypes.NewToolResultErrorFromErr("access denied", err), nil
+		}
+		handler, ok := handlers[request.Params.Name]
+		if !ok {
+			return mcptypes.NewToolResultError(fmt.Sprintf("unknown tool: %s", request.Params.Name)), nil
+		}
+		return handler(ctx, request)
+	}
+}
+
+// handlersFor returns the tool handlers of user, building them on first use.
+// Concurrent first calls of a user share one build, which resolves or creates
+// the sandbox through the API without holding the lock, so that other users
+// are not kept waiting.
+func (t *tenantRouter) handlersFor(ctx context.Context, user string) (map[string]mcpserver.ToolHandlerFunc, error) {
+	t.mu.Lock()
+	handlers, ok := t.handlers[user]
+	t.mu.Unlock()
+	if ok {
+		return handlers, nil
+	}
+
+	ch := t.sandboxes.DoChan(user, func() (any, error) {
+		// a build may have completed since the handlers were looked up
+		t.mu.Lock()
+		handlers, ok := t.handlers[user]
+		t.mu.Unlock()
+		if ok {
+			return handlers, nil
+		}
+		// detached from the caller so one cancelled call does not fail the others
+		return t.buildHandlers(context.WithoutCancel(ctx), user)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(map[string]mcpserver.ToolHandlerFunc), nil
+	}
+}
+
+// buildHandlers builds the tool handlers of user, restricted to their sandbox.
+func (t *tenantRouter) buildHandlers(ctx context.Context, user string) (map[string]mcpserver.ToolHandlerFunc, error) {
+	writeRootID, readRootID, err := t.resolveRoots(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	// each tenant gets its own plan store so plans cannot be applied across sandboxes
+	builder := t.builder
+	builder.writeRootID = writeRootID
+	builder.readRootID = readRootID
+	builder.plans = NewPlanStore()
+	tools, err := builder.BuildTools(t.tools)
+	if err != nil {
+		return nil, err
+	}
+
+	handlers := make(map[string]mcpserver.ToolHandlerFunc, len(tools))
+	for _, tool := range tools {
+		handlers[tool.Tool.Name] = tool.Handler
+	}
+	t.mu.Lock()
+	t.handlers[user] = handlers
+	t.mu.Unlock()
+	slog.InfoContext(ctx, "tenant sandbox ready", "user", user, "write_root_id", writeRootID, "read_root_id", readRootID)
+	return handlers, nil
+}
+
+func (t *tenantRouter) resolveRoots(ctx context.Context, user string) (writeRootID, readRootID string, err error) {
+	if tenant, ok := t.tenants[user]; ok {
+		writeRootID, readRootID = tenant.roots()
+		if writeRootID, err = workflowy.ResolveNodeIDToUUID(ctx, t.builder.client, writeRootID); err != nil {
+			return "", "", fmt.Errorf("cannot resolve write root of %s: %w", user, err)
+		}
+		if readRootID, err = workflowy.ResolveNodeIDToUUID(ctx, t.builder.client, readRootID); err != nil {
+			return "", "", fmt.Errorf("cannot resolve read root of %s: %w", user, err)
+		}
+		return writeRootID, readRootID, nil
+	}
+	if t.parentID == "" {
+		return "", "", fmt.Errorf("no sandbox is configured for %s", user)
+	}
+	rootID, err := t.userNode(ctx, user)
+	if err != nil {
+		return "", "", err
+	}
+	return rootID, rootID, nil
+}
+
+// userNode returns the child of the tenant parent named user, creating it if needed.
+func (t *tenantRouter) userNode(ctx context.Context, user string) (string, error) {
+	children, err := t.builder.client.ListChildren(ctx, t.parentID)
+	if err != nil {
+		return "", fmt.Errorf("cannot list tenant parent: %w", err)
+	}
+	for _, child := range children.Items {
+		if child.Name == user {
+			return child.ID, nil
+		}
+	}
+
+	resp, err := t.builder.client.CreateNode(ctx, &workflowy.CreateNodeRequest{ParentID: t.parentID, Name: user})
+	if err != nil {
+		return "", fmt.Errorf("cannot create sandbox for %s: %w", user, err)
+	}
+	if resp.ItemID == "" {
+		return "", errors.New("cannot create sandbox: no ID returned")
+	}
+	slog.InfoContext(ctx, "created tenant sandbox", "user", user, "id", resp.ItemID)
+	return resp.ItemID, nil
+}
@@ -0,0 +1,73 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fastRoot = "11111111-2222-3333-4444-555555555555"
+
+// sandboxClient blocks listing the tenant parent until release is closed.
+type sandboxClient struct {
+	workflowy.Client
+	listing chan struct{} // receives when a listing starts
+	release chan struct{}
+	created atomic.Int32
+}
+
+func (c *sandboxClient) ListTargets(ctx context.Context) (*workflowy.ListTargetsResponse, error) {
+	return &workflowy.ListTargetsResponse{}, nil
+}
+
+func (c *sandboxClient) ListChildren(ctx context.Context, itemID string) (*workflowy.ListChildrenResponse, error) {
+	c.listing <- struct{}{}
+	<-c.release
+	return &workflowy.ListChildrenResponse{}, nil
+}
+
+func (c *sandboxClient) CreateNode(ctx context.Context, req *workflowy.CreateNodeRequest) (*workflowy.CreateNodeResponse, error) {
+	c.created.Add(1)
+	return &workflowy.CreateNodeResponse{ItemID: "sandbox-" + req.Name}, nil
+}
+
+func TestTenantRouter_SlowSandboxDoesNotBlockOthers(t *testing.T) {
+	client := &sandboxClient{listing: make(chan struct{}, 2), release: make(chan struct{})}
+	router := newTenantRouter(NewToolBuilder(client, "", ""), []string{ToolGet}, Config{
+		Tenants:        map[string]Tenant{"fast": {RootID: fastRoot}},
+		TenantParentID: "parent",
+	})
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := router.handlersFor(context.Background(), "slow")
+			assert.NoError(t, err)
+		}()
+	}
+	<-client.listing
+
+	done := make(chan error)
+	go func() {
+		_, err := router.handlersFor(context.Background(), "fast")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("a user waited for the sandbox of another")
+	}
+
+	close(client.release)
+	wg.Wait()
+	assert.Equal(t, int32(1), client.created.Load(), "concurrent first calls create a single sandbox")
+}
@@ -6,36 +6,56 @@ import (
 	"log/slog"
 	"regexp"
 	"strings"
+	"time"
 
 	mcptypes "github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/mholzen/workflowy/pkg/cache"
+	"github.com/mholzen/workflowy/pkg/escape"
+	"github.com/mholzen/workflowy/pkg/filters"
 	"github.com/mholzen/workflowy/pkg/mirror"
+	"github.com/mholzen/workflowy/pkg/move"
+	"github.com/mholzen/workflowy/pkg/patch"
 	"github.com/mholzen/workflowy/pkg/replace"
 	"github.com/mholzen/workflowy/pkg/reports"
 	"github.com/mholzen/workflowy/pkg/search"
+	"github.com/mholzen/workflowy/pkg/secrets"
+	"github.com/mholzen/workflowy/pkg/simulate"
 	"github.com/mholzen/workflowy/pkg/transform"
 	"github.com/mholzen/workflowy/pkg/workflowy"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
-	ToolGet            = "workflowy_get"
-	ToolList           = "workflowy_list"
-	ToolSearch         = "workflowy_search"
-	ToolTargets        = "workflowy_targets"
-	ToolID             = "workflowy_id"
-	ToolCreate         = "workflowy_create"
-	ToolUpdate         = "workflowy_update"
-	ToolMove           = "workflowy_move"
-	ToolDelete         = "workflowy_delete"
-	ToolComplete       = "workflowy_complete"
-	ToolUncomplete     = "workflowy_uncomplete"
-	ToolReportCount    = "workflowy_report_count"
-	ToolReportChildren = "workflowy_report_children"
-	ToolReportCreated  = "workflowy_report_created"
-	ToolReportModified = "workflowy_report_modified"
-	ToolReportMirrors  = "workflowy_report_mirrors"
-	ToolReplace        = "workflowy_replace"
-	ToolTransform      = "workflowy_transform"
+	ToolGet             = "workflowy_get"
+	ToolList            = "workflowy_list"
+	ToolSearch          = "workflowy_search"
+	ToolSavedSearches   = "workflowy_saved_searches"
+	ToolTargets         = "workflowy_targets"
+	ToolPermissions     = "workflowy_permissions"
+	ToolID              = "workflowy_id"
+	ToolLimits          = "workflowy_limits"
+	ToolExport          = "workflowy_export"
+	ToolCreate          = "workflowy_create"
+	ToolUpdate          = "workflowy_update"
+	ToolMove            = "workflowy_move"
+	ToolDelete          = "workflowy_delete"
+	ToolComplete        = "workflowy_complete"
+	ToolUncomplete      = "workflowy_uncomplete"
+	ToolReportCount     = "workflowy_report_count"
+	ToolReportChildren  = "workflowy_report_children"
+	ToolReportCreated   = "workflowy_report_created"
+	ToolReportModified  = "workflowy_report_modified"
+	ToolReportTags      = "workflowy_report_tags"
+	ToolReportCompleted = "workflowy_report_completed"
+	ToolReportStale     = "workflowy_report_stale"
+	ToolReportExposure  = "workflowy_report_exposure"
+	ToolReportDepth     = "workflowy_report_depth"
+	ToolReportMirrors   = "workflowy_report_mirrors"
+	ToolReplace         = "workflowy_replace"
+	ToolTransform       = "workflowy_transform"
+	ToolApplyPlan       = "workflowy_apply_plan"
+	ToolSimulate        = "workflowy_simulate"
 )
 
 // ToolBuilder wires Workflowy operations into MCP tool handlers.
@@ -43,13 +63,61 @@ type ToolBuilder struct {
 	client      workflowy.Client
 	writeRootID string
 	readRootID  string
+	plans       *PlanStore
+	exports     *singleflight.Group
+	method      string   // get, export or backup; empty chooses by depth
+	backupFile  string   // backup read by the backup method; empty reads the latest
+	tools       []string // tools being built, as reported by the permissions tool
+
+	maxBackupAge time.Duration // backups older than this are refused (0 for no limit)
+	force        bool          // writes ignore #locked nodes
+	trashID      string        // node soft-deleted nodes are moved to; empty for a "Trash" node
 }
 
 // NewToolBuilder creates a builder bound to the provided Workflowy client.
 // If writeRootID is set, write operations are restricted to that node and its descendants.
 // If readRootID is set, all operations are restricted to that node and its descendants.
 func NewToolBuilder(client workflowy.Client, writeRootID, readRootID string) ToolBuilder {
-	return ToolBuilder{client: client, writeRootID: writeRootID, readRootID: readRootID}
+	return ToolBuilder{client: client, writeRootID: writeRootID, readRootID: readRootID, plans: NewPlanStore(), exports: &singleflight.Group{}}
+}
+
+// WithAccessMethod returns a copy of the builder that reads with method, as
+// the CLI's --method flag: "get" and "export" always use that API, "backup"
+// reads backupFile (or the latest backup) and "cache" the tree cache, both
+// without calling the API, and ""
+// chooses by depth, reading the backup only without a client.
+// Write tools always work from the live tree.
+func (b ToolBuilder) WithAccessMethod(method, backupFile string) (ToolBuilder, error) {
+	switch method {
+	case "", "get", "export", "backup", "cache":
+	default:
+		return b, fmt.Errorf("method must be 'get', 'export', 'backup' or 'cache'")
+	}
+	b.method = method
+	b.backupFile = backupFile
+	return b, nil
+}
+
+// WithMaxBackupAge returns a copy of the builder that refuses to read backups
+// older than maxAge, as the CLI's --max-backup-age flag. Backups older than
+// a day are always logged as stale.
+func (b ToolBuilder) WithMaxBackupAge(maxAge time.Duration) ToolBuilder {
+	b.maxBackupAge = maxAge
+	return b
+}
+
+// WithForce returns a copy of the builder whose write tools modify nodes
+// locked with #locked, as the CLI's --force flag.
+func (b ToolBuilder) WithForce(force bool) ToolBuilder {
+	b.force = force
+	return b
+}
+
+// WithTrash returns a copy of the builder whose soft deletes move nodes to
+// trashID, as the CLI's --trash-id flag.
+func (b ToolBuilder) WithTrash(trashID string) ToolBuilder {
+	b.trashID = trashID
+	return b
 }
 
 // isRestricted returns true if write restrictions are in effect.
@@ -67,11 +135,13 @@ func (b ToolBuilder) validateReadTarget(ctx context.Context, targetID, operation
 	if !b.isReadRestricted() {
 		return nil
 	}
-	items, err := b.loadExportTree(ctx)
+	items, err := b.loadTree(ctx)
 	if err != nil {
 		return fmt.Errorf("cannot load tree for read validation: %w", err)
 	}
-	return workflowy.ValidateReadAccess(items, b.readRootID, targetID, operation)
+	err = workflowy.ValidateReadAccess(items, b.readRootID, targetID, operation)
+	slog.DebugContext(ctx, "read guard check", "target_id", targetID, "operation", operation, "allowed", err == nil)
+	return err
 }
 
 // defaultReadID returns the readRootID when itemID is "None" and read restrictions are in effect.
@@ -93,31 +163,72 @@ func (b ToolBuilder) readRestrictionNote() string {
 	return fmt.Sprintf(" (restricted to %s and descendants)", b.readRootID)
 }
 
-// validateWriteTarget checks if the target is within the write-root scope.
+// validateWriteTarget checks if the target is within the write-root scope and not locked.
 func (b ToolBuilder) validateWriteTarget(ctx context.Context, targetID, operation string) error {
-	if !b.isRestricted() {
+	if !b.isRestricted() && b.force {
 		return nil
 	}
 	items, err := b.loadExportTree(ctx)
 	if err != nil {
 		return fmt.Errorf("cannot load tree for write validation: %w", err)
 	}
-	return workflowy.ValidateWriteAccess(items, b.writeRootID, targetID, operation)
+	if b.isRestricted() {
+		err = workflowy.ValidateWriteAccess(items, b.writeRootID, targetID, operation)
+		slog.DebugContext(ctx, "write guard check", "target_id", targetID, "operation", operation, "allowed", err == nil)
+		if err != nil {
+			return err
+		}
+	}
+	return b.writeLocks(items).Check(targetID, operation)
+}
+
+// validateWriteSubtree checks, in addition to validateWriteTarget, that the
+// target has no locked descendant, for writes to the whole subtree such as delete.
+func (b ToolBuilder) validateWriteSubtree(ctx context.Context, targetID, operation string) error {
+	if err := b.validateWriteTarget(ctx, targetID, operation); err != nil || b.force {
+		return err
+	}
+	items, err := b.loadExportTree(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot load tree for write validation: %w", err)
+	}
+	return b.writeLocks(items).CheckSubtree(targetID, operation)
 }
 
-// validateWriteParent checks if the parent is within the write-root scope.
+// validateWriteParent checks if the parent is within the write-root scope and not locked.
 func (b ToolBuilder) validateWriteParent(ctx context.Context, parentID, operation string) error {
 	if !b.isRestricted() {
-		return nil
+		if b.force || parentID == "None" || parentID == "" {
+			return nil
+		}
+		items, err := b.loadExportTree(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot load tree for write validation: %w", err)
+		}
+		return b.writeLocks(items).Check(parentID, operation)
 	}
 	if parentID == "None" || parentID == "" {
-		return fmt.Errorf("%s denied: cannot use root as parent when write-root-id is set to %s", operation, b.writeRootID)
+		return workflowy.Errorf(workflowy.ErrAccessDenied, "%s denied: cannot use root as parent when write-root-id is set to %s", operation, b.writeRootID)
 	}
 	items, err := b.loadExportTree(ctx)
 	if err != nil {
 		return fmt.Errorf("cannot load tree for write validation: %w", err)
 	}
-	return workflowy.ValidateWriteAccess(items, b.writeRootID, parentID, operation)
+	err = workflowy.ValidateWriteAccess(items, b.writeRootID, parentID, operation)
+	slog.DebugContext(ctx, "write guard check", "parent_id", parentID, "operation", operation, "allowed", err == nil)
+	if err != nil {
+		return err
+	}
+	return b.writeLocks(items).Check(parentID, operation)
+}
+
+// writeLocks returns the locked nodes of items that writes must leave alone,
+// none when the server was started with --force.
+func (b ToolBuilder) writeLocks(items []*workflowy.Item) workflowy.Locks {
+	if b.force {
+		return workflowy.Locks{}
+	}
+	return workflowy.FindLocks(items)
 }
 
 // defaultParent returns the write-root-id if parentID is "None" and restrictions are in effect.
@@ -141,25 +252,37 @@ func (b ToolBuilder) writeRestrictionNote() string {
 
 // BuildTools constructs the requested tools in the order provided.
 func (b ToolBuilder) BuildTools(toolNames []string) ([]mcpserver.ServerTool, error) {
+	b.tools = toolNames
 	factories := map[string]func() mcpserver.ServerTool{
-		ToolGet:            b.buildGetTool,
-		ToolList:           b.buildListTool,
-		ToolSearch:         b.buildSearchTool,
-		ToolTargets:        b.buildTargetsTool,
-		ToolID:             b.buildIDTool,
-		ToolCreate:         b.buildCreateTool,
-		ToolUpdate:         b.buildUpdateTool,
-		ToolMove:           b.buildMoveTool,
-		ToolDelete:         b.buildDeleteTool,
-		ToolComplete:       b.buildCompleteTool,
-		ToolUncomplete:     b.buildUncompleteTool,
-		ToolReportCount:    b.buildReportCountTool,
-		ToolReportChildren: b.buildReportChildrenTool,
-		ToolReportCreated:  b.buildReportCreatedTool,
-		ToolReportModified: b.buildReportModifiedTool,
-		ToolReportMirrors:  b.buildReportMirrorsTool,
-		ToolReplace:        b.buildReplaceTool,
-		ToolTransform:      b.buildTransformTool,
+		ToolGet:             b.buildGetTool,
+		ToolList:            b.buildListTool,
+		ToolSearch:          b.buildSearchTool,
+		ToolSavedSearches:   b.buildSavedSearchesTool,
+		ToolTargets:         b.buildTargetsTool,
+		ToolPermissions:     b.buildPermissionsTool,
+		ToolID:              b.buildIDTool,
+		ToolLimits:          b.buildLimitsTool,
+		ToolExport:          b.buildExportTool,
+		ToolCreate:          b.buildCreateTool,
+		ToolUpdate:          b.buildUpdateTool,
+		ToolMove:            b.buildMoveTool,
+		ToolDelete:          b.buildDeleteTool,
+		ToolComplete:        b.buildCompleteTool,
+		ToolUncomplete:      b.buildUncompleteTool,
+		ToolReportCount:     b.buildReportCountTool,
+		ToolReportChildren:  b.buildReportChildrenTool,
+		ToolReportCreated:   b.buildReportCreatedTool,
+		ToolReportModified:  b.buildReportModifiedTool,
+		ToolReportTags:      b.buildReportTagsTool,
+		ToolReportCompleted: b.buildReportCompletedTool,
+		ToolReportStale:     b.buildReportStaleTool,
+		ToolReportExposure:  b.buildReportExposureTool,
+		ToolReportDepth:     b.buildReportDepthTool,
+		ToolReportMirrors:   b.buildReportMirrorsTool,
+		ToolReplace:         b.buildReplaceTool,
+		ToolTransform:       b.buildTransformTool,
+		ToolApplyPlan:       b.buildApplyPlanTool,
+		ToolSimulate:        b.buildSimulateTool,
 	}
 
 	var tools []mcpserver.ServerTool
@@ -190,26 +313,48 @@ func (b ToolBuilder) buildGetTool() mcpserver.ServerTool {
 				mcptypes.Description("Include items with empty names"),
 				mcptypes.DefaultBool(false),
 			),
+			mcptypes.WithBoolean("resolve_mirrors",
+				mcptypes.Description("Show the content of the original in place of each mirror copy, marked with mirror_of (reads the backup file)"),
+				mcptypes.DefaultBool(false),
+			),
+			mcptypes.WithBoolean("include_breadcrumb",
+				mcptypes.Description("Include the names of the two top ancestors of each node (loads the whole tree)"),
+				mcptypes.DefaultBool(false),
+			),
+			withCompleted(),
 		),
 		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
 			rawItemID := b.defaultReadID(req.GetString("id", "None"))
 			depth := req.GetInt("depth", 2)
 			includeEmpty := req.GetBool("include_empty_names", false)
+			resolveMirrors := req.GetBool("resolve_mirrors", false)
+			completed := req.GetString("completed", workflowy.CompletedInclude)
+			if err := workflowy.ValidateCompletedMode(completed); err != nil {
+				return errorResult(err), nil
+			}
 
 			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve ID", err), nil
+				return errorResultFromErr("cannot resolve ID", err), nil
 			}
 
 			if err := b.validateReadTarget(ctx, itemID, "get"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 
-			result, err := b.fetchItems(ctx, itemID, depth)
+			result, err := b.fetchOrResolveMirrors(ctx, itemID, depth, resolveMirrors)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot get item", err), nil
+				return errorResultFromErr("cannot get item", err), nil
 			}
 
+			result = workflowy.FilterCompletedTree(result, completed)
+			if item, ok := result.(*workflowy.Item); ok && req.GetBool("include_breadcrumb", false) {
+				items, err := b.loadTree(ctx)
+				if err != nil {
+					return errorResultFromErr("cannot load tree", err), nil
+				}
+				workflowy.AddItemBreadcrumbs([]*workflowy.Item{item}, items)
+			}
 			if !includeEmpty {
 				switch v := result.(type) {
 				case *workflowy.Item:
@@ -241,32 +386,77 @@ func (b ToolBuilder) buildListTool() mcpserver.ServerTool {
 				mcptypes.Description("Include items with empty names"),
 				mcptypes.DefaultBool(false),
 			),
+			mcptypes.WithNumber("offset",
+				mcptypes.Description("Skip this many items of the flattened list; pass next_offset from the previous page"),
+				mcptypes.DefaultNumber(0),
+			),
+			mcptypes.WithNumber("limit",
+				mcptypes.Description("Return at most this many items (default: all). Use with offset to page through large outlines"),
+				mcptypes.DefaultNumber(0),
+			),
+			mcptypes.WithBoolean("resolve_mirrors",
+				mcptypes.Description("Show the content of the original in place of each mirror copy, marked with mirror_of (reads the backup file)"),
+				mcptypes.DefaultBool(false),
+			),
+			mcptypes.WithBoolean("include_breadcrumb",
+				mcptypes.Description("Include the names of the two top ancestors of each node (loads the whole tree)"),
+				mcptypes.DefaultBool(false),
+			),
+			withCompleted(),
 		),
 		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
 			rawItemID := b.defaultReadID(req.GetString("id", "None"))
 			depth := req.GetInt("depth", 2)
 			includeEmpty := req.GetBool("include_empty_names", false)
+			resolveMirrors := req.GetBool("resolve_mirrors", false)
+			completed := req.GetString("completed", workflowy.CompletedInclude)
+			if err := workflowy.ValidateCompletedMode(completed); err != nil {
+				return errorResult(err), nil
+			}
+			offset := req.GetInt("offset", 0)
+			limit := req.GetInt("limit", 0)
+			if offset < 0 || limit < 0 {
+				return mcptypes.NewToolResultError("offset and limit must be non-negative"), nil
+			}
 
 			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve ID", err), nil
+				return errorResultFromErr("cannot resolve ID", err), nil
 			}
 
 			if err := b.validateReadTarget(ctx, itemID, "list"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 
-			data, err := b.fetchItems(ctx, itemID, depth)
+			data, err := b.fetchOrResolveMirrors(ctx, itemID, depth, resolveMirrors)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot list items", err), nil
+				return errorResultFromErr("cannot list items", err), nil
 			}
 
-			flattened := workflowy.FlattenTree(data)
+			flattened := workflowy.FlattenTree(workflowy.FilterCompletedTree(data, completed))
+			if completed == workflowy.CompletedOnly {
+				flattened.Items = workflowy.CompletedItems(flattened.Items)
+			}
 			if !includeEmpty {
 				flattened = workflowy.FilterEmptyList(flattened)
 			}
+			if req.GetBool("include_breadcrumb", false) {
+				items, err := b.loadTree(ctx)
+				if err != nil {
+					return errorResultFromErr("cannot load tree", err), nil
+				}
+				workflowy.AddItemBreadcrumbs(flattened.Items, items)
+			}
 
-			return mcptypes.NewToolResultJSON(map[string]any{"items": flattened.Items})
+			if offset == 0 && limit == 0 {
+				return mcptypes.NewToolResultJSON(map[string]any{"items": flattened.Items})
+			}
+			page := workflowy.Paginate(flattened, offset, limit)
+			result := map[string]any{"items": page.Items, "total": page.Total, "offset": page.Offset}
+			if page.NextOffset > 0 {
+				result["next_offset"] = page.NextOffset
+			}
+			return mcptypes.NewToolResultJSON(result)
 		},
 	}
 }
@@ -275,13 +465,15 @@ func (b ToolBuilder) buildSearchTool() mcpserver.ServerTool {
 	return mcpserver.ServerTool{
 		Tool: mcptypes.NewTool(
 			ToolSearch,
-			mcptypes.WithDescription("Search node names by text or regular expression"+b.readRestrictionNote()),
+			mcptypes.WithDescription("Search node names, and optionally notes, by text or regular expression"+b.readRestrictionNote()),
 			mcptypes.WithString("pattern",
-				mcptypes.Description("Search text or regular expression"),
-				mcptypes.Required(),
+				mcptypes.Description("Search text or regular expression (required unless saved is given)"),
+			),
+			mcptypes.WithString("saved",
+				mcptypes.Description("Name of a saved search to run instead of pattern (see "+ToolSavedSearches+")"),
 			),
 			mcptypes.WithString("id",
-				mcptypes.Description("ID to search within (default: root)"),
+				mcptypes.Description("ID to search within (default: root, or the saved search's root)"),
 				mcptypes.DefaultString("None"),
 			),
 			mcptypes.WithBoolean("regexp",
@@ -292,34 +484,72 @@ func (b ToolBuilder) buildSearchTool() mcpserver.ServerTool {
 				mcptypes.Description("Case-insensitive search"),
 				mcptypes.DefaultBool(false),
 			),
+			mcptypes.WithBoolean("include_breadcrumb",
+				mcptypes.Description("Include the names of the two top ancestors with each result"),
+				mcptypes.DefaultBool(false),
+			),
+			mcptypes.WithString("fields",
+				mcptypes.Description("Comma-separated fields to search: name, note"),
+				mcptypes.DefaultString(search.FieldName),
+			),
+			withCompleted(),
 		),
 		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
 			pattern := strings.TrimSpace(req.GetString("pattern", ""))
-			if pattern == "" {
+			fields, err := search.ParseFields(req.GetString("fields", search.FieldName))
+			if err != nil {
+				return errorResultFromErr("invalid fields", err), nil
+			}
+			filter := filters.Filter{
+				Pattern:    pattern,
+				Regexp:     req.GetBool("regexp", false),
+				IgnoreCase: req.GetBool("ignore_case", false),
+				Fields:     fields,
+				Completed:  req.GetString("completed", workflowy.CompletedInclude),
+			}
+			rawItemID := req.GetString("id", "None")
+			if name := req.GetString("saved", ""); name != "" {
+				if pattern != "" {
+					return mcptypes.NewToolResultError("pattern cannot be given with saved"), nil
+				}
+				saved, err := filters.LoadDefault()
+				if err != nil {
+					return errorResultFromErr("cannot load saved searches", err), nil
+				}
+				if filter, err = filters.Find(saved, name); err != nil {
+					return errorResult(err), nil
+				}
+				if completed := req.GetString("completed", ""); completed != "" {
+					filter.Completed = completed
+				}
+				if (rawItemID == "None" || rawItemID == "") && filter.RootID != "" {
+					rawItemID = filter.RootID
+				}
+			} else if pattern == "" {
 				return mcptypes.NewToolResultError("pattern is required"), nil
 			}
-
-			rawItemID := b.defaultReadID(req.GetString("id", "None"))
-			useRegexp := req.GetBool("regexp", false)
-			ignoreCase := req.GetBool("ignore_case", false)
+			if err := workflowy.ValidateCompletedMode(filter.Completed); err != nil {
+				return errorResult(err), nil
+			}
+			rawItemID = b.defaultReadID(rawItemID)
 
 			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve ID", err), nil
+				return errorResultFromErr("cannot resolve ID", err), nil
 			}
 
 			if err := b.validateReadTarget(ctx, itemID, "search"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 
-			items, err := b.loadExportTree(ctx)
+			items, err := b.loadTree(ctx)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot load tree for search", err), nil
+				return errorResultFromErr("cannot load tree for search", err), nil
 			}
 
 			rootItem := workflowy.FindRootItem(items, itemID)
 			if rootItem == nil && itemID != "None" {
-				return mcptypes.NewToolResultErrorf("item not found: %s", itemID), nil
+				return errorResult(workflowy.Errorf(workflowy.ErrNotFound, "item not found: %s", itemID)), nil
 			}
 
 			searchRoot := items
@@ -327,12 +557,34 @@ func (b ToolBuilder) buildSearchTool() mcpserver.ServerTool {
 				searchRoot = []*workflowy.Item{rootItem}
 			}
 
-			results := search.SearchItems(searchRoot, pattern, useRegexp, ignoreCase)
+			results := filter.Search(searchRoot)
+			if req.GetBool("include_breadcrumb", false) {
+				workflowy.AddBreadcrumbs(results, items)
+			}
 			return mcptypes.NewToolResultJSON(map[string]any{"results": results})
 		},
 	}
 }
 
+func (b ToolBuilder) buildSavedSearchesTool() mcpserver.ServerTool {
+	return mcpserver.ServerTool{
+		Tool: mcptypes.NewTool(
+			ToolSavedSearches,
+			mcptypes.WithDescription("List the saved searches defined in filters.yaml, by name, with their pattern, fields, root and tags. Run one with "+ToolSearch+" and its saved parameter"),
+		),
+		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
+			saved, err := filters.LoadDefault()
+			if err != nil {
+				return errorResultFromErr("cannot load saved searches", err), nil
+			}
+			if saved == nil {
+				saved = []filters.Filter{}
+			}
+			return mcptypes.NewToolResultJSON(map[string]any{"saved_searches": saved})
+		},
+	}
+}
+
 func (b ToolBuilder) buildTargetsTool() mcpserver.ServerTool {
 	return mcpserver.ServerTool{
 		Tool: mcptypes.NewTool(
@@ -342,13 +594,13 @@ func (b ToolBuilder) buildTargetsTool() mcpserver.ServerTool {
 		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
 			response, err := b.client.ListTargets(ctx)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot list targets", err), nil
+				return errorResultFromErr("cannot list targets", err), nil
 			}
 
 			result := map[string]any{"targets": response.Targets}
 
 			if b.isRestricted() || b.isReadRestricted() {
-				items, err := b.loadExportTree(ctx)
+				items, err := b.loadTree(ctx)
 
 				if b.isRestricted() {
 					writeRoot := map[string]string{"id": b.writeRootID}
@@ -394,7 +646,7 @@ func (b ToolBuilder) buildIDTool() mcpserver.ServerTool {
 
 			fullID, err := workflowy.ResolveNodeID(ctx, b.client, rawID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve ID", err), nil
+				return errorResultFromErr("cannot resolve ID", err), nil
 			}
 
 			return mcptypes.NewToolResultJSON(map[string]string{"id": fullID})
@@ -402,6 +654,76 @@ func (b ToolBuilder) buildIDTool() mcpserver.ServerTool {
 	}
 }
 
+func (b ToolBuilder) buildLimitsTool() mcpserver.ServerTool {
+	return mcpserver.ServerTool{
+		Tool: mcptypes.NewTool(
+			ToolLimits,
+			mcptypes.WithDescription("Show the Workflowy API rate limit: requests remaining and when the quota resets. Use before bulk operations to pace them."),
+		),
+		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
+			limit, known, err := workflowy.RateLimit(ctx, b.client)
+			if err != nil {
+				return errorResultFromErr("cannot get rate limit", err), nil
+			}
+			if !known {
+				return mcptypes.NewToolResultJSON(map[string]any{"reported": false})
+			}
+			return mcptypes.NewToolResultJSON(limit)
+		},
+	}
+}
+
+func (b ToolBuilder) buildExportTool() mcpserver.ServerTool {
+	return mcpserver.ServerTool{
+		Tool: mcptypes.NewTool(
+			ToolExport,
+			mcptypes.WithDescription("Export all nodes as a flat list with parent_id, priority, completed and timestamps, for analyzing the whole tree in one call. Served from a cache refreshed at most once a minute"+b.readRestrictionNote()),
+			mcptypes.WithString("id",
+				mcptypes.Description("Only export this node and its descendants (default: all nodes)"),
+				mcptypes.DefaultString("None"),
+			),
+			mcptypes.WithBoolean("force_refresh",
+				mcptypes.Description("Fetch from the API instead of the cache"),
+				mcptypes.DefaultBool(false),
+			),
+		),
+		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
+			rawItemID := b.defaultReadID(req.GetString("id", "None"))
+			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
+			if err != nil {
+				return errorResultFromErr("cannot resolve ID", err), nil
+			}
+
+			if err := b.validateReadTarget(ctx, itemID, "export"); err != nil {
+				return errorResult(err), nil
+			}
+
+			resp, err := b.client.ExportNodesWithCache(ctx, req.GetBool("force_refresh", false))
+			if err != nil {
+				return errorResultFromErr("cannot export nodes", err), nil
+			}
+
+			nodes := resp.Nodes
+			if itemID != "None" {
+				nodes = workflowy.ExportSubtree(nodes, itemID)
+				if len(nodes) == 0 {
+					return errorResult(workflowy.Errorf(workflowy.ErrNotFound, "item %s not found", itemID)), nil
+				}
+			}
+
+			result := map[string]any{"nodes": nodes, "count": len(nodes)}
+			if resp.Partial {
+				// partial exports are not cached, so the cache does not describe them
+				result["partial"] = true
+			} else if cached, err := cache.ReadExportCache(); err == nil && cached != nil {
+				result["cached_at"] = time.Unix(cached.Timestamp, 0).UTC()
+				result["cache_age_seconds"] = int(cache.GetCacheAge(cached).Seconds())
+			}
+			return mcptypes.NewToolResultJSON(result)
+		},
+	}
+}
+
 func (b ToolBuilder) buildCreateTool() mcpserver.ServerTool {
 	return mcpserver.ServerTool{
 		Tool: mcptypes.NewTool(
@@ -424,6 +746,10 @@ func (b ToolBuilder) buildCreateTool() mcpserver.ServerTool {
 			mcptypes.WithString("note",
 				mcptypes.Description("Optional note content"),
 			),
+			mcptypes.WithBoolean("markdown",
+				mcptypes.Description("Convert markdown (**bold**, _italic_, ~~strike~~, `code`, [link](url)) in name and note to Workflowy formatting"),
+				mcptypes.DefaultBool(false),
+			),
 		),
 		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
 			name := strings.TrimSpace(req.GetString("name", ""))
@@ -439,14 +765,19 @@ func (b ToolBuilder) buildCreateTool() mcpserver.ServerTool {
 
 			parentID, err := workflowy.ResolveNodeID(ctx, b.client, rawParentID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve parent ID", err), nil
+				return errorResultFromErr("cannot resolve parent ID", err), nil
 			}
 
 			if err := b.validateReadTarget(ctx, parentID, "create"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 			if err := b.validateWriteParent(ctx, parentID, "create"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
+			}
+
+			if req.GetBool("markdown", false) {
+				name = escape.FromMarkdown(name)
+				note = escape.FromMarkdown(note)
 			}
 
 			request := &workflowy.CreateNodeRequest{
@@ -454,7 +785,7 @@ func (b ToolBuilder) buildCreateTool() mcpserver.ServerTool {
 				Name:     name,
 			}
 			if err := request.SetPosition(strings.TrimSpace(req.GetString("position", ""))); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 			if layoutMode != "" {
 				request.LayoutMode = &layoutMode
@@ -465,7 +796,7 @@ func (b ToolBuilder) buildCreateTool() mcpserver.ServerTool {
 
 			response, err := b.client.CreateNode(ctx, request)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot create node", err), nil
+				return errorResultFromErr("cannot create node", err), nil
 			}
 
 			return mcptypes.NewToolResultJSON(response)
@@ -488,9 +819,16 @@ func (b ToolBuilder) buildUpdateTool() mcpserver.ServerTool {
 			mcptypes.WithString("note",
 				mcptypes.Description("New note content"),
 			),
+			mcptypes.WithString("append_note",
+				mcptypes.Description("Text to add to the end of the current note, on a new line, instead of replacing it"),
+			),
 			mcptypes.WithString("layout_mode",
 				mcptypes.Description("Display mode: bullets, todo, h1, h2, h3"),
 			),
+			mcptypes.WithBoolean("markdown",
+				mcptypes.Description("Convert markdown (**bold**, _italic_, ~~strike~~, `code`, [link](url)) in name, note and append_note to Workflowy formatting"),
+				mcptypes.DefaultBool(false),
+			),
 		),
 		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
 			rawItemID := strings.TrimSpace(req.GetString("id", ""))
@@ -500,19 +838,28 @@ func (b ToolBuilder) buildUpdateTool() mcpserver.ServerTool {
 
 			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve ID", err), nil
+				return errorResultFromErr("cannot resolve ID", err), nil
 			}
 
 			if err := b.validateReadTarget(ctx, itemID, "update"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 			if err := b.validateWriteTarget(ctx, itemID, "update"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 
 			name := strings.TrimSpace(req.GetString("name", ""))
 			note := strings.TrimSpace(req.GetString("note", ""))
+			appendNote := strings.TrimSpace(req.GetString("append_note", ""))
 			layoutMode := strings.TrimSpace(req.GetString("layout_mode", ""))
+			if req.GetBool("markdown", false) {
+				name = escape.FromMarkdown(name)
+				note = escape.FromMarkdown(note)
+				appendNote = escape.FromMarkdown(appendNote)
+			}
+			if note != "" && appendNote != "" {
+				return mcptypes.NewToolResultError("specify either note or append_note, not both"), nil
+			}
 
 			request := &workflowy.UpdateNodeRequest{}
 
@@ -526,13 +873,26 @@ func (b ToolBuilder) buildUpdateTool() mcpserver.ServerTool {
 				request.LayoutMode = &layoutMode
 			}
 
+			if appendNote != "" {
+				item, err := b.client.GetItem(ctx, itemID)
+				if err != nil {
+					return errorResultFromErr("cannot get node", err), nil
+				}
+				var current string
+				if item.Note != nil {
+					current = *item.Note
+				}
+				note = workflowy.JoinNotes(current, appendNote)
+				request.Note = &note
+			}
+
 			if request.Name == nil && request.Note == nil && request.LayoutMode == nil {
-				return mcptypes.NewToolResultError("specify at least one of name, note, or layout_mode"), nil
+				return mcptypes.NewToolResultError("specify at least one of name, note, append_note, or layout_mode"), nil
 			}
 
 			response, err := b.client.UpdateNode(ctx, itemID, request)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot update node", err), nil
+				return errorResultFromErr("cannot update node", err), nil
 			}
 
 			return mcptypes.NewToolResultJSON(response)
@@ -540,14 +900,94 @@ func (b ToolBuilder) buildUpdateTool() mcpserver.ServerTool {
 	}
 }
 
+// moveMatching is the bulk mode of the move tool: it moves every node of
+// scope_id whose name matches pattern under parent_id.
+func (b ToolBuilder) moveMatching(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
+	rawParentID := strings.TrimSpace(req.GetString("parent_id", ""))
+	if rawParentID == "" {
+		return mcptypes.NewToolResultError("parent_id is required"), nil
+	}
+	destination, err := workflowy.ResolveNodeID(ctx, b.client, rawParentID)
+	if err != nil {
+		return errorResultFromErr("cannot resolve parent ID", err), nil
+	}
+	scopeID, err := workflowy.ResolveNodeID(ctx, b.client, req.GetString("scope_id", "None"))
+	if err != nil {
+		return errorResultFromErr("cannot resolve scope ID", err), nil
+	}
+	opts := move.Options{
+		Pattern:     strings.TrimSpace(req.GetString("pattern", "")),
+		Regexp:      req.GetBool("regexp", false),
+		IgnoreCase:  req.GetBool("ignore_case", false),
+		Destination: destination,
+		Depth:       req.GetInt("depth", -1),
+	}
+	if err := opts.Validate(); err != nil {
+		return errorResult(err), nil
+	}
+	position := strings.TrimSpace(req.GetString("position", ""))
+	if err := workflowy.ValidatePosition(position); err != nil {
+		return errorResult(err), nil
+	}
+
+	if err := b.validateReadTarget(ctx, scopeID, "move"); err != nil {
+		return errorResult(err), nil
+	}
+	if err := b.validateReadTarget(ctx, destination, "move destination"); err != nil {
+		return errorResult(err), nil
+	}
+	if err := b.validateWriteTarget(ctx, scopeID, "move"); err != nil {
+		return errorResult(err), nil
+	}
+	if err := b.validateWriteParent(ctx, destination, "move"); err != nil {
+		return errorResult(err), nil
+	}
+
+	items, err := b.loadExportTree(ctx)
+	if err != nil {
+		return errorResultFromErr("cannot load tree", err), nil
+	}
+	searchRoot := items
+	if scopeID != "None" {
+		scope := workflowy.FindItemByID(items, scopeID)
+		if scope == nil {
+			return errorResult(workflowy.Errorf(workflowy.ErrNotFound, "scope item not found: %s", scopeID)), nil
+		}
+		searchRoot = scope.Children
+	}
+
+	results := make([]move.Result, 0)
+	move.Collect(searchRoot, scopeID, opts, 0, &results)
+	locks := b.writeLocks(items)
+	for i := range results {
+		if results[i].Skipped {
+			continue
+		}
+		if err := locks.Check(results[i].ID, "move"); err != nil {
+			results[i].Skipped = true
+			results[i].SkipReason = err.Error()
+		}
+	}
+	if req.GetBool("include_breadcrumb", false) {
+		workflowy.AddBreadcrumbs(results, items)
+	}
+
+	if req.GetBool("dry_run", true) {
+		return mcptypes.NewToolResultJSON(map[string]any{"results": results, "dry_run": true})
+	}
+	if _, err := move.Apply(ctx, b.client, results, destination, position); err != nil {
+		return errorResult(err), nil
+	}
+	return mcptypes.NewToolResultJSON(map[string]any{"results": results})
+}
+
 func (b ToolBuilder) buildMoveTool() mcpserver.ServerTool {
 	return mcpserver.ServerTool{
 		Tool: mcptypes.NewTool(
 			ToolMove,
-			mcptypes.WithDescription("Move a node to a new parent"+b.writeRestrictionNote()),
+			mcptypes.WithDescription("Move a node to a new parent, or, with pattern, every node whose name matches it"+b.writeRestrictionNote()),
 			mcptypes.WithString("id",
-				mcptypes.Description("ID to move"),
-				mcptypes.Required(),
+				mcptypes.Description("ID to move (required unless pattern is given)"),
 			),
 			mcptypes.WithString("parent_id",
 				mcptypes.Description("Destination parent: UUID, target key (home, inbox), or 'None' for top-level"),
@@ -556,11 +996,44 @@ func (b ToolBuilder) buildMoveTool() mcpserver.ServerTool {
 			mcptypes.WithString("position",
 				mcptypes.Description("Position in new parent: top or bottom (default: top)"),
 			),
+			mcptypes.WithString("pattern",
+				mcptypes.Description("Instead of id, move every node whose name contains this text, with its descendants"),
+			),
+			mcptypes.WithBoolean("regexp",
+				mcptypes.Description("Treat pattern as regular expression"),
+				mcptypes.DefaultBool(false),
+			),
+			mcptypes.WithBoolean("ignore_case",
+				mcptypes.Description("Case-insensitive matching of pattern"),
+				mcptypes.DefaultBool(false),
+			),
+			mcptypes.WithString("scope_id",
+				mcptypes.Description("ID to limit pattern to: UUID or target key (default: root)"),
+				mcptypes.DefaultString("None"),
+			),
+			mcptypes.WithNumber("depth",
+				mcptypes.Description("Maximum depth below scope_id to traverse with pattern (-1 for unlimited)"),
+				mcptypes.DefaultNumber(-1),
+			),
+			mcptypes.WithBoolean("dry_run",
+				mcptypes.Description("With pattern, show the nodes that would be moved without moving them"),
+				mcptypes.DefaultBool(true),
+			),
+			mcptypes.WithBoolean("include_breadcrumb",
+				mcptypes.Description("Include the names of the two top ancestors with each result of pattern"),
+				mcptypes.DefaultBool(false),
+			),
 		),
 		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
 			rawItemID := strings.TrimSpace(req.GetString("id", ""))
+			if strings.TrimSpace(req.GetString("pattern", "")) != "" {
+				if rawItemID != "" {
+					return mcptypes.NewToolResultError("specify either id or pattern, not both"), nil
+				}
+				return b.moveMatching(ctx, req)
+			}
 			if rawItemID == "" {
-				return mcptypes.NewToolResultError("id is required"), nil
+				return mcptypes.NewToolResultError("id or pattern is required"), nil
 			}
 
 			rawParentID := strings.TrimSpace(req.GetString("parent_id", ""))
@@ -570,37 +1043,37 @@ func (b ToolBuilder) buildMoveTool() mcpserver.ServerTool {
 
 			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve ID", err), nil
+				return errorResultFromErr("cannot resolve ID", err), nil
 			}
 
 			parentID, err := workflowy.ResolveNodeID(ctx, b.client, rawParentID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve parent ID", err), nil
+				return errorResultFromErr("cannot resolve parent ID", err), nil
 			}
 
 			if err := b.validateReadTarget(ctx, itemID, "move"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 			if err := b.validateReadTarget(ctx, parentID, "move destination"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 			if err := b.validateWriteTarget(ctx, itemID, "move"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 			if err := b.validateWriteParent(ctx, parentID, "move"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 
 			request := &workflowy.MoveNodeRequest{
 				ParentID: parentID,
 			}
 			if err := request.SetPosition(strings.TrimSpace(req.GetString("position", ""))); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 
 			response, err := b.client.MoveNode(ctx, itemID, request)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot move node", err), nil
+				return errorResultFromErr("cannot move node", err), nil
 			}
 
 			return mcptypes.NewToolResultJSON(response)
@@ -612,11 +1085,14 @@ func (b ToolBuilder) buildDeleteTool() mcpserver.ServerTool {
 	return mcpserver.ServerTool{
 		Tool: mcptypes.NewTool(
 			ToolDelete,
-			mcptypes.WithDescription("Delete a node"+b.writeRestrictionNote()),
+			mcptypes.WithDescription("Delete a node, or move it to the trash with soft"+b.writeRestrictionNote()),
 			mcptypes.WithString("id",
 				mcptypes.Description("ID to delete"),
 				mcptypes.Required(),
 			),
+			mcptypes.WithBoolean("soft",
+				mcptypes.Description("Move the node to the trash node, from which it can be moved back, instead of deleting it permanently (default: false)"),
+			),
 		),
 		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
 			rawItemID := strings.TrimSpace(req.GetString("id", ""))
@@ -626,19 +1102,36 @@ func (b ToolBuilder) buildDeleteTool() mcpserver.ServerTool {
 
 			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve ID", err), nil
+				return errorResultFromErr("cannot resolve ID", err), nil
 			}
 
 			if err := b.validateReadTarget(ctx, itemID, "delete"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
-			if err := b.validateWriteTarget(ctx, itemID, "delete"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+			if err := b.validateWriteSubtree(ctx, itemID, "delete"); err != nil {
+				return errorResult(err), nil
+			}
+
+			if req.GetBool("soft", false) {
+				trashID, created, err := workflowy.ResolveTrash(ctx, b.client, b.trashID, b.defaultParent("None"))
+				if err != nil {
+					return errorResult(err), nil
+				}
+				if !created {
+					if err := b.validateWriteParent(ctx, trashID, "delete"); err != nil {
+						return errorResult(err), nil
+					}
+				}
+				response, err := workflowy.MoveToTrash(ctx, b.client, itemID, trashID)
+				if err != nil {
+					return errorResultFromErr("cannot move node to trash", err), nil
+				}
+				return mcptypes.NewToolResultJSON(map[string]string{"id": itemID, "trash_id": trashID, "status": response.Status})
 			}
 
 			response, err := b.client.DeleteNode(ctx, itemID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot delete node", err), nil
+				return errorResultFromErr("cannot delete node", err), nil
 			}
 
 			return mcptypes.NewToolResultJSON(response)
@@ -664,19 +1157,19 @@ func (b ToolBuilder) buildCompleteTool() mcpserver.ServerTool {
 
 			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve ID", err), nil
+				return errorResultFromErr("cannot resolve ID", err), nil
 			}
 
 			if err := b.validateReadTarget(ctx, itemID, "complete"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 			if err := b.validateWriteTarget(ctx, itemID, "complete"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 
 			response, err := b.client.CompleteNode(ctx, itemID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot complete node", err), nil
+				return errorResultFromErr("cannot complete node", err), nil
 			}
 
 			return mcptypes.NewToolResultJSON(response)
@@ -702,19 +1195,19 @@ func (b ToolBuilder) buildUncompleteTool() mcpserver.ServerTool {
 
 			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve ID", err), nil
+				return errorResultFromErr("cannot resolve ID", err), nil
 			}
 
 			if err := b.validateReadTarget(ctx, itemID, "uncomplete"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 			if err := b.validateWriteTarget(ctx, itemID, "uncomplete"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 
 			response, err := b.client.UncompleteNode(ctx, itemID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot uncomplete node", err), nil
+				return errorResultFromErr("cannot uncomplete node", err), nil
 			}
 
 			return mcptypes.NewToolResultJSON(response)
@@ -739,23 +1232,28 @@ func (b ToolBuilder) buildReportCountTool() mcpserver.ServerTool {
 				mcptypes.Description("Preserve HTML tags in output"),
 				mcptypes.DefaultBool(false),
 			),
+			withCompleted(),
 		),
 		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
 			rawItemID := b.defaultReadID(req.GetString("id", "None"))
+			completed := req.GetString("completed", workflowy.CompletedInclude)
+			if err := workflowy.ValidateCompletedMode(completed); err != nil {
+				return errorResult(err), nil
+			}
 			threshold := req.GetFloat("threshold", 0.01)
 
 			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve ID", err), nil
+				return errorResultFromErr("cannot resolve ID", err), nil
 			}
 
 			if err := b.validateReadTarget(ctx, itemID, "report_count"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 
-			root, err := b.buildReportRoot(ctx, itemID)
+			snapshot, root, err := b.buildReportRoot(ctx, itemID, completed)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot load tree", err), nil
+				return errorResultFromErr("cannot load tree", err), nil
 			}
 
 			descendants := workflowy.CountDescendants(root, threshold)
@@ -764,10 +1262,11 @@ func (b ToolBuilder) buildReportCountTool() mcpserver.ServerTool {
 				RootItem:    root,
 				Descendants: descendants,
 				Threshold:   threshold,
+				Snapshot:    snapshot.Meta(),
 			}
 			nodes, err := output.ToNodes()
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot convert to nodes", err), nil
+				return errorResultFromErr("cannot convert to nodes", err), nil
 			}
 			slog.Debug("nodes", "nodes", nodes)
 			return mcptypes.NewToolResultJSON(nodes)
@@ -792,23 +1291,28 @@ func (b ToolBuilder) buildReportChildrenTool() mcpserver.ServerTool {
 				mcptypes.Description("Preserve HTML tags in output"),
 				mcptypes.DefaultBool(false),
 			),
+			withCompleted(),
 		),
 		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
 			rawItemID := b.defaultReadID(req.GetString("id", "None"))
+			completed := req.GetString("completed", workflowy.CompletedInclude)
+			if err := workflowy.ValidateCompletedMode(completed); err != nil {
+				return errorResult(err), nil
+			}
 			topN := req.GetInt("top_n", 20)
 
 			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve ID", err), nil
+				return errorResultFromErr("cannot resolve ID", err), nil
 			}
 
 			if err := b.validateReadTarget(ctx, itemID, "report_children"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 
-			root, err := b.buildReportRoot(ctx, itemID)
+			snapshot, root, err := b.buildReportRoot(ctx, itemID, completed)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot load tree", err), nil
+				return errorResultFromErr("cannot load tree", err), nil
 			}
 
 			descendants := workflowy.CountDescendants(root, 0.0)
@@ -816,8 +1320,9 @@ func (b ToolBuilder) buildReportChildrenTool() mcpserver.ServerTool {
 			ranked := workflowy.RankByChildrenCount(nodesWithTimestamps, topN)
 
 			output := &reports.ChildrenCountReportOutput{
-				Ranked: ranked,
-				TopN:   topN,
+				Ranked:   ranked,
+				TopN:     topN,
+				Snapshot: snapshot.Meta(),
 			}
 
 			return mcptypes.NewToolResultJSON(output)
@@ -842,23 +1347,28 @@ func (b ToolBuilder) buildReportCreatedTool() mcpserver.ServerTool {
 				mcptypes.Description("Preserve HTML tags in output"),
 				mcptypes.DefaultBool(false),
 			),
+			withCompleted(),
 		),
 		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
 			rawItemID := b.defaultReadID(req.GetString("id", "None"))
+			completed := req.GetString("completed", workflowy.CompletedInclude)
+			if err := workflowy.ValidateCompletedMode(completed); err != nil {
+				return errorResult(err), nil
+			}
 			topN := req.GetInt("top_n", 20)
 
 			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve ID", err), nil
+				return errorResultFromErr("cannot resolve ID", err), nil
 			}
 
 			if err := b.validateReadTarget(ctx, itemID, "report_created"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 
-			root, err := b.buildReportRoot(ctx, itemID)
+			snapshot, root, err := b.buildReportRoot(ctx, itemID, completed)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot load tree", err), nil
+				return errorResultFromErr("cannot load tree", err), nil
 			}
 
 			descendants := workflowy.CountDescendants(root, 0.0)
@@ -866,8 +1376,9 @@ func (b ToolBuilder) buildReportCreatedTool() mcpserver.ServerTool {
 			ranked := workflowy.RankByCreated(nodesWithTimestamps, topN)
 
 			output := &reports.CreatedReportOutput{
-				Ranked: ranked,
-				TopN:   topN,
+				Ranked:   ranked,
+				TopN:     topN,
+				Snapshot: snapshot.Meta(),
 			}
 
 			return mcptypes.NewToolResultJSON(output)
@@ -892,23 +1403,28 @@ func (b ToolBuilder) buildReportModifiedTool() mcpserver.ServerTool {
 				mcptypes.Description("Preserve HTML tags in output"),
 				mcptypes.DefaultBool(false),
 			),
+			withCompleted(),
 		),
 		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
 			rawItemID := b.defaultReadID(req.GetString("id", "None"))
+			completed := req.GetString("completed", workflowy.CompletedInclude)
+			if err := workflowy.ValidateCompletedMode(completed); err != nil {
+				return errorResult(err), nil
+			}
 			topN := req.GetInt("top_n", 20)
 
 			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve ID", err), nil
+				return errorResultFromErr("cannot resolve ID", err), nil
 			}
 
 			if err := b.validateReadTarget(ctx, itemID, "report_modified"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 
-			root, err := b.buildReportRoot(ctx, itemID)
+			snapshot, root, err := b.buildReportRoot(ctx, itemID, completed)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot load tree", err), nil
+				return errorResultFromErr("cannot load tree", err), nil
 			}
 
 			descendants := workflowy.CountDescendants(root, 0.0)
@@ -916,8 +1432,270 @@ func (b ToolBuilder) buildReportModifiedTool() mcpserver.ServerTool {
 			ranked := workflowy.RankByModified(nodesWithTimestamps, topN)
 
 			output := &reports.ModifiedReportOutput{
-				Ranked: ranked,
-				TopN:   topN,
+				Ranked:   ranked,
+				TopN:     topN,
+				Snapshot: snapshot.Meta(),
+			}
+
+			return mcptypes.NewToolResultJSON(output)
+		},
+	}
+}
+
+func (b ToolBuilder) buildReportTagsTool() mcpserver.ServerTool {
+	return mcpserver.ServerTool{
+		Tool: mcptypes.NewTool(
+			ToolReportTags,
+			mcptypes.WithDescription("Rank #tags and @mentions in names and notes by number of uses, with the nodes using each tag most"+b.readRestrictionNote()),
+			mcptypes.WithString("id",
+				mcptypes.Description("ID (default: root)"),
+				mcptypes.DefaultString("None"),
+			),
+			mcptypes.WithNumber("top_n",
+				mcptypes.Description("Number of top tags to include (0 for all)"),
+				mcptypes.DefaultNumber(20),
+			),
+			mcptypes.WithNumber("nodes_per_tag",
+				mcptypes.Description("Number of nodes listed under each tag (0 for all)"),
+				mcptypes.DefaultNumber(5),
+			),
+			withCompleted(),
+		),
+		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
+			rawItemID := b.defaultReadID(req.GetString("id", "None"))
+			completed := req.GetString("completed", workflowy.CompletedInclude)
+			if err := workflowy.ValidateCompletedMode(completed); err != nil {
+				return errorResult(err), nil
+			}
+			topN := req.GetInt("top_n", 20)
+
+			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
+			if err != nil {
+				return errorResultFromErr("cannot resolve ID", err), nil
+			}
+
+			if err := b.validateReadTarget(ctx, itemID, "report_tags"); err != nil {
+				return errorResult(err), nil
+			}
+
+			snapshot, root, err := b.buildReportRoot(ctx, itemID, completed)
+			if err != nil {
+				return errorResultFromErr("cannot load tree", err), nil
+			}
+
+			usages := workflowy.CountTags(root, req.GetInt("nodes_per_tag", 5))
+			if topN > 0 && len(usages) > topN {
+				usages = usages[:topN]
+			}
+
+			output := &reports.TagsReportOutput{
+				Tags:     usages,
+				TopN:     topN,
+				Snapshot: snapshot.Meta(),
+			}
+
+			return mcptypes.NewToolResultJSON(output)
+		},
+	}
+}
+
+func (b ToolBuilder) buildReportCompletedTool() mcpserver.ServerTool {
+	return mcpserver.ServerTool{
+		Tool: mcptypes.NewTool(
+			ToolReportCompleted,
+			mcptypes.WithDescription("Count completed nodes per day, week or month, oldest first, to track completion velocity"+b.readRestrictionNote()),
+			mcptypes.WithString("id",
+				mcptypes.Description("ID (default: root)"),
+				mcptypes.DefaultString("None"),
+			),
+			mcptypes.WithString("period",
+				mcptypes.Description("Period to group completions by: day, week (starting Monday) or month"),
+				mcptypes.DefaultString(workflowy.PeriodWeek),
+			),
+		),
+		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
+			rawItemID := b.defaultReadID(req.GetString("id", "None"))
+			period := req.GetString("period", workflowy.PeriodWeek)
+			if err := workflowy.ValidatePeriod(period); err != nil {
+				return errorResult(err), nil
+			}
+
+			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
+			if err != nil {
+				return errorResultFromErr("cannot resolve ID", err), nil
+			}
+
+			if err := b.validateReadTarget(ctx, itemID, "report_completed"); err != nil {
+				return errorResult(err), nil
+			}
+
+			snapshot, root, err := b.buildReportRoot(ctx, itemID, workflowy.CompletedInclude)
+			if err != nil {
+				return errorResultFromErr("cannot load tree", err), nil
+			}
+
+			completions, err := workflowy.CountCompletions(root, period, time.Local)
+			if err != nil {
+				return errorResult(err), nil
+			}
+
+			output := &reports.CompletedReportOutput{
+				Completions: completions,
+				Snapshot:    snapshot.Meta(),
+			}
+
+			return mcptypes.NewToolResultJSON(output)
+		},
+	}
+}
+
+func (b ToolBuilder) buildReportStaleTool() mcpserver.ServerTool {
+	return mcpserver.ServerTool{
+		Tool: mcptypes.NewTool(
+			ToolReportStale,
+			mcptypes.WithDescription("Find the largest subtrees in which no node was modified for a number of days, candidates for archiving"+b.readRestrictionNote()),
+			mcptypes.WithString("id",
+				mcptypes.Description("ID (default: root)"),
+				mcptypes.DefaultString("None"),
+			),
+			mcptypes.WithNumber("days",
+				mcptypes.Description("Report subtrees in which no node was modified in this many days"),
+				mcptypes.DefaultNumber(180),
+			),
+			mcptypes.WithNumber("top_n",
+				mcptypes.Description("Number of top results to include (0 for all)"),
+				mcptypes.DefaultNumber(20),
+			),
+			withCompleted(),
+		),
+		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
+			rawItemID := b.defaultReadID(req.GetString("id", "None"))
+			completed := req.GetString("completed", workflowy.CompletedInclude)
+			if err := workflowy.ValidateCompletedMode(completed); err != nil {
+				return errorResult(err), nil
+			}
+			days := req.GetInt("days", 180)
+			if days <= 0 {
+				return mcptypes.NewToolResultError("days must be positive"), nil
+			}
+			topN := req.GetInt("top_n", 20)
+
+			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
+			if err != nil {
+				return errorResultFromErr("cannot resolve ID", err), nil
+			}
+
+			if err := b.validateReadTarget(ctx, itemID, "report_stale"); err != nil {
+				return errorResult(err), nil
+			}
+
+			snapshot, root, err := b.buildReportRoot(ctx, itemID, completed)
+			if err != nil {
+				return errorResultFromErr("cannot load tree", err), nil
+			}
+
+			subtrees := workflowy.FindStaleSubtrees(root, time.Now().AddDate(0, 0, -days))
+			if topN > 0 && len(subtrees) > topN {
+				subtrees = subtrees[:topN]
+			}
+
+			output := &reports.StaleReportOutput{
+				Subtrees: subtrees,
+				Days:     days,
+				TopN:     topN,
+				Snapshot: snapshot.Meta(),
+			}
+
+			return mcptypes.NewToolResultJSON(output)
+		},
+	}
+}
+
+func (b ToolBuilder) buildReportExposureTool() mcpserver.ServerTool {
+	return mcpserver.ServerTool{
+		Tool: mcptypes.NewTool(
+			ToolReportExposure,
+			mcptypes.WithDescription("Find nodes holding likely secrets (API keys, passwords, card numbers) with the built-in detectors. Secrets are redacted in the results"+b.readRestrictionNote()),
+			mcptypes.WithString("id",
+				mcptypes.Description("ID (default: root)"),
+				mcptypes.DefaultString("None"),
+			),
+			withCompleted(),
+		),
+		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
+			rawItemID := b.defaultReadID(req.GetString("id", "None"))
+			completed := req.GetString("completed", workflowy.CompletedInclude)
+			if err := workflowy.ValidateCompletedMode(completed); err != nil {
+				return errorResult(err), nil
+			}
+
+			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
+			if err != nil {
+				return errorResultFromErr("cannot resolve ID", err), nil
+			}
+
+			if err := b.validateReadTarget(ctx, itemID, "report_exposure"); err != nil {
+				return errorResult(err), nil
+			}
+
+			snapshot, root, err := b.buildReportRoot(ctx, itemID, completed)
+			if err != nil {
+				return errorResultFromErr("cannot load tree", err), nil
+			}
+
+			output := &reports.ExposureReportOutput{
+				Exposures: secrets.Scan(root, secrets.DefaultDetectors()),
+				Snapshot:  snapshot.Meta(),
+			}
+
+			return mcptypes.NewToolResultJSON(output)
+		},
+	}
+}
+
+func (b ToolBuilder) buildReportDepthTool() mcpserver.ServerTool {
+	return mcpserver.ServerTool{
+		Tool: mcptypes.NewTool(
+			ToolReportDepth,
+			mcptypes.WithDescription("Show how deep and wide the outline is: max depth, average branching factor, nodes per level and the paths to the deepest nodes"+b.readRestrictionNote()),
+			mcptypes.WithString("id",
+				mcptypes.Description("ID (default: root)"),
+				mcptypes.DefaultString("None"),
+			),
+			mcptypes.WithNumber("paths",
+				mcptypes.Description("Number of deepest paths to include (0 for all leaves)"),
+				mcptypes.DefaultNumber(5),
+			),
+			withCompleted(),
+		),
+		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
+			rawItemID := b.defaultReadID(req.GetString("id", "None"))
+			completed := req.GetString("completed", workflowy.CompletedInclude)
+			if err := workflowy.ValidateCompletedMode(completed); err != nil {
+				return errorResult(err), nil
+			}
+			paths := req.GetInt("paths", 5)
+			if paths < 0 {
+				return mcptypes.NewToolResultError("paths must not be negative"), nil
+			}
+
+			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
+			if err != nil {
+				return errorResultFromErr("cannot resolve ID", err), nil
+			}
+
+			if err := b.validateReadTarget(ctx, itemID, "report_depth"); err != nil {
+				return errorResult(err), nil
+			}
+
+			snapshot, root, err := b.buildReportRoot(ctx, itemID, completed)
+			if err != nil {
+				return errorResultFromErr("cannot load tree", err), nil
+			}
+
+			output := &reports.DepthReportOutput{
+				Depth:    workflowy.MeasureDepth(root, paths),
+				Snapshot: snapshot.Meta(),
 			}
 
 			return mcptypes.NewToolResultJSON(output)
@@ -942,17 +1720,20 @@ func (b ToolBuilder) buildReportMirrorsTool() mcpserver.ServerTool {
 		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
 			topN := req.GetInt("top_n", 20)
 
-			items, err := workflowy.ReadLatestBackup()
+			snapshot, err := b.loadBackupSnapshot()
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot load backup file (mirror data requires backup)", err), nil
+				return errorResultFromErr("cannot load backup file (mirror data requires backup)", err), nil
 			}
+			items := snapshot.Items()
 
 			infos := mirror.CollectMirrorInfos(items)
 			ranked := mirror.RankByMirrorCount(infos, topN)
 
 			output := &reports.MirrorCountReportOutput{
-				Ranked: ranked,
-				TopN:   topN,
+				Ranked:   ranked,
+				TopN:     topN,
+				Problems: mirror.ValidateChains(items, mirror.DefaultMaxChainLength),
+				Snapshot: snapshot.Meta(),
 			}
 
 			return mcptypes.NewToolResultJSON(output)
@@ -986,9 +1767,16 @@ func (b ToolBuilder) buildReplaceTool() mcpserver.ServerTool {
 				mcptypes.DefaultBool(false),
 			),
 			mcptypes.WithBoolean("dry_run",
-				mcptypes.Description("Show what would be replaced without applying"),
+				mcptypes.Description("Show what would be replaced without applying. Dry-runs return a plan_id for workflowy_apply_plan"),
 				mcptypes.DefaultBool(true),
 			),
+			mcptypes.WithString("move_to",
+				mcptypes.Description("Move matched text instead of replacing: 'note' (from name to note) or 'name' (from note to name)"),
+			),
+			mcptypes.WithBoolean("include_breadcrumb",
+				mcptypes.Description("Include the names of the two top ancestors with each result"),
+				mcptypes.DefaultBool(false),
+			),
 		),
 		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
 			pattern := strings.TrimSpace(req.GetString("pattern", ""))
@@ -996,8 +1784,13 @@ func (b ToolBuilder) buildReplaceTool() mcpserver.ServerTool {
 				return mcptypes.NewToolResultError("pattern is required"), nil
 			}
 
+			moveTo := strings.TrimSpace(req.GetString("move_to", ""))
+			if err := replace.ValidateMoveTo(moveTo); err != nil {
+				return errorResult(err), nil
+			}
+
 			substitution := req.GetString("substitution", "")
-			if substitution == "" {
+			if substitution == "" && moveTo == "" {
 				return mcptypes.NewToolResultError("substitution is required"), nil
 			}
 
@@ -1007,7 +1800,7 @@ func (b ToolBuilder) buildReplaceTool() mcpserver.ServerTool {
 
 			re, err := regexp.Compile(pattern)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("invalid regular expression", err), nil
+				return errorResultFromErr("invalid regular expression", err), nil
 			}
 
 			rawParentID := req.GetString("parent_id", "None")
@@ -1016,26 +1809,26 @@ func (b ToolBuilder) buildReplaceTool() mcpserver.ServerTool {
 
 			parentID, err := workflowy.ResolveNodeID(ctx, b.client, rawParentID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve parent ID", err), nil
+				return errorResultFromErr("cannot resolve parent ID", err), nil
 			}
 
 			if err := b.validateReadTarget(ctx, parentID, "replace"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 			if err := b.validateWriteTarget(ctx, parentID, "replace"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 
 			items, err := b.loadExportTree(ctx)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot load tree", err), nil
+				return errorResultFromErr("cannot load tree", err), nil
 			}
 
 			searchRoot := items
 			if parentID != "None" {
 				rootItem := workflowy.FindItemByID(items, parentID)
 				if rootItem == nil {
-					return mcptypes.NewToolResultErrorf("parent item not found: %s", parentID), nil
+					return errorResult(workflowy.Errorf(workflowy.ErrNotFound, "parent item not found: %s", parentID)), nil
 				}
 				searchRoot = []*workflowy.Item{rootItem}
 			}
@@ -1046,28 +1839,42 @@ func (b ToolBuilder) buildReplaceTool() mcpserver.ServerTool {
 				Interactive: false,
 				DryRun:      dryRun,
 				Depth:       depth,
+				MoveTo:      moveTo,
 			}
 
 			results := make([]replace.Result, 0)
 			replace.CollectReplacements(searchRoot, opts, 0, &results)
+			locks := b.writeLocks(items)
+			for i := range results {
+				if err := locks.Check(results[i].ID, "replace"); err != nil {
+					results[i].Skipped = true
+					results[i].SkipReason = err.Error()
+				}
+			}
+			if req.GetBool("include_breadcrumb", false) {
+				workflowy.AddBreadcrumbs(results, items)
+			}
 
 			if len(results) == 0 {
 				return mcptypes.NewToolResultJSON(map[string]any{"results": results})
 			}
 
-			if !opts.DryRun {
-				for i := range results {
-					result := &results[i]
-					updateReq := &workflowy.UpdateNodeRequest{
-						Name: &result.NewName,
-					}
-					if _, err := b.client.UpdateNode(ctx, result.ID, updateReq); err != nil {
-						result.Skipped = true
-						result.SkipReason = fmt.Sprintf("update failed: %v", err)
-						continue
-					}
-					result.Applied = true
+			if opts.DryRun {
+				return b.planResult(results, replaceEdits(results))
+			}
+
+			for i := range results {
+				result := &results[i]
+				if result.Skipped {
+					continue
 				}
+				updateReq := replace.BuildUpdateRequest(result)
+				if _, err := b.client.UpdateNode(ctx, result.ID, updateReq); err != nil {
+					result.Skipped = true
+					result.SkipReason = fmt.Sprintf("update failed: %v", err)
+					continue
+				}
+				result.Applied = true
 			}
 
 			return mcptypes.NewToolResultJSON(map[string]any{"results": results})
@@ -1090,6 +1897,9 @@ func (b ToolBuilder) buildTransformTool() mcpserver.ServerTool {
 			mcptypes.WithString("exec",
 				mcptypes.Description("Shell command template (use {} for input text). Use instead of transform_name."),
 			),
+			mcptypes.WithString("lang",
+				mcptypes.Description("Language for case transforms as a BCP 47 tag, e.g. tr, nl, de"),
+			),
 			mcptypes.WithString("separator",
 				mcptypes.Description("Separator for split transform. Use \\n for newline, \\t for tab."),
 				mcptypes.DefaultString(","),
@@ -1107,13 +1917,17 @@ func (b ToolBuilder) buildTransformTool() mcpserver.ServerTool {
 				mcptypes.DefaultBool(false),
 			),
 			mcptypes.WithBoolean("dry_run",
-				mcptypes.Description("Show what would be transformed without applying"),
+				mcptypes.Description("Show what would be transformed without applying. Dry-runs return a plan_id for workflowy_apply_plan (except with as_child)"),
 				mcptypes.DefaultBool(true),
 			),
 			mcptypes.WithBoolean("as_child",
 				mcptypes.Description("Insert result as child of source node instead of replacing"),
 				mcptypes.DefaultBool(false),
 			),
+			mcptypes.WithBoolean("include_breadcrumb",
+				mcptypes.Description("Include the names of the two top ancestors with each result"),
+				mcptypes.DefaultBool(false),
+			),
 		),
 		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
 			rawItemID := strings.TrimSpace(req.GetString("id", ""))
@@ -1123,26 +1937,26 @@ func (b ToolBuilder) buildTransformTool() mcpserver.ServerTool {
 
 			itemID, err := workflowy.ResolveNodeID(ctx, b.client, rawItemID)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot resolve ID", err), nil
+				return errorResultFromErr("cannot resolve ID", err), nil
 			}
 
 			if err := b.validateReadTarget(ctx, itemID, "transform"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 			if err := b.validateWriteTarget(ctx, itemID, "transform"); err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 
 			items, err := b.loadExportTree(ctx)
 			if err != nil {
-				return mcptypes.NewToolResultErrorFromErr("cannot load tree", err), nil
+				return errorResultFromErr("cannot load tree", err), nil
 			}
 
 			searchRoot := items
 			if itemID != "None" {
 				rootItem := workflowy.FindItemByID(items, itemID)
 				if rootItem == nil {
-					return mcptypes.NewToolResultErrorf("item not found: %s", itemID), nil
+					return errorResult(workflowy.Errorf(workflowy.ErrNotFound, "item not found: %s", itemID)), nil
 				}
 				searchRoot = []*workflowy.Item{rootItem}
 			}
@@ -1153,7 +1967,7 @@ func (b ToolBuilder) buildTransformTool() mcpserver.ServerTool {
 			// Handle split transform
 			if transformName == "split" {
 				separator := req.GetString("separator", ",")
-				return b.handleSplitTransform(ctx, req, searchRoot, separator)
+				return b.handleSplitTransform(ctx, req, searchRoot, b.writeLocks(items), separator)
 			}
 
 			// Handle exec (no transform_name required)
@@ -1165,9 +1979,9 @@ func (b ToolBuilder) buildTransformTool() mcpserver.ServerTool {
 				return mcptypes.NewToolResultError("transform_name required (use a built-in, 'split', or exec)"), nil
 			}
 
-			t, err := transform.ResolveTransformer(transformName, execCmd)
+			t, err := transform.ResolveTransformerLang(transformName, execCmd, strings.TrimSpace(req.GetString("lang", "")))
 			if err != nil {
-				return mcptypes.NewToolResultError(err.Error()), nil
+				return errorResult(err), nil
 			}
 
 			asChild := req.GetBool("as_child", false)
@@ -1182,6 +1996,20 @@ func (b ToolBuilder) buildTransformTool() mcpserver.ServerTool {
 
 			results := make([]transform.Result, 0)
 			transform.CollectTransformations(searchRoot, opts, 0, &results)
+			locks := b.writeLocks(items)
+			for i := range results {
+				if err := locks.Check(results[i].ID, "transform"); err != nil {
+					results[i].Skipped = true
+					results[i].SkipReason = err.Error()
+				}
+			}
+			if req.GetBool("include_breadcrumb", false) {
+				workflowy.AddBreadcrumbs(results, items)
+			}
+
+			if opts.DryRun && !asChild {
+				return b.planResult(results, transformEdits(results))
+			}
 
 			if !opts.DryRun {
 				transform.ApplyResultsWithOptions(ctx, b.client, results, asChild)
@@ -1192,7 +2020,163 @@ func (b ToolBuilder) buildTransformTool() mcpserver.ServerTool {
 	}
 }
 
-func (b ToolBuilder) handleSplitTransform(ctx context.Context, req mcptypes.CallToolRequest, searchRoot []*workflowy.Item, separator string) (*mcptypes.CallToolResult, error) {
+// planResult stores the previewed edits and returns the dry-run results with their plan_id.
+func (b ToolBuilder) planResult(results any, edits []patch.Edit) (*mcptypes.CallToolResult, error) {
+	if len(edits) == 0 {
+		return mcptypes.NewToolResultJSON(map[string]any{"results": results})
+	}
+	planID, err := b.plans.Save(edits)
+	if err != nil {
+		return errorResultFromErr("cannot save plan", err), nil
+	}
+	return mcptypes.NewToolResultJSON(map[string]any{"results": results, "plan_id": planID})
+}
+
+func (b ToolBuilder) buildApplyPlanTool() mcpserver.ServerTool {
+	return mcpserver.ServerTool{
+		Tool: mcptypes.NewTool(
+			ToolApplyPlan,
+			mcptypes.WithDescription("Apply exactly the edits previewed by a replace or transform dry-run. Edits whose current value changed since the preview are skipped"+b.writeRestrictionNote()),
+			mcptypes.WithString("plan_id",
+				mcptypes.Description("plan_id returned by a workflowy_replace or workflowy_transform dry-run"),
+				mcptypes.Required(),
+			),
+		),
+		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
+			planID := strings.TrimSpace(req.GetString("plan_id", ""))
+			if planID == "" {
+				return mcptypes.NewToolResultError("plan_id is required"), nil
+			}
+
+			// a plan refused by the write restrictions is kept, to apply once they allow it
+			edits, err := b.plans.Get(planID)
+			if err != nil {
+				return errorResult(err), nil
+			}
+			for _, edit := range edits {
+				if err := b.validateWriteTarget(ctx, edit.ID, "apply plan"); err != nil {
+					return errorResult(err), nil
+				}
+			}
+			if edits, err = b.plans.Take(planID); err != nil {
+				return errorResult(err), nil
+			}
+
+			results := patch.Apply(ctx, b.client, edits, patch.Options{})
+			return mcptypes.NewToolResultJSON(map[string]any{"results": results})
+		},
+	}
+}
+
+func (b ToolBuilder) buildSimulateTool() mcpserver.ServerTool {
+	return mcpserver.ServerTool{
+		Tool: mcptypes.NewTool(
+			ToolSimulate,
+			mcptypes.WithDescription("Preview a batch of write operations without applying them: returns the subtree they would produce as a markdown diff, to show the user exactly what will change. Nodes created by the batch get the IDs new-1, new-2... in order, which later operations can use"+b.readRestrictionNote()),
+			mcptypes.WithArray("operations",
+				mcptypes.Description("Operations, applied in order"),
+				mcptypes.Required(),
+				mcptypes.Items(map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"op":        map[string]any{"type": "string", "enum": []string{simulate.OpCreate, simulate.OpUpdate, simulate.OpMove, simulate.OpDelete, simulate.OpComplete, simulate.OpUncomplete}},
+						"id":        map[string]any{"type": "string", "description": "Node to update, move, delete, complete or uncomplete"},
+						"parent_id": map[string]any{"type": "string", "description": "Parent of the created or moved node (default for create: root)"},
+						"name":      map[string]any{"type": "string"},
+						"note":      map[string]any{"type": "string"},
+						"position":  map[string]any{"type": "string", "description": `"top" or "bottom" (default: bottom for create, top for move)`},
+					},
+					"required": []string{"op"},
+				}),
+			),
+			mcptypes.WithString("id",
+				mcptypes.Description("Subtree to show the changes in (default: root)"),
+				mcptypes.DefaultString("None"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcptypes.CallToolRequest) (*mcptypes.CallToolResult, error) {
+			var args struct {
+				Operations []simulate.Operation `json:"operations"`
+			}
+			if err := req.BindArguments(&args); err != nil {
+				return errorResultFromErr("cannot parse operations", err), nil
+			}
+			if len(args.Operations) == 0 {
+				return mcptypes.NewToolResultError("operations is required"), nil
+			}
+
+			itemID, err := workflowy.ResolveNodeIDToUUID(ctx, b.client, b.defaultReadID(req.GetString("id", "None")))
+			if err != nil {
+				return errorResultFromErr("cannot resolve ID", err), nil
+			}
+			if err := b.validateReadTarget(ctx, itemID, "simulate"); err != nil {
+				return errorResult(err), nil
+			}
+
+			for i := range args.Operations {
+				if err := b.checkSimulatedOperation(ctx, &args.Operations[i]); err != nil {
+					return errorResult(fmt.Errorf("operation %d (%s): %w", i+1, args.Operations[i].Op, err)), nil
+				}
+			}
+
+			snapshot, err := b.loadSnapshot(ctx)
+			if err != nil {
+				return errorResultFromErr("cannot load tree", err), nil
+			}
+			result, err := simulate.Run(snapshot.Items(), itemID, args.Operations)
+			if err != nil {
+				return errorResult(err), nil
+			}
+			return mcptypes.NewToolResultJSON(result)
+		},
+	}
+}
+
+// checkSimulatedOperation resolves the IDs of op and checks that the write
+// tools would allow it, so the preview does not show changes that would be denied.
+func (b ToolBuilder) checkSimulatedOperation(ctx context.Context, op *simulate.Operation) error {
+	if err := op.Validate(); err != nil {
+		return err
+	}
+	if op.Op == simulate.OpCreate {
+		op.ParentID = b.defaultParent(op.ParentID)
+	}
+	for _, id := range []*string{&op.ID, &op.ParentID} {
+		if *id == "" || simulate.IsPlaceholder(*id) {
+			continue
+		}
+		resolved, err := workflowy.ResolveNodeIDToUUID(ctx, b.client, strings.TrimSpace(*id))
+		if err != nil {
+			return fmt.Errorf("cannot resolve ID: %w", err)
+		}
+		*id = resolved
+		if err := b.validateReadTarget(ctx, resolved, op.Op); err != nil {
+			return err
+		}
+	}
+
+	if op.Op == simulate.OpCreate {
+		if simulate.IsPlaceholder(op.ParentID) {
+			return nil
+		}
+		return b.validateWriteParent(ctx, op.ParentID, op.Op)
+	}
+	if simulate.IsPlaceholder(op.ID) {
+		return nil
+	}
+	if op.Op == simulate.OpDelete {
+		return b.validateWriteSubtree(ctx, op.ID, op.Op)
+	}
+	if err := b.validateWriteTarget(ctx, op.ID, op.Op); err != nil {
+		return err
+	}
+	if op.Op == simulate.OpMove && !simulate.IsPlaceholder(op.ParentID) {
+		return b.validateWriteParent(ctx, op.ParentID, op.Op)
+	}
+	return nil
+}
+
+func (b ToolBuilder) handleSplitTransform(ctx context.Context, req mcptypes.CallToolRequest, searchRoot []*workflowy.Item, locks workflowy.Locks, separator string) (*mcptypes.CallToolResult, error) {
 	separator = transform.UnescapeSeparator(separator)
 	fields := transform.DetermineFields(req.GetBool("name", false), req.GetBool("note", false))
 	dryRun := req.GetBool("dry_run", true)
@@ -1200,6 +2184,12 @@ func (b ToolBuilder) handleSplitTransform(ctx context.Context, req mcptypes.Call
 
 	var results []transform.SplitResult
 	transform.CollectSplits(searchRoot, separator, fields, true, 0, depth, &results)
+	for i := range results {
+		if err := locks.Check(results[i].ParentID, "split"); err != nil {
+			results[i].Skipped = true
+			results[i].SkipReason = err.Error()
+		}
+	}
 
 	if !dryRun {
 		transform.ApplySplitResults(ctx, b.client, results)
@@ -1208,91 +2198,195 @@ func (b ToolBuilder) handleSplitTransform(ctx context.Context, req mcptypes.Call
 	return mcptypes.NewToolResultJSON(map[string]any{"results": results})
 }
 
-// fetchItems mirrors the CLI logic: depth >=4 or -1 uses export API; otherwise GET API.
+// fetchItems mirrors the CLI logic: the configured method if any, otherwise
+// depth >=4 or -1 uses export API and shallower reads the GET API. Without a
+// client, as the CLI without an API key, it reads the backup; an API error is
+// returned rather than answered from a backup that may be days old.
 func (b ToolBuilder) fetchItems(ctx context.Context, itemID string, depth int) (interface{}, error) {
-	useMethod := "get"
-	if depth == -1 || depth >= 4 {
-		useMethod = "export"
+	useMethod := b.method
+	if useMethod == "" {
+		useMethod = "get"
+		if depth == -1 || depth >= 4 {
+			useMethod = "export"
+		}
+		if b.client == nil {
+			useMethod = "backup"
+		}
 	}
+	slog.DebugContext(ctx, "access method determined", "method", useMethod, "depth", depth)
 
 	switch useMethod {
-	case "export":
-		tree, err := b.loadExportTree(ctx)
+	case "export", "backup", "cache":
+		tree, err := b.loadTree(ctx)
 		if err != nil {
 			return nil, err
 		}
-
-		if itemID != "None" {
-			found := workflowy.FindItemInTree(tree, itemID, depth)
-			if found == nil {
-				return nil, fmt.Errorf("item %s not found", itemID)
-			}
-			return found, nil
-		}
-
-		if depth >= 0 {
-			workflowy.LimitItemsDepth(tree, depth)
-		}
-		return &workflowy.ListChildrenResponse{Items: tree}, nil
+		return selectItems(tree, itemID, depth)
 
 	case "get":
 		if depth < 0 {
 			return nil, fmt.Errorf("depth must be non-negative for get method")
 		}
+		return b.getItems(ctx, itemID, depth)
 
-		if itemID == "None" {
-			resp, err := b.client.ListChildrenRecursiveWithDepth(ctx, itemID, depth)
-			if err != nil {
-				return nil, err
-			}
-			return resp, nil
+	default:
+		return nil, fmt.Errorf("unknown method %s", useMethod)
+	}
+}
+
+// fetchOrResolveMirrors fetches like fetchItems, or from the backup with
+// mirror copies replaced by their originals' content when resolveMirrors is
+// set, since mirror data is only available in backup files.
+func (b ToolBuilder) fetchOrResolveMirrors(ctx context.Context, itemID string, depth int, resolveMirrors bool) (interface{}, error) {
+	if !resolveMirrors {
+		return b.fetchItems(ctx, itemID, depth)
+	}
+
+	tree, err := b.loadBackupTree()
+	if err != nil {
+		return nil, err
+	}
+	roots := tree
+	if itemID != "None" {
+		found := workflowy.FindItemByID(tree, itemID)
+		if found == nil {
+			return nil, workflowy.Errorf(workflowy.ErrNotFound, "item %s not found in backup", itemID)
+		}
+		roots = []*workflowy.Item{found}
+	}
+	count := mirror.InlineMirrors(roots, tree)
+	slog.DebugContext(ctx, "inlined mirrors", "count", count)
+	return selectItems(tree, itemID, depth)
+}
+
+// selectItems returns itemID and its descendants to depth from tree, or the
+// top-level items when itemID is "None".
+func selectItems(tree []*workflowy.Item, itemID string, depth int) (interface{}, error) {
+	if itemID != "None" {
+		found := workflowy.FindItemInTree(tree, itemID, depth)
+		if found == nil {
+			return nil, workflowy.Errorf(workflowy.ErrNotFound, "item %s not found", itemID)
 		}
+		return found, nil
+	}
+
+	if depth >= 0 {
+		workflowy.LimitItemsDepth(tree, depth)
+	}
+	return &workflowy.ListChildrenResponse{Items: tree}, nil
+}
+
+// getItems reads itemID and its descendants to depth with the GET API.
+func (b ToolBuilder) getItems(ctx context.Context, itemID string, depth int) (interface{}, error) {
+	if itemID == "None" {
+		return b.client.ListChildrenRecursiveWithDepth(ctx, itemID, depth)
+	}
+
+	item, err := b.client.GetItem(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
 
-		item, err := b.client.GetItem(ctx, itemID)
+	if depth > 0 {
+		childrenResp, err := b.client.ListChildrenRecursiveWithDepth(ctx, itemID, depth)
 		if err != nil {
 			return nil, err
 		}
+		item.Children = childrenResp.Items
+	}
+	return item, nil
+}
 
-		if depth > 0 {
-			childrenResp, err := b.client.ListChildrenRecursiveWithDepth(ctx, itemID, depth)
-			if err != nil {
-				return nil, err
-			}
-			item.Children = childrenResp.Items
-		}
-		return item, nil
+// loadTree returns the top-level items that read tools work from: the backup
+// with the backup method or without a client, otherwise the export.
+func (b ToolBuilder) loadTree(ctx context.Context) ([]*workflowy.Item, error) {
+	snapshot, err := b.loadSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Items(), nil
+}
 
-	default:
-		return nil, fmt.Errorf("unknown method %s", useMethod)
+// loadSnapshot loads the whole tree once with the configured method, so that
+// everything computed from it reflects the same point in time.
+func (b ToolBuilder) loadSnapshot(ctx context.Context) (*workflowy.Snapshot, error) {
+	if b.method == "backup" || (b.method == "" && b.client == nil) {
+		return b.loadBackupSnapshot()
 	}
+	if b.method == "cache" {
+		return workflowy.ReadTreeCacheSnapshot()
+	}
+	return b.loadExportSnapshot(ctx)
 }
 
-func (b ToolBuilder) loadExportTree(ctx context.Context) ([]*workflowy.Item, error) {
-	resp, err := b.client.ExportNodesWithCache(ctx, false)
+func (b ToolBuilder) loadBackupSnapshot() (*workflowy.Snapshot, error) {
+	items, err := b.loadBackupTree()
 	if err != nil {
 		return nil, err
 	}
-	root := workflowy.BuildTreeFromExport(resp.Nodes)
-	return root.Children, nil
+	return workflowy.NewBackupSnapshot(items), nil
 }
 
-func (b ToolBuilder) buildReportRoot(ctx context.Context, itemID string) (*workflowy.Item, error) {
-	items, err := b.loadExportTree(ctx)
+// loadBackupTree returns the top-level items of the configured backup file,
+// or of the latest backup.
+func (b ToolBuilder) loadBackupTree() ([]*workflowy.Item, error) {
+	items, err := workflowy.ReadCheckedBackup(workflowy.DefaultBackupProvider, b.backupFile, b.maxBackupAge, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("cannot read backup file: %w", err)
+	}
+	return items, nil
+}
+
+// loadExportTree returns the top-level items of the full export. Concurrent
+// calls share a single cache read or API request; each caller still builds its
+// own tree, since handlers modify the items they receive.
+func (b ToolBuilder) loadExportTree(ctx context.Context) ([]*workflowy.Item, error) {
+	snapshot, err := b.loadExportSnapshot(ctx)
 	if err != nil {
 		return nil, err
 	}
+	return snapshot.Items(), nil
+}
 
-	if itemID == "None" {
-		return &workflowy.Item{
-			ID:       "root",
-			Name:     "Root",
-			Children: items,
-		}, nil
+func (b ToolBuilder) loadExportSnapshot(ctx context.Context) (*workflowy.Snapshot, error) {
+	ch := b.exports.DoChan("export", func() (any, error) {
+		// detached from the caller so one cancelled call does not fail the others
+		return b.client.ExportNodesWithCache(context.WithoutCancel(ctx), false)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		if res.Shared {
+			slog.Debug("shared export load with concurrent tool call")
+		}
+		return workflowy.NewExportSnapshot(res.Val.(*workflowy.ExportNodesResponse)), nil
 	}
+}
 
-	target := workflowy.FindItemByID(items, itemID)
-	if target == nil {
-		return nil, fmt.Errorf("item not found: %s", itemID)
+// buildReportRoot loads a snapshot and returns it with the report root: the
+// node itemID, or the whole tree when itemID is "None", with its descendants
+// filtered by completion.
+func (b ToolBuilder) buildReportRoot(ctx context.Context, itemID, completed string) (*workflowy.Snapshot, *workflowy.Item, error) {
+	snapshot, err := b.loadSnapshot(ctx)
+	if err != nil {
+		return nil, nil, err
 	}
-	return target, nil
+	root, err := snapshot.Root(itemID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return snapshot, workflowy.FilterCompletedItem(root, completed), nil
+}
+
+// withCompleted is the completed parameter of read tools, as the CLI's --completed flag.
+func withCompleted() mcptypes.ToolOption {
+	return mcptypes.WithString("completed",
+		mcptypes.Description("Completed nodes: include, exclude (with their descendants) or only (with the ancestors that lead to them)"),
+		mcptypes.DefaultString(workflowy.CompletedInclude),
+	)
 }
@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mcptypes "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mholzen/workflowy/pkg/patch"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// accessClient records the API each read goes through, failing them all with err when set.
+type accessClient struct {
+	workflowy.Client
+	calls []string
+	err   error
+}
+
+func (c *accessClient) GetItem(ctx context.Context, itemID string) (*workflowy.Item, error) {
+	c.calls = append(c.calls, "get")
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &workflowy.Item{ID: itemID, Name: "from get"}, nil
+}
+
+func (c *accessClient) ListChildrenRecursiveWithDepth(ctx context.Context, itemID string, depth int) (*workflowy.ListChildrenResponse, error) {
+	c.calls = append(c.calls, "get")
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &workflowy.ListChildrenResponse{}, nil
+}
+
+func (c *accessClient) ExportNodesWithCache(ctx context.Context, forceRefresh bool) (*workflowy.ExportNodesResponse, error) {
+	c.calls = append(c.calls, "export")
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &workflowy.ExportNodesResponse{Nodes: []workflowy.ExportNode{{ID: "a", Name: "from export"}}}, nil
+}
+
+// backupProvider serves a backup with a single node, counting the reads.
+type backupProvider struct {
+	reads int
+}
+
+func (p *backupProvider) ReadBackupFile(filename string) ([]*workflowy.Item, error) {
+	return p.ReadLatestBackup()
+}
+
+func (p *backupProvider) ReadLatestBackup() ([]*workflowy.Item, error) {
+	p.reads++
+	return []*workflowy.Item{{ID: "a", Name: "from backup"}}, nil
+}
+
+func withBackupProvider(t *testing.T) *backupProvider {
+	provider := &backupProvider{}
+	previous := workflowy.DefaultBackupProvider
+	workflowy.DefaultBackupProvider = provider
+	t.Cleanup(func() { workflowy.DefaultBackupProvider = previous })
+	return provider
+}
+
+func TestFetchItems_Method(t *testing.T) {
+	tests := []struct {
+		method  string
+		depth   int
+		calls   []string
+		backups int
+		name    string
+	}{
+		{method: "", depth: 2, calls: []string{"get", "get"}, name: "from get"},
+		{method: "", depth: -1, calls: []string{"export"}, name: "from export"},
+		{method: "export", depth: 1, calls: []string{"export"}, name: "from export"},
+		{method: "backup", depth: 1, backups: 1, name: "from backup"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			backups := withBackupProvider(t)
+			client := &accessClient{}
+			b, err := NewToolBuilder(client, "", "").WithAccessMethod(tt.method, "")
+			require.NoError(t, err)
+
+			result, err := b.fetchItems(context.Background(), "a", tt.depth)
+			require.NoError(t, err)
+			assert.Equal(t, tt.name, result.(*workflowy.Item).Name)
+			assert.Equal(t, tt.calls, client.calls)
+			assert.Equal(t, tt.backups, backups.reads)
+		})
+	}
+}
+
+func TestFetchItems_NoFallbackOnAPIError(t *testing.T) {
+	backups := withBackupProvider(t)
+	client := &accessClient{err: errors.New("unavailable")}
+	b := NewToolBuilder(client, "", "")
+
+	_, err := b.fetchItems(context.Background(), "a", 1)
+	assert.ErrorContains(t, err, "unavailable")
+	_, err = b.fetchItems(context.Background(), "a", -1)
+	assert.ErrorContains(t, err, "unavailable")
+	assert.Equal(t, 0, backups.reads, "a failed API read is not answered from the backup")
+}
+
+func TestFetchItems_BackupWithoutClient(t *testing.T) {
+	backups := withBackupProvider(t)
+	b := NewToolBuilder(nil, "", "")
+
+	result, err := b.fetchItems(context.Background(), "a", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "from backup", result.(*workflowy.Item).Name)
+	assert.Equal(t, 1, backups.reads)
+}
+
+func TestCapabilities_Roots(t *testing.T) {
+	client := &accessClient{}
+	b := NewToolBuilder(client, "a", "None")
+
+	capabilities := b.capabilities(context.Background(), "1.0", []string{ToolGet}, false)
+	require.NotNil(t, capabilities.WriteRoot)
+	assert.Equal(t, "from export", capabilities.WriteRoot.Name)
+	assert.Equal(t, []string{"export"}, client.calls)
+
+	// the roots are each tenant's, not the server's
+	client.calls = nil
+	capabilities = b.capabilities(context.Background(), "1.0", []string{ToolGet}, true)
+	assert.Nil(t, capabilities.WriteRoot)
+	assert.Nil(t, capabilities.ReadRoot)
+	assert.Empty(t, client.calls)
+}
+
+func TestApplyPlan_KeepsAPlanOutsideTheWriteRoot(t *testing.T) {
+	b := NewToolBuilder(&accessClient{}, "a", "")
+	planID, err := b.plans.Save([]patch.Edit{{ID: "b", Field: patch.FieldName, Old: "old", New: "new"}})
+	require.NoError(t, err)
+
+	req := mcptypes.CallToolRequest{Params: mcptypes.CallToolParams{Arguments: map[string]any{"plan_id": planID}}}
+	result, err := b.buildApplyPlanTool().Handler(context.Background(), req)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	_, err = b.plans.Get(planID)
+	assert.NoError(t, err, "a refused plan can still be applied later")
+}
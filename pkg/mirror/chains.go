@@ -0,0 +1,90 @@
+package mirror
+
+import (
+	"sort"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// DefaultMaxChainLength is the longest chain of mirrors of mirrors accepted by ValidateChains.
+// A mirror copy pointing directly at its original is a chain of length 1.
+const DefaultMaxChainLength = 3
+
+// Kinds of chain problems
+const (
+	ChainCycle           = "cycle"            // the originalId chain returns to a node already visited
+	ChainTooLong         = "too_long"         // the chain is longer than the maximum length
+	ChainMissingOriginal = "missing_original" // the chain ends at an ID that is not in the tree
+)
+
+// ChainProblem describes a mirror copy whose originalId chain cannot be resolved.
+type ChainProblem struct {
+	NodeID   string   `json:"node_id"`
+	NodeName string   `json:"node_name"`
+	Kind     string   `json:"kind"`
+	Chain    []string `json:"chain"` // IDs followed from the node, ending where the problem was found
+}
+
+// index maps node IDs to nodes.
+type index map[string]*workflowy.Item
+
+func buildIndex(items []*workflowy.Item) index {
+	idx := make(index)
+	var walk func(items []*workflowy.Item)
+	walk = func(items []*workflowy.Item) {
+		for _, item := range items {
+			idx[item.ID] = item
+			walk(item.Children)
+		}
+	}
+	walk(items)
+	return idx
+}
+
+// followChain follows the originalId chain of item to the first node that is
+// not a mirror copy. It returns the IDs visited after item and the kind of
+// problem found, or "" when the chain ends at an original within maxLength hops.
+func (idx index) followChain(item *workflowy.Item, maxLength int) (*workflowy.Item, []string, string) {
+	visited := map[string]bool{item.ID: true}
+	var chain []string
+	current := item
+	for {
+		originalID := OriginalID(current)
+		if originalID == "" || originalID == current.ID {
+			return current, chain, ""
+		}
+		chain = append(chain, originalID)
+		if visited[originalID] {
+			return nil, chain, ChainCycle
+		}
+		if maxLength > 0 && len(chain) > maxLength {
+			return nil, chain, ChainTooLong
+		}
+		next, ok := idx[originalID]
+		if !ok {
+			return nil, chain, ChainMissingOriginal
+		}
+		visited[originalID] = true
+		current = next
+	}
+}
+
+// ValidateChains reports the mirror copies in items whose originalId chain
+// cycles, is longer than maxLength, or points at a node missing from items.
+// Problems are sorted by node ID.
+func ValidateChains(items []*workflowy.Item, maxLength int) []ChainProblem {
+	idx := buildIndex(items)
+	var problems []ChainProblem
+	for _, item := range idx {
+		if OriginalID(item) == "" {
+			continue
+		}
+		if _, chain, kind := idx.followChain(item, maxLength); kind != "" {
+			problems = append(problems, ChainProblem{NodeID: item.ID, NodeName: item.Name, Kind: kind, Chain: chain})
+		}
+	}
+	sort.Slice(problems, func(i, j int) bool {
+		return problems[i].NodeID < problems[j].NodeID
+	})
+	return problems
+}
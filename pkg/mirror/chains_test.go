@@ -0,0 +1,66 @@
+package mirror
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+func mirrorOf(id, originalID string) *workflowy.Item {
+	return &workflowy.Item{
+		ID:   id,
+		Name: id,
+		Data: map[string]any{"mirror": map[string]any{"originalId": originalID}},
+	}
+}
+
+func TestValidateChains(t *testing.T) {
+	items := []*workflowy.Item{
+		{ID: "original", Name: "Original"},
+		mirrorOf("copy", "original"),
+		mirrorOf("copy-of-copy", "copy"),
+		mirrorOf("cycle-a", "cycle-b"),
+		mirrorOf("cycle-b", "cycle-a"),
+		mirrorOf("dangling", "deleted"),
+		mirrorOf("long-1", "long-2"),
+		mirrorOf("long-2", "long-3"),
+		mirrorOf("long-3", "long-4"),
+		mirrorOf("long-4", "original"),
+	}
+
+	problems := ValidateChains(items, 3)
+
+	want := []ChainProblem{
+		{NodeID: "cycle-a", NodeName: "cycle-a", Kind: ChainCycle, Chain: []string{"cycle-b", "cycle-a"}},
+		{NodeID: "cycle-b", NodeName: "cycle-b", Kind: ChainCycle, Chain: []string{"cycle-a", "cycle-b"}},
+		{NodeID: "dangling", NodeName: "dangling", Kind: ChainMissingOriginal, Chain: []string{"deleted"}},
+		{NodeID: "long-1", NodeName: "long-1", Kind: ChainTooLong, Chain: []string{"long-2", "long-3", "long-4", "original"}},
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("unexpected problems:\ngot  %+v\nwant %+v", problems, want)
+	}
+}
+
+func TestValidateChains_Healthy(t *testing.T) {
+	items := resolveTestTree()
+	if problems := ValidateChains(items, DefaultMaxChainLength); len(problems) != 0 {
+		t.Errorf("expected no problems, got %+v", problems)
+	}
+}
+
+func TestInlineMirrors_FollowsChains(t *testing.T) {
+	original := &workflowy.Item{ID: "original", Name: "Original", Children: []*workflowy.Item{{ID: "child", Name: "Child"}}}
+	copyOfCopy := mirrorOf("copy-of-copy", "copy")
+	cycle := mirrorOf("cycle-a", "cycle-b")
+	items := []*workflowy.Item{original, mirrorOf("copy", "original"), copyOfCopy, cycle, mirrorOf("cycle-b", "cycle-a")}
+
+	InlineMirrors([]*workflowy.Item{copyOfCopy, cycle}, items)
+
+	if copyOfCopy.MirrorOf != "original" || copyOfCopy.Name != "Original" || len(copyOfCopy.Children) != 1 {
+		t.Errorf("expected a mirror of a mirror to show the original, got %+v", copyOfCopy)
+	}
+	if cycle.MirrorOf != "" {
+		t.Errorf("expected a mirror in a cycle to stay a placeholder, got %+v", cycle)
+	}
+}
@@ -1,7 +1,9 @@
 package mirror
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/mholzen/workflowy/pkg/workflowy"
 )
@@ -147,3 +149,131 @@ func RankByMirrorCount(infos []*MirrorInfo, topN int) []*MirrorInfo {
 	}
 	return sorted
 }
+
+// OriginalID returns the ID of the node a mirror copy points to, or "" if item is not a mirror copy
+func OriginalID(item *workflowy.Item) string {
+	_, originalID := extractMirrorRootIDs(item)
+	return originalID
+}
+
+// Resolution maps a node to the original it mirrors
+type Resolution struct {
+	ID                 string   `json:"id"`
+	Name               string   `json:"name"`
+	IsMirror           bool     `json:"is_mirror"`
+	OriginalID         string   `json:"original_id"`
+	OriginalName       string   `json:"original_name"`
+	OriginalParentID   string   `json:"original_parent_id,omitempty"`
+	OriginalParentName string   `json:"original_parent_name,omitempty"`
+	MirrorIDs          []string `json:"mirror_ids,omitempty"` // other locations of the original
+}
+
+// Resolve finds the node id (a full ID or a 12-character short ID) and maps it
+// to its original. A node that is not a mirror copy resolves to itself.
+func Resolve(items []*workflowy.Item, id string) (*Resolution, error) {
+	parentMap := make(map[string]parentInfo)
+	for _, item := range items {
+		buildParentMap(item, "", "", parentMap)
+	}
+
+	node := findNode(items, id)
+	if node == nil {
+		return nil, fmt.Errorf("item %s not found", id)
+	}
+
+	res := &Resolution{ID: node.ID, Name: node.Name}
+	original := node
+	if originalID := OriginalID(node); originalID != "" && originalID != node.ID {
+		res.IsMirror = true
+		original = workflowy.FindItemByID(items, originalID)
+		if original == nil {
+			return nil, fmt.Errorf("original %s of mirror %s not found", originalID, node.ID)
+		}
+	}
+
+	res.OriginalID = original.ID
+	res.OriginalName = original.Name
+	if pInfo, ok := parentMap[original.ID]; ok {
+		res.OriginalParentID = pInfo.parentID
+		res.OriginalParentName = pInfo.parentName
+	}
+	mirrorIDs, _ := extractMirrorRootIDs(original)
+	sort.Strings(mirrorIDs)
+	res.MirrorIDs = mirrorIDs
+	return res, nil
+}
+
+// findNode returns the node with the given ID, or ending with it when id is a short ID
+func findNode(items []*workflowy.Item, id string) *workflowy.Item {
+	if node := workflowy.FindItemByID(items, id); node != nil || !workflowy.IsShortID(id) {
+		return node
+	}
+	return findBySuffix(items, id)
+}
+
+func findBySuffix(items []*workflowy.Item, suffix string) *workflowy.Item {
+	for _, item := range items {
+		if strings.HasSuffix(item.ID, suffix) {
+			return item
+		}
+		if node := findBySuffix(item.Children, suffix); node != nil {
+			return node
+		}
+	}
+	return nil
+}
+
+// InlineMirrors replaces the mirror copies among items and their descendants
+// with the content of their originals, found in tree, and marks them with
+// MirrorOf. Chains of mirrors of mirrors are followed to the original; copies
+// whose chain cycles or breaks stay placeholders. Mirrors within inlined
+// content are inlined too, except mirrors of an enclosing node, which would
+// repeat forever. It returns the number of mirrors inlined.
+func InlineMirrors(items []*workflowy.Item, tree []*workflowy.Item) int {
+	return buildIndex(tree).inlineMirrors(items, map[string]bool{})
+}
+
+// inlineMirrors inlines mirrors among items; ancestors holds the IDs of the
+// nodes, or originals, enclosing items.
+func (idx index) inlineMirrors(items []*workflowy.Item, ancestors map[string]bool) int {
+	count := 0
+	for _, item := range items {
+		var original *workflowy.Item
+		if OriginalID(item) != "" {
+			original, _, _ = idx.followChain(item, 0)
+		}
+		if original == nil || original == item || ancestors[original.ID] {
+			ancestors[item.ID] = true
+			count += idx.inlineMirrors(item.Children, ancestors)
+			delete(ancestors, item.ID)
+			continue
+		}
+
+		item.Name = original.Name
+		item.Note = original.Note
+		item.CompletedAt = original.CompletedAt
+		item.MirrorOf = original.ID
+		item.Children = cloneItems(original.Children)
+		count++
+
+		ancestors[original.ID] = true
+		count += idx.inlineMirrors(item.Children, ancestors)
+		delete(ancestors, original.ID)
+	}
+	return count
+}
+
+// cloneItems deep-copies items so inlined content can be modified, e.g. depth-limited,
+// without changing the original.
+func cloneItems(items []*workflowy.Item) []*workflowy.Item {
+	if items == nil {
+		return nil
+	}
+	clones := make([]*workflowy.Item, len(items))
+	for i, item := range items {
+		clone := *item
+		clone.Children = cloneItems(item.Children)
+		clones[i] = &clone
+	}
+	return clones
+}
@@ -245,3 +245,129 @@ func TestRankByMirrorCount_TopNLargerThanList(t *testing.T) {
 		t.Fatalf("expected 1 result when topN > list size, got %d", len(ranked))
 	}
 }
+
+func resolveTestTree() []*workflowy.Item {
+	return []*workflowy.Item{
+		{
+			ID:   "projects",
+			Name: "Projects",
+			Children: []*workflowy.Item{
+				{
+					ID:   "0000-1111-aaaabbbbcccc",
+					Name: "Original Node",
+					Data: map[string]any{
+						"mirror": map[string]any{
+							"mirrorRootIds": map[string]any{"mirror-copy": true},
+						},
+					},
+				},
+			},
+		},
+		{
+			ID:   "today",
+			Name: "Today",
+			Children: []*workflowy.Item{
+				{
+					ID:   "mirror-copy",
+					Name: "Original Node",
+					Data: map[string]any{
+						"mirror": map[string]any{
+							"originalId":   "0000-1111-aaaabbbbcccc",
+							"isMirrorRoot": true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestResolve_MirrorCopy(t *testing.T) {
+	res, err := Resolve(resolveTestTree(), "mirror-copy")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.IsMirror {
+		t.Error("expected mirror copy to be reported as a mirror")
+	}
+	if res.OriginalID != "0000-1111-aaaabbbbcccc" {
+		t.Errorf("expected OriginalID '0000-1111-aaaabbbbcccc', got '%s'", res.OriginalID)
+	}
+	if res.OriginalParentID != "projects" || res.OriginalParentName != "Projects" {
+		t.Errorf("expected original parent 'projects' (Projects), got '%s' (%s)", res.OriginalParentID, res.OriginalParentName)
+	}
+	if len(res.MirrorIDs) != 1 || res.MirrorIDs[0] != "mirror-copy" {
+		t.Errorf("expected MirrorIDs [mirror-copy], got %v", res.MirrorIDs)
+	}
+}
+
+func TestResolve_OriginalByShortID(t *testing.T) {
+	res, err := Resolve(resolveTestTree(), "aaaabbbbcccc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.IsMirror {
+		t.Error("expected original not to be reported as a mirror")
+	}
+	if res.ID != "0000-1111-aaaabbbbcccc" || res.OriginalID != res.ID {
+		t.Errorf("expected original to resolve to itself, got ID '%s' OriginalID '%s'", res.ID, res.OriginalID)
+	}
+}
+
+func TestResolve_MissingOriginal(t *testing.T) {
+	items := resolveTestTree()[1:]
+	if _, err := Resolve(items, "mirror-copy"); err == nil {
+		t.Error("expected an error when the original is not in the tree")
+	}
+	if _, err := Resolve(items, "unknown"); err == nil {
+		t.Error("expected an error for an unknown ID")
+	}
+}
+
+func TestInlineMirrors(t *testing.T) {
+	tree := resolveTestTree()
+	tree[0].Children[0].Children = []*workflowy.Item{{ID: "step-1", Name: "Step 1"}}
+
+	today := tree[1]
+	count := InlineMirrors([]*workflowy.Item{today}, tree)
+	if count != 1 {
+		t.Fatalf("expected 1 mirror inlined, got %d", count)
+	}
+
+	mirrored := today.Children[0]
+	if mirrored.ID != "mirror-copy" || mirrored.MirrorOf != "0000-1111-aaaabbbbcccc" {
+		t.Errorf("expected mirror-copy marked as mirror of the original, got ID '%s' MirrorOf '%s'", mirrored.ID, mirrored.MirrorOf)
+	}
+	if len(mirrored.Children) != 1 || mirrored.Children[0].Name != "Step 1" {
+		t.Fatalf("expected the original's children to be inlined, got %v", mirrored.Children)
+	}
+
+	mirrored.Children[0].Name = "changed"
+	if tree[0].Children[0].Children[0].Name != "Step 1" {
+		t.Error("expected inlined children to be copies of the original's")
+	}
+}
+
+func TestInlineMirrors_MirrorInsideOriginal(t *testing.T) {
+	tree := resolveTestTree()
+	original := tree[0].Children[0]
+	original.Children = []*workflowy.Item{
+		{
+			ID: "nested-copy",
+			Data: map[string]any{
+				"mirror": map[string]any{"originalId": original.ID},
+			},
+		},
+	}
+
+	count := InlineMirrors(tree, tree)
+	if count != 1 {
+		t.Fatalf("expected 1 mirror inlined, got %d", count)
+	}
+	if nested := original.Children[0]; nested.MirrorOf != "" {
+		t.Errorf("expected the mirror of an enclosing node to stay a placeholder, got %+v", nested)
+	}
+	if inlined := tree[1].Children[0].Children[0]; inlined.MirrorOf != "" || inlined.Children != nil {
+		t.Errorf("expected the mirror of the node being inlined to stay a placeholder, got %+v", inlined)
+	}
+}
@@ -0,0 +1,158 @@
+// Package move moves, in bulk, the nodes whose name matches a pattern to a
+// destination node.
+package move
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mholzen/workflowy/pkg/search"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// Result is a node to move.
+type Result struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	FromID     string `json:"from_id"` // the parent it is moved from, "None" at the top level
+	URL        string `json:"url"`
+	Applied    bool   `json:"applied"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	Failed     bool   `json:"failed,omitempty"`
+	SkipReason string `json:"skip_reason,omitempty"`
+	Breadcrumb string `json:"breadcrumb,omitempty"`
+}
+
+func (r Result) String() string {
+	var s string
+	if r.Skipped {
+		s = fmt.Sprintf("%s: \"%s\" (skipped: %s)", r.ID, r.Name, r.SkipReason)
+	} else if r.Applied {
+		s = fmt.Sprintf("%s: \"%s\"", r.ID, r.Name)
+	} else {
+		s = fmt.Sprintf("%s: \"%s\" (dry-run)", r.ID, r.Name)
+	}
+	if r.Breadcrumb != "" {
+		s += fmt.Sprintf(" (in %s)", r.Breadcrumb)
+	}
+	return s
+}
+
+// Options selects the nodes to move and where to.
+type Options struct {
+	Pattern     string // text, or a regular expression with Regexp, matched against the name
+	Regexp      bool
+	IgnoreCase  bool
+	Destination string // the ID of the node to move them under
+	Depth       int    // -1 for unlimited
+}
+
+// Validate returns an error when the pattern or the destination is missing,
+// or the pattern is not a valid regular expression.
+func (o Options) Validate() error {
+	if o.Pattern == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	if o.Destination == "" {
+		return fmt.Errorf("destination is required")
+	}
+	if o.Regexp {
+		if _, err := search.CompileRegexp(o.Pattern, o.IgnoreCase); err != nil {
+			return fmt.Errorf("invalid regular expression: %w", err)
+		}
+	}
+	return nil
+}
+
+// Collect appends to results the nodes of items, children of parentID, and
+// their descendants, down to opts.Depth, whose name matches opts. The
+// descendants of a match move with it, so they are not collected. A match
+// that is the destination, contains it or is already under it is collected
+// as skipped.
+func Collect(items []*workflowy.Item, parentID string, opts Options, currentDepth int, results *[]Result) {
+	if opts.Depth >= 0 && currentDepth > opts.Depth {
+		return
+	}
+
+	for _, item := range items {
+		if len(search.FindMatches(item.Name, opts.Pattern, opts.Regexp, opts.IgnoreCase)) == 0 {
+			Collect(item.Children, item.ID, opts, currentDepth+1, results)
+			continue
+		}
+		result := Result{
+			ID:     item.ID,
+			Name:   item.Name,
+			FromID: parentID,
+			URL:    fmt.Sprintf("https://workflowy.com/#/%s", item.ID),
+		}
+		switch {
+		case item.ID == opts.Destination:
+			result.Skipped, result.SkipReason = true, "is the destination"
+		case parentID == opts.Destination:
+			result.Skipped, result.SkipReason = true, "already in the destination"
+		case workflowy.FindItemByID([]*workflowy.Item{item}, opts.Destination) != nil:
+			result.Skipped, result.SkipReason = true, "contains the destination"
+		}
+		*results = append(*results, result)
+		if result.Skipped {
+			Collect(item.Children, item.ID, opts, currentDepth+1, results)
+		}
+	}
+}
+
+// NodeID returns the ID of the node of the result.
+func (r *Result) NodeID() string {
+	return r.ID
+}
+
+// SetBreadcrumb sets the ancestors shown with the result (see workflowy.AddBreadcrumbs).
+func (r *Result) SetBreadcrumb(breadcrumb string) {
+	r.Breadcrumb = breadcrumb
+}
+
+// Mover is the subset of workflowy.Client needed to apply results.
+type Mover interface {
+	MoveNode(ctx context.Context, itemID string, req *workflowy.MoveNodeRequest) (*workflowy.MoveNodeResponse, error)
+}
+
+// Apply moves the results not already skipped under destination, at
+// position (top when empty, as for the API), keeping their order, and
+// returns the number applied. A failed result is skipped and the others are
+// still moved; a cancelled context skips the remaining ones.
+func Apply(ctx context.Context, client Mover, results []Result, destination, position string) (int, error) {
+	req := &workflowy.MoveNodeRequest{ParentID: destination}
+	if err := req.SetPosition(position); err != nil {
+		return 0, err
+	}
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	// each node moved to the top goes above the previous one, so move the last first
+	if position != "bottom" {
+		slices.Reverse(order)
+	}
+
+	applied := 0
+	for _, i := range order {
+		result := &results[i]
+		if result.Skipped {
+			continue
+		}
+		if ctx.Err() != nil {
+			result.Skipped = true
+			result.SkipReason = "cancelled"
+			continue
+		}
+		if _, err := client.MoveNode(ctx, result.ID, req); err != nil {
+			result.Skipped = true
+			result.Failed = true
+			result.SkipReason = fmt.Sprintf("move failed: %v", err)
+			continue
+		}
+		result.Applied = true
+		applied++
+	}
+	return applied, nil
+}
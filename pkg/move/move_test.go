@@ -0,0 +1,91 @@
+package move
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMover struct {
+	moved  []string
+	failOn string
+}
+
+func (m *fakeMover) MoveNode(ctx context.Context, itemID string, req *workflowy.MoveNodeRequest) (*workflowy.MoveNodeResponse, error) {
+	if itemID == m.failOn {
+		return nil, fmt.Errorf("boom")
+	}
+	m.moved = append(m.moved, itemID)
+	return &workflowy.MoveNodeResponse{}, nil
+}
+
+func testItems() []*workflowy.Item {
+	return []*workflowy.Item{
+		{ID: "projects", Name: "Projects", Children: []*workflowy.Item{
+			{ID: "site", Name: "Website #archive", Children: []*workflowy.Item{
+				{ID: "copy", Name: "Old copy #archive"},
+			}},
+			{ID: "garden", Name: "Garden"},
+		}},
+		{ID: "notes", Name: "Notes #ARCHIVE"},
+		{ID: "archive", Name: "Archive", Children: []*workflowy.Item{
+			{ID: "done", Name: "Done #archive"},
+		}},
+	}
+}
+
+func collect(opts Options) []Result {
+	var results []Result
+	Collect(testItems(), "None", opts, 0, &results)
+	return results
+}
+
+func TestCollect(t *testing.T) {
+	results := collect(Options{Pattern: "#archive", Destination: "archive", Depth: -1})
+
+	require.Len(t, results, 2, "the descendants of a match move with it")
+	assert.Equal(t, "site", results[0].ID)
+	assert.Equal(t, "projects", results[0].FromID)
+	assert.Equal(t, "done", results[1].ID)
+	assert.Equal(t, "already in the destination", results[1].SkipReason)
+
+	results = collect(Options{Pattern: "#archive", IgnoreCase: true, Destination: "archive", Depth: 0})
+	require.Len(t, results, 1)
+	assert.Equal(t, "notes", results[0].ID)
+
+	results = collect(Options{Pattern: `^(Website|Old)\b`, Regexp: true, Destination: "copy", Depth: -1})
+	require.Len(t, results, 2)
+	assert.Equal(t, "contains the destination", results[0].SkipReason)
+	assert.Equal(t, "is the destination", results[1].SkipReason)
+}
+
+func TestOptionsValidate(t *testing.T) {
+	assert.NoError(t, Options{Pattern: "#archive", Destination: "archive"}.Validate())
+	assert.Error(t, Options{Destination: "archive"}.Validate())
+	assert.Error(t, Options{Pattern: "#archive"}.Validate())
+	assert.Error(t, Options{Pattern: "(", Regexp: true, Destination: "archive"}.Validate())
+}
+
+func TestApply(t *testing.T) {
+	results := []Result{{ID: "a"}, {ID: "b", Skipped: true}, {ID: "c"}, {ID: "d"}}
+	client := &fakeMover{failOn: "d"}
+
+	applied, err := Apply(context.Background(), client, results, "archive", "top")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, applied)
+	assert.Equal(t, []string{"c", "a"}, client.moved, "moved to the top last first, to keep their order")
+	assert.True(t, results[3].Failed)
+
+	client = &fakeMover{}
+	_, err = Apply(context.Background(), client, []Result{{ID: "a"}, {ID: "c"}}, "archive", "bottom")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, client.moved)
+
+	_, err = Apply(context.Background(), client, results, "archive", "middle")
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+// Package opml reads OPML outlines, the interchange format of outliners such
+// as OmniOutliner and Dynalist.
+package opml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/outline"
+)
+
+// Document is a parsed OPML file.
+type Document struct {
+	Title string
+	Nodes []*outline.Node
+}
+
+type document struct {
+	Head struct {
+		Title string `xml:"title"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []outlineElement `xml:"outline"`
+	} `xml:"body"`
+}
+
+type outlineElement struct {
+	Text     string           `xml:"text,attr"`
+	Title    string           `xml:"title,attr"`
+	Note     string           `xml:"_note,attr"`
+	Complete string           `xml:"_complete,attr"`
+	Outlines []outlineElement `xml:"outline"`
+}
+
+// Parse reads an OPML document. Each outline's text (or title) becomes a
+// node name, its _note attribute the note, and _complete="true" marks it completed.
+func Parse(r io.Reader) (*Document, error) {
+	var doc document
+	decoder := xml.NewDecoder(r)
+	// OPML files in the wild declare encodings other than UTF-8; accept them as is
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("cannot parse OPML: %w", err)
+	}
+	return &Document{Title: strings.TrimSpace(doc.Head.Title), Nodes: convert(doc.Body.Outlines)}, nil
+}
+
+func convert(outlines []outlineElement) []*outline.Node {
+	if len(outlines) == 0 {
+		return nil
+	}
+	nodes := make([]*outline.Node, 0, len(outlines))
+	for _, o := range outlines {
+		name := o.Text
+		if name == "" {
+			name = o.Title
+		}
+		nodes = append(nodes, &outline.Node{
+			Name:      name,
+			Note:      o.Note,
+			Completed: strings.EqualFold(o.Complete, "true"),
+			Children:  convert(o.Outlines),
+		})
+	}
+	return nodes
+}
@@ -0,0 +1,37 @@
+package opml
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/outline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	f, err := os.Open("testdata/dynalist.opml")
+	require.NoError(t, err)
+	defer f.Close()
+
+	doc, err := Parse(f)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Projects", doc.Title)
+	assert.Equal(t, []*outline.Node{
+		{Name: "Website", Note: "Launch & marketing", Children: []*outline.Node{
+			{Name: "Write **copy**", Completed: true},
+			{Name: "Pick a <b>theme</b>"},
+		}},
+		{Name: "Garden", Children: []*outline.Node{
+			{Name: "Tomatoes"},
+		}},
+	}, doc.Nodes)
+	assert.Equal(t, 5, outline.Count(doc.Nodes))
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse(strings.NewReader("<opml><body><outline text='a'>"))
+	assert.ErrorContains(t, err, "cannot parse OPML")
+}
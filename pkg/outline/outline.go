@@ -0,0 +1,170 @@
+// Package outline creates trees of nodes parsed from other formats, such as
+// OPML and markdown documents, as Workflowy nodes, and keeps Workflowy
+// subtrees in sync with them.
+package outline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// Node is an outline entry.
+type Node struct {
+	Name       string  `json:"name"`
+	Note       string  `json:"note,omitempty"`
+	LayoutMode string  `json:"layout_mode,omitempty"`
+	Completed  bool    `json:"completed,omitempty"`
+	Children   []*Node `json:"children,omitempty"`
+}
+
+// Count returns the number of nodes in nodes and their descendants.
+func Count(nodes []*Node) int {
+	count := len(nodes)
+	for _, node := range nodes {
+		count += Count(node.Children)
+	}
+	return count
+}
+
+// Creator is the subset of workflowy.Client needed to import an outline.
+type Creator interface {
+	CreateNode(ctx context.Context, req *workflowy.CreateNodeRequest) (*workflowy.CreateNodeResponse, error)
+	CompleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error)
+}
+
+// Options controls how an outline is imported.
+type Options struct {
+	ParentID string
+	Position string              // position of the top-level nodes: "top" or "bottom" (default: API default)
+	Convert  func(string) string // applied to names and notes, e.g. markdown conversion
+	// Collision is the policy for nodes named like an existing sibling (see
+	// workflowy.CollisionSkip), applied to the names after Convert.
+	Collision string
+	// Children lists the existing children of ParentID, and of the nodes
+	// merged into, to check names against. Without it, no name collides.
+	Children func(ctx context.Context, parentID string) ([]*workflowy.Item, error)
+}
+
+// Result summarizes an import.
+type Result struct {
+	Created int      `json:"created"`
+	Failed  int      `json:"failed"`            // nodes not created, including the descendants of failed nodes
+	Skipped int      `json:"skipped,omitempty"` // nodes left out as their name was taken, with their descendants
+	Merged  int      `json:"merged,omitempty"`  // nodes whose children were added to the sibling of the same name
+	Renamed int      `json:"renamed,omitempty"` // nodes created with a suffix as their name was taken
+	IDs     []string `json:"ids"`               // IDs of the top-level nodes created, or merged into
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// Import creates nodes under opts.ParentID in document order. A node that
+// cannot be created is skipped with its descendants and the import continues;
+// a cancelled context stops it. The result reports what was created.
+func Import(ctx context.Context, client Creator, nodes []*Node, opts Options) *Result {
+	result := &Result{IDs: []string{}}
+	if opts.Convert == nil {
+		opts.Convert = func(s string) string { return s }
+	}
+	siblings, err := existingSiblings(ctx, opts, opts.ParentID)
+	if err != nil {
+		result.Failed += Count(nodes)
+		result.Errors = append(result.Errors, err.Error())
+		return result
+	}
+	if opts.Position != "top" {
+		result.IDs = append(result.IDs, importNodes(ctx, client, nodes, opts.ParentID, opts.Position, siblings, opts, result)...)
+		return result
+	}
+
+	// each node created at the top goes above the previous one, so create the last first
+	reversed := slices.Clone(nodes)
+	slices.Reverse(reversed)
+	ids := importNodes(ctx, client, reversed, opts.ParentID, opts.Position, siblings, opts, result)
+	slices.Reverse(ids)
+	result.IDs = append(result.IDs, ids...)
+	return result
+}
+
+// existingSiblings returns the existing children of parentID, to check the
+// names of the nodes imported under it against.
+func existingSiblings(ctx context.Context, opts Options, parentID string) (*workflowy.Siblings, error) {
+	if opts.Children == nil {
+		return workflowy.NewSiblings(nil), nil
+	}
+	children, err := opts.Children(ctx, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the children of %s to check name collisions: %w", parentID, err)
+	}
+	return workflowy.NewSiblings(children), nil
+}
+
+// importNodes creates nodes under parentID, whose children are siblings,
+// and returns the IDs of the nodes created or merged into.
+func importNodes(ctx context.Context, client Creator, nodes []*Node, parentID, position string, siblings *workflowy.Siblings, opts Options, result *Result) []string {
+	var ids []string
+	for _, node := range nodes {
+		if ctx.Err() != nil {
+			result.Failed += 1 + Count(node.Children)
+			continue
+		}
+
+		name, existing := siblings.Resolve(opts.Convert(node.Name), opts.Collision)
+		switch {
+		case existing != nil && opts.Collision == workflowy.CollisionMerge:
+			result.Merged++
+			ids = append(ids, existing.ID)
+			slog.DebugContext(ctx, "merged node", "id", existing.ID, "parent_id", parentID)
+			if len(node.Children) == 0 {
+				continue
+			}
+			children, err := existingSiblings(ctx, opts, existing.ID)
+			if err != nil {
+				result.Failed += Count(node.Children)
+				result.Errors = append(result.Errors, err.Error())
+				continue
+			}
+			importNodes(ctx, client, node.Children, existing.ID, "bottom", children, opts, result)
+			continue
+		case existing != nil:
+			result.Skipped += 1 + Count(node.Children)
+			slog.DebugContext(ctx, "skipped node", "name", name, "existing_id", existing.ID)
+			continue
+		case name != opts.Convert(node.Name):
+			result.Renamed++
+		}
+
+		req := &workflowy.CreateNodeRequest{ParentID: parentID, Name: name}
+		if node.Note != "" {
+			note := opts.Convert(node.Note)
+			req.Note = &note
+		}
+		if node.LayoutMode != "" {
+			layoutMode := node.LayoutMode
+			req.LayoutMode = &layoutMode
+		}
+		if position != "" {
+			req.Position = &position
+		}
+		resp, err := client.CreateNode(ctx, req)
+		if err != nil {
+			result.Failed += 1 + Count(node.Children)
+			result.Errors = append(result.Errors, fmt.Sprintf("cannot create %q: %v", node.Name, err))
+			continue
+		}
+		result.Created++
+		ids = append(ids, resp.ItemID)
+		siblings.Add(&workflowy.Item{ID: resp.ItemID, Name: name})
+		slog.DebugContext(ctx, "imported node", "id", resp.ItemID, "parent_id", parentID)
+
+		if node.Completed {
+			if _, err := client.CompleteNode(ctx, resp.ItemID); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("cannot complete %q: %v", node.Name, err))
+			}
+		}
+		importNodes(ctx, client, node.Children, resp.ItemID, "bottom", workflowy.NewSiblings(nil), opts, result)
+	}
+	return ids
+}
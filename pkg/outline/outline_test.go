@@ -0,0 +1,133 @@
+package outline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCreator struct {
+	created   []*workflowy.CreateNodeRequest
+	completed []string
+	failOn    string
+}
+
+func (c *fakeCreator) CreateNode(ctx context.Context, req *workflowy.CreateNodeRequest) (*workflowy.CreateNodeResponse, error) {
+	if req.Name == c.failOn {
+		return nil, fmt.Errorf("boom")
+	}
+	c.created = append(c.created, req)
+	return &workflowy.CreateNodeResponse{ItemID: fmt.Sprintf("node-%d", len(c.created))}, nil
+}
+
+func (c *fakeCreator) CompleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error) {
+	c.completed = append(c.completed, itemID)
+	return &workflowy.UpdateNodeResponse{}, nil
+}
+
+func testNodes() []*Node {
+	return []*Node{
+		{Name: "Website", Note: "Launch & marketing", LayoutMode: "h1", Children: []*Node{
+			{Name: "Write copy", Completed: true},
+			{Name: "Pick a theme"},
+		}},
+		{Name: "Garden", Children: []*Node{
+			{Name: "Tomatoes"},
+		}},
+	}
+}
+
+func TestImport(t *testing.T) {
+	client := &fakeCreator{}
+
+	result := Import(context.Background(), client, testNodes(), Options{ParentID: "parent"})
+
+	assert.Equal(t, 5, result.Created)
+	assert.Zero(t, result.Failed)
+	assert.Equal(t, []string{"node-1", "node-4"}, result.IDs)
+	require.Len(t, client.created, 5)
+	assert.Equal(t, "parent", client.created[0].ParentID)
+	assert.Equal(t, "Launch & marketing", *client.created[0].Note)
+	assert.Equal(t, "h1", *client.created[0].LayoutMode)
+	assert.Nil(t, client.created[1].LayoutMode)
+	assert.Equal(t, "node-1", client.created[1].ParentID)
+	assert.Equal(t, "bottom", *client.created[1].Position, "children keep their order")
+	assert.Equal(t, "node-4", client.created[4].ParentID)
+	assert.Equal(t, []string{"node-2"}, client.completed)
+}
+
+func TestImport_SkipsSubtreeOfFailedNode(t *testing.T) {
+	client := &fakeCreator{failOn: "WEBSITE"}
+
+	result := Import(context.Background(), client, testNodes(), Options{ParentID: "parent", Convert: strings.ToUpper})
+
+	assert.Equal(t, 2, result.Created)
+	assert.Equal(t, 3, result.Failed)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0], `cannot create "Website"`)
+	assert.Equal(t, "GARDEN", client.created[0].Name)
+}
+
+func TestImport_Collision(t *testing.T) {
+	existing := map[string][]*workflowy.Item{
+		"parent": {{ID: "web", Name: "Website"}},
+		"web":    {{ID: "copy", Name: "Write copy"}},
+	}
+	var listed []string
+	children := func(ctx context.Context, parentID string) ([]*workflowy.Item, error) {
+		listed = append(listed, parentID)
+		return existing[parentID], nil
+	}
+
+	client := &fakeCreator{}
+	result := Import(context.Background(), client, testNodes(), Options{ParentID: "parent", Collision: workflowy.CollisionSkip, Children: children})
+	assert.Equal(t, 2, result.Created)
+	assert.Equal(t, 3, result.Skipped)
+	assert.Equal(t, "Garden", client.created[0].Name)
+
+	client = &fakeCreator{}
+	result = Import(context.Background(), client, testNodes(), Options{ParentID: "parent", Collision: workflowy.CollisionRename, Children: children})
+	assert.Equal(t, 5, result.Created)
+	assert.Equal(t, 1, result.Renamed)
+	assert.Equal(t, "Website (2)", client.created[0].Name)
+
+	client = &fakeCreator{}
+	listed = nil
+	result = Import(context.Background(), client, testNodes(), Options{ParentID: "parent", Collision: workflowy.CollisionMerge, Children: children})
+	assert.Equal(t, 2, result.Merged, "Website and its child Write copy")
+	assert.Equal(t, 3, result.Created)
+	assert.Equal(t, []string{"web", "node-2"}, result.IDs)
+	require.Len(t, client.created, 3)
+	assert.Equal(t, "Pick a theme", client.created[0].Name)
+	assert.Equal(t, "web", client.created[0].ParentID)
+	assert.Equal(t, []string{"parent", "web"}, listed, "the children of merged nodes are listed as they are reached")
+}
+
+func TestImport_CollisionListFails(t *testing.T) {
+	client := &fakeCreator{}
+	children := func(ctx context.Context, parentID string) ([]*workflowy.Item, error) {
+		return nil, fmt.Errorf("unavailable")
+	}
+
+	result := Import(context.Background(), client, testNodes(), Options{ParentID: "parent", Collision: workflowy.CollisionSkip, Children: children})
+	assert.Equal(t, 5, result.Failed)
+	assert.Empty(t, client.created)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0], "cannot list the children of parent")
+}
+
+func TestImport_TopKeepsOrder(t *testing.T) {
+	nodes := []*Node{{Name: "first"}, {Name: "second"}}
+	client := &fakeCreator{}
+
+	result := Import(context.Background(), client, nodes, Options{ParentID: "parent", Position: "top"})
+
+	require.Len(t, client.created, 2)
+	assert.Equal(t, "second", client.created[0].Name, "the last node is created first so the first ends on top")
+	assert.Equal(t, []string{"node-2", "node-1"}, result.IDs, "IDs are in document order")
+}
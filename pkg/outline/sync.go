@@ -0,0 +1,264 @@
+package outline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// Kinds of Change
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// Change is one step in making a Workflowy subtree match an outline. Only
+// names and completion are compared by Diff: notes and layouts are not
+// represented in every outline format, so they are left as they are.
+// DiffWithNotes compares notes too.
+type Change struct {
+	Op        string  `json:"op"`
+	ID        string  `json:"id,omitempty"`        // the node updated or deleted
+	ParentID  string  `json:"parent_id,omitempty"` // the node a created node goes under
+	Name      string  `json:"name"`                // the current name, or the name of the created node
+	NewName   string  `json:"new_name,omitempty"`  // set when an update renames the node
+	Completed *bool   `json:"completed,omitempty"` // set when an update completes or uncompletes the node
+	NewNote   *string `json:"new_note,omitempty"`  // set when an update changes the note
+	Node      *Node   `json:"node,omitempty"`      // the node created, with its children
+}
+
+func (c Change) String() string {
+	switch c.Op {
+	case OpCreate:
+		return fmt.Sprintf("+ %s", c.Name)
+	case OpDelete:
+		return fmt.Sprintf("- %s", c.Name)
+	}
+	var parts []string
+	if c.NewName != "" {
+		parts = append(parts, fmt.Sprintf("%q → %q", c.Name, c.NewName))
+	} else {
+		parts = append(parts, c.Name)
+	}
+	if c.Completed != nil && *c.Completed {
+		parts = append(parts, "(complete)")
+	} else if c.Completed != nil {
+		parts = append(parts, "(uncomplete)")
+	}
+	if c.NewNote != nil {
+		parts = append(parts, "(note)")
+	}
+	return "~ " + strings.Join(parts, " ")
+}
+
+// Reverse returns the change as seen from the other side: what happens to
+// the outline when it is made to match the subtree. The previous note is not
+// known, so a note change cannot be reversed.
+func (c Change) Reverse() Change {
+	switch c.Op {
+	case OpCreate:
+		c.Op = OpDelete
+	case OpDelete:
+		c.Op = OpCreate
+	default:
+		if c.NewName != "" {
+			c.Name, c.NewName = c.NewName, c.Name
+		}
+		if c.Completed != nil {
+			completed := !*c.Completed
+			c.Completed = &completed
+		}
+	}
+	return c
+}
+
+// FromItems converts Workflowy items to an outline of their names, notes,
+// completion and layouts.
+func FromItems(items []*workflowy.Item) []*Node {
+	if len(items) == 0 {
+		return nil
+	}
+	nodes := make([]*Node, 0, len(items))
+	for _, item := range items {
+		node := &Node{Name: item.Name, Completed: item.IsCompleted(), Children: FromItems(item.Children)}
+		if item.Note != nil {
+			node.Note = *item.Note
+		}
+		if mode, ok := item.Data["layoutMode"].(string); ok {
+			node.LayoutMode = mode
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// ToItems converts an outline to Workflowy items without IDs, the inverse of FromItems.
+func ToItems(nodes []*Node) []*workflowy.Item {
+	if len(nodes) == 0 {
+		return nil
+	}
+	items := make([]*workflowy.Item, 0, len(nodes))
+	for _, node := range nodes {
+		item := &workflowy.Item{Name: node.Name, Completed: node.Completed, Children: ToItems(node.Children)}
+		if node.Note != "" {
+			note := node.Note
+			item.Note = &note
+		}
+		if node.LayoutMode != "" {
+			item.Data = map[string]interface{}{"layoutMode": node.LayoutMode}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// Diff returns the changes that make the children of parentID, items, match
+// nodes. At each level, nodes are matched to items of the same name first, in
+// order; the remaining nodes and items are paired in order and updated, and
+// what is left over is created or deleted. Created nodes go at the bottom of
+// their parent: sync does not reorder existing nodes.
+func Diff(nodes []*Node, items []*workflowy.Item, parentID string) []Change {
+	return diff(nodes, items, parentID, false)
+}
+
+// DiffWithNotes is Diff, also updating the notes of items that differ from
+// their nodes, to make a subtree match another one.
+func DiffWithNotes(nodes []*Node, items []*workflowy.Item, parentID string) []Change {
+	return diff(nodes, items, parentID, true)
+}
+
+func diff(nodes []*Node, items []*workflowy.Item, parentID string, notes bool) []Change {
+	matched := make([]*workflowy.Item, len(nodes))
+	used := make([]bool, len(items))
+	for i, node := range nodes {
+		for j, item := range items {
+			if !used[j] && item.Name == node.Name {
+				matched[i], used[j] = item, true
+				break
+			}
+		}
+	}
+	next := 0
+	for i := range nodes {
+		if matched[i] != nil {
+			continue
+		}
+		for next < len(items) && used[next] {
+			next++
+		}
+		if next < len(items) {
+			matched[i], used[next] = items[next], true
+		}
+	}
+
+	var changes []Change
+	for i, node := range nodes {
+		item := matched[i]
+		if item == nil {
+			changes = append(changes, Change{Op: OpCreate, ParentID: parentID, Name: node.Name, Node: node})
+			continue
+		}
+		update := Change{Op: OpUpdate, ID: item.ID, Name: item.Name}
+		if item.Name != node.Name {
+			update.NewName = node.Name
+		}
+		if completed := item.IsCompleted(); completed != node.Completed {
+			update.Completed = &node.Completed
+		}
+		if note := itemNote(item); notes && note != node.Note {
+			update.NewNote = &node.Note
+		}
+		if update.NewName != "" || update.Completed != nil || update.NewNote != nil {
+			changes = append(changes, update)
+		}
+		changes = append(changes, diff(node.Children, item.Children, item.ID, notes)...)
+	}
+	for j, item := range items {
+		if !used[j] {
+			changes = append(changes, Change{Op: OpDelete, ID: item.ID, Name: item.Name})
+		}
+	}
+	return changes
+}
+
+func itemNote(item *workflowy.Item) string {
+	if item.Note == nil {
+		return ""
+	}
+	return *item.Note
+}
+
+// Syncer is the subset of workflowy.Client needed to apply changes.
+type Syncer interface {
+	Creator
+	UpdateNode(ctx context.Context, itemID string, req *workflowy.UpdateNodeRequest) (*workflowy.UpdateNodeResponse, error)
+	UncompleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error)
+	DeleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error)
+}
+
+// SyncResult summarizes applied changes.
+type SyncResult struct {
+	Applied int      `json:"applied"`
+	Failed  int      `json:"failed"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// Apply applies changes in order. A change that fails is reported and the
+// others are still applied; a cancelled context stops them.
+func Apply(ctx context.Context, client Syncer, changes []Change) *SyncResult {
+	result := &SyncResult{}
+	for _, change := range changes {
+		if ctx.Err() != nil {
+			result.Failed++
+			continue
+		}
+		if err := applyChange(ctx, client, change); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("cannot %s %q: %v", change.Op, change.Name, err))
+			continue
+		}
+		result.Applied++
+		slog.DebugContext(ctx, "applied sync change", "op", change.Op, "id", change.ID, "parent_id", change.ParentID)
+	}
+	return result
+}
+
+func applyChange(ctx context.Context, client Syncer, change Change) error {
+	switch change.Op {
+	case OpCreate:
+		imported := Import(ctx, client, []*Node{change.Node}, Options{ParentID: change.ParentID, Position: "bottom"})
+		if imported.Failed > 0 {
+			return fmt.Errorf("%d of %d node(s) not created: %s", imported.Failed, Count([]*Node{change.Node}), strings.Join(imported.Errors, "; "))
+		}
+	case OpUpdate:
+		if change.NewName != "" || change.NewNote != nil {
+			req := &workflowy.UpdateNodeRequest{Note: change.NewNote}
+			if change.NewName != "" {
+				req.Name = &change.NewName
+			}
+			if _, err := client.UpdateNode(ctx, change.ID, req); err != nil {
+				return err
+			}
+		}
+		if change.Completed != nil && *change.Completed {
+			if _, err := client.CompleteNode(ctx, change.ID); err != nil {
+				return err
+			}
+		} else if change.Completed != nil {
+			if _, err := client.UncompleteNode(ctx, change.ID); err != nil {
+				return err
+			}
+		}
+	case OpDelete:
+		if _, err := client.DeleteNode(ctx, change.ID); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown operation %q", change.Op)
+	}
+	return nil
+}
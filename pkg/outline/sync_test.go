@@ -0,0 +1,136 @@
+package outline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSyncer struct {
+	fakeCreator
+	renamed     map[string]string
+	notes       map[string]string
+	uncompleted []string
+	deleted     []string
+}
+
+func (c *fakeSyncer) UpdateNode(ctx context.Context, itemID string, req *workflowy.UpdateNodeRequest) (*workflowy.UpdateNodeResponse, error) {
+	if req.Name != nil {
+		c.renamed[itemID] = *req.Name
+	}
+	if req.Note != nil {
+		c.notes[itemID] = *req.Note
+	}
+	return &workflowy.UpdateNodeResponse{}, nil
+}
+
+func (c *fakeSyncer) UncompleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error) {
+	c.uncompleted = append(c.uncompleted, itemID)
+	return &workflowy.UpdateNodeResponse{}, nil
+}
+
+func (c *fakeSyncer) DeleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error) {
+	c.deleted = append(c.deleted, itemID)
+	return &workflowy.UpdateNodeResponse{}, nil
+}
+
+func testItems() []*workflowy.Item {
+	completedAt := int64(1700000000)
+	return []*workflowy.Item{
+		{ID: "website", Name: "Website", Children: []*workflowy.Item{
+			{ID: "copy", Name: "Write copy"},
+			{ID: "theme", Name: "Pick a theme", CompletedAt: &completedAt},
+		}},
+		{ID: "garden", Name: "Garden", Children: []*workflowy.Item{
+			{ID: "peppers", Name: "Peppers"},
+		}},
+		{ID: "archive", Name: "Archive"},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	changes := Diff(testNodes(), testItems(), "parent")
+
+	completed, uncompleted := true, false
+	assert.Equal(t, []Change{
+		{Op: OpUpdate, ID: "copy", Name: "Write copy", Completed: &completed},
+		{Op: OpUpdate, ID: "theme", Name: "Pick a theme", Completed: &uncompleted},
+		{Op: OpUpdate, ID: "peppers", Name: "Peppers", NewName: "Tomatoes"},
+		{Op: OpDelete, ID: "archive", Name: "Archive"},
+	}, changes)
+	assert.Empty(t, Diff(FromItems(testItems()), testItems(), "parent"), "an outline of the items has no changes")
+}
+
+func TestDiff_CreatesNewSubtrees(t *testing.T) {
+	nodes := append(FromItems(testItems()), &Node{Name: "Kitchen", Children: []*Node{{Name: "Paint"}}})
+
+	changes := Diff(nodes, testItems(), "parent")
+
+	require.Len(t, changes, 1)
+	assert.Equal(t, OpCreate, changes[0].Op)
+	assert.Equal(t, "parent", changes[0].ParentID)
+	assert.Equal(t, "Paint", changes[0].Node.Children[0].Name)
+	assert.Equal(t, "- Kitchen", changes[0].Reverse().String())
+}
+
+func TestDiffWithNotes(t *testing.T) {
+	nodes := FromItems(testItems())
+	nodes[0].Note = "launch in May"
+	nodes[1].Children[0].Name = "Tomatoes"
+
+	changes := DiffWithNotes(nodes, testItems(), "parent")
+
+	note := "launch in May"
+	assert.Equal(t, []Change{
+		{Op: OpUpdate, ID: "website", Name: "Website", NewNote: &note},
+		{Op: OpUpdate, ID: "peppers", Name: "Peppers", NewName: "Tomatoes"},
+	}, changes)
+	assert.Equal(t, "~ Website (note)", changes[0].String())
+	assert.Len(t, Diff(nodes, testItems(), "parent"), 1, "Diff leaves notes as they are")
+
+	client := &fakeSyncer{renamed: map[string]string{}, notes: map[string]string{}}
+	result := Apply(context.Background(), client, changes)
+	assert.Equal(t, 2, result.Applied)
+	assert.Equal(t, map[string]string{"website": "launch in May"}, client.notes)
+	assert.Equal(t, map[string]string{"peppers": "Tomatoes"}, client.renamed)
+}
+
+func TestChange_Reverse(t *testing.T) {
+	completed := true
+	change := Change{Op: OpUpdate, ID: "a", Name: "Old", NewName: "New", Completed: &completed}
+
+	assert.Equal(t, `~ "Old" → "New" (complete)`, change.String())
+	assert.Equal(t, `~ "New" → "Old" (uncomplete)`, change.Reverse().String())
+	assert.Equal(t, change, change.Reverse().Reverse())
+}
+
+func TestApply(t *testing.T) {
+	client := &fakeSyncer{renamed: map[string]string{}, notes: map[string]string{}}
+	nodes := testNodes()
+	nodes[1].Children = append(nodes[1].Children, &Node{Name: "Basil"})
+
+	result := Apply(context.Background(), client, Diff(nodes, testItems(), "parent"))
+
+	assert.Equal(t, 5, result.Applied)
+	assert.Zero(t, result.Failed)
+	assert.Equal(t, []string{"copy"}, client.completed)
+	assert.Equal(t, []string{"theme"}, client.uncompleted)
+	assert.Equal(t, map[string]string{"peppers": "Tomatoes"}, client.renamed)
+	assert.Equal(t, []string{"archive"}, client.deleted)
+	require.Len(t, client.created, 1)
+	assert.Equal(t, "Basil", client.created[0].Name)
+	assert.Equal(t, "garden", client.created[0].ParentID)
+	assert.Equal(t, "bottom", *client.created[0].Position)
+}
+
+func TestToItems(t *testing.T) {
+	nodes := FromItems(testItems())
+	nodes[0].Note = "launch in May"
+	nodes[2].LayoutMode = "h1"
+
+	assert.Equal(t, nodes, FromItems(ToItems(nodes)))
+	assert.Empty(t, ToItems(nodes)[0].ID)
+}
@@ -0,0 +1,275 @@
+package patch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+const (
+	FieldName = "name"
+	FieldNote = "note"
+)
+
+// Edit is a single field change on a node: the value of Field is expected to be Old
+// and will be set to New.
+type Edit struct {
+	ID    string `json:"id"`
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Result records the outcome of applying an edit.
+type Result struct {
+	Edit
+	Applied    bool   `json:"applied"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	Failed     bool   `json:"failed,omitempty"`
+	SkipReason string `json:"skip_reason,omitempty"`
+}
+
+func (r Result) String() string {
+	if r.Skipped {
+		return fmt.Sprintf("%s (%s): \"%s\" (skipped: %s)", r.ID, r.Field, r.Old, r.SkipReason)
+	}
+	status := "→"
+	if !r.Applied {
+		status = "→ (dry-run)"
+	}
+	return fmt.Sprintf("%s (%s): \"%s\" %s \"%s\"", r.ID, r.Field, r.Old, status, r.New)
+}
+
+// Reverse returns the edit that undoes e.
+func (e Edit) Reverse() Edit {
+	return Edit{ID: e.ID, Field: e.Field, Old: e.New, New: e.Old}
+}
+
+// Reverse returns the edits that undo edits, in reverse order.
+func Reverse(edits []Edit) []Edit {
+	reversed := make([]Edit, len(edits))
+	for i, edit := range edits {
+		reversed[len(edits)-1-i] = edit.Reverse()
+	}
+	return reversed
+}
+
+// Validate checks that the edit has an ID and a supported field.
+func (e Edit) Validate() error {
+	if e.ID == "" {
+		return fmt.Errorf("edit is missing an id")
+	}
+	if e.Field != FieldName && e.Field != FieldNote {
+		return fmt.Errorf("edit %s: field must be '%s' or '%s', got '%s'", e.ID, FieldName, FieldNote, e.Field)
+	}
+	return nil
+}
+
+// UpdateRequest returns the update request that applies the edit.
+func (e Edit) UpdateRequest() *workflowy.UpdateNodeRequest {
+	value := e.New
+	if e.Field == FieldNote {
+		return &workflowy.UpdateNodeRequest{Note: &value}
+	}
+	return &workflowy.UpdateNodeRequest{Name: &value}
+}
+
+// ReadFile loads a list of edits from a JSON file. See Parse for the accepted formats.
+func ReadFile(path string) ([]Edit, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read patch file: %w", err)
+	}
+	edits, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse patch file: %w", err)
+	}
+	return edits, nil
+}
+
+// entry accepts the edit schema as well as the JSON output of replace
+// (old_name/new_name) and transform (original/new) dry-runs.
+type entry struct {
+	ID       string  `json:"id"`
+	Field    string  `json:"field"`
+	Old      *string `json:"old"`
+	New      *string `json:"new"`
+	Original *string `json:"original"`
+	OldName  *string `json:"old_name"`
+	NewName  *string `json:"new_name"`
+	OldNote  *string `json:"old_note"`
+	NewNote  *string `json:"new_note"`
+	MovedTo  string  `json:"moved_to"`
+	Skipped  bool    `json:"skipped"`
+}
+
+func (e entry) edits() []Edit {
+	if e.OldName != nil || e.NewName != nil {
+		edits := []Edit{{ID: e.ID, Field: FieldName, Old: deref(e.OldName), New: deref(e.NewName)}}
+		if e.MovedTo != "" {
+			edits = append(edits, Edit{ID: e.ID, Field: FieldNote, Old: deref(e.OldNote), New: deref(e.NewNote)})
+		}
+		return edits
+	}
+	old := e.Old
+	if old == nil {
+		old = e.Original
+	}
+	return []Edit{{ID: e.ID, Field: e.Field, Old: deref(old), New: deref(e.New)}}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// Parse decodes a JSON list of edits, optionally wrapped in {"results": [...]}.
+// Entries in the replace and transform output formats are converted to edits,
+// and entries marked as skipped are ignored.
+func Parse(data []byte) ([]Edit, error) {
+	var entries []entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		var wrapped struct {
+			Results []entry `json:"results"`
+		}
+		if wrappedErr := json.Unmarshal(data, &wrapped); wrappedErr != nil {
+			return nil, err
+		}
+		entries = wrapped.Results
+	}
+
+	edits := []Edit{}
+	for _, e := range entries {
+		if e.Skipped {
+			continue
+		}
+		for _, edit := range e.edits() {
+			if err := edit.Validate(); err != nil {
+				return nil, err
+			}
+			edits = append(edits, edit)
+		}
+	}
+	return edits, nil
+}
+
+// WriteFile saves a list of edits as JSON, readable by the owner only since
+// edits hold outline content, such as the secrets an undo patch restores.
+func WriteFile(path string, edits []Edit) error {
+	if edits == nil {
+		edits = []Edit{}
+	}
+	data, err := json.MarshalIndent(edits, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot marshal patch: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("cannot write patch file: %w", err)
+	}
+	return nil
+}
+
+// Options controls how edits are applied.
+type Options struct {
+	DryRun bool
+	// Force skips verifying that current values still match Old.
+	Force bool
+}
+
+// Verify checks each edit against the current value of its node and field.
+// Edits whose Old value no longer matches are returned as skipped. Successive
+// edits of the same field are checked against the value left by the previous edit.
+func Verify(ctx context.Context, client workflowy.Client, edits []Edit) []Result {
+	current := make(map[string]string)
+	fetched := make(map[string]error)
+
+	results := make([]Result, 0, len(edits))
+	for _, edit := range edits {
+		result := Result{Edit: edit}
+		if _, ok := fetched[edit.ID]; !ok {
+			item, err := client.GetItem(ctx, edit.ID)
+			if err == nil && item == nil {
+				err = fmt.Errorf("node not found")
+			}
+			fetched[edit.ID] = err
+			if err == nil {
+				current[edit.ID+"/"+FieldName] = item.Name
+				current[edit.ID+"/"+FieldNote] = deref(item.Note)
+			}
+		}
+		if err := fetched[edit.ID]; err != nil {
+			result.Skipped = true
+			result.Failed = true
+			result.SkipReason = fmt.Sprintf("cannot get node: %v", err)
+			results = append(results, result)
+			continue
+		}
+
+		key := edit.ID + "/" + edit.Field
+		if current[key] != edit.Old {
+			result.Skipped = true
+			result.SkipReason = fmt.Sprintf("current %s no longer matches: \"%s\"", edit.Field, current[key])
+			results = append(results, result)
+			continue
+		}
+		current[key] = edit.New
+		results = append(results, result)
+	}
+	return results
+}
+
+// Apply verifies (unless opts.Force) and writes each edit through the client.
+// Edits that fail verification or the update are reported as skipped and do
+// not stop the remaining edits. Once ctx is cancelled, remaining edits are
+// skipped as "cancelled".
+func Apply(ctx context.Context, client workflowy.Client, edits []Edit, opts Options) []Result {
+	var results []Result
+	if opts.Force {
+		results = make([]Result, 0, len(edits))
+		for _, edit := range edits {
+			results = append(results, Result{Edit: edit})
+		}
+	} else {
+		results = Verify(ctx, client, edits)
+	}
+
+	if opts.DryRun {
+		return results
+	}
+
+	for i := range results {
+		result := &results[i]
+		if result.Skipped {
+			continue
+		}
+		if ctx.Err() != nil {
+			result.Skipped = true
+			result.SkipReason = "cancelled"
+			continue
+		}
+		if _, err := client.UpdateNode(ctx, result.ID, result.UpdateRequest()); err != nil {
+			result.Skipped = true
+			result.Failed = true
+			result.SkipReason = fmt.Sprintf("update failed: %v", err)
+			continue
+		}
+		result.Applied = true
+	}
+	return results
+}
+
+// Applied returns the edits of results that were applied.
+func Applied(results []Result) []Edit {
+	var edits []Edit
+	for _, result := range results {
+		if result.Applied {
+			edits = append(edits, result.Edit)
+		}
+	}
+	return edits
+}
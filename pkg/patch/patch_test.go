@@ -0,0 +1,154 @@
+package patch
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverse(t *testing.T) {
+	edits := []Edit{
+		{ID: "1", Field: FieldName, Old: "a", New: "b"},
+		{ID: "1", Field: FieldNote, Old: "", New: "c"},
+		{ID: "2", Field: FieldName, Old: "x", New: "y"},
+	}
+
+	reversed := Reverse(edits)
+
+	assert.Equal(t, []Edit{
+		{ID: "2", Field: FieldName, Old: "y", New: "x"},
+		{ID: "1", Field: FieldNote, Old: "c", New: ""},
+		{ID: "1", Field: FieldName, Old: "b", New: "a"},
+	}, reversed)
+}
+
+func TestUpdateRequest(t *testing.T) {
+	nameReq := Edit{ID: "1", Field: FieldName, Old: "a", New: "b"}.UpdateRequest()
+	require.NotNil(t, nameReq.Name)
+	assert.Equal(t, "b", *nameReq.Name)
+	assert.Nil(t, nameReq.Note)
+
+	noteReq := Edit{ID: "1", Field: FieldNote, Old: "a", New: ""}.UpdateRequest()
+	require.NotNil(t, noteReq.Note)
+	assert.Equal(t, "", *noteReq.Note)
+	assert.Nil(t, noteReq.Name)
+}
+
+func TestWriteReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "undo.json")
+	edits := []Edit{{ID: "1", Field: FieldName, Old: "a", New: "b"}}
+
+	require.NoError(t, WriteFile(path, edits))
+	loaded, err := ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, edits, loaded)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Edit{ID: "1", Field: FieldNote}.Validate())
+	assert.Error(t, Edit{Field: FieldName}.Validate())
+	assert.Error(t, Edit{ID: "1", Field: "title"}.Validate())
+}
+
+func TestParse_Formats(t *testing.T) {
+	edits, err := Parse([]byte(`[{"id":"1","field":"note","old":"a","new":"b"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, []Edit{{ID: "1", Field: FieldNote, Old: "a", New: "b"}}, edits)
+
+	// replace output, including moves and skipped results
+	edits, err = Parse([]byte(`[
+		{"id":"1","old_name":"a x","new_name":"a","old_note":"","new_note":"x","moved_to":"note"},
+		{"id":"2","old_name":"b","new_name":"c","skipped":true,"skip_reason":"user declined"}
+	]`))
+	require.NoError(t, err)
+	assert.Equal(t, []Edit{
+		{ID: "1", Field: FieldName, Old: "a x", New: "a"},
+		{ID: "1", Field: FieldNote, Old: "", New: "x"},
+	}, edits)
+
+	// transform output wrapped as returned by MCP tools
+	edits, err = Parse([]byte(`{"results":[{"id":"3","field":"name","original":"abc","new":"ABC"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []Edit{{ID: "3", Field: FieldName, Old: "abc", New: "ABC"}}, edits)
+
+	_, err = Parse([]byte(`[{"id":"1","field":"title","old":"a","new":"b"}]`))
+	assert.Error(t, err)
+}
+
+type fakeClient struct {
+	workflowy.Client
+	items   map[string]*workflowy.Item
+	updates []string
+}
+
+func (c *fakeClient) GetItem(ctx context.Context, itemID string) (*workflowy.Item, error) {
+	item, ok := c.items[itemID]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	return item, nil
+}
+
+func (c *fakeClient) UpdateNode(ctx context.Context, itemID string, req *workflowy.UpdateNodeRequest) (*workflowy.UpdateNodeResponse, error) {
+	c.updates = append(c.updates, itemID)
+	return &workflowy.UpdateNodeResponse{}, nil
+}
+
+func TestApply_VerifiesCurrentValues(t *testing.T) {
+	note := "n"
+	client := &fakeClient{items: map[string]*workflowy.Item{
+		"1": {ID: "1", Name: "a", Note: &note},
+		"2": {ID: "2", Name: "changed"},
+	}}
+	edits := []Edit{
+		{ID: "1", Field: FieldName, Old: "a", New: "b"},
+		{ID: "1", Field: FieldName, Old: "b", New: "c"},
+		{ID: "1", Field: FieldNote, Old: "n", New: ""},
+		{ID: "2", Field: FieldName, Old: "original", New: "new"},
+		{ID: "3", Field: FieldName, Old: "x", New: "y"},
+	}
+
+	results := Apply(context.Background(), client, edits, Options{})
+
+	require.Len(t, results, 5)
+	assert.True(t, results[0].Applied)
+	assert.True(t, results[1].Applied)
+	assert.True(t, results[2].Applied)
+	assert.True(t, results[3].Skipped)
+	assert.Contains(t, results[3].SkipReason, "no longer matches")
+	assert.True(t, results[4].Skipped)
+	assert.Equal(t, []string{"1", "1", "1"}, client.updates)
+	assert.Len(t, Applied(results), 3)
+}
+
+func TestApply_DryRunAndForce(t *testing.T) {
+	client := &fakeClient{items: map[string]*workflowy.Item{"1": {ID: "1", Name: "other"}}}
+	edits := []Edit{{ID: "1", Field: FieldName, Old: "a", New: "b"}}
+
+	results := Apply(context.Background(), client, edits, Options{DryRun: true})
+	assert.True(t, results[0].Skipped)
+	assert.Empty(t, client.updates)
+
+	results = Apply(context.Background(), client, edits, Options{Force: true})
+	assert.True(t, results[0].Applied)
+	assert.Equal(t, []string{"1"}, client.updates)
+}
+
+func TestApply_StopsWhenCancelled(t *testing.T) {
+	client := &fakeClient{items: map[string]*workflowy.Item{"1": {ID: "1", Name: "a"}}}
+	edits := []Edit{{ID: "1", Field: FieldName, Old: "a", New: "b"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := Apply(ctx, client, edits, Options{Force: true})
+
+	assert.True(t, results[0].Skipped)
+	assert.False(t, results[0].Failed)
+	assert.Equal(t, "cancelled", results[0].SkipReason)
+	assert.Empty(t, client.updates)
+}
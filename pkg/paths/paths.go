@@ -0,0 +1,277 @@
+// Package paths locates the configuration, cache and backup directories on each platform.
+package paths
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// DirName is the name of the legacy per-user directory, ~/.workflowy
+const DirName = ".workflowy"
+
+// Environment variables overriding the default locations
+const (
+	ConfigDirEnv = "WORKFLOWY_CONFIG_DIR"
+	CacheDirEnv  = "WORKFLOWY_CACHE_DIR"
+	BackupDirEnv = "WORKFLOWY_BACKUP_DIR" // a list separated by os.PathListSeparator
+	StorageEnv   = "WORKFLOWY_STORAGE"    // a directory, or memory
+	APIKeyEnv    = "WORKFLOWY_API_KEY"    // the API key itself, used instead of the key file
+
+	SessionCookieEnv = "WORKFLOWY_SESSION_COOKIE" // browser session cookie for the private API
+)
+
+// APIKeyFileName is the name of the API key file in the configuration directory
+const APIKeyFileName = "api.key"
+
+// SessionCookieFileName is the name of the session cookie file in the configuration directory
+const SessionCookieFileName = "session.cookie"
+
+// appName names the directory created under %APPDATA% and %LOCALAPPDATA% on Windows
+const appName = "workflowy"
+
+// Configured holds the locations set in the configuration file. The
+// environment variables take precedence over them. A leading ~ stands for
+// the home directory.
+type Configured struct {
+	CacheDir   string
+	BackupDirs []string
+	Storage    string
+}
+
+var configured Configured
+
+// Configure sets the locations read from the configuration file for the
+// running process.
+func Configure(c Configured) {
+	configured = c
+}
+
+// Env describes the platform used to resolve paths, so resolution can be tested
+// for other operating systems.
+type Env struct {
+	GOOS       string
+	Home       string
+	Getenv     func(string) string
+	Exists     func(path string) bool
+	Configured Configured
+}
+
+// CurrentEnv returns the environment of the running process.
+func CurrentEnv() (Env, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Env{}, fmt.Errorf("could not get home directory: %w", err)
+	}
+	return Env{GOOS: runtime.GOOS, Home: home, Getenv: os.Getenv, Exists: exists, Configured: configured}, nil
+}
+
+// Storage returns where state files are kept: $WORKFLOWY_STORAGE if set, then
+// the configured storage, otherwise the cache directory.
+func (e Env) Storage() string {
+	if storage := e.Getenv(StorageEnv); storage != "" {
+		return storage
+	}
+	if e.Configured.Storage != "" {
+		return e.Configured.Storage
+	}
+	return e.CacheDir()
+}
+
+// ExpandHome replaces a leading ~ in path with the home directory of the
+// running process.
+func ExpandHome(path string) string {
+	env, err := CurrentEnv()
+	if err != nil {
+		return path
+	}
+	return env.expand(path)
+}
+
+// expand replaces a leading ~ in path with the home directory.
+func (e Env) expand(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		return filepath.Join(e.Home, path[1:])
+	}
+	return path
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// ConfigDir returns the directory holding the API key and other configuration:
+// $WORKFLOWY_CONFIG_DIR if set, otherwise ~/.workflowy, except on Windows where
+// %APPDATA%\workflowy is used unless ~/.workflowy already exists.
+func (e Env) ConfigDir() string {
+	if dir := e.Getenv(ConfigDirEnv); dir != "" {
+		return dir
+	}
+	return e.windowsDir("APPDATA")
+}
+
+// CacheDir returns the directory holding caches and state files:
+// $WORKFLOWY_CACHE_DIR if set, then the configured cache directory, otherwise
+// ~/.workflowy, except on Windows where %LOCALAPPDATA%\workflowy is used
+// unless ~/.workflowy already exists.
+func (e Env) CacheDir() string {
+	if dir := e.Getenv(CacheDirEnv); dir != "" {
+		return dir
+	}
+	if e.Configured.CacheDir != "" {
+		return e.expand(e.Configured.CacheDir)
+	}
+	return e.windowsDir("LOCALAPPDATA")
+}
+
+func (e Env) windowsDir(variable string) string {
+	legacy := filepath.Join(e.Home, DirName)
+	if e.GOOS != "windows" || e.Exists(legacy) {
+		return legacy
+	}
+	if base := e.Getenv(variable); base != "" {
+		return filepath.Join(base, appName)
+	}
+	return legacy
+}
+
+// BackupDirs returns the directories where Workflowy's Dropbox backups may be
+// found, most likely first: the Dropbox folder recorded in Dropbox's info.json,
+// ~/Dropbox, and on Windows the OneDrive folders. $WORKFLOWY_BACKUP_DIR, when
+// set, replaces these, and otherwise the configured backup directories do.
+func (e Env) BackupDirs() []string {
+	if list := e.Getenv(BackupDirEnv); list != "" {
+		return filepath.SplitList(list)
+	}
+	if len(e.Configured.BackupDirs) > 0 {
+		dirs := make([]string, len(e.Configured.BackupDirs))
+		for i, dir := range e.Configured.BackupDirs {
+			dirs[i] = e.expand(dir)
+		}
+		return dirs
+	}
+
+	var roots []string
+	roots = append(roots, e.dropboxRoots()...)
+	roots = append(roots, filepath.Join(e.Home, "Dropbox"))
+	if e.GOOS == "windows" {
+		if profile := e.Getenv("USERPROFILE"); profile != "" && profile != e.Home {
+			roots = append(roots, filepath.Join(profile, "Dropbox"))
+		}
+		for _, variable := range []string{"OneDrive", "OneDriveConsumer", "OneDriveCommercial"} {
+			if dir := e.Getenv(variable); dir != "" {
+				roots = append(roots, dir)
+			}
+		}
+		roots = append(roots, filepath.Join(e.Home, "OneDrive"))
+	}
+
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		dir := filepath.Join(root, "Apps", "Workflowy", "Data")
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// dropboxRoots reads the Dropbox folder locations from Dropbox's info.json.
+func (e Env) dropboxRoots() []string {
+	var candidates []string
+	if e.GOOS == "windows" {
+		for _, variable := range []string{"APPDATA", "LOCALAPPDATA"} {
+			if base := e.Getenv(variable); base != "" {
+				candidates = append(candidates, filepath.Join(base, "Dropbox", "info.json"))
+			}
+		}
+	} else {
+		candidates = append(candidates, filepath.Join(e.Home, ".dropbox", "info.json"))
+	}
+
+	for _, candidate := range candidates {
+		data, err := os.ReadFile(candidate)
+		if err != nil {
+			continue
+		}
+		var info map[string]struct {
+			Path string `json:"path"`
+		}
+		if err := json.Unmarshal(data, &info); err != nil {
+			continue
+		}
+		var roots []string
+		for _, account := range []string{"personal", "business"} {
+			if path := info[account].Path; path != "" {
+				roots = append(roots, path)
+			}
+		}
+		return roots
+	}
+	return nil
+}
+
+// ConfigDir returns the configuration directory of the running process.
+func ConfigDir() (string, error) {
+	env, err := CurrentEnv()
+	if err != nil {
+		return "", err
+	}
+	return env.ConfigDir(), nil
+}
+
+// CacheDir returns the cache directory of the running process.
+func CacheDir() (string, error) {
+	env, err := CurrentEnv()
+	if err != nil {
+		return "", err
+	}
+	return env.CacheDir(), nil
+}
+
+// Storage returns where the running process keeps state files.
+func Storage() (string, error) {
+	env, err := CurrentEnv()
+	if err != nil {
+		return "", err
+	}
+	return env.Storage(), nil
+}
+
+// BackupDirs returns the candidate backup directories of the running process.
+func BackupDirs() ([]string, error) {
+	env, err := CurrentEnv()
+	if err != nil {
+		return nil, err
+	}
+	return env.BackupDirs(), nil
+}
+
+// ConfigFile returns the path of name in the configuration directory.
+func ConfigFile(name string) (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// APIKeyFile returns the default API key file.
+func APIKeyFile() (string, error) {
+	return ConfigFile(APIKeyFileName)
+}
+
+// CacheFile returns the path of name in the cache directory.
+func CacheFile(name string) (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
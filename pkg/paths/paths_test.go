@@ -0,0 +1,137 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEnv(goos, home string, vars map[string]string, existing ...string) Env {
+	return Env{
+		GOOS:   goos,
+		Home:   home,
+		Getenv: func(key string) string { return vars[key] },
+		Exists: func(path string) bool {
+			for _, e := range existing {
+				if e == path {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+func TestConfigAndCacheDir_Unix(t *testing.T) {
+	env := testEnv("linux", "/home/u", nil)
+	assert.Equal(t, filepath.Join("/home/u", ".workflowy"), env.ConfigDir())
+	assert.Equal(t, filepath.Join("/home/u", ".workflowy"), env.CacheDir())
+}
+
+func TestConfigAndCacheDir_Windows(t *testing.T) {
+	vars := map[string]string{"APPDATA": "/users/u/AppData/Roaming", "LOCALAPPDATA": "/users/u/AppData/Local"}
+
+	env := testEnv("windows", "/users/u", vars)
+	assert.Equal(t, filepath.Join("/users/u/AppData/Roaming", "workflowy"), env.ConfigDir())
+	assert.Equal(t, filepath.Join("/users/u/AppData/Local", "workflowy"), env.CacheDir())
+
+	// an existing ~/.workflowy keeps being used
+	legacy := filepath.Join("/users/u", ".workflowy")
+	env = testEnv("windows", "/users/u", vars, legacy)
+	assert.Equal(t, legacy, env.ConfigDir())
+	assert.Equal(t, legacy, env.CacheDir())
+
+	// without APPDATA, fall back to ~/.workflowy
+	env = testEnv("windows", "/users/u", nil)
+	assert.Equal(t, legacy, env.ConfigDir())
+}
+
+func TestBackupDirs_Unix(t *testing.T) {
+	env := testEnv("darwin", t.TempDir(), nil)
+	assert.Equal(t, []string{filepath.Join(env.Home, "Dropbox", "Apps", "Workflowy", "Data")}, env.BackupDirs())
+}
+
+func TestBackupDirs_Windows(t *testing.T) {
+	env := testEnv("windows", "/users/u", map[string]string{
+		"USERPROFILE": "/users/u",
+		"OneDrive":    "/users/u/OneDrive - Personal",
+	})
+	assert.Equal(t, []string{
+		filepath.Join("/users/u", "Dropbox", "Apps", "Workflowy", "Data"),
+		filepath.Join("/users/u/OneDrive - Personal", "Apps", "Workflowy", "Data"),
+		filepath.Join("/users/u", "OneDrive", "Apps", "Workflowy", "Data"),
+	}, env.BackupDirs())
+}
+
+func TestBackupDirs_DropboxInfo(t *testing.T) {
+	home := t.TempDir()
+	infoDir := filepath.Join(home, ".dropbox")
+	require.NoError(t, os.MkdirAll(infoDir, 0o755))
+	info := `{"personal": {"path": "/data/Dropbox (Personal)"}, "business": {"path": "/data/Dropbox (Work)"}}`
+	require.NoError(t, os.WriteFile(filepath.Join(infoDir, "info.json"), []byte(info), 0o644))
+
+	env := testEnv("linux", home, nil)
+	assert.Equal(t, []string{
+		filepath.Join("/data/Dropbox (Personal)", "Apps", "Workflowy", "Data"),
+		filepath.Join("/data/Dropbox (Work)", "Apps", "Workflowy", "Data"),
+		filepath.Join(home, "Dropbox", "Apps", "Workflowy", "Data"),
+	}, env.BackupDirs())
+}
+
+func TestEnvironmentOverrides(t *testing.T) {
+	list := "/backups/a" + string(os.PathListSeparator) + "/backups/b"
+	env := testEnv("windows", "/users/u", map[string]string{
+		"APPDATA":    "/users/u/AppData/Roaming",
+		ConfigDirEnv: "/etc/workflowy",
+		CacheDirEnv:  "/var/cache/workflowy",
+		BackupDirEnv: list,
+	})
+
+	assert.Equal(t, "/etc/workflowy", env.ConfigDir())
+	assert.Equal(t, "/var/cache/workflowy", env.CacheDir())
+	assert.Equal(t, []string{"/backups/a", "/backups/b"}, env.BackupDirs())
+}
+
+func TestConfiguredLocations(t *testing.T) {
+	env := testEnv("linux", "/home/u", nil)
+	env.Configured = Configured{CacheDir: "~/.cache/workflowy", BackupDirs: []string{"/backups/a", "~/b"}}
+	assert.Equal(t, filepath.Join("/home/u", ".cache", "workflowy"), env.CacheDir())
+	assert.Equal(t, []string{"/backups/a", filepath.Join("/home/u", "b")}, env.BackupDirs())
+
+	// environment variables take precedence
+	env.Getenv = func(key string) string {
+		return map[string]string{CacheDirEnv: "/var/cache/workflowy", BackupDirEnv: "/backups/c"}[key]
+	}
+	assert.Equal(t, "/var/cache/workflowy", env.CacheDir())
+	assert.Equal(t, []string{"/backups/c"}, env.BackupDirs())
+}
+
+func TestStorage(t *testing.T) {
+	env := testEnv("linux", "/home/u", nil)
+	assert.Equal(t, env.CacheDir(), env.Storage(), "state files are in the cache directory by default")
+
+	env.Configured = Configured{Storage: "~/state"}
+	assert.Equal(t, "~/state", env.Storage())
+
+	env.Getenv = func(key string) string {
+		return map[string]string{StorageEnv: "/data/workflowy"}[key]
+	}
+	assert.Equal(t, "/data/workflowy", env.Storage())
+}
+
+func TestConfigFile_UsesEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(ConfigDirEnv, dir)
+	t.Setenv(CacheDirEnv, filepath.Join(dir, "cache"))
+
+	keyFile, err := APIKeyFile()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, APIKeyFileName), keyFile)
+
+	cacheFile, err := CacheFile("export-cache.json")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "cache", "export-cache.json"), cacheFile)
+}
@@ -0,0 +1,148 @@
+package picker
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Candidate is an entry that can be picked.
+type Candidate struct {
+	ID      string
+	Text    string // shown and matched
+	Context string // shown after the text, dimmed, and matched too, such as the path of a node
+}
+
+// Match is a candidate that matches a query, with the runes of its text and
+// context that the query matched.
+type Match struct {
+	Candidate
+	Score     int
+	Positions []int // indexes of the matched runes, in Text followed by a space and Context
+}
+
+// Scores of a matched rune: each one scores, more when it follows another
+// matched rune or starts a word; each rune skipped in between costs.
+const (
+	scoreMatch       = 16
+	bonusConsecutive = 8
+	bonusWordStart   = 8
+	bonusText        = 4 // matched in the text rather than the context
+	penaltyGap       = 1
+)
+
+// Filter returns the candidates matching query, best first; among those
+// scoring the same, shorter texts come first, then candidates keep their
+// order. Each word of the query must match, as a subsequence of the text and
+// context. The match ignores case unless the query has an upper-case letter.
+// An empty query matches every candidate, in order.
+func Filter(candidates []Candidate, query string) []Match {
+	terms := strings.Fields(query)
+	matches := make([]Match, 0, len(candidates))
+	for _, candidate := range candidates {
+		if match, ok := matchCandidate(candidate, terms); ok {
+			matches = append(matches, match)
+		}
+	}
+	if len(terms) == 0 {
+		return matches
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return utf8.RuneCountInString(matches[i].Text) < utf8.RuneCountInString(matches[j].Text)
+	})
+	return matches
+}
+
+func matchCandidate(candidate Candidate, terms []string) (Match, bool) {
+	match := Match{Candidate: candidate}
+	haystack := []rune(candidate.Text)
+	textLength := len(haystack)
+	if candidate.Context != "" {
+		haystack = append(append(haystack, ' '), []rune(candidate.Context)...)
+	}
+	for _, term := range terms {
+		positions, ok := matchTerm(haystack, []rune(term), hasUpper(term))
+		if !ok {
+			return Match{}, false
+		}
+		match.Score += score(haystack, positions, textLength)
+		match.Positions = append(match.Positions, positions...)
+	}
+	sort.Ints(match.Positions)
+	return match, true
+}
+
+// matchTerm finds term as a subsequence of haystack: the first occurrence,
+// then shortened from its end so that the matched runes are as close as
+// possible, as fzf does.
+func matchTerm(haystack, term []rune, caseSensitive bool) ([]int, bool) {
+	if len(term) == 0 {
+		return nil, true
+	}
+	equal := func(a, b rune) bool {
+		if caseSensitive {
+			return a == b
+		}
+		return unicode.ToLower(a) == unicode.ToLower(b)
+	}
+	t, end := 0, -1
+	for i, r := range haystack {
+		if equal(r, term[t]) {
+			t++
+			if t == len(term) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return nil, false
+	}
+	positions := make([]int, len(term))
+	t = len(term) - 1
+	for i := end; i >= 0 && t >= 0; i-- {
+		if equal(haystack[i], term[t]) {
+			positions[t] = i
+			t--
+		}
+	}
+	return positions, true
+}
+
+func score(haystack []rune, positions []int, textLength int) int {
+	total := 0
+	for i, position := range positions {
+		total += scoreMatch
+		if position < textLength {
+			total += bonusText
+		}
+		if i > 0 {
+			if gap := position - positions[i-1] - 1; gap == 0 {
+				total += bonusConsecutive
+			} else {
+				total -= gap * penaltyGap
+			}
+		}
+		if position == 0 || !isWordRune(haystack[position-1]) {
+			total += bonusWordStart
+		}
+	}
+	return total
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func hasUpper(s string) bool {
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+// Package picker is a fuzzy finder for the terminal, in the style of fzf: the
+// candidates narrow down as a query is typed, and one is picked with the
+// arrow keys and enter.
+//
+//	candidate, err := picker.Run(tty, candidates, "")
+//	if errors.Is(err, picker.ErrCancelled) { ... }
+package picker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/mholzen/workflowy/pkg/terminal"
+)
+
+// ErrCancelled is returned when the picker is left without picking, with
+// escape or ctrl-c.
+var ErrCancelled = errors.New("cancelled")
+
+// headerLines are the lines above the matches: the query and the counts.
+const headerLines = 2
+
+// Picker holds the query, the matching candidates and the one selected.
+type Picker struct {
+	candidates []Candidate
+	query      []rune
+	matches    []Match
+	cursor     int // index of the selected match
+	offset     int // index of the first match shown
+}
+
+// New returns a picker of candidates, with query already typed.
+func New(candidates []Candidate, query string) *Picker {
+	p := &Picker{candidates: candidates, query: []rune(query)}
+	p.filter()
+	return p
+}
+
+// Query returns the query typed.
+func (p *Picker) Query() string {
+	return string(p.query)
+}
+
+// Matches returns the candidates matching the query, best first.
+func (p *Picker) Matches() []Match {
+	return p.matches
+}
+
+// Selected returns the match under the cursor, if any matches.
+func (p *Picker) Selected() (Match, bool) {
+	if len(p.matches) == 0 {
+		return Match{}, false
+	}
+	return p.matches[p.cursor], true
+}
+
+func (p *Picker) filter() {
+	p.matches = Filter(p.candidates, string(p.query))
+	p.cursor, p.offset = 0, 0
+}
+
+// Handle applies event, and reports whether a match was picked or the picker
+// was cancelled.
+func (p *Picker) Handle(event terminal.Event, size terminal.Size) (picked, cancelled bool) {
+	page := max(size.Height-headerLines, 1)
+	switch event.Key {
+	case terminal.KeyRune:
+		p.query = append(p.query, event.Rune)
+		p.filter()
+	case terminal.KeyBackspace:
+		if len(p.query) > 0 {
+			p.query = p.query[:len(p.query)-1]
+			p.filter()
+		}
+	case terminal.KeyClear:
+		p.query = nil
+		p.filter()
+	case terminal.KeyUp:
+		p.move(-1, page)
+	case terminal.KeyDown, terminal.KeyTab:
+		p.move(1, page)
+	case terminal.KeyPageUp:
+		p.move(-page, page)
+	case terminal.KeyPageDown:
+		p.move(page, page)
+	case terminal.KeyEnter:
+		return len(p.matches) > 0, false
+	case terminal.KeyCancel:
+		return false, true
+	}
+	return false, false
+}
+
+func (p *Picker) move(delta, page int) {
+	if len(p.matches) == 0 {
+		return
+	}
+	p.cursor = min(max(p.cursor+delta, 0), len(p.matches)-1)
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+page {
+		p.offset = p.cursor - page + 1
+	}
+}
+
+// Render draws the picker on a terminal of size: the query on the first
+// line, the number of matches on the second, then as many matches as fit,
+// and leaves the cursor after the query.
+func (p *Picker) Render(w io.Writer, size terminal.Size) error {
+	out := bufio.NewWriter(w)
+	out.WriteString("\x1b[H\x1b[2J")
+	out.WriteString("> " + truncate(string(p.query), size.Width-2))
+	fmt.Fprintf(out, "\r\n\x1b[2m  %d/%d\x1b[22m", len(p.matches), len(p.candidates))
+	last := min(p.offset+max(size.Height-headerLines, 0), len(p.matches))
+	for i := p.offset; i < last; i++ {
+		out.WriteString("\r\n")
+		writeMatch(out, p.matches[i], i == p.cursor, size.Width)
+	}
+	fmt.Fprintf(out, "\x1b[1;%dH", min(utf8.RuneCountInString(string(p.query))+3, max(size.Width, 1)))
+	return out.Flush()
+}
+
+// writeMatch writes a line of a match within width: a marker when selected,
+// the text with the matched runes highlighted, and the context dimmed.
+func writeMatch(out *bufio.Writer, match Match, selected bool, width int) {
+	if selected {
+		out.WriteString("\x1b[1m> ")
+	} else {
+		out.WriteString("  ")
+	}
+	runes := []rune(match.Text)
+	textLength := len(runes)
+	if match.Context != "" {
+		runes = append(append(runes, ' ', ' '), []rune(match.Context)...)
+	}
+	for i, r := range runes {
+		if i >= width-2 {
+			break
+		}
+		if i == textLength {
+			out.WriteString("\x1b[2m")
+		}
+		position := i
+		if i > textLength {
+			position = i - 1 // the context is matched after a single space
+		}
+		highlighted := i != textLength && slices.Contains(match.Positions, position)
+		if highlighted {
+			out.WriteString("\x1b[32m")
+		}
+		out.WriteRune(r)
+		if highlighted {
+			out.WriteString("\x1b[39m")
+		}
+	}
+	out.WriteString("\x1b[0m")
+}
+
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if width < 0 {
+		width = 0
+	}
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[len(runes)-width:])
+}
+
+// Pick runs a picker reading keys from in and drawing on out until a
+// candidate is picked, which it returns, or it is cancelled (ErrCancelled).
+func Pick(in io.Reader, out io.Writer, size terminal.Size, candidates []Candidate, query string) (Candidate, error) {
+	p := New(candidates, query)
+	buf := make([]byte, 256)
+	for {
+		if err := p.Render(out, size); err != nil {
+			return Candidate{}, err
+		}
+		n, err := in.Read(buf)
+		for _, event := range terminal.Decode(buf[:n]) {
+			picked, cancelled := p.Handle(event, size)
+			if cancelled {
+				return Candidate{}, ErrCancelled
+			}
+			if picked {
+				match, _ := p.Selected()
+				return match.Candidate, nil
+			}
+		}
+		if err == io.EOF {
+			return Candidate{}, ErrCancelled
+		}
+		if err != nil {
+			return Candidate{}, err
+		}
+	}
+}
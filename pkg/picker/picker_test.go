@@ -0,0 +1,96 @@
+package picker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var candidates = []Candidate{
+	{ID: "1", Text: "Weekly review", Context: "Projects"},
+	{ID: "2", Text: "Inbox"},
+	{ID: "3", Text: "Review notes", Context: "Projects > Weekly"},
+	{ID: "4", Text: "Write blog post", Context: "Ideas"},
+}
+
+func ids(matches []Match) []string {
+	var result []string
+	for _, match := range matches {
+		result = append(result, match.ID)
+	}
+	return result
+}
+
+func TestFilter(t *testing.T) {
+	assert.Equal(t, []string{"1", "2", "3", "4"}, ids(Filter(candidates, "")), "an empty query keeps every candidate, in order")
+	assert.Equal(t, []string{"3", "1"}, ids(Filter(candidates, "review")), "the shorter text first when scores are equal")
+	assert.Equal(t, []string{"4", "1", "3"}, ids(Filter(candidates, "wr")), "consecutive letters starting a word first, letters far apart last")
+	assert.Equal(t, []string{"3"}, ids(Filter(candidates, "notes weekly")), "every word matches, in the text or the context")
+	assert.Empty(t, Filter(candidates, "REVIEW"), "an upper-case letter makes the match case-sensitive")
+	assert.Equal(t, []string{"3"}, ids(Filter(candidates, "Review")))
+
+	match := Filter(candidates, "inb")[0]
+	assert.Equal(t, []int{0, 1, 2}, match.Positions)
+	match = Filter(candidates, "projects")[0]
+	assert.Equal(t, "3", match.ID)
+	assert.Equal(t, 13, match.Positions[0], "positions in the context follow the text and a space")
+}
+
+func TestMatchTerm_Shortest(t *testing.T) {
+	positions, ok := matchTerm([]rune("axxab"), []rune("ab"), false)
+	require.True(t, ok)
+	assert.Equal(t, []int{3, 4}, positions)
+}
+
+func TestHandle(t *testing.T) {
+	size := terminal.Size{Width: 40, Height: 4}
+	p := New(candidates, "")
+	p.Handle(terminal.Event{Key: terminal.KeyDown}, size)
+	p.Handle(terminal.Event{Key: terminal.KeyDown}, size)
+	p.Handle(terminal.Event{Key: terminal.KeyDown}, size)
+	selected, _ := p.Selected()
+	assert.Equal(t, "4", selected.ID)
+	assert.Equal(t, 2, p.offset, "the list scrolls to keep the selection in sight")
+	p.Handle(terminal.Event{Key: terminal.KeyDown}, size)
+	selected, _ = p.Selected()
+	assert.Equal(t, "4", selected.ID, "the cursor stops at the last match")
+
+	p.Handle(terminal.Event{Key: terminal.KeyRune, Rune: 'z'}, size)
+	assert.Empty(t, p.Matches())
+	picked, cancelled := p.Handle(terminal.Event{Key: terminal.KeyEnter}, size)
+	assert.False(t, picked, "nothing to pick")
+	assert.False(t, cancelled)
+	p.Handle(terminal.Event{Key: terminal.KeyBackspace}, size)
+	assert.Len(t, p.Matches(), 4)
+	_, cancelled = p.Handle(terminal.Event{Key: terminal.KeyCancel}, size)
+	assert.True(t, cancelled)
+}
+
+func TestRender(t *testing.T) {
+	var out bytes.Buffer
+	p := New(candidates, "inb")
+	require.NoError(t, p.Render(&out, terminal.Size{Width: 40, Height: 10}))
+	assert.Contains(t, out.String(), "> inb")
+	assert.Contains(t, out.String(), "1/4")
+	assert.Contains(t, out.String(), "\x1b[1m> \x1b[32mI\x1b[39m\x1b[32mn\x1b[39m\x1b[32mb\x1b[39mox\x1b[0m")
+}
+
+func TestPick(t *testing.T) {
+	size := terminal.Size{Width: 40, Height: 10}
+	candidate, err := Pick(strings.NewReader("note\r"), &bytes.Buffer{}, size, candidates, "")
+	require.NoError(t, err)
+	assert.Equal(t, "3", candidate.ID)
+
+	candidate, err = Pick(strings.NewReader("\x1b[B\r"), &bytes.Buffer{}, size, candidates, "review")
+	require.NoError(t, err)
+	assert.Equal(t, "1", candidate.ID)
+
+	_, err = Pick(strings.NewReader("\x03"), &bytes.Buffer{}, size, candidates, "")
+	assert.ErrorIs(t, err, ErrCancelled)
+	_, err = Pick(strings.NewReader("inb"), &bytes.Buffer{}, size, candidates, "")
+	assert.ErrorIs(t, err, ErrCancelled, "the end of the input cancels")
+}
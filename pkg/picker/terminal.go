@@ -0,0 +1,24 @@
+package picker
+
+import (
+	"os"
+
+	"github.com/mholzen/workflowy/pkg/terminal"
+)
+
+// Run picks a candidate on the terminal tty, such as /dev/tty, so that the
+// standard output stays free for the result. The picker takes the whole
+// screen, in the alternate screen buffer, and the terminal is restored when
+// it returns. Run is not available on Windows.
+func Run(tty *os.File, candidates []Candidate, query string) (Candidate, error) {
+	size, err := terminal.GetSize(tty)
+	if err != nil {
+		return Candidate{}, err
+	}
+	restore, err := terminal.FullScreen(tty)
+	if err != nil {
+		return Candidate{}, err
+	}
+	defer restore()
+	return Pick(tty, tty, size, candidates, query)
+}
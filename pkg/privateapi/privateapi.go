@@ -0,0 +1,173 @@
+// Package privateapi reads the outline through Workflowy's private web API,
+// authenticated with the session cookie of a logged-in browser.
+//
+// The private API is what the Workflowy app uses. It returns data the public
+// API does not, such as mirrors, but it is undocumented and may change without
+// notice, and a session cookie grants full access to the account. It is only
+// used when explicitly requested (--method=session).
+package privateapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/paths"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+const (
+	// DefaultBaseURL is the address of the Workflowy web app
+	DefaultBaseURL = "https://workflowy.com"
+	// initializationPath returns the whole outline of the logged-in user
+	initializationPath = "/get_initialization_data?client_version=21"
+	// sessionCookieName is the cookie the web app authenticates with
+	sessionCookieName = "sessionid"
+)
+
+// Warning is logged each time the private API is used.
+const Warning = "reading through the undocumented private API with a session cookie: it may break without notice, and the cookie grants full access to your account"
+
+// LoadSessionCookie returns the session cookie from $WORKFLOWY_SESSION_COOKIE,
+// or from session.cookie in the configuration directory. It warns when the
+// file can be read by other users.
+func LoadSessionCookie() (string, error) {
+	if cookie := strings.TrimSpace(os.Getenv(paths.SessionCookieEnv)); cookie != "" {
+		return cookie, nil
+	}
+
+	path, err := paths.ConfigFile(paths.SessionCookieFileName)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("no session cookie: set %s or write the value of the %s cookie of a logged-in browser to %s", paths.SessionCookieEnv, sessionCookieName, path)
+		}
+		return "", fmt.Errorf("cannot read session cookie: %w", err)
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		slog.Warn("session cookie file is readable by other users; restrict it with chmod 600", "file", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read session cookie: %w", err)
+	}
+	cookie := strings.TrimSpace(string(data))
+	if cookie == "" {
+		return "", fmt.Errorf("session cookie file %s is empty", path)
+	}
+	return cookie, nil
+}
+
+// Client reads the outline through the private API.
+type Client struct {
+	baseURL    string
+	cookie     string
+	httpClient *http.Client
+}
+
+// NewClient returns a client authenticated with cookie, either the value of
+// the sessionid cookie or a full "name=value" cookie header.
+func NewClient(cookie string) *Client {
+	return &Client{
+		baseURL:    DefaultBaseURL,
+		cookie:     cookie,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// WithBaseURL returns a copy of the client sending requests to baseURL.
+func (c *Client) WithBaseURL(baseURL string) *Client {
+	copied := *c
+	copied.baseURL = strings.TrimSuffix(baseURL, "/")
+	return &copied
+}
+
+// initializationData is the part of the initialization response holding the outline.
+type initializationData struct {
+	ProjectTreeData struct {
+		MainProjectTreeInfo struct {
+			RootProjectChildren          []workflowy.BackupNode `json:"rootProjectChildren"`
+			DateJoinedTimestampInSeconds int64                  `json:"dateJoinedTimestampInSeconds"`
+		} `json:"mainProjectTreeInfo"`
+	} `json:"projectTreeData"`
+}
+
+// Tree returns the top-level items of the outline, with the mirror metadata
+// found in backups.
+func (c *Client) Tree(ctx context.Context) ([]*workflowy.Item, error) {
+	slog.Warn(Warning)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+initializationPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	cookie := c.cookie
+	if !strings.Contains(cookie, "=") {
+		cookie = sessionCookieName + "=" + cookie
+	}
+	req.Header.Set("Cookie", cookie)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach the private API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("session cookie rejected (status %d): log in to Workflowy again and update the cookie", resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("private API returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read private API response: %w", err)
+	}
+	var data initializationData
+	if err := json.Unmarshal(body, &data); err != nil {
+		// an expired session is answered with the login page
+		return nil, fmt.Errorf("cannot parse private API response (the session may have expired): %w", err)
+	}
+
+	info := data.ProjectTreeData.MainProjectTreeInfo
+	if info.RootProjectChildren == nil {
+		return nil, fmt.Errorf("private API response has no outline (the session may have expired or the format changed)")
+	}
+	items := make([]*workflowy.Item, len(info.RootProjectChildren))
+	for i, node := range info.RootProjectChildren {
+		items[i] = workflowy.BackupNodeToItem(absoluteTimes(node, info.DateJoinedTimestampInSeconds))
+	}
+	return items, nil
+}
+
+// absoluteTimes converts the times of node and its descendants, which the
+// private API gives in seconds since the user joined, to Unix times.
+func absoluteTimes(node workflowy.BackupNode, joined int64) workflowy.BackupNode {
+	if node.CreatedAt != 0 {
+		node.CreatedAt += joined
+	}
+	if node.ModifiedAt != 0 {
+		node.ModifiedAt += joined
+	}
+	if node.Completed != nil {
+		completed := *node.Completed + joined
+		node.Completed = &completed
+	}
+	children := make([]workflowy.BackupNode, len(node.Children))
+	for i, child := range node.Children {
+		children[i] = absoluteTimes(child, joined)
+	}
+	node.Children = children
+	return node
+}
@@ -0,0 +1,65 @@
+package privateapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/paths"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTree(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/get_initialization_data", r.URL.Path)
+		if r.Header.Get("Cookie") != "sessionid=secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"projectTreeData": {"mainProjectTreeInfo": {
+			"dateJoinedTimestampInSeconds": 1600000000,
+			"rootProjectChildren": [
+				{"id": "a", "nm": "Projects", "ct": 10, "lm": 100, "ch": [
+					{"id": "b", "nm": "", "lm": 200, "cp": 300, "metadata": {"mirror": {"originalId": "c"}}}
+				]}
+			]}}}`))
+	}))
+	defer server.Close()
+
+	items, err := NewClient("secret").WithBaseURL(server.URL).Tree(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "Projects", items[0].Name)
+	assert.Equal(t, int64(1600000010), items[0].CreatedAt)
+	assert.Equal(t, int64(1600000100), items[0].ModifiedAt)
+	mirrorCopy := items[0].Children[0]
+	require.NotNil(t, mirrorCopy.CompletedAt)
+	assert.Equal(t, int64(1600000300), *mirrorCopy.CompletedAt)
+	assert.Equal(t, map[string]interface{}{"originalId": "c"}, mirrorCopy.Data["mirror"])
+
+	_, err = NewClient("expired").WithBaseURL(server.URL).Tree(context.Background())
+	assert.ErrorContains(t, err, "session cookie rejected")
+}
+
+func TestLoadSessionCookie(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(paths.ConfigDirEnv, dir)
+	t.Setenv(paths.SessionCookieEnv, "")
+
+	_, err := LoadSessionCookie()
+	assert.ErrorContains(t, err, "no session cookie")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, paths.SessionCookieFileName), []byte("from-file\n"), 0600))
+	cookie, err := LoadSessionCookie()
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", cookie)
+
+	t.Setenv(paths.SessionCookieEnv, "from-env")
+	cookie, err = LoadSessionCookie()
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", cookie)
+}
@@ -0,0 +1,287 @@
+// Package recipes reads workflow recipes: named, parameterized sequences of
+// workflowy commands, built in or defined in recipes.yaml in the
+// configuration directory.
+package recipes
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/mholzen/workflowy/pkg/paths"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is the name of the recipes file in the configuration directory
+const DefaultFile = "recipes.yaml"
+
+//go:embed builtin.yaml
+var builtinRecipes []byte
+
+// Recipe is a sequence of workflowy commands run one after the other. The
+// file maps each name to its recipe:
+//
+//	archive-project:
+//	  description: Complete a project and move it to the archive
+//	  vars:
+//	    - name: project
+//	      required: true
+//	    - name: archive
+//	      default: archive
+//	  steps:
+//	    - name: complete
+//	      run: [complete, "{{.project}}", --cascade]
+//	    - run: [move, "{{.project}}", "{{.archive}}"]
+//
+// Each argument of a step is a Go template over the variables, so a value
+// holding spaces stays a single argument.
+type Recipe struct {
+	Name        string `yaml:"-" json:"name"`
+	Description string `yaml:"description" json:"description,omitempty"`
+	Vars        []Var  `yaml:"vars" json:"vars,omitempty"`
+	Steps       []Step `yaml:"steps" json:"steps"`
+	Builtin     bool   `yaml:"-" json:"builtin"`
+}
+
+// Var is a variable of a recipe, set with --var name=value.
+type Var struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description,omitempty"`
+	Default     string `yaml:"default" json:"default,omitempty"`
+	Required    bool   `yaml:"required" json:"required,omitempty"`
+}
+
+// Step is a workflowy command of a recipe, without the leading "workflowy".
+type Step struct {
+	Name            string   `yaml:"name" json:"name,omitempty"`
+	Run             []string `yaml:"run" json:"run"`
+	ContinueOnError bool     `yaml:"continue_on_error" json:"continue_on_error,omitempty"` // run the next steps even if this one fails
+}
+
+// Command is a step with its arguments filled in.
+type Command struct {
+	Step
+	Args []string `json:"args"`
+}
+
+// Label returns the name of the step, or its command if it has none.
+func (c Command) Label() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return strings.Join(c.Args, " ")
+}
+
+// Label returns the name of the step, or its command if it has none.
+func (s Step) Label() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return strings.Join(s.Run, " ")
+}
+
+var varNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Parse reads recipes from YAML data, sorted by name. source names the data
+// in errors.
+func Parse(data []byte, source string) ([]Recipe, error) {
+	var byName map[string]Recipe
+	if err := yaml.Unmarshal(data, &byName); err != nil {
+		return nil, fmt.Errorf("cannot parse recipes %s: %w", source, err)
+	}
+	recipes := make([]Recipe, 0, len(byName))
+	for name, recipe := range byName {
+		recipe.Name = name
+		if err := recipe.Validate(); err != nil {
+			return nil, fmt.Errorf("recipe %q in %s: %w", name, source, err)
+		}
+		recipes = append(recipes, recipe)
+	}
+	sort.Slice(recipes, func(i, j int) bool { return recipes[i].Name < recipes[j].Name })
+	return recipes, nil
+}
+
+// Load reads the recipes at path. A missing file has no recipes.
+func Load(path string) ([]Recipe, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot read recipes: %w", err)
+	}
+	return Parse(data, path)
+}
+
+// Builtin returns the recipes shipped with workflowy.
+func Builtin() []Recipe {
+	recipes, err := Parse(builtinRecipes, "builtin.yaml")
+	if err != nil {
+		panic(err)
+	}
+	for i := range recipes {
+		recipes[i].Builtin = true
+	}
+	return recipes
+}
+
+// LoadDefault returns the built-in recipes and those of the configuration
+// directory (~/.workflowy/recipes.yaml), which replace built-in recipes of
+// the same name.
+func LoadDefault() ([]Recipe, error) {
+	path, err := paths.ConfigFile(DefaultFile)
+	if err != nil {
+		return nil, err
+	}
+	defined, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return Merge(Builtin(), defined), nil
+}
+
+// Merge returns the recipes of base and overrides, sorted by name, with those
+// of overrides replacing those of base of the same name.
+func Merge(base, overrides []Recipe) []Recipe {
+	byName := make(map[string]Recipe, len(base)+len(overrides))
+	for _, recipe := range base {
+		byName[recipe.Name] = recipe
+	}
+	for _, recipe := range overrides {
+		byName[recipe.Name] = recipe
+	}
+	merged := make([]Recipe, 0, len(byName))
+	for _, recipe := range byName {
+		merged = append(merged, recipe)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name < merged[j].Name })
+	return merged
+}
+
+// Find returns the recipe called name.
+func Find(recipes []Recipe, name string) (Recipe, error) {
+	names := make([]string, len(recipes))
+	for i, recipe := range recipes {
+		if recipe.Name == name {
+			return recipe, nil
+		}
+		names[i] = recipe.Name
+	}
+	return Recipe{}, fmt.Errorf("unknown recipe %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// Validate checks that the recipe has steps, that its variables are named and
+// that the arguments of its steps are valid templates.
+func (r Recipe) Validate() error {
+	if len(r.Steps) == 0 {
+		return fmt.Errorf("recipe has no steps")
+	}
+	seen := make(map[string]bool, len(r.Vars))
+	for _, v := range r.Vars {
+		if !varNamePattern.MatchString(v.Name) {
+			return fmt.Errorf("invalid variable name %q: use letters, digits and underscores", v.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("variable %q is declared twice", v.Name)
+		}
+		seen[v.Name] = true
+	}
+	for i, step := range r.Steps {
+		if len(step.Run) == 0 {
+			return fmt.Errorf("step %d has no command", i+1)
+		}
+		if step.Run[0] == "recipes" {
+			return fmt.Errorf("step %d: recipes cannot run other recipes", i+1)
+		}
+		for _, arg := range step.Run {
+			if _, err := parseArg(arg); err != nil {
+				return fmt.Errorf("step %d: %w", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+func parseArg(arg string) (*template.Template, error) {
+	tmpl, err := template.New("arg").Option("missingkey=error").Parse(arg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid argument %q: %w", arg, err)
+	}
+	return tmpl, nil
+}
+
+// ParseVars reads name=value assignments, as given to --var.
+func ParseVars(assignments []string) (map[string]string, error) {
+	values := make(map[string]string, len(assignments))
+	for _, assignment := range assignments {
+		name, value, ok := strings.Cut(assignment, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid variable %q: expected name=value", assignment)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// Resolve returns the values of the variables of the recipe: those given,
+// then the defaults. It fails if a required variable is missing or a value
+// is given for a variable the recipe does not declare.
+func (r Recipe) Resolve(given map[string]string) (map[string]string, error) {
+	declared := make(map[string]bool, len(r.Vars))
+	values := make(map[string]string, len(r.Vars))
+	var missing []string
+	for _, v := range r.Vars {
+		declared[v.Name] = true
+		value, ok := given[v.Name]
+		if !ok {
+			value = v.Default
+		}
+		if value == "" && v.Required {
+			missing = append(missing, v.Name)
+		}
+		values[v.Name] = value
+	}
+	for name := range given {
+		if !declared[name] {
+			return nil, fmt.Errorf("recipe %s has no variable %q", r.Name, name)
+		}
+	}
+	if len(missing) > 0 {
+		flags := make([]string, len(missing))
+		for i, name := range missing {
+			flags[i] = "--var " + name + "=..."
+		}
+		return nil, fmt.Errorf("recipe %s requires %s", r.Name, strings.Join(flags, " "))
+	}
+	return values, nil
+}
+
+// Commands returns the steps of the recipe with their arguments filled in from
+// the values given, see Resolve.
+func (r Recipe) Commands(given map[string]string) ([]Command, error) {
+	values, err := r.Resolve(given)
+	if err != nil {
+		return nil, err
+	}
+	commands := make([]Command, len(r.Steps))
+	for i, step := range r.Steps {
+		args := make([]string, len(step.Run))
+		for j, arg := range step.Run {
+			tmpl, err := parseArg(arg)
+			if err != nil {
+				return nil, err
+			}
+			var sb strings.Builder
+			if err := tmpl.Execute(&sb, values); err != nil {
+				return nil, fmt.Errorf("step %d: %w", i+1, err)
+			}
+			args[j] = sb.String()
+		}
+		commands[i] = Command{Step: step, Args: args}
+	}
+	return commands, nil
+}
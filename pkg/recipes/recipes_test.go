@@ -0,0 +1,107 @@
+package recipes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const archiveRecipe = `
+archive:
+  description: Archive a project
+  vars:
+    - name: project
+      required: true
+    - name: archive
+      default: archive-id
+  steps:
+    - name: complete
+      run: [complete, "{{.project}}", --cascade]
+    - run: [move, "{{.project}}", "{{.archive}}"]
+`
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	require.NoError(t, os.WriteFile(path, []byte(archiveRecipe), 0644))
+
+	recipes, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, recipes, 1)
+	assert.Equal(t, "archive", recipes[0].Name)
+	assert.False(t, recipes[0].Builtin)
+	assert.Equal(t, "complete", recipes[0].Steps[0].Label())
+	assert.Equal(t, "move {{.project}} {{.archive}}", recipes[0].Steps[1].Label())
+
+	recipes, err = Load(filepath.Join(t.TempDir(), DefaultFile))
+	require.NoError(t, err)
+	assert.Empty(t, recipes)
+}
+
+func TestCommands(t *testing.T) {
+	recipes, err := Parse([]byte(archiveRecipe), "test")
+	require.NoError(t, err)
+	recipe := recipes[0]
+
+	commands, err := recipe.Commands(map[string]string{"project": "Q3 plan"})
+	require.NoError(t, err)
+	require.Len(t, commands, 2)
+	assert.Equal(t, []string{"complete", "Q3 plan", "--cascade"}, commands[0].Args)
+	assert.Equal(t, []string{"move", "Q3 plan", "archive-id"}, commands[1].Args)
+
+	commands, err = recipe.Commands(map[string]string{"project": "p", "archive": "a"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"move", "p", "a"}, commands[1].Args)
+
+	_, err = recipe.Commands(nil)
+	assert.EqualError(t, err, "recipe archive requires --var project=...")
+	_, err = recipe.Commands(map[string]string{"project": "p", "typo": "x"})
+	assert.EqualError(t, err, `recipe archive has no variable "typo"`)
+}
+
+func TestParseInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"no steps":      "r:\n  description: nothing\n",
+		"empty command": "r:\n  steps:\n    - run: []\n",
+		"recursion":     "r:\n  steps:\n    - run: [recipes, run, r]\n",
+		"bad template":  "r:\n  steps:\n    - run: [get, \"{{.id\"]\n",
+		"bad var name":  "r:\n  vars:\n    - name: my-var\n  steps:\n    - run: [get]\n",
+	} {
+		_, err := Parse([]byte(content), "test")
+		assert.Error(t, err, name)
+	}
+}
+
+func TestParseVars(t *testing.T) {
+	values, err := ParseVars([]string{"project=abc", "query=a=b", "empty="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"project": "abc", "query": "a=b", "empty": ""}, values)
+
+	_, err = ParseVars([]string{"project"})
+	assert.Error(t, err)
+}
+
+func TestBuiltin(t *testing.T) {
+	builtin := Builtin()
+	require.NotEmpty(t, builtin)
+	for _, recipe := range builtin {
+		assert.True(t, recipe.Builtin)
+	}
+	recipe, err := Find(builtin, "archive-completed-project")
+	require.NoError(t, err)
+	commands, err := recipe.Commands(map[string]string{"project": "p", "archive": "a"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"move", "p", "a", "--position=top"}, commands[1].Args)
+
+	override := Recipe{Name: "archive-completed-project", Steps: []Step{{Run: []string{"get"}}}}
+	merged := Merge(builtin, []Recipe{override})
+	assert.Len(t, merged, len(builtin))
+	recipe, err = Find(merged, "archive-completed-project")
+	require.NoError(t, err)
+	assert.False(t, recipe.Builtin)
+
+	_, err = Find(merged, "missing")
+	assert.ErrorContains(t, err, "unknown recipe \"missing\"")
+}
@@ -1,33 +1,56 @@
 // TODO: Add --name and --note flags to replace command to allow replacing in notes
-// (currently only replaces in item.Name)
+// (currently only replaces in item.Name, except when moving text with --move-to)
 package replace
 
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
+	"github.com/mholzen/workflowy/pkg/patch"
 	"github.com/mholzen/workflowy/pkg/workflowy"
 )
 
+const (
+	// MoveToNote moves matched text out of the name and appends it to the note
+	MoveToNote = "note"
+	// MoveToName moves matched text out of the note and appends it to the name
+	MoveToName = "name"
+)
+
 type Result struct {
 	ID         string `json:"id"`
 	OldName    string `json:"old_name"`
 	NewName    string `json:"new_name"`
+	OldNote    string `json:"old_note,omitempty"`
+	NewNote    string `json:"new_note,omitempty"`
+	MovedTo    string `json:"moved_to,omitempty"`
 	URL        string `json:"url"`
 	Applied    bool   `json:"applied"`
 	Skipped    bool   `json:"skipped,omitempty"`
+	Failed     bool   `json:"failed,omitempty"`
 	SkipReason string `json:"skip_reason,omitempty"`
+	Breadcrumb string `json:"breadcrumb,omitempty"`
 }
 
 func (r Result) String() string {
+	var s string
 	if r.Skipped {
-		return fmt.Sprintf("%s: \"%s\" (skipped: %s)", r.ID, r.OldName, r.SkipReason)
+		s = fmt.Sprintf("%s: \"%s\" (skipped: %s)", r.ID, r.OldName, r.SkipReason)
+	} else {
+		status := "→"
+		if !r.Applied {
+			status = "→ (dry-run)"
+		}
+		s = fmt.Sprintf("%s: \"%s\" %s \"%s\"", r.ID, r.OldName, status, r.NewName)
+		if r.MovedTo != "" {
+			s += fmt.Sprintf(" (note: \"%s\" → \"%s\")", r.OldNote, r.NewNote)
+		}
 	}
-	status := "→"
-	if !r.Applied {
-		status = "→ (dry-run)"
+	if r.Breadcrumb != "" {
+		s += fmt.Sprintf(" (in %s)", r.Breadcrumb)
 	}
-	return fmt.Sprintf("%s: \"%s\" %s \"%s\"", r.ID, r.OldName, status, r.NewName)
+	return s
 }
 
 type Options struct {
@@ -36,6 +59,15 @@ type Options struct {
 	Interactive bool
 	DryRun      bool
 	Depth       int
+	MoveTo      string // "", MoveToNote or MoveToName
+}
+
+// ValidateMoveTo validates that moveTo is either empty, "note", or "name".
+func ValidateMoveTo(moveTo string) error {
+	if moveTo != "" && moveTo != MoveToNote && moveTo != MoveToName {
+		return fmt.Errorf("move-to must be 'note' or 'name'")
+	}
+	return nil
 }
 
 func CollectReplacements(items []*workflowy.Item, opts Options, currentDepth int, results *[]Result) {
@@ -44,18 +76,8 @@ func CollectReplacements(items []*workflowy.Item, opts Options, currentDepth int
 	}
 
 	for _, item := range items {
-		if opts.Pattern.MatchString(item.Name) {
-			newName := opts.Pattern.ReplaceAllString(item.Name, opts.Replacement)
-			if newName != item.Name {
-				*results = append(*results, Result{
-					ID:      item.ID,
-					OldName: item.Name,
-					NewName: newName,
-					URL:     fmt.Sprintf("https://workflowy.com/#/%s", item.ID),
-					Applied: false,
-					Skipped: false,
-				})
-			}
+		if result, ok := collectReplacement(item, opts); ok {
+			*results = append(*results, result)
 		}
 
 		if len(item.Children) > 0 {
@@ -63,3 +85,98 @@ func CollectReplacements(items []*workflowy.Item, opts Options, currentDepth int
 		}
 	}
 }
+
+func collectReplacement(item *workflowy.Item, opts Options) (Result, bool) {
+	result := Result{
+		ID:      item.ID,
+		OldName: item.Name,
+		URL:     fmt.Sprintf("https://workflowy.com/#/%s", item.ID),
+	}
+
+	note := ""
+	if item.Note != nil {
+		note = *item.Note
+	}
+
+	switch opts.MoveTo {
+	case MoveToNote:
+		matches := opts.Pattern.FindAllString(item.Name, -1)
+		if len(matches) == 0 {
+			return Result{}, false
+		}
+		result.NewName = strings.TrimSpace(opts.Pattern.ReplaceAllString(item.Name, opts.Replacement))
+		result.OldNote = note
+		result.NewNote = appendText(note, strings.Join(matches, " "), "\n")
+		result.MovedTo = MoveToNote
+
+	case MoveToName:
+		matches := opts.Pattern.FindAllString(note, -1)
+		if len(matches) == 0 {
+			return Result{}, false
+		}
+		result.NewName = appendText(item.Name, strings.Join(matches, " "), " ")
+		result.OldNote = note
+		result.NewNote = strings.TrimSpace(opts.Pattern.ReplaceAllString(note, opts.Replacement))
+		result.MovedTo = MoveToName
+
+	default:
+		if !opts.Pattern.MatchString(item.Name) {
+			return Result{}, false
+		}
+		result.NewName = opts.Pattern.ReplaceAllString(item.Name, opts.Replacement)
+		if result.NewName == item.Name {
+			return Result{}, false
+		}
+	}
+
+	return result, true
+}
+
+func appendText(existing, text, separator string) string {
+	if strings.TrimSpace(existing) == "" {
+		return text
+	}
+	return existing + separator + text
+}
+
+// NodeID returns the ID of the node of the result.
+func (r *Result) NodeID() string {
+	return r.ID
+}
+
+// SetBreadcrumb sets the ancestors shown with the result (see workflowy.AddBreadcrumbs).
+func (r *Result) SetBreadcrumb(breadcrumb string) {
+	r.Breadcrumb = breadcrumb
+}
+
+// BuildUpdateRequest returns the update request that applies a replacement result.
+// Moves update both the name and the note.
+func BuildUpdateRequest(result *Result) *workflowy.UpdateNodeRequest {
+	req := &workflowy.UpdateNodeRequest{
+		Name: &result.NewName,
+	}
+	if result.MovedTo != "" {
+		req.Note = &result.NewNote
+	}
+	return req
+}
+
+// Edits returns the field edits made by the result.
+func (r Result) Edits() []patch.Edit {
+	edits := []patch.Edit{{ID: r.ID, Field: patch.FieldName, Old: r.OldName, New: r.NewName}}
+	if r.MovedTo != "" {
+		edits = append(edits, patch.Edit{ID: r.ID, Field: patch.FieldNote, Old: r.OldNote, New: r.NewNote})
+	}
+	return edits
+}
+
+// UndoPatch returns the edits that revert the applied results.
+func UndoPatch(results []Result) []patch.Edit {
+	var edits []patch.Edit
+	for _, result := range results {
+		if result.Applied {
+			edits = append(edits, result.Edits()...)
+		}
+	}
+	return patch.Reverse(edits)
+}
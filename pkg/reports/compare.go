@@ -0,0 +1,54 @@
+package reports
+
+import (
+	"fmt"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// CountComparisonReportOutput compares descendant counts between a snapshot
+// and a previous one
+type CountComparisonReportOutput struct {
+	RootItem         *workflowy.Item
+	Descendants      workflowy.Descendants // counts in the current snapshot
+	Previous         map[string]int        // counts in the previous snapshot, by node ID
+	Threshold        float64
+	Snapshot         workflowy.SnapshotMeta
+	PreviousSnapshot workflowy.SnapshotMeta
+}
+
+// Title returns the report title
+func (c *CountComparisonReportOutput) Title() string {
+	return fmt.Sprintf("Descendant Count Comparison (threshold: %.2f%%) - %s → %s",
+		c.Threshold*100, snapshotTimestamp(c.PreviousSnapshot), snapshotTimestamp(c.Snapshot))
+}
+
+// ToNodes converts the comparison to a tree of Workflowy items
+func (c *CountComparisonReportOutput) ToNodes() (*workflowy.Item, error) {
+	note := "Previous snapshot: " + c.PreviousSnapshot.String() + "\n" + *snapshotNote(c.Snapshot)
+	return &workflowy.Item{
+		Name:     c.Title(),
+		Note:     &note,
+		Children: []*workflowy.Item{c.convertNode(c.Descendants)},
+	}, nil
+}
+
+func (c *CountComparisonReportOutput) convertNode(node workflowy.Descendants) *workflowy.Item {
+	nodeValue := node.NodeValue()
+
+	var name string
+	if previous, ok := c.Previous[(*nodeValue).Item().ID]; ok {
+		name = fmt.Sprintf("%s (%d → %d descendants, %+d)", (*nodeValue).String(), previous, node.Count, node.Count-previous)
+	} else {
+		name = fmt.Sprintf("%s (%d descendants, new)", (*nodeValue).String(), node.Count)
+	}
+
+	item := &workflowy.Item{
+		Name:     name,
+		Children: make([]*workflowy.Item, 0),
+	}
+	for child := range node.Children() {
+		item.Children = append(item.Children, c.convertNode(child.Node()))
+	}
+	return item
+}
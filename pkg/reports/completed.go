@@ -0,0 +1,45 @@
+package reports
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// CompletedReportOutput wraps completion counts per period
+type CompletedReportOutput struct {
+	Completions *workflowy.Completions
+	Snapshot    workflowy.SnapshotMeta // the snapshot the completions were counted in
+}
+
+// Title returns the report title
+func (r *CompletedReportOutput) Title() string {
+	period := r.Completions.Period
+	if period != "" {
+		period = strings.ToUpper(period[:1]) + period[1:]
+	}
+	return fmt.Sprintf("Completed Nodes by %s - %s", period, snapshotTimestamp(r.Snapshot))
+}
+
+// ToNodes converts the completion counts to Workflowy items, oldest period first
+func (r *CompletedReportOutput) ToNodes() (*workflowy.Item, error) {
+	children := make([]*workflowy.Item, 0, len(r.Completions.Buckets)+1)
+
+	for _, bucket := range r.Completions.Buckets {
+		children = append(children, &workflowy.Item{
+			Name: fmt.Sprintf("%s: %d completed (%.1f%%)", bucket.Label, bucket.Count, bucket.Ratio*100),
+		})
+	}
+	if r.Completions.Undated > 0 {
+		children = append(children, &workflowy.Item{
+			Name: fmt.Sprintf("(no date): %d completed", r.Completions.Undated),
+		})
+	}
+
+	return &workflowy.Item{
+		Name:     r.Title(),
+		Note:     snapshotNote(r.Snapshot),
+		Children: children,
+	}, nil
+}
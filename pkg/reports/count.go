@@ -11,18 +11,20 @@ type CountReportOutput struct {
 	RootItem    *workflowy.Item
 	Descendants workflowy.Descendants
 	Threshold   float64
+	Snapshot    workflowy.SnapshotMeta // the snapshot the counts were computed from
 }
 
 // Title returns the report title
 func (c *CountReportOutput) Title() string {
 	return fmt.Sprintf("Descendant Count Report (threshold: %.2f%%) - %s",
-		c.Threshold*100, GenerateTimestamp())
+		c.Threshold*100, snapshotTimestamp(c.Snapshot))
 }
 
 // ToNodes converts the count report to a tree of Workflowy items
 func (c *CountReportOutput) ToNodes() (*workflowy.Item, error) {
 	reportRoot := &workflowy.Item{
 		Name:     c.Title(),
+		Note:     snapshotNote(c.Snapshot),
 		Children: []*workflowy.Item{convertDescendantNode(c.Descendants)},
 	}
 
@@ -0,0 +1,50 @@
+package reports
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// DepthReportOutput wraps the depth and breadth statistics of a tree
+type DepthReportOutput struct {
+	Depth    workflowy.TreeDepth
+	Snapshot workflowy.SnapshotMeta // the snapshot the tree was measured in
+}
+
+// Title returns the report title
+func (r *DepthReportOutput) Title() string {
+	return fmt.Sprintf("Tree Depth and Breadth - %s", snapshotTimestamp(r.Snapshot))
+}
+
+// ToNodes converts the statistics to Workflowy items, with the nodes per level
+// and links to the deepest nodes
+func (r *DepthReportOutput) ToNodes() (*workflowy.Item, error) {
+	levels := &workflowy.Item{Name: "Nodes per level"}
+	for depth, count := range r.Depth.LevelCounts {
+		levels.Children = append(levels.Children, &workflowy.Item{
+			Name: fmt.Sprintf("Level %d: %d", depth, count),
+		})
+	}
+
+	deepest := &workflowy.Item{Name: "Deepest paths"}
+	for i, path := range r.Depth.DeepestPaths {
+		deepest.Children = append(deepest.Children, &workflowy.Item{
+			Name: fmt.Sprintf("%d. [%s](https://workflowy.com/#/%s) (depth %d)",
+				i+1, strings.Join(path.Path, workflowy.BreadcrumbSeparator), path.ID, path.Depth),
+		})
+	}
+
+	return &workflowy.Item{
+		Name: r.Title(),
+		Note: snapshotNote(r.Snapshot),
+		Children: []*workflowy.Item{
+			{Name: fmt.Sprintf("Max depth: %d", r.Depth.MaxDepth)},
+			{Name: fmt.Sprintf("Nodes: %d", r.Depth.NodeCount)},
+			{Name: fmt.Sprintf("Average branching factor: %.2f", r.Depth.BranchingFactor)},
+			levels,
+			deepest,
+		},
+	}, nil
+}
@@ -0,0 +1,43 @@
+package reports
+
+import (
+	"fmt"
+
+	"github.com/mholzen/workflowy/pkg/secrets"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// ExposureReportOutput wraps the nodes holding likely secrets
+type ExposureReportOutput struct {
+	Exposures []secrets.Exposure
+	Snapshot  workflowy.SnapshotMeta // the snapshot the nodes were scanned in
+}
+
+// Title returns the report title
+func (r *ExposureReportOutput) Title() string {
+	return fmt.Sprintf("Nodes with Likely Secrets - %s", snapshotTimestamp(r.Snapshot))
+}
+
+// ToNodes converts the exposures to Workflowy items linking to the nodes, with
+// one child per redacted secret
+func (r *ExposureReportOutput) ToNodes() (*workflowy.Item, error) {
+	children := make([]*workflowy.Item, len(r.Exposures))
+
+	for i, exposure := range r.Exposures {
+		child := &workflowy.Item{
+			Name: fmt.Sprintf("%d. [%s](https://workflowy.com/#/%s) (%d findings)", i+1, exposure.Name, exposure.ID, len(exposure.Findings)),
+		}
+		for _, finding := range exposure.Findings {
+			child.Children = append(child.Children, &workflowy.Item{
+				Name: fmt.Sprintf("%s in %s: %s", finding.Detector, finding.Field, finding.Match),
+			})
+		}
+		children[i] = child
+	}
+
+	return &workflowy.Item{
+		Name:     r.Title(),
+		Note:     snapshotNote(r.Snapshot),
+		Children: children,
+	}, nil
+}
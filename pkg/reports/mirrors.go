@@ -2,6 +2,7 @@ package reports
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/mholzen/workflowy/pkg/mirror"
 	"github.com/mholzen/workflowy/pkg/workflowy"
@@ -9,16 +10,18 @@ import (
 
 // MirrorCountReportOutput wraps mirror count ranking results
 type MirrorCountReportOutput struct {
-	Ranked []*mirror.MirrorInfo
-	TopN   int
+	Ranked   []*mirror.MirrorInfo
+	TopN     int
+	Problems []mirror.ChainProblem  // mirror chains that cycle, are too long or are broken
+	Snapshot workflowy.SnapshotMeta // the snapshot the ranking was computed from
 }
 
 // Title returns the report title
 func (r *MirrorCountReportOutput) Title() string {
 	if r.TopN > 0 {
-		return fmt.Sprintf("Top %d Nodes by Mirror Count - %s", r.TopN, GenerateTimestamp())
+		return fmt.Sprintf("Top %d Nodes by Mirror Count - %s", r.TopN, snapshotTimestamp(r.Snapshot))
 	}
-	return fmt.Sprintf("Nodes by Mirror Count - %s", GenerateTimestamp())
+	return fmt.Sprintf("Nodes by Mirror Count - %s", snapshotTimestamp(r.Snapshot))
 }
 
 // ToNodes converts the ranking to Workflowy items
@@ -40,12 +43,31 @@ func (r *MirrorCountReportOutput) ToNodes() (*workflowy.Item, error) {
 		children[i] = child
 	}
 
+	if len(r.Problems) > 0 {
+		children = append(children, buildChainProblems(r.Problems))
+	}
+
 	return &workflowy.Item{
 		Name:     r.Title(),
+		Note:     snapshotNote(r.Snapshot),
 		Children: children,
 	}, nil
 }
 
+func buildChainProblems(problems []mirror.ChainProblem) *workflowy.Item {
+	node := &workflowy.Item{Name: fmt.Sprintf("Mirror chain problems (%d)", len(problems))}
+	for _, p := range problems {
+		label := p.NodeName
+		if label == "" {
+			label = p.NodeID
+		}
+		node.Children = append(node.Children, &workflowy.Item{
+			Name: fmt.Sprintf("[%s](https://workflowy.com/#/%s): %s via %s", label, p.NodeID, strings.ReplaceAll(p.Kind, "_", " "), strings.Join(p.Chain, " → ")),
+		})
+	}
+	return node
+}
+
 func buildMirrorChildren(info *mirror.MirrorInfo) []*workflowy.Item {
 	var children []*workflowy.Item
 
@@ -24,3 +24,22 @@ func GenerateTimestamp() string {
 func CreateReportNote() string {
 	return "Generated: " + GenerateTimestamp()
 }
+
+// snapshotTimestamp returns the time of the snapshot a report was computed
+// from, or the current time when the report has no snapshot.
+func snapshotTimestamp(snapshot workflowy.SnapshotMeta) string {
+	if snapshot.TakenAt.IsZero() {
+		return GenerateTimestamp()
+	}
+	return snapshot.TakenAt.Format("2006-01-02 15:04:05")
+}
+
+// snapshotNote returns the note of a report root: the snapshot it was computed
+// from and when it was generated.
+func snapshotNote(snapshot workflowy.SnapshotMeta) *string {
+	note := CreateReportNote()
+	if !snapshot.TakenAt.IsZero() {
+		note = "Snapshot: " + snapshot.String() + "\n" + note
+	}
+	return &note
+}
@@ -9,16 +9,17 @@ import (
 
 // ChildrenCountReportOutput wraps children count ranking results
 type ChildrenCountReportOutput struct {
-	Ranked []workflowy.ChildrenCountRankable
-	TopN   int
+	Ranked   []workflowy.ChildrenCountRankable
+	TopN     int
+	Snapshot workflowy.SnapshotMeta // the snapshot the ranking was computed from
 }
 
 // Title returns the report title
 func (r *ChildrenCountReportOutput) Title() string {
 	if r.TopN > 0 {
-		return fmt.Sprintf("Top %d Nodes by Children Count - %s", r.TopN, GenerateTimestamp())
+		return fmt.Sprintf("Top %d Nodes by Children Count - %s", r.TopN, snapshotTimestamp(r.Snapshot))
 	}
-	return fmt.Sprintf("Nodes by Children Count - %s", GenerateTimestamp())
+	return fmt.Sprintf("Nodes by Children Count - %s", snapshotTimestamp(r.Snapshot))
 }
 
 // ToNodes converts the ranking to Workflowy items
@@ -42,22 +43,24 @@ func (r *ChildrenCountReportOutput) ToNodes() (*workflowy.Item, error) {
 
 	return &workflowy.Item{
 		Name:     r.Title(),
+		Note:     snapshotNote(r.Snapshot),
 		Children: children,
 	}, nil
 }
 
 // CreatedReportOutput wraps created date ranking results
 type CreatedReportOutput struct {
-	Ranked []workflowy.TimestampRankable
-	TopN   int
+	Ranked   []workflowy.TimestampRankable
+	TopN     int
+	Snapshot workflowy.SnapshotMeta // the snapshot the ranking was computed from
 }
 
 // Title returns the report title
 func (r *CreatedReportOutput) Title() string {
 	if r.TopN > 0 {
-		return fmt.Sprintf("Top %d Oldest Nodes by Creation Date - %s", r.TopN, GenerateTimestamp())
+		return fmt.Sprintf("Top %d Oldest Nodes by Creation Date - %s", r.TopN, snapshotTimestamp(r.Snapshot))
 	}
-	return fmt.Sprintf("Oldest Nodes by Creation Date - %s", GenerateTimestamp())
+	return fmt.Sprintf("Oldest Nodes by Creation Date - %s", snapshotTimestamp(r.Snapshot))
 }
 
 // ToNodes converts the ranking to Workflowy items
@@ -84,22 +87,24 @@ func (r *CreatedReportOutput) ToNodes() (*workflowy.Item, error) {
 
 	return &workflowy.Item{
 		Name:     r.Title(),
+		Note:     snapshotNote(r.Snapshot),
 		Children: children,
 	}, nil
 }
 
 // ModifiedReportOutput wraps modified date ranking results
 type ModifiedReportOutput struct {
-	Ranked []workflowy.TimestampRankable
-	TopN   int
+	Ranked   []workflowy.TimestampRankable
+	TopN     int
+	Snapshot workflowy.SnapshotMeta // the snapshot the ranking was computed from
 }
 
 // Title returns the report title
 func (r *ModifiedReportOutput) Title() string {
 	if r.TopN > 0 {
-		return fmt.Sprintf("Top %d Oldest Nodes by Modification Date - %s", r.TopN, GenerateTimestamp())
+		return fmt.Sprintf("Top %d Oldest Nodes by Modification Date - %s", r.TopN, snapshotTimestamp(r.Snapshot))
 	}
-	return fmt.Sprintf("Oldest Nodes by Modification Date - %s", GenerateTimestamp())
+	return fmt.Sprintf("Oldest Nodes by Modification Date - %s", snapshotTimestamp(r.Snapshot))
 }
 
 // ToNodes converts the ranking to Workflowy items
@@ -131,6 +136,7 @@ func (r *ModifiedReportOutput) ToNodes() (*workflowy.Item, error) {
 
 	return &workflowy.Item{
 		Name:     r.Title(),
+		Note:     snapshotNote(r.Snapshot),
 		Children: children,
 	}, nil
 }
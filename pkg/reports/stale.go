@@ -0,0 +1,46 @@
+package reports
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// StaleReportOutput wraps the subtrees untouched for a number of days
+type StaleReportOutput struct {
+	Subtrees []workflowy.StaleSubtree
+	Days     int
+	TopN     int
+	Snapshot workflowy.SnapshotMeta // the snapshot the subtrees were found in
+}
+
+// Title returns the report title
+func (r *StaleReportOutput) Title() string {
+	if r.TopN > 0 {
+		return fmt.Sprintf("Top %d Subtrees Untouched for %d Days - %s", r.TopN, r.Days, snapshotTimestamp(r.Snapshot))
+	}
+	return fmt.Sprintf("Subtrees Untouched for %d Days - %s", r.Days, snapshotTimestamp(r.Snapshot))
+}
+
+// ToNodes converts the stale subtrees to Workflowy items linking to them
+func (r *StaleReportOutput) ToNodes() (*workflowy.Item, error) {
+	children := make([]*workflowy.Item, len(r.Subtrees))
+
+	for i, subtree := range r.Subtrees {
+		modified := "no date"
+		if subtree.LastModified > 0 {
+			modified = "last modified " + time.Unix(subtree.LastModified, 0).Format("2006-01-02")
+		}
+		children[i] = &workflowy.Item{
+			Name: fmt.Sprintf("%d. [%s](https://workflowy.com/#/%s) (%d descendants, %s)",
+				i+1, subtree.Name, subtree.ID, subtree.Descendants, modified),
+		}
+	}
+
+	return &workflowy.Item{
+		Name:     r.Title(),
+		Note:     snapshotNote(r.Snapshot),
+		Children: children,
+	}, nil
+}
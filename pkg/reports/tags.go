@@ -0,0 +1,46 @@
+package reports
+
+import (
+	"fmt"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// TagsReportOutput wraps tag usage counts
+type TagsReportOutput struct {
+	Tags     []workflowy.TagUsage
+	TopN     int
+	Snapshot workflowy.SnapshotMeta // the snapshot the tags were counted in
+}
+
+// Title returns the report title
+func (r *TagsReportOutput) Title() string {
+	if r.TopN > 0 {
+		return fmt.Sprintf("Top %d Tags by Usage - %s", r.TopN, snapshotTimestamp(r.Snapshot))
+	}
+	return fmt.Sprintf("Tags by Usage - %s", snapshotTimestamp(r.Snapshot))
+}
+
+// ToNodes converts the tag counts to Workflowy items, with links to the nodes
+// using each tag the most
+func (r *TagsReportOutput) ToNodes() (*workflowy.Item, error) {
+	children := make([]*workflowy.Item, len(r.Tags))
+
+	for i, usage := range r.Tags {
+		child := &workflowy.Item{
+			Name: fmt.Sprintf("%d. %s (%d uses in %d nodes)", i+1, usage.Tag, usage.Count, usage.NodeCount),
+		}
+		for _, node := range usage.Nodes {
+			child.Children = append(child.Children, &workflowy.Item{
+				Name: fmt.Sprintf("[%s](https://workflowy.com/#/%s) (%d)", node.Name, node.ID, node.Count),
+			})
+		}
+		children[i] = child
+	}
+
+	return &workflowy.Item{
+		Name:     r.Title(),
+		Note:     snapshotNote(r.Snapshot),
+		Children: children,
+	}, nil
+}
@@ -1,25 +1,109 @@
-// TODO: Add --name and --note flags to search command to allow searching in notes
-// (currently only searches in item.Name)
 package search
 
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/mholzen/workflowy/pkg/workflowy"
 )
 
+// Fields that can be searched
+const (
+	FieldName = "name"
+	FieldNote = "note"
+)
+
+// ParseFields parses a comma-separated list of fields, such as "name,note".
+func ParseFields(value string) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		switch field {
+		case FieldName, FieldNote:
+			if !slices.Contains(fields, field) {
+				fields = append(fields, field)
+			}
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown field %q: must be %s or %s", field, FieldName, FieldNote)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("at least one field is required")
+	}
+	return fields, nil
+}
+
 type Result struct {
-	ID              string          `json:"id"`
-	Name            string          `json:"name"`
-	HighlightedName string          `json:"highlighted_name"`
-	URL             string          `json:"url"`
-	MatchPositions  []MatchPosition `json:"match_positions"`
+	ID                 string          `json:"id"`
+	Name               string          `json:"name"`
+	HighlightedName    string          `json:"highlighted_name"`
+	URL                string          `json:"url"`
+	MatchPositions     []MatchPosition `json:"match_positions"`
+	Fields             []string        `json:"fields"` // the fields that matched
+	HighlightedNote    string          `json:"highlighted_note,omitempty"`
+	NoteMatchPositions []MatchPosition `json:"note_match_positions,omitempty"`
+	Breadcrumb         string          `json:"breadcrumb,omitempty"`
 }
 
 func (r Result) String() string {
-	return fmt.Sprintf("- [%s](%s)", r.HighlightedName, r.URL)
+	s := fmt.Sprintf("- [%s](%s)", r.HighlightedName, r.URL)
+	if r.Breadcrumb != "" {
+		s += fmt.Sprintf(" (in %s)", r.Breadcrumb)
+	}
+	if len(r.NoteMatchPositions) > 0 {
+		s += " — note: " + noteExcerpt(r.HighlightedNote)
+	}
+	return s
+}
+
+// noteExcerptContext is the number of bytes kept around the first match of a
+// note in Result.String.
+const noteExcerptContext = 40
+
+// noteExcerpt shortens a highlighted note to a single line around its first match.
+func noteExcerpt(highlighted string) string {
+	start := strings.Index(highlighted, "**")
+	if start < 0 {
+		start = 0
+	}
+	end := strings.Index(highlighted[min(start+2, len(highlighted)):], "**")
+	if end < 0 {
+		end = start
+	} else {
+		end += start + 4
+	}
+
+	from := max(start-noteExcerptContext, 0)
+	for from > 0 && !utf8.RuneStart(highlighted[from]) {
+		from--
+	}
+	to := min(end+noteExcerptContext, len(highlighted))
+	for to < len(highlighted) && !utf8.RuneStart(highlighted[to]) {
+		to++
+	}
+
+	excerpt := strings.Join(strings.Fields(highlighted[from:to]), " ")
+	if from > 0 {
+		excerpt = "…" + excerpt
+	}
+	if to < len(highlighted) {
+		excerpt += "…"
+	}
+	return excerpt
+}
+
+// NodeID returns the ID of the node of the result.
+func (r *Result) NodeID() string {
+	return r.ID
+}
+
+// SetBreadcrumb sets the ancestors shown with the result (see workflowy.AddBreadcrumbs).
+func (r *Result) SetBreadcrumb(breadcrumb string) {
+	r.Breadcrumb = breadcrumb
 }
 
 type MatchPosition struct {
@@ -27,33 +111,52 @@ type MatchPosition struct {
 	End   int `json:"end"`
 }
 
+// SearchItems searches the names of items and their descendants.
 func SearchItems(items []*workflowy.Item, pattern string, useRegexp, ignoreCase bool) []Result {
+	return SearchFields(items, pattern, useRegexp, ignoreCase, []string{FieldName})
+}
+
+// SearchFields searches the given fields of items and their descendants. A node
+// matching in several fields is returned once, with each field listed.
+func SearchFields(items []*workflowy.Item, pattern string, useRegexp, ignoreCase bool, fields []string) []Result {
 	var results []Result
 
 	for _, item := range items {
-		collectSearchResults(item, pattern, useRegexp, ignoreCase, &results)
+		collectSearchResults(item, pattern, useRegexp, ignoreCase, fields, &results)
 	}
 
 	return results
 }
 
-func collectSearchResults(item *workflowy.Item, pattern string, useRegexp, ignoreCase bool, results *[]Result) {
+func collectSearchResults(item *workflowy.Item, pattern string, useRegexp, ignoreCase bool, fields []string, results *[]Result) {
 	name := item.Name
-	matchPositions := FindMatches(name, pattern, useRegexp, ignoreCase)
+	result := Result{
+		ID:              item.ID,
+		Name:            name,
+		HighlightedName: name,
+		URL:             fmt.Sprintf("https://workflowy.com/#/%s", item.ID),
+	}
 
-	if len(matchPositions) > 0 {
-		highlightedName := HighlightMatches(name, matchPositions)
-		*results = append(*results, Result{
-			ID:              item.ID,
-			Name:            name,
-			HighlightedName: highlightedName,
-			URL:             fmt.Sprintf("https://workflowy.com/#/%s", item.ID),
-			MatchPositions:  matchPositions,
-		})
+	if slices.Contains(fields, FieldName) {
+		if positions := FindMatches(name, pattern, useRegexp, ignoreCase); len(positions) > 0 {
+			result.HighlightedName = HighlightMatches(name, positions)
+			result.MatchPositions = positions
+			result.Fields = append(result.Fields, FieldName)
+		}
+	}
+	if slices.Contains(fields, FieldNote) && item.Note != nil {
+		if positions := FindMatches(*item.Note, pattern, useRegexp, ignoreCase); len(positions) > 0 {
+			result.HighlightedNote = HighlightMatches(*item.Note, positions)
+			result.NoteMatchPositions = positions
+			result.Fields = append(result.Fields, FieldNote)
+		}
+	}
+	if len(result.Fields) > 0 {
+		*results = append(*results, result)
 	}
 
 	for _, child := range item.Children {
-		collectSearchResults(child, pattern, useRegexp, ignoreCase, results)
+		collectSearchResults(child, pattern, useRegexp, ignoreCase, fields, results)
 	}
 }
 
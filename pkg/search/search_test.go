@@ -0,0 +1,54 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields("name, note,name")
+	require.NoError(t, err)
+	assert.Equal(t, []string{FieldName, FieldNote}, fields)
+
+	_, err = ParseFields("name,body")
+	assert.Error(t, err)
+	_, err = ParseFields("")
+	assert.Error(t, err)
+}
+
+func TestSearchFields(t *testing.T) {
+	note := "call the plumber\nabout the leak"
+	items := []*workflowy.Item{
+		{ID: "a", Name: "Leak in kitchen", Note: &note},
+		{ID: "b", Name: "Groceries", Note: &note, Children: []*workflowy.Item{
+			{ID: "c", Name: "milk"},
+		}},
+	}
+
+	results := SearchItems(items, "leak", false, true)
+	require.Len(t, results, 1)
+	assert.Equal(t, []string{FieldName}, results[0].Fields)
+	assert.Empty(t, results[0].HighlightedNote)
+
+	results = SearchFields(items, "leak", false, true, []string{FieldName, FieldNote})
+	require.Len(t, results, 2)
+	assert.Equal(t, []string{FieldName, FieldNote}, results[0].Fields)
+	assert.Equal(t, "**Leak** in kitchen", results[0].HighlightedName)
+	assert.Equal(t, []string{FieldNote}, results[1].Fields)
+	assert.Equal(t, "Groceries", results[1].HighlightedName)
+	assert.Equal(t, "call the plumber\nabout the **leak**", results[1].HighlightedNote)
+	assert.Equal(t, "- [Groceries](https://workflowy.com/#/b) — note: call the plumber about the **leak**", results[1].String())
+}
+
+func TestNoteExcerpt(t *testing.T) {
+	long := strings.Repeat("é", 60) + " **match** " + strings.Repeat("x", 60)
+	excerpt := noteExcerpt(long)
+	assert.True(t, strings.HasPrefix(excerpt, "…"))
+	assert.True(t, strings.HasSuffix(excerpt, "…"))
+	assert.Contains(t, excerpt, "**match**")
+	assert.Less(t, len(excerpt), len(long))
+}
@@ -0,0 +1,218 @@
+// Package secrets finds likely secrets (API keys, passwords, card numbers)
+// in an outline, so they can be removed from the cloud copy.
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"gopkg.in/yaml.v3"
+)
+
+// Fields a secret can be found in
+const (
+	FieldName = "name"
+	FieldNote = "note"
+)
+
+// Detector finds one kind of secret with a regular expression. When the
+// expression has a group named "secret", only that group is the secret, and
+// the rest of the match (e.g. "password:") is context.
+type Detector struct {
+	Name     string
+	Pattern  *regexp.Regexp
+	validate func(match string) bool // rejects matches that only look like secrets
+}
+
+// matches returns the secrets the detector finds in text.
+func (d Detector) matches(text string) []string {
+	var matches []string
+	for _, match := range d.Pattern.FindAllString(text, -1) {
+		if d.validate == nil || d.validate(match) {
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// DefaultDetectors returns the built-in detectors. They are heuristics: they
+// miss secrets without a recognizable shape and may flag harmless text.
+func DefaultDetectors() []Detector {
+	return []Detector{
+		{Name: "aws-access-key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{Name: "credit-card", Pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), validate: luhn},
+		{Name: "github-token", Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+		{Name: "google-api-key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+		{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+		{Name: "password", Pattern: regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|passcode)\s*[:=]\s*(?P<secret>\S+)`)},
+		{Name: "private-key", Pattern: regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY-----`)},
+		{Name: "secret-assignment", Pattern: regexp.MustCompile(`(?i)\b(?:api[_-]?key|secret|token|access[_-]?key)\s*[:=]\s*['"]?(?P<secret>[A-Za-z0-9_\-./+=]{12,})`)},
+		{Name: "secret-key", Pattern: regexp.MustCompile(`\b(?:sk-[A-Za-z0-9_-]{20,}|[rs]k_live_[A-Za-z0-9]{20,})`)},
+		{Name: "slack-token", Pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+		{Name: "url-credentials", Pattern: regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^\s:/@]+:(?P<secret>[^\s/@]+)@`)},
+	}
+}
+
+// LoadDetectors reads custom detectors from a YAML file mapping each name to
+// a regular expression:
+//
+//	employee-id: "EMP-[0-9]{6}"
+//	vault-path: "(?i)vault://\\S+"
+func LoadDetectors(path string) ([]Detector, error) {
+	data, err := os.ReadFile(workflowy.ExpandTilde(path))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read patterns file: %w", err)
+	}
+	var patterns map[string]string
+	if err := yaml.Unmarshal(data, &patterns); err != nil {
+		return nil, fmt.Errorf("cannot parse patterns file %s: %w", path, err)
+	}
+
+	detectors := make([]Detector, 0, len(patterns))
+	for name, pattern := range patterns {
+		if pattern == "" {
+			return nil, fmt.Errorf("pattern %q in %s is empty", name, path)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q in %s: %w", name, path, err)
+		}
+		detectors = append(detectors, Detector{Name: name, Pattern: re})
+	}
+	sort.Slice(detectors, func(i, j int) bool { return detectors[i].Name < detectors[j].Name })
+	return detectors, nil
+}
+
+// Merge returns the detectors of base and extra, an extra detector replacing
+// the base detector of the same name.
+func Merge(base, extra []Detector) []Detector {
+	names := make(map[string]bool, len(extra))
+	for _, detector := range extra {
+		names[detector.Name] = true
+	}
+	var merged []Detector
+	for _, detector := range base {
+		if !names[detector.Name] {
+			merged = append(merged, detector)
+		}
+	}
+	return append(merged, extra...)
+}
+
+// Finding is a likely secret in a node.
+type Finding struct {
+	Detector string `json:"detector"`
+	Field    string `json:"field"` // name or note
+	Match    string `json:"match"` // redacted
+}
+
+// Exposure is a node with likely secrets.
+type Exposure struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"` // with the secrets redacted
+	Findings []Finding `json:"findings"`
+}
+
+// Scan returns the nodes of root and its descendants in which a detector
+// finds a secret, in outline order. Secrets are redacted in the results, so
+// they can be shown or uploaded as a report.
+func Scan(root *workflowy.Item, detectors []Detector) []Exposure {
+	var exposures []Exposure
+	var visit func(item *workflowy.Item)
+	visit = func(item *workflowy.Item) {
+		var findings []Finding
+		name := item.Name
+		for _, detector := range detectors {
+			for _, match := range detector.matches(item.Name) {
+				findings = append(findings, Finding{Detector: detector.Name, Field: FieldName, Match: Redact(match)})
+				name = strings.ReplaceAll(name, match, Redact(match))
+			}
+			if item.Note != nil {
+				for _, match := range detector.matches(*item.Note) {
+					findings = append(findings, Finding{Detector: detector.Name, Field: FieldNote, Match: Redact(match)})
+				}
+			}
+		}
+		if len(findings) > 0 {
+			exposures = append(exposures, Exposure{ID: item.ID, Name: name, Findings: findings})
+		}
+		for _, child := range item.Children {
+			visit(child)
+		}
+	}
+	visit(root)
+	return exposures
+}
+
+// Mask replaces the secrets the detectors find in text with asterisks,
+// keeping their last four characters so they can still be told apart.
+func Mask(text string, detectors []Detector) string {
+	for _, detector := range detectors {
+		group := detector.Pattern.SubexpIndex("secret")
+		var masked strings.Builder
+		last := 0
+		for _, loc := range detector.Pattern.FindAllStringSubmatchIndex(text, -1) {
+			if detector.validate != nil && !detector.validate(text[loc[0]:loc[1]]) {
+				continue
+			}
+			start, end := loc[0], loc[1]
+			if group > 0 && loc[2*group] >= 0 {
+				start, end = loc[2*group], loc[2*group+1]
+			}
+			masked.WriteString(text[last:start])
+			masked.WriteString(maskSecret(text[start:end]))
+			last = end
+		}
+		masked.WriteString(text[last:])
+		text = masked.String()
+	}
+	return text
+}
+
+// maskSecret hides all but the last four characters of a secret, or all of
+// it when it is short enough for four characters to give much of it away.
+func maskSecret(secret string) string {
+	runes := []rune(secret)
+	if len(runes) <= 8 {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+}
+
+// Redact keeps the first four characters of a secret, enough to recognize it.
+func Redact(secret string) string {
+	runes := []rune(secret)
+	if len(runes) <= 8 {
+		return "****"
+	}
+	return string(runes[:4]) + "****"
+}
+
+// luhn reports whether the digits of number pass the Luhn checksum of card numbers.
+func luhn(number string) bool {
+	var digits []int
+	for _, r := range number {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, int(r-'0'))
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := range digits {
+		digit := digits[len(digits)-1-i]
+		if i%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,203 @@
+// Package selftest exercises the write API end to end, inside a scratch node,
+// to confirm that an API key, its permissions and the connection work.
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// Step is a check of the self-test.
+type Step struct {
+	Name       string `json:"name"`
+	OK         bool   `json:"ok"`
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (s Step) String() string {
+	if s.OK {
+		return fmt.Sprintf("ok    %s (%dms)", s.Name, s.DurationMS)
+	}
+	if s.Error == "" {
+		return fmt.Sprintf("skip  %s", s.Name)
+	}
+	return fmt.Sprintf("FAIL  %s: %s", s.Name, s.Error)
+}
+
+// Report is the outcome of a self-test.
+type Report struct {
+	ParentID  string `json:"parent_id"`
+	ScratchID string `json:"scratch_id,omitempty"` // the node created for the test, deleted at the end
+	Steps     []Step `json:"steps"`
+	Passed    bool   `json:"passed"`
+}
+
+// Failed returns the number of steps that did not pass.
+func (r *Report) Failed() int {
+	failed := 0
+	for _, step := range r.Steps {
+		if !step.OK {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Run creates a scratch node at the bottom of parentID and, inside it,
+// creates, reads, updates, moves, completes, uncompletes and deletes nodes,
+// checking each result through the API. The scratch node is deleted at the
+// end, whatever the outcome. Once a step fails, the steps depending on it
+// are skipped.
+func Run(ctx context.Context, client workflowy.Client, parentID string) *Report {
+	r := &runner{client: client, report: &Report{ParentID: parentID}}
+
+	r.step("read parent", func() error {
+		_, err := client.GetItem(ctx, parentID)
+		return err
+	})
+	r.step("create scratch node", func() error {
+		id, err := r.create(ctx, parentID, fmt.Sprintf("workflowy selftest %s", time.Now().Format(time.RFC3339)))
+		r.report.ScratchID = id
+		return err
+	})
+	if r.report.ScratchID != "" {
+		defer func() {
+			// clean up even when cancelled
+			r.step("delete scratch node", func() error {
+				_, err := client.DeleteNode(context.WithoutCancel(ctx), r.report.ScratchID)
+				return err
+			})
+			r.report.Passed = r.report.Failed() == 0
+		}()
+	}
+
+	var first, second string
+	r.step("create node", func() error {
+		var err error
+		if first, err = r.create(ctx, r.report.ScratchID, "first"); err != nil {
+			return err
+		}
+		return r.expect(ctx, first, "first", "", false)
+	})
+	r.step("update name and note", func() error {
+		name, note := "first (updated)", "selftest note"
+		if _, err := client.UpdateNode(ctx, first, &workflowy.UpdateNodeRequest{Name: &name, Note: &note}); err != nil {
+			return err
+		}
+		return r.expect(ctx, first, name, note, false)
+	})
+	r.step("move node", func() error {
+		var err error
+		if second, err = r.create(ctx, r.report.ScratchID, "second"); err != nil {
+			return err
+		}
+		if _, err := client.MoveNode(ctx, first, &workflowy.MoveNodeRequest{ParentID: second}); err != nil {
+			return err
+		}
+		return r.expectChild(ctx, second, first, true)
+	})
+	r.step("complete node", func() error {
+		if _, err := client.CompleteNode(ctx, first); err != nil {
+			return err
+		}
+		return r.expect(ctx, first, "first (updated)", "selftest note", true)
+	})
+	r.step("uncomplete node", func() error {
+		if _, err := client.UncompleteNode(ctx, first); err != nil {
+			return err
+		}
+		return r.expect(ctx, first, "first (updated)", "selftest note", false)
+	})
+	r.step("delete node", func() error {
+		if _, err := client.DeleteNode(ctx, first); err != nil {
+			return err
+		}
+		return r.expectChild(ctx, second, first, false)
+	})
+
+	r.report.Passed = r.report.Failed() == 0
+	return r.report
+}
+
+type runner struct {
+	client workflowy.Client
+	report *Report
+	failed bool
+}
+
+// step runs check, unless an earlier step failed, and records its outcome.
+func (r *runner) step(name string, check func() error) {
+	step := Step{Name: name}
+	if r.failed && name != "delete scratch node" {
+		r.report.Steps = append(r.report.Steps, step)
+		return
+	}
+	start := time.Now()
+	err := check()
+	step.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		step.Error = err.Error()
+		r.failed = true
+	} else {
+		step.OK = true
+	}
+	r.report.Steps = append(r.report.Steps, step)
+}
+
+func (r *runner) create(ctx context.Context, parentID, name string) (string, error) {
+	position := "bottom"
+	resp, err := r.client.CreateNode(ctx, &workflowy.CreateNodeRequest{ParentID: parentID, Name: name, Position: &position})
+	if err != nil {
+		return "", err
+	}
+	if resp.ItemID == "" {
+		return "", fmt.Errorf("no ID returned")
+	}
+	return resp.ItemID, nil
+}
+
+// expect reads id back and checks its name, note and completion.
+func (r *runner) expect(ctx context.Context, id, name, note string, completed bool) error {
+	item, err := r.client.GetItem(ctx, id)
+	if err != nil {
+		return fmt.Errorf("cannot read back %s: %w", id, err)
+	}
+	if item.Name != name {
+		return fmt.Errorf("name is %q, expected %q", item.Name, name)
+	}
+	if itemNote := noteOf(item); itemNote != note {
+		return fmt.Errorf("note is %q, expected %q", itemNote, note)
+	}
+	if item.IsCompleted() != completed {
+		return fmt.Errorf("completed is %t, expected %t", item.IsCompleted(), completed)
+	}
+	return nil
+}
+
+// expectChild checks whether childID is a child of parentID.
+func (r *runner) expectChild(ctx context.Context, parentID, childID string, present bool) error {
+	children, err := r.client.ListChildren(ctx, parentID)
+	if err != nil {
+		return fmt.Errorf("cannot list children of %s: %w", parentID, err)
+	}
+	found := slices.ContainsFunc(children.Items, func(item *workflowy.Item) bool { return item.ID == childID })
+	if found != present {
+		if present {
+			return fmt.Errorf("%s not found under %s", childID, parentID)
+		}
+		return fmt.Errorf("%s still under %s", childID, parentID)
+	}
+	return nil
+}
+
+func noteOf(item *workflowy.Item) string {
+	if item.Note == nil {
+		return ""
+	}
+	return *item.Note
+}
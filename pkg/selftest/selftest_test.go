@@ -0,0 +1,165 @@
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient is an in-memory outline.
+type fakeClient struct {
+	workflowy.Client
+	items    map[string]*workflowy.Item
+	parents  map[string]string
+	next     int
+	failMove bool
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		items:   map[string]*workflowy.Item{"root": {ID: "root"}},
+		parents: map[string]string{},
+	}
+}
+
+func (c *fakeClient) item(id string) (*workflowy.Item, error) {
+	item, ok := c.items[id]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", id)
+	}
+	return item, nil
+}
+
+func (c *fakeClient) GetItem(ctx context.Context, itemID string) (*workflowy.Item, error) {
+	item, err := c.item(itemID)
+	if err != nil {
+		return nil, err
+	}
+	copy := *item
+	return &copy, nil
+}
+
+func (c *fakeClient) ListChildren(ctx context.Context, itemID string) (*workflowy.ListChildrenResponse, error) {
+	var items []*workflowy.Item
+	for id, parent := range c.parents {
+		if parent == itemID {
+			items = append(items, c.items[id])
+		}
+	}
+	return &workflowy.ListChildrenResponse{Items: items}, nil
+}
+
+func (c *fakeClient) CreateNode(ctx context.Context, req *workflowy.CreateNodeRequest) (*workflowy.CreateNodeResponse, error) {
+	if _, err := c.item(req.ParentID); err != nil {
+		return nil, err
+	}
+	c.next++
+	id := fmt.Sprintf("n%d", c.next)
+	c.items[id] = &workflowy.Item{ID: id, Name: req.Name}
+	c.parents[id] = req.ParentID
+	return &workflowy.CreateNodeResponse{ItemID: id}, nil
+}
+
+func (c *fakeClient) UpdateNode(ctx context.Context, itemID string, req *workflowy.UpdateNodeRequest) (*workflowy.UpdateNodeResponse, error) {
+	item, err := c.item(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		item.Name = *req.Name
+	}
+	if req.Note != nil {
+		note := *req.Note
+		item.Note = &note
+	}
+	return &workflowy.UpdateNodeResponse{Status: "ok"}, nil
+}
+
+func (c *fakeClient) MoveNode(ctx context.Context, itemID string, req *workflowy.MoveNodeRequest) (*workflowy.MoveNodeResponse, error) {
+	if c.failMove {
+		return nil, fmt.Errorf("forbidden")
+	}
+	c.parents[itemID] = req.ParentID
+	return &workflowy.MoveNodeResponse{Status: "ok"}, nil
+}
+
+func (c *fakeClient) CompleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error) {
+	item, err := c.item(itemID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	item.CompletedAt = &now
+	return &workflowy.UpdateNodeResponse{Status: "ok"}, nil
+}
+
+func (c *fakeClient) UncompleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error) {
+	item, err := c.item(itemID)
+	if err != nil {
+		return nil, err
+	}
+	item.CompletedAt = nil
+	return &workflowy.UpdateNodeResponse{Status: "ok"}, nil
+}
+
+func (c *fakeClient) DeleteNode(ctx context.Context, itemID string) (*workflowy.UpdateNodeResponse, error) {
+	if _, err := c.item(itemID); err != nil {
+		return nil, err
+	}
+	var remove func(id string)
+	remove = func(id string) {
+		delete(c.items, id)
+		delete(c.parents, id)
+		for child, parent := range c.parents {
+			if parent == id {
+				remove(child)
+			}
+		}
+	}
+	remove(itemID)
+	return &workflowy.UpdateNodeResponse{Status: "ok"}, nil
+}
+
+func TestRun(t *testing.T) {
+	client := newFakeClient()
+
+	report := Run(context.Background(), client, "root")
+	for _, step := range report.Steps {
+		assert.True(t, step.OK, step.String())
+	}
+	assert.True(t, report.Passed)
+	assert.NotEmpty(t, report.ScratchID)
+	assert.Equal(t, "delete scratch node", report.Steps[len(report.Steps)-1].Name)
+	assert.Len(t, client.items, 1, "scratch node left behind")
+}
+
+func TestRunFailure(t *testing.T) {
+	client := newFakeClient()
+	client.failMove = true
+
+	report := Run(context.Background(), client, "root")
+	assert.False(t, report.Passed)
+	assert.Equal(t, 4, report.Failed())
+
+	names := make(map[string]Step)
+	for _, step := range report.Steps {
+		names[step.Name] = step
+	}
+	assert.Contains(t, names["move node"].Error, "forbidden")
+	assert.Empty(t, names["complete node"].Error, "skipped")
+	assert.True(t, names["delete scratch node"].OK)
+	assert.Len(t, client.items, 1, "scratch node left behind")
+}
+
+func TestRunMissingParent(t *testing.T) {
+	report := Run(context.Background(), newFakeClient(), "missing")
+	require.NotEmpty(t, report.Steps)
+	assert.False(t, report.Passed)
+	assert.False(t, report.Steps[0].OK)
+	assert.Empty(t, report.ScratchID)
+}
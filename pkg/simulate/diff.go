@@ -0,0 +1,152 @@
+package simulate
+
+import (
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+// maxDiffCells bounds the work of comparing the changed region line by line;
+// beyond it, the whole region is shown as removed and added.
+const maxDiffCells = 4_000_000
+
+// Render returns the descendants of root as the lines of a markdown list,
+// completed nodes as checked items and notes indented under their node.
+func Render(root *workflowy.Item) []string {
+	var lines []string
+	var visit func(item *workflowy.Item, depth int)
+	visit = func(item *workflowy.Item, depth int) {
+		indent := strings.Repeat("  ", depth)
+		marker := "- "
+		if item.IsCompleted() {
+			marker = "- [x] "
+		}
+		lines = append(lines, indent+marker+item.Name)
+		if item.Note != nil && *item.Note != "" {
+			for _, line := range strings.Split(*item.Note, "\n") {
+				lines = append(lines, indent+"  "+line)
+			}
+		}
+		for _, child := range item.Children {
+			visit(child, depth+1)
+		}
+	}
+	for _, child := range root.Children {
+		visit(child, 0)
+	}
+	return lines
+}
+
+// Diff compares two renderings and returns the changed lines, prefixed with
+// "-" or "+", surrounded by a few unchanged lines; "..." separates changes
+// far apart. It returns the number of lines added and removed.
+func Diff(before, after []string) (lines []string, added, removed int) {
+	// lines common to the start and end of both are unchanged
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	var ops []line
+	for _, text := range before[:prefix] {
+		ops = append(ops, line{' ', text})
+	}
+	ops = append(ops, compare(before[prefix:len(before)-suffix], after[prefix:len(after)-suffix])...)
+	for _, text := range before[len(before)-suffix:] {
+		ops = append(ops, line{' ', text})
+	}
+
+	// keep the changes and the unchanged lines close to one
+	keep := make([]bool, len(ops))
+	for i, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+		if op.kind == '+' {
+			added++
+		} else {
+			removed++
+		}
+		for j := max(0, i-contextLines); j <= min(len(ops)-1, i+contextLines); j++ {
+			keep[j] = true
+		}
+	}
+	for i, op := range ops {
+		if !keep[i] {
+			continue
+		}
+		if i > 0 && !keep[i-1] && len(lines) > 0 {
+			lines = append(lines, "...")
+		}
+		lines = append(lines, string(op.kind)+op.text)
+	}
+	return lines, added, removed
+}
+
+// line is a line of a diff, kind being ' ', '-' or '+'.
+type line struct {
+	kind byte
+	text string
+}
+
+// compare diffs two sequences of lines through their longest common
+// subsequence, removals before additions.
+func compare(before, after []string) []line {
+	if len(before)*len(after) > maxDiffCells {
+		var lines []line
+		for _, text := range before {
+			lines = append(lines, line{'-', text})
+		}
+		for _, text := range after {
+			lines = append(lines, line{'+', text})
+		}
+		return lines
+	}
+
+	// common[i][j] is the length of the longest common subsequence of before[i:] and after[j:]
+	common := make([][]int, len(before)+1)
+	for i := range common {
+		common[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var lines []line
+	i, j := 0, 0
+	for i < len(before) && j < len(after) {
+		switch {
+		case before[i] == after[j]:
+			lines = append(lines, line{' ', before[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, line{'-', before[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', after[j]})
+			j++
+		}
+	}
+	for ; i < len(before); i++ {
+		lines = append(lines, line{'-', before[i]})
+	}
+	for ; j < len(after); j++ {
+		lines = append(lines, line{'+', after[j]})
+	}
+	return lines
+}
@@ -0,0 +1,288 @@
+// Package simulate predicts the outcome of write operations by applying them
+// to an in-memory copy of the tree, without calling the API, so the changes
+// can be reviewed before they are made.
+package simulate
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// Kinds of Operation
+const (
+	OpCreate     = "create"
+	OpUpdate     = "update"
+	OpMove       = "move"
+	OpDelete     = "delete"
+	OpComplete   = "complete"
+	OpUncomplete = "uncomplete"
+)
+
+// PlaceholderPrefix starts the IDs given to created nodes, new-1, new-2...,
+// in the order of the operations, so later operations can refer to them.
+const PlaceholderPrefix = "new-"
+
+// IsPlaceholder reports whether id refers to a node created by an operation.
+func IsPlaceholder(id string) bool {
+	return strings.HasPrefix(id, PlaceholderPrefix)
+}
+
+// Operation is one proposed write, with the parameters of the matching write tool.
+type Operation struct {
+	Op       string  `json:"op"`
+	ID       string  `json:"id,omitempty"`        // the node updated, moved, deleted or (un)completed
+	ParentID string  `json:"parent_id,omitempty"` // the parent of a created or moved node
+	Name     *string `json:"name,omitempty"`
+	Note     *string `json:"note,omitempty"`
+	Position string  `json:"position,omitempty"` // top or bottom
+}
+
+// Validate checks that the operation has the parameters its kind requires.
+func (o Operation) Validate() error {
+	switch o.Op {
+	case OpCreate:
+		if o.Name == nil || strings.TrimSpace(*o.Name) == "" {
+			return fmt.Errorf("create requires a name")
+		}
+	case OpUpdate:
+		if o.ID == "" {
+			return fmt.Errorf("update requires an id")
+		}
+		if o.Name == nil && o.Note == nil {
+			return fmt.Errorf("update requires a name or a note")
+		}
+	case OpMove:
+		if o.ID == "" || o.ParentID == "" {
+			return fmt.Errorf("move requires an id and a parent_id")
+		}
+	case OpDelete, OpComplete, OpUncomplete:
+		if o.ID == "" {
+			return fmt.Errorf("%s requires an id", o.Op)
+		}
+	default:
+		return fmt.Errorf("unknown operation %q: must be create, update, move, delete, complete or uncomplete", o.Op)
+	}
+	return workflowy.ValidatePosition(o.Position)
+}
+
+// Tree is an in-memory copy of an outline that operations are applied to.
+type Tree struct {
+	root    *workflowy.Item // holds the top-level items
+	parents map[string]*workflowy.Item
+	created int
+	now     func() time.Time
+}
+
+// NewTree copies items, leaving them untouched by the operations.
+func NewTree(items []*workflowy.Item) *Tree {
+	t := &Tree{
+		root:    &workflowy.Item{ID: "root", Name: "Root"},
+		parents: make(map[string]*workflowy.Item),
+		now:     time.Now,
+	}
+	t.root.Children = t.copyItems(items, t.root)
+	return t
+}
+
+func (t *Tree) copyItems(items []*workflowy.Item, parent *workflowy.Item) []*workflowy.Item {
+	copies := make([]*workflowy.Item, len(items))
+	for i, item := range items {
+		copied := *item
+		if item.Note != nil {
+			note := *item.Note
+			copied.Note = &note
+		}
+		copied.Children = t.copyItems(item.Children, &copied)
+		t.parents[copied.ID] = parent
+		copies[i] = &copied
+	}
+	return copies
+}
+
+// Items returns the top-level items of the tree.
+func (t *Tree) Items() []*workflowy.Item {
+	return t.root.Children
+}
+
+// Root returns the node with the given ID, or a synthetic root holding the
+// top-level items when id is "None" or empty.
+func (t *Tree) Root(id string) (*workflowy.Item, error) {
+	if id == "" || id == "None" {
+		return t.root, nil
+	}
+	return t.find(id)
+}
+
+func (t *Tree) find(id string) (*workflowy.Item, error) {
+	parent, ok := t.parents[id]
+	if !ok {
+		return nil, fmt.Errorf("item with ID %s not found", id)
+	}
+	for _, child := range parent.Children {
+		if child.ID == id {
+			return child, nil
+		}
+	}
+	return nil, fmt.Errorf("item with ID %s not found", id)
+}
+
+// Apply applies op to the tree and returns the ID of the node it affected,
+// a placeholder for created nodes.
+func (t *Tree) Apply(op Operation) (string, error) {
+	if err := op.Validate(); err != nil {
+		return "", err
+	}
+	if op.Op == OpCreate {
+		return t.create(op)
+	}
+
+	item, err := t.find(op.ID)
+	if err != nil {
+		return "", err
+	}
+	now := t.now().Unix()
+	switch op.Op {
+	case OpUpdate:
+		if op.Name != nil {
+			item.Name = *op.Name
+		}
+		if op.Note != nil {
+			note := *op.Note
+			item.Note = &note
+		}
+		item.ModifiedAt = now
+	case OpMove:
+		parent, err := t.Root(op.ParentID)
+		if err != nil {
+			return "", err
+		}
+		for ancestor := parent; ancestor != t.root; ancestor = t.parents[ancestor.ID] {
+			if ancestor.ID == item.ID {
+				return "", fmt.Errorf("cannot move %s under itself", item.ID)
+			}
+		}
+		t.detach(item)
+		t.attach(item, parent, op.Position, "top")
+	case OpDelete:
+		t.detach(item)
+		t.forget(item)
+	case OpComplete:
+		if !item.IsCompleted() {
+			item.CompletedAt = &now
+			item.Completed = true
+		}
+	case OpUncomplete:
+		item.CompletedAt = nil
+		item.Completed = false
+	}
+	return item.ID, nil
+}
+
+func (t *Tree) create(op Operation) (string, error) {
+	parent, err := t.Root(op.ParentID)
+	if err != nil {
+		return "", err
+	}
+	t.created++
+	now := t.now().Unix()
+	item := &workflowy.Item{
+		ID:         fmt.Sprintf("%s%d", PlaceholderPrefix, t.created),
+		Name:       *op.Name,
+		CreatedAt:  now,
+		ModifiedAt: now,
+	}
+	if op.Note != nil {
+		note := *op.Note
+		item.Note = &note
+	}
+	t.attach(item, parent, op.Position, "bottom")
+	return item.ID, nil
+}
+
+// attach adds item to the children of parent, at position or else at the
+// default position of the operation.
+func (t *Tree) attach(item, parent *workflowy.Item, position, defaultPosition string) {
+	if position == "" {
+		position = defaultPosition
+	}
+	if position == "top" {
+		parent.Children = append([]*workflowy.Item{item}, parent.Children...)
+	} else {
+		parent.Children = append(parent.Children, item)
+	}
+	t.parents[item.ID] = parent
+}
+
+func (t *Tree) detach(item *workflowy.Item) {
+	parent := t.parents[item.ID]
+	for i, child := range parent.Children {
+		if child == item {
+			parent.Children = append(parent.Children[:i:i], parent.Children[i+1:]...)
+			break
+		}
+	}
+	delete(t.parents, item.ID)
+}
+
+// forget removes the descendants of a deleted item, so they cannot be the
+// target of later operations.
+func (t *Tree) forget(item *workflowy.Item) {
+	for _, child := range item.Children {
+		delete(t.parents, child.ID)
+		t.forget(child)
+	}
+}
+
+// Result is the predicted outcome of operations on a subtree.
+type Result struct {
+	Diff       string   `json:"diff"` // markdown diff of the subtree, empty when unchanged
+	Added      int      `json:"added"`
+	Removed    int      `json:"removed"`
+	CreatedIDs []string `json:"created_ids,omitempty"` // placeholders of the created nodes
+}
+
+// Run applies operations, in order, to a copy of items and compares the
+// subtree of rootID ("None" for the whole outline) before and after. It stops
+// at the first operation that cannot be applied.
+func Run(items []*workflowy.Item, rootID string, operations []Operation) (*Result, error) {
+	tree := NewTree(items)
+	before, err := renderSubtree(tree, rootID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for i, op := range operations {
+		id, err := tree.Apply(op)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", i+1, op.Op, err)
+		}
+		if op.Op == OpCreate {
+			result.CreatedIDs = append(result.CreatedIDs, id)
+		}
+	}
+
+	after, _ := renderSubtree(tree, rootID) // empty when the root was deleted
+	lines, added, removed := Diff(before, after)
+	result.Added, result.Removed = added, removed
+	if len(lines) > 0 {
+		result.Diff = "```diff\n" + strings.Join(lines, "\n") + "\n```"
+	}
+	return result, nil
+}
+
+// renderSubtree renders the node with the given ID and its descendants, or
+// the whole outline for "None".
+func renderSubtree(tree *Tree, id string) ([]string, error) {
+	root, err := tree.Root(id)
+	if err != nil {
+		return nil, err
+	}
+	if root != tree.root {
+		root = &workflowy.Item{Children: []*workflowy.Item{root}}
+	}
+	return Render(root), nil
+}
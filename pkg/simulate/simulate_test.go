@@ -0,0 +1,102 @@
+package simulate
+
+import (
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr(s string) *string { return &s }
+
+func testItems() []*workflowy.Item {
+	return []*workflowy.Item{
+		{ID: "a", Name: "Projects", Children: []*workflowy.Item{
+			{ID: "a1", Name: "Website"},
+			{ID: "a2", Name: "Garden", Children: []*workflowy.Item{
+				{ID: "a2a", Name: "Plant tomatoes"},
+			}},
+		}},
+		{ID: "b", Name: "Archive"},
+	}
+}
+
+func TestTreeApply(t *testing.T) {
+	items := testItems()
+	tree := NewTree(items)
+
+	id, err := tree.Apply(Operation{Op: OpCreate, ParentID: "a2", Name: ptr("Water")})
+	require.NoError(t, err)
+	assert.Equal(t, "new-1", id)
+
+	_, err = tree.Apply(Operation{Op: OpCreate, ParentID: id, Name: ptr("Daily"), Note: ptr("at dawn")})
+	require.NoError(t, err)
+	_, err = tree.Apply(Operation{Op: OpUpdate, ID: "a1", Name: ptr("Blog")})
+	require.NoError(t, err)
+	_, err = tree.Apply(Operation{Op: OpMove, ID: "a1", ParentID: "b"})
+	require.NoError(t, err)
+	_, err = tree.Apply(Operation{Op: OpComplete, ID: "a2a"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"- Projects",
+		"  - Garden",
+		"    - [x] Plant tomatoes",
+		"    - Water",
+		"      - Daily",
+		"        at dawn",
+		"- Archive",
+		"  - Blog",
+	}, Render(tree.root))
+
+	assert.Equal(t, "Website", items[0].Children[0].Name, "the original items are unchanged")
+	assert.Len(t, items[1].Children, 0)
+}
+
+func TestTreeApplyErrors(t *testing.T) {
+	tree := NewTree(testItems())
+
+	_, err := tree.Apply(Operation{Op: OpMove, ID: "a", ParentID: "a2a"})
+	assert.ErrorContains(t, err, "under itself")
+
+	_, err = tree.Apply(Operation{Op: OpDelete, ID: "a2"})
+	require.NoError(t, err)
+	_, err = tree.Apply(Operation{Op: OpUpdate, ID: "a2a", Name: ptr("x")})
+	assert.ErrorContains(t, err, "not found", "descendants of deleted nodes are gone")
+
+	_, err = tree.Apply(Operation{Op: "rename", ID: "a"})
+	assert.ErrorContains(t, err, "unknown operation")
+	_, err = tree.Apply(Operation{Op: OpCreate, ParentID: "a"})
+	assert.ErrorContains(t, err, "requires a name")
+	_, err = tree.Apply(Operation{Op: OpCreate, ParentID: "a", Name: ptr("x"), Position: "middle"})
+	assert.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	result, err := Run(testItems(), "a", []Operation{
+		{Op: OpCreate, ParentID: "a", Name: ptr("Kitchen"), Position: "top"},
+		{Op: OpDelete, ID: "a2"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new-1"}, result.CreatedIDs)
+	assert.Equal(t, 1, result.Added)
+	assert.Equal(t, 2, result.Removed)
+	assert.Equal(t, "```diff\n - Projects\n+  - Kitchen\n   - Website\n-  - Garden\n-    - Plant tomatoes\n```", result.Diff)
+
+	_, err = Run(testItems(), "None", []Operation{{Op: OpDelete, ID: "missing"}})
+	assert.EqualError(t, err, "operation 1 (delete): item with ID missing not found")
+
+	result, err = Run(testItems(), "None", nil)
+	require.NoError(t, err)
+	assert.Empty(t, result.Diff)
+}
+
+func TestDiffContext(t *testing.T) {
+	before := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12"}
+	after := []string{"1", "x", "3", "4", "5", "6", "7", "8", "9", "10", "y", "12"}
+	lines, added, removed := Diff(before, after)
+	assert.Equal(t, 2, added)
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, []string{" 1", "-2", "+x", " 3", " 4", " 5", "...", " 8", " 9", " 10", "-11", "+y", " 12"}, lines)
+}
@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// tmpSuffix marks the files being written by Put.
+const tmpSuffix = ".tmp"
+
+// lockSuffix marks the files locked by Lock and Append, kept once created.
+const lockSuffix = ".lock"
+
+// FileStorage keeps each value in a file named after its key, in dir for the
+// empty namespace and in a subdirectory named after other namespaces. Files
+// are readable by their owner only, as they can hold the whole outline.
+//
+// Writes are atomic, and appends and updates take an advisory lock on a file
+// next to the value, so that processes sharing the directory, such as a cron
+// job and an interactive command, do not corrupt each other's values.
+type FileStorage struct {
+	mu  sync.Mutex // serializes appends and writes within the process
+	dir string
+}
+
+// NewFileStorage returns the storage in dir, created on the first write.
+func NewFileStorage(dir string) *FileStorage {
+	return &FileStorage{dir: dir}
+}
+
+// Dir returns the directory of the storage.
+func (s *FileStorage) Dir() string {
+	return s.dir
+}
+
+// Path returns the file holding the value of key.
+func (s *FileStorage) Path(namespace, key string) string {
+	return filepath.Join(s.dir, namespace, key)
+}
+
+// Location returns the file holding the value of key.
+func (s *FileStorage) Location(namespace, key string) string {
+	return s.Path(namespace, key)
+}
+
+// Get returns the content of the file of key.
+func (s *FileStorage) Get(namespace, key string) ([]byte, error) {
+	if err := checkKey(namespace, key); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(s.Path(namespace, key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("cannot read %s: %w", s.Path(namespace, key), err)
+	}
+	return data, nil
+}
+
+// Put writes value to a temporary file of its own, renamed to the file of
+// key, so that readers never see a partial value, even with several
+// processes writing it at once.
+func (s *FileStorage) Put(namespace, key string, value []byte) error {
+	if err := checkKey(namespace, key); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := s.Path(namespace, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("cannot create storage directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*"+tmpSuffix)
+	if err != nil {
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	_, err = tmp.Write(value)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Lock takes the advisory lock of the file of key, waiting for other
+// processes to release it.
+func (s *FileStorage) Lock(namespace, key string) (func() error, error) {
+	if err := checkKey(namespace, key); err != nil {
+		return nil, err
+	}
+	path := s.Path(namespace, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("cannot create storage directory: %w", err)
+	}
+	f, err := os.OpenFile(path+lockSuffix, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot lock %s: %w", path, err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("cannot lock %s: %w", path, err)
+	}
+	return func() error {
+		err := unlockFile(f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}, nil
+}
+
+// Append adds data to the end of the file of key, holding its lock.
+func (s *FileStorage) Append(namespace, key string, data []byte) (err error) {
+	if err := checkKey(namespace, key); err != nil {
+		return err
+	}
+	// the file lock is taken first, as Update holds it while calling Put
+	unlock, err := s.Lock(namespace, key)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if unlockErr := unlock(); err == nil {
+			err = unlockErr
+		}
+	}()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := s.Path(namespace, key)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot open %s: %w", path, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// List returns the names of the files of namespace, leaving out
+// subdirectories, files being written and lock files.
+func (s *FileStorage) List(namespace string) ([]string, error) {
+	if err := checkNamespace(namespace); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(s.dir, namespace))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot list %s: %w", filepath.Join(s.dir, namespace), err)
+	}
+	var keys []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), tmpSuffix) || strings.HasSuffix(entry.Name(), lockSuffix) {
+			continue
+		}
+		keys = append(keys, entry.Name())
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete removes the file of key.
+func (s *FileStorage) Delete(namespace, key string) error {
+	if err := checkKey(namespace, key); err != nil {
+		return err
+	}
+	if err := os.Remove(s.Path(namespace, key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot delete %s: %w", s.Path(namespace, key), err)
+	}
+	return nil
+}
+
+// String returns the directory of the storage.
+func (s *FileStorage) String() string {
+	return s.dir
+}
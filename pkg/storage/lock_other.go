@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package storage
+
+import "os"
+
+// lockFile does nothing on platforms without file locks, where only the
+// writes within a process are serialized.
+func lockFile(f *os.File) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"slices"
+	"sync"
+)
+
+// MemoryLocation is the location of a storage kept in memory, lost when the
+// process exits.
+const MemoryLocation = "memory"
+
+// MemoryStorage keeps values in memory, for processes that must not write to
+// disk, such as an MCP server in a read-only container.
+type MemoryStorage struct {
+	mu     sync.Mutex
+	values map[string]map[string][]byte // by namespace, then key
+}
+
+// NewMemoryStorage returns an empty storage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{values: make(map[string]map[string][]byte)}
+}
+
+// Location returns the key, in the memory of the process.
+func (s *MemoryStorage) Location(namespace, key string) string {
+	if namespace == "" {
+		return MemoryLocation + "#" + key
+	}
+	return MemoryLocation + "#" + namespace + "/" + key
+}
+
+// Get returns a copy of the value of key.
+func (s *MemoryStorage) Get(namespace, key string) ([]byte, error) {
+	if err := checkKey(namespace, key); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.values[namespace][key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return slices.Clone(value), nil
+}
+
+// Put sets the value of key to a copy of value.
+func (s *MemoryStorage) Put(namespace, key string, value []byte) error {
+	if err := checkKey(namespace, key); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.namespace(namespace)[key] = append([]byte{}, value...)
+	return nil
+}
+
+// Append adds data to the end of the value of key.
+func (s *MemoryStorage) Append(namespace, key string, data []byte) error {
+	if err := checkKey(namespace, key); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values := s.namespace(namespace)
+	values[key] = append(values[key], data...)
+	return nil
+}
+
+// List returns the keys of namespace, sorted.
+func (s *MemoryStorage) List(namespace string) ([]string, error) {
+	if err := checkNamespace(namespace); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key := range s.values[namespace] {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// Delete removes key.
+func (s *MemoryStorage) Delete(namespace, key string) error {
+	if err := checkKey(namespace, key); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values[namespace], key)
+	return nil
+}
+
+// String returns MemoryLocation.
+func (s *MemoryStorage) String() string {
+	return MemoryLocation
+}
+
+func (s *MemoryStorage) namespace(namespace string) map[string][]byte {
+	values, ok := s.values[namespace]
+	if !ok {
+		values = make(map[string][]byte)
+		s.values[namespace] = values
+	}
+	return values
+}
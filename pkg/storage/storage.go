@@ -0,0 +1,156 @@
+// Package storage keeps the state of the CLI and the MCP server, such as the
+// caches, the undo journal, the ingestion seen-state and the usage statistics,
+// as values addressed by a namespace and a key. Values are kept as files in a
+// directory, so that a server deployment can keep all its state on a single
+// mounted volume, or in memory, so that it writes nothing at all:
+//
+//	WORKFLOWY_STORAGE=/data/workflowy
+//	WORKFLOWY_STORAGE=memory
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mholzen/workflowy/pkg/paths"
+)
+
+// ErrNotFound is returned by Get for a key without a value.
+var ErrNotFound = errors.New("not found")
+
+// Storage keeps values by namespace and key. The empty namespace is the top
+// level; other namespaces group related values. Implementations are safe for
+// concurrent use.
+type Storage interface {
+	// Get returns the value of key, or ErrNotFound.
+	Get(namespace, key string) ([]byte, error)
+	// Put sets the value of key, replacing any previous value at once.
+	Put(namespace, key string, value []byte) error
+	// List returns the keys of namespace, sorted.
+	List(namespace string) ([]string, error)
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(namespace, key string) error
+	// Location describes where the value of key is kept, for messages.
+	Location(namespace, key string) string
+}
+
+// Appender is implemented by storages that can add to the end of a value
+// without rewriting it, for logs such as the undo journal.
+type Appender interface {
+	Append(namespace, key string, data []byte) error
+}
+
+// Locker is implemented by storages shared between processes, such as
+// several CLI invocations at once, to serialize the updates of a value.
+type Locker interface {
+	// Lock waits until no other process holds the lock of key, and takes it
+	// until unlock is called.
+	Lock(namespace, key string) (unlock func() error, err error)
+}
+
+// Update replaces the value of key by what update returns from it, nil if it
+// has none. With a Locker, no other process updates or appends to key in the
+// meantime, so that concurrent updates are not lost.
+func Update(s Storage, namespace, key string, update func(value []byte) ([]byte, error)) (err error) {
+	if l, ok := s.(Locker); ok {
+		unlock, err := l.Lock(namespace, key)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if unlockErr := unlock(); err == nil {
+				err = unlockErr
+			}
+		}()
+	}
+	value, err := s.Get(namespace, key)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	value, err = update(value)
+	if err != nil {
+		return err
+	}
+	return s.Put(namespace, key, value)
+}
+
+// Append adds data to the end of the value of key, creating it if needed.
+func Append(s Storage, namespace, key string, data []byte) error {
+	if a, ok := s.(Appender); ok {
+		return a.Append(namespace, key, data)
+	}
+	value, err := s.Get(namespace, key)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return s.Put(namespace, key, append(value, data...))
+}
+
+// Open returns the storage at location: memory for a new MemoryStorage, or a
+// directory. A leading ~ stands for the home directory.
+func Open(location string) (Storage, error) {
+	if location == MemoryLocation {
+		return NewMemoryStorage(), nil
+	}
+	if location == "" {
+		return nil, fmt.Errorf("storage location is empty")
+	}
+	return NewFileStorage(paths.ExpandHome(location)), nil
+}
+
+var (
+	mu             sync.Mutex
+	opened         = make(map[string]Storage)
+	defaultStorage Storage // set by SetDefault
+)
+
+// SetDefault makes Default return s whatever the configured location, such as
+// a MemoryStorage for a server that must not write to disk. A nil s restores
+// the configured location.
+func SetDefault(s Storage) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultStorage = s
+}
+
+// Default returns the storage of the running process: the storage given to
+// SetDefault, $WORKFLOWY_STORAGE, the storage set in the configuration file,
+// or the cache directory. Storages are opened once per location.
+func Default() (Storage, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if defaultStorage != nil {
+		return defaultStorage, nil
+	}
+	location, err := paths.Storage()
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := opened[location]; ok {
+		return s, nil
+	}
+	s, err := Open(location)
+	if err != nil {
+		return nil, err
+	}
+	opened[location] = s
+	return s, nil
+}
+
+// checkKey rejects names that would escape a directory of a FileStorage, so
+// that all storages accept the same keys.
+func checkKey(namespace, key string) error {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	return checkNamespace(namespace)
+}
+
+func checkNamespace(namespace string) error {
+	if namespace == "." || namespace == ".." || strings.ContainsAny(namespace, `/\`) {
+		return fmt.Errorf("invalid storage namespace %q", namespace)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/paths"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStorage checks the behavior common to all storages.
+func testStorage(t *testing.T, s Storage) {
+	_, err := s.Get("", "missing.json")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, s.Put("", "b.json", []byte("first")))
+	require.NoError(t, s.Put("", "b.json", []byte("second")))
+	require.NoError(t, s.Put("", "a.json", []byte("{}")))
+	require.NoError(t, s.Put("ingest", "a.json", []byte("other")))
+
+	value, err := s.Get("", "b.json")
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(value))
+
+	keys, err := s.List("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.json", "b.json"}, keys)
+	keys, err = s.List("ingest")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.json"}, keys)
+	keys, err = s.List("empty")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	require.NoError(t, Append(s, "", "log.jsonl", []byte("1\n")))
+	require.NoError(t, Append(s, "", "log.jsonl", []byte("2\n")))
+	value, err = s.Get("", "log.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, "1\n2\n", string(value))
+
+	require.NoError(t, s.Delete("", "b.json"))
+	require.NoError(t, s.Delete("", "b.json"), "deleting a missing key is not an error")
+	_, err = s.Get("", "b.json")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Error(t, s.Put("", "../escape", []byte("x")))
+	assert.Error(t, s.Put("..", "key", []byte("x")))
+	_, err = s.List("a/b")
+	assert.Error(t, err)
+}
+
+func TestFileStorage(t *testing.T) {
+	dir := t.TempDir()
+	s := NewFileStorage(dir)
+	testStorage(t, s)
+
+	assert.Equal(t, filepath.Join(dir, "a.json"), s.Location("", "a.json"))
+	assert.FileExists(t, filepath.Join(dir, "ingest", "a.json"))
+	info, err := os.Stat(filepath.Join(dir, "a.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestFileStorage_ListSkipsPartialWrites(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json.tmp"), []byte("{"), 0600))
+	keys, err := NewFileStorage(dir).List("")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestFileStorage_ConcurrentUpdates(t *testing.T) {
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// a storage per writer, as separate processes would have
+			s := NewFileStorage(dir)
+			assert.NoError(t, Update(s, "", "count", func(value []byte) ([]byte, error) {
+				n, _ := strconv.Atoi(string(value))
+				return []byte(strconv.Itoa(n + 1)), nil
+			}))
+			assert.NoError(t, s.Put("", "last", []byte(strings.Repeat("x", 1<<16))))
+		}()
+	}
+	wg.Wait()
+
+	s := NewFileStorage(dir)
+	value, err := s.Get("", "count")
+	require.NoError(t, err)
+	assert.Equal(t, "20", string(value), "no update is lost")
+	value, err = s.Get("", "last")
+	require.NoError(t, err)
+	assert.Len(t, value, 1<<16)
+	keys, err := s.List("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"count", "last"}, keys, "no temporary or lock file is listed")
+}
+
+func TestUpdate_KeepsValueOnError(t *testing.T) {
+	s := NewMemoryStorage()
+	require.NoError(t, s.Put("", "a", []byte("1")))
+	assert.Error(t, Update(s, "", "a", func(value []byte) ([]byte, error) {
+		return nil, errors.New("failed")
+	}))
+	value, err := s.Get("", "a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(value))
+}
+
+func TestMemoryStorage(t *testing.T) {
+	s := NewMemoryStorage()
+	testStorage(t, s)
+
+	value := []byte("original")
+	require.NoError(t, s.Put("", "key", value))
+	value[0] = 'O'
+	got, err := s.Get("", "key")
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(got), "values are copied")
+	assert.Equal(t, "memory#ingest/key", s.Location("ingest", "key"))
+}
+
+func TestOpen(t *testing.T) {
+	s, err := Open("/data/workflowy")
+	require.NoError(t, err)
+	assert.Equal(t, "/data/workflowy", s.(*FileStorage).Dir())
+
+	_, err = Open("")
+	assert.Error(t, err)
+
+	s, err = Open("memory")
+	require.NoError(t, err)
+	assert.IsType(t, &MemoryStorage{}, s)
+}
+
+func TestDefault(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(paths.CacheDirEnv, dir)
+	t.Setenv(paths.StorageEnv, "")
+	s, err := Default()
+	require.NoError(t, err)
+	assert.Equal(t, dir, s.(*FileStorage).Dir())
+
+	again, err := Default()
+	require.NoError(t, err)
+	assert.Same(t, s, again)
+
+	t.Setenv(paths.StorageEnv, filepath.Join(dir, "state"))
+	s, err = Default()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "state"), s.(*FileStorage).Dir())
+}
+
+func TestSetDefault(t *testing.T) {
+	t.Setenv(paths.CacheDirEnv, t.TempDir())
+	t.Setenv(paths.StorageEnv, "")
+	memory := NewMemoryStorage()
+	SetDefault(memory)
+	s, err := Default()
+	require.NoError(t, err)
+	assert.Same(t, memory, s)
+
+	SetDefault(nil)
+	s, err = Default()
+	require.NoError(t, err)
+	assert.IsType(t, &FileStorage{}, s)
+}
@@ -0,0 +1,111 @@
+package terminal
+
+import "unicode/utf8"
+
+// Key is a key pressed, decoded from the bytes read from a terminal.
+type Key int
+
+const (
+	KeyRune Key = iota // a character
+	KeyEnter
+	KeyCancel // escape, ctrl-c, ctrl-g
+	KeyUp     // up arrow, ctrl-p, ctrl-k
+	KeyDown   // down arrow, ctrl-n
+	KeyLeft
+	KeyRight
+	KeyTab
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+	KeyBackspace
+	KeyClear // ctrl-u
+	KeyNone  // ignored
+)
+
+// Event is a key pressed, with its character for KeyRune.
+type Event struct {
+	Key  Key
+	Rune rune
+}
+
+// Decode returns the keys pressed in data, as read at once from a terminal
+// in raw mode.
+func Decode(data []byte) []Event {
+	var events []Event
+	for len(data) > 0 {
+		switch data[0] {
+		case '\r', '\n':
+			events = append(events, Event{Key: KeyEnter})
+		case 3, 7: // ctrl-c, ctrl-g
+			events = append(events, Event{Key: KeyCancel})
+		case 16, 11: // ctrl-p, ctrl-k
+			events = append(events, Event{Key: KeyUp})
+		case 14: // ctrl-n
+			events = append(events, Event{Key: KeyDown})
+		case '\t':
+			events = append(events, Event{Key: KeyTab})
+		case 127, 8:
+			events = append(events, Event{Key: KeyBackspace})
+		case 21:
+			events = append(events, Event{Key: KeyClear})
+		case 27:
+			key, length := decodeEscape(data)
+			events = append(events, Event{Key: key})
+			data = data[length:]
+			continue
+		default:
+			r, size := utf8.DecodeRune(data)
+			key := KeyRune
+			if r < ' ' || r == utf8.RuneError {
+				key = KeyNone
+			}
+			events = append(events, Event{Key: key, Rune: r})
+			data = data[size:]
+			continue
+		}
+		data = data[1:]
+	}
+	return events
+}
+
+// decodeEscape decodes the escape sequence at the start of data, returning
+// the key and the length of the sequence. An escape on its own cancels.
+func decodeEscape(data []byte) (Key, int) {
+	if len(data) < 3 || (data[1] != '[' && data[1] != 'O') {
+		return KeyCancel, 1
+	}
+	switch data[2] {
+	case 'A':
+		return KeyUp, 3
+	case 'B':
+		return KeyDown, 3
+	case 'C':
+		return KeyRight, 3
+	case 'D':
+		return KeyLeft, 3
+	case 'H':
+		return KeyHome, 3
+	case 'F':
+		return KeyEnd, 3
+	case '1', '4', '5', '6':
+		if len(data) >= 4 && data[3] == '~' {
+			switch data[2] {
+			case '1':
+				return KeyHome, 4
+			case '4':
+				return KeyEnd, 4
+			case '5':
+				return KeyPageUp, 4
+			}
+			return KeyPageDown, 4
+		}
+	}
+	// skip other sequences, such as function keys
+	for i := 2; i < len(data); i++ {
+		if data[i] >= 0x40 && data[i] <= 0x7e {
+			return KeyNone, i + 1
+		}
+	}
+	return KeyNone, len(data)
+}
@@ -0,0 +1,27 @@
+package terminal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	events := Decode([]byte("aé\x1b[A\x1b[B\x1b[5~\x1b[C\x1b[D\x1bOH\x1b[4~\x1b[15~\t\x7f\r\x1b"))
+	assert.Equal(t, []Event{
+		{Key: KeyRune, Rune: 'a'},
+		{Key: KeyRune, Rune: 'é'},
+		{Key: KeyUp},
+		{Key: KeyDown},
+		{Key: KeyPageUp},
+		{Key: KeyRight},
+		{Key: KeyLeft},
+		{Key: KeyHome},
+		{Key: KeyEnd},
+		{Key: KeyNone},
+		{Key: KeyTab},
+		{Key: KeyBackspace},
+		{Key: KeyEnter},
+		{Key: KeyCancel},
+	}, events)
+}
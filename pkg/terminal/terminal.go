@@ -0,0 +1,63 @@
+// Package terminal puts a terminal in raw mode for full-screen programs, such
+// as the picker and the browser, and decodes the keys read from it.
+//
+// The terminal is set up with stty, so raw mode is not available on Windows.
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Size is the size of a terminal, in characters.
+type Size struct {
+	Width, Height int
+}
+
+// GetSize returns the size of tty, as stty reports it.
+func GetSize(tty *os.File) (Size, error) {
+	output, err := stty(tty, "size")
+	if err != nil {
+		return Size{}, err
+	}
+	var size Size
+	if _, err := fmt.Sscan(output, &size.Height, &size.Width); err != nil || size.Width <= 0 || size.Height <= 0 {
+		return Size{}, fmt.Errorf("cannot read the terminal size from %q", output)
+	}
+	return size, nil
+}
+
+// FullScreen sets tty, such as /dev/tty, to raw mode and switches to the
+// alternate screen buffer. restore switches back and restores the settings
+// of the terminal.
+func FullScreen(tty *os.File) (restore func(), err error) {
+	if runtime.GOOS == "windows" {
+		return nil, fmt.Errorf("full-screen mode is not available on Windows")
+	}
+	saved, err := stty(tty, "-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty(tty, "raw", "-echo"); err != nil {
+		return nil, err
+	}
+	fmt.Fprint(tty, "\x1b[?1049h")
+	return func() {
+		fmt.Fprint(tty, "\x1b[?1049l")
+		stty(tty, saved)
+	}, nil
+}
+
+// stty runs stty on tty with args and returns its output.
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("cannot set up the terminal: stty %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
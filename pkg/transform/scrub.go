@@ -0,0 +1,16 @@
+package transform
+
+import "github.com/mholzen/workflowy/pkg/secrets"
+
+// NewScrubber returns a transformer that masks the secrets found by detectors,
+// keeping their last four characters (see secrets.Mask).
+func NewScrubber(detectors []secrets.Detector) Transformer {
+	return func(s string) (string, error) {
+		return secrets.Mask(s, detectors), nil
+	}
+}
+
+// Scrub masks the secrets found by the built-in detectors of the exposure report.
+func Scrub(s string) (string, error) {
+	return secrets.Mask(s, secrets.DefaultDetectors()), nil
+}
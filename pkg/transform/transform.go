@@ -8,23 +8,30 @@ import (
 	"sort"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
+	"github.com/mholzen/workflowy/pkg/patch"
 	"github.com/mholzen/workflowy/pkg/workflowy"
 )
 
 type Transformer func(string) (string, error)
 
 var BuiltinTransformers = map[string]Transformer{
-	"lowercase":      Lowercase,
-	"uppercase":      Uppercase,
-	"capitalize":     Capitalize,
-	"title":          TitleCase,
-	"trim":           Trim,
-	"no-punctuation": RemovePunctuation,
-	"no-whitespace":  RemoveWhitespace,
+	"lowercase":         Lowercase,
+	"uppercase":         Uppercase,
+	"capitalize":        Capitalize,
+	"title":             TitleCase,
+	"trim":              Trim,
+	"no-punctuation":    RemovePunctuation,
+	"no-whitespace":     RemoveWhitespace,
+	"strip-emoji":       StripEmoji,
+	"normalize-unicode": NormalizeUnicode,
+	"slug":              Slug,
+	"scrub":             Scrub,
+	"unfurl":            Unfurl,
 }
 
 func ListBuiltins() []string {
@@ -61,9 +68,11 @@ type Result struct {
 	New         string          `json:"new"`
 	Applied     bool            `json:"applied"`
 	Skipped     bool            `json:"skipped,omitempty"`
+	Failed      bool            `json:"failed,omitempty"`
 	SkipReason  string          `json:"skip_reason,omitempty"`
 	Error       error           `json:"error,omitempty"`
 	CreatedID   string          `json:"created_id,omitempty"`
+	Breadcrumb  string          `json:"breadcrumb,omitempty"`
 }
 
 func (r Result) String() string {
@@ -78,9 +87,22 @@ func (r Result) String() string {
 	if r.CreatedID != "" {
 		result += " [child: " + r.CreatedID + "]"
 	}
+	if r.Breadcrumb != "" {
+		result += " (in " + r.Breadcrumb + ")"
+	}
 	return result
 }
 
+// NodeID returns the ID of the node of the result.
+func (r *Result) NodeID() string {
+	return r.ID
+}
+
+// SetBreadcrumb sets the ancestors shown with the result (see workflowy.AddBreadcrumbs).
+func (r *Result) SetBreadcrumb(breadcrumb string) {
+	r.Breadcrumb = breadcrumb
+}
+
 func CollectTransformations(items []*workflowy.Item, opts Options, depth int, results *[]Result) {
 	if opts.Depth >= 0 && depth > opts.Depth {
 		return
@@ -144,12 +166,17 @@ func Trim(s string) (string, error) {
 }
 
 func Capitalize(s string) (string, error) {
-	if len(s) == 0 {
-		return s, nil
+	return capitalize(s, nil), nil
+}
+
+// capitalize title-cases the first rune of s using the given case mapping and leaves
+// the remaining bytes untouched, so invalid UTF-8 and combining marks are preserved.
+func capitalize(s string, special unicode.SpecialCase) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
 	}
-	runes := []rune(s)
-	runes[0] = unicode.ToUpper(runes[0])
-	return string(runes), nil
+	return string(special.ToTitle(r)) + s[size:]
 }
 
 func TitleCase(s string) (string, error) {
@@ -157,6 +184,50 @@ func TitleCase(s string) (string, error) {
 	return caser.String(s), nil
 }
 
+// localeTransformers are the built-ins whose result depends on the language.
+var localeTransformers = map[string]func(language.Tag) Transformer{
+	"lowercase":  LowercaseLang,
+	"uppercase":  UppercaseLang,
+	"capitalize": CapitalizeLang,
+	"title":      TitleCaseLang,
+}
+
+// LowercaseLang returns a lowercase transformer using the casing rules of tag.
+func LowercaseLang(tag language.Tag) Transformer {
+	caser := cases.Lower(tag)
+	return func(s string) (string, error) {
+		return caser.String(s), nil
+	}
+}
+
+// UppercaseLang returns an uppercase transformer using the casing rules of tag.
+func UppercaseLang(tag language.Tag) Transformer {
+	caser := cases.Upper(tag)
+	return func(s string) (string, error) {
+		return caser.String(s), nil
+	}
+}
+
+// CapitalizeLang returns a capitalize transformer using the casing rules of tag
+// (e.g. "i" becomes "İ" in Turkish and Azerbaijani).
+func CapitalizeLang(tag language.Tag) Transformer {
+	var special unicode.SpecialCase
+	if base, _ := tag.Base(); base.String() == "tr" || base.String() == "az" {
+		special = unicode.TurkishCase
+	}
+	return func(s string) (string, error) {
+		return capitalize(s, special), nil
+	}
+}
+
+// TitleCaseLang returns a title case transformer using the casing rules of tag.
+func TitleCaseLang(tag language.Tag) Transformer {
+	caser := cases.Title(tag)
+	return func(s string) (string, error) {
+		return caser.String(s), nil
+	}
+}
+
 func RemovePunctuation(s string) (string, error) {
 	return strings.Map(func(r rune) rune {
 		if unicode.IsPunct(r) {
@@ -197,6 +268,24 @@ func truncate(s string, maxLen int) string {
 	return s[:maxLen] + "..."
 }
 
+// ResolveTransformerLang is like ResolveTransformer, but the case transforms use the
+// casing rules of lang (a BCP 47 tag such as "tr" or "nl"). An empty lang uses the defaults.
+func ResolveTransformerLang(transformName, execCmd, lang string) (Transformer, error) {
+	if lang == "" || execCmd != "" {
+		return ResolveTransformer(transformName, execCmd)
+	}
+
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return nil, fmt.Errorf("invalid language %q: %w", lang, err)
+	}
+
+	if newTransformer, ok := localeTransformers[transformName]; ok {
+		return newTransformer(tag), nil
+	}
+	return ResolveTransformer(transformName, execCmd)
+}
+
 func ResolveTransformer(transformName, execCmd string) (Transformer, error) {
 	if execCmd != "" && transformName != "" {
 		return nil, fmt.Errorf("cannot specify both transform name and exec")
@@ -243,6 +332,18 @@ func BuildUpdateRequest(result *Result) *workflowy.UpdateNodeRequest {
 	return req
 }
 
+// UndoPatch returns the edits that revert the applied results, for results
+// that replaced a field rather than creating a child.
+func UndoPatch(results []Result) []patch.Edit {
+	var edits []patch.Edit
+	for _, result := range results {
+		if result.Applied && result.CreatedID == "" {
+			edits = append(edits, patch.Edit{ID: result.ID, Field: result.Field, Old: result.Original, New: result.New})
+		}
+	}
+	return patch.Reverse(edits)
+}
+
 type Applier interface {
 	UpdateNode(ctx context.Context, itemID string, req *workflowy.UpdateNodeRequest) (*workflowy.UpdateNodeResponse, error)
 	CreateNode(ctx context.Context, req *workflowy.CreateNodeRequest) (*workflowy.CreateNodeResponse, error)
@@ -258,6 +359,11 @@ func ApplyResultsWithOptions(ctx context.Context, client Applier, results []Resu
 		if result.Skipped {
 			continue
 		}
+		if ctx.Err() != nil {
+			result.Skipped = true
+			result.SkipReason = "cancelled"
+			continue
+		}
 
 		if asChild {
 			position := "top"
@@ -273,6 +379,7 @@ func ApplyResultsWithOptions(ctx context.Context, client Applier, results []Resu
 			resp, err := client.CreateNode(ctx, req)
 			if err != nil {
 				result.Skipped = true
+				result.Failed = true
 				result.SkipReason = fmt.Sprintf("create child failed: %v", err)
 				continue
 			}
@@ -282,6 +389,7 @@ func ApplyResultsWithOptions(ctx context.Context, client Applier, results []Resu
 			req := BuildUpdateRequest(result)
 			if _, err := client.UpdateNode(ctx, result.ID, req); err != nil {
 				result.Skipped = true
+				result.Failed = true
 				result.SkipReason = fmt.Sprintf("update failed: %v", err)
 				continue
 			}
@@ -298,6 +406,7 @@ type SplitResult struct {
 	CreatedIDs []string `json:"created_ids,omitempty"`
 	Applied    bool     `json:"applied"`
 	Skipped    bool     `json:"skipped,omitempty"`
+	Failed     bool     `json:"failed,omitempty"`
 	SkipReason string   `json:"skip_reason,omitempty"`
 }
 
@@ -372,6 +481,11 @@ func ApplySplitResults(ctx context.Context, client Applier, results []SplitResul
 		if result.Skipped {
 			continue
 		}
+		if ctx.Err() != nil {
+			result.Skipped = true
+			result.SkipReason = "cancelled"
+			continue
+		}
 
 		createdIDs := make([]string, 0, len(result.Parts))
 		for j := len(result.Parts) - 1; j >= 0; j-- {
@@ -385,6 +499,7 @@ func ApplySplitResults(ctx context.Context, client Applier, results []SplitResul
 			resp, err := client.CreateNode(ctx, req)
 			if err != nil {
 				result.Skipped = true
+				result.Failed = true
 				result.SkipReason = fmt.Sprintf("create failed for part %d: %v", j, err)
 				break
 			}
@@ -270,3 +270,88 @@ func TestSplitResult_String(t *testing.T) {
 func strPtr(s string) *string {
 	return &s
 }
+
+func TestCapitalize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", ""},
+		{"hello", "Hello"},
+		{"élan", "Élan"},
+		{"ǆungla", "ǅungla"},
+		{"привет", "Привет"},
+		{"日本", "日本"},
+		{"\xffabc", "\xffabc"},
+	}
+
+	for _, tt := range tests {
+		got, err := Capitalize(tt.input)
+		if err != nil {
+			t.Fatalf("Capitalize(%q) error = %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("Capitalize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestResolveTransformerLang(t *testing.T) {
+	tests := []struct {
+		name  string
+		lang  string
+		input string
+		want  string
+	}{
+		{"capitalize", "", "istanbul", "Istanbul"},
+		{"capitalize", "tr", "istanbul", "İstanbul"},
+		{"capitalize", "az", "ilham", "İlham"},
+		{"uppercase", "tr", "kiraz", "KİRAZ"},
+		{"lowercase", "tr", "KIRAZ", "kıraz"},
+		{"title", "nl", "ijsselmeer", "IJsselmeer"},
+		{"trim", "tr", "  x  ", "x"},
+	}
+
+	for _, tt := range tests {
+		transformer, err := ResolveTransformerLang(tt.name, "", tt.lang)
+		if err != nil {
+			t.Fatalf("ResolveTransformerLang(%q, %q) error = %v", tt.name, tt.lang, err)
+		}
+		got, err := transformer(tt.input)
+		if err != nil {
+			t.Fatalf("%s(%q) error = %v", tt.name, tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s [%s](%q) = %q, want %q", tt.name, tt.lang, tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := ResolveTransformerLang("capitalize", "", "not a language"); err == nil {
+		t.Error("expected error for invalid language")
+	}
+}
+
+func TestScrub(t *testing.T) {
+	got, err := Scrub("router password: hunter2000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "router password: ******2000"; got != want {
+		t.Errorf("Scrub() = %q, want %q", got, want)
+	}
+}
+
+func TestUndoPatch(t *testing.T) {
+	results := []Result{
+		{ID: "a", Field: "name", Original: "pwd=hunter2000", New: "pwd=******2000", Applied: true},
+		{ID: "b", Field: "note", Original: "x", New: "y", Skipped: true},
+		{ID: "c", Field: "name", Original: "x", New: "y", Applied: true, CreatedID: "child"},
+	}
+	undo := UndoPatch(results)
+	if len(undo) != 1 {
+		t.Fatalf("UndoPatch() returned %d edits, want 1", len(undo))
+	}
+	if undo[0].ID != "a" || undo[0].Old != "pwd=******2000" || undo[0].New != "pwd=hunter2000" {
+		t.Errorf("UndoPatch()[0] = %+v, want the reverse of a", undo[0])
+	}
+}
@@ -0,0 +1,142 @@
+package transform
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/cache"
+)
+
+const (
+	// UnfurlTimeout bounds each page fetch made by the unfurl transform
+	UnfurlTimeout = 5 * time.Second
+	// maxTitleBytes limits how much of a page is read when looking for its title
+	maxTitleBytes = 512 * 1024
+)
+
+var (
+	urlPattern   = regexp.MustCompile(`https?://[^\s<>()\[\]"]+`)
+	titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+)
+
+// TitleFetcher returns the title of the page at url.
+type TitleFetcher func(url string) (string, error)
+
+// NewUnfurler returns a transformer that rewrites bare URLs as markdown links
+// titled with the page title. URLs already inside a markdown link are left alone,
+// as are URLs whose title cannot be fetched.
+func NewUnfurler(fetch TitleFetcher) Transformer {
+	return func(s string) (string, error) {
+		var b strings.Builder
+		var lastErr error
+		unfurled := 0
+		last := 0
+
+		for _, loc := range urlPattern.FindAllStringIndex(s, -1) {
+			start, end := loc[0], loc[1]
+			url := strings.TrimRight(s[start:end], ".,;:!?'")
+			end = start + len(url)
+
+			if start > 0 && (s[start-1] == '[' || strings.HasSuffix(s[:start], "](")) {
+				continue
+			}
+
+			title, err := fetch(url)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if title == "" {
+				continue
+			}
+
+			b.WriteString(s[last:start])
+			b.WriteString("[" + escapeLinkText(title) + "](" + url + ")")
+			last = end
+			unfurled++
+		}
+
+		if unfurled == 0 && lastErr != nil {
+			return "", lastErr
+		}
+		b.WriteString(s[last:])
+		return b.String(), nil
+	}
+}
+
+func escapeLinkText(s string) string {
+	s = strings.ReplaceAll(s, "[", "\\[")
+	return strings.ReplaceAll(s, "]", "\\]")
+}
+
+// FetchTitle returns a TitleFetcher that reads the <title> of HTML pages with client.
+func FetchTitle(client *http.Client) TitleFetcher {
+	return func(url string) (string, error) {
+		resp, err := client.Get(url)
+		if err != nil {
+			return "", fmt.Errorf("cannot fetch %s: %w", url, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			return "", fmt.Errorf("cannot fetch %s: %s", url, resp.Status)
+		}
+
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxTitleBytes))
+		if err != nil {
+			return "", fmt.Errorf("cannot read %s: %w", url, err)
+		}
+
+		match := titlePattern.FindSubmatch(body)
+		if match == nil {
+			return "", nil
+		}
+		return strings.Join(strings.Fields(html.UnescapeString(string(match[1]))), " "), nil
+	}
+}
+
+// CachedTitleFetcher wraps fetch with a title cache. Titles are only cached when
+// the fetch succeeds.
+func CachedTitleFetcher(fetch TitleFetcher, titles *cache.TitleCache) TitleFetcher {
+	return func(url string) (string, error) {
+		if title, ok := titles.Get(url); ok {
+			slog.Debug("unfurl cache hit", "url", url)
+			return title, nil
+		}
+		title, err := fetch(url)
+		if err != nil {
+			return "", err
+		}
+		if err := titles.Put(url, title); err != nil {
+			slog.Warn("cannot save title cache", "error", err)
+		}
+		return title, nil
+	}
+}
+
+var (
+	defaultUnfurler     Transformer
+	defaultUnfurlerOnce sync.Once
+)
+
+// Unfurl rewrites bare URLs as markdown links titled with the page title, fetching
+// titles with a timeout and caching them in ~/.workflowy/title-cache.json.
+func Unfurl(s string) (string, error) {
+	defaultUnfurlerOnce.Do(func() {
+		titles, err := cache.LoadTitleCache()
+		if err != nil {
+			slog.Warn("cannot load title cache, using memory only", "error", err)
+			titles = cache.NewTitleCache("")
+		}
+		client := &http.Client{Timeout: UnfurlTimeout}
+		defaultUnfurler = NewUnfurler(CachedTitleFetcher(FetchTitle(client), titles))
+	})
+	return defaultUnfurler(s)
+}
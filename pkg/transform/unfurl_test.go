@@ -0,0 +1,99 @@
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/cache"
+)
+
+func TestUnfurler(t *testing.T) {
+	titles := map[string]string{
+		"https://example.com/a": "Page A",
+		"https://example.com/b": "B [draft]",
+	}
+	fetch := func(url string) (string, error) {
+		title, ok := titles[url]
+		if !ok {
+			return "", errors.New("not found")
+		}
+		return title, nil
+	}
+	unfurl := NewUnfurler(fetch)
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"no urls", "plain text", "plain text", false},
+		{"bare url", "read https://example.com/a", "read [Page A](https://example.com/a)", false},
+		{"trailing punctuation", "see https://example.com/a.", "see [Page A](https://example.com/a).", false},
+		{"escapes brackets", "https://example.com/b", "[B \\[draft\\]](https://example.com/b)", false},
+		{"already linked", "[Page A](https://example.com/a)", "[Page A](https://example.com/a)", false},
+		{"partial failure", "https://example.com/a https://missing.test", "[Page A](https://example.com/a) https://missing.test", false},
+		{"all failed", "https://missing.test", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := unfurl(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unfurl(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("unfurl(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchTitle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			fmt.Fprint(w, "<html><head><TITLE>\n  Tom &amp; Jerry\n</TITLE></head></html>")
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+			fmt.Fprint(w, "<title>late</title>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetch := FetchTitle(&http.Client{Timeout: 50 * time.Millisecond})
+
+	title, err := fetch(server.URL + "/page")
+	if err != nil || title != "Tom & Jerry" {
+		t.Errorf("fetch(/page) = %q, %v; want %q", title, err, "Tom & Jerry")
+	}
+	if _, err := fetch(server.URL + "/missing"); err == nil {
+		t.Error("expected error for 404")
+	}
+	if _, err := fetch(server.URL + "/slow"); err == nil {
+		t.Error("expected timeout error")
+	}
+}
+
+func TestCachedTitleFetcher(t *testing.T) {
+	calls := 0
+	fetch := CachedTitleFetcher(func(url string) (string, error) {
+		calls++
+		return "Title", nil
+	}, cache.NewTitleCache(""))
+
+	for i := 0; i < 3; i++ {
+		if title, _ := fetch("https://example.com"); title != "Title" {
+			t.Fatalf("title = %q, want %q", title, "Title")
+		}
+	}
+	if calls != 1 {
+		t.Errorf("fetch called %d times, want 1", calls)
+	}
+}
@@ -0,0 +1,107 @@
+package transform
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const zeroWidthJoiner = '\u200D'
+
+// emojiRanges covers pictographs, dingbats, flags, skin tone modifiers and the
+// invisible code points used to build emoji sequences.
+var emojiRanges = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x200D, Hi: 0x200D, Stride: 1}, // zero width joiner
+		{Lo: 0x20E3, Hi: 0x20E3, Stride: 1}, // combining enclosing keycap
+		{Lo: 0x2300, Hi: 0x23FF, Stride: 1}, // miscellaneous technical (⌚, ⏰)
+		{Lo: 0x2600, Hi: 0x27BF, Stride: 1}, // miscellaneous symbols, dingbats
+		{Lo: 0x2B00, Hi: 0x2BFF, Stride: 1}, // arrows and shapes (⭐, ⬛)
+		{Lo: 0xFE00, Hi: 0xFE0F, Stride: 1}, // variation selectors
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1F000, Hi: 0x1FAFF, Stride: 1}, // pictographs, emoticons, flags, modifiers
+		{Lo: 0xE0020, Hi: 0xE007F, Stride: 1}, // tag sequences
+	},
+}
+
+// zeroWidth lists invisible characters commonly left behind by copy and paste.
+var zeroWidth = map[rune]bool{
+	'\u00AD': true, // soft hyphen
+	'\u200B': true, // zero width space
+	'\u200C': true, // zero width non-joiner
+	'\u200D': true, // zero width joiner
+	'\u2060': true, // word joiner
+	'\uFEFF': true, // zero width no-break space (byte order mark)
+}
+
+func isEmoji(r rune) bool {
+	return unicode.Is(emojiRanges, r)
+}
+
+// StripEmoji removes emoji and pictographic symbols. Spaces left doubled, leading
+// or trailing by a removal are collapsed.
+func StripEmoji(s string) (string, error) {
+	out := make([]rune, 0, len(s))
+	removed := false
+	for _, r := range s {
+		if isEmoji(r) {
+			removed = true
+			continue
+		}
+		if removed {
+			if r == ' ' && (len(out) == 0 || out[len(out)-1] == ' ' || out[len(out)-1] == '\n') {
+				continue
+			}
+			if r == '\n' {
+				out = []rune(strings.TrimRight(string(out), " "))
+			}
+			removed = false
+		}
+		out = append(out, r)
+	}
+	if removed {
+		return strings.TrimRight(string(out), " "), nil
+	}
+	return string(out), nil
+}
+
+// NormalizeUnicode applies NFC normalization and removes zero-width characters.
+// Zero width joiners between two emoji are kept so emoji sequences stay intact.
+func NormalizeUnicode(s string) (string, error) {
+	runes := []rune(norm.NFC.String(s))
+	out := make([]rune, 0, len(runes))
+	for i, r := range runes {
+		if !zeroWidth[r] {
+			out = append(out, r)
+			continue
+		}
+		if r == zeroWidthJoiner && i > 0 && i+1 < len(runes) && isEmoji(runes[i-1]) && isEmoji(runes[i+1]) {
+			out = append(out, r)
+		}
+	}
+	return string(out), nil
+}
+
+// Slug converts s to a lowercase, hyphen-separated identifier suitable for URLs
+// and file names. Accents are removed; letters of other scripts are kept.
+func Slug(s string) (string, error) {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range norm.NFKD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			pendingHyphen = true
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,87 @@
+package transform
+
+import "testing"
+
+func TestStripEmoji(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"no emoji", "plain text", "plain text"},
+		{"leading", "🎉 Party", "Party"},
+		{"trailing", "Done ✅", "Done"},
+		{"middle", "a 🚀 b", "a b"},
+		{"adjacent to word", "hot🔥dog", "hotdog"},
+		{"zwj sequence", "family 👨\u200D👩\u200D👧 photo", "family photo"},
+		{"flag", "trip 🇫🇷 notes", "trip notes"},
+		{"skin tone and variation selector", "ok 👍🏽 ❤️ yes", "ok yes"},
+		{"multiline", "first 🎯\nsecond", "first\nsecond"},
+		{"keeps other symbols", "© 2024 — 50%", "© 2024 — 50%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StripEmoji(tt.input)
+			if err != nil {
+				t.Fatalf("StripEmoji() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("StripEmoji(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeUnicode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"already normalized", "caf\u00e9", "caf\u00e9"},
+		{"decomposed to composed", "cafe\u0301", "caf\u00e9"},
+		{"zero width space", "hello\u200Bworld", "helloworld"},
+		{"byte order mark", "\uFEFFtitle", "title"},
+		{"soft hyphen and word joiner", "co\u00ADop\u2060erate", "cooperate"},
+		{"stray joiner", "a\u200Db", "ab"},
+		{"keeps emoji sequences", "👨\u200D👩\u200D👧", "👨\u200D👩\u200D👧"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeUnicode(tt.input)
+			if err != nil {
+				t.Fatalf("NormalizeUnicode() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeUnicode(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Hello World", "hello-world"},
+		{"  Q3 Planning: Goals & Risks!  ", "q3-planning-goals-risks"},
+		{"Café déjà vu", "cafe-deja-vu"},
+		{"already-a-slug", "already-a-slug"},
+		{"Привет мир", "привет-мир"},
+		{"🎉 Launch 🚀", "launch"},
+		{"---", ""},
+	}
+
+	for _, tt := range tests {
+		got, err := Slug(tt.input)
+		if err != nil {
+			t.Fatalf("Slug(%q) error = %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("Slug(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
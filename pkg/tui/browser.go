@@ -0,0 +1,348 @@
+// Package tui is a terminal browser for an outline: nodes are shown as a
+// tree, expanded and collapsed with the arrow keys, and searched by name.
+//
+//	item, err := tui.Run(tty, items)
+//	if item != nil { ... } // picked with enter, nil when quit
+package tui
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/mholzen/workflowy/pkg/escape"
+	"github.com/mholzen/workflowy/pkg/terminal"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// Lines around the tree: the path of the selected node above, the status
+// below.
+const (
+	headerLines = 1
+	footerLines = 1
+)
+
+// Browser holds the tree, the nodes expanded and the one selected.
+type Browser struct {
+	roots         []*workflowy.Item
+	children      map[string][]*workflowy.Item // by parent ID, in outline order
+	parents       map[string]*workflowy.Item
+	order         []*workflowy.Item // every node, in outline order, to search
+	expanded      map[string]bool
+	showCompleted bool
+
+	rows   []row
+	cursor int // index of the selected row
+	offset int // index of the first row shown
+
+	searching bool   // a query is being typed
+	query     []rune // the query typed
+	lastQuery string // the query searched for, again with n and N
+	message   string // shown in the status line until the next key
+}
+
+// row is a visible node, at a depth in the tree.
+type row struct {
+	item  *workflowy.Item
+	depth int
+}
+
+// New returns a browser of items and their descendants, with the top level
+// shown. Completed nodes are hidden until toggled with ".".
+func New(items []*workflowy.Item) *Browser {
+	b := &Browser{
+		children: map[string][]*workflowy.Item{},
+		parents:  map[string]*workflowy.Item{},
+		expanded: map[string]bool{},
+	}
+	b.roots = b.index(items, nil)
+	b.refresh(nil)
+	return b
+}
+
+// index records items, under parent, with their descendants, and returns
+// them in outline order. The items of a snapshot are shared: sort copies.
+func (b *Browser) index(items []*workflowy.Item, parent *workflowy.Item) []*workflowy.Item {
+	sorted := slices.SortedStableFunc(slices.Values(items), func(a, b *workflowy.Item) int {
+		return a.Priority - b.Priority
+	})
+	for _, item := range sorted {
+		b.order = append(b.order, item)
+		if parent != nil {
+			b.parents[item.ID] = parent
+		}
+		if len(item.Children) > 0 {
+			b.children[item.ID] = b.index(item.Children, item)
+		}
+	}
+	return sorted
+}
+
+// visibleChildren returns the children of item, or the roots for nil,
+// without the completed ones when they are hidden.
+func (b *Browser) visibleChildren(item *workflowy.Item) []*workflowy.Item {
+	items := b.roots
+	if item != nil {
+		items = b.children[item.ID]
+	}
+	if b.showCompleted {
+		return items
+	}
+	var visible []*workflowy.Item
+	for _, child := range items {
+		if !child.IsCompleted() {
+			visible = append(visible, child)
+		}
+	}
+	return visible
+}
+
+// refresh lists the visible rows again, keeping selected, or the node
+// nearest to it, under the cursor.
+func (b *Browser) refresh(selected *workflowy.Item) {
+	b.rows = b.rows[:0]
+	var walk func(items []*workflowy.Item, depth int)
+	walk = func(items []*workflowy.Item, depth int) {
+		for _, item := range items {
+			b.rows = append(b.rows, row{item: item, depth: depth})
+			if b.expanded[item.ID] {
+				walk(b.visibleChildren(item), depth+1)
+			}
+		}
+	}
+	walk(b.visibleChildren(nil), 0)
+
+	for item := selected; item != nil; item = b.parents[item.ID] {
+		if i := b.rowOf(item); i >= 0 {
+			b.cursor = i
+			return
+		}
+	}
+	b.cursor = min(b.cursor, max(len(b.rows)-1, 0))
+}
+
+func (b *Browser) rowOf(item *workflowy.Item) int {
+	return slices.IndexFunc(b.rows, func(r row) bool { return r.item == item })
+}
+
+// Selected returns the node under the cursor, if any is shown.
+func (b *Browser) Selected() (*workflowy.Item, bool) {
+	if len(b.rows) == 0 {
+		return nil, false
+	}
+	return b.rows[b.cursor].item, true
+}
+
+// Handle applies event, and reports whether the selected node was picked or
+// the browser was quit.
+func (b *Browser) Handle(event terminal.Event, size terminal.Size) (picked, quit bool) {
+	b.message = ""
+	if b.searching {
+		b.handleSearch(event)
+		b.scroll(size)
+		return false, false
+	}
+
+	page := max(size.Height-headerLines-footerLines, 1)
+	selected, _ := b.Selected()
+	switch event.Key {
+	case terminal.KeyUp:
+		b.move(-1)
+	case terminal.KeyDown:
+		b.move(1)
+	case terminal.KeyPageUp:
+		b.move(-page)
+	case terminal.KeyPageDown:
+		b.move(page)
+	case terminal.KeyHome:
+		b.move(-len(b.rows))
+	case terminal.KeyEnd:
+		b.move(len(b.rows))
+	case terminal.KeyRight:
+		b.expand(selected)
+	case terminal.KeyLeft:
+		b.collapse(selected)
+	case terminal.KeyTab:
+		b.toggle(selected)
+	case terminal.KeyEnter:
+		return len(b.rows) > 0, false
+	case terminal.KeyCancel:
+		return false, true
+	case terminal.KeyRune:
+		switch event.Rune {
+		case 'k':
+			b.move(-1)
+		case 'j':
+			b.move(1)
+		case 'g':
+			b.move(-len(b.rows))
+		case 'G':
+			b.move(len(b.rows))
+		case 'l':
+			b.expand(selected)
+		case 'h':
+			b.collapse(selected)
+		case ' ':
+			b.toggle(selected)
+		case '/':
+			b.searching, b.query = true, nil
+		case 'n':
+			b.search(b.lastQuery, 1)
+		case 'N':
+			b.search(b.lastQuery, -1)
+		case '.':
+			b.showCompleted = !b.showCompleted
+			b.refresh(selected)
+		case 'q':
+			return false, true
+		}
+	}
+	b.scroll(size)
+	return false, false
+}
+
+func (b *Browser) handleSearch(event terminal.Event) {
+	switch event.Key {
+	case terminal.KeyRune:
+		b.query = append(b.query, event.Rune)
+	case terminal.KeyBackspace:
+		if len(b.query) == 0 {
+			b.searching = false
+			return
+		}
+		b.query = b.query[:len(b.query)-1]
+	case terminal.KeyClear:
+		b.query = nil
+	case terminal.KeyEnter:
+		b.searching = false
+		if len(b.query) > 0 {
+			b.lastQuery = string(b.query)
+		}
+		b.search(b.lastQuery, 1)
+	case terminal.KeyCancel:
+		b.searching = false
+	}
+}
+
+func (b *Browser) move(delta int) {
+	if len(b.rows) == 0 {
+		return
+	}
+	b.cursor = min(max(b.cursor+delta, 0), len(b.rows)-1)
+}
+
+// scroll moves the rows shown so that the cursor stays in sight.
+func (b *Browser) scroll(size terminal.Size) {
+	page := max(size.Height-headerLines-footerLines, 1)
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	}
+	if b.cursor >= b.offset+page {
+		b.offset = b.cursor - page + 1
+	}
+	b.offset = max(min(b.offset, len(b.rows)-page), 0)
+}
+
+// expand shows the children of item, or selects the first one when they are
+// shown already.
+func (b *Browser) expand(item *workflowy.Item) {
+	if item == nil || len(b.visibleChildren(item)) == 0 {
+		return
+	}
+	if b.expanded[item.ID] {
+		b.move(1)
+		return
+	}
+	b.expanded[item.ID] = true
+	b.refresh(item)
+}
+
+// collapse hides the children of item, or selects its parent when they are
+// hidden already.
+func (b *Browser) collapse(item *workflowy.Item) {
+	if item == nil {
+		return
+	}
+	if b.expanded[item.ID] {
+		delete(b.expanded, item.ID)
+		b.refresh(item)
+		return
+	}
+	if parent := b.parents[item.ID]; parent != nil {
+		b.refresh(parent)
+	}
+}
+
+func (b *Browser) toggle(item *workflowy.Item) {
+	if item != nil && b.expanded[item.ID] {
+		b.collapse(item)
+	} else {
+		b.expand(item)
+	}
+}
+
+// search selects the next node, in direction, whose name contains query,
+// expanding its ancestors; hidden completed nodes are skipped. The search
+// ignores case unless the query has an upper-case letter.
+func (b *Browser) search(query string, direction int) {
+	if query == "" {
+		return
+	}
+	caseSensitive := strings.IndexFunc(query, unicode.IsUpper) >= 0
+	term := query
+	if !caseSensitive {
+		term = strings.ToLower(query)
+	}
+	start := -1
+	if selected, ok := b.Selected(); ok {
+		start = slices.Index(b.order, selected)
+	}
+	for step := 1; step <= len(b.order); step++ {
+		i := ((start+direction*step)%len(b.order) + len(b.order)) % len(b.order)
+		item := b.order[i]
+		if !b.shown(item) {
+			continue
+		}
+		name := Name(item)
+		if !caseSensitive {
+			name = strings.ToLower(name)
+		}
+		if strings.Contains(name, term) {
+			for parent := b.parents[item.ID]; parent != nil; parent = b.parents[parent.ID] {
+				b.expanded[parent.ID] = true
+			}
+			b.refresh(item)
+			return
+		}
+	}
+	b.message = "no match for " + query
+}
+
+// shown reports whether item can be shown: neither it nor an ancestor is a
+// hidden completed node.
+func (b *Browser) shown(item *workflowy.Item) bool {
+	if b.showCompleted {
+		return true
+	}
+	for ; item != nil; item = b.parents[item.ID] {
+		if item.IsCompleted() {
+			return false
+		}
+	}
+	return true
+}
+
+// Name returns the name of item as plain text on a single line.
+func Name(item *workflowy.Item) string {
+	return strings.Join(strings.Fields(escape.Text(item.Name)), " ")
+}
+
+// path returns the names of item and its ancestors, from the top.
+func (b *Browser) path(item *workflowy.Item) []string {
+	var names []string
+	for ; item != nil; item = b.parents[item.ID] {
+		names = append(names, Name(item))
+	}
+	slices.Reverse(names)
+	return names
+}
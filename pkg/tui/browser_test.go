@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/terminal"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tree() []*workflowy.Item {
+	return []*workflowy.Item{
+		{ID: "inbox", Name: "Inbox", Priority: 1},
+		{ID: "projects", Name: "Projects", Priority: 0, Children: []*workflowy.Item{
+			{ID: "home", Name: "Home", Priority: 0, Children: []*workflowy.Item{
+				{ID: "gate", Name: "Fix the <b>gate</b>"},
+			}},
+			{ID: "done", Name: "Done task", Priority: 1, Completed: true},
+		}},
+	}
+}
+
+var size = terminal.Size{Width: 40, Height: 5}
+
+func key(k terminal.Key) terminal.Event {
+	return terminal.Event{Key: k}
+}
+
+func char(r rune) terminal.Event {
+	return terminal.Event{Key: terminal.KeyRune, Rune: r}
+}
+
+func rowIDs(b *Browser) []string {
+	var ids []string
+	for _, r := range b.rows {
+		ids = append(ids, r.item.ID)
+	}
+	return ids
+}
+
+func selectedID(b *Browser) string {
+	item, _ := b.Selected()
+	return item.ID
+}
+
+func TestBrowser_ExpandCollapse(t *testing.T) {
+	b := New(tree())
+	assert.Equal(t, []string{"projects", "inbox"}, rowIDs(b), "the top level, in outline order")
+
+	b.Handle(key(terminal.KeyRight), size)
+	assert.Equal(t, []string{"projects", "home", "inbox"}, rowIDs(b), "completed nodes are hidden")
+	b.Handle(key(terminal.KeyRight), size)
+	assert.Equal(t, "home", selectedID(b), "right on an expanded node moves to its first child")
+	b.Handle(char('l'), size)
+	b.Handle(char('l'), size)
+	assert.Equal(t, "gate", selectedID(b))
+
+	b.Handle(key(terminal.KeyLeft), size)
+	assert.Equal(t, "home", selectedID(b), "left on a collapsed node moves to its parent")
+	b.Handle(key(terminal.KeyLeft), size)
+	assert.Equal(t, []string{"projects", "home", "inbox"}, rowIDs(b))
+	b.Handle(char('.'), size)
+	assert.Equal(t, []string{"projects", "home", "done", "inbox"}, rowIDs(b))
+
+	b.Handle(char('k'), size)
+	b.Handle(char(' '), size)
+	assert.Equal(t, []string{"projects", "inbox"}, rowIDs(b))
+	assert.Equal(t, "projects", selectedID(b))
+}
+
+func TestBrowser_Search(t *testing.T) {
+	b := New(tree())
+	for _, event := range []terminal.Event{char('/'), char('g'), char('a'), char('t'), key(terminal.KeyEnter)} {
+		b.Handle(event, size)
+	}
+	assert.Equal(t, "gate", selectedID(b), "the ancestors of the match are expanded")
+	assert.Equal(t, []string{"projects", "home", "gate", "inbox"}, rowIDs(b))
+
+	b.Handle(char('n'), size)
+	assert.Equal(t, "gate", selectedID(b), "the search wraps around")
+
+	for _, event := range []terminal.Event{char('/'), char('d'), char('o'), char('n'), char('e'), key(terminal.KeyEnter)} {
+		b.Handle(event, size)
+	}
+	assert.Equal(t, "gate", selectedID(b), "hidden completed nodes are not found")
+	assert.Equal(t, "no match for done", b.message)
+}
+
+func TestBrowser_Scroll(t *testing.T) {
+	b := New(tree())
+	b.Handle(char('.'), size)
+	b.Handle(char('l'), size)
+	b.Handle(char('j'), size)
+	b.Handle(char('l'), size)
+	b.Handle(char('G'), size)
+	assert.Equal(t, "inbox", selectedID(b))
+	assert.Equal(t, 2, b.offset, "three rows fit between the path and the status")
+}
+
+func TestRender(t *testing.T) {
+	b := New(tree())
+	b.Handle(char('l'), size)
+	var out bytes.Buffer
+	require.NoError(t, b.Render(&out, size))
+	assert.Contains(t, out.String(), "\x1b[1mProjects\x1b[0m")
+	assert.Contains(t, out.String(), "\x1b[7m▾ Projects")
+	assert.Contains(t, out.String(), "  ▸ Home (1)")
+	assert.Contains(t, out.String(), "• Inbox")
+}
+
+func TestBrowse(t *testing.T) {
+	item, err := Browse(strings.NewReader("lj\r"), &bytes.Buffer{}, size, New(tree()))
+	require.NoError(t, err)
+	assert.Equal(t, "home", item.ID)
+
+	item, err = Browse(strings.NewReader("jq"), &bytes.Buffer{}, size, New(tree()))
+	require.NoError(t, err)
+	assert.Nil(t, item)
+}
@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mholzen/workflowy/pkg/escape"
+	"github.com/mholzen/workflowy/pkg/terminal"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// hints are shown in the status line when there is nothing else to show.
+const hints = "↑↓ move  ←→ collapse/expand  / search  n next  . completed  enter pick  q quit"
+
+// Render draws the browser on a terminal of size: the path of the selected
+// node on the first line, then as many rows as fit, and the status on the
+// last line: the search being typed, a message, the note of the selected
+// node or the keys.
+func (b *Browser) Render(w io.Writer, size terminal.Size) error {
+	out := bufio.NewWriter(w)
+	out.WriteString("\x1b[?25l\x1b[H\x1b[2J")
+
+	selected, ok := b.Selected()
+	if ok {
+		out.WriteString("\x1b[1m" + fit(strings.Join(b.path(selected), workflowy.BreadcrumbSeparator), size.Width) + "\x1b[0m")
+	}
+	height := max(size.Height-headerLines-footerLines, 0)
+	for i := b.offset; i < min(b.offset+height, len(b.rows)); i++ {
+		fmt.Fprintf(out, "\x1b[%d;1H", i-b.offset+headerLines+1)
+		b.writeRow(out, b.rows[i], i == b.cursor, size.Width)
+	}
+	if len(b.rows) == 0 {
+		fmt.Fprintf(out, "\x1b[%d;1H\x1b[2m%s\x1b[0m", headerLines+1, fit("(no nodes)", size.Width))
+	}
+
+	fmt.Fprintf(out, "\x1b[%d;1H", max(size.Height, 1))
+	switch {
+	case b.searching:
+		out.WriteString(fit("/"+string(b.query), size.Width) + "\x1b[?25h")
+	case b.message != "":
+		out.WriteString(fit(b.message, size.Width))
+	case ok && selected.Note != nil && strings.TrimSpace(*selected.Note) != "":
+		note := strings.Join(strings.Fields(escape.Text(*selected.Note)), " ")
+		out.WriteString("\x1b[2m" + fit("note: "+note, size.Width) + "\x1b[0m")
+	default:
+		out.WriteString("\x1b[2m" + fit(hints, size.Width) + "\x1b[0m")
+	}
+	return out.Flush()
+}
+
+// writeRow writes a row within width: indented by its depth, with a marker
+// telling whether the node has children and shows them. The selected row is
+// in reverse video, completed nodes are dimmed and struck through.
+func (b *Browser) writeRow(out *bufio.Writer, r row, selected bool, width int) {
+	marker := "• "
+	children := len(b.visibleChildren(r.item))
+	switch {
+	case children > 0 && b.expanded[r.item.ID]:
+		marker = "▾ "
+	case children > 0:
+		marker = "▸ "
+	}
+	text := strings.Repeat("  ", r.depth) + marker + Name(r.item)
+	if children > 0 && !b.expanded[r.item.ID] {
+		text += fmt.Sprintf(" (%d)", children)
+	}
+	if selected {
+		out.WriteString("\x1b[7m")
+	}
+	if r.item.IsCompleted() {
+		out.WriteString("\x1b[2;9m")
+	}
+	out.WriteString(fit(text, width))
+	out.WriteString("\x1b[0m")
+}
+
+// fit truncates s to width runes, ending with an ellipsis when cut.
+func fit(s string, width int) string {
+	runes := []rune(s)
+	if width <= 0 {
+		return ""
+	}
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
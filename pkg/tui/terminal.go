@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mholzen/workflowy/pkg/terminal"
+	"github.com/mholzen/workflowy/pkg/workflowy"
+)
+
+// Run browses items on the terminal tty, such as /dev/tty, so that the
+// standard output stays free for the result. It returns the node picked with
+// enter, or nil when the browser is quit. The browser takes the whole screen,
+// in the alternate screen buffer, and the terminal is restored when it
+// returns. Run is not available on Windows.
+func Run(tty *os.File, items []*workflowy.Item) (*workflowy.Item, error) {
+	size, err := terminal.GetSize(tty)
+	if err != nil {
+		return nil, err
+	}
+	restore, err := terminal.FullScreen(tty)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		fmt.Fprint(tty, "\x1b[?25h")
+		restore()
+	}()
+	return Browse(tty, tty, size, New(items))
+}
+
+// Browse runs b reading keys from in and drawing on out until a node is
+// picked, which it returns, or the browser is quit, or in ends.
+func Browse(in io.Reader, out io.Writer, size terminal.Size, b *Browser) (*workflowy.Item, error) {
+	buf := make([]byte, 256)
+	for {
+		if err := b.Render(out, size); err != nil {
+			return nil, err
+		}
+		n, err := in.Read(buf)
+		for _, event := range terminal.Decode(buf[:n]) {
+			picked, quit := b.Handle(event, size)
+			if quit {
+				return nil, nil
+			}
+			if picked {
+				item, _ := b.Selected()
+				return item, nil
+			}
+		}
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Package usage keeps local statistics of the commands and MCP tools used:
+// how often, how long they took and how often they failed. Nothing is sent
+// anywhere; the statistics stay in a file in the cache directory.
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/storage"
+)
+
+// FileName is the name of the statistics file in the cache directory
+const FileName = "usage.json"
+
+// Kinds of use
+const (
+	KindCommand = "command"
+	KindTool    = "tool"
+)
+
+// Entry is the usage of one command or tool.
+type Entry struct {
+	Count    int   `json:"count"`
+	Failures int   `json:"failures,omitempty"`
+	TotalMS  int64 `json:"total_ms"`
+	MaxMS    int64 `json:"max_ms"`
+	First    int64 `json:"first"` // Unix time of the first use
+	Last     int64 `json:"last"`  // Unix time of the last use
+}
+
+// AverageMS returns the mean duration of a use, in milliseconds.
+func (e Entry) AverageMS() int64 {
+	if e.Count == 0 {
+		return 0
+	}
+	return e.TotalMS / int64(e.Count)
+}
+
+// Stats is the usage of commands and tools, by name.
+type Stats struct {
+	Commands map[string]*Entry `json:"commands"`
+	Tools    map[string]*Entry `json:"tools"`
+}
+
+func newStats() *Stats {
+	return &Stats{Commands: make(map[string]*Entry), Tools: make(map[string]*Entry)}
+}
+
+// Add records a use of the command or tool name.
+func (s *Stats) Add(kind, name string, start time.Time, duration time.Duration, failed bool) {
+	entries := s.Commands
+	if kind == KindTool {
+		entries = s.Tools
+	}
+	entry := entries[name]
+	if entry == nil {
+		entry = &Entry{First: start.Unix()}
+		entries[name] = entry
+	}
+	entry.Count++
+	if failed {
+		entry.Failures++
+	}
+	ms := duration.Milliseconds()
+	entry.TotalMS += ms
+	entry.MaxMS = max(entry.MaxMS, ms)
+	entry.Last = start.Unix()
+}
+
+// Row is the usage of a command or tool, for display.
+type Row struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	Entry
+	AverageMS int64 `json:"average_ms"`
+}
+
+// Rows returns the usage of every command and tool, most used first.
+func (s *Stats) Rows() []Row {
+	var rows []Row
+	for name, entry := range s.Commands {
+		rows = append(rows, Row{Kind: KindCommand, Name: name, Entry: *entry, AverageMS: entry.AverageMS()})
+	}
+	for name, entry := range s.Tools {
+		rows = append(rows, Row{Kind: KindTool, Name: name, Entry: *entry, AverageMS: entry.AverageMS()})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Count != rows[j].Count {
+			return rows[i].Count > rows[j].Count
+		}
+		if rows[i].Kind != rows[j].Kind {
+			return rows[i].Kind < rows[j].Kind
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return rows
+}
+
+// File is a statistics file. Each use is added to the file as it is on disk,
+// so that concurrent processes, such as an MCP server and CLI commands, do
+// not lose each other's uses.
+type File struct {
+	mu    sync.Mutex
+	store storage.Storage
+	key   string
+}
+
+// Open returns the statistics file at path, created on the first use.
+func Open(path string) *File {
+	return &File{store: storage.NewFileStorage(filepath.Dir(path)), key: filepath.Base(path)}
+}
+
+// OpenDefault returns the statistics file in the storage (~/.workflowy/usage.json by default).
+func OpenDefault() (*File, error) {
+	s, err := storage.Default()
+	if err != nil {
+		return nil, err
+	}
+	return &File{store: s, key: FileName}, nil
+}
+
+// Path returns the location of the file.
+func (f *File) Path() string {
+	return f.store.Location("", f.key)
+}
+
+// Read returns the statistics in the file, empty if it does not exist.
+func (f *File) Read() (*Stats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.read()
+}
+
+func (f *File) read() (*Stats, error) {
+	data, err := f.store.Get("", f.key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return newStats(), nil
+		}
+		return nil, fmt.Errorf("cannot read usage statistics: %w", err)
+	}
+	return f.parse(data)
+}
+
+// parse decodes the content of the file, empty if there is none.
+func (f *File) parse(data []byte) (*Stats, error) {
+	stats := newStats()
+	if data == nil {
+		return stats, nil
+	}
+	if err := json.Unmarshal(data, stats); err != nil {
+		return nil, fmt.Errorf("cannot parse usage statistics %s: %w", f.Path(), err)
+	}
+	if stats.Commands == nil {
+		stats.Commands = make(map[string]*Entry)
+	}
+	if stats.Tools == nil {
+		stats.Tools = make(map[string]*Entry)
+	}
+	return stats, nil
+}
+
+// Record adds a use of the command or tool name to the file, locked while it
+// is read and written again.
+func (f *File) Record(kind, name string, start time.Time, duration time.Duration, failed bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return storage.Update(f.store, "", f.key, func(data []byte) ([]byte, error) {
+		stats, err := f.parse(data)
+		if err != nil {
+			return nil, err
+		}
+		stats.Add(kind, name, start, duration, failed)
+		return f.encode(stats)
+	})
+}
+
+// Reset deletes the statistics.
+func (f *File) Reset() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.store.Delete("", f.key); err != nil {
+		return fmt.Errorf("cannot delete usage statistics: %w", err)
+	}
+	return nil
+}
+
+func (f *File) encode(stats *Stats) ([]byte, error) {
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode usage statistics: %w", err)
+	}
+	return data, nil
+}
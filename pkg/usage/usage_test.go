@@ -0,0 +1,55 @@
+package usage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile_Record(t *testing.T) {
+	file := Open(filepath.Join(t.TempDir(), FileName))
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, file.Record(KindCommand, "search", start, 300*time.Millisecond, false))
+	require.NoError(t, file.Record(KindCommand, "search", start.Add(time.Hour), 100*time.Millisecond, true))
+	require.NoError(t, file.Record(KindTool, "workflowy_get", start, 50*time.Millisecond, false))
+
+	stats, err := file.Read()
+	require.NoError(t, err)
+	assert.Equal(t, &Entry{
+		Count:    2,
+		Failures: 1,
+		TotalMS:  400,
+		MaxMS:    300,
+		First:    start.Unix(),
+		Last:     start.Add(time.Hour).Unix(),
+	}, stats.Commands["search"])
+	assert.Equal(t, int64(200), stats.Commands["search"].AverageMS())
+	assert.Equal(t, 1, stats.Tools["workflowy_get"].Count)
+
+	require.NoError(t, file.Reset())
+	stats, err = file.Read()
+	require.NoError(t, err)
+	assert.Empty(t, stats.Rows())
+}
+
+func TestStats_Rows(t *testing.T) {
+	stats := newStats()
+	now := time.Now()
+	stats.Add(KindCommand, "get", now, time.Second, false)
+	stats.Add(KindTool, "workflowy_search", now, time.Second, false)
+	stats.Add(KindTool, "workflowy_search", now, time.Second, false)
+	stats.Add(KindCommand, "create", now, time.Second, false)
+
+	rows := stats.Rows()
+
+	require.Len(t, rows, 3)
+	assert.Equal(t, "workflowy_search", rows[0].Name)
+	assert.Equal(t, KindTool, rows[0].Kind)
+	assert.Equal(t, "create", rows[1].Name)
+	assert.Equal(t, "get", rows[2].Name)
+	assert.Equal(t, int64(1000), rows[2].AverageMS)
+}
@@ -0,0 +1,70 @@
+package workflowy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Policies for creating a node whose name a sibling already has
+const (
+	CollisionAllow  = "allow"  // create it anyway
+	CollisionSkip   = "skip"   // leave it out, with its children
+	CollisionRename = "rename" // create it with a " (2)", " (3)"... suffix
+	CollisionMerge  = "merge"  // add its children to the existing node instead
+)
+
+// ValidateCollisionPolicy checks that policy is empty (allow), allow, skip,
+// rename or merge.
+func ValidateCollisionPolicy(policy string) error {
+	switch policy {
+	case "", CollisionAllow, CollisionSkip, CollisionRename, CollisionMerge:
+		return nil
+	}
+	return fmt.Errorf("collision policy must be '%s', '%s', '%s' or '%s'", CollisionAllow, CollisionSkip, CollisionRename, CollisionMerge)
+}
+
+// Siblings indexes the children of a node by name, to find the collisions
+// of the nodes created under it.
+type Siblings struct {
+	byName map[string]*Item
+}
+
+// NewSiblings indexes children, the first of several with the same name
+// standing for them all.
+func NewSiblings(children []*Item) *Siblings {
+	s := &Siblings{byName: make(map[string]*Item, len(children))}
+	for _, child := range children {
+		s.Add(child)
+	}
+	return s
+}
+
+// Add records a node created among the siblings.
+func (s *Siblings) Add(item *Item) {
+	key := strings.TrimSpace(item.Name)
+	if _, ok := s.byName[key]; !ok {
+		s.byName[key] = item
+	}
+}
+
+// Resolve applies policy to a node about to be created with name. It returns
+// the name to create the node with, or, with the skip and merge policies, the
+// existing sibling to leave it out for or to merge it into.
+func (s *Siblings) Resolve(name, policy string) (string, *Item) {
+	existing, taken := s.byName[strings.TrimSpace(name)]
+	if !taken {
+		return name, nil
+	}
+	switch policy {
+	case CollisionSkip, CollisionMerge:
+		return "", existing
+	case CollisionRename:
+		for n := 2; ; n++ {
+			candidate := fmt.Sprintf("%s (%d)", name, n)
+			if _, taken := s.byName[strings.TrimSpace(candidate)]; !taken {
+				return candidate, nil
+			}
+		}
+	}
+	return name, nil
+}
@@ -0,0 +1,29 @@
+package workflowy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSiblingsResolve(t *testing.T) {
+	siblings := NewSiblings([]*Item{{ID: "a", Name: "Notes"}, {ID: "b", Name: "Notes (2)"}})
+
+	name, existing := siblings.Resolve("Ideas", CollisionSkip)
+	assert.Equal(t, "Ideas", name)
+	assert.Nil(t, existing)
+
+	_, existing = siblings.Resolve("Notes ", CollisionMerge)
+	assert.Equal(t, "a", existing.ID)
+
+	name, existing = siblings.Resolve("Notes", CollisionRename)
+	assert.Equal(t, "Notes (3)", name)
+	assert.Nil(t, existing)
+
+	name, existing = siblings.Resolve("Notes", CollisionAllow)
+	assert.Equal(t, "Notes", name)
+	assert.Nil(t, existing)
+
+	assert.NoError(t, ValidateCollisionPolicy(""))
+	assert.Error(t, ValidateCollisionPolicy("overwrite"))
+}
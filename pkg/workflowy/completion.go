@@ -0,0 +1,117 @@
+package workflowy
+
+import (
+	"fmt"
+	"time"
+)
+
+// Periods that completions are grouped by
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// ValidatePeriod checks that period is day, week or month.
+func ValidatePeriod(period string) error {
+	switch period {
+	case PeriodDay, PeriodWeek, PeriodMonth:
+		return nil
+	}
+	return fmt.Errorf("period must be '%s', '%s' or '%s'", PeriodDay, PeriodWeek, PeriodMonth)
+}
+
+// CompletionBucket counts the nodes completed in one period.
+type CompletionBucket struct {
+	Start time.Time `json:"start"`
+	Label string    `json:"label"` // e.g. 2025-03-01, week of 2025-02-24 or 2025-03
+	Count int       `json:"count"`
+	Ratio float64   `json:"ratio"` // share of the dated completions
+}
+
+// Completions is the number of nodes completed in each period.
+type Completions struct {
+	Period  string             `json:"period"`
+	Buckets []CompletionBucket `json:"buckets"` // oldest first, including empty periods
+	Total   int                `json:"total"`   // completions with a date
+	Undated int                `json:"undated"` // completed nodes without completedAt
+}
+
+// CountCompletions groups root and its completed descendants by the period,
+// in loc, of their completedAt. Weeks start on Monday. Periods without
+// completions between the first and the last are included with a zero count.
+func CountCompletions(root *Item, period string, loc *time.Location) (*Completions, error) {
+	if err := ValidatePeriod(period); err != nil {
+		return nil, err
+	}
+
+	result := &Completions{Period: period}
+	counts := make(map[time.Time]int)
+	var first, last time.Time
+	var visit func(item *Item)
+	visit = func(item *Item) {
+		if item.IsCompleted() {
+			if item.CompletedAt == nil {
+				result.Undated++
+			} else {
+				start := periodStart(time.Unix(*item.CompletedAt, 0).In(loc), period)
+				counts[start]++
+				result.Total++
+				if first.IsZero() || start.Before(first) {
+					first = start
+				}
+				if start.After(last) {
+					last = start
+				}
+			}
+		}
+		for _, child := range item.Children {
+			visit(child)
+		}
+	}
+	visit(root)
+
+	if result.Total == 0 {
+		return result, nil
+	}
+	for start := first; !start.After(last); start = nextPeriod(start, period) {
+		result.Buckets = append(result.Buckets, CompletionBucket{
+			Start: start,
+			Label: periodLabel(start, period),
+			Count: counts[start],
+			Ratio: float64(counts[start]) / float64(result.Total),
+		})
+	}
+	return result, nil
+}
+
+func periodStart(t time.Time, period string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch period {
+	case PeriodWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case PeriodMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+	return day
+}
+
+func nextPeriod(start time.Time, period string) time.Time {
+	switch period {
+	case PeriodWeek:
+		return start.AddDate(0, 0, 7)
+	case PeriodMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+func periodLabel(start time.Time, period string) string {
+	switch period {
+	case PeriodWeek:
+		return "week of " + start.Format("2006-01-02")
+	case PeriodMonth:
+		return start.Format("2006-01")
+	}
+	return start.Format("2006-01-02")
+}
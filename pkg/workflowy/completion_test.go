@@ -0,0 +1,54 @@
+package workflowy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountCompletions(t *testing.T) {
+	at := func(date string) *int64 {
+		ts, err := time.Parse("2006-01-02 15:04", date)
+		require.NoError(t, err)
+		unix := ts.Unix()
+		return &unix
+	}
+	root := &Item{ID: "root", Children: []*Item{
+		{ID: "a", CompletedAt: at("2025-03-03 09:00"), Children: []*Item{
+			{ID: "a1", CompletedAt: at("2025-03-09 23:00")},
+			{ID: "a2"},
+		}},
+		{ID: "b", CompletedAt: at("2025-03-20 12:00")},
+		{ID: "c", Completed: true},
+	}}
+
+	completions, err := CountCompletions(root, PeriodWeek, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, 3, completions.Total)
+	assert.Equal(t, 1, completions.Undated)
+	var labels []string
+	var counts []int
+	for _, bucket := range completions.Buckets {
+		labels = append(labels, bucket.Label)
+		counts = append(counts, bucket.Count)
+	}
+	assert.Equal(t, []string{"week of 2025-03-03", "week of 2025-03-10", "week of 2025-03-17"}, labels)
+	assert.Equal(t, []int{2, 0, 1}, counts)
+	assert.InDelta(t, 2.0/3, completions.Buckets[0].Ratio, 1e-9)
+
+	completions, err = CountCompletions(root, PeriodMonth, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, completions.Buckets, 1)
+	assert.Equal(t, "2025-03", completions.Buckets[0].Label)
+
+	completions, err = CountCompletions(root, PeriodDay, time.FixedZone("UTC+2", 2*3600))
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-03", completions.Buckets[0].Label)
+	assert.Equal(t, "2025-03-10", completions.Buckets[7].Label) // a1 falls on the next day
+	assert.Equal(t, 1, completions.Buckets[7].Count)
+
+	_, err = CountCompletions(root, "year", time.UTC)
+	assert.Error(t, err)
+}
@@ -0,0 +1,44 @@
+package workflowy
+
+import "github.com/mholzen/workflowy/pkg/counter"
+
+// DeepPath is the path to one of the deepest nodes of a tree.
+type DeepPath struct {
+	ID    string   `json:"id"` // the deepest node
+	Depth int      `json:"depth"`
+	Path  []string `json:"path"` // names below the root, outermost first, ending with the node
+}
+
+// TreeDepth describes how deep and how wide a tree is.
+type TreeDepth struct {
+	MaxDepth        int        `json:"max_depth"`        // the root being at depth 0
+	NodeCount       int        `json:"node_count"`       // including the root
+	LevelCounts     []int      `json:"level_counts"`     // nodes at each depth
+	BranchingFactor float64    `json:"branching_factor"` // average children of the nodes having some
+	DeepestPaths    []DeepPath `json:"deepest_paths"`
+}
+
+// MeasureDepth returns the depth and breadth statistics of root and its
+// descendants, with the paths to its maxPaths deepest leaves (0 for all).
+func MeasureDepth(root *Item, maxPaths int) TreeDepth {
+	stats := counter.CountDepth(NewItemNode(root), maxPaths)
+	depth := TreeDepth{
+		MaxDepth:        stats.MaxDepth,
+		NodeCount:       stats.NodeCount,
+		LevelCounts:     stats.LevelCounts,
+		BranchingFactor: stats.BranchingFactor,
+		DeepestPaths:    make([]DeepPath, len(stats.DeepestPaths)),
+	}
+	for i, path := range stats.DeepestPaths {
+		names := make([]string, 0, len(path)-1)
+		for _, node := range path[1:] {
+			names = append(names, node.Name())
+		}
+		depth.DeepestPaths[i] = DeepPath{
+			ID:    path[len(path)-1].Item().ID,
+			Depth: len(path) - 1,
+			Path:  names,
+		}
+	}
+	return depth
+}
@@ -0,0 +1,102 @@
+package workflowy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// PathSeparator separates the names of a path given to SplitPath. A name
+// containing it escapes it with a backslash.
+const PathSeparator = "/"
+
+// FindChild returns the first child of parentID named name, ignoring
+// surrounding spaces, or nil if there is none. The children are listed with
+// the API rather than read from the export, so that a node created a moment
+// before is found.
+func FindChild(ctx context.Context, client Client, parentID, name string) (*Item, error) {
+	resp, err := client.ListChildren(ctx, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list children of %s: %w", parentID, err)
+	}
+	children := slices.SortedStableFunc(slices.Values(resp.Items), func(a, b *Item) int {
+		return a.Priority - b.Priority
+	})
+	_, existing := NewSiblings(children).Resolve(name, CollisionSkip)
+	return existing, nil
+}
+
+// FindOrCreate returns the ID of the child of parentID named name, creating
+// it at the bottom of parentID if there is none. It reports whether it was
+// created.
+func FindOrCreate(ctx context.Context, client Client, parentID, name string) (string, bool, error) {
+	existing, err := FindChild(ctx, client, parentID, name)
+	if err != nil {
+		return "", false, err
+	}
+	if existing != nil {
+		return existing.ID, false, nil
+	}
+	position := "bottom"
+	resp, err := client.CreateNode(ctx, &CreateNodeRequest{ParentID: parentID, Name: name, Position: &position})
+	if err != nil {
+		return "", false, fmt.Errorf("cannot create %q: %w", name, err)
+	}
+	return resp.ItemID, true, nil
+}
+
+// FindOrCreatePath walks names down from parentID with FindOrCreate and
+// returns the ID of the last one, with the IDs of the nodes created, from
+// the top.
+func FindOrCreatePath(ctx context.Context, client Client, parentID string, names []string) (string, []string, error) {
+	if len(names) == 0 {
+		return "", nil, fmt.Errorf("path is empty")
+	}
+	id := parentID
+	var created []string
+	for _, name := range names {
+		childID, isNew, err := FindOrCreate(ctx, client, id, name)
+		if err != nil {
+			return "", created, err
+		}
+		if isNew {
+			created = append(created, childID)
+		}
+		id = childID
+	}
+	return id, created, nil
+}
+
+// SplitPath splits a path such as "Journal/2026/February" into names,
+// trimming spaces around each. "\/" stands for a slash within a name.
+func SplitPath(path string) ([]string, error) {
+	var names []string
+	var name strings.Builder
+	flush := func() error {
+		trimmed := strings.TrimSpace(name.String())
+		if trimmed == "" {
+			return fmt.Errorf("path %q has an empty name", path)
+		}
+		names = append(names, trimmed)
+		name.Reset()
+		return nil
+	}
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && strings.HasPrefix(path[i+1:], PathSeparator):
+			name.WriteString(PathSeparator)
+			i += len(PathSeparator)
+		case strings.HasPrefix(path[i:], PathSeparator):
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		default:
+			name.WriteByte(path[i])
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
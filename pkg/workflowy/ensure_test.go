@@ -0,0 +1,79 @@
+package workflowy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTreeClient keeps the children of each node, and adds the nodes created.
+type fakeTreeClient struct {
+	Client
+	children map[string][]*Item
+	created  int
+}
+
+func (c *fakeTreeClient) ListChildren(ctx context.Context, itemID string) (*ListChildrenResponse, error) {
+	return &ListChildrenResponse{Items: c.children[itemID]}, nil
+}
+
+func (c *fakeTreeClient) CreateNode(ctx context.Context, req *CreateNodeRequest) (*CreateNodeResponse, error) {
+	c.created++
+	id := fmt.Sprintf("new%d", c.created)
+	c.children[req.ParentID] = append(c.children[req.ParentID], &Item{ID: id, Name: req.Name, Priority: len(c.children[req.ParentID])})
+	return &CreateNodeResponse{ItemID: id}, nil
+}
+
+func TestFindChild(t *testing.T) {
+	client := &fakeTreeClient{children: map[string][]*Item{
+		"None": {{ID: "b", Name: "Inbox", Priority: 2}, {ID: "a", Name: " Inbox ", Priority: 1}},
+	}}
+
+	existing, err := FindChild(context.Background(), client, "None", "Inbox")
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.Equal(t, "a", existing.ID, "the first child in outline order")
+
+	existing, err = FindChild(context.Background(), client, "None", "inbox")
+	require.NoError(t, err)
+	assert.Nil(t, existing, "names are compared with their case")
+}
+
+func TestFindOrCreatePath(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeTreeClient{children: map[string][]*Item{
+		"None": {{ID: "journal", Name: "Journal"}},
+	}}
+
+	id, created, err := FindOrCreatePath(ctx, client, "None", []string{"Journal", "2026", "February"})
+	require.NoError(t, err)
+	assert.Equal(t, "new2", id)
+	assert.Equal(t, []string{"new1", "new2"}, created)
+	assert.Equal(t, "2026", client.children["journal"][0].Name)
+
+	id, created, err = FindOrCreatePath(ctx, client, "None", []string{"Journal", "2026", "February"})
+	require.NoError(t, err)
+	assert.Equal(t, "new2", id, "the path is found again")
+	assert.Empty(t, created)
+
+	_, _, err = FindOrCreatePath(ctx, client, "None", nil)
+	assert.Error(t, err)
+}
+
+func TestSplitPath(t *testing.T) {
+	names, err := SplitPath("Journal / 2026/February")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Journal", "2026", "February"}, names)
+
+	names, err = SplitPath(`Reading/Books\/Articles`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Reading", "Books/Articles"}, names)
+
+	_, err = SplitPath("Journal//2026")
+	assert.Error(t, err)
+	_, err = SplitPath("")
+	assert.Error(t, err)
+}
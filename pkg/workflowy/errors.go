@@ -0,0 +1,30 @@
+package workflowy
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of errors, matched with errors.Is, so that callers such as the MCP
+// server can tell how a failed operation may be recovered from.
+var (
+	ErrNotFound     = errors.New("not found")          // no node has the ID given
+	ErrAmbiguousID  = errors.New("ambiguous short ID") // several nodes end with the short ID given
+	ErrAccessDenied = errors.New("access denied")      // the node is outside the read-root or write-root
+	ErrLocked       = errors.New("locked")             // the node is tagged #locked, or within such a node
+)
+
+// kindError is an error of one of the kinds above, with its own message.
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func (e *kindError) Error() string        { return e.msg }
+func (e *kindError) Is(target error) bool { return target == e.kind }
+
+// Errorf formats an error that matches kind with errors.Is, without adding
+// the text of kind to the message.
+func Errorf(kind error, format string, args ...any) error {
+	return &kindError{kind: kind, msg: fmt.Sprintf(format, args...)}
+}
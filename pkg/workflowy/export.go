@@ -0,0 +1,55 @@
+package workflowy
+
+import "fmt"
+
+// ItemToExportNode converts an Item to an ExportNode under parentID, nil for
+// a top-level node, the inverse of ExportNodeToItem.
+func ItemToExportNode(item *Item, parentID *string) ExportNode {
+	return ExportNode{
+		ID:          item.ID,
+		Name:        item.Name,
+		Note:        item.Note,
+		ParentID:    parentID,
+		Priority:    item.Priority,
+		Completed:   item.IsCompleted(),
+		Data:        item.Data,
+		CreatedAt:   item.CreatedAt,
+		ModifiedAt:  item.ModifiedAt,
+		CompletedAt: item.CompletedAt,
+	}
+}
+
+// ItemToExportNodes flattens the descendants of root into export nodes, the
+// inverse of BuildTreeFromExport: children of root are top-level nodes, each
+// other node has the ID of its parent, and priorities follow the order of
+// children. Items without an ID, such as trees parsed from markdown, get
+// generated IDs that are the same on each call:
+//
+//	nodes := workflowy.ItemToExportNodes(&workflowy.Item{Children: items})
+//	tree := workflowy.BuildTreeFromExport(nodes)
+func ItemToExportNodes(root *Item) []ExportNode {
+	var nodes []ExportNode
+	generated := 0
+	var flatten func(items []*Item, parentID *string)
+	flatten = func(items []*Item, parentID *string) {
+		for i, item := range items {
+			node := ItemToExportNode(item, parentID)
+			node.Priority = i
+			if node.ID == "" {
+				generated++
+				node.ID = generatedExportID(generated)
+			}
+			nodes = append(nodes, node)
+			id := node.ID
+			flatten(item.Children, &id)
+		}
+	}
+	flatten(root.Children, nil)
+	return nodes
+}
+
+// generatedExportID returns the n-th ID given to items without one, shaped
+// like a Workflowy UUID.
+func generatedExportID(n int) string {
+	return fmt.Sprintf("00000000-0000-4000-8000-%012x", n)
+}
@@ -0,0 +1,57 @@
+package workflowy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemToExportNodes_RoundTrip(t *testing.T) {
+	note := "by Friday"
+	completedAt := int64(1700000000)
+	root := &Item{ID: "root", Name: "Root", Children: []*Item{
+		{ID: "a", Name: "Project", Note: &note, Data: map[string]interface{}{"layoutMode": "h1"}, Children: []*Item{
+			{ID: "a1", Name: "Draft", CompletedAt: &completedAt, Completed: true},
+			{ID: "a2", Name: "Review", Priority: 1},
+		}},
+		{ID: "b", Name: "Archive", Priority: 1},
+	}}
+
+	nodes := ItemToExportNodes(root)
+
+	require.Len(t, nodes, 4)
+	assert.Nil(t, nodes[0].ParentID)
+	assert.Equal(t, "a", *nodes[1].ParentID)
+	assert.Equal(t, 1, nodes[2].Priority)
+	assert.True(t, nodes[1].Completed)
+	assert.Equal(t, root, BuildTreeFromExport(nodes))
+}
+
+func TestItemToExportNodes_OrdersByPosition(t *testing.T) {
+	// priorities come from the order of children, not the items
+	root := &Item{Children: []*Item{
+		{ID: "first", Priority: 7},
+		{ID: "second", Priority: 3},
+	}}
+
+	tree := BuildTreeFromExport(ItemToExportNodes(root))
+
+	require.Len(t, tree.Children, 2)
+	assert.Equal(t, "first", tree.Children[0].ID)
+	assert.Equal(t, "second", tree.Children[1].ID)
+}
+
+func TestItemToExportNodes_GeneratesMissingIDs(t *testing.T) {
+	root := &Item{Children: []*Item{
+		{Name: "Parent", Children: []*Item{{Name: "Child"}}},
+	}}
+
+	nodes := ItemToExportNodes(root)
+
+	require.Len(t, nodes, 2)
+	assert.Equal(t, "00000000-0000-4000-8000-000000000001", nodes[0].ID)
+	assert.Equal(t, nodes[0].ID, *nodes[1].ParentID)
+	assert.Equal(t, nodes, ItemToExportNodes(root))
+	assert.Empty(t, root.Children[0].ID, "items are left unchanged")
+}
@@ -0,0 +1,120 @@
+package workflowy
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// exportFromBytes decodes fuzz input into export nodes. Each pair of bytes is
+// a node: the first places it at the top level, under a missing parent
+// (an orphan) or under one of the nodes before it, the second sets its
+// priority, from a small range so that siblings share priorities. The nodes
+// are then rotated by the first byte, so children can precede their parents.
+func exportFromBytes(data []byte) []ExportNode {
+	n := min(len(data)/2, 200)
+	nodes := make([]ExportNode, n)
+	for i := range nodes {
+		b := data[2*i]
+		nodes[i] = ExportNode{ID: fmt.Sprintf("node-%d", i), Priority: int(data[2*i+1] % 4)}
+		switch {
+		case b%8 == 0 || i == 0:
+		case b%8 == 1:
+			missing := fmt.Sprintf("missing-%d", i)
+			nodes[i].ParentID = &missing
+		default:
+			parent := nodes[int(b)%i].ID
+			nodes[i].ParentID = &parent
+		}
+	}
+	if n > 0 {
+		rotation := int(data[0]) % n
+		nodes = append(nodes[rotation:], nodes[:rotation]...)
+	}
+	return nodes
+}
+
+func addExportSeeds(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 0})
+	f.Add([]byte{0, 1, 2, 1, 2, 1, 3, 0})                   // siblings sharing priorities
+	f.Add([]byte{5, 3, 1, 0, 1, 2, 2, 2, 3, 1, 4, 0, 9, 1}) // orphans, rotated
+	f.Add([]byte{2, 0, 2, 1, 3, 2, 4, 3, 5, 0, 6, 1, 7, 2}) // deep chain
+}
+
+// preorder returns the IDs of items and their descendants in depth-first order.
+func preorder(items []*Item) []string {
+	var ids []string
+	for _, item := range items {
+		ids = append(ids, item.ID)
+		ids = append(ids, preorder(item.Children)...)
+	}
+	return ids
+}
+
+func FuzzBuildTreeFromExport(f *testing.F) {
+	addExportSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		nodes := exportFromBytes(data)
+		byID := make(map[string]ExportNode, len(nodes))
+		for _, node := range nodes {
+			byID[node.ID] = node
+		}
+
+		root := BuildTreeFromExport(nodes)
+		require.Equal(t, "root", root.ID)
+
+		// every node appears exactly once
+		ids := preorder(root.Children)
+		require.Len(t, ids, len(nodes))
+		seen := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			require.False(t, seen[id], "node %s appears twice", id)
+			seen[id] = true
+		}
+
+		// nodes are under their parent, or at the top level if it is missing,
+		// and siblings are ordered by priority
+		var check func(parentID *string, items []*Item)
+		check = func(parentID *string, items []*Item) {
+			for i, item := range items {
+				node := byID[item.ID]
+				if node.ParentID != nil && parentID == nil {
+					_, exists := byID[*node.ParentID]
+					require.False(t, exists, "node %s is at the top level but its parent exists", item.ID)
+				} else {
+					require.Equal(t, parentID, node.ParentID)
+				}
+				if i > 0 {
+					require.LessOrEqual(t, items[i-1].Priority, item.Priority)
+				}
+				id := item.ID
+				check(&id, item.Children)
+			}
+		}
+		check(nil, root.Children)
+	})
+}
+
+func FuzzFlattenTree(f *testing.F) {
+	addExportSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		nodes := exportFromBytes(data)
+
+		root := BuildTreeFromExport(nodes)
+		expected := append([]string{root.ID}, preorder(root.Children)...)
+		flat := FlattenTree(root)
+		require.Len(t, flat.Items, len(nodes)+1)
+		ids := make([]string, len(flat.Items))
+		for i, item := range flat.Items {
+			require.Empty(t, item.Children)
+			ids[i] = item.ID
+		}
+		require.Equal(t, expected, ids, "items are flattened depth-first")
+
+		root = BuildTreeFromExport(nodes)
+		flat = FlattenTree(&ListChildrenResponse{Items: root.Children})
+		require.Len(t, flat.Items, len(nodes))
+	})
+}
@@ -0,0 +1,38 @@
+package workflowy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"strconv"
+)
+
+// SubtreeHash returns a stable hex-encoded SHA-256 of the content of item and
+// its descendants: names, notes, completion and the order of children. IDs,
+// timestamps and layout are not included, so the hash is the same whether the
+// tree was read from the export API or a backup, and changes whenever anything
+// visible under item does.
+func SubtreeHash(item *Item) string {
+	return hex.EncodeToString(subtreeHash(item))
+}
+
+func subtreeHash(item *Item) []byte {
+	h := sha256.New()
+	writeField(h, item.Name)
+	note := ""
+	if item.Note != nil {
+		note = *item.Note
+	}
+	writeField(h, note)
+	writeField(h, strconv.FormatBool(item.IsCompleted()))
+	writeField(h, strconv.Itoa(len(item.Children)))
+	for _, child := range item.Children {
+		h.Write(subtreeHash(child))
+	}
+	return h.Sum(nil)
+}
+
+// writeField writes s length-prefixed, so adjacent fields cannot run together.
+func writeField(h hash.Hash, s string) {
+	h.Write([]byte(strconv.Itoa(len(s)) + ":" + s))
+}
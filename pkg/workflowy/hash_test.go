@@ -0,0 +1,57 @@
+package workflowy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func hashTestTree() *Item {
+	note := "details"
+	return &Item{ID: "a", Name: "Project", Note: &note, ModifiedAt: 100, Children: []*Item{
+		{ID: "b", Name: "Task 1"},
+		{ID: "c", Name: "Task 2"},
+	}}
+}
+
+func TestSubtreeHash_Stable(t *testing.T) {
+	other := hashTestTree()
+	other.ID = "other"
+	other.ModifiedAt = 200
+	other.Children[0].ID = "other-b"
+
+	assert.Len(t, SubtreeHash(hashTestTree()), 64)
+	assert.Equal(t, SubtreeHash(hashTestTree()), SubtreeHash(other), "IDs and timestamps are not content")
+}
+
+func TestSubtreeHash_CompletionFromExportOrBackup(t *testing.T) {
+	completedAt := int64(1)
+	backup := hashTestTree()
+	backup.Children[0].CompletedAt = &completedAt
+	export := hashTestTree()
+	export.Children[0].Completed = true // exports omit completedAt
+
+	assert.Equal(t, SubtreeHash(backup), SubtreeHash(export))
+	assert.NotEqual(t, SubtreeHash(hashTestTree()), SubtreeHash(export))
+}
+
+func TestSubtreeHash_ChangesWithContent(t *testing.T) {
+	base := SubtreeHash(hashTestTree())
+
+	completed := int64(1)
+	changes := map[string]func(item *Item){
+		"descendant name": func(item *Item) { item.Children[1].Name = "Task 3" },
+		"note":            func(item *Item) { item.Note = nil },
+		"completion":      func(item *Item) { item.Children[0].CompletedAt = &completed },
+		"order":           func(item *Item) { item.Children[0], item.Children[1] = item.Children[1], item.Children[0] },
+		"new child":       func(item *Item) { item.Children[0].Children = []*Item{{Name: ""}} },
+		"moved text":      func(item *Item) { item.Name = "Project" + "Task 1"; item.Children[0].Name = "" },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			item := hashTestTree()
+			change(item)
+			assert.NotEqual(t, base, SubtreeHash(item))
+		})
+	}
+}
@@ -32,4 +32,14 @@ func (p *FileBackupProvider) ReadLatestBackup() ([]*Item, error) {
 	return ReadLatestBackup()
 }
 
+func (p *FileBackupProvider) LatestBackupFile() (string, error) {
+	return LatestBackupFile()
+}
+
+// BackupLocator is implemented by backup providers that read their latest
+// backup from a file, so that the age of that file can be checked.
+type BackupLocator interface {
+	LatestBackupFile() (string, error)
+}
+
 var DefaultBackupProvider BackupProvider = &FileBackupProvider{}
@@ -0,0 +1,80 @@
+package workflowy
+
+import (
+	"slices"
+	"strings"
+)
+
+// LockTag marks curated content: writes leave a node tagged with it, in its
+// name or note, and all its descendants alone.
+const LockTag = "#locked"
+
+// IsLocked reports whether item itself carries the lock tag.
+func IsLocked(item *Item) bool {
+	note := ""
+	if item.Note != nil {
+		note = *item.Note
+	}
+	return slices.ContainsFunc(Tags(item.Name, note), func(tag string) bool {
+		return strings.EqualFold(tag, LockTag)
+	})
+}
+
+// Locks tells which nodes of a tree are locked, by their own lock tag or an
+// ancestor's, and which contain locked nodes.
+type Locks struct {
+	locked     map[string]*Item // locked node ID -> nearest node carrying the tag
+	containing map[string]*Item // ancestor ID of a tagged node -> the first of them
+}
+
+// FindLocks indexes the locks of the tree made of items.
+func FindLocks(items []*Item) Locks {
+	locks := Locks{locked: make(map[string]*Item), containing: make(map[string]*Item)}
+	var visit func(item *Item, lock *Item, ancestors []*Item)
+	visit = func(item *Item, lock *Item, ancestors []*Item) {
+		if IsLocked(item) {
+			lock = item
+			for _, ancestor := range ancestors {
+				if locks.containing[ancestor.ID] == nil {
+					locks.containing[ancestor.ID] = item
+				}
+			}
+		}
+		if lock != nil {
+			locks.locked[item.ID] = lock
+		}
+		ancestors = append(ancestors, item)
+		for _, child := range item.Children {
+			visit(child, lock, ancestors)
+		}
+	}
+	for _, item := range items {
+		visit(item, nil, nil)
+	}
+	return locks
+}
+
+// Check returns an error if targetID is locked.
+func (l Locks) Check(targetID, operation string) error {
+	lock := l.locked[targetID]
+	switch {
+	case lock == nil:
+		return nil
+	case lock.ID == targetID:
+		return Errorf(ErrLocked, "%s denied: %s is tagged %s", operation, targetID, LockTag)
+	default:
+		return Errorf(ErrLocked, "%s denied: %s is within %q (%s), tagged %s", operation, targetID, lock.Name, lock.ID, LockTag)
+	}
+}
+
+// CheckSubtree returns an error if targetID is locked or has a locked
+// descendant, as deleting it would delete locked content.
+func (l Locks) CheckSubtree(targetID, operation string) error {
+	if err := l.Check(targetID, operation); err != nil {
+		return err
+	}
+	if lock := l.containing[targetID]; lock != nil {
+		return Errorf(ErrLocked, "%s denied: %s contains %q (%s), tagged %s", operation, targetID, lock.Name, lock.ID, LockTag)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package workflowy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindLocks(t *testing.T) {
+	note := "reviewed #Locked."
+	items := []*Item{
+		{ID: "projects", Name: "Projects", Children: []*Item{
+			{ID: "curated", Name: "Reading list", Note: &note, Children: []*Item{
+				{ID: "book", Name: "A book"},
+			}},
+			{ID: "draft", Name: "Draft"},
+		}},
+		{ID: "hashtag", Name: "not a tag: https://example.com/#locked"},
+	}
+	locks := FindLocks(items)
+
+	assert.NoError(t, locks.Check("projects", "update"))
+	assert.NoError(t, locks.Check("draft", "update"))
+	assert.NoError(t, locks.Check("hashtag", "update"))
+	assert.NoError(t, locks.Check("None", "create"))
+	assert.EqualError(t, locks.Check("curated", "update"), "update denied: curated is tagged #locked")
+	assert.EqualError(t, locks.Check("book", "move"), `move denied: book is within "Reading list" (curated), tagged #locked`)
+
+	assert.NoError(t, locks.CheckSubtree("draft", "delete"))
+	assert.EqualError(t, locks.CheckSubtree("projects", "delete"), `delete denied: projects contains "Reading list" (curated), tagged #locked`)
+	assert.Error(t, locks.CheckSubtree("book", "delete"))
+}
+
+func TestLockErrorKind(t *testing.T) {
+	items := []*Item{{ID: "a", Name: "Curated " + LockTag}}
+	err := FindLocks(items).Check("a", "update")
+	assert.ErrorIs(t, err, ErrLocked)
+	assert.NotErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, "update denied: a is tagged #locked", err.Error())
+}
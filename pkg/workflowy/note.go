@@ -0,0 +1,44 @@
+package workflowy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// JoinNotes returns first and second on separate lines, or whichever is not
+// empty. Blank lines between them are dropped.
+func JoinNotes(first, second string) string {
+	first = strings.TrimRight(first, "\n")
+	second = strings.TrimLeft(second, "\n")
+	if first == "" || second == "" {
+		return first + second
+	}
+	return first + "\n" + second
+}
+
+// AppendNote adds text to the end of the note of itemID, on a new line. The
+// current note is read with GetItem rather than from the export, so that a
+// note written a moment before is kept.
+func AppendNote(ctx context.Context, client Client, itemID, text string) (*UpdateNodeResponse, error) {
+	return addToNote(ctx, client, itemID, func(note string) string { return JoinNotes(note, text) })
+}
+
+// PrependNote adds text to the start of the note of itemID, on a line of its
+// own.
+func PrependNote(ctx context.Context, client Client, itemID, text string) (*UpdateNodeResponse, error) {
+	return addToNote(ctx, client, itemID, func(note string) string { return JoinNotes(text, note) })
+}
+
+func addToNote(ctx context.Context, client Client, itemID string, join func(note string) string) (*UpdateNodeResponse, error) {
+	item, err := client.GetItem(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get node: %w", err)
+	}
+	var note string
+	if item.Note != nil {
+		note = *item.Note
+	}
+	note = join(note)
+	return client.UpdateNode(ctx, itemID, &UpdateNodeRequest{Note: &note})
+}
@@ -0,0 +1,45 @@
+package workflowy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNoteClient holds the note of a single node.
+type fakeNoteClient struct {
+	Client
+	note *string
+}
+
+func (c *fakeNoteClient) GetItem(ctx context.Context, itemID string) (*Item, error) {
+	return &Item{ID: itemID, Note: c.note}, nil
+}
+
+func (c *fakeNoteClient) UpdateNode(ctx context.Context, itemID string, req *UpdateNodeRequest) (*UpdateNodeResponse, error) {
+	c.note = req.Note
+	return &UpdateNodeResponse{}, nil
+}
+
+func TestJoinNotes(t *testing.T) {
+	assert.Equal(t, "first\nsecond", JoinNotes("first\n\n", "\nsecond"))
+	assert.Equal(t, "second", JoinNotes("", "second"))
+	assert.Equal(t, "first", JoinNotes("first", ""))
+}
+
+func TestAppendAndPrependNote(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeNoteClient{}
+
+	_, err := AppendNote(ctx, client, "a", "called back")
+	require.NoError(t, err)
+	assert.Equal(t, "called back", *client.note, "an empty note is replaced")
+
+	_, err = AppendNote(ctx, client, "a", "left a message")
+	require.NoError(t, err)
+	_, err = PrependNote(ctx, client, "a", "2026-10-15")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10-15\ncalled back\nleft a message", *client.note)
+}
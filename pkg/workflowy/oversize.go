@@ -0,0 +1,177 @@
+package workflowy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// OversizePolicy controls what CreateNode and UpdateNode do with a name or
+// note longer than the API accepts.
+type OversizePolicy string
+
+const (
+	// OversizeSplit keeps what fits and creates the rest as continuation children
+	OversizeSplit OversizePolicy = "split"
+	// OversizeTruncate drops what does not fit
+	OversizeTruncate OversizePolicy = "truncate"
+	// OversizeError refuses the request
+	OversizeError OversizePolicy = "error"
+)
+
+// ParseOversizePolicy validates a policy name.
+func ParseOversizePolicy(s string) (OversizePolicy, error) {
+	switch p := OversizePolicy(s); p {
+	case OversizeSplit, OversizeTruncate, OversizeError:
+		return p, nil
+	}
+	return "", fmt.Errorf("oversize policy must be '%s', '%s' or '%s', got '%s'", OversizeSplit, OversizeTruncate, OversizeError, s)
+}
+
+// Default length limits, in characters
+const (
+	DefaultMaxNameLength = 10000
+	DefaultMaxNoteLength = 100000
+)
+
+// continuationName names the continuation children that hold the overflow of a note
+const continuationName = "(continued)"
+
+// LengthLimits bounds the length of names and notes sent to the API.
+type LengthLimits struct {
+	MaxName int
+	MaxNote int
+	Policy  OversizePolicy
+}
+
+// DefaultLengthLimits splits content over the default limits.
+var DefaultLengthLimits = LengthLimits{MaxName: DefaultMaxNameLength, MaxNote: DefaultMaxNoteLength, Policy: OversizeSplit}
+
+func (l LengthLimits) withDefaults() LengthLimits {
+	if l.MaxName <= 0 {
+		l.MaxName = DefaultMaxNameLength
+	}
+	if l.MaxNote <= 0 {
+		l.MaxNote = DefaultMaxNoteLength
+	}
+	if l.Policy == "" {
+		l.Policy = OversizeSplit
+	}
+	return l
+}
+
+// continuation is the content of a child created for overflowing text.
+type continuation struct {
+	Name string
+	Note *string
+}
+
+// fit shortens name and note in place to the limits. With OversizeSplit, the
+// overflow is returned as continuations: name overflow as child names, then
+// note overflow as child notes. It reports whether any text was dropped.
+func (l LengthLimits) fit(name, note *string) (rest []continuation, truncated bool, err error) {
+	l = l.withDefaults()
+	var nameChunks, noteChunks []string
+	if name != nil {
+		nameChunks = chunkText(*name, l.MaxName)
+	}
+	if note != nil {
+		noteChunks = chunkText(*note, l.MaxNote)
+	}
+	if len(nameChunks) <= 1 && len(noteChunks) <= 1 {
+		return nil, false, nil
+	}
+
+	switch l.Policy {
+	case OversizeError:
+		if len(nameChunks) > 1 {
+			return nil, false, fmt.Errorf("name is %d characters, over the limit of %d", utf8.RuneCountInString(*name), l.MaxName)
+		}
+		return nil, false, fmt.Errorf("note is %d characters, over the limit of %d", utf8.RuneCountInString(*note), l.MaxNote)
+	case OversizeTruncate:
+		truncated = true
+	}
+
+	if len(nameChunks) > 1 {
+		*name = nameChunks[0]
+		if !truncated {
+			for _, chunk := range nameChunks[1:] {
+				rest = append(rest, continuation{Name: chunk})
+			}
+		}
+	}
+	if len(noteChunks) > 1 {
+		*note = noteChunks[0]
+		if !truncated {
+			for _, chunk := range noteChunks[1:] {
+				rest = append(rest, continuation{Name: continuationName, Note: &chunk})
+			}
+		}
+	}
+	return rest, truncated, nil
+}
+
+// chunkText splits s into pieces of at most max characters, preferring to
+// break after whitespace and never inside an HTML tag.
+func chunkText(s string, max int) []string {
+	if utf8.RuneCountInString(s) <= max {
+		return []string{s}
+	}
+	var chunks []string
+	for utf8.RuneCountInString(s) > max {
+		cut := breakPoint(s, max)
+		chunks = append(chunks, strings.TrimRightFunc(s[:cut], unicode.IsSpace))
+		s = strings.TrimLeftFunc(s[cut:], unicode.IsSpace)
+	}
+	if s != "" {
+		chunks = append(chunks, s)
+	}
+	return chunks
+}
+
+// breakPoint returns the byte offset at which to cut s so the first part has at most max characters.
+func breakPoint(s string, max int) int {
+	limit := len(s)
+	runes := 0
+	for i := range s {
+		if runes == max {
+			limit = i
+			break
+		}
+		runes++
+	}
+
+	cut := limit
+	// back out of an unclosed tag
+	if open := strings.LastIndexByte(s[:cut], '<'); open > 0 && strings.IndexByte(s[open:cut], '>') < 0 {
+		cut = open
+	}
+	// prefer a word boundary in the second half of the chunk
+	if space := strings.LastIndexFunc(s[:cut], unicode.IsSpace); space > cut/2 {
+		cut = space + 1
+	}
+	return cut
+}
+
+// createContinuations creates the overflow of a node as children of parentID, in order.
+// With position "top", they are created in reverse so they precede existing children.
+func (wc *WorkflowyClient) createContinuations(ctx context.Context, parentID string, rest []continuation, position string) ([]string, error) {
+	ids := make([]string, len(rest))
+	for n := range rest {
+		i := n
+		if position == "top" {
+			i = len(rest) - 1 - n
+		}
+		req := &CreateNodeRequest{ParentID: parentID, Name: rest[i].Name, Note: rest[i].Note, Position: &position}
+		var resp CreateNodeResponse
+		if err := wc.Do(ctx, "POST", "/nodes", req, &resp); err != nil {
+			return nil, fmt.Errorf("cannot create continuation %d of %d under %s: %w", i+1, len(rest), parentID, err)
+		}
+		ids[i] = resp.ItemID
+	}
+	slog.InfoContext(ctx, "split oversize content into continuation nodes", "parent_id", parentID, "continuations", len(rest))
+	return ids, nil
+}
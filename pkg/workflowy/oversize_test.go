@@ -0,0 +1,104 @@
+package workflowy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mholzen/workflowy/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		max  int
+		want []string
+	}{
+		{"fits", "short", 10, []string{"short"}},
+		{"breaks at word boundary", "one two three four", 10, []string{"one two", "three four"}},
+		{"hard split without spaces", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"counts characters not bytes", "ééééé", 2, []string{"éé", "éé", "é"}},
+		{"does not split tags", "see <a href=\"x\">link</a>", 12, []string{"see", "<a href=\"x\">", "link</a>"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, chunkText(tt.text, tt.max))
+		})
+	}
+}
+
+func TestLengthLimits_Fit(t *testing.T) {
+	name := "aaaa bbbb cccc"
+	note := "1234567890"
+
+	n, o := name, note
+	rest, truncated, err := LengthLimits{MaxName: 5, MaxNote: 5, Policy: OversizeSplit}.fit(&n, &o)
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Equal(t, "aaaa", n)
+	assert.Equal(t, "12345", o)
+	require.Len(t, rest, 3)
+	assert.Equal(t, "bbbb", rest[0].Name)
+	assert.Equal(t, "cccc", rest[1].Name)
+	assert.Equal(t, continuationName, rest[2].Name)
+	assert.Equal(t, "67890", *rest[2].Note)
+
+	n, o = name, note
+	rest, truncated, err = LengthLimits{MaxName: 5, MaxNote: 5, Policy: OversizeTruncate}.fit(&n, &o)
+	require.NoError(t, err)
+	assert.True(t, truncated)
+	assert.Empty(t, rest)
+	assert.Equal(t, "aaaa", n)
+
+	n = name
+	_, _, err = LengthLimits{MaxName: 5, Policy: OversizeError}.fit(&n, nil)
+	assert.ErrorContains(t, err, "over the limit of 5")
+	assert.Equal(t, name, n)
+}
+
+func TestWorkflowyClient_CreateNode_SplitsOversizeName(t *testing.T) {
+	var created []CreateNodeRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req CreateNodeRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		created = append(created, req)
+		json.NewEncoder(w).Encode(CreateNodeResponse{ItemID: strings.Repeat("x", len(created))})
+	}))
+	defer server.Close()
+
+	wc := &WorkflowyClient{Client: client.New(server.URL), limits: LengthLimits{MaxName: 10}}
+	resp, err := wc.CreateNode(context.Background(), &CreateNodeRequest{ParentID: "parent", Name: "first part second part"})
+	require.NoError(t, err)
+
+	require.Len(t, created, 3)
+	assert.Equal(t, "first part", created[0].Name)
+	assert.Equal(t, "parent", created[0].ParentID)
+	assert.Equal(t, "second", created[1].Name)
+	assert.Equal(t, "x", created[1].ParentID)
+	assert.Equal(t, "part", created[2].Name)
+	assert.Equal(t, "x", resp.ItemID)
+	assert.Equal(t, []string{"xx", "xxx"}, resp.ContinuationIDs)
+}
+
+func TestWorkflowyClient_CreateNode_LeavesRequestUnchanged(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(CreateNodeResponse{ItemID: "x"})
+	}))
+	defer server.Close()
+
+	wc := &WorkflowyClient{Client: client.New(server.URL), limits: LengthLimits{MaxName: 10, MaxNote: 10}}
+	note := "first part second part"
+	req := &CreateNodeRequest{ParentID: "parent", Name: "first part second part", Note: &note}
+	_, err := wc.CreateNode(context.Background(), req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "first part second part", req.Name)
+	assert.Equal(t, "first part second part", *req.Note)
+	assert.Equal(t, "first part second part", note)
+}
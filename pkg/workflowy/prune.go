@@ -0,0 +1,154 @@
+package workflowy
+
+import (
+	"slices"
+	"strings"
+)
+
+// Predicate reports whether Prune drops an item, with its descendants.
+type Predicate func(item *Item) bool
+
+// Prune returns the items without those matching drop and their descendants.
+// Kept items are copied, so the tree given is not modified.
+func Prune(items []*Item, drop Predicate) []*Item {
+	if drop == nil {
+		return items
+	}
+	kept := make([]*Item, 0, len(items))
+	for _, item := range items {
+		if drop(item) {
+			continue
+		}
+		copied := *item
+		copied.Children = Prune(item.Children, drop)
+		kept = append(kept, &copied)
+	}
+	return kept
+}
+
+// AnyOf drops the items that any of predicates drops. Nil predicates are
+// ignored; without any, AnyOf returns nil, which drops nothing.
+func AnyOf(predicates ...Predicate) Predicate {
+	predicates = slices.DeleteFunc(slices.Clone(predicates), func(p Predicate) bool { return p == nil })
+	if len(predicates) == 0 {
+		return nil
+	}
+	return func(item *Item) bool {
+		for _, p := range predicates {
+			if p(item) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// EmptyName drops items whose name is blank.
+func EmptyName(item *Item) bool {
+	return strings.TrimSpace(item.Name) == ""
+}
+
+// Completed drops completed items.
+func Completed(item *Item) bool {
+	return item.IsCompleted()
+}
+
+// Tagged drops items whose name or note holds one of tags, ignoring case. A
+// tag without a leading # or @ is a #tag.
+func Tagged(tags ...string) Predicate {
+	if len(tags) == 0 {
+		return nil
+	}
+	wanted := make([]string, len(tags))
+	for i, tag := range tags {
+		if !strings.HasPrefix(tag, "#") && !strings.HasPrefix(tag, "@") {
+			tag = "#" + tag
+		}
+		wanted[i] = strings.ToLower(tag)
+	}
+	return func(item *Item) bool {
+		texts := []string{item.Name}
+		if item.Note != nil {
+			texts = append(texts, *item.Note)
+		}
+		for _, tag := range Tags(texts...) {
+			if slices.Contains(wanted, strings.ToLower(tag)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// LimitChildren returns copies of items keeping, at every level, the first limit
+// children of each node by priority. A limit of zero or less keeps all of them.
+func LimitChildren(items []*Item, limit int) []*Item {
+	if limit <= 0 {
+		return items
+	}
+	limited := make([]*Item, len(items))
+	for i, item := range items {
+		copied := *item
+		copied.Children = LimitChildren(firstByPriority(item.Children, limit), limit)
+		limited[i] = &copied
+	}
+	return limited
+}
+
+// firstByPriority returns the first limit items by priority.
+func firstByPriority(items []*Item, limit int) []*Item {
+	if limit <= 0 || len(items) <= limit {
+		return items
+	}
+	sorted := slices.Clone(items)
+	slices.SortStableFunc(sorted, func(a, b *Item) int { return a.Priority - b.Priority })
+	return sorted[:limit]
+}
+
+// Pruning drops nodes from a tree before it is formatted, combining the
+// filters of the get and list commands. The zero value keeps every node.
+type Pruning struct {
+	EmptyNames  bool     // drop nodes with a blank name
+	Completed   bool     // drop completed nodes
+	Tags        []string // drop nodes holding one of these tags
+	MaxChildren int      // keep at most this many children per node (0 for all)
+}
+
+// Predicate returns the predicate of the nodes p drops, or nil if it drops none.
+func (p Pruning) Predicate() Predicate {
+	var predicates []Predicate
+	if p.EmptyNames {
+		predicates = append(predicates, EmptyName)
+	}
+	if p.Completed {
+		predicates = append(predicates, Completed)
+	}
+	return AnyOf(append(predicates, Tagged(p.Tags...))...)
+}
+
+// Apply returns the children of a node pruned by p, as copies: the tree given
+// is not modified.
+func (p Pruning) Apply(children []*Item) []*Item {
+	children = firstByPriority(Prune(children, p.Predicate()), p.MaxChildren)
+	return LimitChildren(children, p.MaxChildren)
+}
+
+// ApplyTree prunes a fetched *Item (its descendants; the item itself is always
+// kept) or *ListChildrenResponse (the children of the root) by p.
+func (p Pruning) ApplyTree(data interface{}) interface{} {
+	if p.Predicate() == nil && p.MaxChildren <= 0 {
+		return data
+	}
+	switch v := data.(type) {
+	case *Item:
+		if v == nil {
+			return v
+		}
+		copied := *v
+		copied.Children = p.Apply(v.Children)
+		return &copied
+	case *ListChildrenResponse:
+		return &ListChildrenResponse{Items: p.Apply(v.Items)}
+	}
+	return data
+}
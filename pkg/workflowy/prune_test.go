@@ -0,0 +1,66 @@
+package workflowy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pruneFixture() []*Item {
+	note := "waiting on @bob"
+	completedAt := int64(1700000000)
+	return []*Item{
+		{ID: "a", Name: "Project #someday", Children: []*Item{{ID: "a1", Name: "idea"}}},
+		{ID: "b", Name: "Project", Children: []*Item{
+			{ID: "b3", Name: "third", Priority: 2},
+			{ID: "b1", Name: "first", Priority: 0, CompletedAt: &completedAt},
+			{ID: "b2", Name: "second", Priority: 1, Note: &note},
+			{ID: "b4", Name: "  ", Priority: 3},
+		}},
+	}
+}
+
+func ids(items []*Item) []string {
+	var result []string
+	for _, item := range items {
+		result = append(result, item.ID)
+		for _, id := range ids(item.Children) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func TestPrune(t *testing.T) {
+	items := pruneFixture()
+
+	pruned := Prune(items, Tagged("someday"))
+	assert.Equal(t, []string{"b", "b3", "b1", "b2", "b4"}, ids(pruned))
+	assert.Equal(t, []string{"a", "a1", "b", "b3", "b1", "b2", "b4"}, ids(items), "the tree given is not modified")
+
+	assert.Equal(t, []string{"a", "a1", "b", "b3", "b1", "b4"}, ids(Prune(items, Tagged("@BOB"))), "tags in notes, ignoring case")
+	assert.Equal(t, []string{"a", "a1", "b", "b3", "b2", "b4"}, ids(Prune(items, Completed)))
+	assert.Equal(t, []string{"a", "a1", "b", "b3", "b1", "b2"}, ids(Prune(items, EmptyName)))
+	assert.Equal(t, []string{"b", "b3"}, ids(Prune(items, AnyOf(Tagged("#someday"), Completed, EmptyName, Tagged("@bob")))))
+	assert.Equal(t, ids(items), ids(Prune(items, AnyOf())))
+}
+
+func TestLimitChildren(t *testing.T) {
+	items := pruneFixture()
+	assert.Equal(t, []string{"a", "a1", "b", "b1", "b2"}, ids(LimitChildren(items, 2)), "the first children by priority")
+	assert.Len(t, items[1].Children, 4, "the tree given is not modified")
+	assert.Equal(t, ids(items), ids(LimitChildren(items, 0)))
+}
+
+func TestPruning(t *testing.T) {
+	root := &Item{ID: "root", Children: pruneFixture()}
+
+	assert.Same(t, root, Pruning{}.ApplyTree(root), "the zero value keeps every node")
+
+	pruned := Pruning{Completed: true, EmptyNames: true, MaxChildren: 1}.ApplyTree(root).(*Item)
+	assert.Equal(t, "root", pruned.ID)
+	assert.Equal(t, []string{"a", "a1"}, ids(pruned.Children), "the root's children are limited too")
+
+	list := Pruning{Tags: []string{"someday"}, MaxChildren: 2}.ApplyTree(&ListChildrenResponse{Items: pruneFixture()}).(*ListChildrenResponse)
+	assert.Equal(t, []string{"b", "b1", "b2"}, ids(list.Items))
+}
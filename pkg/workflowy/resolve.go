@@ -0,0 +1,254 @@
+package workflowy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Resolver turns one form of node identifier, such as a short ID or a
+// target key, into an ID the API accepts. Resolve reports ok false when id
+// is not of its form, so that the next resolver of the chain is tried.
+type Resolver struct {
+	Name    string
+	Resolve func(ctx context.Context, client Client, id string) (resolved string, ok bool, err error)
+}
+
+// Names of the built-in resolvers, in the order they are tried.
+const (
+	ResolverUUID    = "uuid"
+	ResolverShortID = "short-id"
+	ResolverURL     = "url"
+	ResolverTarget  = "target"
+	ResolverAlias   = "alias"
+	ResolverPath    = "path"
+)
+
+var (
+	resolversMu sync.Mutex
+	resolvers   []Resolver
+	aliases     map[string]string // set by SetAliases
+)
+
+// the alias resolver resolves through the chain: set it up once declared
+func init() {
+	resolvers = []Resolver{
+		{Name: ResolverUUID, Resolve: resolveUUID},
+		{Name: ResolverShortID, Resolve: resolveShortID},
+		{Name: ResolverURL, Resolve: resolveURL},
+		{Name: ResolverTarget, Resolve: resolveTarget},
+		{Name: ResolverAlias, Resolve: resolveAlias},
+		{Name: ResolverPath, Resolve: resolvePath},
+	}
+}
+
+// RegisterResolver adds r to the chain of ResolveNodeID, before the resolver
+// named before, or last when before is empty, e.g. to accept project codes
+// of another system:
+//
+//	workflowy.RegisterResolver(workflowy.Resolver{Name: "jira", Resolve: resolveJira}, workflowy.ResolverPath)
+func RegisterResolver(r Resolver, before string) error {
+	if r.Name == "" || r.Resolve == nil {
+		return fmt.Errorf("resolver needs a name and a Resolve function")
+	}
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	if slices.ContainsFunc(resolvers, func(existing Resolver) bool { return existing.Name == r.Name }) {
+		return fmt.Errorf("resolver %q is already registered", r.Name)
+	}
+	i := len(resolvers)
+	if before != "" {
+		i = slices.IndexFunc(resolvers, func(existing Resolver) bool { return existing.Name == before })
+		if i < 0 {
+			return fmt.Errorf("no resolver named %q", before)
+		}
+	}
+	resolvers = slices.Insert(resolvers, i, r)
+	return nil
+}
+
+// UnregisterResolver removes the resolver named name from the chain.
+func UnregisterResolver(name string) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers = slices.DeleteFunc(resolvers, func(r Resolver) bool { return r.Name == name })
+}
+
+// ResolverNames returns the names of the resolvers of the chain, in order.
+func ResolverNames() []string {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	names := make([]string, len(resolvers))
+	for i, r := range resolvers {
+		names[i] = r.Name
+	}
+	return names
+}
+
+// SetAliases sets the names the alias resolver accepts, and the identifiers
+// they stand for, in any form ResolveNodeID accepts but another alias.
+func SetAliases(a map[string]string) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	aliases = a
+}
+
+// ResolveNodeID resolves id with the first resolver of the chain that
+// accepts it: a UUID, a short ID, a Workflowy URL, a target key, an alias or
+// a path of names. An id no resolver accepts is sanitized. If client is nil,
+// only sanitization is performed.
+func ResolveNodeID(ctx context.Context, client Client, id string) (string, error) {
+	return resolveNodeID(ctx, client, id, "")
+}
+
+// resolveNodeID is ResolveNodeID without the resolver named skip.
+func resolveNodeID(ctx context.Context, client Client, id, skip string) (string, error) {
+	if id == "" || id == "None" {
+		return id, nil
+	}
+
+	if client == nil {
+		return SanitizeNodeID(id), nil
+	}
+
+	resolversMu.Lock()
+	chain := slices.Clone(resolvers)
+	resolversMu.Unlock()
+	for _, r := range chain {
+		if r.Name == skip {
+			continue
+		}
+		resolved, ok, err := r.Resolve(ctx, client, id)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return resolved, nil
+		}
+	}
+	return SanitizeNodeID(id), nil
+}
+
+// ResolveNodeIDToUUID resolves any node identifier to a full UUID.
+// Unlike ResolveNodeID, this always returns a UUID even for target keys like "inbox".
+func ResolveNodeIDToUUID(ctx context.Context, client Client, id string) (string, error) {
+	resolved, err := ResolveNodeID(ctx, client, id)
+	if err != nil || client == nil || resolved == "" || resolved == "None" {
+		return resolved, err
+	}
+
+	isTarget, err := IsTargetKey(ctx, client, resolved)
+	if err != nil {
+		return "", fmt.Errorf("cannot check target: %w", err)
+	}
+	if isTarget {
+		item, err := client.GetItem(ctx, resolved)
+		if err != nil {
+			return "", fmt.Errorf("cannot resolve target %q to UUID: %w", resolved, err)
+		}
+		return item.ID, nil
+	}
+	return resolved, nil
+}
+
+// IsUUID returns true if the ID is a full UUID: 32 hexadecimal characters in
+// groups of 8, 4, 4, 4 and 12 separated by dashes.
+func IsUUID(id string) bool {
+	groups := strings.Split(id, "-")
+	if len(groups) != 5 {
+		return false
+	}
+	for i, size := range []int{8, 4, 4, 4, 12} {
+		if len(groups[i]) != size || strings.Trim(groups[i], "0123456789abcdefABCDEF") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func resolveUUID(ctx context.Context, client Client, id string) (string, bool, error) {
+	id = strings.TrimSpace(id)
+	return id, IsUUID(id), nil
+}
+
+// resolveShortID accepts a short ID once sanitized, as copied with stray
+// characters around it.
+func resolveShortID(ctx context.Context, client Client, id string) (string, bool, error) {
+	sanitized := SanitizeNodeID(strings.TrimSpace(id))
+	if !IsShortID(sanitized) {
+		return "", false, nil
+	}
+	resolved, err := ResolveShortID(ctx, client, sanitized)
+	return resolved, true, err
+}
+
+// resolveURL accepts the URL of a node, such as
+// https://workflowy.com/#/3495d784abcd, ending with its short ID or UUID.
+func resolveURL(ctx context.Context, client Client, id string) (string, bool, error) {
+	_, fragment, found := strings.Cut(strings.TrimSpace(id), "workflowy.com/#/")
+	if !found {
+		return "", false, nil
+	}
+	fragment, _, _ = strings.Cut(fragment, "?")
+	fragment = strings.TrimRight(fragment, "/")
+	fragment = fragment[strings.LastIndex(fragment, "/")+1:]
+	switch {
+	case IsUUID(fragment):
+		return fragment, true, nil
+	case IsShortID(fragment):
+		resolved, err := ResolveShortID(ctx, client, fragment)
+		return resolved, true, err
+	}
+	return "", true, Errorf(ErrNotFound, "no node ID in URL %s", id)
+}
+
+func resolveTarget(ctx context.Context, client Client, id string) (string, bool, error) {
+	isTarget, err := IsTargetKey(ctx, client, id)
+	if err != nil {
+		return "", false, fmt.Errorf("cannot check target: %w", err)
+	}
+	return id, isTarget, nil
+}
+
+func resolveAlias(ctx context.Context, client Client, id string) (string, bool, error) {
+	resolversMu.Lock()
+	target, ok := aliases[strings.TrimSpace(id)]
+	resolversMu.Unlock()
+	if !ok {
+		return "", false, nil
+	}
+	resolved, err := resolveNodeID(ctx, client, target, ResolverAlias)
+	if err != nil {
+		return "", true, fmt.Errorf("cannot resolve alias %q: %w", id, err)
+	}
+	return resolved, true, nil
+}
+
+// resolvePath accepts names separated by PathSeparator, such as
+// /Projects/Website, walked down from the top level: a leading separator
+// tells a single name from an ID. The first child of each name is taken, as
+// by FindChild.
+func resolvePath(ctx context.Context, client Client, id string) (string, bool, error) {
+	id = strings.TrimSpace(id)
+	if !strings.Contains(id, PathSeparator) {
+		return "", false, nil
+	}
+	names, err := SplitPath(strings.TrimPrefix(id, PathSeparator))
+	if err != nil {
+		return "", true, err
+	}
+	parentID := "None"
+	for _, name := range names {
+		child, err := FindChild(ctx, client, parentID, name)
+		if err != nil {
+			return "", true, err
+		}
+		if child == nil {
+			return "", true, Errorf(ErrNotFound, "no node named %q under %s", name, parentID)
+		}
+		parentID = child.ID
+	}
+	return parentID, true, nil
+}
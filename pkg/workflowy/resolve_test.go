@@ -0,0 +1,86 @@
+package workflowy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const websiteID = "6f2c1e4a-8b3d-4c5e-9f01-3495d784abcd"
+
+// fakeResolveClient has an inbox target and a /Projects/Website node.
+type fakeResolveClient struct {
+	fakeTreeClient
+}
+
+func newFakeResolveClient() *fakeResolveClient {
+	return &fakeResolveClient{fakeTreeClient{children: map[string][]*Item{
+		"None":     {{ID: "projects", Name: "Projects"}},
+		"projects": {{ID: websiteID, Name: "Website"}},
+	}}}
+}
+
+func (c *fakeResolveClient) ListTargets(ctx context.Context) (*ListTargetsResponse, error) {
+	return &ListTargetsResponse{Targets: []Target{{Key: "inbox", Type: "system"}}}, nil
+}
+
+func (c *fakeResolveClient) ExportNodesWithCache(ctx context.Context, forceRefresh bool) (*ExportNodesResponse, error) {
+	return &ExportNodesResponse{Nodes: []ExportNode{{ID: "projects"}, {ID: websiteID}}}, nil
+}
+
+func TestResolveNodeID(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeResolveClient()
+	SetAliases(map[string]string{"site": "/Projects/Website", "in": "inbox"})
+	defer SetAliases(nil)
+
+	for id, want := range map[string]string{
+		websiteID:                               websiteID,
+		"3495d784abcd":                          websiteID,
+		"`3495d784abcd`,":                       websiteID,
+		"https://workflowy.com/#/3495d784abcd":  websiteID,
+		"https://workflowy.com/#/3495d784abcd/": websiteID,
+		"inbox":                                 "inbox",
+		"site":                                  websiteID,
+		"in":                                    "inbox",
+		"/Projects/Website":                     websiteID,
+		"None":                                  "None",
+	} {
+		resolved, err := ResolveNodeID(ctx, client, id)
+		require.NoError(t, err, id)
+		assert.Equal(t, want, resolved, id)
+	}
+
+	_, err := ResolveNodeID(ctx, client, "/Projects/Garden")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	_, err = ResolveNodeID(ctx, client, "https://workflowy.com/#/")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestRegisterResolver(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeResolveClient()
+	jira := Resolver{Name: "jira", Resolve: func(ctx context.Context, client Client, id string) (string, bool, error) {
+		if !strings.HasPrefix(id, "WEB-") {
+			return "", false, nil
+		}
+		return websiteID, true, nil
+	}}
+
+	require.NoError(t, RegisterResolver(jira, ResolverPath))
+	defer UnregisterResolver("jira")
+	assert.Equal(t, []string{"uuid", "short-id", "url", "target", "alias", "jira", "path"}, ResolverNames())
+	assert.Error(t, RegisterResolver(jira, ""), "names are unique")
+	assert.Error(t, RegisterResolver(Resolver{Name: "other", Resolve: jira.Resolve}, "missing"))
+
+	resolved, err := ResolveNodeID(ctx, client, "WEB-42")
+	require.NoError(t, err)
+	assert.Equal(t, websiteID, resolved)
+	resolved, err = ResolveNodeID(ctx, client, "inbox")
+	require.NoError(t, err)
+	assert.Equal(t, "inbox", resolved, "earlier resolvers still come first")
+}
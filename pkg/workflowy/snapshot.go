@@ -0,0 +1,84 @@
+package workflowy
+
+import (
+	"fmt"
+	"time"
+)
+
+// SnapshotMeta identifies the point in time a snapshot represents.
+type SnapshotMeta struct {
+	TakenAt time.Time `json:"taken_at"`
+	Source  string    `json:"source"` // "get", "export", "backup", "session" or "cache"
+}
+
+// String returns the snapshot time and source, e.g. "2025-01-02 15:04:05 (export)".
+func (m SnapshotMeta) String() string {
+	return fmt.Sprintf("%s (%s)", m.TakenAt.Format("2006-01-02 15:04:05"), m.Source)
+}
+
+// Snapshot is the whole tree as loaded once from the export API or a backup.
+// Computations that share a snapshot see the same, consistent state.
+// The items are shared and must not be modified.
+type Snapshot struct {
+	items []*Item
+	meta  SnapshotMeta
+}
+
+// NewSnapshot returns a snapshot of items taken at takenAt from source.
+func NewSnapshot(items []*Item, source string, takenAt time.Time) *Snapshot {
+	return &Snapshot{items: items, meta: SnapshotMeta{TakenAt: takenAt, Source: source}}
+}
+
+// NewExportSnapshot returns a snapshot of an export response, dated when the
+// export was fetched from the API.
+func NewExportSnapshot(resp *ExportNodesResponse) *Snapshot {
+	takenAt := resp.FetchedAt
+	if takenAt.IsZero() {
+		takenAt = time.Now()
+	}
+	return NewSnapshot(BuildTreeFromExport(resp.Nodes).Children, "export", takenAt)
+}
+
+// NewBackupSnapshot returns a snapshot of backup items. Backups do not record
+// when they were written, so the snapshot is dated by the most recent
+// modification it contains.
+func NewBackupSnapshot(items []*Item) *Snapshot {
+	var latest int64
+	var walk func(items []*Item)
+	walk = func(items []*Item) {
+		for _, item := range items {
+			latest = max(latest, item.ModifiedAt)
+			walk(item.Children)
+		}
+	}
+	walk(items)
+
+	takenAt := time.Now()
+	if latest > 0 {
+		takenAt = time.Unix(latest, 0)
+	}
+	return NewSnapshot(items, "backup", takenAt)
+}
+
+// Items returns the top-level items of the snapshot.
+func (s *Snapshot) Items() []*Item {
+	return s.items
+}
+
+// Meta returns when and from where the snapshot was taken.
+func (s *Snapshot) Meta() SnapshotMeta {
+	return s.meta
+}
+
+// Root returns the item with the given ID, or a synthetic root holding the
+// top-level items when id is "None".
+func (s *Snapshot) Root(id string) (*Item, error) {
+	if id == "None" {
+		return &Item{ID: "root", Name: "Root", Children: s.items}, nil
+	}
+	item := FindItemByID(s.items, id)
+	if item == nil {
+		return nil, Errorf(ErrNotFound, "item with ID %s not found", id)
+	}
+	return item, nil
+}
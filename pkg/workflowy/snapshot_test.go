@@ -0,0 +1,60 @@
+package workflowy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBackupSnapshot_DatedByLatestModification(t *testing.T) {
+	items := []*Item{
+		{ID: "a", ModifiedAt: 1700000000, Children: []*Item{
+			{ID: "b", ModifiedAt: 1700000500},
+		}},
+		{ID: "c", ModifiedAt: 1700000100},
+	}
+
+	snapshot := NewBackupSnapshot(items)
+
+	assert.Equal(t, "backup", snapshot.Meta().Source)
+	assert.Equal(t, time.Unix(1700000500, 0), snapshot.Meta().TakenAt)
+	assert.Len(t, snapshot.Items(), 2)
+}
+
+func TestNewExportSnapshot_DatedByFetch(t *testing.T) {
+	fetchedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	parentID := "a"
+	resp := &ExportNodesResponse{
+		Nodes: []ExportNode{
+			{ID: "a", Name: "A"},
+			{ID: "b", Name: "B", ParentID: &parentID},
+		},
+		FetchedAt: fetchedAt,
+	}
+
+	snapshot := NewExportSnapshot(resp)
+
+	assert.Equal(t, SnapshotMeta{TakenAt: fetchedAt, Source: "export"}, snapshot.Meta())
+	require.Len(t, snapshot.Items(), 1)
+	assert.Equal(t, "b", snapshot.Items()[0].Children[0].ID)
+}
+
+func TestSnapshotRoot(t *testing.T) {
+	snapshot := NewSnapshot([]*Item{
+		{ID: "a", Children: []*Item{{ID: "b"}}},
+	}, "backup", time.Now())
+
+	root, err := snapshot.Root("None")
+	require.NoError(t, err)
+	assert.Equal(t, "root", root.ID)
+	assert.Len(t, root.Children, 1)
+
+	item, err := snapshot.Root("b")
+	require.NoError(t, err)
+	assert.Equal(t, "b", item.ID)
+
+	_, err = snapshot.Root("missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,61 @@
+package workflowy
+
+import (
+	"sort"
+	"time"
+)
+
+// StaleSubtree is a subtree in which no node was modified since a cutoff.
+type StaleSubtree struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Descendants  int    `json:"descendants"`
+	LastModified int64  `json:"last_modified"` // most recent modification in the subtree, 0 if unknown
+}
+
+// FindStaleSubtrees returns the largest subtrees below root in which no node
+// was modified after cutoff: a stale node is only reported when its parent
+// is root or was itself touched. Nodes without a modification time count as
+// untouched. Subtrees are sorted by decreasing number of descendants, then
+// oldest first.
+func FindStaleSubtrees(root *Item, cutoff time.Time) []StaleSubtree {
+	type stat struct {
+		latest      int64
+		descendants int
+	}
+	stats := make(map[*Item]stat)
+	var measure func(item *Item) stat
+	measure = func(item *Item) stat {
+		s := stat{latest: item.ModifiedAt}
+		for _, child := range item.Children {
+			childStat := measure(child)
+			s.latest = max(s.latest, childStat.latest)
+			s.descendants += childStat.descendants + 1
+		}
+		stats[item] = s
+		return s
+	}
+	measure(root)
+
+	var stale []StaleSubtree
+	var collect func(item *Item)
+	collect = func(item *Item) {
+		for _, child := range item.Children {
+			s := stats[child]
+			if s.latest > cutoff.Unix() {
+				collect(child)
+				continue
+			}
+			stale = append(stale, StaleSubtree{ID: child.ID, Name: child.Name, Descendants: s.descendants, LastModified: s.latest})
+		}
+	}
+	collect(root)
+
+	sort.SliceStable(stale, func(i, j int) bool {
+		if stale[i].Descendants != stale[j].Descendants {
+			return stale[i].Descendants > stale[j].Descendants
+		}
+		return stale[i].LastModified < stale[j].LastModified
+	})
+	return stale
+}
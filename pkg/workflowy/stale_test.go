@@ -0,0 +1,39 @@
+package workflowy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindStaleSubtrees(t *testing.T) {
+	cutoff := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	old := cutoff.AddDate(0, -6, 0).Unix()
+	older := cutoff.AddDate(-1, 0, 0).Unix()
+	recent := cutoff.AddDate(0, 1, 0).Unix()
+
+	root := &Item{ID: "root", Children: []*Item{
+		{ID: "archive", Name: "Archive", ModifiedAt: old, Children: []*Item{
+			{ID: "a1", ModifiedAt: older},
+			{ID: "a2", ModifiedAt: older},
+		}},
+		{ID: "projects", Name: "Projects", ModifiedAt: older, Children: []*Item{
+			{ID: "live", ModifiedAt: recent},
+			{ID: "dead", Name: "Dead project", ModifiedAt: older, Children: []*Item{
+				{ID: "d1", ModifiedAt: older},
+			}},
+			{ID: "undated", Name: "Undated"},
+		}},
+		{ID: "today", Name: "Today", ModifiedAt: recent},
+	}}
+
+	assert.Equal(t, []StaleSubtree{
+		{ID: "archive", Name: "Archive", Descendants: 2, LastModified: old},
+		{ID: "dead", Name: "Dead project", Descendants: 1, LastModified: older},
+		{ID: "undated", Name: "Undated", Descendants: 0, LastModified: 0},
+	}, FindStaleSubtrees(root, cutoff))
+
+	stale := FindStaleSubtrees(root, cutoff.AddDate(-2, 0, 0))
+	assert.Equal(t, []StaleSubtree{{ID: "undated", Name: "Undated"}}, stale)
+}
@@ -0,0 +1,47 @@
+package workflowy
+
+// BranchSummary stands in for a branch too large to print in full: its size
+// and its first few descendants.
+type BranchSummary struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Descendants int     `json:"descendants"`
+	Items       []*Item `json:"nodes"` // the first descendants, depth first, without children
+	More        int     `json:"more"`  // descendants not in Items
+}
+
+// CountItems returns the number of items and their descendants.
+func CountItems(items []*Item) int {
+	count := len(items)
+	for _, item := range items {
+		count += CountItems(item.Children)
+	}
+	return count
+}
+
+// Summarize returns a summary of each of items with at most perBranch of its
+// descendants. The items are left unchanged.
+func Summarize(items []*Item, perBranch int) []BranchSummary {
+	summaries := make([]BranchSummary, 0, len(items))
+	for _, item := range items {
+		summary := BranchSummary{ID: item.ID, Name: item.Name, Descendants: CountItems(item.Children)}
+		summary.Items = firstDescendants(item.Children, max(perBranch, 0), []*Item{})
+		summary.More = summary.Descendants - len(summary.Items)
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// firstDescendants appends copies without children of items and their
+// descendants, depth first, to out until it holds n items.
+func firstDescendants(items []*Item, n int, out []*Item) []*Item {
+	for _, item := range items {
+		if len(out) >= n {
+			break
+		}
+		flat := *item
+		flat.Children = nil
+		out = firstDescendants(item.Children, n, append(out, &flat))
+	}
+	return out
+}
@@ -0,0 +1,31 @@
+package workflowy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	items := []*Item{
+		{ID: "big", Name: "Big", Children: []*Item{
+			{ID: "a", Children: []*Item{{ID: "a1"}, {ID: "a2"}}},
+			{ID: "b"},
+		}},
+		{ID: "small", Name: "Small", Children: []*Item{{ID: "c"}}},
+		{ID: "empty", Name: "Empty"},
+	}
+
+	summaries := Summarize(items, 2)
+
+	require.Len(t, summaries, 3)
+	assert.Equal(t, 4, summaries[0].Descendants)
+	assert.Equal(t, []*Item{{ID: "a"}, {ID: "a1"}}, summaries[0].Items)
+	assert.Equal(t, 2, summaries[0].More)
+	assert.Equal(t, []*Item{{ID: "c"}}, summaries[1].Items)
+	assert.Zero(t, summaries[1].More)
+	assert.Empty(t, summaries[2].Items)
+	assert.Len(t, items[0].Children[0].Children, 2, "the items are unchanged")
+	assert.Equal(t, 8, CountItems(items))
+}
@@ -0,0 +1,116 @@
+package workflowy
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// tagPattern matches #tags and @mentions at the start of the text or after a
+// space, an opening parenthesis or a formatting tag, so URL fragments and
+// emails are not tags.
+var tagPattern = regexp.MustCompile(`(?:^|[\s(>])([#@][\p{L}\p{N}_][\p{L}\p{N}_:.-]*)`)
+
+// Tags returns the distinct #tags and @mentions in texts, in order of first
+// appearance. Trailing punctuation is not part of a tag.
+func Tags(texts ...string) []string {
+	var tags []string
+	for _, tag := range TagOccurrences(texts...) {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// TagOccurrences returns every #tag and @mention in texts, including repeats.
+func TagOccurrences(texts ...string) []string {
+	var tags []string
+	for _, text := range texts {
+		for _, m := range tagPattern.FindAllStringSubmatch(text, -1) {
+			tags = append(tags, trimTagPunctuation(m[1]))
+		}
+	}
+	return tags
+}
+
+func trimTagPunctuation(tag string) string {
+	for len(tag) > 2 {
+		switch tag[len(tag)-1] {
+		case '.', ':', '-':
+			tag = tag[:len(tag)-1]
+		default:
+			return tag
+		}
+	}
+	return tag
+}
+
+// TagUsage is how often a tag is used, and the nodes that use it most.
+type TagUsage struct {
+	Tag       string    `json:"tag"`        // as first written
+	Count     int       `json:"count"`      // occurrences in names and notes
+	NodeCount int       `json:"node_count"` // nodes using the tag
+	Nodes     []TagNode `json:"nodes"`      // most occurrences first
+}
+
+// TagNode is a node using a tag.
+type TagNode struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"` // occurrences of the tag in the node
+}
+
+// CountTags counts the #tags and @mentions in the names and notes of root and
+// its descendants. Tags differing only by case are counted together. Tags are
+// sorted by decreasing count, then by name, and each lists at most nodesPerTag
+// nodes (0 for all), in tree order among nodes with the same count.
+func CountTags(root *Item, nodesPerTag int) []TagUsage {
+	byKey := make(map[string]*TagUsage)
+	var visit func(item *Item)
+	visit = func(item *Item) {
+		note := ""
+		if item.Note != nil {
+			note = *item.Note
+		}
+		counts := make(map[string]int)
+		var keys []string
+		for _, tag := range TagOccurrences(item.Name, note) {
+			key := strings.ToLower(tag)
+			if _, ok := byKey[key]; !ok {
+				byKey[key] = &TagUsage{Tag: tag}
+			}
+			if counts[key] == 0 {
+				keys = append(keys, key)
+			}
+			counts[key]++
+		}
+		for _, key := range keys {
+			usage := byKey[key]
+			usage.Count += counts[key]
+			usage.NodeCount++
+			usage.Nodes = append(usage.Nodes, TagNode{ID: item.ID, Name: item.Name, Count: counts[key]})
+		}
+		for _, child := range item.Children {
+			visit(child)
+		}
+	}
+	visit(root)
+
+	usages := make([]TagUsage, 0, len(byKey))
+	for _, usage := range byKey {
+		sort.SliceStable(usage.Nodes, func(i, j int) bool { return usage.Nodes[i].Count > usage.Nodes[j].Count })
+		if nodesPerTag > 0 && len(usage.Nodes) > nodesPerTag {
+			usage.Nodes = usage.Nodes[:nodesPerTag]
+		}
+		usages = append(usages, *usage)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Count != usages[j].Count {
+			return usages[i].Count > usages[j].Count
+		}
+		return strings.ToLower(usages[i].Tag) < strings.ToLower(usages[j].Tag)
+	})
+	return usages
+}
@@ -0,0 +1,35 @@
+package workflowy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTags(t *testing.T) {
+	assert.Equal(t, []string{"#urgent", "@alice", "#q3-plan"}, Tags("#urgent call @alice (#q3-plan).", "again #urgent"))
+	assert.Equal(t, []string{"#due:2025-01-02", "#bold"}, Tags("ship it #due:2025-01-02 <b>#bold</b>"))
+	assert.Empty(t, Tags("mail bob@example.com", "see https://example.com/#section", "C# and #"))
+}
+
+func TestCountTags(t *testing.T) {
+	note := "see #Urgent and #urgent again"
+	root := &Item{ID: "root", Name: "Root", Children: []*Item{
+		{ID: "a", Name: "#urgent call @alice", Note: &note},
+		{ID: "b", Name: "plan #q3", Children: []*Item{
+			{ID: "c", Name: "ask @alice about #urgent"},
+		}},
+	}}
+
+	usages := CountTags(root, 1)
+	assert.Equal(t, []TagUsage{
+		{Tag: "#urgent", Count: 4, NodeCount: 2, Nodes: []TagNode{{ID: "a", Name: "#urgent call @alice", Count: 3}}},
+		{Tag: "@alice", Count: 2, NodeCount: 2, Nodes: []TagNode{{ID: "a", Name: "#urgent call @alice", Count: 1}}},
+		{Tag: "#q3", Count: 1, NodeCount: 1, Nodes: []TagNode{{ID: "b", Name: "plan #q3", Count: 1}}},
+	}, usages)
+
+	usages = CountTags(root, 0)
+	assert.Len(t, usages[1].Nodes, 2)
+	assert.Equal(t, "c", usages[1].Nodes[1].ID)
+	assert.Empty(t, CountTags(&Item{ID: "x", Name: "no tags"}, 0))
+}
@@ -0,0 +1,59 @@
+package workflowy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// TrashName is the name of the node soft-deleted nodes are moved to, when no
+// trash node is configured.
+const TrashName = "Trash"
+
+// FindTrash returns the ID of the trash node: trashID if set, resolved,
+// otherwise the child of parentID named Trash, or "" if there is none.
+func FindTrash(ctx context.Context, client Client, trashID, parentID string) (string, error) {
+	if trashID != "" {
+		id, err := ResolveNodeID(ctx, client, trashID)
+		if err != nil {
+			return "", fmt.Errorf("cannot resolve trash ID: %w", err)
+		}
+		return id, nil
+	}
+
+	children, err := client.ListChildren(ctx, parentID)
+	if err != nil {
+		return "", fmt.Errorf("cannot list children of %s: %w", parentID, err)
+	}
+	for _, child := range children.Items {
+		if strings.TrimSpace(child.Name) == TrashName {
+			return child.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// ResolveTrash returns the trash node as FindTrash does, creating it at the
+// bottom of parentID if there is none. It reports whether it was created.
+func ResolveTrash(ctx context.Context, client Client, trashID, parentID string) (string, bool, error) {
+	id, err := FindTrash(ctx, client, trashID, parentID)
+	if err != nil || id != "" {
+		return id, false, err
+	}
+	position := "bottom"
+	resp, err := client.CreateNode(ctx, &CreateNodeRequest{ParentID: parentID, Name: TrashName, Position: &position})
+	if err != nil {
+		return "", false, fmt.Errorf("cannot create trash: %w", err)
+	}
+	return resp.ItemID, true, nil
+}
+
+// MoveToTrash soft-deletes itemID by moving it to the top of trashID, where
+// it can be restored from with a move.
+func MoveToTrash(ctx context.Context, client Client, itemID, trashID string) (*MoveNodeResponse, error) {
+	if itemID == trashID {
+		return nil, fmt.Errorf("cannot move the trash into itself")
+	}
+	position := "top"
+	return client.MoveNode(ctx, itemID, &MoveNodeRequest{ParentID: trashID, Position: &position})
+}
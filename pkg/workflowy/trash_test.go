@@ -0,0 +1,67 @@
+package workflowy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTrashClient struct {
+	Client
+	children []*Item
+	created  []*CreateNodeRequest
+	moved    map[string]*MoveNodeRequest
+}
+
+func (c *fakeTrashClient) ListChildren(ctx context.Context, itemID string) (*ListChildrenResponse, error) {
+	return &ListChildrenResponse{Items: c.children}, nil
+}
+
+func (c *fakeTrashClient) CreateNode(ctx context.Context, req *CreateNodeRequest) (*CreateNodeResponse, error) {
+	c.created = append(c.created, req)
+	return &CreateNodeResponse{ItemID: "trash"}, nil
+}
+
+func (c *fakeTrashClient) MoveNode(ctx context.Context, itemID string, req *MoveNodeRequest) (*MoveNodeResponse, error) {
+	c.moved[itemID] = req
+	return &MoveNodeResponse{Status: "ok"}, nil
+}
+
+func TestResolveTrash(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeTrashClient{children: []*Item{{ID: "a", Name: "Inbox"}, {ID: "t", Name: " Trash "}}}
+
+	id, created, err := ResolveTrash(ctx, client, "", "None")
+	require.NoError(t, err)
+	assert.Equal(t, "t", id)
+	assert.False(t, created)
+	assert.Empty(t, client.created)
+
+	client.children = client.children[:1]
+	id, created, err = ResolveTrash(ctx, client, "", "None")
+	require.NoError(t, err)
+	assert.Equal(t, "trash", id)
+	assert.True(t, created)
+	require.Len(t, client.created, 1)
+	assert.Equal(t, TrashName, client.created[0].Name)
+	assert.Equal(t, "None", client.created[0].ParentID)
+
+	id, err = FindTrash(ctx, client, "", "None")
+	require.NoError(t, err)
+	assert.Empty(t, id)
+}
+
+func TestMoveToTrash(t *testing.T) {
+	client := &fakeTrashClient{moved: make(map[string]*MoveNodeRequest)}
+
+	_, err := MoveToTrash(context.Background(), client, "a", "t")
+	require.NoError(t, err)
+	require.Contains(t, client.moved, "a")
+	assert.Equal(t, "t", client.moved["a"].ParentID)
+	assert.Equal(t, "top", *client.moved["a"].Position)
+
+	_, err = MoveToTrash(context.Background(), client, "t", "t")
+	assert.Error(t, err)
+}
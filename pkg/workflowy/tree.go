@@ -17,6 +17,20 @@ func FindItemByID(items []*Item, id string) *Item {
 	return nil
 }
 
+// FindPath returns the item with the given ID preceded by its ancestors,
+// outermost first, or nil if it is not found.
+func FindPath(items []*Item, id string) []*Item {
+	for _, item := range items {
+		if item.ID == id {
+			return []*Item{item}
+		}
+		if path := FindPath(item.Children, id); path != nil {
+			return append([]*Item{item}, path...)
+		}
+	}
+	return nil
+}
+
 func FindRootItem(items []*Item, itemID string) *Item {
 	if itemID == "None" {
 		return nil
@@ -85,6 +99,46 @@ func FlattenItem(item *Item) []*Item {
 	return result
 }
 
+// FlattenCopy returns item and its descendants down to maxDepth levels (-1 for
+// all) as a flat list of copies without children. Unlike FlattenItem, the tree
+// is left unchanged, so it can be used on shared snapshots.
+func FlattenCopy(item *Item, maxDepth int) []*Item {
+	flat := *item
+	flat.Children = nil
+	result := []*Item{&flat}
+	if maxDepth == 0 {
+		return result
+	}
+	for _, child := range item.Children {
+		result = append(result, FlattenCopy(child, maxDepth-1)...)
+	}
+	return result
+}
+
+// Page is a window of a flat list, for paging through large outlines.
+type Page struct {
+	Items      []*Item `json:"nodes"`
+	Total      int     `json:"total"`
+	Offset     int     `json:"offset"`
+	NextOffset int     `json:"next_offset,omitempty"` // set when more items follow
+}
+
+// Paginate returns at most limit items of list starting at offset; a limit of
+// zero or less returns all remaining items.
+func Paginate(list *ListChildrenResponse, offset, limit int) *Page {
+	total := len(list.Items)
+	offset = min(max(offset, 0), total)
+	end := total
+	if limit > 0 {
+		end = min(offset+limit, total)
+	}
+	page := &Page{Items: list.Items[offset:end], Total: total, Offset: offset}
+	if end < total {
+		page.NextOffset = end
+	}
+	return page
+}
+
 func FilterEmptyItem(item *Item) *Item {
 	if item == nil {
 		return nil
@@ -102,19 +156,141 @@ func FilterEmptyList(list *ListChildrenResponse) *ListChildrenResponse {
 }
 
 func FilterEmpty(items []*Item) []*Item {
+	return Prune(items, EmptyName)
+}
+
+// Modes of FilterCompleted
+const (
+	CompletedInclude = "include" // keep completed nodes
+	CompletedExclude = "exclude" // drop completed nodes and their descendants
+	CompletedOnly    = "only"    // keep completed nodes and the ancestors that lead to them
+)
+
+// ValidateCompletedMode checks that mode is include, exclude or only. An empty
+// mode includes completed nodes.
+func ValidateCompletedMode(mode string) error {
+	switch mode {
+	case "", CompletedInclude, CompletedExclude, CompletedOnly:
+		return nil
+	}
+	return fmt.Errorf("completed must be '%s', '%s' or '%s'", CompletedInclude, CompletedExclude, CompletedOnly)
+}
+
+// FilterCompleted returns the items filtered by completion (see the modes).
+// Kept items are copied, so the tree given is not modified.
+func FilterCompleted(items []*Item, mode string) []*Item {
+	switch mode {
+	case CompletedExclude:
+		return Prune(items, Completed)
+	case CompletedOnly:
+	default:
+		return items
+	}
 	filtered := make([]*Item, 0, len(items))
 	for _, item := range items {
-		if strings.TrimSpace(item.Name) == "" {
+		children := FilterCompleted(item.Children, mode)
+		if !item.IsCompleted() && len(children) == 0 {
 			continue
 		}
-		if len(item.Children) > 0 {
-			item.Children = FilterEmpty(item.Children)
-		}
-		filtered = append(filtered, item)
+		kept := *item
+		kept.Children = children
+		filtered = append(filtered, &kept)
 	}
 	return filtered
 }
 
+// FilterCompletedItem returns a copy of item with its descendants filtered by
+// completion. The item itself is always kept.
+func FilterCompletedItem(item *Item, mode string) *Item {
+	if item == nil || mode == "" || mode == CompletedInclude {
+		return item
+	}
+	kept := *item
+	kept.Children = FilterCompleted(item.Children, mode)
+	return &kept
+}
+
+// FilterCompletedTree filters a fetched *Item (its descendants) or
+// *ListChildrenResponse (its items) by completion.
+func FilterCompletedTree(data interface{}, mode string) interface{} {
+	switch v := data.(type) {
+	case *Item:
+		return FilterCompletedItem(v, mode)
+	case *ListChildrenResponse:
+		return &ListChildrenResponse{Items: FilterCompleted(v.Items, mode)}
+	}
+	return data
+}
+
+// CompletedItems returns the completed items of a flat list, dropping the
+// ancestors that FilterCompleted keeps in a tree.
+func CompletedItems(items []*Item) []*Item {
+	completed := make([]*Item, 0, len(items))
+	for _, item := range items {
+		if item.IsCompleted() {
+			completed = append(completed, item)
+		}
+	}
+	return completed
+}
+
+// BreadcrumbSeparator separates ancestor names in a breadcrumb.
+const BreadcrumbSeparator = " > "
+
+// BreadcrumbLevels is the number of ancestors shown in result breadcrumbs.
+const BreadcrumbLevels = 2
+
+// BuildBreadcrumbs maps each item ID to the names of its top ancestors (at most
+// levels of them), outermost first. Top-level items have no entry.
+func BuildBreadcrumbs(items []*Item, levels int) map[string]string {
+	crumbs := make(map[string]string)
+	var walk func(items []*Item, ancestors []string)
+	walk = func(items []*Item, ancestors []string) {
+		for _, item := range items {
+			if len(ancestors) > 0 {
+				top := ancestors
+				if len(top) > levels {
+					top = top[:levels]
+				}
+				crumbs[item.ID] = strings.Join(top, BreadcrumbSeparator)
+			}
+			if len(item.Children) > 0 {
+				walk(item.Children, append(ancestors[:len(ancestors):len(ancestors)], item.Name))
+			}
+		}
+	}
+	walk(items, nil)
+	return crumbs
+}
+
+// Breadcrumbed is a result about a node, that can show where the node is.
+type Breadcrumbed interface {
+	NodeID() string
+	SetBreadcrumb(breadcrumb string)
+}
+
+// AddBreadcrumbs sets the breadcrumb of each result from the ancestors of its
+// node in items (see BuildBreadcrumbs).
+func AddBreadcrumbs[R any, P interface {
+	*R
+	Breadcrumbed
+}](results []R, items []*Item) {
+	crumbs := BuildBreadcrumbs(items, BreadcrumbLevels)
+	for i := range results {
+		result := P(&results[i])
+		result.SetBreadcrumb(crumbs[result.NodeID()])
+	}
+}
+
+// AddItemBreadcrumbs sets the breadcrumb of each of nodes, as listed by get
+// and list, from its ancestors in items.
+func AddItemBreadcrumbs(nodes []*Item, items []*Item) {
+	crumbs := BuildBreadcrumbs(items, BreadcrumbLevels)
+	for _, node := range nodes {
+		node.Breadcrumb = crumbs[node.ID]
+	}
+}
+
 // IsRestricted returns true if rootID represents an active restriction (not empty and not "None").
 func IsRestricted(rootID string) bool {
 	return rootID != "" && rootID != "None"
@@ -146,7 +322,7 @@ func ValidateAccess(items []*Item, rootID, targetID, rootLabel, operation string
 		return nil
 	}
 	if !IsDescendantOf(items, rootID, targetID) {
-		return fmt.Errorf("%s denied: %s is not within %s %s", operation, targetID, rootLabel, rootID)
+		return Errorf(ErrAccessDenied, "%s denied: %s is not within %s %s", operation, targetID, rootLabel, rootID)
 	}
 	return nil
 }
@@ -160,3 +336,35 @@ func ValidateWriteAccess(items []*Item, writeRootID, targetID, operation string)
 func ValidateReadAccess(items []*Item, readRootID, targetID, operation string) error {
 	return ValidateAccess(items, readRootID, targetID, "read-root", operation)
 }
+
+// ExportSubtree returns rootID and its descendants from flat export nodes, in
+// export order. Other nodes, including ancestors of rootID, are left out.
+func ExportSubtree(nodes []ExportNode, rootID string) []ExportNode {
+	children := make(map[string][]string)
+	for _, node := range nodes {
+		if node.ParentID != nil {
+			children[*node.ParentID] = append(children[*node.ParentID], node.ID)
+		}
+	}
+
+	keep := map[string]bool{rootID: true}
+	queue := []string{rootID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, child := range children[id] {
+			if !keep[child] {
+				keep[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	var subtree []ExportNode
+	for _, node := range nodes {
+		if keep[node.ID] {
+			subtree = append(subtree, node)
+		}
+	}
+	return subtree
+}
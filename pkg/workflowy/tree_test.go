@@ -0,0 +1,197 @@
+package workflowy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildBreadcrumbs(t *testing.T) {
+	items := []*Item{
+		{
+			ID:   "work",
+			Name: "Work",
+			Children: []*Item{
+				{
+					ID:   "projects",
+					Name: "Projects",
+					Children: []*Item{
+						{
+							ID:   "alpha",
+							Name: "Alpha",
+							Children: []*Item{
+								{ID: "alpha-notes", Name: "Notes"},
+							},
+						},
+						{ID: "beta-notes", Name: "Notes"},
+					},
+				},
+			},
+		},
+		{ID: "home", Name: "Home"},
+	}
+
+	crumbs := BuildBreadcrumbs(items, 2)
+
+	assert.Equal(t, "Work > Projects", crumbs["alpha-notes"], "the top ancestors, not the nearest")
+	assert.Equal(t, "Work > Projects", crumbs["beta-notes"])
+	assert.Equal(t, "Work > Projects", crumbs["alpha"])
+	assert.Equal(t, "Work", crumbs["projects"])
+	assert.NotContains(t, crumbs, "work")
+	assert.NotContains(t, crumbs, "home")
+}
+
+type crumbResult struct {
+	ID         string
+	Breadcrumb string
+}
+
+func (r *crumbResult) NodeID() string                  { return r.ID }
+func (r *crumbResult) SetBreadcrumb(breadcrumb string) { r.Breadcrumb = breadcrumb }
+
+func TestAddBreadcrumbs(t *testing.T) {
+	items := []*Item{
+		{ID: "work", Name: "Work", Children: []*Item{
+			{ID: "projects", Name: "Projects", Children: []*Item{
+				{ID: "alpha", Name: "Alpha"},
+			}},
+		}},
+	}
+	results := []crumbResult{{ID: "alpha"}, {ID: "work"}, {ID: "missing"}}
+
+	AddBreadcrumbs(results, items)
+
+	assert.Equal(t, "Work > Projects", results[0].Breadcrumb)
+	assert.Empty(t, results[1].Breadcrumb)
+	assert.Empty(t, results[2].Breadcrumb)
+}
+
+func TestAddItemBreadcrumbs(t *testing.T) {
+	notes := &Item{ID: "notes", Name: "Notes"}
+	items := []*Item{{ID: "work", Name: "Work", Children: []*Item{
+		{ID: "projects", Name: "Projects", Children: []*Item{
+			{ID: "alpha", Name: "Alpha", Children: []*Item{notes}},
+		}},
+	}}}
+
+	AddItemBreadcrumbs([]*Item{notes, items[0]}, items)
+
+	assert.Equal(t, "Work > Projects", notes.Breadcrumb)
+	assert.Empty(t, items[0].Breadcrumb)
+}
+
+func TestExportSubtree(t *testing.T) {
+	parent := func(id string) *string { return &id }
+	nodes := []ExportNode{
+		{ID: "work"},
+		{ID: "projects", ParentID: parent("work")},
+		{ID: "alpha", ParentID: parent("projects")},
+		{ID: "home"},
+		{ID: "alpha-notes", ParentID: parent("alpha")},
+		{ID: "chores", ParentID: parent("home")},
+	}
+
+	ids := func(nodes []ExportNode) []string {
+		var ids []string
+		for _, node := range nodes {
+			ids = append(ids, node.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"projects", "alpha", "alpha-notes"}, ids(ExportSubtree(nodes, "projects")))
+	assert.Equal(t, []string{"chores"}, ids(ExportSubtree(nodes, "chores")))
+	assert.Empty(t, ExportSubtree(nodes, "missing"))
+}
+
+func TestPaginate(t *testing.T) {
+	list := &ListChildrenResponse{Items: []*Item{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}, {ID: "e"}}}
+
+	page := Paginate(list, 0, 2)
+	assert.Equal(t, []*Item{{ID: "a"}, {ID: "b"}}, page.Items)
+	assert.Equal(t, 5, page.Total)
+	assert.Equal(t, 2, page.NextOffset)
+
+	page = Paginate(list, page.NextOffset, 2)
+	assert.Equal(t, []*Item{{ID: "c"}, {ID: "d"}}, page.Items)
+	assert.Equal(t, 4, page.NextOffset)
+
+	page = Paginate(list, 4, 2)
+	assert.Equal(t, []*Item{{ID: "e"}}, page.Items)
+	assert.Zero(t, page.NextOffset, "last page has no next offset")
+
+	assert.Len(t, Paginate(list, 1, 0).Items, 4, "no limit returns the rest")
+	assert.Empty(t, Paginate(list, 10, 2).Items)
+	assert.Equal(t, 5, Paginate(list, 10, 2).Offset)
+}
+
+func TestFlattenCopy(t *testing.T) {
+	grandchild := &Item{ID: "grandchild"}
+	child := &Item{ID: "child", Children: []*Item{grandchild}}
+	root := &Item{ID: "root", Children: []*Item{child, {ID: "sibling"}}}
+
+	ids := func(items []*Item) []string {
+		var ids []string
+		for _, item := range items {
+			assert.Empty(t, item.Children)
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"root", "child", "grandchild", "sibling"}, ids(FlattenCopy(root, -1)))
+	assert.Equal(t, []string{"root", "child", "sibling"}, ids(FlattenCopy(root, 1)))
+	assert.Equal(t, []string{"root"}, ids(FlattenCopy(root, 0)))
+	assert.Len(t, root.Children, 2, "the tree is unchanged")
+	assert.Len(t, child.Children, 1)
+}
+
+func TestFindPath(t *testing.T) {
+	task := &Item{ID: "task"}
+	alpha := &Item{ID: "alpha", Children: []*Item{task}}
+	items := []*Item{{ID: "inbox"}, {ID: "projects", Children: []*Item{alpha}}}
+
+	assert.Equal(t, []*Item{items[1], alpha, task}, FindPath(items, "task"))
+	assert.Equal(t, []*Item{items[0]}, FindPath(items, "inbox"))
+	assert.Nil(t, FindPath(items, "missing"))
+}
+
+func TestFilterCompleted(t *testing.T) {
+	done := int64(1700000000)
+	items := []*Item{
+		{ID: "project", Children: []*Item{
+			{ID: "shipped", CompletedAt: &done, Children: []*Item{{ID: "step"}}},
+			{ID: "open", Children: []*Item{{ID: "checked", Completed: true}}},
+			{ID: "todo"},
+		}},
+		{ID: "idle"},
+	}
+	ids := func(items []*Item) []string {
+		var ids []string
+		for _, item := range items {
+			for _, flat := range FlattenCopy(item, -1) {
+				ids = append(ids, flat.ID)
+			}
+		}
+		return ids
+	}
+
+	assert.Equal(t, ids(items), ids(FilterCompleted(items, CompletedInclude)))
+	assert.Equal(t, []string{"project", "open", "todo", "idle"}, ids(FilterCompleted(items, CompletedExclude)))
+	only := FilterCompleted(items, CompletedOnly)
+	assert.Equal(t, []string{"project", "shipped", "open", "checked"}, ids(only))
+	assert.Len(t, items[0].Children, 3, "the tree is unchanged")
+
+	var flat []*Item
+	for _, item := range only {
+		flat = append(flat, FlattenCopy(item, -1)...)
+	}
+	assert.Equal(t, []string{"shipped", "checked"}, ids(CompletedItems(flat)))
+
+	root := FilterCompletedItem(&Item{ID: "root", CompletedAt: &done, Children: items}, CompletedExclude)
+	assert.Equal(t, "root", root.ID, "the item itself is kept")
+	assert.Len(t, root.Children, 2)
+
+	assert.NoError(t, ValidateCompletedMode(""))
+	assert.Error(t, ValidateCompletedMode("hide"))
+}
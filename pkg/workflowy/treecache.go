@@ -0,0 +1,109 @@
+package workflowy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mholzen/workflowy/pkg/cache"
+)
+
+// RefreshTreeCache applies a complete export to the tree cache, returning the
+// nodes it added, changed and removed.
+func RefreshTreeCache(tc *cache.TreeCache, resp *ExportNodesResponse) (cache.TreeDiff, error) {
+	if resp.Partial {
+		return cache.TreeDiff{}, fmt.Errorf("cannot refresh tree cache from an incomplete export")
+	}
+	nodes := make(map[string]json.RawMessage, len(resp.Nodes))
+	for _, node := range resp.Nodes {
+		data, err := json.Marshal(node)
+		if err != nil {
+			return cache.TreeDiff{}, fmt.Errorf("cannot encode node %s: %w", node.ID, err)
+		}
+		nodes[node.ID] = data
+	}
+	fetchedAt := resp.FetchedAt
+	if fetchedAt.IsZero() {
+		fetchedAt = time.Now()
+	}
+	return tc.Refresh(nodes, fetchedAt)
+}
+
+// RefreshTreeCacheSubtree fetches the descendants of rootID, a UUID, down to
+// depth levels with the GET API and applies them to the tree cache, returning
+// the nodes it added, changed and removed.
+func RefreshTreeCacheSubtree(ctx context.Context, client Client, tc *cache.TreeCache, rootID string, depth int) (cache.TreeDiff, error) {
+	fetchedAt := time.Now()
+	resp, err := client.ListChildrenRecursiveWithDepth(ctx, rootID, depth)
+	if err != nil {
+		return cache.TreeDiff{}, fmt.Errorf("cannot fetch subtree %s: %w", rootID, err)
+	}
+	nodes := make(map[string]json.RawMessage)
+	var add func(items []*Item, parentID *string) error
+	add = func(items []*Item, parentID *string) error {
+		for _, item := range items {
+			data, err := json.Marshal(ItemToExportNode(item, parentID))
+			if err != nil {
+				return fmt.Errorf("cannot encode node %s: %w", item.ID, err)
+			}
+			nodes[item.ID] = data
+			id := item.ID
+			if err := add(item.Children, &id); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var parentID *string
+	if rootID != "None" {
+		parentID = &rootID
+	}
+	if err := add(resp.Items, parentID); err != nil {
+		return cache.TreeDiff{}, err
+	}
+	return tc.RefreshSubtree(rootID, nodes, depth, fetchedAt)
+}
+
+// TreeCacheExport returns the nodes of the tree cache as an export response,
+// dated when the cache was last refreshed.
+func TreeCacheExport(tc *cache.TreeCache) (*ExportNodesResponse, error) {
+	if tc.IsEmpty() {
+		return nil, fmt.Errorf("tree cache is empty (run `workflowy cache refresh`)")
+	}
+	ids := make([]string, 0, len(tc.Nodes))
+	for id := range tc.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	resp := &ExportNodesResponse{Nodes: make([]ExportNode, 0, len(ids)), FetchedAt: time.Unix(tc.RefreshedAt, 0)}
+	for _, id := range ids {
+		var node ExportNode
+		if err := json.Unmarshal(tc.Nodes[id], &node); err != nil {
+			return nil, fmt.Errorf("cannot parse cached node %s: %w", id, err)
+		}
+		resp.Nodes = append(resp.Nodes, node)
+	}
+	return resp, nil
+}
+
+// ReadTreeCache returns the tree cache of the cache directory as an export response.
+func ReadTreeCache() (*ExportNodesResponse, error) {
+	tc, err := cache.LoadTreeCache()
+	if err != nil {
+		return nil, err
+	}
+	return TreeCacheExport(tc)
+}
+
+// ReadTreeCacheSnapshot returns a snapshot of the tree cache of the cache
+// directory, dated when it was last refreshed.
+func ReadTreeCacheSnapshot() (*Snapshot, error) {
+	resp, err := ReadTreeCache()
+	if err != nil {
+		return nil, err
+	}
+	return NewSnapshot(BuildTreeFromExport(resp.Nodes).Children, "cache", resp.FetchedAt), nil
+}
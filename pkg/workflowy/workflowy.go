@@ -1,6 +1,7 @@
 package workflowy
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"iter"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
@@ -17,6 +19,7 @@ import (
 	"github.com/mholzen/workflowy/pkg/cache"
 	"github.com/mholzen/workflowy/pkg/client"
 	"github.com/mholzen/workflowy/pkg/counter"
+	"github.com/mholzen/workflowy/pkg/paths"
 )
 
 // WithAPIKey sets up Bearer token authentication
@@ -89,7 +92,7 @@ func IsTargetKey(ctx context.Context, client Client, id string) (bool, error) {
 
 // ResolveShortID resolves a short ID to its full UUID by searching all nodes
 func ResolveShortID(ctx context.Context, client Client, shortID string) (string, error) {
-	slog.Info("resolving short ID, loading export tree", "short_id", shortID)
+	slog.InfoContext(ctx, "resolving short ID, loading export tree", "short_id", shortID)
 
 	resp, err := client.ExportNodesWithCache(ctx, false)
 	if err != nil {
@@ -105,69 +108,15 @@ func ResolveShortID(ctx context.Context, client Client, shortID string) (string,
 
 	switch len(matches) {
 	case 0:
-		return "", fmt.Errorf("no node found with short ID: %s", shortID)
+		return "", Errorf(ErrNotFound, "no node found with short ID: %s", shortID)
 	case 1:
-		slog.Info("resolved short ID", "short_id", shortID, "full_id", matches[0])
+		slog.InfoContext(ctx, "resolved short ID", "short_id", shortID, "full_id", matches[0])
 		return matches[0], nil
 	default:
-		return "", fmt.Errorf("multiple nodes match short ID %s: %v", shortID, matches)
+		return "", Errorf(ErrAmbiguousID, "multiple nodes match short ID %s: %v", shortID, matches)
 	}
 }
 
-// ResolveNodeID resolves target keys, short IDs, and sanitizes full IDs.
-// If client is nil, only sanitization is performed.
-func ResolveNodeID(ctx context.Context, client Client, id string) (string, error) {
-	if id == "" || id == "None" {
-		return id, nil
-	}
-
-	if client == nil {
-		return SanitizeNodeID(id), nil
-	}
-
-	isTarget, err := IsTargetKey(ctx, client, id)
-	if err != nil {
-		return "", fmt.Errorf("cannot check target: %w", err)
-	}
-	if isTarget {
-		return id, nil
-	}
-
-	sanitized := SanitizeNodeID(id)
-
-	if IsShortID(sanitized) {
-		return ResolveShortID(ctx, client, sanitized)
-	}
-
-	return sanitized, nil
-}
-
-// ResolveNodeIDToUUID resolves any node identifier to a full UUID.
-// Unlike ResolveNodeID, this always returns a UUID even for target keys like "inbox".
-func ResolveNodeIDToUUID(ctx context.Context, client Client, id string) (string, error) {
-	if id == "" || id == "None" {
-		return id, nil
-	}
-
-	if client == nil {
-		return SanitizeNodeID(id), nil
-	}
-
-	isTarget, err := IsTargetKey(ctx, client, id)
-	if err != nil {
-		return "", fmt.Errorf("cannot check target: %w", err)
-	}
-	if isTarget {
-		item, err := client.GetItem(ctx, id)
-		if err != nil {
-			return "", fmt.Errorf("cannot resolve target %q to UUID: %w", id, err)
-		}
-		return item.ID, nil
-	}
-
-	return ResolveNodeID(ctx, client, id)
-}
-
 // ExpandTilde expands a leading ~ to the user's home directory
 func ExpandTilde(path string) string {
 	if !strings.HasPrefix(path, "~") {
@@ -180,38 +129,60 @@ func ExpandTilde(path string) string {
 	return filepath.Join(home, path[1:])
 }
 
-// ResolveAPIKey resolves the API key with precedence:
-// 1. Explicit file path (if different from default)
-// 2. WORKFLOWY_API_KEY environment variable
-// 3. Default file path
-func ResolveAPIKey(apiKeyFile, defaultAPIKeyFile string) (client.Option, error) {
-	apiKeyFile = ExpandTilde(apiKeyFile)
-	defaultAPIKeyFile = ExpandTilde(defaultAPIKeyFile)
+// APIKeySource is where the API key is read from: an environment variable or a file.
+type APIKeySource struct {
+	Env  string
+	File string
+}
 
-	if apiKeyFile != defaultAPIKeyFile {
-		slog.Debug("using API key from explicit file", "file", apiKeyFile)
-		return WithAPIKeyFromFile(apiKeyFile)
+// String returns "env:<variable>" or "file:<path>".
+func (s APIKeySource) String() string {
+	if s.Env != "" {
+		return "env:" + s.Env
 	}
+	return "file:" + s.File
+}
 
-	if envKey := os.Getenv("WORKFLOWY_API_KEY"); envKey != "" {
-		slog.Debug("using API key from WORKFLOWY_API_KEY environment variable")
-		return WithAPIKey(strings.TrimSpace(envKey)), nil
+// ResolveAPIKeySource returns where the API key is read from, with precedence:
+// 1. WORKFLOWY_API_KEY environment variable, if not blank
+// 2. apiKeyFile, the --api-key-file flag, if set
+// 3. defaultAPIKeyFile
+func ResolveAPIKeySource(apiKeyFile, defaultAPIKeyFile string) APIKeySource {
+	if strings.TrimSpace(os.Getenv(paths.APIKeyEnv)) != "" {
+		return APIKeySource{Env: paths.APIKeyEnv}
+	}
+	if apiKeyFile == "" {
+		apiKeyFile = defaultAPIKeyFile
 	}
+	return APIKeySource{File: ExpandTilde(apiKeyFile)}
+}
 
-	slog.Debug("using API key from default file", "file", defaultAPIKeyFile)
-	return WithAPIKeyFromFile(defaultAPIKeyFile)
+// ResolveAPIKey reads the API key from the source returned by ResolveAPIKeySource.
+func ResolveAPIKey(apiKeyFile, defaultAPIKeyFile string) (client.Option, error) {
+	source := ResolveAPIKeySource(apiKeyFile, defaultAPIKeyFile)
+	if source.Env != "" {
+		slog.Debug("using API key from environment variable", "variable", source.Env)
+		return WithAPIKey(strings.TrimSpace(os.Getenv(source.Env))), nil
+	}
+	return WithAPIKeyFromFile(source.File)
 }
 
 // WorkflowyClient wraps the generic Client with Workflowy-specific methods
 type WorkflowyClient struct {
 	*client.Client
-	opts []client.Option
+	opts   []client.Option
+	limits LengthLimits
 }
 
 // NewWorkflowyClient creates a new Workflowy API client
 func NewWorkflowyClient(opts ...client.Option) *WorkflowyClient {
 	c := client.New("https://workflowy.com/api/v1", opts...)
-	return &WorkflowyClient{Client: c, opts: opts}
+	return &WorkflowyClient{Client: c, opts: opts, limits: DefaultLengthLimits}
+}
+
+// SetLengthLimits sets the limits and policy applied to names and notes by CreateNode and UpdateNode.
+func (wc *WorkflowyClient) SetLengthLimits(limits LengthLimits) {
+	wc.limits = limits
 }
 
 // Item represents a Workflowy item with all its properties
@@ -224,7 +195,16 @@ type Item struct {
 	CreatedAt   int64                  `json:"createdAt"`
 	ModifiedAt  int64                  `json:"modifiedAt"`
 	CompletedAt *int64                 `json:"completedAt"`
+	Completed   bool                   `json:"completed,omitempty"` // set by the export API, which may omit completedAt
 	Children    []*Item                `json:"children,omitempty"`
+	MirrorOf    string                 `json:"mirror_of,omitempty"`  // set on mirror copies whose original was inlined
+	Breadcrumb  string                 `json:"breadcrumb,omitempty"` // set by AddItemBreadcrumbs
+}
+
+// IsCompleted reports whether the item is completed, from whichever of
+// completedAt and completed its data source sets.
+func (item *Item) IsCompleted() bool {
+	return item.CompletedAt != nil || item.Completed
 }
 
 // ListChildrenResponse represents the response from list nodes API
@@ -256,6 +236,9 @@ func (r *CreateNodeRequest) SetPosition(position string) error {
 // CreateNodeResponse represents the response from nodes-create API
 type CreateNodeResponse struct {
 	ItemID string `json:"item_id"`
+	// set when an oversize name or note was split or truncated
+	ContinuationIDs []string `json:"continuation_ids,omitempty"`
+	Truncated       bool     `json:"truncated,omitempty"`
 }
 
 // UpdateNodeRequest represents the request body for nodes-update API
@@ -268,6 +251,9 @@ type UpdateNodeRequest struct {
 // UpdateNodeResponse represents the response from nodes-update API
 type UpdateNodeResponse struct {
 	Status string `json:"status"`
+	// set when an oversize name or note was split or truncated
+	ContinuationIDs []string `json:"continuation_ids,omitempty"`
+	Truncated       bool     `json:"truncated,omitempty"`
 }
 
 // ValidatePosition validates that position is either empty, "top", or "bottom".
@@ -370,7 +356,7 @@ func (wc *WorkflowyClient) ListChildrenRecursiveWithDepth(ctx context.Context, i
 // fetchChildrenRecursively is a helper function to recursively populate children
 // depth parameter controls how many more levels deep to fetch
 func (wc *WorkflowyClient) fetchChildrenRecursively(ctx context.Context, item *Item, depth int) error {
-	slog.Debug("fetching children recursively", "item_id", item.ID, "depth", depth)
+	slog.DebugContext(ctx, "fetching children recursively", "item_id", item.ID, "depth", depth)
 
 	// Stop recursion if depth is 0 or negative
 	if depth <= 0 {
@@ -398,25 +384,74 @@ func (wc *WorkflowyClient) fetchChildrenRecursively(ctx context.Context, item *I
 }
 
 // CreateNode creates a new node in Workflowy
+// A name or note over the length limits is handled according to the oversize
+// policy; if creating a continuation fails, the response of the created node
+// is returned along with the error.
 func (wc *WorkflowyClient) CreateNode(ctx context.Context, req *CreateNodeRequest) (*CreateNodeResponse, error) {
+	fitted := *req
+	if req.Note != nil {
+		note := *req.Note
+		fitted.Note = &note
+	}
+	rest, truncated, err := wc.limits.fit(&fitted.Name, fitted.Note)
+	if err != nil {
+		return nil, err
+	}
+
 	var resp CreateNodeResponse
-	err := wc.Do(ctx, "POST", "/nodes", req, &resp)
+	err = wc.Do(ctx, "POST", "/nodes", &fitted, &resp)
 	if err != nil {
 		return nil, err
 	}
+	resp.Truncated = truncated
+	if truncated {
+		slog.WarnContext(ctx, "truncated oversize content", "id", resp.ItemID)
+	}
 
+	if len(rest) > 0 {
+		resp.ContinuationIDs, err = wc.createContinuations(ctx, resp.ItemID, rest, "bottom")
+		if err != nil {
+			return &resp, err
+		}
+	}
 	return &resp, nil
 }
 
 // UpdateNode updates an existing node in Workflowy
+// Oversize content is handled as in CreateNode; continuations are created
+// before the node's existing children.
 func (wc *WorkflowyClient) UpdateNode(ctx context.Context, itemID string, req *UpdateNodeRequest) (*UpdateNodeResponse, error) {
+	fitted := *req
+	if req.Name != nil {
+		name := *req.Name
+		fitted.Name = &name
+	}
+	if req.Note != nil {
+		note := *req.Note
+		fitted.Note = &note
+	}
+	rest, truncated, err := wc.limits.fit(fitted.Name, fitted.Note)
+	if err != nil {
+		return nil, err
+	}
+
 	var resp UpdateNodeResponse
 	path := fmt.Sprintf("/nodes/%s", itemID)
-	err := wc.Do(ctx, "POST", path, req, &resp)
+	err = wc.Do(ctx, "POST", path, &fitted, &resp)
 	if err != nil {
 		return nil, err
 	}
+	resp.Truncated = truncated
+	if truncated {
+		slog.WarnContext(ctx, "truncated oversize content", "id", itemID)
+	}
 
+	if len(rest) > 0 {
+		resp.ContinuationIDs, err = wc.createContinuations(ctx, itemID, rest, "top")
+		if err != nil {
+			return &resp, err
+		}
+	}
 	return &resp, nil
 }
 
@@ -481,6 +516,12 @@ type ExportNode struct {
 // ExportNodesResponse represents the response from GET /nodes-export
 type ExportNodesResponse struct {
 	Nodes []ExportNode `json:"nodes"`
+	// NextCursor is set when the export continues on another page
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Partial is set when some nodes could not be downloaded
+	Partial bool `json:"-"`
+	// FetchedAt is when the nodes were fetched from the API, including for cached responses
+	FetchedAt time.Time `json:"-"`
 }
 
 // Target represents a Workflowy target (shortcuts or system targets)
@@ -495,15 +536,74 @@ type ListTargetsResponse struct {
 	Targets []Target `json:"targets"`
 }
 
-// ExportNodes retrieves all nodes from Workflowy (rate limited to 1 req/min)
+// maxExportPages bounds how many pages of /nodes-export are followed
+const maxExportPages = 1000
+
+// ExportNodes retrieves all nodes from Workflowy (rate limited to 1 req/min).
+// Paged exports are followed to the end. If a page cannot be downloaded or parsed
+// completely, the complete node records received are returned with Partial set.
 func (wc *WorkflowyClient) ExportNodes(ctx context.Context) (*ExportNodesResponse, error) {
-	var resp ExportNodesResponse
-	err := wc.Do(ctx, "GET", "/nodes-export", nil, &resp)
-	if err != nil {
-		return nil, err
+	result := &ExportNodesResponse{}
+	path := "/nodes-export"
+	for page := 0; page < maxExportPages; page++ {
+		data, err := wc.Get(ctx, path)
+		var resp ExportNodesResponse
+		if err == nil {
+			err = json.Unmarshal(data, &resp)
+		}
+		if err != nil {
+			salvaged := SalvageExportNodes(data)
+			if len(result.Nodes)+len(salvaged) == 0 {
+				return nil, err
+			}
+			slog.WarnContext(ctx, "export response incomplete, using salvaged nodes", "page", page, "salvaged", len(salvaged), "error", err)
+			result.Nodes = append(result.Nodes, salvaged...)
+			result.Partial = true
+			return result, nil
+		}
+
+		result.Nodes = append(result.Nodes, resp.Nodes...)
+		if resp.NextCursor == "" {
+			return result, nil
+		}
+		path = "/nodes-export?cursor=" + url.QueryEscape(resp.NextCursor)
 	}
+	return nil, fmt.Errorf("export exceeded %d pages", maxExportPages)
+}
 
-	return &resp, nil
+// SalvageExportNodes returns the complete node records found in a possibly
+// truncated /nodes-export response body.
+func SalvageExportNodes(data []byte) []ExportNode {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		if key != "nodes" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil
+			}
+			continue
+		}
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			return nil
+		}
+		var nodes []ExportNode
+		for dec.More() {
+			var node ExportNode
+			if err := dec.Decode(&node); err != nil {
+				break
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+	return nil
 }
 
 // ListTargets retrieves all available targets (shortcuts and system targets)
@@ -517,6 +617,26 @@ func (wc *WorkflowyClient) ListTargets(ctx context.Context) (*ListTargetsRespons
 	return &resp, nil
 }
 
+// RateLimit returns the API quota reported by the most recent response,
+// issuing a lightweight request first if none has been made yet. It returns
+// false when the API does not report rate limits.
+func RateLimit(ctx context.Context, c Client) (client.RateLimit, bool, error) {
+	reporter, ok := c.(interface {
+		RateLimit() (client.RateLimit, bool)
+	})
+	if !ok {
+		return client.RateLimit{}, false, fmt.Errorf("client does not report rate limits")
+	}
+	if limit, known := reporter.RateLimit(); known {
+		return limit, true, nil
+	}
+	if _, err := c.ListTargets(ctx); err != nil {
+		return client.RateLimit{}, false, fmt.Errorf("cannot query API: %w", err)
+	}
+	limit, known := reporter.RateLimit()
+	return limit, known, nil
+}
+
 // BackupNode represents a node from a Workflowy backup file
 // Backup files use different field names than the API (nm, ch, ct, lm)
 type BackupNode struct {
@@ -582,22 +702,78 @@ func ReadBackupFile(filename string) ([]*Item, error) {
 	return items, nil
 }
 
-// ReadLatestBackup reads the most recent backup file from Dropbox folder
+// ReadLatestBackup reads the most recent backup file from the Dropbox (or, on
+// Windows, OneDrive) backup folders
 func ReadLatestBackup() ([]*Item, error) {
-	homeDir, err := os.UserHomeDir()
+	latest, err := LatestBackupFile()
 	if err != nil {
-		return nil, fmt.Errorf("could not get home directory: %w", err)
+		return nil, err
 	}
 
-	dropboxPath := filepath.Join(homeDir, "Dropbox", "Apps", "Workflowy", "Data")
+	slog.Debug("reading latest backup file", "file", filepath.Base(latest))
+	return ReadBackupFile(latest)
+}
+
+// BackupWarnAge is the age past which reading a backup logs a warning
+const BackupWarnAge = 24 * time.Hour
+
+// CheckBackupAge dates backupFile by when it was written. It fails if the
+// backup is older than maxAge (when positive), and logs a warning if it is
+// older than BackupWarnAge. Backups that cannot be found are left for the read
+// to report.
+func CheckBackupAge(backupFile string, maxAge time.Duration, now time.Time) error {
+	info, err := os.Stat(backupFile)
+	if err != nil {
+		return nil
+	}
+
+	age := now.Sub(info.ModTime()).Round(time.Minute)
+	if maxAge > 0 && age > maxAge {
+		return fmt.Errorf("backup %s is %s old, older than the maximum backup age of %s", filepath.Base(backupFile), age, maxAge)
+	}
+	if age > BackupWarnAge {
+		slog.Warn("backup is stale: changes since it was written are missing", "file", filepath.Base(backupFile), "age", age.String())
+	}
+	return nil
+}
+
+// ReadCheckedBackup reads backupFile, or else the latest backup of provider,
+// once its age is checked (see CheckBackupAge). The file of the latest backup,
+// and so its age, is only known to providers that implement BackupLocator.
+func ReadCheckedBackup(provider BackupProvider, backupFile string, maxAge time.Duration, now time.Time) ([]*Item, error) {
+	path := backupFile
+	if locator, ok := provider.(BackupLocator); ok && path == "" {
+		// without backups, ReadLatestBackup reports the error
+		path, _ = locator.LatestBackupFile()
+	}
+	if path == "" {
+		return provider.ReadLatestBackup()
+	}
+	if err := CheckBackupAge(path, maxAge, now); err != nil {
+		return nil, err
+	}
+	return provider.ReadBackupFile(path)
+}
 
-	files, err := filepath.Glob(filepath.Join(dropboxPath, "*.workflowy.backup"))
+// LatestBackupFile returns the path of the most recent backup file in the
+// backup folders
+func LatestBackupFile() (string, error) {
+	dirs, err := paths.BackupDirs()
 	if err != nil {
-		return nil, fmt.Errorf("cannot search for backup files: %w", err)
+		return "", err
+	}
+
+	var files []string
+	for _, dir := range dirs {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.workflowy.backup"))
+		if err != nil {
+			return "", fmt.Errorf("cannot search for backup files: %w", err)
+		}
+		files = append(files, matches...)
 	}
 
 	if len(files) == 0 {
-		return nil, fmt.Errorf("no backup files found in %s", dropboxPath)
+		return "", fmt.Errorf("no backup files found in %s", strings.Join(dirs, ", "))
 	}
 
 	// Find the most recent file
@@ -613,9 +789,7 @@ func ReadLatestBackup() ([]*Item, error) {
 			latest = file
 		}
 	}
-
-	slog.Debug("reading latest backup file", "file", filepath.Base(latest))
-	return ReadBackupFile(latest)
+	return latest, nil
 }
 
 // ExportNodeToItem converts an ExportNode to an Item
@@ -629,6 +803,7 @@ func ExportNodeToItem(node ExportNode) *Item {
 		CreatedAt:   node.CreatedAt,
 		ModifiedAt:  node.ModifiedAt,
 		CompletedAt: node.CompletedAt,
+		Completed:   node.Completed,
 		Children:    nil, // Will be populated during tree building
 	}
 }
@@ -704,19 +879,20 @@ func (wc *WorkflowyClient) ExportNodesWithCache(ctx context.Context, forceRefres
 	// Try to read cache first
 	cachedData, err := cache.ReadExportCache()
 	if err != nil {
-		slog.Warn("cannot read cache, will fetch from API", "error", err)
+		slog.WarnContext(ctx, "cannot read cache, will fetch from API", "error", err)
 	}
 
 	// Use cache if valid and not forcing refresh
 	if !forceRefresh && cache.IsCacheValid(cachedData) {
 		age := cache.GetCacheAge(cachedData)
-		slog.Info("using cached export data", "age_seconds", int(age.Seconds()))
+		slog.InfoContext(ctx, "using cached export data", "age_seconds", int(age.Seconds()))
 
 		// Unmarshal cached data
 		var resp ExportNodesResponse
 		if err := json.Unmarshal(cachedData.Data, &resp); err != nil {
-			slog.Warn("cannot unmarshal cached data, will fetch from API", "error", err)
+			slog.WarnContext(ctx, "cannot unmarshal cached data, will fetch from API", "error", err)
 		} else {
+			resp.FetchedAt = time.Unix(cachedData.Timestamp, 0)
 			return &resp, nil
 		}
 	}
@@ -731,30 +907,85 @@ func (wc *WorkflowyClient) ExportNodesWithCache(ctx context.Context, forceRefres
 	}
 
 	// Fetch fresh data from API
-	slog.Info("fetching fresh export data from API")
+	slog.InfoContext(ctx, "fetching fresh export data from API")
+	fetchedAt := time.Now()
 	resp, err := wc.ExportNodes(ctx)
 	if err != nil {
 		// If API call fails, try to use stale cache as fallback
 		if cachedData != nil {
 			age := cache.GetCacheAge(cachedData)
-			slog.Warn("API call failed, using stale cache", "age_seconds", int(age.Seconds()))
+			slog.WarnContext(ctx, "API call failed, using stale cache", "age_seconds", int(age.Seconds()))
 
 			var fallbackResp ExportNodesResponse
 			if unmarshalErr := json.Unmarshal(cachedData.Data, &fallbackResp); unmarshalErr == nil {
+				fallbackResp.FetchedAt = time.Unix(cachedData.Timestamp, 0)
 				return &fallbackResp, nil
 			}
 		}
+		// Without an export cache, the tree cache keeps the tree available offline
+		if fallbackResp, cacheErr := ReadTreeCache(); cacheErr == nil {
+			slog.WarnContext(ctx, "API call failed, using tree cache", "age_seconds", int(time.Since(fallbackResp.FetchedAt).Seconds()))
+			return fallbackResp, nil
+		}
 		return nil, fmt.Errorf("cannot fetch export data: %w", err)
 	}
 
+	resp.FetchedAt = fetchedAt
+
+	// Never cache a partial export; prefer a complete stale cache when there is one
+	if resp.Partial {
+		if cachedData != nil {
+			var fallbackResp ExportNodesResponse
+			if unmarshalErr := json.Unmarshal(cachedData.Data, &fallbackResp); unmarshalErr == nil {
+				fallbackResp.FetchedAt = time.Unix(cachedData.Timestamp, 0)
+				age := cache.GetCacheAge(cachedData)
+				slog.WarnContext(ctx, "export incomplete, using stale cache", "age_seconds", int(age.Seconds()))
+				return &fallbackResp, nil
+			}
+		}
+		slog.WarnContext(ctx, "export incomplete, returning partial data without caching", "nodes", len(resp.Nodes))
+		return resp, nil
+	}
+
 	// Write to cache
 	if err := cache.WriteExportCache(resp); err != nil {
-		slog.Warn("cannot write cache (continuing anyway)", "error", err)
+		slog.WarnContext(ctx, "cannot write cache (continuing anyway)", "error", err)
+	}
+	if err := updateTreeCache(resp); err != nil {
+		slog.WarnContext(ctx, "cannot update tree cache (continuing anyway)", "error", err)
 	}
 
 	return resp, nil
 }
 
+// ReadCachedExport returns the export cache whatever its age, or the tree
+// cache when there is none, without calling the API.
+func ReadCachedExport() (*ExportNodesResponse, error) {
+	cachedData, err := cache.ReadExportCache()
+	if err != nil {
+		return nil, err
+	}
+	if cachedData != nil {
+		var resp ExportNodesResponse
+		if err := json.Unmarshal(cachedData.Data, &resp); err != nil {
+			return nil, fmt.Errorf("cannot parse cached export: %w", err)
+		}
+		resp.FetchedAt = time.Unix(cachedData.Timestamp, 0)
+		return &resp, nil
+	}
+	return ReadTreeCache()
+}
+
+// updateTreeCache applies a fresh export to the tree cache of the cache directory.
+func updateTreeCache(resp *ExportNodesResponse) error {
+	tc, err := cache.LoadTreeCache()
+	if err != nil {
+		return err
+	}
+	_, err = RefreshTreeCache(tc, resp)
+	return err
+}
+
 // ItemNode wraps Item to implement counter.TreeProvider interface
 type ItemNode struct {
 	item     *Item
@@ -850,6 +1081,16 @@ func CountDescendants(item *Item, threshold float64) Descendants {
 	return descendantTreeCount
 }
 
+// DescendantCounts maps the ID of each node in root to its descendant count.
+func DescendantCounts(root Descendants) map[string]int {
+	counts := make(map[string]int)
+	for _, node := range counter.CollectAllNodes(root) {
+		item := (**node.NodeValue()).Item()
+		counts[item.ID] = node.Count
+	}
+	return counts
+}
+
 // NodeWithTimestamps combines a descendant count node with timestamp information
 type NodeWithTimestamps struct {
 	Count      Descendants
@@ -3,12 +3,17 @@ package workflowy
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/mholzen/workflowy/pkg/client"
+	"github.com/mholzen/workflowy/pkg/paths"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -539,3 +544,424 @@ func TestSanitizeNodeID(t *testing.T) {
 		})
 	}
 }
+
+const exportBody = `{"nodes":[{"id":"a","name":"First"},{"id":"b","name":"Second"},{"id":"c","name":"Third"}]}`
+
+func TestWorkflowyClient_ExportNodes_ResumesTruncatedBody(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Accept-Ranges", "bytes")
+		if rng := r.Header.Get("Range"); rng != "" {
+			var start int
+			_, err := fmt.Sscanf(rng, "bytes=%d-", &start)
+			require.NoError(t, err)
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(exportBody)-1, len(exportBody)))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte(exportBody[start:]))
+			return
+		}
+		// declare the full length but stop halfway through
+		w.Header().Set("Content-Length", fmt.Sprint(len(exportBody)))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(exportBody[:40]))
+	}))
+	defer server.Close()
+
+	wc := &WorkflowyClient{Client: client.New(server.URL)}
+	resp, err := wc.ExportNodes(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, resp.Partial)
+	assert.Len(t, resp.Nodes, 3)
+	assert.Equal(t, 2, requests)
+}
+
+func TestWorkflowyClient_ExportNodes_SalvagesTruncatedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprint(len(exportBody)))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(exportBody[:70]))
+	}))
+	defer server.Close()
+
+	wc := &WorkflowyClient{Client: client.New(server.URL)}
+	resp, err := wc.ExportNodes(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, resp.Partial)
+	require.Len(t, resp.Nodes, 2)
+	assert.Equal(t, "b", resp.Nodes[1].ID)
+}
+
+func TestWorkflowyClient_ExportNodes_FollowsCursor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cursor") == "page2" {
+			w.Write([]byte(`{"nodes":[{"id":"c","name":"Third"}]}`))
+			return
+		}
+		w.Write([]byte(`{"nodes":[{"id":"a","name":"First"},{"id":"b","name":"Second"}],"next_cursor":"page2"}`))
+	}))
+	defer server.Close()
+
+	wc := &WorkflowyClient{Client: client.New(server.URL)}
+	resp, err := wc.ExportNodes(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, resp.Partial)
+	assert.Len(t, resp.Nodes, 3)
+	assert.Empty(t, resp.NextCursor)
+}
+
+func TestWorkflowyClient_ExportNodes_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	wc := &WorkflowyClient{Client: client.New(server.URL)}
+	resp, err := wc.ExportNodes(context.Background())
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+}
+
+func TestSalvageExportNodes(t *testing.T) {
+	assert.Len(t, SalvageExportNodes([]byte(exportBody)), 3)
+	assert.Len(t, SalvageExportNodes([]byte(exportBody[:len(exportBody)-5])), 2)
+	assert.Len(t, SalvageExportNodes([]byte(`{"meta":{"x":1},"nodes":[{"id":"a"},{"id"`)), 1)
+	assert.Empty(t, SalvageExportNodes([]byte(`{"nod`)))
+	assert.Empty(t, SalvageExportNodes(nil))
+}
+
+func TestWorkflowyClient_GetItem_RevalidatesWithETag(t *testing.T) {
+	var transfers, notModified int
+	etag := `"v1"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			etag = `"v2"`
+			json.NewEncoder(w).Encode(UpdateNodeResponse{Status: "ok"})
+			return
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		transfers++
+		w.Header().Set("ETag", etag)
+		json.NewEncoder(w).Encode(GetItemResponse{Node: Item{ID: "test", Name: "Name " + etag}})
+	}))
+	defer server.Close()
+
+	wc := &WorkflowyClient{Client: client.New(server.URL)}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		item, err := wc.GetItem(ctx, "test")
+		require.NoError(t, err)
+		assert.Equal(t, `Name "v1"`, item.Name)
+	}
+	assert.Equal(t, 1, transfers)
+	assert.Equal(t, 2, notModified)
+
+	name := "Updated"
+	_, err := wc.UpdateNode(ctx, "test", &UpdateNodeRequest{Name: &name})
+	require.NoError(t, err)
+
+	item, err := wc.GetItem(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, `Name "v2"`, item.Name)
+	assert.Equal(t, 2, transfers)
+}
+
+func TestWorkflowyClient_GetItem_RefetchesWhenNotModifiedIsNoLongerCached(t *testing.T) {
+	var wc *WorkflowyClient
+	var transfers int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			json.NewEncoder(w).Encode(UpdateNodeResponse{Status: "ok"})
+			return
+		}
+		if r.Header.Get("If-None-Match") != "" {
+			// a write completes while the revalidation is in flight
+			name := "Updated"
+			_, err := wc.UpdateNode(r.Context(), "test", &UpdateNodeRequest{Name: &name})
+			assert.NoError(t, err)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		transfers++
+		w.Header().Set("ETag", `"v1"`)
+		json.NewEncoder(w).Encode(GetItemResponse{Node: Item{ID: "test", Name: "Name"}})
+	}))
+	defer server.Close()
+
+	wc = &WorkflowyClient{Client: client.New(server.URL)}
+	ctx := context.Background()
+
+	_, err := wc.GetItem(ctx, "test")
+	require.NoError(t, err)
+	item, err := wc.GetItem(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, "Name", item.Name)
+	assert.Equal(t, 2, transfers)
+}
+
+func TestWorkflowyClient_GetItem_KeepsCacheAfterFailedWrite(t *testing.T) {
+	var transfers int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			http.Error(w, "invalid name", http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		transfers++
+		w.Header().Set("ETag", `"v1"`)
+		json.NewEncoder(w).Encode(GetItemResponse{Node: Item{ID: "test", Name: "Name"}})
+	}))
+	defer server.Close()
+
+	wc := &WorkflowyClient{Client: client.New(server.URL)}
+	ctx := context.Background()
+
+	_, err := wc.GetItem(ctx, "test")
+	require.NoError(t, err)
+	name := ""
+	_, err = wc.UpdateNode(ctx, "test", &UpdateNodeRequest{Name: &name})
+	require.Error(t, err)
+	_, err = wc.GetItem(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, 1, transfers)
+}
+
+func TestRateLimit_ReportsHeadersAndWaitsWhenExhausted(t *testing.T) {
+	var requests []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, time.Now())
+		w.Header().Set("RateLimit-Limit", "60")
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(2-len(requests)))
+		w.Header().Set("RateLimit-Reset", "1")
+		json.NewEncoder(w).Encode(ListTargetsResponse{})
+	}))
+	defer server.Close()
+
+	wc := &WorkflowyClient{Client: client.New(server.URL)}
+	ctx := context.Background()
+
+	limit, known, err := RateLimit(ctx, wc)
+	require.NoError(t, err)
+	require.True(t, known)
+	assert.Equal(t, 60, limit.Limit)
+	assert.Equal(t, 1, limit.Remaining)
+	assert.WithinDuration(t, time.Now().Add(time.Second), limit.Reset, time.Second)
+
+	// the quota is nearly exhausted, so the next request waits for the reset
+	_, err = wc.ListTargets(ctx)
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.GreaterOrEqual(t, requests[1].Sub(requests[0]), 900*time.Millisecond)
+}
+
+func TestRateLimit_NotReported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ListTargetsResponse{})
+	}))
+	defer server.Close()
+
+	_, known, err := RateLimit(context.Background(), &WorkflowyClient{Client: client.New(server.URL)})
+	require.NoError(t, err)
+	assert.False(t, known)
+}
+
+func TestRetry_RetriesTransientErrorsWithBody(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req UpdateNodeRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		bodies = append(bodies, *req.Name)
+		switch len(bodies) {
+		case 1, 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			json.NewEncoder(w).Encode(UpdateNodeResponse{Status: "ok"})
+		}
+	}))
+	defer server.Close()
+
+	wc := &WorkflowyClient{Client: client.New(server.URL, client.WithRetry(3, time.Millisecond))}
+	name := "Updated"
+	_, err := wc.UpdateNode(context.Background(), "test", &UpdateNodeRequest{Name: &name})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Updated", "Updated", "Updated"}, bodies)
+}
+
+func TestRetry_GivesUp(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		requests   int
+	}{
+		{name: "retries exhausted", status: http.StatusBadGateway, requests: 3},
+		{name: "client error", status: http.StatusNotFound, requests: 1},
+		{name: "retry-after too long", status: http.StatusTooManyRequests, retryAfter: "3600", requests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			wc := &WorkflowyClient{Client: client.New(server.URL, client.WithRetry(2, time.Millisecond))}
+			_, err := wc.ExportNodes(context.Background())
+
+			var apiErr *client.APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.status, apiErr.Status)
+			assert.Equal(t, tt.requests, requests)
+		})
+	}
+}
+
+func TestRetry_DoesNotRetryWritesOnServerError(t *testing.T) {
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.Method]++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	wc := &WorkflowyClient{Client: client.New(server.URL, client.WithRetry(2, time.Millisecond))}
+	_, err := wc.CreateNode(context.Background(), &CreateNodeRequest{ParentID: "None", Name: "New"})
+	require.Error(t, err)
+	_, err = wc.DeleteNode(context.Background(), "test")
+	require.Error(t, err)
+
+	// the create may have been applied: retrying could duplicate the node
+	assert.Equal(t, 1, requests[http.MethodPost])
+	assert.Equal(t, 3, requests[http.MethodDelete])
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	wait, ok := client.ParseRetryAfter("120", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, wait)
+
+	wait, ok = client.ParseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, wait)
+
+	_, ok = client.ParseRetryAfter("", now)
+	assert.False(t, ok)
+	_, ok = client.ParseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
+
+func TestCheckBackupAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "2025-03-01.workflowy.backup")
+	require.NoError(t, os.WriteFile(path, []byte("[]"), 0644))
+	written := time.Date(2025, 3, 1, 6, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path, written, written))
+
+	now := written.Add(30 * time.Hour)
+	assert.NoError(t, CheckBackupAge(path, 0, now))
+	assert.NoError(t, CheckBackupAge(path, 48*time.Hour, now))
+	err := CheckBackupAge(path, 24*time.Hour, now)
+	assert.ErrorContains(t, err, "2025-03-01.workflowy.backup is 30h0m0s old, older than the maximum backup age of 24h0m0s")
+
+	assert.NoError(t, CheckBackupAge(filepath.Join(t.TempDir(), "missing.backup"), time.Hour, now))
+}
+
+type locatedBackupProvider struct {
+	latest string
+	read   []string
+}
+
+func (p *locatedBackupProvider) ReadBackupFile(filename string) ([]*Item, error) {
+	p.read = append(p.read, filename)
+	return []*Item{{ID: filename}}, nil
+}
+
+func (p *locatedBackupProvider) ReadLatestBackup() ([]*Item, error) {
+	return nil, fmt.Errorf("the latest backup is read through its file")
+}
+
+func (p *locatedBackupProvider) LatestBackupFile() (string, error) {
+	return p.latest, nil
+}
+
+func TestReadCheckedBackup_ChecksTheProvidersLatestBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "2025-03-01.workflowy.backup")
+	require.NoError(t, os.WriteFile(path, []byte("[]"), 0644))
+	written := time.Date(2025, 3, 1, 6, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path, written, written))
+	now := written.Add(30 * time.Hour)
+
+	provider := &locatedBackupProvider{latest: path}
+	_, err := ReadCheckedBackup(provider, "", 24*time.Hour, now)
+	assert.ErrorContains(t, err, "2025-03-01.workflowy.backup is 30h0m0s old")
+	assert.Empty(t, provider.read)
+
+	items, err := ReadCheckedBackup(provider, "", 48*time.Hour, now)
+	require.NoError(t, err)
+	assert.Equal(t, path, items[0].ID, "reads the file whose age was checked")
+}
+
+func TestResolveAPIKeySource(t *testing.T) {
+	t.Setenv(paths.APIKeyEnv, "")
+	assert.Equal(t, APIKeySource{File: "/etc/default.key"}, ResolveAPIKeySource("", "/etc/default.key"))
+	assert.Equal(t, APIKeySource{File: "/run/secrets/key"}, ResolveAPIKeySource("/run/secrets/key", "/etc/default.key"))
+
+	t.Setenv(paths.APIKeyEnv, "  ")
+	assert.Equal(t, "file:/etc/default.key", ResolveAPIKeySource("", "/etc/default.key").String(), "a blank variable is ignored")
+
+	t.Setenv(paths.APIKeyEnv, "env-key")
+	assert.Equal(t, "env:"+paths.APIKeyEnv, ResolveAPIKeySource("/run/secrets/key", "/etc/default.key").String())
+}
+
+func TestResolveAPIKey_Precedence(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		json.NewEncoder(w).Encode(GetItemResponse{Node: Item{ID: "test"}})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	defaultFile := filepath.Join(dir, "default.key")
+	explicitFile := filepath.Join(dir, "explicit.key")
+	require.NoError(t, os.WriteFile(defaultFile, []byte("default-key\n"), 0600))
+	require.NoError(t, os.WriteFile(explicitFile, []byte("explicit-key\n"), 0600))
+
+	authorizationWith := func(apiKeyFile string) string {
+		t.Helper()
+		option, err := ResolveAPIKey(apiKeyFile, defaultFile)
+		require.NoError(t, err)
+		c := &WorkflowyClient{Client: client.New(server.URL, option)}
+		_, err = c.GetItem(context.Background(), "test")
+		require.NoError(t, err)
+		return authorization
+	}
+
+	t.Setenv(paths.APIKeyEnv, "")
+	assert.Equal(t, "Bearer default-key", authorizationWith(defaultFile))
+	assert.Equal(t, "Bearer explicit-key", authorizationWith(explicitFile))
+
+	t.Setenv(paths.APIKeyEnv, " env-key\n")
+	assert.Equal(t, "Bearer env-key", authorizationWith(defaultFile))
+	assert.Equal(t, "Bearer env-key", authorizationWith(explicitFile))
+}